@@ -26,7 +26,21 @@ contract ManagementContract {
 
     //The messageBus where messages can be sent to Obscuro
     MessageBus.IMessageBus public messageBus;
+
+    // The latest allow-list of recognised enclaves. It is signed by the network's policy key, which the enclaves check
+    // before applying it, so the contract only stores it
+    bytes public attestationAllowList;
+
+    // The account that deployed the contract, which is the only one that can publish an allow-list or request a rotation
+    // of the network secret
+    address private owner;
+    // The epoch of the latest network secret. The secret generated by InitializeNetworkSecret is epoch 0
+    uint256 public secretEpoch;
+    // The aggregators whose attestation was revoked. They are not sent the secrets of later epochs
+    mapping(address => bool) private revoked;
+
     constructor() {
+        owner = msg.sender;
         messageBus = new MessageBus.MessageBus();
         emit LogManagementContractCreated(address(messageBus));
     }
@@ -195,6 +209,7 @@ contract ManagementContract {
 
     // Aggregators can request the Network Secret given an attestation request report
     function RequestNetworkSecret(string calldata requestReport) public {
+        require(!revoked[msg.sender], "aggregator attestation was revoked");
         // Attestations should only be allowed to produce once ?
         attestationRequests[msg.sender] = requestReport;
     }
@@ -219,6 +234,8 @@ contract ManagementContract {
         require(recoveredAddrSignedCalculated == attesterID, "calculated address and attesterID dont match");
         }
 
+        require(!revoked[requesterID], "aggregator attestation was revoked");
+
         // mark the requesterID aggregator as an attested aggregator and store its host address
         attested[requesterID] = true;
         // TODO - Consider whether to remove duplicates.
@@ -229,6 +246,34 @@ contract ManagementContract {
         return hostAddresses;
     }
 
+    // Publishes a new allow-list of the enclaves that can be sent the network secret
+    function SetAttestationAllowList(bytes calldata allowList) public {
+        require(msg.sender == owner, "only the owner can publish an attestation allow-list");
+        attestationAllowList = allowList;
+    }
+
+    // Requests that the enclaves rotate the network secret to the given epoch, optionally revoking the attestation of an
+    // aggregator first. The request is signed by the network's policy key, which the enclaves check before acting on it
+    // solc-ignore-next-line unused-param
+    function RequestSecretRotation(uint256 epoch, address revokedID, bytes calldata signature) public {
+        require(msg.sender == owner, "only the owner can request a secret rotation");
+        require(epoch == secretEpoch + 1, "can only rotate to the next epoch");
+
+        if (revokedID != address(0)) {
+            attested[revokedID] = false;
+            revoked[revokedID] = true;
+        }
+    }
+
+    // Publishes the secret of the next epoch, encrypted for each authorised enclave by the enclave that generated it
+    // solc-ignore-next-line unused-param
+    function PublishSecretRotation(uint256 epoch, bytes calldata rotation) public {
+        require(attested[msg.sender], "aggregator not attested");
+        require(epoch == secretEpoch + 1, "can only rotate to the next epoch");
+
+        secretEpoch = epoch;
+    }
+
 
     // Accessor to check if the contract is locked or not
     function IsWithdrawalAvailable() view public returns (bool) {
@@ -239,4 +284,9 @@ contract ManagementContract {
     function Attested(address _addr) view public returns (bool) {
         return attested[_addr];
     }
+
+    // Accessor that checks if the attestation of an address was revoked
+    function Revoked(address _addr) view public returns (bool) {
+        return revoked[_addr];
+    }
 }
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// AttestationAllowList is the list of enclaves that the network recognises when sharing its secret. It is signed with
+// the network's policy key and published in the management contract, so that the recognised enclaves can be upgraded
+// without reconfiguring every node.
+type AttestationAllowList struct {
+	// Enclaves only apply an allow-list with a higher version than the one in force on the same L1 fork.
+	Version uint64 `json:"version"`
+	// The recognised enclave builds (MRENCLAVE values).
+	UniqueIDs []gethcommon.Hash `json:"uniqueIDs"`
+	// The recognised enclave signing keys (MRSIGNER values).
+	SignerIDs []gethcommon.Hash `json:"signerIDs"`
+	// The minimum security version number (SVN) of a recognised enclave.
+	MinSecurityVersion uint64 `json:"minSecurityVersion"`
+
+	Signature []byte `json:"signature"`
+}
+
+// The domain of the allow-list signatures, which distinguishes them from the other messages signed with the policy key.
+const attestationAllowListDomain = "obscuro-attestation-allow-list"
+
+// The contents of an allow-list that are signed, along with the network the allow-list is published on.
+type signedAttestationAllowList struct {
+	Domain             string
+	L1ChainID          uint64
+	ManagementContract gethcommon.Address
+	Version            uint64
+	UniqueIDs          []gethcommon.Hash
+	SignerIDs          []gethcommon.Hash
+	MinSecurityVersion uint64
+}
+
+// Hash returns the hash of the allow-list's contents, which is signed with the policy key. The hash commits to the L1
+// chain and the management contract the allow-list is published in, so that it cannot be replayed on another network.
+func (l *AttestationAllowList) Hash(l1ChainID int64, mgmtContract gethcommon.Address) (gethcommon.Hash, error) {
+	encoded, err := rlp.EncodeToBytes(signedAttestationAllowList{
+		Domain:             attestationAllowListDomain,
+		L1ChainID:          uint64(l1ChainID),
+		ManagementContract: mgmtContract,
+		Version:            l.Version,
+		UniqueIDs:          l.UniqueIDs,
+		SignerIDs:          l.SignerIDs,
+		MinSecurityVersion: l.MinSecurityVersion,
+	})
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("could not encode attestation allow-list. Cause: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// Sign signs the allow-list with the policy key, for publication in the given management contract.
+func (l *AttestationAllowList) Sign(policyKey *ecdsa.PrivateKey, l1ChainID int64, mgmtContract gethcommon.Address) error {
+	hash, err := l.Hash(l1ChainID, mgmtContract)
+	if err != nil {
+		return err
+	}
+	l.Signature, err = crypto.Sign(hash.Bytes(), policyKey)
+	if err != nil {
+		return fmt.Errorf("could not sign attestation allow-list. Cause: %w", err)
+	}
+	return nil
+}
+
+// Signer returns the address of the key that signed the allow-list for publication in the given management contract.
+func (l *AttestationAllowList) Signer(l1ChainID int64, mgmtContract gethcommon.Address) (gethcommon.Address, error) {
+	hash, err := l.Hash(l1ChainID, mgmtContract)
+	if err != nil {
+		return gethcommon.Address{}, err
+	}
+	publicKey, err := crypto.SigToPub(hash.Bytes(), l.Signature)
+	if err != nil {
+		return gethcommon.Address{}, fmt.Errorf("could not recover signer of attestation allow-list. Cause: %w", err)
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+// Validate returns an error if the allow-list does not recognise any enclave.
+func (l *AttestationAllowList) Validate() error {
+	if len(l.UniqueIDs) == 0 && len(l.SignerIDs) == 0 {
+		return errors.New("allow-list recognises no enclave builds or signing keys")
+	}
+	return nil
+}
+
+// EncodeAttestationAllowList returns the allow-list in the format stored in the management contract.
+func EncodeAttestationAllowList(allowList *AttestationAllowList) ([]byte, error) {
+	return json.Marshal(allowList)
+}
+
+// DecodeAttestationAllowList decodes an allow-list stored in the management contract.
+func DecodeAttestationAllowList(encoded []byte) (*AttestationAllowList, error) {
+	allowList := new(AttestationAllowList)
+	if err := json.Unmarshal(encoded, allowList); err != nil {
+		return nil, fmt.Errorf("could not decode attestation allow-list. Cause: %w", err)
+	}
+	return allowList, nil
+}
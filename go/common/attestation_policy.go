@@ -0,0 +1,96 @@
+package common
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/edgelesssys/ego/attestation/tcbstatus"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// AttestationPolicy is the set of rules that the attestation report of an enclave must satisfy for the enclave to be
+// sent the network secret by the other enclaves, and to be trusted by its host.
+type AttestationPolicy struct {
+	// The recognised enclave builds (MRENCLAVE values) and enclave signing keys (MRSIGNER values). An enclave is
+	// recognised if either its build or its signing key is. If both are empty, no enclave is recognised
+	UniqueIDs []gethcommon.Hash
+	SignerIDs []gethcommon.Hash
+	// The minimum security version number (SVN) of a recognised enclave
+	MinSecurityVersion uint64
+	// The product ID (ISVPRODID) of a recognised enclave. Zero means any product ID
+	ProductID uint16
+	// Whether enclaves running in debug mode are recognised
+	AllowDebug bool
+	// The TCB statuses that are accepted in addition to an up-to-date TCB
+	AllowedTCBStatuses []tcbstatus.Status
+	// The address of the key that signs the allow-lists published in the management contract, which replace the
+	// recognised enclaves and minimum SVN above. If zero, published allow-lists are ignored. Only used by enclaves
+	AllowListSigner gethcommon.Address
+}
+
+// Check returns an error if the report does not satisfy the policy.
+func (p *AttestationPolicy) Check(report attestation.Report) error {
+	if !containsHash(p.UniqueIDs, report.UniqueID) && !containsHash(p.SignerIDs, report.SignerID) {
+		return fmt.Errorf("enclave is not recognised. MRENCLAVE: %x, MRSIGNER: %x", report.UniqueID, report.SignerID)
+	}
+	if uint64(report.SecurityVersion) < p.MinSecurityVersion {
+		return fmt.Errorf("security version %d is below the minimum of %d", report.SecurityVersion, p.MinSecurityVersion)
+	}
+	if p.ProductID != 0 {
+		// The product ID is reported as a little-endian uint16, padded to 16 bytes.
+		if len(report.ProductID) < 2 || binary.LittleEndian.Uint16(report.ProductID) != p.ProductID {
+			return fmt.Errorf("product ID %x does not match the expected product ID %d", report.ProductID, p.ProductID)
+		}
+	}
+	if report.Debug && !p.AllowDebug {
+		return errors.New("enclave is running in debug mode")
+	}
+	if report.TCBStatus != tcbstatus.UpToDate && !containsStatus(p.AllowedTCBStatuses, report.TCBStatus) {
+		return fmt.Errorf("TCB status is not accepted: %s", tcbstatus.Explain(report.TCBStatus))
+	}
+	return nil
+}
+
+// ParseTCBStatuses parses the names of TCB statuses (e.g. SWHardeningNeeded).
+func ParseTCBStatuses(names []string) ([]tcbstatus.Status, error) {
+	var statuses []tcbstatus.Status
+	for _, name := range names {
+		status, err := parseTCBStatus(name)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func parseTCBStatus(name string) (tcbstatus.Status, error) {
+	for status := tcbstatus.UpToDate; status <= tcbstatus.Unknown; status++ {
+		if status.String() == name {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("unrecognised TCB status '%s'", name)
+}
+
+func containsHash(hashes []gethcommon.Hash, value []byte) bool {
+	for _, hash := range hashes {
+		if bytes.Equal(hash.Bytes(), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsStatus(statuses []tcbstatus.Status, status tcbstatus.Status) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
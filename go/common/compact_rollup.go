@@ -0,0 +1,215 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/obscuronet/go-obscuro/contracts/generated/MessageBus"
+)
+
+// compactRollupVersion prefixes rollups in the compact encoding. RLP-encoded rollups start with a list prefix (0xc0 or
+// above) instead, so both encodings can be told apart when decoding.
+const compactRollupVersion = 0x01
+
+// compactRollup is the encoding of an ExtRollup published to the L1. The rollup header is derived from the head batch,
+// and each batch header omits the fields that can be derived from the previous batch. The values shared by the batches
+// (the aggregator and the L1 blocks they reference) are stored once.
+type compactRollup struct {
+	ParentHash L2RootHash
+	Number     *big.Int
+	R, S       *big.Int
+
+	FirstBatchParentHash L2RootHash
+	FirstBatchNumber     *big.Int
+	Agg                  common.Address
+	L1Refs               []compactL1Ref
+	Batches              []compactBatch
+}
+
+// compactL1Ref holds the L1 block fields of a batch header, which are often shared by consecutive batches.
+type compactL1Ref struct {
+	L1Proof                       L1RootHash
+	LatestInboundCrossChainHash   common.Hash
+	LatestInboundCrossChainHeight *big.Int
+}
+
+type compactBatch struct {
+	L1Ref              uint64 // the index of the batch's L1 block fields in `compactRollup.L1Refs`
+	Root               StateRoot
+	TxHash             common.Hash
+	ReceiptHash        common.Hash
+	Bloom              types.Bloom
+	GasLimit           uint64
+	GasUsed            uint64
+	Time               uint64
+	Extra              []byte
+	MixDigest          common.Hash
+	BaseFee            *big.Int
+	R, S               *big.Int
+	CrossChainMessages []MessageBus.StructsCrossChainMessage
+	TxHashes           []TxHash
+	EncryptedTxBlob    EncryptedTransactions
+	ForkID             common.Hash `rlp:"optional"` // Optional, so that the rollups published before it was introduced decode.
+}
+
+// Encodes the rollup in the compact encoding. Returns an error if the rollup cannot be recovered exactly from its
+// compact encoding (e.g. because its header was not derived from its head batch).
+func encodeCompactRollup(r *ExtRollup) ([]byte, error) {
+	if len(r.Batches) == 0 {
+		return nil, errors.New("rollup has no batches")
+	}
+
+	firstBatch := r.Batches[0].Header
+	compact := compactRollup{
+		ParentHash:           r.Header.ParentHash,
+		Number:               r.Header.Number,
+		R:                    r.Header.R,
+		S:                    r.Header.S,
+		FirstBatchParentHash: firstBatch.ParentHash,
+		FirstBatchNumber:     firstBatch.Number,
+		Agg:                  firstBatch.Agg,
+		Batches:              make([]compactBatch, len(r.Batches)),
+	}
+
+	l1RefIndices := map[compactL1RefKey]uint64{}
+	for idx, batch := range r.Batches {
+		header := batch.Header
+		l1Ref := compactL1Ref{
+			L1Proof:                       header.L1Proof,
+			LatestInboundCrossChainHash:   header.LatestInboundCrossChainHash,
+			LatestInboundCrossChainHeight: header.LatestInboundCrossChainHeight,
+		}
+		l1RefIdx, found := l1RefIndices[l1Ref.key()]
+		if !found {
+			l1RefIdx = uint64(len(compact.L1Refs))
+			l1RefIndices[l1Ref.key()] = l1RefIdx
+			compact.L1Refs = append(compact.L1Refs, l1Ref)
+		}
+
+		compact.Batches[idx] = compactBatch{
+			L1Ref:              l1RefIdx,
+			Root:               header.Root,
+			TxHash:             header.TxHash,
+			ReceiptHash:        header.ReceiptHash,
+			Bloom:              header.Bloom,
+			GasLimit:           header.GasLimit,
+			GasUsed:            header.GasUsed,
+			Time:               header.Time,
+			Extra:              header.Extra,
+			MixDigest:          header.MixDigest,
+			BaseFee:            header.BaseFee,
+			R:                  header.R,
+			S:                  header.S,
+			CrossChainMessages: header.CrossChainMessages,
+			TxHashes:           batch.TxHashes,
+			EncryptedTxBlob:    batch.EncryptedTxBlob,
+			ForkID:             header.ForkID,
+		}
+	}
+
+	encoded, err := rlp.EncodeToBytes(compact)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode compact rollup. Cause: %w", err)
+	}
+	encoded = append([]byte{compactRollupVersion}, encoded...)
+
+	// We check that the rollup is recovered exactly, rather than checking each of the assumptions the encoding makes.
+	decoded, err := decodeCompactRollup(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode compact rollup. Cause: %w", err)
+	}
+	expected, err := rlp.EncodeToBytes(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode rollup. Cause: %w", err)
+	}
+	actual, err := rlp.EncodeToBytes(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode decoded rollup. Cause: %w", err)
+	}
+	if !bytes.Equal(expected, actual) {
+		return nil, errors.New("rollup cannot be recovered from its compact encoding")
+	}
+
+	return encoded, nil
+}
+
+func decodeCompactRollup(encoded []byte) (*ExtRollup, error) {
+	compact := compactRollup{}
+	if err := rlp.DecodeBytes(encoded[1:], &compact); err != nil {
+		return nil, err
+	}
+	if len(compact.Batches) == 0 {
+		return nil, errors.New("compact rollup has no batches")
+	}
+
+	batches := make([]*ExtBatch, len(compact.Batches))
+	parentHash := compact.FirstBatchParentHash
+	var crossChainMessages []MessageBus.StructsCrossChainMessage
+	for idx, batch := range compact.Batches {
+		if batch.L1Ref >= uint64(len(compact.L1Refs)) {
+			return nil, fmt.Errorf("batch %d references unknown L1 block %d", idx, batch.L1Ref)
+		}
+		l1Ref := compact.L1Refs[batch.L1Ref]
+
+		header := &BatchHeader{
+			ParentHash:                    parentHash,
+			Root:                          batch.Root,
+			TxHash:                        batch.TxHash,
+			ReceiptHash:                   batch.ReceiptHash,
+			Bloom:                         batch.Bloom,
+			Number:                        big.NewInt(0).Add(compact.FirstBatchNumber, big.NewInt(int64(idx))),
+			GasLimit:                      batch.GasLimit,
+			GasUsed:                       batch.GasUsed,
+			Time:                          batch.Time,
+			Extra:                         batch.Extra,
+			MixDigest:                     batch.MixDigest,
+			BaseFee:                       batch.BaseFee,
+			ForkID:                        batch.ForkID,
+			Agg:                           compact.Agg,
+			L1Proof:                       l1Ref.L1Proof,
+			R:                             batch.R,
+			S:                             batch.S,
+			CrossChainMessages:            batch.CrossChainMessages,
+			LatestInboundCrossChainHash:   l1Ref.LatestInboundCrossChainHash,
+			LatestInboundCrossChainHeight: l1Ref.LatestInboundCrossChainHeight,
+		}
+		batches[idx] = &ExtBatch{
+			Header:          header,
+			TxHashes:        batch.TxHashes,
+			EncryptedTxBlob: batch.EncryptedTxBlob,
+		}
+		parentHash = header.Hash()
+		crossChainMessages = append(crossChainMessages, header.CrossChainMessages...)
+	}
+
+	headBatch := batches[len(batches)-1]
+	rollupHeader := headBatch.Header.ToRollupHeader()
+	rollupHeader.ParentHash = compact.ParentHash
+	rollupHeader.Number = compact.Number
+	rollupHeader.R = compact.R
+	rollupHeader.S = compact.S
+	rollupHeader.HeadBatchHash = headBatch.Hash()
+	rollupHeader.CrossChainMessages = crossChainMessages
+
+	return &ExtRollup{Header: rollupHeader, Batches: batches}, nil
+}
+
+// The L1 block fields of a batch header, in a form that can be used as a map key.
+type compactL1RefKey struct {
+	l1Proof     L1RootHash
+	inboundHash common.Hash
+	height      string
+}
+
+func (r compactL1Ref) key() compactL1RefKey {
+	height := ""
+	if r.LatestInboundCrossChainHeight != nil {
+		height = r.LatestInboundCrossChainHeight.String()
+	}
+	return compactL1RefKey{l1Proof: r.L1Proof, inboundHash: r.LatestInboundCrossChainHash, height: height}
+}
@@ -0,0 +1,139 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// dictionaryID identifies the dictionary used to compress data. It is recorded in each compressed frame, so that the
+// dictionary can be changed in the future without breaking the decompression of data that has already been published.
+// The contents of the dictionary for a given ID must never change.
+const dictionaryID = 1
+
+// windowSize is the window of the encoder, which bounds how far back the frames it produces refer to. The decoders do
+// not accept frames with larger windows.
+const windowSize = 8 << 20
+
+var (
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	gzipMagic = []byte{0x1f, 0x8b}
+
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder // used when the decompressed size is not limited
+
+	ErrTooLarge = errors.New("decompressed data exceeds the maximum size")
+)
+
+func init() {
+	var err error
+	encoder, err = zstd.NewWriter(nil,
+		zstd.WithEncoderLevel(zstd.SpeedBestCompression),
+		zstd.WithWindowSize(windowSize),
+		zstd.WithEncoderDictRaw(dictionaryID, dictionary()),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("could not create zstd encoder. Cause: %s", err))
+	}
+	decoder, err = zstd.NewReader(nil, zstd.WithDecoderDictRaw(dictionaryID, dictionary()), zstd.WithDecoderMaxWindow(windowSize))
+	if err != nil {
+		panic(fmt.Sprintf("could not create zstd decoder. Cause: %s", err))
+	}
+}
+
+// Compress compresses the data using zstd with the Obscuro dictionary.
+func Compress(data []byte) []byte {
+	return encoder.EncodeAll(data, nil)
+}
+
+// Decompress decompresses data compressed with `Compress`. For backwards compatibility, it also accepts gzip-compressed
+// data, which was used to compress rollups before zstd. Returns ErrTooLarge if the data decompresses to more than
+// maxSize bytes. Zero means no limit, and must only be used for data produced by an enclave.
+func Decompress(data []byte, maxSize uint64) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, zstdMagic):
+		return decompressZstd(data, maxSize)
+	case bytes.HasPrefix(data, gzipMagic):
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("could not decompress gzip data. Cause: %w", err)
+		}
+		defer gz.Close()
+		if maxSize == 0 {
+			return io.ReadAll(gz)
+		}
+		decompressed, err := io.ReadAll(io.LimitReader(gz, int64(maxSize)+1))
+		if err != nil {
+			return nil, fmt.Errorf("could not decompress gzip data. Cause: %w", err)
+		}
+		if uint64(len(decompressed)) > maxSize {
+			return nil, ErrTooLarge
+		}
+		return decompressed, nil
+	default:
+		return nil, fmt.Errorf("data is not zstd or gzip compressed")
+	}
+}
+
+// Decompresses zstd data, stopping as soon as the decompressed data exceeds maxSize bytes.
+func decompressZstd(data []byte, maxSize uint64) ([]byte, error) {
+	if maxSize == 0 {
+		decompressed, err := decoder.DecodeAll(data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not decompress zstd data. Cause: %w", err)
+		}
+		return decompressed, nil
+	}
+
+	limitedDecoder, err := zstd.NewReader(nil,
+		zstd.WithDecoderConcurrency(1),
+		zstd.WithDecoderDictRaw(dictionaryID, dictionary()),
+		zstd.WithDecoderMaxMemory(maxSize),
+		zstd.WithDecoderMaxWindow(windowSize),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create zstd decoder. Cause: %w", err)
+	}
+	defer limitedDecoder.Close()
+
+	decompressed, err := limitedDecoder.DecodeAll(data, nil)
+	if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+		return nil, ErrTooLarge
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not decompress zstd data. Cause: %w", err)
+	}
+	return decompressed, nil
+}
+
+// IsCompressed returns whether the data starts with the header of a zstd frame.
+func IsCompressed(data []byte) bool {
+	return bytes.HasPrefix(data, zstdMagic)
+}
+
+// Returns the dictionary for `dictionaryID`. It primes the compressor with the byte sequences that recur in rollups
+// and transaction blobs, which the compressor would otherwise have to learn afresh in each (small) payload.
+func dictionary() []byte {
+	var dict []byte
+	// An empty logs bloom, as RLP-encoded in batch headers.
+	dict = append(dict, 0xb9, 0x01, 0x00)
+	dict = append(dict, make([]byte, 256)...)
+	// The root of an empty trie, used as the transactions and receipts roots of batches without transactions.
+	dict = append(dict, 0xa0, 0x56, 0xe8, 0x1f, 0x17, 0x1b, 0xcc, 0x55, 0xa6, 0xff, 0x83, 0x45, 0xe6, 0x92, 0xc0, 0xf8,
+		0x6e, 0x5b, 0x48, 0xe0, 0x1b, 0x99, 0x6c, 0xad, 0xc0, 0x01, 0x62, 0x2f, 0xb5, 0xe3, 0x63, 0xb4, 0x21)
+	// An empty hash and an empty address.
+	dict = append(dict, 0xa0)
+	dict = append(dict, make([]byte, 32)...)
+	dict = append(dict, 0x94)
+	dict = append(dict, make([]byte, 20)...)
+	// The selectors of the ERC-20 `transfer`, `approve` and `transferFrom` functions, which make up most of the
+	// transactions' calldata.
+	dict = append(dict, 0xa9, 0x05, 0x9c, 0xbb, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	dict = append(dict, 0x09, 0x5e, 0xa7, 0xb3, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	dict = append(dict, 0x23, 0xb8, 0x72, 0xdd, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	return dict
+}
@@ -0,0 +1,52 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"testing"
+)
+
+func TestDecompressesZstdAndGzip(t *testing.T) {
+	data := bytes.Repeat([]byte("obscuro rollup "), 100)
+
+	compressed := Compress(data)
+	if !IsCompressed(compressed) || len(compressed) >= len(data) {
+		t.Fatalf("expected data to be compressed")
+	}
+	decompressed, err := Decompress(compressed, 0)
+	if err != nil || !bytes.Equal(decompressed, data) {
+		t.Fatalf("could not decompress zstd data. Cause: %s", err)
+	}
+
+	// Rollups published before the switch to zstd were compressed using gzip.
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	if _, err = gz.Write(data); err != nil {
+		t.Fatalf("could not gzip data. Cause: %s", err)
+	}
+	if err = gz.Close(); err != nil {
+		t.Fatalf("could not gzip data. Cause: %s", err)
+	}
+	decompressed, err = Decompress(gzipped.Bytes(), 0)
+	if err != nil || !bytes.Equal(decompressed, data) {
+		t.Fatalf("could not decompress gzip data. Cause: %s", err)
+	}
+
+	if _, err = Decompress(data, 0); err == nil {
+		t.Fatalf("expected uncompressed data to be rejected")
+	}
+}
+
+func TestDecompressionStopsAtTheMaximumSize(t *testing.T) {
+	data := make([]byte, 1024*1024)
+	compressed := Compress(data)
+
+	if _, err := Decompress(compressed, uint64(len(data))-1); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected data exceeding the maximum size to be rejected, got %v", err)
+	}
+	decompressed, err := Decompress(compressed, uint64(len(data)))
+	if err != nil || !bytes.Equal(decompressed, data) {
+		t.Fatalf("could not decompress data of the maximum size. Cause: %s", err)
+	}
+}
@@ -0,0 +1,111 @@
+package common
+
+import (
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// DivergenceKind is a way in which a batch received from the sequencer can differ from its re-execution by a validator.
+type DivergenceKind string
+
+const (
+	StateRootDivergence          DivergenceKind = "stateRoot"
+	ReceiptRootDivergence        DivergenceKind = "receiptRoot"
+	GasUsedDivergence            DivergenceKind = "gasUsed"
+	LogsBloomDivergence          DivergenceKind = "logsBloom"
+	CrossChainMessagesDivergence DivergenceKind = "crossChainMessages"
+	// TransactionExecutionDivergence means a transaction included in the batch could not be executed.
+	TransactionExecutionDivergence DivergenceKind = "transactionExecution"
+	// SyntheticTransactionDivergence means the batch included a synthetic transaction not signed by the message bus owner.
+	SyntheticTransactionDivergence DivergenceKind = "syntheticTransaction"
+	// ChainParamsDivergence means the batch exceeded the gas limit or did not use the base fee set in the genesis.
+	ChainParamsDivergence DivergenceKind = "chainParams"
+)
+
+// DivergenceReport is produced by a validator when its re-execution of a batch signed by the sequencer does not match
+// the batch. It is signed with the validator's enclave key, whose public key was shared during attestation, so it can
+// be submitted as evidence against the sequencer.
+type DivergenceReport struct {
+	BatchHash   L2RootHash         `json:"batchHash"`
+	BatchNumber uint64             `json:"batchNumber"`
+	ParentHash  L2RootHash         `json:"parentHash"`
+	L1Proof     L1RootHash         `json:"l1Proof"`
+	Sequencer   gethcommon.Address `json:"sequencer"`
+	Validator   gethcommon.Address `json:"validator"`
+
+	Divergences []DivergenceKind `json:"divergences"`
+
+	// The values in the batch header, and the values from the validator's re-execution.
+	ExpectedStateRoot          StateRoot       `json:"expectedStateRoot"`
+	ActualStateRoot            StateRoot       `json:"actualStateRoot"`
+	ExpectedReceiptRoot        gethcommon.Hash `json:"expectedReceiptRoot"`
+	ActualReceiptRoot          gethcommon.Hash `json:"actualReceiptRoot"`
+	ExpectedCrossChainMessages gethcommon.Hash `json:"expectedCrossChainMessages"` // The hash of the messages' hashes
+	ActualCrossChainMessages   gethcommon.Hash `json:"actualCrossChainMessages"`
+
+	// The hashes of the re-executed receipts, deposits included, in the order of the batch's receipt trie. Anyone
+	// holding the sequencer's receipts can compare them to find the first transaction whose execution diverged.
+	ActualReceiptHashes []gethcommon.Hash `json:"actualReceiptHashes"`
+
+	// The index in the batch of the earliest transaction the validator can show diverged, or nil if none can be shown
+	// from the batch alone. The batch only commits to the results of all its transactions, so this is only known when a
+	// transaction failed to execute, was not a valid synthetic transaction, emitted different cross-chain messages, or
+	// emitted logs missing from the batch's logs bloom. An earlier transaction may still have diverged without evidence
+	// in the batch; the receipt hashes locate it.
+	FirstDivergentTx *uint64 `json:"firstDivergentTx"`
+
+	Signature []byte `json:"signature"`
+}
+
+// The contents of a report that the validator signs. RLP does not tell a nil pointer from zero, so whether the first
+// divergent transaction is known is encoded explicitly.
+type signedDivergenceReport struct {
+	BatchHash                  L2RootHash
+	BatchNumber                uint64
+	ParentHash                 L2RootHash
+	L1Proof                    L1RootHash
+	Sequencer                  gethcommon.Address
+	Validator                  gethcommon.Address
+	Divergences                []DivergenceKind
+	ExpectedStateRoot          StateRoot
+	ActualStateRoot            StateRoot
+	ExpectedReceiptRoot        gethcommon.Hash
+	ActualReceiptRoot          gethcommon.Hash
+	ExpectedCrossChainMessages gethcommon.Hash
+	ActualCrossChainMessages   gethcommon.Hash
+	ActualReceiptHashes        []gethcommon.Hash
+	HasFirstDivergentTx        bool
+	FirstDivergentTx           uint64
+}
+
+// Hash returns the hash of the RLP encoding of the report's contents, which is signed by the validator.
+func (r *DivergenceReport) Hash() (gethcommon.Hash, error) {
+	signed := signedDivergenceReport{
+		BatchHash:                  r.BatchHash,
+		BatchNumber:                r.BatchNumber,
+		ParentHash:                 r.ParentHash,
+		L1Proof:                    r.L1Proof,
+		Sequencer:                  r.Sequencer,
+		Validator:                  r.Validator,
+		Divergences:                r.Divergences,
+		ExpectedStateRoot:          r.ExpectedStateRoot,
+		ActualStateRoot:            r.ActualStateRoot,
+		ExpectedReceiptRoot:        r.ExpectedReceiptRoot,
+		ActualReceiptRoot:          r.ActualReceiptRoot,
+		ExpectedCrossChainMessages: r.ExpectedCrossChainMessages,
+		ActualCrossChainMessages:   r.ActualCrossChainMessages,
+		ActualReceiptHashes:        r.ActualReceiptHashes,
+		HasFirstDivergentTx:        r.FirstDivergentTx != nil,
+	}
+	if r.FirstDivergentTx != nil {
+		signed.FirstDivergentTx = *r.FirstDivergentTx
+	}
+	encoded, err := rlp.EncodeToBytes(signed)
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("could not encode divergence report. Cause: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
@@ -30,6 +30,33 @@ func StartNewContainer(containerName, image string, cmds []string, ports []int,
 	}
 	defer cli.Close()
 
+	id, err := CreateNewContainer(containerName, image, cmds, ports, envs, devices, volumes)
+	if err != nil {
+		return "", err
+	}
+
+	if err := cli.ContainerStart(ctx, id, types.ContainerStartOptions{}); err != nil {
+		return "", err
+	}
+
+	out, err := cli.ContainerLogs(ctx, id, types.ContainerLogsOptions{ShowStderr: true, ShowStdout: true})
+	if err != nil {
+		return "", err
+	}
+
+	_, _ = stdcopy.StdCopy(os.Stdout, os.Stderr, out)
+	return id, nil
+}
+
+// CreateNewContainer creates the container without starting it, so that files can be copied into it first.
+func CreateNewContainer(containerName, image string, cmds []string, ports []int, envs, devices, volumes map[string]string) (string, error) {
+	ctx := context.Background()
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return "", err
+	}
+	defer cli.Close()
+
 	// Check if the image exists locally
 	_, _, err = cli.ImageInspectWithRaw(context.Background(), image)
 	if err != nil {
@@ -112,18 +139,29 @@ func StartNewContainer(containerName, image string, cmds []string, ports []int,
 	if err != nil {
 		return "", err
 	}
+	return resp.ID, nil
+}
 
-	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
-		return "", err
+// StartContainer starts an existing container, such as one that has been stopped.
+func StartContainer(containerName string) error {
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return err
 	}
+	defer cli.Close()
 
-	out, err := cli.ContainerLogs(ctx, resp.ID, types.ContainerLogsOptions{ShowStderr: true, ShowStdout: true})
+	return cli.ContainerStart(context.Background(), containerName, types.ContainerStartOptions{})
+}
+
+// Stop stops the container without removing it, so that it can be started again with its data.
+func Stop(containerName string) error {
+	cli, err := client.NewClientWithOpts(client.FromEnv)
 	if err != nil {
-		return "", err
+		return err
 	}
+	defer cli.Close()
 
-	_, _ = stdcopy.StdCopy(os.Stdout, os.Stderr, out)
-	return resp.ID, nil
+	return cli.ContainerStop(context.Background(), containerName, nil)
 }
 
 func StopAndRemove(containerName string) error {
@@ -142,6 +180,142 @@ func StopAndRemove(containerName string) error {
 	return cli.ContainerRemove(ctx, containerName, types.ContainerRemoveOptions{Force: true})
 }
 
+// Rename renames the container.
+func Rename(containerName, newName string) error {
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	return cli.ContainerRename(context.Background(), containerName, newName)
+}
+
+// ContainerState returns the state of the container (e.g. running, exited), or false if there is no such container.
+func ContainerState(containerName string) (string, bool, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return "", false, err
+	}
+	defer cli.Close()
+
+	containerJSON, err := cli.ContainerInspect(context.Background(), containerName)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return containerJSON.State.Status, true, nil
+}
+
+// ContainerVolume returns the name of the volume mounted at the path in the container, or false if there is no such
+// container or no volume is mounted at the path.
+func ContainerVolume(containerName, path string) (string, bool, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return "", false, err
+	}
+	defer cli.Close()
+
+	containerJSON, err := cli.ContainerInspect(context.Background(), containerName)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	for _, mountPoint := range containerJSON.Mounts {
+		if mountPoint.Type == mount.TypeVolume && mountPoint.Destination == path {
+			return mountPoint.Name, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// ContainerImage returns the image the container was created with.
+func ContainerImage(containerName string) (string, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return "", err
+	}
+	defer cli.Close()
+
+	containerJSON, err := cli.ContainerInspect(context.Background(), containerName)
+	if err != nil {
+		return "", err
+	}
+	return containerJSON.Config.Image, nil
+}
+
+// CopyFromContainer writes the file or dir at srcPath in the container to dst, as a tar archive.
+func CopyFromContainer(containerName, srcPath string, dst io.Writer) error {
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	content, _, err := cli.CopyFromContainer(context.Background(), containerName, srcPath)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	_, err = io.Copy(dst, content)
+	return err
+}
+
+// CopyToContainer extracts the tar archive read from src into the dir at dstPath in the container.
+func CopyToContainer(containerName, dstPath string, src io.Reader) error {
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	return cli.CopyToContainer(context.Background(), containerName, dstPath, src, types.CopyToContainerOptions{})
+}
+
+// RemoveVolume removes the volume and all its data. The containers using it must have been removed first.
+func RemoveVolume(volumeName string) error {
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	err = cli.VolumeRemove(context.Background(), volumeName, false)
+	if err != nil && !client.IsErrNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// Logs writes the logs of the container to stdout and stderr. If tail is not "all", only that many lines are written
+// from the end of the logs. If follow is true, it keeps writing the new logs until the container stops.
+func Logs(containerName string, tail string, follow bool, stdout, stderr io.Writer) error {
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	out, err := cli.ContainerLogs(context.Background(), containerName, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       tail,
+		Follow:     follow,
+	})
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = stdcopy.StdCopy(stdout, stderr, out)
+	return err
+}
+
 func ensureVolumeExists(cli *client.Client, volumeName string) (*types.Volume, error) {
 	ctx := context.Background()
 	allVolumes, err := cli.VolumeList(ctx, filters.NewArgs())
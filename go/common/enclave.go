@@ -30,23 +30,38 @@ type Enclave interface {
 	// GenerateSecret - the genesis enclave is responsible with generating the secret entropy
 	GenerateSecret() (EncryptedSharedEnclaveSecret, error)
 
-	// InitEnclave - initialise an enclave with a seed received by another enclave
+	// InitEnclave - initialise an enclave with the secrets of every epoch received from another enclave, as an RLP-encoded
+	// list indexed by epoch and encrypted with the enclave's key. The bare secret of epoch 0, as sent by enclaves that
+	// predate epochs, is accepted too. Enclaves that predate epochs cannot decode the list, so they must be upgraded
+	// before they are sent the secret by an upgraded enclave
 	InitEnclave(secret EncryptedSharedEnclaveSecret) error
 
 	// SubmitL1Block - Used for the host to submit L1 blocks to the enclave, these may be:
 	//  a. historic block - if the enclave is behind and in the process of catching up with the L1 state
 	//  b. the latest block published by the L1, to which the enclave should respond with a rollup
-	// It is the responsibility of the host to gossip the returned rollup
+	// The batch, rollups and secret responses produced as a result are pushed on the event stream
 	// For good functioning the caller should always submit blocks ordered by height
-	// submitting a block before receiving ancestors of it, will result in it being ignored
-	SubmitL1Block(block L1Block, receipts L1Receipts, isLatest bool) (*BlockSubmissionResponse, error)
+	// submitting a block before receiving ancestors of it, will result in it being rejected with a BlockRejectError
+	SubmitL1Block(block L1Block, receipts L1Receipts, isLatest bool) error
 
 	// SubmitTx - user transactions
 	SubmitTx(tx EncryptedTx) (EncryptedResponseSendRawTx, error)
 
-	// SubmitBatch submits a batch received from the sequencer for processing.
+	// SubmitBatch submits a batch received from the sequencer for processing. The logs of the batch for each
+	// subscription are pushed on the event stream
 	SubmitBatch(batch *ExtBatch) error
 
+	// StreamEvents - Returns a stream of the enclave's outputs following the one with the given sequence number, and a
+	// function that closes the stream. The enclave buffers its outputs until they are acknowledged, so the stream
+	// starts with the buffered outputs, then pushes each new output as soon as it is produced. It is the
+	// responsibility of the host to distribute the batches, to publish the rollups and secret responses, and to route
+	// the logs to their subscribers
+	StreamEvents(after uint64) (<-chan *EnclaveEvent, func(), error)
+
+	// AckEvents - Acknowledges that the outputs up to and including the one with the given sequence number were
+	// handled, so that the enclave no longer buffers them
+	AckEvents(sequence uint64) error
+
 	// ExecuteOffChainTransaction - Execute a smart contract to retrieve data
 	// Todo - return the result with a block delay. To prevent frontrunning.
 	ExecuteOffChainTransaction(encryptedParams EncryptedParamsCall) (EncryptedResponseCall, error)
@@ -63,6 +78,39 @@ type Enclave interface {
 	// GetTransactionReceipt returns a transaction receipt given its signed hash, or nil if the transaction is unknown
 	GetTransactionReceipt(encryptedParams EncryptedParamsGetTxReceipt) (EncryptedResponseGetTxReceipt, error)
 
+	// GetWithdrawalProofs returns the cross-chain messages published by a transaction given its hash, with what is
+	// needed to claim them on the L1, encrypted with the viewing key for the transaction's `from` field
+	GetWithdrawalProofs(encryptedParams EncryptedParamsGetWithdrawals) (EncryptedResponseGetWithdrawals, error)
+
+	// DebugTraceTransaction re-executes a transaction given its hash and returns the trace of its execution, encrypted
+	// with the viewing key for the transaction's `from` field
+	DebugTraceTransaction(encryptedParams EncryptedParamsTraceTx) (EncryptedResponseTrace, error)
+
+	// DebugTraceCall executes a call in the same way as eth_call and returns the trace of its execution, encrypted with
+	// the viewing key for the call's `from` field
+	DebugTraceCall(encryptedParams EncryptedParamsTraceCall) (EncryptedResponseTrace, error)
+
+	// GetDivergenceReports returns the signed reports of the batches received from the sequencer whose re-execution
+	// diverged from them, in batch number order
+	GetDivergenceReports() ([]*DivergenceReport, error)
+
+	// ExportSnapshot - returns a snapshot of the enclave's state at the batch with the given number, from which another
+	// enclave can resume without replaying the preceding L1 blocks. The snapshot is signed by the enclave, and encrypted
+	// so that only enclaves holding the shared secret can read it
+	ExportSnapshot(batchNumber uint64) (EncryptedSnapshot, error)
+
+	// ImportSnapshot - resumes from a snapshot exported by another enclave. It can only be called on an enclave that
+	// holds the shared secret but has no head batch yet
+	ImportSnapshot(snapshot EncryptedSnapshot) error
+
+	// GetInboundMessageRecords returns the audit records of the cross-chain messages sent from the L1 by a sender, or
+	// of a single message given its sender and sequence number, encrypted with the viewing key of the node's host
+	GetInboundMessageRecords(encryptedParams EncryptedParamsGetInboundMsgs) (EncryptedResponseGetInboundMsgs, error)
+
+	// GetInboundMessageDelivery returns whether and where the cross-chain message with the given sender and sequence
+	// number was delivered, or nil if the enclave has not seen the message
+	GetInboundMessageDelivery(sender gethcommon.Address, sequence uint64) (*InboundMessageDelivery, error)
+
 	// AddViewingKey - Decrypts, verifies and saves viewing keys.
 	// Viewing keys are asymmetric keys generated inside the wallet extension, and then signed by the wallet (e.g.
 	// MetaMask) in which the user holds the signing keys.
@@ -81,9 +129,17 @@ type Enclave interface {
 	// GetCode returns the code stored at the given address in the state for the given rollup hash.
 	GetCode(address gethcommon.Address, rollupHash *gethcommon.Hash) ([]byte, error)
 
+	// GetPublicStorageAt returns the value of a storage slot of a contract in the state for the given batch hash,
+	// provided the contract declares the slot public.
+	GetPublicStorageAt(address gethcommon.Address, slot gethcommon.Hash, batchHash *gethcommon.Hash) (gethcommon.Hash, error)
+
+	// GetStorageAt returns the value of any storage slot of a contract, encrypted with the viewing key for the
+	// contract's deployer.
+	GetStorageAt(encryptedParams EncryptedParamsGetStorageAt) (EncryptedResponseGetStorageAt, error)
+
 	// Subscribe adds a log subscription to the enclave under the given ID, provided the request is authenticated
-	// correctly. The events will be populated in the BlockSubmissionResponse. If there is an existing subscription
-	// with the given ID, it is overwritten.
+	// correctly. The logs will be pushed on the event stream as each batch is processed. If there is an existing
+	// subscription with the given ID, it is overwritten.
 	Subscribe(id rpc.ID, encryptedParams EncryptedParamsLogSubscription) error
 
 	// Unsubscribe removes the log subscription with the given ID from the enclave. If there is no subscription with
@@ -102,16 +158,44 @@ type Enclave interface {
 	// HealthCheck returns whether the enclave is in a healthy state
 	HealthCheck() (bool, error)
 
-	GenerateRollup() (*ExtRollup, error)
+	// GenerateRollups - Generates the next rollups to publish, in order. The pending batches are split across several
+	// rollups if they do not fit in a single one
+	GenerateRollups() ([]*ExtRollup, error)
+
+	// CreateBatch - Used for the host to have the sequencer enclave produce a batch on top of the current head batch,
+	// when the enclave is configured to produce batches on the host's interval rather than for each L1 block. The batch
+	// records the latest L1 block ingested by the enclave as its L1 proof. If skipIfEmpty is true and there are no
+	// pending transactions or deposits, no batch is produced.
+	// The batch and any rollups produced are pushed on the event stream
+	CreateBatch(skipIfEmpty bool) error
+
+	// TimeBasedBatches returns whether the enclave produces batches only when the host requests them via CreateBatch,
+	// so that a sequencer host can check that its batch interval is consistent with the enclave's configuration
+	TimeBasedBatches() (bool, error)
 }
 
-// BlockSubmissionResponse is the response sent from the enclave back to the node after ingesting a block
-type BlockSubmissionResponse struct {
-	ProducedBatch           *ExtBatch                 // The batch produced iff the node is a sequencer and is on the latest block.
-	ProducedRollup          *ExtRollup                // The rollup produced iff the node is a sequencer and it is time to produce a new rollup.
+// EnclaveEvent is an output of the enclave pushed to the host on the event stream, after ingesting a block or a batch,
+// or after producing a batch at the host's request
+type EnclaveEvent struct {
+	ProducedBatch           *ExtBatch                 // The batch produced iff the node is a sequencer and is on the latest block, or the batch requested by the host.
+	ProducedRollups         []*ExtRollup              // The rollups produced iff the node is a sequencer and it is time to produce new rollups, to be published in order.
 	ProducedSecretResponses []*ProducedSecretResponse // The responses to any secret requests in the ingested L1 block.
-	SubscribedLogs          map[rpc.ID][]byte         // The logs produced by the L1 block and all its ancestors for each subscription ID.
-	RejectError             *BlockRejectError         // If block was rejected, contains information about what block to submit next.
+	SubscribedLogs          map[rpc.ID][]byte         // The logs of the new head batch for each subscription ID.
+	RollupDecision          *RollupDecision           // Whether and why the sequencer published a rollup. Nil if the node did not consider publishing one.
+	ProducedSecretRotation  *SecretRotation           // The secret of the next epoch iff the node is the sequencer and the ingested L1 block requested a rotation.
+	Sequence                uint64                    // The sequence number of the event, which increases with each event.
+}
+
+// IsEmpty indicates whether the event carries nothing for the host to act on.
+func (e *EnclaveEvent) IsEmpty() bool {
+	return e.ProducedBatch == nil && len(e.ProducedRollups) == 0 && len(e.ProducedSecretResponses) == 0 &&
+		len(e.SubscribedLogs) == 0 && e.RollupDecision == nil && e.ProducedSecretRotation == nil
+}
+
+// RollupDecision is the sequencer's decision whether to publish the batches pending publication in a rollup.
+type RollupDecision struct {
+	Publish bool
+	Reason  string
 }
 
 // ProducedSecretResponse contains the data to publish to L1 in response to a secret request discovered while processing an L1 block
@@ -26,11 +26,23 @@ func (eb EncodedL1Block) DecodeBlock() (*types.Block, error) {
 	return &b, nil
 }
 
+// MaxEncodedRollupSize is the maximum size of an encoded rollup. The sequencer does not publish larger rollups, and the
+// rollups read from the L1 are not decompressed beyond this size, so that a small rollup transaction cannot expand to
+// an unbounded size inside the enclave.
+const MaxEncodedRollupSize = 1024 * 1024
+
+// EncodeRollup encodes the rollup in the compact encoding. Returns an error if the rollup cannot be recovered from the
+// compact encoding, which means its header was not derived from its head batch.
 func EncodeRollup(r *ExtRollup) (EncodedRollup, error) {
-	return rlp.EncodeToBytes(r)
+	return encodeCompactRollup(r)
 }
 
+// DecodeRollup decodes a rollup in either the compact encoding, or the RLP encoding of the rollups published before
+// the compact encoding was introduced.
 func DecodeRollup(encoded EncodedRollup) (*ExtRollup, error) {
+	if len(encoded) > 0 && encoded[0] == compactRollupVersion {
+		return decodeCompactRollup(encoded)
+	}
 	r := new(ExtRollup)
 	err := rlp.DecodeBytes(encoded, r)
 	return r, err
@@ -50,6 +50,10 @@ type BatchHeader struct {
 
 	// The block height of the latest block that has been scanned for cross chain messages.
 	LatestInboundCrossChainHeight *big.Int `json:"inboundCrossChainHeight"`
+
+	// Identifies the changes of the chain config that are active at the batch's number. It is optional, so that the
+	// batches produced before it was introduced, which have a zero fork ID, still decode and keep their hash.
+	ForkID common.Hash `json:"forkID" rlp:"optional"`
 }
 
 // RollupHeader is a public / plaintext struct that holds common properties of rollups.
@@ -0,0 +1,75 @@
+package common
+
+import (
+	gethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// InboundMessageStatus is the outcome of the delivery of a cross-chain message sent from the L1.
+type InboundMessageStatus string
+
+const (
+	// InboundMessageDelivered means a synthetic transaction delivered the message to the L2 message bus.
+	InboundMessageDelivered InboundMessageStatus = "delivered"
+	// InboundMessageRefused means the message was only seen as a replay of a sequence number delivered earlier.
+	InboundMessageRefused InboundMessageStatus = "refused"
+	// InboundMessageOrphaned means the message was only delivered in batches that are no longer canonical, because the
+	// L1 reorganised. It will be delivered again on the canonical chain.
+	InboundMessageOrphaned InboundMessageStatus = "orphaned"
+)
+
+// InboundMessageRecord is the enclave's audit record of a cross-chain message sent from the L1, identified by its
+// sender and the sequence number the L1 message bus assigned to it.
+type InboundMessageRecord struct {
+	Sender      gethcommon.Address   `json:"sender"`
+	Sequence    uint64               `json:"sequence"`
+	Nonce       uint32               `json:"nonce"`
+	Topic       uint32               `json:"topic"`
+	MessageHash gethcommon.Hash      `json:"messageHash"`
+	Status      InboundMessageStatus `json:"status"`
+
+	// The synthetic transaction that delivered the message, and the batch it was delivered in. When the record is read,
+	// these are resolved from the delivery in the canonical batch.
+	SyntheticTxHash gethcommon.Hash `json:"syntheticTxHash"`
+	BatchNumber     uint64          `json:"batchNumber"`
+	BatchL1Proof    gethcommon.Hash `json:"batchL1Proof"`
+
+	// The message is delivered again on each fork of the L1 that relays it, so every delivery is kept.
+	Deliveries     []MessageDelivery `json:"deliveries"`
+	RefusedReplays []RefusedReplay   `json:"refusedReplays"`
+}
+
+// MessageDelivery records the successful execution of a synthetic transaction delivering the message in a batch.
+type MessageDelivery struct {
+	MessageHash     gethcommon.Hash `json:"messageHash"` // Differs between forks if they relayed different contents
+	SyntheticTxHash gethcommon.Hash `json:"syntheticTxHash"`
+	BatchNumber     uint64          `json:"batchNumber"`
+	BatchL1Proof    gethcommon.Hash `json:"batchL1Proof"`
+}
+
+// RefusedReplay records a message that was not delivered because its sequence number had been delivered already.
+type RefusedReplay struct {
+	MessageHash  gethcommon.Hash `json:"messageHash"` // Differs from the record's hash if the replay had different contents
+	BatchNumber  uint64          `json:"batchNumber"`
+	BatchL1Proof gethcommon.Hash `json:"batchL1Proof"`
+}
+
+// InboundMessageDelivery is the public part of an InboundMessageRecord. The message itself is public on the L1, so
+// anyone may learn whether and where it was delivered, but not the replays that were refused.
+type InboundMessageDelivery struct {
+	MessageHash     gethcommon.Hash      `json:"messageHash"`
+	Status          InboundMessageStatus `json:"status"`
+	SyntheticTxHash gethcommon.Hash      `json:"syntheticTxHash"`
+	BatchNumber     uint64               `json:"batchNumber"`
+	BatchL1Proof    gethcommon.Hash      `json:"batchL1Proof"`
+}
+
+// Delivery returns the public part of the record.
+func (r *InboundMessageRecord) Delivery() *InboundMessageDelivery {
+	return &InboundMessageDelivery{
+		MessageHash:     r.MessageHash,
+		Status:          r.Status,
+		SyntheticTxHash: r.SyntheticTxHash,
+		BatchNumber:     r.BatchNumber,
+		BatchL1Proof:    r.BatchL1Proof,
+	}
+}
@@ -1,7 +1,9 @@
 package log
 
 import (
+	"errors"
 	"os"
+	"sync/atomic"
 
 	gethlog "github.com/ethereum/go-ethereum/log"
 )
@@ -56,6 +58,29 @@ func New(component string, level int, out string, ctx ...interface{}) gethlog.Lo
 		}
 		s = s1
 	}
-	l.SetHandler(gethlog.LvlFilterHandler(gethlog.Lvl(level), s))
+	l.SetHandler(&levelHandler{level: int32(level), next: s})
 	return l
 }
+
+// SetLevel changes the level of a top level logger created by New, and of the loggers derived from it.
+func SetLevel(logger gethlog.Logger, level int) error {
+	h, ok := logger.GetHandler().(*levelHandler)
+	if !ok {
+		return errors.New("logger was not created by log.New")
+	}
+	atomic.StoreInt32(&h.level, int32(level))
+	return nil
+}
+
+// levelHandler drops the records above its level, which can be changed while the logger is in use.
+type levelHandler struct {
+	level int32
+	next  gethlog.Handler
+}
+
+func (h *levelHandler) Log(r *gethlog.Record) error {
+	if r.Lvl > gethlog.Lvl(atomic.LoadInt32(&h.level)) {
+		return nil
+	}
+	return h.next.Log(r)
+}
@@ -7,9 +7,8 @@ import (
 
 // ExtRollup is an encrypted form of rollup used when passing the rollup around outside an enclave.
 type ExtRollup struct {
-	Header *RollupHeader
-	// TODO - #718 - Consider compressing these batches before submitting to the L1.
-	Batches []*ExtBatch // The batches included in the rollup, in external/encrypted form.
+	Header  *RollupHeader
+	Batches []*ExtBatch // The batches included in the rollup, in external/encrypted form. See `EncodeRollup` for their compact encoding on the L1.
 	hash    atomic.Value
 }
 
@@ -0,0 +1,210 @@
+package rpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/obscuronet/go-obscuro/go/common"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// The TLS keys are generated afresh each time the enclave or host starts, so the certificates only need to outlive a
+// single run.
+const certificateValidity = 10 * 365 * 24 * time.Hour
+
+// The private enterprise number under which the OIDs of the certificate extensions are allocated. Obscuro does not have
+// its own private enterprise number yet, so the number IANA reserves for documentation (RFC 5612) is used, which is
+// never assigned to an organisation and so cannot clash with another organisation's extensions. The UUID arc (2.25)
+// cannot be used instead, since Go's X.509 parser rejects OID components that do not fit in an int.
+// todo - replace with Obscuro's own private enterprise number once it is registered with IANA
+const privateEnterpriseNumber = 32473
+
+var (
+	// The OIDs of the certificate extensions that bind the enclave's and the host's TLS keys to their identities.
+	enclaveBindingOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, privateEnterpriseNumber, 1, 1}
+	hostBindingOID    = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, privateEnterpriseNumber, 1, 2}
+)
+
+// ReportVerifier verifies an enclave's attestation report against the attestation policy, and returns the data embedded
+// in the report.
+type ReportVerifier func(att *common.AttestationReport) ([]byte, error)
+
+// enclaveBinding binds the enclave's TLS key to its attestation report.
+type enclaveBinding struct {
+	Attestation common.EncodedAttestationReport
+	Signature   []byte // The attested enclave key's signature over the hash of the TLS public key.
+}
+
+// NewEnclaveTLSConfig returns the TLS config of the enclave's RPC server. The enclave's certificate carries its
+// attestation report, and a signature over the certificate's key by the attested enclave key. Only a host presenting
+// a certificate whose key is signed by the key of the given host ID can connect.
+func NewEnclaveTLSConfig(attestation *common.AttestationReport, enclaveKey *ecdsa.PrivateKey, hostID gethcommon.Address) (*tls.Config, error) {
+	encodedAttestation, err := common.EncodeAttestation(attestation)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode attestation. Cause: %w", err)
+	}
+	certificate, err := newBoundCertificate(enclaveBindingOID, func(keyHash []byte) ([]byte, error) {
+		signature, err := gethcrypto.Sign(keyHash, enclaveKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not sign TLS key. Cause: %w", err)
+		}
+		return rlp.EncodeToBytes(enclaveBinding{Attestation: encodedAttestation, Signature: signature})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   tls.RequireAnyClientCert,
+		MinVersion:   tls.VersionTLS13,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyHostCertificate(rawCerts, hostID)
+		},
+	}, nil
+}
+
+// NewHostTLSConfig returns the TLS config the host uses to connect to its enclave. The host's certificate carries a
+// signature over the certificate's key by the host's key, whose address must be the host ID. The host only accepts an
+// enclave certificate that carries an attestation report for the host ID that passes the verifier, and whose key is
+// signed by the attested enclave key.
+func NewHostTLSConfig(hostKey *ecdsa.PrivateKey, hostID gethcommon.Address, verifyReport ReportVerifier) (*tls.Config, error) {
+	if gethcrypto.PubkeyToAddress(hostKey.PublicKey) != hostID {
+		return nil, fmt.Errorf("host key does not match host ID %s", hostID)
+	}
+	certificate, err := newBoundCertificate(hostBindingOID, func(keyHash []byte) ([]byte, error) {
+		return gethcrypto.Sign(keyHash, hostKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS13,
+		// The enclave's certificate is self-signed, so rather than checking it against a CA, we check it against the
+		// enclave's attestation.
+		InsecureSkipVerify: true, //nolint:gosec
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyEnclaveCertificate(rawCerts, hostID, verifyReport)
+		},
+	}, nil
+}
+
+// Checks that the host's TLS key is signed by the key of the given host ID.
+func verifyHostCertificate(rawCerts [][]byte, hostID gethcommon.Address) error {
+	signature, keyHash, err := peerBinding(rawCerts, hostBindingOID)
+	if err != nil {
+		return err
+	}
+	signer, err := gethcrypto.SigToPub(keyHash, signature)
+	if err != nil {
+		return fmt.Errorf("could not recover signer of host TLS key. Cause: %w", err)
+	}
+	if gethcrypto.PubkeyToAddress(*signer) != hostID {
+		return fmt.Errorf("host TLS key was not signed by the key of host %s", hostID)
+	}
+	return nil
+}
+
+// Checks that the enclave's attestation report is for the given host ID and passes the verifier, and that the enclave's
+// TLS key is signed by the attested enclave key.
+func verifyEnclaveCertificate(rawCerts [][]byte, hostID gethcommon.Address, verifyReport ReportVerifier) error {
+	encodedBinding, keyHash, err := peerBinding(rawCerts, enclaveBindingOID)
+	if err != nil {
+		return err
+	}
+	var binding enclaveBinding
+	if err = rlp.DecodeBytes(encodedBinding, &binding); err != nil {
+		return fmt.Errorf("could not decode enclave binding. Cause: %w", err)
+	}
+	attestation, err := common.DecodeAttestation(binding.Attestation)
+	if err != nil {
+		return fmt.Errorf("could not decode enclave attestation. Cause: %w", err)
+	}
+	if attestation.Owner != hostID {
+		return fmt.Errorf("enclave attestation was for host %s rather than host %s", attestation.Owner, hostID)
+	}
+	data, err := verifyReport(attestation)
+	if err != nil {
+		return fmt.Errorf("could not verify enclave attestation. Cause: %w", err)
+	}
+	if err = attestation.VerifyIdentity(data); err != nil {
+		return err
+	}
+
+	enclaveKey, err := gethcrypto.DecompressPubkey(attestation.PubKey)
+	if err != nil {
+		return fmt.Errorf("could not decompress attested enclave key. Cause: %w", err)
+	}
+	signer, err := gethcrypto.SigToPub(keyHash, binding.Signature)
+	if err != nil {
+		return fmt.Errorf("could not recover signer of enclave TLS key. Cause: %w", err)
+	}
+	if gethcrypto.PubkeyToAddress(*signer) != gethcrypto.PubkeyToAddress(*enclaveKey) {
+		return errors.New("enclave TLS key was not signed by the attested enclave key")
+	}
+	return nil
+}
+
+// Generates a new TLS key and a self-signed certificate for it, carrying an extension with the given OID whose value
+// is produced by `bind` from the hash of the key.
+func newBoundCertificate(oid asn1.ObjectIdentifier, bind func(keyHash []byte) ([]byte, error)) (tls.Certificate, error) {
+	tlsKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not generate TLS key. Cause: %w", err)
+	}
+	encodedKey, err := x509.MarshalPKIXPublicKey(&tlsKey.PublicKey)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not encode TLS key. Cause: %w", err)
+	}
+	binding, err := bind(gethcrypto.Keccak256(encodedKey))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(1),
+		Subject:         pkix.Name{CommonName: "obscuro"},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(certificateValidity),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		ExtraExtensions: []pkix.Extension{{Id: oid, Value: binding}},
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &tlsKey.PublicKey, tlsKey)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not create TLS certificate. Cause: %w", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{certificate}, PrivateKey: tlsKey}, nil
+}
+
+// Returns the value of the peer certificate's extension with the given OID, and the hash of the certificate's key. The
+// TLS handshake itself proves that the peer holds the corresponding private key.
+func peerBinding(rawCerts [][]byte, oid asn1.ObjectIdentifier) ([]byte, []byte, error) {
+	if len(rawCerts) != 1 {
+		return nil, nil, fmt.Errorf("expected a single peer certificate, got %d", len(rawCerts))
+	}
+	certificate, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse peer certificate. Cause: %w", err)
+	}
+	for _, extension := range certificate.Extensions {
+		if extension.Id.Equal(oid) {
+			return extension.Value, gethcrypto.Keccak256(certificate.RawSubjectPublicKeyInfo), nil
+		}
+	}
+	return nil, nil, errors.New("peer certificate did not bind its key to an Obscuro identity")
+}
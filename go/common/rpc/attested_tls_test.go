@@ -0,0 +1,123 @@
+package rpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/obscuronet/go-obscuro/go/common"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestAttestedTLSHandshakeSucceedsBetweenHostAndItsEnclave(t *testing.T) {
+	hostKey, enclaveKey := generateKey(t), generateKey(t)
+	hostID := gethcrypto.PubkeyToAddress(hostKey.PublicKey)
+
+	if err := handshake(newHostTLSConfig(t, hostKey, acceptReport), newEnclaveTLSConfig(t, enclaveKey, hostID, hostID)); err != nil {
+		t.Fatalf("expected handshake to succeed. Cause: %s", err)
+	}
+}
+
+func TestAttestedTLSHandshakeFailsForOtherHost(t *testing.T) {
+	hostKey, otherHostKey, enclaveKey := generateKey(t), generateKey(t), generateKey(t)
+	hostID := gethcrypto.PubkeyToAddress(hostKey.PublicKey)
+
+	if err := handshake(newHostTLSConfig(t, otherHostKey, acceptReport), newEnclaveTLSConfig(t, enclaveKey, hostID, hostID)); err == nil {
+		t.Fatal("expected enclave to refuse a host whose key does not match its host ID")
+	}
+}
+
+func TestAttestedTLSHandshakeFailsForEnclaveOfOtherHost(t *testing.T) {
+	hostKey, otherHostKey, enclaveKey := generateKey(t), generateKey(t), generateKey(t)
+	hostID := gethcrypto.PubkeyToAddress(hostKey.PublicKey)
+	otherHostID := gethcrypto.PubkeyToAddress(otherHostKey.PublicKey)
+
+	// The enclave would accept the host, but its attestation report is for another host.
+	if err := handshake(newHostTLSConfig(t, hostKey, acceptReport), newEnclaveTLSConfig(t, enclaveKey, otherHostID, hostID)); err == nil {
+		t.Fatal("expected host to refuse an enclave attested for another host")
+	}
+}
+
+func TestAttestedTLSHandshakeFailsForUnverifiedAttestation(t *testing.T) {
+	hostKey, enclaveKey := generateKey(t), generateKey(t)
+	hostID := gethcrypto.PubkeyToAddress(hostKey.PublicKey)
+	enclaveConfig := newEnclaveTLSConfig(t, enclaveKey, hostID, hostID)
+
+	rejectReport := func(*common.AttestationReport) ([]byte, error) {
+		return nil, errors.New("enclave is not recognised")
+	}
+	if err := handshake(newHostTLSConfig(t, hostKey, rejectReport), enclaveConfig); err == nil {
+		t.Fatal("expected host to refuse an enclave whose attestation report fails verification")
+	}
+
+	// The report is genuine, but it attests to different keys than the ones the enclave presents.
+	otherReportData := func(*common.AttestationReport) ([]byte, error) {
+		return (&common.AttestationReport{Owner: hostID}).IDHash()
+	}
+	if err := handshake(newHostTLSConfig(t, hostKey, otherReportData), enclaveConfig); err == nil {
+		t.Fatal("expected host to refuse an enclave whose attestation report is for other keys")
+	}
+}
+
+// Performs a TLS handshake between the host and enclave with the given configs, returning the first error either side
+// encountered.
+func handshake(hostConfig *tls.Config, enclaveConfig *tls.Config) error {
+	hostConn, enclaveConn := net.Pipe()
+	enclaveErr := make(chan error, 1)
+	go func() {
+		server := tls.Server(enclaveConn, enclaveConfig)
+		enclaveErr <- server.Handshake()
+		server.Close()
+	}()
+
+	client := tls.Client(hostConn, hostConfig)
+	err := client.Handshake()
+	if err == nil {
+		// In TLS 1.3, the enclave only verifies the host's certificate once the host's side of the handshake is
+		// complete, so we read from the connection until the enclave closes it.
+		_, err = client.Read(make([]byte, 1))
+	}
+	hostConn.Close()
+	if serverErr := <-enclaveErr; serverErr != nil {
+		return serverErr
+	}
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// Accepts any attestation report, as the enclave's simulated attestation does.
+func acceptReport(att *common.AttestationReport) ([]byte, error) {
+	return att.IDHash()
+}
+
+func newHostTLSConfig(t *testing.T, hostKey *ecdsa.PrivateKey, verifyReport ReportVerifier) *tls.Config {
+	tlsConfig, err := NewHostTLSConfig(hostKey, gethcrypto.PubkeyToAddress(hostKey.PublicKey), verifyReport)
+	if err != nil {
+		t.Fatalf("could not create host TLS config. Cause: %s", err)
+	}
+	return tlsConfig
+}
+
+func newEnclaveTLSConfig(t *testing.T, enclaveKey *ecdsa.PrivateKey, owner gethcommon.Address, hostID gethcommon.Address) *tls.Config {
+	attestation := &common.AttestationReport{PubKey: gethcrypto.CompressPubkey(&enclaveKey.PublicKey), Owner: owner}
+	tlsConfig, err := NewEnclaveTLSConfig(attestation, enclaveKey, hostID)
+	if err != nil {
+		t.Fatalf("could not create enclave TLS config. Cause: %s", err)
+	}
+	return tlsConfig
+}
+
+func generateKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := gethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("could not generate key. Cause: %s", err)
+	}
+	return key
+}
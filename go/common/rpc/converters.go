@@ -19,7 +19,7 @@ import (
 // Protobuf message classes.
 
 func ToAttestationReportMsg(report *common.AttestationReport) generated.AttestationReportMsg {
-	return generated.AttestationReportMsg{Report: report.Report, PubKey: report.PubKey, Owner: report.Owner.Bytes(), HostAddress: report.HostAddress}
+	return generated.AttestationReportMsg{Report: report.Report, PubKey: report.PubKey, Owner: report.Owner.Bytes(), HostAddress: report.HostAddress, RPCPubKey: report.RPCPubKey}
 }
 
 func FromAttestationReportMsg(msg *generated.AttestationReportMsg) *common.AttestationReport {
@@ -28,34 +28,66 @@ func FromAttestationReportMsg(msg *generated.AttestationReportMsg) *common.Attes
 		PubKey:      msg.PubKey,
 		Owner:       gethcommon.BytesToAddress(msg.Owner),
 		HostAddress: msg.HostAddress,
+		RPCPubKey:   msg.RPCPubKey,
 	}
 }
 
-func ToBlockSubmissionResponseMsg(response *common.BlockSubmissionResponse) (generated.BlockSubmissionResponseMsg, error) {
-	subscribedLogBytes, err := json.Marshal(response.SubscribedLogs)
+func ToEnclaveEventMsg(event *common.EnclaveEvent) (generated.EnclaveEventMsg, error) {
+	subscribedLogBytes, err := json.Marshal(event.SubscribedLogs)
 	if err != nil {
-		return generated.BlockSubmissionResponseMsg{}, fmt.Errorf("could not marshal subscribed logs to JSON. Cause: %w", err)
+		return generated.EnclaveEventMsg{}, fmt.Errorf("could not marshal subscribed logs to JSON. Cause: %w", err)
+	}
+	var producedSecretRotation []byte
+	if event.ProducedSecretRotation != nil {
+		if producedSecretRotation, err = common.EncodeSecretRotation(event.ProducedSecretRotation); err != nil {
+			return generated.EnclaveEventMsg{}, err
+		}
 	}
 
-	producedBatchMsg := ToExtBatchMsg(response.ProducedBatch)
-	producedRollupMsg := ToExtRollupMsg(response.ProducedRollup)
+	producedBatchMsg := ToExtBatchMsg(event.ProducedBatch)
+	producedRollupMsgs := make([]*generated.ExtRollupMsg, len(event.ProducedRollups))
+	for idx, rollup := range event.ProducedRollups {
+		producedRollupMsg := ToExtRollupMsg(rollup)
+		producedRollupMsgs[idx] = &producedRollupMsg
+	}
 
-	return generated.BlockSubmissionResponseMsg{
+	return generated.EnclaveEventMsg{
 		ProducedBatch:           &producedBatchMsg,
-		ProducedRollup:          &producedRollupMsg,
+		ProducedRollups:         producedRollupMsgs,
 		SubscribedLogs:          subscribedLogBytes,
-		ProducedSecretResponses: ToSecretRespMsg(response.ProducedSecretResponses),
+		ProducedSecretResponses: ToSecretRespMsg(event.ProducedSecretResponses),
+		RollupDecision:          toRollupDecisionMsg(event.RollupDecision),
+		ProducedSecretRotation:  producedSecretRotation,
+		Sequence:                event.Sequence,
 	}, nil
 }
 
-func ToBlockSubmissionRejectionMsg(rejectError *common.BlockRejectError) (generated.BlockSubmissionResponseMsg, error) {
-	errMsg := &generated.BlockSubmissionErrorMsg{
+func toRollupDecisionMsg(decision *common.RollupDecision) *generated.RollupDecisionMsg {
+	if decision == nil {
+		return nil
+	}
+	return &generated.RollupDecisionMsg{Publish: decision.Publish, Reason: decision.Reason}
+}
+
+func fromRollupDecisionMsg(msg *generated.RollupDecisionMsg) *common.RollupDecision {
+	if msg == nil {
+		return nil
+	}
+	return &common.RollupDecision{Publish: msg.Publish, Reason: msg.Reason}
+}
+
+func ToBlockSubmissionErrorMsg(rejectError *common.BlockRejectError) *generated.BlockSubmissionErrorMsg {
+	return &generated.BlockSubmissionErrorMsg{
 		Cause:  rejectError.Wrapped.Error(),
 		L1Head: rejectError.L1Head.Bytes(),
 	}
-	return generated.BlockSubmissionResponseMsg{
-		Error: errMsg,
-	}, nil
+}
+
+func FromBlockSubmissionErrorMsg(msg *generated.BlockSubmissionErrorMsg) *common.BlockRejectError {
+	return &common.BlockRejectError{
+		L1Head:  gethcommon.BytesToHash(msg.L1Head),
+		Wrapped: errors.New(msg.Cause),
+	}
 }
 
 func ToSecretRespMsg(responses []*common.ProducedSecretResponse) []*generated.SecretResponseMsg {
@@ -87,22 +119,34 @@ func FromSecretRespMsg(secretResponses []*generated.SecretResponseMsg) []*common
 	return respList
 }
 
-func FromBlockSubmissionResponseMsg(msg *generated.BlockSubmissionResponseMsg) (*common.BlockSubmissionResponse, error) {
-	if msg.Error != nil {
-		return nil, &common.BlockRejectError{
-			L1Head:  gethcommon.BytesToHash(msg.Error.L1Head),
-			Wrapped: errors.New(msg.Error.Cause),
-		}
-	}
+func FromEnclaveEventMsg(msg *generated.EnclaveEventMsg) (*common.EnclaveEvent, error) {
 	var subscribedLogs map[rpc.ID][]byte
 	if err := json.Unmarshal(msg.SubscribedLogs, &subscribedLogs); err != nil {
-		return nil, fmt.Errorf("could not unmarshal subscribed logs from submission response JSON. Cause: %w", err)
+		return nil, fmt.Errorf("could not unmarshal subscribed logs from enclave event JSON. Cause: %w", err)
+	}
+	var producedBatch *common.ExtBatch
+	if msg.ProducedBatch != nil && msg.ProducedBatch.Header != nil {
+		producedBatch = FromExtBatchMsg(msg.ProducedBatch)
 	}
-	return &common.BlockSubmissionResponse{
-		ProducedBatch:           FromExtBatchMsg(msg.ProducedBatch),
-		ProducedRollup:          FromExtRollupMsg(msg.ProducedRollup),
+	producedRollups := make([]*common.ExtRollup, len(msg.ProducedRollups))
+	for idx, rollupMsg := range msg.ProducedRollups {
+		producedRollups[idx] = FromExtRollupMsg(rollupMsg)
+	}
+	var producedSecretRotation *common.SecretRotation
+	if len(msg.ProducedSecretRotation) != 0 {
+		var err error
+		if producedSecretRotation, err = common.DecodeSecretRotation(msg.ProducedSecretRotation); err != nil {
+			return nil, err
+		}
+	}
+	return &common.EnclaveEvent{
+		ProducedBatch:           producedBatch,
+		ProducedRollups:         producedRollups,
 		SubscribedLogs:          subscribedLogs,
 		ProducedSecretResponses: FromSecretRespMsg(msg.ProducedSecretResponses),
+		RollupDecision:          fromRollupDecisionMsg(msg.RollupDecision),
+		ProducedSecretRotation:  producedSecretRotation,
+		Sequence:                msg.Sequence,
 	}, nil
 }
 
@@ -188,6 +232,7 @@ func ToBatchHeaderMsg(header *common.BatchHeader) *generated.BatchHeaderMsg { //
 		BaseFee:                     baseFee,
 		CrossChainMessages:          ToCrossChainMsgs(header.CrossChainMessages),
 		LatestInboundCrossChainHash: header.LatestInboundCrossChainHash.Bytes(),
+		ForkID:                      header.ForkID.Bytes(),
 	}
 
 	if header.LatestInboundCrossChainHeight != nil {
@@ -248,6 +293,7 @@ func FromBatchHeaderMsg(header *generated.BatchHeaderMsg) *common.BatchHeader {
 		CrossChainMessages:            FromCrossChainMsgs(header.CrossChainMessages),
 		LatestInboundCrossChainHash:   gethcommon.BytesToHash(header.LatestInboundCrossChainHash),
 		LatestInboundCrossChainHeight: big.NewInt(0).SetBytes(header.LatestInboundCrossChainHeight),
+		ForkID:                        gethcommon.BytesToHash(header.ForkID),
 	}
 }
 
@@ -364,3 +410,99 @@ func FromRollupHeaderMsg(header *generated.RollupHeaderMsg) *common.RollupHeader
 		LatestInboundCrossChainHeight: big.NewInt(0).SetBytes(header.LatestInboundCrossChainHeight),
 	}
 }
+
+func ToDivergenceReportMsgs(reports []*common.DivergenceReport) []*generated.DivergenceReportMsg {
+	msgs := make([]*generated.DivergenceReportMsg, len(reports))
+	for idx, report := range reports {
+		divergences := make([]string, len(report.Divergences))
+		for i, divergence := range report.Divergences {
+			divergences[i] = string(divergence)
+		}
+		msgs[idx] = &generated.DivergenceReportMsg{
+			BatchHash:                  report.BatchHash.Bytes(),
+			BatchNumber:                report.BatchNumber,
+			ParentHash:                 report.ParentHash.Bytes(),
+			L1Proof:                    report.L1Proof.Bytes(),
+			Sequencer:                  report.Sequencer.Bytes(),
+			Validator:                  report.Validator.Bytes(),
+			Divergences:                divergences,
+			ExpectedStateRoot:          report.ExpectedStateRoot.Bytes(),
+			ActualStateRoot:            report.ActualStateRoot.Bytes(),
+			ExpectedReceiptRoot:        report.ExpectedReceiptRoot.Bytes(),
+			ActualReceiptRoot:          report.ActualReceiptRoot.Bytes(),
+			ExpectedCrossChainMessages: report.ExpectedCrossChainMessages.Bytes(),
+			ActualCrossChainMessages:   report.ActualCrossChainMessages.Bytes(),
+			ActualReceiptHashes:        make([][]byte, len(report.ActualReceiptHashes)),
+			HasFirstDivergentTx:        report.FirstDivergentTx != nil,
+			Signature:                  report.Signature,
+		}
+		for i, receiptHash := range report.ActualReceiptHashes {
+			msgs[idx].ActualReceiptHashes[i] = receiptHash.Bytes()
+		}
+		if report.FirstDivergentTx != nil {
+			msgs[idx].FirstDivergentTx = *report.FirstDivergentTx
+		}
+	}
+	return msgs
+}
+
+func FromDivergenceReportMsgs(msgs []*generated.DivergenceReportMsg) []*common.DivergenceReport {
+	reports := make([]*common.DivergenceReport, len(msgs))
+	for idx, msg := range msgs {
+		divergences := make([]common.DivergenceKind, len(msg.Divergences))
+		for i, divergence := range msg.Divergences {
+			divergences[i] = common.DivergenceKind(divergence)
+		}
+		reports[idx] = &common.DivergenceReport{
+			BatchHash:                  gethcommon.BytesToHash(msg.BatchHash),
+			BatchNumber:                msg.BatchNumber,
+			ParentHash:                 gethcommon.BytesToHash(msg.ParentHash),
+			L1Proof:                    gethcommon.BytesToHash(msg.L1Proof),
+			Sequencer:                  gethcommon.BytesToAddress(msg.Sequencer),
+			Validator:                  gethcommon.BytesToAddress(msg.Validator),
+			Divergences:                divergences,
+			ExpectedStateRoot:          gethcommon.BytesToHash(msg.ExpectedStateRoot),
+			ActualStateRoot:            gethcommon.BytesToHash(msg.ActualStateRoot),
+			ExpectedReceiptRoot:        gethcommon.BytesToHash(msg.ExpectedReceiptRoot),
+			ActualReceiptRoot:          gethcommon.BytesToHash(msg.ActualReceiptRoot),
+			ExpectedCrossChainMessages: gethcommon.BytesToHash(msg.ExpectedCrossChainMessages),
+			ActualCrossChainMessages:   gethcommon.BytesToHash(msg.ActualCrossChainMessages),
+			ActualReceiptHashes:        make([]gethcommon.Hash, len(msg.ActualReceiptHashes)),
+			Signature:                  msg.Signature,
+		}
+		for i, receiptHash := range msg.ActualReceiptHashes {
+			reports[idx].ActualReceiptHashes[i] = gethcommon.BytesToHash(receiptHash)
+		}
+		if msg.HasFirstDivergentTx {
+			firstDivergentTx := msg.FirstDivergentTx
+			reports[idx].FirstDivergentTx = &firstDivergentTx
+		}
+	}
+	return reports
+}
+
+func ToInboundMessageDeliveryMsg(delivery *common.InboundMessageDelivery) *generated.InboundMessageDeliveryMsg {
+	if delivery == nil {
+		return nil
+	}
+	return &generated.InboundMessageDeliveryMsg{
+		MessageHash:     delivery.MessageHash.Bytes(),
+		Status:          string(delivery.Status),
+		SyntheticTxHash: delivery.SyntheticTxHash.Bytes(),
+		BatchNumber:     delivery.BatchNumber,
+		BatchL1Proof:    delivery.BatchL1Proof.Bytes(),
+	}
+}
+
+func FromInboundMessageDeliveryMsg(msg *generated.InboundMessageDeliveryMsg) *common.InboundMessageDelivery {
+	if msg == nil {
+		return nil
+	}
+	return &common.InboundMessageDelivery{
+		MessageHash:     gethcommon.BytesToHash(msg.MessageHash),
+		Status:          common.InboundMessageStatus(msg.Status),
+		SyntheticTxHash: gethcommon.BytesToHash(msg.SyntheticTxHash),
+		BatchNumber:     msg.BatchNumber,
+		BatchL1Proof:    gethcommon.BytesToHash(msg.BatchL1Proof),
+	}
+}
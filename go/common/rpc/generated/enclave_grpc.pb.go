@@ -30,19 +30,24 @@ type EnclaveProtoClient interface {
 	GenerateSecret(ctx context.Context, in *GenerateSecretRequest, opts ...grpc.CallOption) (*GenerateSecretResponse, error)
 	// Init - initialise an enclave with a seed received by another enclave
 	InitEnclave(ctx context.Context, in *InitEnclaveRequest, opts ...grpc.CallOption) (*InitEnclaveResponse, error)
-	// SubmitL1Block - Used for the host to submit blocks to the enclave, these may be:
-	//
-	//	a. historic block - if the enclave is behind and in the process of catching up with the L1 state
-	//	b. the latest block published by the L1, to which the enclave should respond with a rollup
-	//
-	// It is the responsibility of the host to gossip the returned rollup
+	// Ingest - Used for the host to stream L1 blocks, and batches received from the sequencer, into the enclave. The
+	// enclave responds to each of them in order, with the rejection if a block could not be ingested. The blocks may be:
+	//  a. historic block - if the enclave is behind and in the process of catching up with the L1 state
+	//  b. the latest block published by the L1, to which the enclave should respond with a rollup
 	// For good functioning the caller should always submit blocks ordered by height
-	// submitting a block before receiving ancestors of it, will result in it being ignored
-	SubmitL1Block(ctx context.Context, in *SubmitBlockRequest, opts ...grpc.CallOption) (*SubmitBlockResponse, error)
+	// submitting a block before receiving ancestors of it, will result in it being rejected
+	Ingest(ctx context.Context, opts ...grpc.CallOption) (EnclaveProto_IngestClient, error)
+	// StreamEvents - streams the outputs of the enclave to the host: the batches and rollups produced by the sequencer,
+	// the responses to secret requests, and the logs of each new batch for each subscription. It is the responsibility
+	// of the host to distribute the batches and to publish the rollups and secret responses. The enclave buffers its
+	// events until the host acknowledges them via AckEvents, and streams the buffered events following the requested
+	// sequence number before the new ones. The first message is empty, and acknowledges that the stream is open
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (EnclaveProto_StreamEventsClient, error)
+	// AckEvents - acknowledges that the host has handled the enclave's events up to the given sequence number, so that
+	// the enclave no longer buffers them
+	AckEvents(ctx context.Context, in *AckEventsRequest, opts ...grpc.CallOption) (*AckEventsResponse, error)
 	// SubmitTx - user transactions
 	SubmitTx(ctx context.Context, in *SubmitTxRequest, opts ...grpc.CallOption) (*SubmitTxResponse, error)
-	// SubmitBatch submits a batch received from the sequencer for processing.
-	SubmitBatch(ctx context.Context, in *SubmitBatchRequest, opts ...grpc.CallOption) (*SubmitBatchResponse, error)
 	// ExecuteOffChainTransaction - returns the result of executing the smart contract as a user, encrypted with the
 	// viewing key corresponding to the `from` field
 	ExecuteOffChainTransaction(ctx context.Context, in *OffChainRequest, opts ...grpc.CallOption) (*OffChainResponse, error)
@@ -62,14 +67,40 @@ type EnclaveProtoClient interface {
 	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error)
 	// GetCode returns the code stored at the given address in the state for the given rollup height or rollup hash
 	GetCode(ctx context.Context, in *GetCodeRequest, opts ...grpc.CallOption) (*GetCodeResponse, error)
+	// GetPublicStorageAt returns the value of a storage slot that the contract declares public
+	GetPublicStorageAt(ctx context.Context, in *GetPublicStorageAtRequest, opts ...grpc.CallOption) (*GetPublicStorageAtResponse, error)
+	// GetStorageAt returns the value of any storage slot of a contract, encrypted for the contract's deployer
+	GetStorageAt(ctx context.Context, in *GetStorageAtRequest, opts ...grpc.CallOption) (*GetStorageAtResponse, error)
 	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (*SubscribeResponse, error)
 	Unsubscribe(ctx context.Context, in *UnsubscribeRequest, opts ...grpc.CallOption) (*UnsubscribeResponse, error)
 	// EstimateGas returns the estimation of gas used for the given transactions
 	EstimateGas(ctx context.Context, in *EstimateGasRequest, opts ...grpc.CallOption) (*EstimateGasResponse, error)
 	GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (*GetLogsResponse, error)
+	// GetWithdrawalProofs returns the cross-chain messages published by a transaction, and how to claim them on the L1
+	GetWithdrawalProofs(ctx context.Context, in *GetWithdrawalProofsRequest, opts ...grpc.CallOption) (*GetWithdrawalProofsResponse, error)
+	// DebugTraceTransaction returns the trace of a transaction's execution
+	DebugTraceTransaction(ctx context.Context, in *DebugTraceTransactionRequest, opts ...grpc.CallOption) (*DebugTraceResponse, error)
+	// DebugTraceCall returns the trace of a call's execution
+	DebugTraceCall(ctx context.Context, in *DebugTraceCallRequest, opts ...grpc.CallOption) (*DebugTraceResponse, error)
+	// GetInboundMessageRecords returns the audit records of the cross-chain messages sent from the L1
+	GetInboundMessageRecords(ctx context.Context, in *GetInboundMessageRecordsRequest, opts ...grpc.CallOption) (*GetInboundMessageRecordsResponse, error)
+	// GetInboundMessageDelivery returns whether and where a cross-chain message sent from the L1 was delivered
+	GetInboundMessageDelivery(ctx context.Context, in *GetInboundMessageDeliveryRequest, opts ...grpc.CallOption) (*GetInboundMessageDeliveryResponse, error)
 	// HealthCheck returns the health status of enclave + db
 	HealthCheck(ctx context.Context, in *EmptyArgs, opts ...grpc.CallOption) (*HealthCheckResponse, error)
+	// GetDivergenceReports returns the reports of the batches whose re-execution diverged from them
+	GetDivergenceReports(ctx context.Context, in *EmptyArgs, opts ...grpc.CallOption) (*GetDivergenceReportsResponse, error)
+	// ExportSnapshot - returns an encrypted, signed snapshot of the enclave's state at the given batch
+	ExportSnapshot(ctx context.Context, in *ExportSnapshotRequest, opts ...grpc.CallOption) (*ExportSnapshotResponse, error)
+	// ImportSnapshot - resumes from a snapshot exported by another enclave. The snapshot is streamed in chunks, so that
+	// the other calls keep gRPC's default message size limit
+	ImportSnapshot(ctx context.Context, opts ...grpc.CallOption) (EnclaveProto_ImportSnapshotClient, error)
 	CreateRollup(ctx context.Context, in *CreateRollupRequest, opts ...grpc.CallOption) (*CreateRollupResponse, error)
+	// CreateBatch - used by the host to have a sequencer enclave produce a batch on the host's batch interval, rather
+	// than for each L1 block
+	CreateBatch(ctx context.Context, in *CreateBatchRequest, opts ...grpc.CallOption) (*CreateBatchResponse, error)
+	// TimeBasedBatches - returns whether the enclave only produces batches when requested via CreateBatch
+	TimeBasedBatches(ctx context.Context, in *EmptyArgs, opts ...grpc.CallOption) (*TimeBasedBatchesResponse, error)
 }
 
 type enclaveProtoClient struct {
@@ -116,27 +147,81 @@ func (c *enclaveProtoClient) InitEnclave(ctx context.Context, in *InitEnclaveReq
 	return out, nil
 }
 
-func (c *enclaveProtoClient) SubmitL1Block(ctx context.Context, in *SubmitBlockRequest, opts ...grpc.CallOption) (*SubmitBlockResponse, error) {
-	out := new(SubmitBlockResponse)
-	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/SubmitL1Block", in, out, opts...)
+func (c *enclaveProtoClient) Ingest(ctx context.Context, opts ...grpc.CallOption) (EnclaveProto_IngestClient, error) {
+	stream, err := c.cc.NewStream(ctx, &EnclaveProto_ServiceDesc.Streams[0], "/generated.EnclaveProto/Ingest", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	x := &enclaveProtoIngestClient{stream}
+	return x, nil
 }
 
-func (c *enclaveProtoClient) SubmitTx(ctx context.Context, in *SubmitTxRequest, opts ...grpc.CallOption) (*SubmitTxResponse, error) {
-	out := new(SubmitTxResponse)
-	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/SubmitTx", in, out, opts...)
+type EnclaveProto_IngestClient interface {
+	Send(*IngestRequest) error
+	Recv() (*IngestResponse, error)
+	grpc.ClientStream
+}
+
+type enclaveProtoIngestClient struct {
+	grpc.ClientStream
+}
+
+func (x *enclaveProtoIngestClient) Send(m *IngestRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *enclaveProtoIngestClient) Recv() (*IngestResponse, error) {
+	m := new(IngestResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *enclaveProtoClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (EnclaveProto_StreamEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &EnclaveProto_ServiceDesc.Streams[1], "/generated.EnclaveProto/StreamEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &enclaveProtoStreamEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type EnclaveProto_StreamEventsClient interface {
+	Recv() (*EnclaveEventMsg, error)
+	grpc.ClientStream
+}
+
+type enclaveProtoStreamEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *enclaveProtoStreamEventsClient) Recv() (*EnclaveEventMsg, error) {
+	m := new(EnclaveEventMsg)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *enclaveProtoClient) AckEvents(ctx context.Context, in *AckEventsRequest, opts ...grpc.CallOption) (*AckEventsResponse, error) {
+	out := new(AckEventsResponse)
+	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/AckEvents", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *enclaveProtoClient) SubmitBatch(ctx context.Context, in *SubmitBatchRequest, opts ...grpc.CallOption) (*SubmitBatchResponse, error) {
-	out := new(SubmitBatchResponse)
-	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/SubmitBatch", in, out, opts...)
+func (c *enclaveProtoClient) SubmitTx(ctx context.Context, in *SubmitTxRequest, opts ...grpc.CallOption) (*SubmitTxResponse, error) {
+	out := new(SubmitTxResponse)
+	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/SubmitTx", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -215,6 +300,24 @@ func (c *enclaveProtoClient) GetCode(ctx context.Context, in *GetCodeRequest, op
 	return out, nil
 }
 
+func (c *enclaveProtoClient) GetPublicStorageAt(ctx context.Context, in *GetPublicStorageAtRequest, opts ...grpc.CallOption) (*GetPublicStorageAtResponse, error) {
+	out := new(GetPublicStorageAtResponse)
+	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/GetPublicStorageAt", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enclaveProtoClient) GetStorageAt(ctx context.Context, in *GetStorageAtRequest, opts ...grpc.CallOption) (*GetStorageAtResponse, error) {
+	out := new(GetStorageAtResponse)
+	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/GetStorageAt", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *enclaveProtoClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (*SubscribeResponse, error) {
 	out := new(SubscribeResponse)
 	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/Subscribe", in, out, opts...)
@@ -251,6 +354,51 @@ func (c *enclaveProtoClient) GetLogs(ctx context.Context, in *GetLogsRequest, op
 	return out, nil
 }
 
+func (c *enclaveProtoClient) GetWithdrawalProofs(ctx context.Context, in *GetWithdrawalProofsRequest, opts ...grpc.CallOption) (*GetWithdrawalProofsResponse, error) {
+	out := new(GetWithdrawalProofsResponse)
+	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/GetWithdrawalProofs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enclaveProtoClient) DebugTraceTransaction(ctx context.Context, in *DebugTraceTransactionRequest, opts ...grpc.CallOption) (*DebugTraceResponse, error) {
+	out := new(DebugTraceResponse)
+	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/DebugTraceTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enclaveProtoClient) DebugTraceCall(ctx context.Context, in *DebugTraceCallRequest, opts ...grpc.CallOption) (*DebugTraceResponse, error) {
+	out := new(DebugTraceResponse)
+	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/DebugTraceCall", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enclaveProtoClient) GetInboundMessageRecords(ctx context.Context, in *GetInboundMessageRecordsRequest, opts ...grpc.CallOption) (*GetInboundMessageRecordsResponse, error) {
+	out := new(GetInboundMessageRecordsResponse)
+	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/GetInboundMessageRecords", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enclaveProtoClient) GetInboundMessageDelivery(ctx context.Context, in *GetInboundMessageDeliveryRequest, opts ...grpc.CallOption) (*GetInboundMessageDeliveryResponse, error) {
+	out := new(GetInboundMessageDeliveryResponse)
+	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/GetInboundMessageDelivery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *enclaveProtoClient) HealthCheck(ctx context.Context, in *EmptyArgs, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
 	out := new(HealthCheckResponse)
 	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/HealthCheck", in, out, opts...)
@@ -260,6 +408,58 @@ func (c *enclaveProtoClient) HealthCheck(ctx context.Context, in *EmptyArgs, opt
 	return out, nil
 }
 
+func (c *enclaveProtoClient) GetDivergenceReports(ctx context.Context, in *EmptyArgs, opts ...grpc.CallOption) (*GetDivergenceReportsResponse, error) {
+	out := new(GetDivergenceReportsResponse)
+	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/GetDivergenceReports", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enclaveProtoClient) ExportSnapshot(ctx context.Context, in *ExportSnapshotRequest, opts ...grpc.CallOption) (*ExportSnapshotResponse, error) {
+	out := new(ExportSnapshotResponse)
+	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/ExportSnapshot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enclaveProtoClient) ImportSnapshot(ctx context.Context, opts ...grpc.CallOption) (EnclaveProto_ImportSnapshotClient, error) {
+	stream, err := c.cc.NewStream(ctx, &EnclaveProto_ServiceDesc.Streams[2], "/generated.EnclaveProto/ImportSnapshot", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &enclaveProtoImportSnapshotClient{stream}
+	return x, nil
+}
+
+type EnclaveProto_ImportSnapshotClient interface {
+	Send(*ImportSnapshotRequest) error
+	CloseAndRecv() (*ImportSnapshotResponse, error)
+	grpc.ClientStream
+}
+
+type enclaveProtoImportSnapshotClient struct {
+	grpc.ClientStream
+}
+
+func (x *enclaveProtoImportSnapshotClient) Send(m *ImportSnapshotRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *enclaveProtoImportSnapshotClient) CloseAndRecv() (*ImportSnapshotResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportSnapshotResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *enclaveProtoClient) CreateRollup(ctx context.Context, in *CreateRollupRequest, opts ...grpc.CallOption) (*CreateRollupResponse, error) {
 	out := new(CreateRollupResponse)
 	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/CreateRollup", in, out, opts...)
@@ -269,6 +469,24 @@ func (c *enclaveProtoClient) CreateRollup(ctx context.Context, in *CreateRollupR
 	return out, nil
 }
 
+func (c *enclaveProtoClient) CreateBatch(ctx context.Context, in *CreateBatchRequest, opts ...grpc.CallOption) (*CreateBatchResponse, error) {
+	out := new(CreateBatchResponse)
+	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/CreateBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enclaveProtoClient) TimeBasedBatches(ctx context.Context, in *EmptyArgs, opts ...grpc.CallOption) (*TimeBasedBatchesResponse, error) {
+	out := new(TimeBasedBatchesResponse)
+	err := c.cc.Invoke(ctx, "/generated.EnclaveProto/TimeBasedBatches", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EnclaveProtoServer is the server API for EnclaveProto service.
 // All implementations must embed UnimplementedEnclaveProtoServer
 // for forward compatibility
@@ -281,19 +499,24 @@ type EnclaveProtoServer interface {
 	GenerateSecret(context.Context, *GenerateSecretRequest) (*GenerateSecretResponse, error)
 	// Init - initialise an enclave with a seed received by another enclave
 	InitEnclave(context.Context, *InitEnclaveRequest) (*InitEnclaveResponse, error)
-	// SubmitL1Block - Used for the host to submit blocks to the enclave, these may be:
-	//
-	//	a. historic block - if the enclave is behind and in the process of catching up with the L1 state
-	//	b. the latest block published by the L1, to which the enclave should respond with a rollup
-	//
-	// It is the responsibility of the host to gossip the returned rollup
+	// Ingest - Used for the host to stream L1 blocks, and batches received from the sequencer, into the enclave. The
+	// enclave responds to each of them in order, with the rejection if a block could not be ingested. The blocks may be:
+	//  a. historic block - if the enclave is behind and in the process of catching up with the L1 state
+	//  b. the latest block published by the L1, to which the enclave should respond with a rollup
 	// For good functioning the caller should always submit blocks ordered by height
-	// submitting a block before receiving ancestors of it, will result in it being ignored
-	SubmitL1Block(context.Context, *SubmitBlockRequest) (*SubmitBlockResponse, error)
+	// submitting a block before receiving ancestors of it, will result in it being rejected
+	Ingest(EnclaveProto_IngestServer) error
+	// StreamEvents - streams the outputs of the enclave to the host: the batches and rollups produced by the sequencer,
+	// the responses to secret requests, and the logs of each new batch for each subscription. It is the responsibility
+	// of the host to distribute the batches and to publish the rollups and secret responses. The enclave buffers its
+	// events until the host acknowledges them via AckEvents, and streams the buffered events following the requested
+	// sequence number before the new ones. The first message is empty, and acknowledges that the stream is open
+	StreamEvents(*StreamEventsRequest, EnclaveProto_StreamEventsServer) error
+	// AckEvents - acknowledges that the host has handled the enclave's events up to the given sequence number, so that
+	// the enclave no longer buffers them
+	AckEvents(context.Context, *AckEventsRequest) (*AckEventsResponse, error)
 	// SubmitTx - user transactions
 	SubmitTx(context.Context, *SubmitTxRequest) (*SubmitTxResponse, error)
-	// SubmitBatch submits a batch received from the sequencer for processing.
-	SubmitBatch(context.Context, *SubmitBatchRequest) (*SubmitBatchResponse, error)
 	// ExecuteOffChainTransaction - returns the result of executing the smart contract as a user, encrypted with the
 	// viewing key corresponding to the `from` field
 	ExecuteOffChainTransaction(context.Context, *OffChainRequest) (*OffChainResponse, error)
@@ -313,14 +536,40 @@ type EnclaveProtoServer interface {
 	GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error)
 	// GetCode returns the code stored at the given address in the state for the given rollup height or rollup hash
 	GetCode(context.Context, *GetCodeRequest) (*GetCodeResponse, error)
+	// GetPublicStorageAt returns the value of a storage slot that the contract declares public
+	GetPublicStorageAt(context.Context, *GetPublicStorageAtRequest) (*GetPublicStorageAtResponse, error)
+	// GetStorageAt returns the value of any storage slot of a contract, encrypted for the contract's deployer
+	GetStorageAt(context.Context, *GetStorageAtRequest) (*GetStorageAtResponse, error)
 	Subscribe(context.Context, *SubscribeRequest) (*SubscribeResponse, error)
 	Unsubscribe(context.Context, *UnsubscribeRequest) (*UnsubscribeResponse, error)
 	// EstimateGas returns the estimation of gas used for the given transactions
 	EstimateGas(context.Context, *EstimateGasRequest) (*EstimateGasResponse, error)
 	GetLogs(context.Context, *GetLogsRequest) (*GetLogsResponse, error)
+	// GetWithdrawalProofs returns the cross-chain messages published by a transaction, and how to claim them on the L1
+	GetWithdrawalProofs(context.Context, *GetWithdrawalProofsRequest) (*GetWithdrawalProofsResponse, error)
+	// DebugTraceTransaction returns the trace of a transaction's execution
+	DebugTraceTransaction(context.Context, *DebugTraceTransactionRequest) (*DebugTraceResponse, error)
+	// DebugTraceCall returns the trace of a call's execution
+	DebugTraceCall(context.Context, *DebugTraceCallRequest) (*DebugTraceResponse, error)
+	// GetInboundMessageRecords returns the audit records of the cross-chain messages sent from the L1
+	GetInboundMessageRecords(context.Context, *GetInboundMessageRecordsRequest) (*GetInboundMessageRecordsResponse, error)
+	// GetInboundMessageDelivery returns whether and where a cross-chain message sent from the L1 was delivered
+	GetInboundMessageDelivery(context.Context, *GetInboundMessageDeliveryRequest) (*GetInboundMessageDeliveryResponse, error)
 	// HealthCheck returns the health status of enclave + db
 	HealthCheck(context.Context, *EmptyArgs) (*HealthCheckResponse, error)
+	// GetDivergenceReports returns the reports of the batches whose re-execution diverged from them
+	GetDivergenceReports(context.Context, *EmptyArgs) (*GetDivergenceReportsResponse, error)
+	// ExportSnapshot - returns an encrypted, signed snapshot of the enclave's state at the given batch
+	ExportSnapshot(context.Context, *ExportSnapshotRequest) (*ExportSnapshotResponse, error)
+	// ImportSnapshot - resumes from a snapshot exported by another enclave. The snapshot is streamed in chunks, so that
+	// the other calls keep gRPC's default message size limit
+	ImportSnapshot(EnclaveProto_ImportSnapshotServer) error
 	CreateRollup(context.Context, *CreateRollupRequest) (*CreateRollupResponse, error)
+	// CreateBatch - used by the host to have a sequencer enclave produce a batch on the host's batch interval, rather
+	// than for each L1 block
+	CreateBatch(context.Context, *CreateBatchRequest) (*CreateBatchResponse, error)
+	// TimeBasedBatches - returns whether the enclave only produces batches when requested via CreateBatch
+	TimeBasedBatches(context.Context, *EmptyArgs) (*TimeBasedBatchesResponse, error)
 	mustEmbedUnimplementedEnclaveProtoServer()
 }
 
@@ -340,15 +589,18 @@ func (UnimplementedEnclaveProtoServer) GenerateSecret(context.Context, *Generate
 func (UnimplementedEnclaveProtoServer) InitEnclave(context.Context, *InitEnclaveRequest) (*InitEnclaveResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method InitEnclave not implemented")
 }
-func (UnimplementedEnclaveProtoServer) SubmitL1Block(context.Context, *SubmitBlockRequest) (*SubmitBlockResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method SubmitL1Block not implemented")
+func (UnimplementedEnclaveProtoServer) Ingest(EnclaveProto_IngestServer) error {
+	return status.Errorf(codes.Unimplemented, "method Ingest not implemented")
+}
+func (UnimplementedEnclaveProtoServer) StreamEvents(*StreamEventsRequest, EnclaveProto_StreamEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedEnclaveProtoServer) AckEvents(context.Context, *AckEventsRequest) (*AckEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AckEvents not implemented")
 }
 func (UnimplementedEnclaveProtoServer) SubmitTx(context.Context, *SubmitTxRequest) (*SubmitTxResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SubmitTx not implemented")
 }
-func (UnimplementedEnclaveProtoServer) SubmitBatch(context.Context, *SubmitBatchRequest) (*SubmitBatchResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method SubmitBatch not implemented")
-}
 func (UnimplementedEnclaveProtoServer) ExecuteOffChainTransaction(context.Context, *OffChainRequest) (*OffChainResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ExecuteOffChainTransaction not implemented")
 }
@@ -373,6 +625,12 @@ func (UnimplementedEnclaveProtoServer) GetBalance(context.Context, *GetBalanceRe
 func (UnimplementedEnclaveProtoServer) GetCode(context.Context, *GetCodeRequest) (*GetCodeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetCode not implemented")
 }
+func (UnimplementedEnclaveProtoServer) GetPublicStorageAt(context.Context, *GetPublicStorageAtRequest) (*GetPublicStorageAtResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPublicStorageAt not implemented")
+}
+func (UnimplementedEnclaveProtoServer) GetStorageAt(context.Context, *GetStorageAtRequest) (*GetStorageAtResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStorageAt not implemented")
+}
 func (UnimplementedEnclaveProtoServer) Subscribe(context.Context, *SubscribeRequest) (*SubscribeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
 }
@@ -385,12 +643,42 @@ func (UnimplementedEnclaveProtoServer) EstimateGas(context.Context, *EstimateGas
 func (UnimplementedEnclaveProtoServer) GetLogs(context.Context, *GetLogsRequest) (*GetLogsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLogs not implemented")
 }
+func (UnimplementedEnclaveProtoServer) GetWithdrawalProofs(context.Context, *GetWithdrawalProofsRequest) (*GetWithdrawalProofsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWithdrawalProofs not implemented")
+}
+func (UnimplementedEnclaveProtoServer) DebugTraceTransaction(context.Context, *DebugTraceTransactionRequest) (*DebugTraceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DebugTraceTransaction not implemented")
+}
+func (UnimplementedEnclaveProtoServer) DebugTraceCall(context.Context, *DebugTraceCallRequest) (*DebugTraceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DebugTraceCall not implemented")
+}
+func (UnimplementedEnclaveProtoServer) GetInboundMessageRecords(context.Context, *GetInboundMessageRecordsRequest) (*GetInboundMessageRecordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInboundMessageRecords not implemented")
+}
+func (UnimplementedEnclaveProtoServer) GetInboundMessageDelivery(context.Context, *GetInboundMessageDeliveryRequest) (*GetInboundMessageDeliveryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInboundMessageDelivery not implemented")
+}
 func (UnimplementedEnclaveProtoServer) HealthCheck(context.Context, *EmptyArgs) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
+func (UnimplementedEnclaveProtoServer) GetDivergenceReports(context.Context, *EmptyArgs) (*GetDivergenceReportsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDivergenceReports not implemented")
+}
+func (UnimplementedEnclaveProtoServer) ExportSnapshot(context.Context, *ExportSnapshotRequest) (*ExportSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportSnapshot not implemented")
+}
+func (UnimplementedEnclaveProtoServer) ImportSnapshot(EnclaveProto_ImportSnapshotServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportSnapshot not implemented")
+}
 func (UnimplementedEnclaveProtoServer) CreateRollup(context.Context, *CreateRollupRequest) (*CreateRollupResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateRollup not implemented")
 }
+func (UnimplementedEnclaveProtoServer) CreateBatch(context.Context, *CreateBatchRequest) (*CreateBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBatch not implemented")
+}
+func (UnimplementedEnclaveProtoServer) TimeBasedBatches(context.Context, *EmptyArgs) (*TimeBasedBatchesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TimeBasedBatches not implemented")
+}
 func (UnimplementedEnclaveProtoServer) mustEmbedUnimplementedEnclaveProtoServer() {}
 
 // UnsafeEnclaveProtoServer may be embedded to opt out of forward compatibility for this service.
@@ -476,56 +764,85 @@ func _EnclaveProto_InitEnclave_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
-func _EnclaveProto_SubmitL1Block_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SubmitBlockRequest)
-	if err := dec(in); err != nil {
+func _EnclaveProto_Ingest_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EnclaveProtoServer).Ingest(&enclaveProtoIngestServer{stream})
+}
+
+type EnclaveProto_IngestServer interface {
+	Send(*IngestResponse) error
+	Recv() (*IngestRequest, error)
+	grpc.ServerStream
+}
+
+type enclaveProtoIngestServer struct {
+	grpc.ServerStream
+}
+
+func (x *enclaveProtoIngestServer) Send(m *IngestResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *enclaveProtoIngestServer) Recv() (*IngestRequest, error) {
+	m := new(IngestRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
-	if interceptor == nil {
-		return srv.(EnclaveProtoServer).SubmitL1Block(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/generated.EnclaveProto/SubmitL1Block",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(EnclaveProtoServer).SubmitL1Block(ctx, req.(*SubmitBlockRequest))
+	return m, nil
+}
+
+func _EnclaveProto_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
 	}
-	return interceptor(ctx, in, info, handler)
+	return srv.(EnclaveProtoServer).StreamEvents(m, &enclaveProtoStreamEventsServer{stream})
 }
 
-func _EnclaveProto_SubmitTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SubmitTxRequest)
+type EnclaveProto_StreamEventsServer interface {
+	Send(*EnclaveEventMsg) error
+	grpc.ServerStream
+}
+
+type enclaveProtoStreamEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *enclaveProtoStreamEventsServer) Send(m *EnclaveEventMsg) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _EnclaveProto_AckEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AckEventsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(EnclaveProtoServer).SubmitTx(ctx, in)
+		return srv.(EnclaveProtoServer).AckEvents(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/generated.EnclaveProto/SubmitTx",
+		FullMethod: "/generated.EnclaveProto/AckEvents",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(EnclaveProtoServer).SubmitTx(ctx, req.(*SubmitTxRequest))
+		return srv.(EnclaveProtoServer).AckEvents(ctx, req.(*AckEventsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _EnclaveProto_SubmitBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SubmitBatchRequest)
+func _EnclaveProto_SubmitTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitTxRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(EnclaveProtoServer).SubmitBatch(ctx, in)
+		return srv.(EnclaveProtoServer).SubmitTx(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/generated.EnclaveProto/SubmitBatch",
+		FullMethod: "/generated.EnclaveProto/SubmitTx",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(EnclaveProtoServer).SubmitBatch(ctx, req.(*SubmitBatchRequest))
+		return srv.(EnclaveProtoServer).SubmitTx(ctx, req.(*SubmitTxRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -674,6 +991,42 @@ func _EnclaveProto_GetCode_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EnclaveProto_GetPublicStorageAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPublicStorageAtRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnclaveProtoServer).GetPublicStorageAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/generated.EnclaveProto/GetPublicStorageAt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnclaveProtoServer).GetPublicStorageAt(ctx, req.(*GetPublicStorageAtRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnclaveProto_GetStorageAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStorageAtRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnclaveProtoServer).GetStorageAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/generated.EnclaveProto/GetStorageAt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnclaveProtoServer).GetStorageAt(ctx, req.(*GetStorageAtRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _EnclaveProto_Subscribe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SubscribeRequest)
 	if err := dec(in); err != nil {
@@ -746,6 +1099,96 @@ func _EnclaveProto_GetLogs_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EnclaveProto_GetWithdrawalProofs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWithdrawalProofsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnclaveProtoServer).GetWithdrawalProofs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/generated.EnclaveProto/GetWithdrawalProofs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnclaveProtoServer).GetWithdrawalProofs(ctx, req.(*GetWithdrawalProofsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnclaveProto_DebugTraceTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DebugTraceTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnclaveProtoServer).DebugTraceTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/generated.EnclaveProto/DebugTraceTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnclaveProtoServer).DebugTraceTransaction(ctx, req.(*DebugTraceTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnclaveProto_DebugTraceCall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DebugTraceCallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnclaveProtoServer).DebugTraceCall(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/generated.EnclaveProto/DebugTraceCall",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnclaveProtoServer).DebugTraceCall(ctx, req.(*DebugTraceCallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnclaveProto_GetInboundMessageRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInboundMessageRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnclaveProtoServer).GetInboundMessageRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/generated.EnclaveProto/GetInboundMessageRecords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnclaveProtoServer).GetInboundMessageRecords(ctx, req.(*GetInboundMessageRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnclaveProto_GetInboundMessageDelivery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInboundMessageDeliveryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnclaveProtoServer).GetInboundMessageDelivery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/generated.EnclaveProto/GetInboundMessageDelivery",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnclaveProtoServer).GetInboundMessageDelivery(ctx, req.(*GetInboundMessageDeliveryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _EnclaveProto_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(EmptyArgs)
 	if err := dec(in); err != nil {
@@ -764,6 +1207,68 @@ func _EnclaveProto_HealthCheck_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EnclaveProto_GetDivergenceReports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmptyArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnclaveProtoServer).GetDivergenceReports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/generated.EnclaveProto/GetDivergenceReports",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnclaveProtoServer).GetDivergenceReports(ctx, req.(*EmptyArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnclaveProto_ExportSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnclaveProtoServer).ExportSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/generated.EnclaveProto/ExportSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnclaveProtoServer).ExportSnapshot(ctx, req.(*ExportSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnclaveProto_ImportSnapshot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EnclaveProtoServer).ImportSnapshot(&enclaveProtoImportSnapshotServer{stream})
+}
+
+type EnclaveProto_ImportSnapshotServer interface {
+	SendAndClose(*ImportSnapshotResponse) error
+	Recv() (*ImportSnapshotRequest, error)
+	grpc.ServerStream
+}
+
+type enclaveProtoImportSnapshotServer struct {
+	grpc.ServerStream
+}
+
+func (x *enclaveProtoImportSnapshotServer) SendAndClose(m *ImportSnapshotResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *enclaveProtoImportSnapshotServer) Recv() (*ImportSnapshotRequest, error) {
+	m := new(ImportSnapshotRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _EnclaveProto_CreateRollup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateRollupRequest)
 	if err := dec(in); err != nil {
@@ -782,6 +1287,42 @@ func _EnclaveProto_CreateRollup_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EnclaveProto_CreateBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnclaveProtoServer).CreateBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/generated.EnclaveProto/CreateBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnclaveProtoServer).CreateBatch(ctx, req.(*CreateBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnclaveProto_TimeBasedBatches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmptyArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnclaveProtoServer).TimeBasedBatches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/generated.EnclaveProto/TimeBasedBatches",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnclaveProtoServer).TimeBasedBatches(ctx, req.(*EmptyArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // EnclaveProto_ServiceDesc is the grpc.ServiceDesc for EnclaveProto service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -806,17 +1347,13 @@ var EnclaveProto_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _EnclaveProto_InitEnclave_Handler,
 		},
 		{
-			MethodName: "SubmitL1Block",
-			Handler:    _EnclaveProto_SubmitL1Block_Handler,
+			MethodName: "AckEvents",
+			Handler:    _EnclaveProto_AckEvents_Handler,
 		},
 		{
 			MethodName: "SubmitTx",
 			Handler:    _EnclaveProto_SubmitTx_Handler,
 		},
-		{
-			MethodName: "SubmitBatch",
-			Handler:    _EnclaveProto_SubmitBatch_Handler,
-		},
 		{
 			MethodName: "ExecuteOffChainTransaction",
 			Handler:    _EnclaveProto_ExecuteOffChainTransaction_Handler,
@@ -849,6 +1386,14 @@ var EnclaveProto_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetCode",
 			Handler:    _EnclaveProto_GetCode_Handler,
 		},
+		{
+			MethodName: "GetPublicStorageAt",
+			Handler:    _EnclaveProto_GetPublicStorageAt_Handler,
+		},
+		{
+			MethodName: "GetStorageAt",
+			Handler:    _EnclaveProto_GetStorageAt_Handler,
+		},
 		{
 			MethodName: "Subscribe",
 			Handler:    _EnclaveProto_Subscribe_Handler,
@@ -865,15 +1410,68 @@ var EnclaveProto_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetLogs",
 			Handler:    _EnclaveProto_GetLogs_Handler,
 		},
+		{
+			MethodName: "GetWithdrawalProofs",
+			Handler:    _EnclaveProto_GetWithdrawalProofs_Handler,
+		},
+		{
+			MethodName: "DebugTraceTransaction",
+			Handler:    _EnclaveProto_DebugTraceTransaction_Handler,
+		},
+		{
+			MethodName: "DebugTraceCall",
+			Handler:    _EnclaveProto_DebugTraceCall_Handler,
+		},
+		{
+			MethodName: "GetInboundMessageRecords",
+			Handler:    _EnclaveProto_GetInboundMessageRecords_Handler,
+		},
+		{
+			MethodName: "GetInboundMessageDelivery",
+			Handler:    _EnclaveProto_GetInboundMessageDelivery_Handler,
+		},
 		{
 			MethodName: "HealthCheck",
 			Handler:    _EnclaveProto_HealthCheck_Handler,
 		},
+		{
+			MethodName: "GetDivergenceReports",
+			Handler:    _EnclaveProto_GetDivergenceReports_Handler,
+		},
+		{
+			MethodName: "ExportSnapshot",
+			Handler:    _EnclaveProto_ExportSnapshot_Handler,
+		},
 		{
 			MethodName: "CreateRollup",
 			Handler:    _EnclaveProto_CreateRollup_Handler,
 		},
+		{
+			MethodName: "CreateBatch",
+			Handler:    _EnclaveProto_CreateBatch_Handler,
+		},
+		{
+			MethodName: "TimeBasedBatches",
+			Handler:    _EnclaveProto_TimeBasedBatches_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Ingest",
+			Handler:       _EnclaveProto_Ingest_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _EnclaveProto_StreamEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportSnapshot",
+			Handler:       _EnclaveProto_ImportSnapshot_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "enclave.proto",
 }
@@ -0,0 +1,9 @@
+package rpc
+
+const (
+	// MaxSnapshotSize is the maximum size of the state snapshots exchanged between the host and the enclave, which are
+	// larger than gRPC's default message size limit of 4MB.
+	MaxSnapshotSize = 1 << 30
+	// SnapshotChunkSize is the size of the chunks in which a snapshot is streamed to the enclave for import.
+	SnapshotChunkSize = 1 << 20
+)
@@ -0,0 +1,84 @@
+package common
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// SecretRotation distributes the network secret of a new epoch. The secret is generated by the sequencer's enclave,
+// encrypted for the enclave of each node that is authorised at the time, and published in the management contract.
+type SecretRotation struct {
+	Epoch   uint64
+	Secrets []*EpochSecret
+	// Signed with the attested key of the enclave that generated the secret.
+	Signature []byte
+}
+
+// EpochSecret is the secret of an epoch, encrypted with the attested key of the recipient's enclave.
+type EpochSecret struct {
+	RecipientID gethcommon.Address
+	Secret      EncryptedSharedEnclaveSecret
+}
+
+// Hash returns the hash of the rotation's contents, which is signed by the enclave that generated the secret.
+func (r *SecretRotation) Hash() (gethcommon.Hash, error) {
+	encoded, err := rlp.EncodeToBytes([]interface{}{r.Epoch, r.Secrets})
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("could not encode secret rotation. Cause: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// Sign signs the rotation with the attested key of the enclave that generated the secret.
+func (r *SecretRotation) Sign(enclaveKey *ecdsa.PrivateKey) error {
+	hash, err := r.Hash()
+	if err != nil {
+		return err
+	}
+	r.Signature, err = crypto.Sign(hash.Bytes(), enclaveKey)
+	if err != nil {
+		return fmt.Errorf("could not sign secret rotation. Cause: %w", err)
+	}
+	return nil
+}
+
+// Signer returns the public key that signed the rotation.
+func (r *SecretRotation) Signer() (*ecdsa.PublicKey, error) {
+	hash, err := r.Hash()
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := crypto.SigToPub(hash.Bytes(), r.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("could not recover signer of secret rotation. Cause: %w", err)
+	}
+	return publicKey, nil
+}
+
+// SecretFor returns the encrypted secret for the given recipient, or nil if the recipient was not authorised.
+func (r *SecretRotation) SecretFor(recipientID gethcommon.Address) EncryptedSharedEnclaveSecret {
+	for _, secret := range r.Secrets {
+		if secret.RecipientID == recipientID {
+			return secret.Secret
+		}
+	}
+	return nil
+}
+
+// EncodeSecretRotation returns the rotation in the format published in the management contract.
+func EncodeSecretRotation(rotation *SecretRotation) ([]byte, error) {
+	return rlp.EncodeToBytes(rotation)
+}
+
+// DecodeSecretRotation decodes a rotation published in the management contract.
+func DecodeSecretRotation(encoded []byte) (*SecretRotation, error) {
+	rotation := new(SecretRotation)
+	if err := rlp.DecodeBytes(encoded, rotation); err != nil {
+		return nil, fmt.Errorf("could not decode secret rotation. Cause: %w", err)
+	}
+	return rotation, nil
+}
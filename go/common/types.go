@@ -2,6 +2,8 @@ package common
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -45,17 +47,26 @@ type (
 	EncryptedParamsGetTxCount      []byte // As above, but for an RPC getTransactionCount request.
 	EncryptedParamsEstimateGas     []byte // As above, but for an RPC estimateGas request.
 	EncryptedParamsGetLogs         []byte // As above, but for an RPC getLogs request.
-
-	EncryptedResponseGetBalance   []byte // The response for an RPC getBalance request, as a JSON object encrypted with the viewing key of the user.
-	EncryptedResponseCall         []byte // As above, but for an RPC call request.
-	EncryptedResponseGetTxReceipt []byte // As above, but for an RPC getTransactionReceipt request.
-	EncryptedResponseSendRawTx    []byte // As above, but for an RPC sendRawTransaction request.
-	EncryptedResponseGetTxByHash  []byte // As above, but for an RPC getTransactionByHash request.
-	EncryptedResponseGetTxCount   []byte // As above, but for an RPC getTransactionCount request.
-	EncryptedLogSubscription      []byte // As above, but for a log subscription request.
-	EncryptedLogs                 []byte // As above, but for a log subscription response.
-	EncryptedResponseEstimateGas  []byte // As above, but for an RPC estimateGas response.
-	EncryptedResponseGetLogs      []byte // As above, but for an RPC getLogs request.
+	EncryptedParamsGetWithdrawals  []byte // As above, but for an RPC getWithdrawalProofs request.
+	EncryptedParamsGetInboundMsgs  []byte // As above, but for an RPC getInboundMessageRecords request.
+	EncryptedParamsTraceTx         []byte // As above, but for an RPC debug_traceTransaction request.
+	EncryptedParamsTraceCall       []byte // As above, but for an RPC debug_traceCall request.
+	EncryptedParamsGetStorageAt    []byte // As above, but for an RPC obscuro_getStorageAt request.
+
+	EncryptedResponseGetBalance     []byte // The response for an RPC getBalance request, as a JSON object encrypted with the viewing key of the user.
+	EncryptedResponseCall           []byte // As above, but for an RPC call request.
+	EncryptedResponseGetTxReceipt   []byte // As above, but for an RPC getTransactionReceipt request.
+	EncryptedResponseSendRawTx      []byte // As above, but for an RPC sendRawTransaction request.
+	EncryptedResponseGetTxByHash    []byte // As above, but for an RPC getTransactionByHash request.
+	EncryptedResponseGetTxCount     []byte // As above, but for an RPC getTransactionCount request.
+	EncryptedLogSubscription        []byte // As above, but for a log subscription request.
+	EncryptedLogs                   []byte // As above, but for a log subscription response.
+	EncryptedResponseEstimateGas    []byte // As above, but for an RPC estimateGas response.
+	EncryptedResponseGetLogs        []byte // As above, but for an RPC getLogs request.
+	EncryptedResponseGetWithdrawals []byte // As above, but for an RPC getWithdrawalProofs request.
+	EncryptedResponseGetInboundMsgs []byte // As above, but for an RPC getInboundMessageRecords request.
+	EncryptedResponseTrace          []byte // As above, but for an RPC debug_traceTransaction or debug_traceCall request.
+	EncryptedResponseGetStorageAt   []byte // As above, but for an RPC obscuro_getStorageAt request.
 
 	Nonce               = uint64
 	EncodedRollup       []byte
@@ -76,11 +87,50 @@ type AttestationReport struct {
 	PubKey      []byte         // a public key that can be used to send encrypted data back to the TEE securely (should only be used once Report has been verified)
 	Owner       common.Address // address identifying the owner of the TEE which signed this report, can also be verified from the encrypted Report data
 	HostAddress string         // the IP address on which the host can be contacted by other Obscuro hosts for peer-to-peer communication
+	RPCPubKey   []byte         // the public key that clients use to encrypt their RPC requests to the enclave
+}
+
+// attestationIDData is the identifying data whose hash is embedded in the signed attestation report
+type attestationIDData struct {
+	Owner       common.Address
+	PubKey      []byte
+	HostAddress string
+	RPCPubKey   []byte
+}
+
+// IDHash returns a hash of the identifying data of the report. The enclave includes this hash in the signed report, so
+// that anyone verifying the report can check that the keys and host address were not tampered with.
+func (a *AttestationReport) IDHash() ([]byte, error) {
+	idJSON, err := json.Marshal(attestationIDData{
+		Owner:       a.Owner,
+		PubKey:      a.PubKey,
+		HostAddress: a.HostAddress,
+		RPCPubKey:   a.RPCPubKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to format ID data as JSON. Cause: %w", err)
+	}
+	hash := sha256.Sum256(idJSON)
+	return hash[:], nil
+}
+
+// VerifyIdentity checks that the data embedded in the verified report is the hash of the report's identifying data.
+func (a *AttestationReport) VerifyIdentity(data []byte) error {
+	expectedIDHash, err := a.IDHash()
+	if err != nil {
+		return fmt.Errorf("failed to create ID data to check attestation report with owner: %s. Cause: %w", a.Owner, err)
+	}
+	// we trim the actual data because data extracted from the verified attestation is always 64 bytes long (padded with zeroes at the end)
+	if len(data) < len(expectedIDHash) || !bytes.Equal(expectedIDHash, data[:len(expectedIDHash)]) {
+		return fmt.Errorf("failed to verify hash for attestation report with owner: %s", a.Owner)
+	}
+	return nil
 }
 
 type (
 	EncryptedSharedEnclaveSecret []byte
 	EncodedAttestationReport     []byte
+	EncryptedSnapshot            []byte
 )
 
 // BlockAndReceipts - a structure that contains a fuller view of a block. It allows iterating over the
@@ -0,0 +1,40 @@
+package common
+
+import (
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// WithdrawalStatus is the progress of a cross-chain message sent from the L2 towards finality on the L1.
+type WithdrawalStatus string
+
+const (
+	// WithdrawalPending means the batch containing the message has not been rolled up on the canonical L1 chain yet.
+	WithdrawalPending WithdrawalStatus = "pending"
+	// WithdrawalPublished means the message has been pushed to the L1 message bus, but is not final yet.
+	WithdrawalPublished WithdrawalStatus = "published"
+	// WithdrawalFinal means the message can be consumed on the L1.
+	WithdrawalFinal WithdrawalStatus = "final"
+)
+
+// WithdrawalProof describes a cross-chain message published by an L2 transaction, and everything needed to claim it
+// on the L1. The rollup, L1 and finality fields are only set once the message has been published on the L1.
+type WithdrawalProof struct {
+	Message     CrossChainMessage `json:"message"`
+	MessageHash gethcommon.Hash   `json:"messageHash"` // The hash under which the L1 message bus stores the message
+	Status      WithdrawalStatus  `json:"status"`
+
+	TxHash      gethcommon.Hash `json:"txHash"`
+	BatchHash   gethcommon.Hash `json:"batchHash"`
+	BatchNumber *hexutil.Big    `json:"batchNumber"`
+
+	RollupHash     *gethcommon.Hash `json:"rollupHash"`
+	RollupNumber   *hexutil.Big     `json:"rollupNumber"`
+	L1BlockHash    *gethcommon.Hash `json:"l1BlockHash"`
+	L1BlockNumber  *hexutil.Big     `json:"l1BlockNumber"`
+	TimeOfFinality *hexutil.Uint64  `json:"timeOfFinality"` // The L1 timestamp from which the message is final
+
+	// The call to make on the L1 message bus to check that the message is final before acting on it.
+	MessageBusAddress gethcommon.Address `json:"messageBusAddress"`
+	ClaimCallData     hexutil.Bytes      `json:"claimCallData"`
+}
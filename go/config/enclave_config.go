@@ -1,7 +1,10 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/obscuronet/go-obscuro/go/common"
 
@@ -27,6 +30,27 @@ type EnclaveConfig struct {
 	ObscuroChainID int64
 	// Whether to produce a verified attestation report
 	WillAttest bool
+	// The attestation policy that another enclave must satisfy to be sent the network secret. An enclave is recognised
+	// if either its build (MRENCLAVE) or its signing key (MRSIGNER) is listed. If the enclave attests, at least one
+	// build or signing key must be listed
+	AttestationUniqueIDs []gethcommon.Hash
+	AttestationSignerIDs []gethcommon.Hash
+	// The minimum security version number (SVN) of a recognised enclave
+	AttestationMinSecurityVersion uint64
+	// The product ID of a recognised enclave. Zero means any product ID
+	AttestationProductID uint16
+	// Whether enclaves running in debug mode are recognised
+	AttestationAllowDebug bool
+	// The names of the TCB statuses that are accepted in addition to UpToDate (e.g. SWHardeningNeeded)
+	AttestationAllowedTCBStatuses []string
+	// The address of the key that signs the allow-lists published in the management contract, which replace the
+	// recognised enclaves and minimum SVN, and the requests to rotate the network secret. If zero, published allow-lists
+	// and rotation requests are ignored
+	AttestationAllowListSigner gethcommon.Address
+	// Whether the RPC server only accepts calls over TLS from a host presenting a certificate signed by the key of
+	// HostID, with the enclave's own certificate bound to its attestation report. On by default, and can only be turned
+	// off for enclaves that do not attest, in development and tests
+	AttestedTLS bool
 	// Whether to validate incoming L1 blocks
 	ValidateL1Blocks bool
 	// When validating incoming blocks, the genesis config for the L1 chain
@@ -52,10 +76,54 @@ type EnclaveConfig struct {
 	MessageBusAddress gethcommon.Address
 	// The identity of the sequencer for the network
 	SequencerID gethcommon.Address
-	// A json string that specifies the prefunded addresses at the genesis of the Obscuro network
+	// A json string that specifies the genesis of the Obscuro network: the chain parameters, and the prefunded accounts
+	// and predeployed contracts. If empty, the testnet genesis is used. Its `Config` is the versioned chain config, which
+	// schedules the EVM hardforks and the Obscuro protocol forks at batch numbers
 	ObscuroGenesis string
-	// Cadence
+	// The maximum number of batches pending publication before the sequencer publishes them in a rollup. Zero means no
+	// limit. If Cadence, RollupInterval and MaxPendingRollupSize are all zero, the batches are published as they are
+	// produced
 	Cadence uint64
+	// Whether the sequencer produces batches when requested by the host on its batch interval, rather than for each
+	// live L1 block
+	TimeBasedBatches bool
+	// The gas limit of the batches produced by the sequencer. Transactions that do not fit remain in the mempool.
+	// It only caps the batches the sequencer produces; validators check batches against the gas limit set in the
+	// genesis, which is the same across the network. Zero means no limit
+	MaxBatchGas uint64
+	// The maximum encoded size in bytes of the transactions in a batch produced by the sequencer, so that the rollups
+	// still fit in an L1 transaction. Zero means no limit
+	MaxBatchSize uint64
+	// The maximum size in bytes of the calldata of a rollup transaction. The sequencer splits the batches across several
+	// rollups if a single rollup would be larger. Zero means no limit
+	MaxRollupSize uint64
+	// The maximum time between rollups, measured using the batches' timestamps. Zero means no limit
+	RollupInterval time.Duration
+	// The maximum encoded size in bytes of the batches pending publication before the sequencer publishes them in a
+	// rollup. Zero means no limit
+	MaxPendingRollupSize uint64
+	// The sequencer defers rollups while the base fee of the latest L1 block is above this ceiling, until the time since
+	// the last rollup reaches RollupL1BaseFeeDeadline. Zero means no ceiling
+	RollupMaxL1BaseFee      *big.Int
+	RollupL1BaseFeeDeadline time.Duration
+	// How long the sequencer waits for a rollup to appear on the L1 before rolling up its batches again. Zero means two
+	// minutes
+	RollupInclusionTimeout time.Duration
+	// The number of most recent batches whose state is retained. The trie nodes only reachable from the state of older
+	// batches are garbage collected, and historical queries against them fail. The state of the batches since the
+	// latest rollup is retained regardless. Zero retains the state of every batch
+	StateRetentionBatches uint64
+	// The state of every batch whose number is a multiple of this interval is persisted as a checkpoint, which is
+	// retained beyond the retention window and from which the state is rebuilt after a restart. Zero disables checkpoints
+	StateCheckpointInterval uint64
+	// The number of most recent checkpoints that are retained. The persisted trie nodes only reachable from older
+	// checkpoints are deleted. Zero retains every checkpoint
+	StateCheckpointsRetained uint64
+	// The size in MB of the in-memory cache of trie nodes, shared by every access to the state
+	TrieCacheSizeMB int
+	// Whether the enclave serves the debug_traceTransaction and debug_traceCall requests. The traces are only returned to
+	// the sender of the transaction or call, but reveal the storage of the contracts it touches
+	DebugNamespaceEnabled bool
 }
 
 // DefaultEnclaveConfig returns an EnclaveConfig with default values.
@@ -81,5 +149,60 @@ func DefaultEnclaveConfig() EnclaveConfig {
 		SequencerID:               gethcommon.BytesToAddress([]byte("")),
 		ObscuroGenesis:            "",
 		Cadence:                   10,
+		TimeBasedBatches:          false,
+		MaxBatchGas:               30_000_000,
+		MaxBatchSize:              64 * 1024,
+		MaxRollupSize:             120 * 1024, // below the 128KB maximum size of transactions accepted by geth
+		RollupInterval:            0,
+		MaxPendingRollupSize:      0,
+		RollupMaxL1BaseFee:        big.NewInt(0),
+		RollupL1BaseFeeDeadline:   time.Hour,
+		RollupInclusionTimeout:    2 * time.Minute,
+		StateRetentionBatches:     0,
+		StateCheckpointInterval:   0,
+		StateCheckpointsRetained:  0,
+		TrieCacheSizeMB:           64,
+		DebugNamespaceEnabled:     false,
+
+		// Attestation config.
+		AttestationUniqueIDs:          nil,
+		AttestationSignerIDs:          nil,
+		AttestationMinSecurityVersion: 0,
+		AttestationProductID:          0,
+		AttestationAllowDebug:         false,
+		AttestationAllowedTCBStatuses: nil,
+		AttestationAllowListSigner:    gethcommon.Address{},
+		AttestedTLS:                   true,
+	}
+}
+
+// Validate checks that the enclave config is consistent.
+func (c EnclaveConfig) Validate() error {
+	if c.WillAttest && len(c.AttestationUniqueIDs) == 0 && len(c.AttestationSignerIDs) == 0 {
+		return errors.New("willAttest=true so the enclave must recognise the builds or signing keys of the other enclaves, but no attestationUniqueIDs or attestationSignerIDs were provided")
+	}
+	if c.WillAttest && !c.AttestedTLS {
+		return errors.New("willAttest=true so the enclave must only accept calls from its host, but attestedTLS=false; the attested channel can only be disabled for enclaves that do not attest, in development and tests")
+	}
+	return c.ValidateDB()
+}
+
+// ValidateDB checks that the enclave's DB config is consistent.
+func (c EnclaveConfig) ValidateDB() error {
+	if c.UseInMemoryDB && c.EdgelessDBHost != "" {
+		return fmt.Errorf("invalid db config, useInMemoryDB=true so EdgelessDB host not expected, but EdgelessDBHost=%s", c.EdgelessDBHost)
+	}
+	if !c.WillAttest && c.EdgelessDBHost != "" {
+		return fmt.Errorf("invalid db config, willAttest=false so EdgelessDB host not supported, but EdgelessDBHost=%s", c.EdgelessDBHost)
+	}
+	if !c.UseInMemoryDB && c.WillAttest && c.EdgelessDBHost == "" {
+		return fmt.Errorf("useInMemoryDB=false, willAttest=true so expected an EdgelessDB host but none was provided")
+	}
+	if c.SqliteDBPath != "" && c.UseInMemoryDB {
+		return fmt.Errorf("useInMemoryDB=true so sqlite database will not be used and no path is needed, but sqliteDBPath=%s", c.SqliteDBPath)
+	}
+	if c.SqliteDBPath != "" && c.WillAttest {
+		return fmt.Errorf("willAttest=true so sqlite database will not be used and no path is needed, but sqliteDBPath=%s", c.SqliteDBPath)
 	}
+	return nil
 }
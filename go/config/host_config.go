@@ -1,6 +1,8 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/obscuronet/go-obscuro/go/common"
@@ -54,7 +56,7 @@ type HostInputConfig struct {
 	// The path that the node's logs are written to
 	LogPath string
 	// The stringified private key for the host's L1 wallet
-	PrivateKeyString string
+	PrivateKeyString string `secret:"true"`
 	// The ID of the L1 chain
 	L1ChainID int64
 	// The ID of the Obscuro chain
@@ -75,6 +77,39 @@ type HostInputConfig struct {
 
 	// LevelDBPath path for the levelDB persistence dir (can be empty if a throwaway file in /tmp/ is acceptable, or if using InMemory DB)
 	LevelDBPath string
+
+	// BatchInterval is how often the sequencer host asks its enclave to produce a batch. If zero, the enclave produces a
+	// batch for each live L1 block instead
+	BatchInterval time.Duration
+
+	// SkipEmptyBatches sets whether the sequencer skips producing a batch on its batch interval if there are no pending
+	// transactions or deposits
+	SkipEmptyBatches bool
+
+	// SnapshotPath is the path to a state snapshot exported by another node, which a new node's enclave imports on
+	// startup instead of replaying the L1 from L1StartHash. If empty, no snapshot is imported
+	SnapshotPath string
+
+	// AdminIPCPath is the path of the IPC endpoint on which the host serves the operator-only admin API (e.g. snapshot
+	// export), in addition to the client APIs. If empty, the admin API is not served
+	AdminIPCPath string
+
+	// AttestedTLS sets whether the host connects to the enclave over mutually-authenticated TLS, presenting a
+	// certificate signed by the key of its ID and only accepting an enclave certificate bound to the enclave's
+	// attestation report. The enclave must be configured likewise. On by default, and can only be turned off if the
+	// enclave does not attest, in development and tests
+	AttestedTLS bool
+	// EnclaveWillAttest sets whether the host's enclave produces a verified attestation report. If so, the host only
+	// connects over attested TLS to an enclave whose report satisfies the attestation policy below, which should match
+	// the enclave's own. Otherwise, the enclave's attestation report is simulated and is not verified
+	EnclaveWillAttest bool
+	// The attestation policy that the host's enclave must satisfy. See config.EnclaveConfig
+	AttestationUniqueIDs          []gethcommon.Hash
+	AttestationSignerIDs          []gethcommon.Hash
+	AttestationMinSecurityVersion uint64
+	AttestationProductID          uint16
+	AttestationAllowDebug         bool
+	AttestationAllowedTCBStatuses []string
 }
 
 // ToHostConfig returns a HostConfig given a HostInputConfig
@@ -108,6 +143,19 @@ func (p HostInputConfig) ToHostConfig() *HostConfig {
 		MetricsHTTPPort:           p.MetricsHTTPPort,
 		UseInMemoryDB:             p.UseInMemoryDB,
 		LevelDBPath:               p.LevelDBPath,
+		BatchInterval:             p.BatchInterval,
+		SkipEmptyBatches:          p.SkipEmptyBatches,
+		SnapshotPath:              p.SnapshotPath,
+		AdminIPCPath:              p.AdminIPCPath,
+		AttestedTLS:               p.AttestedTLS,
+
+		EnclaveWillAttest:             p.EnclaveWillAttest,
+		AttestationUniqueIDs:          p.AttestationUniqueIDs,
+		AttestationSignerIDs:          p.AttestationSignerIDs,
+		AttestationMinSecurityVersion: p.AttestationMinSecurityVersion,
+		AttestationProductID:          p.AttestationProductID,
+		AttestationAllowDebug:         p.AttestationAllowDebug,
+		AttestationAllowedTCBStatuses: p.AttestationAllowedTCBStatuses,
 	}
 }
 
@@ -150,7 +198,7 @@ type HostConfig struct {
 	// The path that the node's logs are written to
 	LogPath string
 	// The stringified private key for the host's L1 wallet
-	PrivateKeyString string
+	PrivateKeyString string `secret:"true"`
 	// The ID of the L1 chain
 	L1ChainID int64
 	// The ID of the Obscuro chain
@@ -174,6 +222,31 @@ type HostConfig struct {
 
 	// filepath for the levelDB persistence dir (can be empty if a throwaway file in /tmp/ is acceptable, or if using InMemory DB)
 	LevelDBPath string
+
+	// How often the sequencer host asks its enclave to produce a batch. If zero, the enclave produces a batch for each
+	// live L1 block instead
+	BatchInterval time.Duration
+
+	// Whether the sequencer skips producing a batch on its batch interval if there are no pending transactions or deposits
+	SkipEmptyBatches bool
+
+	// The path to a state snapshot for the enclave to import on startup, if any
+	SnapshotPath string
+
+	// The path of the IPC endpoint serving the admin API, if any
+	AdminIPCPath string
+
+	// Whether the host connects to the enclave over TLS bound to the host's key and the enclave's attestation
+	AttestedTLS bool
+	// Whether the enclave's attestation report is verified against the attestation policy below, rather than simulated
+	EnclaveWillAttest bool
+	// The attestation policy that the host's enclave must satisfy
+	AttestationUniqueIDs          []gethcommon.Hash
+	AttestationSignerIDs          []gethcommon.Hash
+	AttestationMinSecurityVersion uint64
+	AttestationProductID          uint16
+	AttestationAllowDebug         bool
+	AttestationAllowedTCBStatuses []string
 }
 
 // DefaultHostParsedConfig returns a HostConfig with default values.
@@ -205,5 +278,49 @@ func DefaultHostParsedConfig() *HostInputConfig {
 		MetricsEnabled:            true,
 		MetricsHTTPPort:           14000,
 		UseInMemoryDB:             true,
+		BatchInterval:             0,
+		SkipEmptyBatches:          false,
+		SnapshotPath:              "",
+		AdminIPCPath:              "",
+		AttestedTLS:               true,
+
+		EnclaveWillAttest:             false, // todo: attestation should be on by default before production release
+		AttestationUniqueIDs:          nil,
+		AttestationSignerIDs:          nil,
+		AttestationMinSecurityVersion: 0,
+		AttestationProductID:          0,
+		AttestationAllowDebug:         false,
+		AttestationAllowedTCBStatuses: nil,
+	}
+}
+
+// Validate checks that the host config is consistent.
+func (c *HostConfig) Validate() error {
+	if c.IsGenesis && c.NodeType != common.Sequencer {
+		return errors.New("genesis node must be the sequencer")
+	}
+	if !c.IsGenesis && c.NodeType == common.Sequencer {
+		return errors.New("only the genesis node can be a sequencer")
+	}
+	if c.P2PPublicAddress == "" {
+		return errors.New("the host must specify a public P2P address")
+	}
+	if c.BatchInterval < 0 {
+		return errors.New("the batch interval cannot be negative")
+	}
+	if c.EnclaveWillAttest && !c.AttestedTLS {
+		return errors.New("enclaveWillAttest=true so the host must only call its enclave over the attested channel, but attestedTLS=false; the attested channel can only be disabled for enclaves that do not attest, in development and tests")
+	}
+	if c.AttestedTLS && c.EnclaveWillAttest && len(c.AttestationUniqueIDs) == 0 && len(c.AttestationSignerIDs) == 0 {
+		return errors.New("enclaveWillAttest=true so the host must recognise its enclave's build or signing key, but no attestationUniqueIDs or attestationSignerIDs were provided")
+	}
+	return c.ValidateDB()
+}
+
+// ValidateDB checks that the host's DB config is consistent.
+func (c *HostConfig) ValidateDB() error {
+	if c.UseInMemoryDB && c.LevelDBPath != "" {
+		return fmt.Errorf("useInMemoryDB=true so levelDB will not be used and no path is needed, but levelDBPath=%s", c.LevelDBPath)
 	}
+	return nil
 }
@@ -0,0 +1,537 @@
+package config
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode"
+
+	"github.com/naoina/toml"
+	"github.com/obscuronet/go-obscuro/go/common/log"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+// ConfigFlagName is the name of the flag giving the path of the .toml config file.
+const ConfigFlagName = "config"
+
+const (
+	sourceDefault = "default"
+	redacted      = "[redacted]"
+
+	// Struct tags read by the Loader.
+	flagTag     = "flag"     // The name of the flag that sets the field.
+	validateTag = "validate" // A comma-separated list of the rules the field's value must satisfy.
+	secretTag   = "secret"   // Whether the field's value is redacted when the config is printed or logged.
+	reloadTag   = "reload"   // Whether the field's value can be changed while the process is running.
+)
+
+// Loader loads a config struct from, in increasing order of precedence, the struct's initial values, a .toml file,
+// environment variables and command-line flags, then validates it.
+//
+// The struct's fields are read from the .toml file using the field names, from the environment variables named
+// <envPrefix>_<FLAG_NAME> (or <envPrefix>_<FIELD_NAME> for fields without a flag) and from the flag named by the
+// field's `flag` tag. Fields can be tagged with `validate` rules (required, address, hex32, hostport, port, duration,
+// oneof=a|b, min=N and max=N), `secret:"true"` to redact them when printed, and `reload:"true"` to allow them to
+// change on SIGHUP.
+type Loader struct {
+	flags      *flag.FlagSet
+	envPrefix  string
+	configPath *string
+	flagValues map[string]string // The values of the flags set on the command line, by field name.
+
+	mu       sync.Mutex
+	schema   reflect.Value     // The struct the config is loaded into.
+	defaults reflect.Value     // A copy of the struct's initial values.
+	sources  map[string]string // Where the value of each field came from, by field name.
+}
+
+// NewLoader returns a Loader for the struct pointed to by schema, and defines the `config` flag and a flag for each of
+// the struct's fields with a `flag` tag on flags. The struct's current values are the defaults.
+func NewLoader(schema interface{}, flags *flag.FlagSet, envPrefix string, usages map[string]string) *Loader {
+	schemaValue := reflect.ValueOf(schema)
+	if schemaValue.Kind() != reflect.Pointer || schemaValue.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("config schema must be a pointer to a struct, got %T", schema))
+	}
+	defaults := reflect.New(schemaValue.Elem().Type()).Elem()
+	defaults.Set(schemaValue.Elem())
+
+	l := &Loader{
+		flags:      flags,
+		envPrefix:  envPrefix,
+		flagValues: map[string]string{},
+		schema:     schemaValue.Elem(),
+		defaults:   defaults,
+		sources:    map[string]string{},
+	}
+
+	l.configPath = flags.String(ConfigFlagName, "", usages[ConfigFlagName])
+	schemaType := l.schema.Type()
+	for i := 0; i < schemaType.NumField(); i++ {
+		field := schemaType.Field(i)
+		name := field.Tag.Get(flagTag)
+		if name == "" {
+			continue
+		}
+		flags.Var(&fieldFlag{loader: l, field: field, value: formatValue(defaults.Field(i))}, name, usages[name])
+	}
+	return l
+}
+
+// Load parses the command-line arguments, then loads and validates the config. The returned error lists every
+// invalid field.
+func (l *Loader) Load(args []string) error {
+	if err := l.flags.Parse(args); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sources, err := l.load(l.schema)
+	if err != nil {
+		return err
+	}
+	l.sources = sources
+	return nil
+}
+
+// Effective returns the loaded config with the source of each value, one field per line, with secrets redacted.
+func (l *Loader) Effective() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var sb strings.Builder
+	sb.WriteString("Effective config:")
+	schemaType := l.schema.Type()
+	for i := 0; i < schemaType.NumField(); i++ {
+		field := schemaType.Field(i)
+		sb.WriteString(fmt.Sprintf("\n  %s = %s (%s)", field.Name, displayValue(field, l.schema.Field(i)), l.sources[field.Name]))
+	}
+	return sb.String()
+}
+
+// WatchReload reloads the config each time the process receives a SIGHUP. The fields tagged `reload:"true"` that
+// changed are updated and apply is called with the config; changes to other fields are logged and ignored until
+// restart. If the reloaded config is invalid, the current config is kept. The returned function stops the watch.
+func (l *Loader) WatchReload(logger gethlog.Logger, apply func(schema interface{})) func() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	stopCh := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-sigCh:
+				changed, err := l.reload(logger, apply)
+				if err != nil {
+					logger.Error("Could not reload config. Keeping the current config.", log.ErrKey, err)
+					continue
+				}
+				logger.Info("Reloaded config.", "changed", changed)
+			case <-stopCh:
+				signal.Stop(sigCh)
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(stopCh) }) }
+}
+
+// Loads the config into a fresh copy of the defaults, and updates the reloadable fields that changed. Returns the
+// names of the fields that were updated.
+func (l *Loader) reload(logger gethlog.Logger, apply func(schema interface{})) ([]string, error) {
+	fresh := reflect.New(l.schema.Type()).Elem()
+	sources, err := l.load(fresh)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	var changed []string
+	schemaType := l.schema.Type()
+	for i := 0; i < schemaType.NumField(); i++ {
+		field := schemaType.Field(i)
+		if reflect.DeepEqual(l.schema.Field(i).Interface(), fresh.Field(i).Interface()) {
+			continue
+		}
+		if field.Tag.Get(reloadTag) != "true" {
+			logger.Warn("Config field changed but cannot be reloaded. The change takes effect on restart.", "field", field.Name)
+			continue
+		}
+		l.schema.Field(i).Set(fresh.Field(i))
+		l.sources[field.Name] = sources[field.Name]
+		changed = append(changed, field.Name)
+	}
+	l.mu.Unlock()
+
+	if len(changed) > 0 {
+		apply(l.schema.Addr().Interface())
+	}
+	return changed, nil
+}
+
+// Sets target to the defaults, then applies the config file, the environment variables and the flags, then validates
+// the result. Returns the source of each field.
+func (l *Loader) load(target reflect.Value) (map[string]string, error) {
+	target.Set(l.defaults)
+	schemaType := target.Type()
+	sources := make(map[string]string, schemaType.NumField())
+	for i := 0; i < schemaType.NumField(); i++ {
+		sources[schemaType.Field(i).Name] = sourceDefault
+	}
+
+	if *l.configPath != "" {
+		if err := l.loadFile(target, sources); err != nil {
+			return nil, err
+		}
+	}
+
+	var problems []string
+	for i := 0; i < schemaType.NumField(); i++ {
+		field := schemaType.Field(i)
+
+		envName := l.envName(field)
+		if envValue, ok := os.LookupEnv(envName); ok {
+			source := "env " + envName
+			if err := setFromString(target.Field(i), envValue); err != nil {
+				problems = append(problems, fmt.Sprintf("%s (from %s): %s", field.Name, source, err))
+			} else {
+				sources[field.Name] = source
+			}
+		}
+
+		if flagValue, ok := l.flagValues[field.Name]; ok {
+			// The flag's value was already checked when the flag was parsed.
+			_ = setFromString(target.Field(i), flagValue)
+			sources[field.Name] = "flag -" + field.Tag.Get(flagTag)
+		}
+	}
+
+	for i := 0; i < schemaType.NumField(); i++ {
+		field := schemaType.Field(i)
+		if err := validateField(field, target.Field(i)); err != nil {
+			problems = append(problems, fmt.Sprintf("%s = %s (from %s): %s", field.Name, displayValue(field, target.Field(i)), sources[field.Name], err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid config:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return sources, nil
+}
+
+// Applies the .toml file at the configured path to target, and records the fields it sets in sources.
+func (l *Loader) loadFile(target reflect.Value, sources map[string]string) error {
+	data, err := os.ReadFile(*l.configPath)
+	if err != nil {
+		return fmt.Errorf("could not read config file at %s. Cause: %w", *l.configPath, err)
+	}
+	table, err := toml.Parse(data)
+	if err != nil {
+		return fmt.Errorf("could not parse config file at %s. Cause: %w", *l.configPath, err)
+	}
+	if err = toml.UnmarshalTable(table, target.Addr().Interface()); err != nil {
+		return fmt.Errorf("invalid config file at %s. Cause: %w", *l.configPath, err)
+	}
+
+	fieldNames := map[string]string{}
+	schemaType := target.Type()
+	for i := 0; i < schemaType.NumField(); i++ {
+		fieldNames[normaliseKey(schemaType.Field(i).Name)] = schemaType.Field(i).Name
+	}
+	for key := range table.Fields {
+		if fieldName, ok := fieldNames[normaliseKey(key)]; ok {
+			sources[fieldName] = "file " + *l.configPath
+		}
+	}
+	return nil
+}
+
+// Returns the name of the environment variable that sets the field.
+func (l *Loader) envName(field reflect.StructField) string {
+	name := field.Tag.Get(flagTag)
+	if name == "" {
+		name = field.Name
+	}
+	return l.envPrefix + "_" + toScreamingSnakeCase(name)
+}
+
+// Redacted returns the fields of the struct (or pointer to a struct) cfg in the format of `%+v`, with the values of
+// the fields tagged `secret:"true"` redacted. It is used wherever a config is printed or logged.
+func Redacted(cfg interface{}) string {
+	value := reflect.Indirect(reflect.ValueOf(cfg))
+	if value.Kind() != reflect.Struct {
+		return fmt.Sprintf("%+v", cfg)
+	}
+
+	fields := make([]string, value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		fields[i] = fmt.Sprintf("%s:%s", field.Name, displayValue(field, value.Field(i)))
+	}
+	return "{" + strings.Join(fields, " ") + "}"
+}
+
+// fieldFlag is the flag.Value of the flag that sets a field of a Loader's config.
+type fieldFlag struct {
+	loader *Loader
+	field  reflect.StructField
+	value  string
+}
+
+func (f *fieldFlag) String() string {
+	return f.value
+}
+
+func (f *fieldFlag) Set(s string) error {
+	if err := setFromString(reflect.New(f.field.Type).Elem(), s); err != nil {
+		return err
+	}
+	f.value = s
+	f.loader.flagValues[f.field.Name] = s
+	return nil
+}
+
+func (f *fieldFlag) IsBoolFlag() bool {
+	return f.field.Type.Kind() == reflect.Bool
+}
+
+// Parses s into value according to value's kind. Lists are comma-separated.
+func setFromString(value reflect.Value, s string) error {
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.String:
+		value.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a boolean", s)
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return fmt.Errorf("'%s' is not a duration", s)
+			}
+			value.SetInt(int64(d))
+			return nil
+		}
+		i, err := strconv.ParseInt(s, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("'%s' is not an integer of %d bits", s, value.Type().Bits())
+		}
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("'%s' is not an unsigned integer of %d bits", s, value.Type().Bits())
+		}
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("'%s' is not a number", s)
+		}
+		value.SetFloat(f)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported config field type %s", value.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported config field type %s", value.Type())
+	}
+	return nil
+}
+
+// Checks the value of the field against the rules in its `validate` tag. Apart from `required`, the rules are not
+// applied to empty strings, and are applied to each item of a list.
+func validateField(field reflect.StructField, value reflect.Value) error {
+	rules := field.Tag.Get(validateTag)
+	if rules == "" {
+		return nil
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		if rule == "required" {
+			if value.IsZero() || (value.Kind() == reflect.Slice && value.Len() == 0) {
+				return errors.New("is required")
+			}
+			continue
+		}
+
+		if value.Kind() != reflect.Slice {
+			if err := checkRule(rule, value); err != nil {
+				return err
+			}
+			continue
+		}
+		for i := 0; i < value.Len(); i++ {
+			if err := checkRule(rule, value.Index(i)); err != nil {
+				return fmt.Errorf("item %d %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Checks a single value against a single validation rule.
+func checkRule(rule string, value reflect.Value) error {
+	name, arg, _ := strings.Cut(rule, "=")
+	if value.Kind() == reflect.String {
+		return checkStringRule(rule, name, arg, value.String())
+	}
+	return checkNumberRule(rule, name, arg, value)
+}
+
+func checkStringRule(rule, name, arg, s string) error {
+	if s == "" {
+		return nil
+	}
+	switch name {
+	case "address":
+		if !gethcommon.IsHexAddress(s) {
+			return errors.New("must be a 20-byte hex address")
+		}
+	case "hex32":
+		b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil || len(b) != gethcommon.HashLength {
+			return errors.New("must be 32 hex-encoded bytes")
+		}
+	case "hostport":
+		_, port, err := net.SplitHostPort(s)
+		if err != nil {
+			return errors.New("must be of the form host:port")
+		}
+		if _, err = strconv.ParseUint(port, 10, 16); err != nil {
+			return errors.New("must have a port between 0 and 65535")
+		}
+	case "duration":
+		if _, err := time.ParseDuration(s); err != nil {
+			return errors.New("must be a duration such as 500ms or 10s")
+		}
+	case "oneof":
+		options := strings.Split(arg, "|")
+		for _, option := range options {
+			if s == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(options, ", "))
+	default:
+		return fmt.Errorf("unsupported validation rule '%s' for a string", rule)
+	}
+	return nil
+}
+
+func checkNumberRule(rule, name, arg string, value reflect.Value) error {
+	var n float64
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = value.Float()
+	default:
+		return fmt.Errorf("unsupported validation rule '%s' for %s", rule, value.Type())
+	}
+	switch name {
+	case "port":
+		if n > 65535 {
+			return errors.New("must be a port between 0 and 65535")
+		}
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Errorf("invalid validation rule '%s'", rule)
+		}
+		if name == "min" && n < limit {
+			return fmt.Errorf("must be at least %s", arg)
+		}
+		if name == "max" && n > limit {
+			return fmt.Errorf("must be at most %s", arg)
+		}
+	default:
+		return fmt.Errorf("unsupported validation rule '%s' for a number", rule)
+	}
+	return nil
+}
+
+// Returns the value of the field for printing, or a placeholder if the field is a secret that is set.
+func displayValue(field reflect.StructField, value reflect.Value) string {
+	if field.Tag.Get(secretTag) == "true" && !value.IsZero() {
+		return redacted
+	}
+	if value.Kind() == reflect.String && value.String() == "" {
+		return `""`
+	}
+	return formatValue(value)
+}
+
+// Formats the value of a field the way it is written in flags and environment variables.
+func formatValue(value reflect.Value) string {
+	if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String {
+		return strings.Join(value.Interface().([]string), ",")
+	}
+	return fmt.Sprintf("%v", value.Interface())
+}
+
+// Normalises a .toml key or field name the same way as the toml package, to match keys to fields.
+func normaliseKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "")
+}
+
+// Converts a flag or field name such as l1ChainID or P2PBindAddress to L1_CHAIN_ID or P2P_BIND_ADDRESS.
+func toScreamingSnakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				sb.WriteRune('_')
+			}
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
+
+// ToHashes converts the hex values of a list field, such as a list of MRENCLAVE values, to hashes.
+func ToHashes(hexValues []string) []gethcommon.Hash {
+	hashes := make([]gethcommon.Hash, len(hexValues))
+	for i, hexValue := range hexValues {
+		hashes[i] = gethcommon.HexToHash(hexValue)
+	}
+	return hashes
+}
+
+// FromHashes converts hashes to the hex values of a list field.
+func FromHashes(hashes []gethcommon.Hash) []string {
+	hexValues := make([]string, len(hashes))
+	for i, hash := range hashes {
+		hexValues[i] = hash.Hex()
+	}
+	return hexValues
+}
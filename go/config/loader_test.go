@@ -0,0 +1,124 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/obscuronet/go-obscuro/go/common/log"
+)
+
+const testEnvPrefix = "OBSCURO_LOADER_TEST"
+
+type testConfig struct {
+	FromDefault string `flag:"fromDefault"`
+	FromFile    string `flag:"fromFile"`
+	FromEnv     int    `flag:"fromEnv" validate:"max=10"`
+	FromFlag    bool   `flag:"fromFlag"`
+	Address     string `flag:"address" validate:"address"`
+	PrivateKey  string `flag:"privateKey" validate:"hex32" secret:"true"`
+	Peers       []string
+	LogLevel    int `flag:"logLevel" reload:"true"`
+}
+
+func TestLoaderAppliesSourcesInOrderOfPrecedence(t *testing.T) {
+	configPath := writeConfigFile(t, "fromFile = \"file\"\nfromEnv = 1\nfromFlag = false\npeers = [\"a:1\", \"b:2\"]\n")
+	t.Setenv(testEnvPrefix+"_FROM_ENV", "2")
+	t.Setenv(testEnvPrefix+"_FROM_FLAG", "false")
+
+	cfg := testConfig{FromDefault: "default", FromFile: "default", FromEnv: 0}
+	loader := newTestLoader(&cfg)
+	if err := loader.Load([]string{"-config", configPath, "-fromFlag"}); err != nil {
+		t.Fatalf("could not load config. Cause: %s", err)
+	}
+
+	if cfg.FromDefault != "default" || cfg.FromFile != "file" || cfg.FromEnv != 2 || !cfg.FromFlag {
+		t.Fatalf("sources were not applied in order of precedence, got %+v", cfg)
+	}
+	if len(cfg.Peers) != 2 || cfg.Peers[1] != "b:2" {
+		t.Fatalf("list was not loaded from file, got %s", cfg.Peers)
+	}
+	effective := loader.Effective()
+	for _, line := range []string{
+		"FromDefault = default (default)",
+		"FromFile = file (file " + configPath + ")",
+		"FromEnv = 2 (env " + testEnvPrefix + "_FROM_ENV)",
+		"FromFlag = true (flag -fromFlag)",
+	} {
+		if !strings.Contains(effective, line) {
+			t.Errorf("effective config does not contain '%s':\n%s", line, effective)
+		}
+	}
+}
+
+func TestLoaderReportsEveryInvalidFieldWithoutSecrets(t *testing.T) {
+	secret := "0xnotakey"
+	cfg := testConfig{}
+	loader := newTestLoader(&cfg)
+	err := loader.Load([]string{"-fromEnv", "11", "-address", "0x1234", "-privateKey", secret})
+	if err == nil {
+		t.Fatal("expected invalid config to be rejected")
+	}
+
+	for _, expected := range []string{
+		"FromEnv = 11 (from flag -fromEnv): must be at most 10",
+		"Address = 0x1234 (from flag -address): must be a 20-byte hex address",
+		"PrivateKey = [redacted] (from flag -privateKey): must be 32 hex-encoded bytes",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("error does not contain '%s':\n%s", expected, err)
+		}
+	}
+	if strings.Contains(err.Error(), secret) {
+		t.Errorf("error contains secret:\n%s", err)
+	}
+}
+
+func TestLoaderOnlyReloadsReloadableFields(t *testing.T) {
+	configPath := writeConfigFile(t, "logLevel = 3\nfromFile = \"before\"\n")
+	cfg := testConfig{}
+	loader := newTestLoader(&cfg)
+	if err := loader.Load([]string{"-config", configPath}); err != nil {
+		t.Fatalf("could not load config. Cause: %s", err)
+	}
+
+	if err := os.WriteFile(configPath, []byte("logLevel = 5\nfromFile = \"after\"\n"), 0o600); err != nil {
+		t.Fatalf("could not update config file. Cause: %s", err)
+	}
+	applied := 0
+	changed, err := loader.reload(log.New(log.TestLogCmp, 0, log.SysOut), func(interface{}) { applied++ })
+	if err != nil {
+		t.Fatalf("could not reload config. Cause: %s", err)
+	}
+
+	if applied != 1 || len(changed) != 1 || cfg.LogLevel != 5 {
+		t.Fatalf("expected the log level to be reloaded, got changed fields %s and config %+v", changed, cfg)
+	}
+	if cfg.FromFile != "before" {
+		t.Fatalf("expected a field that cannot be reloaded to be unchanged, got %s", cfg.FromFile)
+	}
+}
+
+func TestRedactedHidesSecrets(t *testing.T) {
+	redactedCfg := Redacted(&HostConfig{PrivateKeyString: "0xsecret", LogPath: "logs.txt"})
+	if strings.Contains(redactedCfg, "0xsecret") || !strings.Contains(redactedCfg, "PrivateKeyString:[redacted]") {
+		t.Fatalf("secret was not redacted: %s", redactedCfg)
+	}
+	if !strings.Contains(redactedCfg, "LogPath:logs.txt") {
+		t.Fatalf("field was not printed: %s", redactedCfg)
+	}
+}
+
+func newTestLoader(cfg *testConfig) *Loader {
+	return NewLoader(cfg, flag.NewFlagSet("test", flag.ContinueOnError), testEnvPrefix, map[string]string{})
+}
+
+func writeConfigFile(t *testing.T, contents string) string {
+	configPath := path.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(configPath, []byte(contents), 0o600); err != nil {
+		t.Fatalf("could not write config file. Cause: %s", err)
+	}
+	return configPath
+}
@@ -0,0 +1,126 @@
+package enclave
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/go/config"
+	"github.com/obscuronet/go-obscuro/go/enclave/db"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+// NewAttestationPolicy returns the attestation policy set in the enclave config.
+func NewAttestationPolicy(config config.EnclaveConfig) (common.AttestationPolicy, error) {
+	tcbStatuses, err := common.ParseTCBStatuses(config.AttestationAllowedTCBStatuses)
+	if err != nil {
+		return common.AttestationPolicy{}, err
+	}
+	return common.AttestationPolicy{
+		UniqueIDs:          config.AttestationUniqueIDs,
+		SignerIDs:          config.AttestationSignerIDs,
+		MinSecurityVersion: config.AttestationMinSecurityVersion,
+		ProductID:          config.AttestationProductID,
+		AllowDebug:         config.AttestationAllowDebug,
+		AllowedTCBStatuses: tcbStatuses,
+		AllowListSigner:    config.AttestationAllowListSigner,
+	}, nil
+}
+
+// attestationPolicyHolder holds the enclave's attestation policy, and applies the allow-list in force on the L1 chain
+// processed by the enclave to it. Each allow-list is published on an L1 fork, so the allow-list in force changes when
+// the L1 chain is reorganised.
+type attestationPolicyHolder struct {
+	policy       common.AttestationPolicy
+	allowList    *common.AttestationAllowList // the allow-list in force, if any
+	inForceAt    common.L1RootHash            // the L1 block the allow-list is in force at
+	l1ChainID    int64
+	mgmtContract gethcommon.Address // the management contract the allow-lists are published in
+	mutex        sync.RWMutex
+}
+
+func newAttestationPolicyHolder(policy common.AttestationPolicy, l1ChainID int64, mgmtContract gethcommon.Address) *attestationPolicyHolder {
+	return &attestationPolicyHolder{policy: policy, l1ChainID: l1ChainID, mgmtContract: mgmtContract}
+}
+
+// Checks the report against the policy, with the recognised enclaves and minimum SVN of the allow-list in force.
+func (h *attestationPolicyHolder) check(report attestation.Report) error {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	policy := h.policy
+	if h.allowList != nil {
+		policy.UniqueIDs = h.allowList.UniqueIDs
+		policy.SignerIDs = h.allowList.SignerIDs
+		policy.MinSecurityVersion = h.allowList.MinSecurityVersion
+	}
+	return policy.Check(report)
+}
+
+// Returns the allow-list in force, or nil if there is none, and the hash of the L1 block it is in force at.
+func (h *attestationPolicyHolder) currentAllowList() (*common.AttestationAllowList, common.L1RootHash) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.allowList, h.inForceAt
+}
+
+// Puts the allow-list in force at the given L1 block. A nil allow-list restores the recognised enclaves and minimum SVN
+// of the config.
+func (h *attestationPolicyHolder) setAllowList(allowList *common.AttestationAllowList, l1Block common.L1RootHash) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.allowList = allowList
+	h.inForceAt = l1Block
+}
+
+// Returns an error unless the allow-list is signed by the policy key for this network, recognises some enclave, and is
+// more recent than the allow-list currently in force, if any.
+func (h *attestationPolicyHolder) verifyAllowList(allowList *common.AttestationAllowList, current *common.AttestationAllowList) error {
+	if h.policy.AllowListSigner == (gethcommon.Address{}) {
+		return errors.New("no allow-list signer is configured")
+	}
+	signer, err := allowList.Signer(h.l1ChainID, h.mgmtContract)
+	if err != nil {
+		return err
+	}
+	if signer != h.policy.AllowListSigner {
+		return fmt.Errorf("allow-list was signed by %s rather than the allow-list signer", signer)
+	}
+	if err = allowList.Validate(); err != nil {
+		return err
+	}
+	if current != nil && allowList.Version <= current.Version {
+		return fmt.Errorf("allow-list version %d is not more recent than the current version %d", allowList.Version, current.Version)
+	}
+	return nil
+}
+
+// Puts the allow-list in force on the L1 chain ending in the given block, if any, and otherwise restores the config's
+// policy.
+func loadAttestationAllowList(storage db.Storage, l1Block common.L1RootHash, policy *attestationPolicyHolder) error {
+	allowList, err := storage.FetchAttestationAllowList(l1Block)
+	if err != nil && !errors.Is(err, errutil.ErrNotFound) {
+		return fmt.Errorf("could not retrieve attestation allow-list. Cause: %w", err)
+	}
+	policy.setAllowList(allowList, l1Block)
+	return nil
+}
+
+// Puts the allow-list in force on the enclave's L1 head, if the enclave has processed any L1 blocks.
+func loadAttestationAllowListAtHead(storage db.Storage, policy *attestationPolicyHolder, logger gethlog.Logger) {
+	l1Head, err := storage.FetchHeadBlock()
+	if err != nil {
+		if !errors.Is(err, errutil.ErrNotFound) {
+			logger.Error("Could not retrieve L1 head to load the attestation allow-list.", log.ErrKey, err)
+		}
+		return
+	}
+	if err = loadAttestationAllowList(storage, l1Head.Hash(), policy); err != nil {
+		logger.Error("Could not load the attestation allow-list.", log.ErrKey, err)
+	}
+}
@@ -0,0 +1,119 @@
+package enclave
+
+import (
+	"testing"
+
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/edgelesssys/ego/attestation/tcbstatus"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/obscuronet/go-obscuro/go/common"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	recognisedBuild  = gethcommon.HexToHash("0x01")
+	recognisedSigner = gethcommon.HexToHash("0x02")
+	upgradedBuild    = gethcommon.HexToHash("0x03")
+
+	testL1ChainID    = int64(1337)
+	testMgmtContract = gethcommon.HexToAddress("0x01")
+)
+
+func TestAttestationPolicyChecksReport(t *testing.T) {
+	policy := common.AttestationPolicy{
+		UniqueIDs:          []gethcommon.Hash{recognisedBuild},
+		SignerIDs:          []gethcommon.Hash{recognisedSigner},
+		MinSecurityVersion: 2,
+		ProductID:          1,
+		AllowedTCBStatuses: []tcbstatus.Status{tcbstatus.SWHardeningNeeded},
+	}
+	validReport := attestation.Report{
+		UniqueID:        upgradedBuild.Bytes(),
+		SignerID:        recognisedSigner.Bytes(),
+		SecurityVersion: 2,
+		ProductID:       []byte{1, 0},
+		TCBStatus:       tcbstatus.SWHardeningNeeded,
+	}
+	if err := policy.Check(validReport); err != nil {
+		t.Fatalf("expected report to satisfy the policy, got: %s", err)
+	}
+
+	invalidReports := map[string]func(report *attestation.Report){
+		"unrecognised enclave": func(report *attestation.Report) { report.SignerID = upgradedBuild.Bytes() },
+		"outdated SVN":         func(report *attestation.Report) { report.SecurityVersion = 1 },
+		"wrong product ID":     func(report *attestation.Report) { report.ProductID = []byte{2, 0} },
+		"debug enclave":        func(report *attestation.Report) { report.Debug = true },
+		"revoked TCB":          func(report *attestation.Report) { report.TCBStatus = tcbstatus.Revoked },
+	}
+	for name, invalidate := range invalidReports {
+		report := validReport
+		invalidate(&report)
+		if err := policy.Check(report); err == nil {
+			t.Errorf("expected report with %s to fail the policy", name)
+		}
+	}
+}
+
+func TestAttestationPolicyAppliesSignedAllowList(t *testing.T) {
+	policyKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	holder := newAttestationPolicyHolder(common.AttestationPolicy{
+		UniqueIDs:       []gethcommon.Hash{recognisedBuild},
+		AllowListSigner: crypto.PubkeyToAddress(policyKey.PublicKey),
+	}, testL1ChainID, testMgmtContract)
+	upgradedReport := attestation.Report{UniqueID: upgradedBuild.Bytes()}
+	if err = holder.check(upgradedReport); err == nil {
+		t.Fatal("expected upgraded enclave not to be recognised before the allow-list is applied")
+	}
+
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowList := &common.AttestationAllowList{Version: 1, UniqueIDs: []gethcommon.Hash{upgradedBuild}}
+	if err = allowList.Sign(otherKey, testL1ChainID, testMgmtContract); err != nil {
+		t.Fatal(err)
+	}
+	if err = holder.verifyAllowList(allowList, nil); err == nil {
+		t.Fatal("expected allow-list signed by another key to be rejected")
+	}
+	if err = allowList.Sign(policyKey, testL1ChainID, gethcommon.HexToAddress("0x02")); err != nil {
+		t.Fatal(err)
+	}
+	if err = holder.verifyAllowList(allowList, nil); err == nil {
+		t.Fatal("expected allow-list signed for another management contract to be rejected")
+	}
+	emptyAllowList := &common.AttestationAllowList{Version: 1}
+	if err = emptyAllowList.Sign(policyKey, testL1ChainID, testMgmtContract); err != nil {
+		t.Fatal(err)
+	}
+	if err = holder.verifyAllowList(emptyAllowList, nil); err == nil {
+		t.Fatal("expected allow-list recognising no enclave to be rejected")
+	}
+
+	if err = allowList.Sign(policyKey, testL1ChainID, testMgmtContract); err != nil {
+		t.Fatal(err)
+	}
+	if err = holder.verifyAllowList(allowList, nil); err != nil {
+		t.Fatalf("could not verify allow-list: %s", err)
+	}
+	holder.setAllowList(allowList, gethcommon.HexToHash("0x01"))
+	if err = holder.check(upgradedReport); err != nil {
+		t.Fatalf("expected upgraded enclave to be recognised, got: %s", err)
+	}
+	if err = holder.check(attestation.Report{UniqueID: recognisedBuild.Bytes()}); err == nil {
+		t.Fatal("expected enclave removed from the allow-list not to be recognised")
+	}
+	if err = holder.verifyAllowList(allowList, allowList); err == nil {
+		t.Fatal("expected allow-list with the same version to be rejected")
+	}
+
+	// On an L1 fork without the allow-list, the config's policy is in force again.
+	holder.setAllowList(nil, gethcommon.HexToHash("0x02"))
+	if err = holder.check(attestation.Report{UniqueID: recognisedBuild.Bytes()}); err != nil {
+		t.Fatalf("expected enclave recognised by the config to be recognised, got: %s", err)
+	}
+}
@@ -0,0 +1,51 @@
+package enclave
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+	"github.com/obscuronet/go-obscuro/go/enclave/chainconfig"
+	"github.com/obscuronet/go-obscuro/go/enclave/db"
+
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+// Checks that the chain config can replace the version the enclave applied before it was restarted, given the batches
+// it has already processed, then stores it as the applied version.
+func applyChainConfig(storage db.Storage, chainConfig *chainconfig.ChainConfig, logger gethlog.Logger) error {
+	headBatchNumber := big.NewInt(0)
+	headBatch, err := storage.FetchHeadBatch()
+	switch {
+	case err == nil:
+		headBatchNumber = headBatch.Number()
+	case !errors.Is(err, errutil.ErrNotFound):
+		return fmt.Errorf("could not retrieve head batch. Cause: %w", err)
+	}
+
+	stored, err := storage.FetchChainConfig()
+	switch {
+	case err == nil:
+		// Before the first batch, nothing is active yet, so any change is allowed.
+		if headBatch != nil {
+			if err = chainConfig.CheckCompatible(stored, headBatchNumber.Uint64()); err != nil {
+				return err
+			}
+		}
+	case !errors.Is(err, errutil.ErrNotFound):
+		return fmt.Errorf("could not retrieve applied chain config. Cause: %w", err)
+	}
+	if err = storage.StoreChainConfig(chainConfig); err != nil {
+		return fmt.Errorf("could not store chain config. Cause: %w", err)
+	}
+
+	upcoming := chainConfig.Upcoming(headBatchNumber)
+	if len(upcoming) == 0 {
+		logger.Info(fmt.Sprintf("Applied chain config version %d. No changes are scheduled.", chainConfig.Version))
+	} else {
+		logger.Info(fmt.Sprintf("Applied chain config version %d. Scheduled changes: %s.", chainConfig.Version, strings.Join(upcoming, ", ")))
+	}
+	return nil
+}
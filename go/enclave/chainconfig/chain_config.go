@@ -0,0 +1,231 @@
+package chainconfig
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// The chain config schedules the changes to the behaviour of the network at batch numbers, so that every enclave applies
+// them at the same batch. A change is rolled out by publishing a new version of the config that schedules it at a batch
+// in the future, which each node can pick up at any restart before that batch, instead of all the nodes restarting
+// together. A new version cannot reschedule or cancel the changes that are already active.
+//
+// Each batch carries the fork ID of the changes that are active at its number. An enclave rejects the batches whose fork
+// ID differs from its own, which means the sequencer is running another config.
+
+// ProtocolFork is a change to the Obscuro protocol, activated at a batch number.
+type ProtocolFork string
+
+// InboundMessageReplayProtection records the delivery of each inbound cross-chain message in the rollup state, and
+// refuses the messages whose sender and sequence number were delivered already. It changes the state root of the
+// batches that deliver messages, so it must be scheduled at the same batch across the network.
+const InboundMessageReplayProtection ProtocolFork = "inboundMessageReplayProtection"
+
+// The protocol forks implemented by the enclave. A config that schedules an unknown fork is rejected, so that an enclave
+// whose code has not been upgraded stops, rather than diverging from the network when the fork activates.
+var knownProtocolForks = map[ProtocolFork]bool{
+	InboundMessageReplayProtection: true,
+}
+
+// ChainConfig is a version of the chain config.
+type ChainConfig struct {
+	// Increased by each new version. An enclave refuses to go back to an older version than the one it has applied.
+	Version uint64
+	// The batch numbers at which the EVM hardforks activate. If nil, all the hardforks up to London are active from genesis.
+	// Only the hardforks known to the version of geth the enclave is built with can be scheduled. geth 1.10.16 knows the
+	// hardforks up to Arrow Glacier and the merge, so Shanghai and the later hardforks cannot be scheduled.
+	EVM *params.ChainConfig
+	// The batch numbers at which the Obscuro protocol forks activate.
+	ProtocolForks map[ProtocolFork]uint64
+}
+
+// Default returns the chain config used when none is specified.
+func Default(chainID int64) *ChainConfig {
+	return &ChainConfig{EVM: DefaultEVMConfig(chainID)}
+}
+
+// DefaultEVMConfig returns the EVM config used when the chain config does not specify one.
+func DefaultEVMConfig(chainID int64) *params.ChainConfig {
+	return &params.ChainConfig{
+		ChainID:             big.NewInt(chainID),
+		HomesteadBlock:      gethcommon.Big0,
+		DAOForkBlock:        gethcommon.Big0,
+		EIP150Block:         gethcommon.Big0,
+		EIP155Block:         gethcommon.Big0,
+		EIP158Block:         gethcommon.Big0,
+		ByzantiumBlock:      gethcommon.Big0,
+		ConstantinopleBlock: gethcommon.Big0,
+		PetersburgBlock:     gethcommon.Big0,
+		IstanbulBlock:       gethcommon.Big0,
+		MuirGlacierBlock:    gethcommon.Big0,
+		BerlinBlock:         gethcommon.Big0,
+		LondonBlock:         gethcommon.Big0,
+	}
+}
+
+// Resolve returns a copy of the config with the defaults applied. Returns an error if the config is for another chain,
+// if its hardforks are not scheduled in order, or if it schedules a protocol fork the enclave does not implement.
+func (c *ChainConfig) Resolve(chainID int64) (*ChainConfig, error) {
+	resolved := &ChainConfig{Version: c.Version, ProtocolForks: map[ProtocolFork]uint64{}}
+	if c.EVM == nil {
+		resolved.EVM = DefaultEVMConfig(chainID)
+	} else {
+		evmConfig := *c.EVM
+		resolved.EVM = &evmConfig
+	}
+	for fork, number := range c.ProtocolForks {
+		if !knownProtocolForks[fork] {
+			return nil, fmt.Errorf("chain config version %d schedules protocol fork %s, which this version of the enclave does not implement", c.Version, fork)
+		}
+		resolved.ProtocolForks[fork] = number
+	}
+
+	if resolved.EVM.ChainID == nil {
+		resolved.EVM.ChainID = big.NewInt(chainID)
+	}
+	if resolved.EVM.ChainID.Cmp(big.NewInt(chainID)) != 0 {
+		return nil, fmt.Errorf("chain config is for chain %d, but the enclave is configured for chain %d", resolved.EVM.ChainID, chainID)
+	}
+	if err := resolved.EVM.CheckConfigForkOrder(); err != nil {
+		return nil, fmt.Errorf("invalid hardfork schedule in chain config version %d. Cause: %w", c.Version, err)
+	}
+	return resolved, nil
+}
+
+// IsActive returns whether the protocol fork is active at the given batch number.
+func (c *ChainConfig) IsActive(fork ProtocolFork, batchNumber *big.Int) bool {
+	activation, found := c.ProtocolForks[fork]
+	return found && batchNumber.Cmp(new(big.Int).SetUint64(activation)) >= 0
+}
+
+// ForkID returns the identifier of the changes that are active at the given batch number. It only changes at the
+// batches where changes activate, so enclaves running different versions of the config agree on it until the first
+// batch where they disagree on the active changes.
+func (c *ChainConfig) ForkID(batchNumber *big.Int) (gethcommon.Hash, error) {
+	var active []activation
+	for _, a := range c.activations() {
+		if a.Batch.Cmp(batchNumber) <= 0 {
+			active = append(active, a)
+		}
+	}
+	encoded, err := rlp.EncodeToBytes([]interface{}{c.EVM.ChainID, active})
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("could not encode chain config activations. Cause: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// CheckForkID returns an error if the fork ID of a batch differs from the one of the changes that the config has active
+// at the batch's number, which means the batch was produced under another version of the config.
+//
+// The batches produced before the fork ID was introduced have a zero fork ID. They were produced under the default
+// config, so they are accepted as long as the config has the same changes active at their number as the default config.
+func (c *ChainConfig) CheckForkID(batchNumber *big.Int, forkID gethcommon.Hash) error {
+	expected, err := c.ForkID(batchNumber)
+	if err != nil {
+		return err
+	}
+	if forkID == (gethcommon.Hash{}) {
+		legacy, err := Default(c.EVM.ChainID.Int64()).ForkID(batchNumber)
+		if err != nil {
+			return err
+		}
+		if expected != legacy {
+			return fmt.Errorf("batch %d has no fork ID, but chain config version %d has other changes active at that batch than the default config",
+				batchNumber, c.Version)
+		}
+		return nil
+	}
+	if forkID != expected {
+		return fmt.Errorf("batch %d has fork ID %s, but chain config version %d has fork ID %s at that batch",
+			batchNumber, forkID, c.Version, expected)
+	}
+	return nil
+}
+
+// Upcoming returns the changes that activate after the given batch number, in the order they are listed in the config.
+func (c *ChainConfig) Upcoming(batchNumber *big.Int) []string {
+	var upcoming []string
+	for _, a := range c.activations() {
+		if a.Batch.Cmp(batchNumber) > 0 {
+			upcoming = append(upcoming, fmt.Sprintf("%s at batch %d", a.Name, a.Batch))
+		}
+	}
+	return upcoming
+}
+
+// CheckCompatible returns an error if the config cannot replace the stored config, given the number of the head batch.
+func (c *ChainConfig) CheckCompatible(stored *ChainConfig, headBatchNumber uint64) error {
+	if c.Version < stored.Version {
+		return fmt.Errorf("chain config version %d is older than version %d, which the enclave has already applied", c.Version, stored.Version)
+	}
+	if compatErr := stored.EVM.CheckCompatible(c.EVM, headBatchNumber); compatErr != nil {
+		return fmt.Errorf("chain config version %d changes an EVM hardfork that is already active. Cause: %w", c.Version, compatErr)
+	}
+
+	head := new(big.Int).SetUint64(headBatchNumber)
+	forks := map[ProtocolFork]bool{}
+	for fork := range stored.ProtocolForks {
+		forks[fork] = true
+	}
+	for fork := range c.ProtocolForks {
+		forks[fork] = true
+	}
+	for fork := range forks {
+		storedNumber, storedFound := stored.ProtocolForks[fork]
+		newNumber, newFound := c.ProtocolForks[fork]
+		if storedFound == newFound && storedNumber == newNumber {
+			continue
+		}
+		if stored.IsActive(fork, head) || c.IsActive(fork, head) {
+			return fmt.Errorf("chain config version %d changes protocol fork %s, which is already active at batch %d", c.Version, fork, headBatchNumber)
+		}
+	}
+	return nil
+}
+
+// A change that activates at a batch number.
+type activation struct {
+	Name  string
+	Batch *big.Int
+}
+
+// Returns the scheduled changes: the EVM hardforks in the order they were released, then the protocol forks by name.
+func (c *ChainConfig) activations() []activation {
+	evmForks := []activation{
+		{"homestead", c.EVM.HomesteadBlock},
+		{"daoFork", c.EVM.DAOForkBlock},
+		{"eip150", c.EVM.EIP150Block},
+		{"eip155", c.EVM.EIP155Block},
+		{"eip158", c.EVM.EIP158Block},
+		{"byzantium", c.EVM.ByzantiumBlock},
+		{"constantinople", c.EVM.ConstantinopleBlock},
+		{"petersburg", c.EVM.PetersburgBlock},
+		{"istanbul", c.EVM.IstanbulBlock},
+		{"muirGlacier", c.EVM.MuirGlacierBlock},
+		{"berlin", c.EVM.BerlinBlock},
+		{"london", c.EVM.LondonBlock},
+		{"arrowGlacier", c.EVM.ArrowGlacierBlock},
+		{"mergeFork", c.EVM.MergeForkBlock},
+	}
+	var activations []activation
+	for _, fork := range evmForks {
+		if fork.Batch != nil {
+			activations = append(activations, fork)
+		}
+	}
+
+	var protocolForks []activation
+	for fork, number := range c.ProtocolForks {
+		protocolForks = append(protocolForks, activation{string(fork), new(big.Int).SetUint64(number)})
+	}
+	sort.Slice(protocolForks, func(i, j int) bool { return protocolForks[i].Name < protocolForks[j].Name })
+	return append(activations, protocolForks...)
+}
@@ -0,0 +1,105 @@
+package chainconfig
+
+import (
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	testChainID                  = 777
+	testFork        ProtocolFork = "testFork"
+	testForkBatch                = 100
+	unknownTestFork ProtocolFork = "unknownTestFork"
+)
+
+func TestForkIDOnlyChangesAtActivations(t *testing.T) {
+	knownProtocolForks[testFork] = true
+	defer delete(knownProtocolForks, testFork)
+
+	original := Default(testChainID)
+	upgraded, err := (&ChainConfig{Version: 1, ProtocolForks: map[ProtocolFork]uint64{testFork: testForkBatch}}).Resolve(testChainID)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if forkID(t, original, testForkBatch-1) != forkID(t, upgraded, testForkBatch-1) {
+		t.Fatal("expected fork IDs to match before the protocol fork activates")
+	}
+	if forkID(t, original, testForkBatch) == forkID(t, upgraded, testForkBatch) {
+		t.Fatal("expected fork IDs to differ once the protocol fork activates")
+	}
+	if forkID(t, upgraded, testForkBatch) != forkID(t, upgraded, testForkBatch+1) {
+		t.Fatal("expected fork ID not to change between activations")
+	}
+	if upgraded.IsActive(testFork, big.NewInt(testForkBatch-1)) || !upgraded.IsActive(testFork, big.NewInt(testForkBatch)) {
+		t.Fatal("expected protocol fork to activate at its batch")
+	}
+}
+
+func TestZeroForkIDIsCheckedAgainstTheDefaultConfig(t *testing.T) {
+	knownProtocolForks[testFork] = true
+	defer delete(knownProtocolForks, testFork)
+
+	upgraded, err := (&ChainConfig{Version: 1, ProtocolForks: map[ProtocolFork]uint64{testFork: testForkBatch}}).Resolve(testChainID)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if err = upgraded.CheckForkID(big.NewInt(testForkBatch-1), forkID(t, upgraded, testForkBatch-1)); err != nil {
+		t.Fatalf("expected the fork ID of the config to be accepted, got %s", err)
+	}
+	if err = upgraded.CheckForkID(big.NewInt(testForkBatch-1), gethcommon.Hash{}); err != nil {
+		t.Fatalf("expected a batch without fork ID to be accepted before the protocol fork activates, got %s", err)
+	}
+	if err = upgraded.CheckForkID(big.NewInt(testForkBatch), gethcommon.Hash{}); err == nil {
+		t.Fatal("expected a batch without fork ID to be rejected once the protocol fork activates")
+	}
+	if err = upgraded.CheckForkID(big.NewInt(testForkBatch), forkID(t, upgraded, testForkBatch-1)); err == nil {
+		t.Fatal("expected a batch with the fork ID of other active changes to be rejected")
+	}
+}
+
+func TestChainConfigCannotChangeActiveForks(t *testing.T) {
+	knownProtocolForks[testFork] = true
+	defer delete(knownProtocolForks, testFork)
+
+	original := Default(testChainID)
+	upgraded, err := (&ChainConfig{Version: 1, ProtocolForks: map[ProtocolFork]uint64{testFork: testForkBatch}}).Resolve(testChainID)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if err = upgraded.CheckCompatible(original, testForkBatch-1); err != nil {
+		t.Fatalf("expected fork scheduled after the head batch to be allowed, got %s", err)
+	}
+	if err = upgraded.CheckCompatible(original, testForkBatch); err == nil {
+		t.Fatal("expected fork scheduled at or before the head batch to be rejected")
+	}
+	if err = original.CheckCompatible(upgraded, testForkBatch-1); err == nil {
+		t.Fatal("expected older version to be rejected")
+	}
+
+	delayedLondon := Default(testChainID)
+	delayedLondon.Version = 1
+	delayedLondon.EVM.LondonBlock = big.NewInt(testForkBatch)
+	if err = delayedLondon.CheckCompatible(original, testForkBatch-1); err == nil {
+		t.Fatal("expected rescheduling an active hardfork to be rejected")
+	}
+}
+
+func TestChainConfigWithUnknownProtocolForkIsRejected(t *testing.T) {
+	cfg := &ChainConfig{ProtocolForks: map[ProtocolFork]uint64{unknownTestFork: testForkBatch}}
+	if _, err := cfg.Resolve(testChainID); err == nil {
+		t.Fatal("expected chain config with unknown protocol fork to be rejected")
+	}
+}
+
+func forkID(t *testing.T, config *ChainConfig, batchNumber int64) gethcommon.Hash {
+	t.Helper()
+	id, err := config.ForkID(big.NewInt(batchNumber))
+	if err != nil {
+		t.Fatalf("could not compute fork ID. Cause: %s", err)
+	}
+	return id
+}
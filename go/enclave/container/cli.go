@@ -5,120 +5,148 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"time"
 
 	"github.com/obscuronet/go-obscuro/go/common"
 
 	gethcommon "github.com/ethereum/go-ethereum/common"
-	"github.com/naoina/toml"
 	"github.com/obscuronet/go-obscuro/go/config"
 )
 
-// EnclaveConfigToml is the structure that an enclave's .toml config is parsed into.
+// EnclaveConfigToml is the structure that an enclave's config is loaded into, from its .toml config file, environment
+// variables and flags. See config.Loader for the meaning of the struct tags.
 type EnclaveConfigToml struct {
-	HostID                    string
-	HostAddress               string
-	Address                   string
-	NodeType                  string
-	L1ChainID                 int64
-	ObscuroChainID            int64
-	WillAttest                bool
-	ValidateL1Blocks          bool
-	ManagementContractAddress string
-	LogLevel                  int
-	LogPath                   string
-	UseInMemoryDB             bool
+	HostID                    string `flag:"hostID" validate:"address"`
+	HostAddress               string `flag:"hostAddress" validate:"hostport"`
+	Address                   string `flag:"address" validate:"required,hostport"`
+	NodeType                  string `flag:"nodeType" validate:"required,oneof=sequencer|validator"`
+	L1ChainID                 int64  `flag:"l1ChainID"`
+	ObscuroChainID            int64  `flag:"obscuroChainID"`
+	WillAttest                bool   `flag:"willAttest"`
+	ValidateL1Blocks          bool   `flag:"validateL1Blocks"`
+	ManagementContractAddress string `flag:"managementContractAddress" validate:"address"`
+	LogLevel                  int    `flag:"logLevel" validate:"min=0,max=5" reload:"true"`
+	LogPath                   string `flag:"logPath"`
+	UseInMemoryDB             bool   `flag:"useInMemoryDB"`
 	GenesisJSON               string
-	EdgelessDBHost            string
-	SqliteDBPath              string
-	ProfilerEnabled           bool
-	MinGasPrice               int64
-	MessageBusAddress         string
-	SequencerID               string
-	ObscuroGenesis            string
-	Cadence                   uint64
-}
-
-// ParseConfig returns a config.EnclaveConfig based on either the file identified by the `config` flag, or the flags
-// with specific defaults (if the `config` flag isn't specified).
-func ParseConfig() (config.EnclaveConfig, error) {
-	cfg := config.DefaultEnclaveConfig()
-	flagUsageMap := getFlagUsageMap()
+	EdgelessDBHost            string `flag:"edgelessDBHost"`
+	SqliteDBPath              string `flag:"sqliteDBPath"`
+	ProfilerEnabled           bool   `flag:"profilerEnabled"`
+	MinGasPrice               int64  `flag:"minGasPrice" validate:"min=0"`
+	MessageBusAddress         string `flag:"messageBusAddress" validate:"address"`
+	SequencerID               string `flag:"sequencerID" validate:"address"`
+	ObscuroGenesis            string `flag:"obscuroGenesis"`
+	Cadence                   uint64 `flag:"Cadence"`
+	TimeBasedBatches          bool   `flag:"timeBasedBatches"`
+	MaxBatchGas               uint64 `flag:"maxBatchGas"`
+	MaxBatchSize              uint64 `flag:"maxBatchSize"`
+	MaxRollupSize             uint64 `flag:"maxRollupSize"`
+	RollupInterval            int    `flag:"rollupIntervalSecs" validate:"min=0"`
+	MaxPendingRollupSize      uint64 `flag:"maxPendingRollupSize"`
+	RollupMaxL1BaseFee        int64  `flag:"rollupMaxL1BaseFee" validate:"min=0"`
+	RollupL1BaseFeeDeadline   int    `flag:"rollupL1BaseFeeDeadlineSecs" validate:"min=0"`
+	RollupInclusionTimeout    int    `flag:"rollupInclusionTimeoutSecs" validate:"min=0"`
+	StateRetentionBatches     uint64 `flag:"stateRetentionBatches"`
+	StateCheckpointInterval   uint64 `flag:"stateCheckpointInterval"`
+	StateCheckpointsRetained  uint64 `flag:"stateCheckpointsRetained"`
+	TrieCacheSizeMB           int    `flag:"trieCacheSizeMB" validate:"min=0"`
+	DebugNamespaceEnabled     bool   `flag:"debugNamespaceEnabled"`
 
-	configPath := flag.String(configName, "", flagUsageMap[configName])
-	hostID := flag.String(hostIDName, cfg.HostID.Hex(), flagUsageMap[hostIDName])
-	hostAddress := flag.String(hostAddressName, cfg.HostAddress, flagUsageMap[hostAddressName])
-	address := flag.String(addressName, cfg.Address, flagUsageMap[addressName])
-	nodeTypeStr := flag.String(nodeTypeName, cfg.NodeType.String(), flagUsageMap[nodeTypeName])
-	l1ChainID := flag.Int64(l1ChainIDName, cfg.L1ChainID, flagUsageMap[l1ChainIDName])
-	obscuroChainID := flag.Int64(obscuroChainIDName, cfg.ObscuroChainID, flagUsageMap[obscuroChainIDName])
-	willAttest := flag.Bool(willAttestName, cfg.WillAttest, flagUsageMap[willAttestName])
-	validateL1Blocks := flag.Bool(validateL1BlocksName, cfg.ValidateL1Blocks, flagUsageMap[validateL1BlocksName])
-	managementContractAddress := flag.String(ManagementContractAddressName, cfg.ManagementContractAddress.Hex(), flagUsageMap[ManagementContractAddressName])
-	loglevel := flag.Int(logLevelName, cfg.LogLevel, flagUsageMap[logLevelName])
-	logPath := flag.String(logPathName, cfg.LogPath, flagUsageMap[logPathName])
-	useInMemoryDB := flag.Bool(useInMemoryDBName, cfg.UseInMemoryDB, flagUsageMap[useInMemoryDBName])
-	edgelessDBHost := flag.String(edgelessDBHostName, cfg.EdgelessDBHost, flagUsageMap[edgelessDBHostName])
-	sqliteDBPath := flag.String(sqliteDBPathName, cfg.SqliteDBPath, flagUsageMap[sqliteDBPathName])
-	profilerEnabled := flag.Bool(profilerEnabledName, cfg.ProfilerEnabled, flagUsageMap[profilerEnabledName])
-	minGasPrice := flag.Int64(minGasPriceName, cfg.MinGasPrice.Int64(), flagUsageMap[minGasPriceName])
-	messageBusAddress := flag.String(messageBusAddressName, cfg.MessageBusAddress.Hex(), flagUsageMap[messageBusAddressName])
-	sequencerID := flag.String(sequencerIDName, cfg.SequencerID.Hex(), flagUsageMap[sequencerIDName])
-	obscuroGenesis := flag.String(obscuroGenesisName, cfg.ObscuroGenesis, flagUsageMap[obscuroGenesisName])
-	Cadence := flag.Uint64(CadenceName, cfg.Cadence, flagUsageMap[CadenceName])
-
-	flag.Parse()
+	// Attestation config.
+	AttestationUniqueIDs          []string `flag:"attestationUniqueIDs" validate:"hex32"`
+	AttestationSignerIDs          []string `flag:"attestationSignerIDs" validate:"hex32"`
+	AttestationMinSecurityVersion uint64   `flag:"attestationMinSVN"`
+	AttestationProductID          uint16   `flag:"attestationProductID"`
+	AttestationAllowDebug         bool     `flag:"attestationAllowDebug"`
+	AttestationAllowedTCBStatuses []string `flag:"attestationTCBStatuses"`
+	AttestationAllowListSigner    string   `flag:"attestationAllowListSigner" validate:"address"`
+	AttestedTLS                   bool     `flag:"attestedTLS"`
+}
 
-	if *configPath != "" {
-		return fileBasedConfig(*configPath)
+// ParseConfig loads a config.EnclaveConfig from, in increasing order of precedence, the defaults, the file identified
+// by the `config` flag, the `EDG_OBSCURO_ENCLAVE_*` environment variables and the flags, then validates it. The
+// returned loader prints the effective config and reloads the config.
+func ParseConfig() (config.EnclaveConfig, *config.Loader, error) {
+	tomlConfig := enclaveConfigTomlFrom(config.DefaultEnclaveConfig())
+	loader := config.NewLoader(&tomlConfig, flag.CommandLine, envPrefix, getFlagUsageMap())
+	if err := loader.Load(os.Args[1:]); err != nil {
+		return config.EnclaveConfig{}, nil, err
 	}
 
-	nodeType, err := common.ToNodeType(*nodeTypeStr)
+	cfg, err := toEnclaveConfig(tomlConfig)
 	if err != nil {
-		return config.EnclaveConfig{}, fmt.Errorf("unrecognised node type '%s'", *nodeTypeStr)
+		return config.EnclaveConfig{}, nil, err
+	}
+	if err = cfg.Validate(); err != nil {
+		return config.EnclaveConfig{}, nil, err
 	}
 
-	cfg.HostID = gethcommon.HexToAddress(*hostID)
-	cfg.HostAddress = *hostAddress
-	cfg.Address = *address
-	cfg.NodeType = nodeType
-	cfg.L1ChainID = *l1ChainID
-	cfg.ObscuroChainID = *obscuroChainID
-	cfg.WillAttest = *willAttest
-	cfg.ValidateL1Blocks = *validateL1Blocks
-	cfg.ManagementContractAddress = gethcommon.HexToAddress(*managementContractAddress)
-	cfg.LogLevel = *loglevel
-	cfg.LogPath = *logPath
-	cfg.UseInMemoryDB = *useInMemoryDB
-	cfg.EdgelessDBHost = *edgelessDBHost
-	cfg.SqliteDBPath = *sqliteDBPath
-	cfg.ProfilerEnabled = *profilerEnabled
-	cfg.MinGasPrice = big.NewInt(*minGasPrice)
-	cfg.MessageBusAddress = gethcommon.HexToAddress(*messageBusAddress)
-	cfg.SequencerID = gethcommon.HexToAddress(*sequencerID)
-	cfg.ObscuroGenesis = *obscuroGenesis
-	cfg.Cadence = *Cadence
-
-	return cfg, nil
+	return cfg, loader, nil
 }
 
-// Parses the config from the .toml file at configPath.
-func fileBasedConfig(configPath string) (config.EnclaveConfig, error) {
-	bytes, err := os.ReadFile(configPath)
-	if err != nil {
-		panic(fmt.Sprintf("could not read config file at %s. Cause: %s", configPath, err))
-	}
+// Converts an EnclaveConfig to the structure its config is loaded into.
+func enclaveConfigTomlFrom(cfg config.EnclaveConfig) EnclaveConfigToml {
+	return EnclaveConfigToml{
+		HostID:                    cfg.HostID.Hex(),
+		HostAddress:               cfg.HostAddress,
+		Address:                   cfg.Address,
+		NodeType:                  cfg.NodeType.String(),
+		L1ChainID:                 cfg.L1ChainID,
+		ObscuroChainID:            cfg.ObscuroChainID,
+		WillAttest:                cfg.WillAttest,
+		ValidateL1Blocks:          cfg.ValidateL1Blocks,
+		ManagementContractAddress: cfg.ManagementContractAddress.Hex(),
+		LogLevel:                  cfg.LogLevel,
+		LogPath:                   cfg.LogPath,
+		UseInMemoryDB:             cfg.UseInMemoryDB,
+		GenesisJSON:               string(cfg.GenesisJSON),
+		EdgelessDBHost:            cfg.EdgelessDBHost,
+		SqliteDBPath:              cfg.SqliteDBPath,
+		ProfilerEnabled:           cfg.ProfilerEnabled,
+		MinGasPrice:               cfg.MinGasPrice.Int64(),
+		MessageBusAddress:         cfg.MessageBusAddress.Hex(),
+		SequencerID:               cfg.SequencerID.Hex(),
+		ObscuroGenesis:            cfg.ObscuroGenesis,
+		Cadence:                   cfg.Cadence,
+		TimeBasedBatches:          cfg.TimeBasedBatches,
+		MaxBatchGas:               cfg.MaxBatchGas,
+		MaxBatchSize:              cfg.MaxBatchSize,
+		MaxRollupSize:             cfg.MaxRollupSize,
+		RollupInterval:            int(cfg.RollupInterval.Seconds()),
+		MaxPendingRollupSize:      cfg.MaxPendingRollupSize,
+		RollupMaxL1BaseFee:        cfg.RollupMaxL1BaseFee.Int64(),
+		RollupL1BaseFeeDeadline:   int(cfg.RollupL1BaseFeeDeadline.Seconds()),
+		RollupInclusionTimeout:    int(cfg.RollupInclusionTimeout.Seconds()),
+		StateRetentionBatches:     cfg.StateRetentionBatches,
+		StateCheckpointInterval:   cfg.StateCheckpointInterval,
+		StateCheckpointsRetained:  cfg.StateCheckpointsRetained,
+		TrieCacheSizeMB:           cfg.TrieCacheSizeMB,
+		DebugNamespaceEnabled:     cfg.DebugNamespaceEnabled,
 
-	var tomlConfig EnclaveConfigToml
-	err = toml.Unmarshal(bytes, &tomlConfig)
-	if err != nil {
-		panic(fmt.Sprintf("could not read config file at %s. Cause: %s", configPath, err))
+		// Attestation config.
+		AttestationUniqueIDs:          config.FromHashes(cfg.AttestationUniqueIDs),
+		AttestationSignerIDs:          config.FromHashes(cfg.AttestationSignerIDs),
+		AttestationMinSecurityVersion: cfg.AttestationMinSecurityVersion,
+		AttestationProductID:          cfg.AttestationProductID,
+		AttestationAllowDebug:         cfg.AttestationAllowDebug,
+		AttestationAllowedTCBStatuses: cfg.AttestationAllowedTCBStatuses,
+		AttestationAllowListSigner:    cfg.AttestationAllowListSigner.Hex(),
+		AttestedTLS:                   cfg.AttestedTLS,
 	}
+}
 
+// Converts the loaded config to an EnclaveConfig.
+func toEnclaveConfig(tomlConfig EnclaveConfigToml) (config.EnclaveConfig, error) {
 	nodeType, err := common.ToNodeType(tomlConfig.NodeType)
 	if err != nil {
 		return config.EnclaveConfig{}, fmt.Errorf("unrecognised node type '%s'", tomlConfig.NodeType)
 	}
 
+	var genesisJSON []byte
+	if tomlConfig.GenesisJSON != "" {
+		genesisJSON = []byte(tomlConfig.GenesisJSON)
+	}
+
 	return config.EnclaveConfig{
 		HostID:                    gethcommon.HexToAddress(tomlConfig.HostID),
 		HostAddress:               tomlConfig.HostAddress,
@@ -132,9 +160,38 @@ func fileBasedConfig(configPath string) (config.EnclaveConfig, error) {
 		LogLevel:                  tomlConfig.LogLevel,
 		LogPath:                   tomlConfig.LogPath,
 		UseInMemoryDB:             tomlConfig.UseInMemoryDB,
-		GenesisJSON:               []byte(tomlConfig.GenesisJSON),
+		GenesisJSON:               genesisJSON,
 		EdgelessDBHost:            tomlConfig.EdgelessDBHost,
 		SqliteDBPath:              tomlConfig.SqliteDBPath,
 		ProfilerEnabled:           tomlConfig.ProfilerEnabled,
+		MinGasPrice:               big.NewInt(tomlConfig.MinGasPrice),
+		MessageBusAddress:         gethcommon.HexToAddress(tomlConfig.MessageBusAddress),
+		SequencerID:               gethcommon.HexToAddress(tomlConfig.SequencerID),
+		ObscuroGenesis:            tomlConfig.ObscuroGenesis,
+		Cadence:                   tomlConfig.Cadence,
+		TimeBasedBatches:          tomlConfig.TimeBasedBatches,
+		MaxBatchGas:               tomlConfig.MaxBatchGas,
+		MaxBatchSize:              tomlConfig.MaxBatchSize,
+		MaxRollupSize:             tomlConfig.MaxRollupSize,
+		RollupInterval:            time.Duration(tomlConfig.RollupInterval) * time.Second,
+		MaxPendingRollupSize:      tomlConfig.MaxPendingRollupSize,
+		RollupMaxL1BaseFee:        big.NewInt(tomlConfig.RollupMaxL1BaseFee),
+		RollupL1BaseFeeDeadline:   time.Duration(tomlConfig.RollupL1BaseFeeDeadline) * time.Second,
+		RollupInclusionTimeout:    time.Duration(tomlConfig.RollupInclusionTimeout) * time.Second,
+		StateRetentionBatches:     tomlConfig.StateRetentionBatches,
+		StateCheckpointInterval:   tomlConfig.StateCheckpointInterval,
+		StateCheckpointsRetained:  tomlConfig.StateCheckpointsRetained,
+		TrieCacheSizeMB:           tomlConfig.TrieCacheSizeMB,
+		DebugNamespaceEnabled:     tomlConfig.DebugNamespaceEnabled,
+
+		// Attestation config.
+		AttestationUniqueIDs:          config.ToHashes(tomlConfig.AttestationUniqueIDs),
+		AttestationSignerIDs:          config.ToHashes(tomlConfig.AttestationSignerIDs),
+		AttestationMinSecurityVersion: tomlConfig.AttestationMinSecurityVersion,
+		AttestationProductID:          tomlConfig.AttestationProductID,
+		AttestationAllowDebug:         tomlConfig.AttestationAllowDebug,
+		AttestationAllowedTCBStatuses: tomlConfig.AttestationAllowedTCBStatuses,
+		AttestationAllowListSigner:    gethcommon.HexToAddress(tomlConfig.AttestationAllowListSigner),
+		AttestedTLS:                   tomlConfig.AttestedTLS,
 	}, nil
 }
@@ -1,8 +1,14 @@
 package container
 
+import "github.com/obscuronet/go-obscuro/go/config"
+
+// The prefix of the names of the environment variables that set the config, e.g. EDG_OBSCURO_ENCLAVE_LOG_LEVEL. ego
+// only passes the environment variables prefixed with EDG_ to the enclave.
+const envPrefix = "EDG_OBSCURO_ENCLAVE"
+
 // Flag names.
 const (
-	configName                    = "config"
+	configName                    = config.ConfigFlagName
 	hostIDName                    = "hostID"
 	hostAddressName               = "hostAddress"
 	addressName                   = "address"
@@ -23,13 +29,39 @@ const (
 	sequencerIDName               = "sequencerID"
 	obscuroGenesisName            = "obscuroGenesis"
 	CadenceName                   = "Cadence"
+	timeBasedBatchesName          = "timeBasedBatches"
+	maxBatchGasName               = "maxBatchGas"
+	maxBatchSizeName              = "maxBatchSize"
+	maxRollupSizeName             = "maxRollupSize"
+	stateRetentionBatchesName     = "stateRetentionBatches"
+	stateCheckpointIntervalName   = "stateCheckpointInterval"
+	stateCheckpointsRetainedName  = "stateCheckpointsRetained"
+	trieCacheSizeMBName           = "trieCacheSizeMB"
+	debugNamespaceEnabledName     = "debugNamespaceEnabled"
+
+	// Rollup publication policy flags.
+	rollupIntervalSecsName          = "rollupIntervalSecs"
+	maxPendingRollupSizeName        = "maxPendingRollupSize"
+	rollupMaxL1BaseFeeName          = "rollupMaxL1BaseFee"
+	rollupL1BaseFeeDeadlineSecsName = "rollupL1BaseFeeDeadlineSecs"
+	rollupInclusionTimeoutSecsName  = "rollupInclusionTimeoutSecs"
+
+	// Attestation flags.
+	attestationUniqueIDsName       = "attestationUniqueIDs"
+	attestationSignerIDsName       = "attestationSignerIDs"
+	attestationMinSVNName          = "attestationMinSVN"
+	attestationProductIDName       = "attestationProductID"
+	attestationAllowDebugName      = "attestationAllowDebug"
+	attestationTCBStatusesName     = "attestationTCBStatuses"
+	attestationAllowListSignerName = "attestationAllowListSigner"
+	attestedTLSName                = "attestedTLS"
 )
 
 // Returns a map of the flag usages.
 // While we could just use constants instead of a map, this approach allows us to test that all the expected flags are defined.
 func getFlagUsageMap() map[string]string {
 	return map[string]string{
-		configName:                    "The path to the node's config file. Overrides all other flags",
+		configName:                    "The path to the enclave's .toml config file. Environment variables and flags override its values",
 		hostIDName:                    "The 20 bytes of the address of the Obscuro host this enclave serves",
 		hostAddressName:               "The peer-to-peer IP address of the Obscuro host this enclave serves",
 		addressName:                   "The address on which to serve the Obscuro enclave service",
@@ -48,7 +80,33 @@ func getFlagUsageMap() map[string]string {
 		minGasPriceName:               "The minimum gas price for mining a transaction",
 		messageBusAddressName:         "The address of the L1 message bus contract owned by the management contract.",
 		sequencerIDName:               "The 20 bytes of the address of the sequencer for this network",
-		obscuroGenesisName:            "The json string with the obscuro genesis",
-		CadenceName:                   "The amounts of batches between each rollup",
+		obscuroGenesisName:            "The json string with the obscuro genesis: the chain parameters and the versioned chain config, and the prefunded accounts and predeployed contracts",
+		CadenceName:                   "The maximum number of batches pending publication before the sequencer publishes a rollup. Zero means no limit",
+		timeBasedBatchesName:          "Whether the sequencer produces batches when requested by the host on its batch interval, rather than for each L1 block",
+		maxBatchGasName:               "The gas limit of the batches produced by the sequencer. Zero means no limit",
+		maxBatchSizeName:              "The maximum size in bytes of the transactions in a batch produced by the sequencer. Zero means no limit",
+		maxRollupSizeName:             "The maximum size in bytes of the calldata of a rollup transaction. Larger rollups are split. Zero means no limit",
+		stateRetentionBatchesName:     "The number of most recent batches whose state is retained. Zero retains the state of every batch",
+		stateCheckpointIntervalName:   "The interval in batches at which the state is persisted as a checkpoint. Zero disables checkpoints",
+		stateCheckpointsRetainedName:  "The number of most recent state checkpoints that are retained. Zero retains every checkpoint",
+		trieCacheSizeMBName:           "The size in MB of the in-memory cache of trie nodes",
+		debugNamespaceEnabledName:     "Whether the enclave serves the debug_traceTransaction and debug_traceCall requests (Defaults to false)",
+
+		// Rollup publication policy flags.
+		rollupIntervalSecsName:          "The maximum time between rollups in seconds, measured using the batches' timestamps. Zero means no limit",
+		maxPendingRollupSizeName:        "The maximum size in bytes of the batches pending publication before the sequencer publishes a rollup. Zero means no limit",
+		rollupMaxL1BaseFeeName:          "The L1 base fee in wei above which the sequencer defers rollups until the deadline. Zero means no ceiling",
+		rollupL1BaseFeeDeadlineSecsName: "The time since the last rollup in seconds after which the sequencer publishes rollups regardless of the L1 base fee",
+		rollupInclusionTimeoutSecsName:  "How long in seconds the sequencer waits for a rollup to appear on the L1 before rolling up its batches again",
+
+		// Attestation flags.
+		attestationUniqueIDsName:       "A comma-separated list of the MRENCLAVE values of the enclaves that are sent the network secret",
+		attestationSignerIDsName:       "A comma-separated list of the MRSIGNER values of the enclaves that are sent the network secret",
+		attestationMinSVNName:          "The minimum security version number of the enclaves that are sent the network secret",
+		attestationProductIDName:       "The product ID of the enclaves that are sent the network secret. Zero means any product ID",
+		attestationAllowDebugName:      "Whether enclaves running in debug mode are sent the network secret",
+		attestationTCBStatusesName:     "A comma-separated list of the TCB statuses accepted in addition to UpToDate (e.g. SWHardeningNeeded)",
+		attestationAllowListSignerName: "The address of the key that signs the attestation allow-lists and secret rotation requests published in the management contract",
+		attestedTLSName:                "Whether the RPC server only accepts TLS connections from a host whose certificate is signed by the key of the host ID. Can only be turned off if the enclave does not attest",
 	}
 }
@@ -1,6 +1,7 @@
 package container
 
 import (
+	"flag"
 	"os"
 	"path"
 	"reflect"
@@ -23,7 +24,12 @@ func TestConfigIsParsedFromTomlFileIfConfigFlagIsPresent(t *testing.T) {
 		panic(err)
 	}
 
-	cfg, err := fileBasedConfig(path.Join(wd, testToml))
+	tomlConfig := enclaveConfigTomlFrom(config.DefaultEnclaveConfig())
+	loader := config.NewLoader(&tomlConfig, flag.NewFlagSet("test", flag.ContinueOnError), envPrefix, getFlagUsageMap())
+	if err = loader.Load([]string{"--" + configName, path.Join(wd, testToml)}); err != nil {
+		t.Fatalf("could not load config. Cause: %s", err)
+	}
+	cfg, err := toEnclaveConfig(tomlConfig)
 	if err != nil {
 		t.Fatalf("could not parse config. Cause: %s", err)
 	}
@@ -35,7 +41,7 @@ func TestConfigIsParsedFromTomlFileIfConfigFlagIsPresent(t *testing.T) {
 func TestConfigIsParsedFromCmdLineFlagsIfConfigFlagIsNotPresent(t *testing.T) {
 	os.Args = append(os.Args, "--"+l1ChainIDName, strconv.FormatInt(expectedChainID, 10))
 
-	cfg, err := ParseConfig()
+	cfg, _, err := ParseConfig()
 	if err != nil {
 		t.Fatalf("could not parse config. Cause: %s", err)
 	}
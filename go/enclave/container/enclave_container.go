@@ -2,7 +2,7 @@ package container
 
 import (
 	"context"
-	"fmt"
+	"crypto/tls"
 
 	gethlog "github.com/ethereum/go-ethereum/log"
 	"github.com/obscuronet/go-obscuro/go/common"
@@ -23,6 +23,8 @@ type EnclaveContainer struct {
 	Enclave   common.Enclave
 	RPCServer *enclave.RPCServer
 	Logger    gethlog.Logger
+
+	stopConfigWatch func() // stops reloading the config on SIGHUP, if it is being reloaded
 }
 
 func (e *EnclaveContainer) Start() error {
@@ -35,6 +37,9 @@ func (e *EnclaveContainer) Start() error {
 }
 
 func (e *EnclaveContainer) Stop() error {
+	if e.stopConfigWatch != nil {
+		e.stopConfigWatch()
+	}
 	_, err := e.RPCServer.Stop(context.Background(), nil)
 	if err != nil {
 		e.Logger.Warn("unable to cleanly stop enclave", log.ErrKey, err)
@@ -43,16 +48,24 @@ func (e *EnclaveContainer) Stop() error {
 	return nil
 }
 
+// WatchConfig reloads the config loaded by loader when the process receives a SIGHUP, and applies the new log level.
+func (e *EnclaveContainer) WatchConfig(loader *config.Loader) {
+	e.stopConfigWatch = loader.WatchReload(e.Logger, func(schema interface{}) {
+		logLevel := schema.(*EnclaveConfigToml).LogLevel
+		if err := log.SetLevel(e.Logger, logLevel); err != nil {
+			e.Logger.Error("Could not change the log level.", log.ErrKey, err)
+			return
+		}
+		e.Logger.Info("Changed the log level.", "level", logLevel)
+	})
+}
+
 // NewEnclaveContainerFromConfig wires up the components of the Enclave and its RPC server. Manages their lifecycle/monitors their status
 func NewEnclaveContainerFromConfig(config config.EnclaveConfig) *EnclaveContainer {
 	// todo - improve this wiring, perhaps setup DB etc. at this level and inject into enclave
 	//  (at that point the WithLogger constructor could be a full DI constructor like the HostContainer tries, for testability)
 	logger := log.New(log.EnclaveCmp, config.LogLevel, config.LogPath, log.NodeIDKey, config.HostID)
 
-	// todo - this is for debugging purposes only, should be remove in the future
-	fmt.Printf("Building enclave container with config: %+v\n", config)
-	logger.Info(fmt.Sprintf("Building enclave container with config: %+v", config))
-
 	return NewEnclaveContainerWithLogger(config, logger)
 }
 
@@ -71,7 +84,14 @@ func NewEnclaveContainerWithLogger(config config.EnclaveConfig, logger gethlog.L
 	}
 
 	encl := enclave.NewEnclave(config, genesis, mgmtContractLib, logger)
-	rpcServer := enclave.NewEnclaveRPCServer(config.Address, encl, logger)
+	var tlsConfig *tls.Config
+	if config.AttestedTLS {
+		tlsConfig, err = enclave.NewRPCServerTLSConfig(encl)
+		if err != nil {
+			logger.Crit("unable to create attested TLS config for the enclave RPC server", log.ErrKey, err)
+		}
+	}
+	rpcServer := enclave.NewEnclaveRPCServer(config.Address, encl, tlsConfig, logger)
 
 	return &EnclaveContainer{
 		Enclave:   encl,
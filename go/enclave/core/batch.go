@@ -1,6 +1,7 @@
 package core
 
 import (
+	"fmt"
 	"math/big"
 	"sync/atomic"
 	"time"
@@ -64,11 +65,15 @@ func (b *Batch) ToExtBatch(transactionBlobCrypto crypto.TransactionBlobCrypto) *
 	}
 }
 
-func ToBatch(extBatch *common.ExtBatch, transactionBlobCrypto crypto.TransactionBlobCrypto) *Batch {
+func ToBatch(extBatch *common.ExtBatch, transactionBlobCrypto crypto.TransactionBlobCrypto) (*Batch, error) {
+	txs, err := transactionBlobCrypto.Decrypt(extBatch.EncryptedTxBlob)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt transactions of batch %s. Cause: %w", extBatch.Hash(), err)
+	}
 	return &Batch{
 		Header:       extBatch.Header,
-		Transactions: transactionBlobCrypto.Decrypt(extBatch.EncryptedTxBlob),
-	}
+		Transactions: txs,
+	}, nil
 }
 
 func EmptyBatch(agg gethcommon.Address, parent *common.BatchHeader, blkHash gethcommon.Hash) (*Batch, error) {
@@ -87,6 +92,8 @@ func EmptyBatch(agg gethcommon.Address, parent *common.BatchHeader, blkHash geth
 		// note that this randomness will be published in the header of the batch.
 		// the randomness exposed to smart contract is combining this with the shared secret.
 		MixDigest: gethcommon.BytesToHash(rand),
+		// The base fee is set in the genesis, and does not change.
+		BaseFee: parent.BaseFee,
 	}
 	b := Batch{
 		Header: &h,
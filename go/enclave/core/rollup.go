@@ -50,14 +50,18 @@ func (r *Rollup) ToExtRollup(txBlobCrypto crypto.TransactionBlobCrypto) *common.
 	}
 }
 
-func ToRollup(encryptedRollup *common.ExtRollup, txBlobCrypto crypto.TransactionBlobCrypto) *Rollup {
+func ToRollup(encryptedRollup *common.ExtRollup, txBlobCrypto crypto.TransactionBlobCrypto) (*Rollup, error) {
 	batches := make([]*Batch, len(encryptedRollup.Batches))
 	for idx, extBatch := range encryptedRollup.Batches {
-		batches[idx] = ToBatch(extBatch, txBlobCrypto)
+		batch, err := ToBatch(extBatch, txBlobCrypto)
+		if err != nil {
+			return nil, err
+		}
+		batches[idx] = batch
 	}
 
 	return &Rollup{
 		Header:  encryptedRollup.Header,
 		Batches: batches,
-	}
+	}, nil
 }
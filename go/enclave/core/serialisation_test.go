@@ -1,11 +1,14 @@
 package core
 
 import (
+	"math/big"
 	"sync/atomic"
 	"testing"
 
+	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
 	"github.com/obscuronet/go-obscuro/integration/datagenerator"
 )
 
@@ -62,3 +65,76 @@ func TestSerialiseBatch(t *testing.T) {
 		t.Errorf("batch deserialized incorrectly\n")
 	}
 }
+
+func TestCompactRollupEncoding(t *testing.T) {
+	txBlobCrypto := crypto.NewTransactionBlobCryptoImpl(nil)
+	agg := datagenerator.RandomAddress()
+	l1Proofs := []common.L1RootHash{gethcommon.BytesToHash(datagenerator.RandomBytes(32)), gethcommon.BytesToHash(datagenerator.RandomBytes(32))}
+
+	// We create a chain of batches, the first two of which share an L1 proof.
+	parent := &common.BatchHeader{Number: big.NewInt(4)}
+	extBatches := make([]*common.ExtBatch, 3)
+	for idx := range extBatches {
+		l1Proof := l1Proofs[idx/2]
+		batch, err := EmptyBatch(agg, parent, l1Proof)
+		if err != nil {
+			t.Fatalf("could not create batch. Cause: %s", err)
+		}
+		batch.Header.LatestInboundCrossChainHash = l1Proof
+		batch.Header.LatestInboundCrossChainHeight = big.NewInt(int64(100 + idx/2))
+		batch.Header.R, batch.Header.S = big.NewInt(int64(idx+1)), big.NewInt(int64(idx+2))
+		batch.Transactions = []*common.L2Tx{datagenerator.CreateL2Tx()}
+		extBatches[idx] = batch.ToExtBatch(txBlobCrypto)
+		parent = batch.Header
+	}
+
+	header := parent.ToRollupHeader()
+	header.ParentHash = gethcommon.BytesToHash(datagenerator.RandomBytes(32))
+	header.Number = big.NewInt(2)
+	header.HeadBatchHash = parent.Hash()
+	header.R, header.S = big.NewInt(10), big.NewInt(11)
+	rollup := &common.ExtRollup{Header: header, Batches: extBatches}
+
+	encoded, err := common.EncodeRollup(rollup)
+	if err != nil {
+		t.Fatalf("could not encode rollup. Cause: %s", err)
+	}
+	rlpEncoded, err := rlp.EncodeToBytes(rollup)
+	if err != nil {
+		t.Fatalf("could not RLP-encode rollup. Cause: %s", err)
+	}
+	if len(encoded) >= len(rlpEncoded) {
+		t.Errorf("expected compact encoding to be smaller than %d bytes, got %d", len(rlpEncoded), len(encoded))
+	}
+	assertRollupRecovered(t, rollup, encoded)
+
+	// A rollup whose header was not derived from its head batch cannot be encoded.
+	header.Time++
+	if _, err = common.EncodeRollup(rollup); err == nil {
+		t.Fatal("expected encoding of rollup not derived from its head batch to fail")
+	}
+
+	// Rollups published in the RLP encoding can still be decoded.
+	if rlpEncoded, err = rlp.EncodeToBytes(rollup); err != nil {
+		t.Fatalf("could not RLP-encode rollup. Cause: %s", err)
+	}
+	assertRollupRecovered(t, rollup, rlpEncoded)
+}
+
+func assertRollupRecovered(t *testing.T, rollup *common.ExtRollup, encoded common.EncodedRollup) {
+	decoded, err := common.DecodeRollup(encoded)
+	if err != nil {
+		t.Fatalf("could not decode rollup. Cause: %s", err)
+	}
+	if decoded.Header.Hash() != rollup.Header.Hash() || decoded.Header.R.Cmp(rollup.Header.R) != 0 {
+		t.Fatalf("rollup decoded incorrectly")
+	}
+	if len(decoded.Batches) != len(rollup.Batches) {
+		t.Fatalf("expected %d batches, got %d", len(rollup.Batches), len(decoded.Batches))
+	}
+	for idx, batch := range decoded.Batches {
+		if batch.Header.Hash() != rollup.Batches[idx].Header.Hash() || string(batch.EncryptedTxBlob) != string(rollup.Batches[idx].EncryptedTxBlob) {
+			t.Fatalf("batch %d decoded incorrectly", idx)
+		}
+	}
+}
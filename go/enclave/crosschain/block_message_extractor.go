@@ -11,23 +11,20 @@ import (
 )
 
 type blockMessageExtractor struct {
-	busAddress   *common.L1Address
-	l2MessageBus *common.L2Address // TODO: remove this
-	storage      db.Storage
-	logger       gethlog.Logger
+	busAddress *common.L1Address
+	storage    db.Storage
+	logger     gethlog.Logger
 }
 
 func NewBlockMessageExtractor(
 	busAddress *common.L1Address,
-	l2BusAddress *common.L2Address,
 	storage db.Storage,
 	logger gethlog.Logger,
 ) BlockMessageExtractor {
 	return &blockMessageExtractor{
-		busAddress:   busAddress,
-		l2MessageBus: l2BusAddress,
-		storage:      storage,
-		logger:       logger,
+		busAddress: busAddress,
+		storage:    storage,
+		logger:     logger,
 	}
 }
 
@@ -15,6 +15,8 @@ import (
 	"golang.org/x/crypto/sha3"
 )
 
+const storeCrossChainMessageMethod = "storeCrossChainMessage"
+
 var (
 	MessageBusABI, _    = abi.JSON(strings.NewReader(MessageBus.MessageBusMetaData.ABI))
 	CrossChainEventName = "LogMessagePublished"
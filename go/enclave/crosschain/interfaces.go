@@ -5,6 +5,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
 )
 
 type (
@@ -31,12 +32,19 @@ type Manager interface {
 	// GetOwner - Returns the address of the identity owning the message bus.
 	GetOwner() common.L2Address
 
-	// GetBusAddress - Returns the L2 address of the message bus contract.
+	// GetBusAddress - Returns the L2 address of the message bus contract, or the zero address before the owner is derived.
 	GetBusAddress() *common.L2Address
 
-	// DeriveOwner - Generates the key pair that will be used to transact with the L2 message bus.
-	// TODO: Implement with cryptography epic.
-	DeriveOwner(seed []byte) (*common.L2Address, error)
+	// DeriveOwner - Derives the key pair that will be used to transact with the L2 message bus from the shared secret,
+	// and returns the address of the message bus it deploys. Must be called before any synthetic transaction is created.
+	// The message bus is deployed at an address derived from the owner, so the owner is always derived from the secret
+	// of the first epoch, and cannot be derived again from another secret.
+	DeriveOwner(secret crypto.SharedEnclaveSecret) (*common.L2Address, error)
+
+	// VerifySyntheticTransactions - Returns an error if the transactions include a deposit, which only the enclave
+	// generates, or a transaction signed by the owner other than the message bus deployment. Returns ErrOwnerNotDerived
+	// before the owner is derived.
+	VerifySyntheticTransactions(transactions common.L2Transactions) error
 
 	// GenerateMessageBusDeployTx - Returns a signed message bus deployment transaction.
 	GenerateMessageBusDeployTx() (*common.L2Tx, error)
@@ -44,7 +52,14 @@ type Manager interface {
 	// ExtractOutboundMessages - Finds relevant logs in the receipts and converts them to cross chain messages.
 	ExtractOutboundMessages(receipts common.L2Receipts) (common.CrossChainMessages, error)
 
-	CreateSyntheticTransactions(messages common.CrossChainMessages, rollupState *state.StateDB) common.L2Transactions
+	// CreateSyntheticTransactions - Generates the transactions delivering the messages in the batch. With replay
+	// protection, the messages whose sender and sequence number were delivered already are refused, and the refusals are
+	// recorded in the audit trail.
+	CreateSyntheticTransactions(messages common.CrossChainMessages, rollupState *state.StateDB, batch *common.BatchHeader, replayProtection bool) common.L2Transactions
+
+	// RecordDeliveries - Records the messages delivered by the synthetic transactions whose receipts are successful in the
+	// audit trail and, with replay protection, in the rollup state. Must be called once the transactions are executed.
+	RecordDeliveries(transactions common.L2Transactions, receipts common.L2Receipts, rollupState *state.StateDB, batch *common.BatchHeader, replayProtection bool) error
 
 	RetrieveInboundMessages(fromBlock *common.L1Block, toBlock *common.L1Block, rollupState *state.StateDB) common.CrossChainMessages
 }
@@ -2,53 +2,49 @@ package crosschain
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
+	gethcrypto "github.com/ethereum/go-ethereum/crypto"
 	gethlog "github.com/ethereum/go-ethereum/log"
 	"github.com/obscuronet/go-obscuro/contracts/generated/MessageBus"
 	"github.com/obscuronet/go-obscuro/go/common"
 	"github.com/obscuronet/go-obscuro/go/common/log"
+	obscurocrypto "github.com/obscuronet/go-obscuro/go/enclave/crypto"
 	"github.com/obscuronet/go-obscuro/go/enclave/db"
 	"github.com/obscuronet/go-obscuro/go/enclave/rpc"
 	"github.com/obscuronet/go-obscuro/go/wallet"
 )
 
-// TODO: Cryptography epic remove this.
-const ( // DO NOT USE OR CHANGE THIS KEY IN THE REST OF THE CODEBASE
-	ownerKeyHex = "6e384a07a01263518a18a5424c7b6bbfc3604ba7d93f47e3a455cbdd7f9f0682"
-)
+// ErrOwnerNotDerived is returned when the message bus owner is needed before the enclave has received the shared secret.
+var ErrOwnerNotDerived = errors.New("the message bus owner has not been derived from the shared secret")
 
 type MessageBusManager struct {
-	messageBusAddress *gethcommon.Address
-	storage           db.Storage
-	logger            gethlog.Logger
-	wallet            wallet.Wallet
+	storage db.Storage
+	logger  gethlog.Logger
+	chainID *big.Int
+
+	// The owner is derived from the shared secret when the enclave receives it, which happens on an RPC goroutine.
+	ownerLock         sync.RWMutex
+	wallet            wallet.Wallet // Nil until the owner is derived from the shared secret
+	messageBusAddress gethcommon.Address
+	deployTx          *common.L2Tx
 }
 
 func NewObscuroMessageBusManager(
-	storage db.Storage, /*key *ecdsa.PrivateKey,*/
+	storage db.Storage,
 	chainID *big.Int,
 	logger gethlog.Logger,
 ) Manager {
-	// TODO: Cryptography epic remove this key and use the DeriveKey
-	key, _ := crypto.HexToECDSA(ownerKeyHex)
-	wallet := wallet.NewInMemoryWalletFromPK(chainID, key, logger)
-
-	logger.Info(fmt.Sprintf("L2 Cross Chain Owner Address: %s", wallet.Address().Hex()), log.CmpKey, log.CrossChainCmp)
-
-	// Key is derived, address is predictable, thus address of contract is predictable across all enclaves
-	l2MessageBus := crypto.CreateAddress(wallet.Address(), 0)
-
 	return &MessageBusManager{
-		messageBusAddress: &l2MessageBus,
-		storage:           storage,
-		logger:            logger,
-		wallet:            wallet,
+		storage: storage,
+		logger:  logger,
+		chainID: chainID,
 	}
 }
 
@@ -59,28 +55,89 @@ func (m *MessageBusManager) IsSyntheticTransaction(transaction common.L2Tx) bool
 	}
 	// The message bus manager considers the transaction synthetic only if the sender is
 	// the owner identity which should be available only to enclaves.
-	return bytes.Equal(sender.Bytes(), m.GetOwner().Bytes())
+	owner, err := m.owner()
+	return err == nil && bytes.Equal(sender.Bytes(), owner.Address().Bytes())
 }
 
-// GetOwner - Returns the address of the identity owning the message bus.
+// GetOwner - Returns the address of the identity owning the message bus, or the zero address if it has not been derived.
 func (m *MessageBusManager) GetOwner() common.L2Address {
-	return m.wallet.Address()
+	owner, err := m.owner()
+	if err != nil {
+		return gethcommon.Address{}
+	}
+	return owner.Address()
 }
 
-// GetBusAddress - Returns the L2 address of the message bus contract.
+// GetBusAddress - Returns the L2 address of the message bus contract, or the zero address if the owner has not been
+// derived.
 // TODO: Figure out how to expose the deployed contract to the external world. Perhaps extract event from contract construction?
 func (m *MessageBusManager) GetBusAddress() *common.L2Address {
-	return m.messageBusAddress
+	m.ownerLock.RLock()
+	defer m.ownerLock.RUnlock()
+	address := m.messageBusAddress
+	return &address
+}
+
+// DeriveOwner - Derives the key pair that will be used to transact with the L2 message bus from the shared secret.
+func (m *MessageBusManager) DeriveOwner(secret obscurocrypto.SharedEnclaveSecret) (*common.L2Address, error) {
+	key, err := obscurocrypto.DeriveKey(secret, obscurocrypto.MessageBusOwnerKeyInfo)
+	if err != nil {
+		return nil, fmt.Errorf("could not derive message bus owner key. Cause: %w", err)
+	}
+	ownerWallet := wallet.NewInMemoryWalletFromPK(m.chainID, key, m.logger)
+
+	m.ownerLock.Lock()
+	defer m.ownerLock.Unlock()
+	// The message bus is deployed at an address derived from the owner, so a new owner would move the bus away from
+	// the deposits and withdrawals recorded by the deployed contract.
+	if m.wallet != nil {
+		if m.wallet.Address() != ownerWallet.Address() {
+			return nil, fmt.Errorf("the message bus owner was already derived from another secret")
+		}
+		address := m.messageBusAddress
+		return &address, nil
+	}
+
+	deployTx, err := generateMessageBusDeployTx(ownerWallet)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate message bus deployment transaction. Cause: %w", err)
+	}
+	m.wallet = ownerWallet
+	m.deployTx = deployTx
+	// The key and thus the contract address are the same across all enclaves sharing the secret.
+	m.messageBusAddress = gethcrypto.CreateAddress(ownerWallet.Address(), 0)
+
+	m.logger.Info(fmt.Sprintf("L2 Cross Chain Owner Address: %s. L2 Message Bus Address: %s", ownerWallet.Address().Hex(), m.messageBusAddress.Hex()),
+		log.CmpKey, log.CrossChainCmp)
+	address := m.messageBusAddress
+	return &address, nil
 }
 
-// DeriveOwner - Generates the key pair that will be used to transact with the L2 message bus.
-func (m *MessageBusManager) DeriveOwner(seed []byte) (*common.L2Address, error) {
-	// TODO: Implement with cryptography epic!
-	return m.messageBusAddress, nil
+// Returns the wallet of the owner, or ErrOwnerNotDerived.
+func (m *MessageBusManager) owner() (wallet.Wallet, error) {
+	m.ownerLock.RLock()
+	defer m.ownerLock.RUnlock()
+	if m.wallet == nil {
+		return nil, ErrOwnerNotDerived
+	}
+	return m.wallet, nil
 }
 
 // GenerateMessageBusDeployTx - Returns a signed message bus deployment transaction.
 func (m *MessageBusManager) GenerateMessageBusDeployTx() (*common.L2Tx, error) {
+	m.ownerLock.RLock()
+	defer m.ownerLock.RUnlock()
+	if m.wallet == nil {
+		return nil, ErrOwnerNotDerived
+	}
+
+	m.logger.Trace(fmt.Sprintf("Generated synthetic deployment transaction for the MessageBus contract %s - TX HASH: %s", m.messageBusAddress.Hex(), m.deployTx.Hash().Hex()),
+		log.CmpKey, log.CrossChainCmp)
+
+	return m.deployTx, nil
+}
+
+func generateMessageBusDeployTx(owner wallet.Wallet) (*common.L2Tx, error) {
 	tx := &types.LegacyTx{
 		Nonce:    0, // The first transaction of the owner identity should always be deploying the contract
 		Value:    gethcommon.Big0,
@@ -89,21 +146,45 @@ func (m *MessageBusManager) GenerateMessageBusDeployTx() (*common.L2Tx, error) {
 		Data:     gethcommon.FromHex(MessageBus.MessageBusMetaData.Bin),
 		To:       nil, // Geth requires nil instead of gethcommon.Address{} which equates to zero address in order to return receipt.
 	}
+	return owner.SignTransaction(tx)
+}
 
-	stx, err := m.wallet.SignTransaction(tx)
-	if err != nil {
-		return nil, err
+func (m *MessageBusManager) VerifySyntheticTransactions(transactions common.L2Transactions) error {
+	m.ownerLock.RLock()
+	defer m.ownerLock.RUnlock()
+	if m.wallet == nil {
+		return ErrOwnerNotDerived
 	}
+	for _, tx := range transactions {
+		sender, err := rpc.GetSender(tx)
+		if err != nil {
+			return fmt.Errorf("could not recover sender of transaction %s. Cause: %w", tx.Hash().Hex(), err)
+		}
+		// Deposits are never included in the batch's transactions, as each enclave generates them itself, so the
+		// deployment of the message bus is the only transaction the owner signs. The deployment is signed
+		// deterministically, so any other transaction signed by the owner differs from it.
+		if sender == m.wallet.Address() && tx.Hash() != m.deployTx.Hash() {
+			return fmt.Errorf("transaction %s signed by the message bus owner is not the message bus deployment", tx.Hash().Hex())
+		}
+		if m.isDeposit(tx) {
+			return fmt.Errorf("deposit transaction %s is not generated by the enclave", tx.Hash().Hex())
+		}
+	}
+	return nil
+}
 
-	m.logger.Trace(fmt.Sprintf("Generated synthetic deployment transaction for the MessageBus contract %s - TX HASH: %s", m.messageBusAddress.Hex(), stx.Hash().Hex()),
-		log.CmpKey, log.CrossChainCmp)
-
-	return stx, nil
+// Returns whether the transaction calls the function of the message bus that only the owner may call.
+func (m *MessageBusManager) isDeposit(tx *common.L2Tx) bool {
+	if tx.To() == nil || *tx.To() != m.messageBusAddress {
+		return false
+	}
+	method, err := MessageBusABI.MethodById(tx.Data())
+	return err == nil && method.Name == storeCrossChainMessageMethod
 }
 
 // ExtractLocalMessages - Finds relevant logs in the receipts and converts them to cross chain messages.
 func (m *MessageBusManager) ExtractOutboundMessages(receipts common.L2Receipts) (common.CrossChainMessages, error) {
-	logs, err := filterLogsFromReceipts(receipts, m.messageBusAddress, &CrossChainEventID)
+	logs, err := filterLogsFromReceipts(receipts, m.GetBusAddress(), &CrossChainEventID)
 	if err != nil {
 		m.logger.Error("Error extracting logs from L2 message bus!", log.ErrKey, err, log.CmpKey, log.CrossChainCmp)
 		return make(common.CrossChainMessages, 0), err
@@ -162,15 +243,31 @@ func (m *MessageBusManager) RetrieveInboundMessages(fromBlock *common.L1Block, t
 }
 
 // CreateSyntheticTransactions - generates transactions that the enclave should execute internally for the messages.
-func (m *MessageBusManager) CreateSyntheticTransactions(messages common.CrossChainMessages, rollupState *state.StateDB) common.L2Transactions {
+func (m *MessageBusManager) CreateSyntheticTransactions(messages common.CrossChainMessages, rollupState *state.StateDB, batch *common.BatchHeader, replayProtection bool) common.L2Transactions {
+	owner, err := m.owner()
+	if err != nil {
+		m.logger.Crit("Cannot create synthetic transactions before the message bus owner is derived.", log.CmpKey, log.CrossChainCmp)
+	}
+	messageBusAddress := m.GetBusAddress()
 	// Get current nonce for this stateDB.
 	// There can be forks thus we cannot trust the wallet.
-	startingNonce := rollupState.GetNonce(m.GetOwner())
+	nonce := rollupState.GetNonce(owner.Address())
 
 	signedTransactions := make(types.Transactions, 0)
-	for idx, message := range messages {
+	// The deliveries are only marked in the state once the batch's synthetic transactions are executed.
+	inBatch := map[gethcommon.Hash]bool{}
+	for _, message := range messages {
+		if replayProtection {
+			slot := deliveredMessageSlot(message.Sender, message.Sequence)
+			if isDelivered(rollupState, *messageBusAddress, message) || inBatch[slot] {
+				m.refuseReplay(message, batch)
+				continue
+			}
+			inBatch[slot] = true
+		}
+
 		delayInBlocks := big.NewInt(int64(message.ConsistencyLevel))
-		data, err := MessageBusABI.Pack("storeCrossChainMessage", message, delayInBlocks)
+		data, err := MessageBusABI.Pack(storeCrossChainMessageMethod, message, delayInBlocks)
 		if err != nil {
 			m.logger.Crit("Failed packing submitOutOfNetwork message!", log.CmpKey, log.CrossChainCmp)
 			return signedTransactions
@@ -180,20 +277,76 @@ func (m *MessageBusManager) CreateSyntheticTransactions(messages common.CrossCha
 		}
 
 		tx := &types.LegacyTx{
-			Nonce:    startingNonce + uint64(idx),
+			Nonce:    nonce,
 			Value:    gethcommon.Big0,
 			Gas:      5_000_000,
 			GasPrice: gethcommon.Big0, // Synthetic transactions are on the house. Or the house.
 			Data:     data,
-			To:       m.messageBusAddress,
+			To:       messageBusAddress,
 		}
 
-		stx, err := m.wallet.SignTransaction(tx)
+		stx, err := owner.SignTransaction(tx)
 		if err != nil {
 			panic(err)
 		}
 		signedTransactions = append(signedTransactions, stx)
+		nonce++
 	}
 
 	return signedTransactions
 }
+
+// RecordDeliveries - Records the messages delivered by the synthetic transactions that executed successfully.
+func (m *MessageBusManager) RecordDeliveries(transactions common.L2Transactions, receipts common.L2Receipts, rollupState *state.StateDB, batch *common.BatchHeader, replayProtection bool) error {
+	successful := map[gethcommon.Hash]bool{}
+	for _, receipt := range receipts {
+		successful[receipt.TxHash] = receipt.Status == types.ReceiptStatusSuccessful
+	}
+
+	for _, tx := range transactions {
+		if !successful[tx.Hash()] {
+			continue
+		}
+		message, err := decodeDeposit(tx)
+		if err != nil {
+			return err
+		}
+		messageHash, err := HashMessage(message)
+		if err != nil {
+			return err
+		}
+		if replayProtection {
+			markDelivered(rollupState, *m.GetBusAddress(), message, messageHash)
+		}
+
+		record, err := fetchInboundMessageRecord(m.storage, message)
+		if err != nil {
+			m.logger.Error("Could not read inbound message record.", log.ErrKey, err, log.CmpKey, log.CrossChainCmp)
+		}
+		m.storeInboundMessageRecord(recordDelivery(record, message, messageHash, tx.Hash(), batch))
+	}
+	return nil
+}
+
+func (m *MessageBusManager) refuseReplay(message common.CrossChainMessage, batch *common.BatchHeader) {
+	m.logger.Warn(fmt.Sprintf("Refusing replay of cross chain message from %s with sequence %d in batch b_%d.",
+		message.Sender.Hex(), message.Sequence, batch.Number), log.CmpKey, log.CrossChainCmp)
+
+	messageHash, err := HashMessage(message)
+	if err != nil {
+		m.logger.Error("Could not hash replayed cross chain message.", log.ErrKey, err, log.CmpKey, log.CrossChainCmp)
+		return
+	}
+	record, err := fetchInboundMessageRecord(m.storage, message)
+	if err != nil {
+		m.logger.Error("Could not read inbound message record.", log.ErrKey, err, log.CmpKey, log.CrossChainCmp)
+	}
+	m.storeInboundMessageRecord(recordReplay(record, message, messageHash, batch))
+}
+
+// The audit trail is only used for debugging, so failing to write it does not stop the batch from being processed.
+func (m *MessageBusManager) storeInboundMessageRecord(record *common.InboundMessageRecord) {
+	if err := m.storage.StoreInboundMessageRecord(record); err != nil {
+		m.logger.Error("Could not store inbound message record.", log.ErrKey, err, log.CmpKey, log.CrossChainCmp)
+	}
+}
@@ -0,0 +1,90 @@
+package crosschain
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	gethcrypto "github.com/ethereum/go-ethereum/crypto"
+	gethlog "github.com/ethereum/go-ethereum/log"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
+	"github.com/obscuronet/go-obscuro/go/enclave/db"
+)
+
+func TestOwnerDerivedFromSharedSecret(t *testing.T) {
+	chainID := big.NewInt(777)
+	newManager := func(secret crypto.SharedEnclaveSecret) Manager {
+		manager := NewObscuroMessageBusManager(db.NewStorage(rawdb.NewMemoryDatabase(), nil, gethlog.New()), chainID, gethlog.New())
+		if _, err := manager.DeriveOwner(secret); err != nil {
+			t.Fatalf("could not derive owner. Cause: %s", err)
+		}
+		return manager
+	}
+	manager := newManager(crypto.SharedEnclaveSecret{1})
+	if manager.GetOwner() != newManager(crypto.SharedEnclaveSecret{1}).GetOwner() {
+		t.Fatal("enclaves sharing the secret derived different owners")
+	}
+	if manager.GetOwner() == newManager(crypto.SharedEnclaveSecret{2}).GetOwner() {
+		t.Fatal("enclaves with different secrets derived the same owner")
+	}
+
+	deployTx, err := manager.GenerateMessageBusDeployTx()
+	if err != nil {
+		t.Fatalf("could not generate deployment transaction. Cause: %s", err)
+	}
+	if err = manager.VerifySyntheticTransactions(common.L2Transactions{deployTx}); err != nil {
+		t.Fatalf("deployment transaction was rejected. Cause: %s", err)
+	}
+
+	// A deposit signed by any key other than the owner's is rejected.
+	otherKey, _ := gethcrypto.GenerateKey()
+	data, _ := MessageBusABI.Pack(storeCrossChainMessageMethod, common.CrossChainMessage{Payload: []byte{}}, big.NewInt(0))
+	forgedTx, err := types.SignNewTx(otherKey, types.NewEIP155Signer(chainID), &types.LegacyTx{To: manager.GetBusAddress(), Gas: 5_000_000, Data: data})
+	if err != nil {
+		t.Fatalf("could not sign transaction. Cause: %s", err)
+	}
+	if err = manager.VerifySyntheticTransactions(common.L2Transactions{deployTx, forgedTx}); err == nil {
+		t.Fatal("forged deposit transaction was accepted")
+	}
+
+	// Any other transaction signed by the owner is rejected, even one that deploys the message bus.
+	ownerKey, err := crypto.DeriveKey(crypto.SharedEnclaveSecret{1}, crypto.MessageBusOwnerKeyInfo)
+	if err != nil {
+		t.Fatalf("could not derive owner key. Cause: %s", err)
+	}
+	redeployTx, err := types.SignNewTx(ownerKey, types.NewEIP155Signer(chainID), &types.LegacyTx{Nonce: 1, Gas: 5_000_000, Data: deployTx.Data()})
+	if err != nil {
+		t.Fatalf("could not sign transaction. Cause: %s", err)
+	}
+	if err = manager.VerifySyntheticTransactions(common.L2Transactions{redeployTx}); err == nil {
+		t.Fatal("second deployment transaction signed by the owner was accepted")
+	}
+
+	// The owner cannot be derived again from another secret, since the message bus would move.
+	busAddress := *manager.GetBusAddress()
+	if _, err = manager.DeriveOwner(crypto.SharedEnclaveSecret{2}); err == nil {
+		t.Fatal("owner was derived again from another secret")
+	}
+	if derived, err := manager.DeriveOwner(crypto.SharedEnclaveSecret{1}); err != nil || *derived != busAddress {
+		t.Fatalf("could not derive owner again from the same secret. Cause: %v", err)
+	}
+}
+
+func TestTransactionsAreRefusedBeforeTheOwnerIsDerived(t *testing.T) {
+	manager := NewObscuroMessageBusManager(db.NewStorage(rawdb.NewMemoryDatabase(), nil, gethlog.New()), big.NewInt(777), gethlog.New())
+	if *manager.GetBusAddress() != (gethcommon.Address{}) {
+		t.Fatal("expected no message bus address before the owner is derived")
+	}
+	key, _ := gethcrypto.GenerateKey()
+	tx, err := types.SignNewTx(key, types.NewEIP155Signer(big.NewInt(777)), &types.LegacyTx{Gas: 21_000})
+	if err != nil {
+		t.Fatalf("could not sign transaction. Cause: %s", err)
+	}
+	if err = manager.VerifySyntheticTransactions(common.L2Transactions{tx}); !errors.Is(err, ErrOwnerNotDerived) {
+		t.Fatalf("expected transaction to be refused until the owner is derived, got %v", err)
+	}
+}
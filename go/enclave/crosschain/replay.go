@@ -0,0 +1,166 @@
+package crosschain
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+	"github.com/obscuronet/go-obscuro/go/enclave/db"
+)
+
+// The deliveries are recorded in the storage of the L2 message bus, so that every enclave reaches the same decision, and
+// that they are rolled back together with the rest of the state when the L1 reorganises. The slots are derived from a
+// domain separator, so they cannot collide with the slots of the contract's own variables.
+var deliveredMessagesDomain = []byte("obscuro.crosschain.delivered")
+
+// HashMessage - Returns the hash of a message as computed by the message buses, i.e. of its ABI encoding.
+func HashMessage(message common.CrossChainMessage) (gethcommon.Hash, error) {
+	encodedMessage, err := MessageBusABI.Methods[verifyMessageFinalizedMethod].Inputs.Pack(message)
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("could not encode cross chain message. Cause: %w", err)
+	}
+	return crypto.Keccak256Hash(encodedMessage), nil
+}
+
+// deliveredMessageSlot - Returns the storage slot of the L2 message bus marking the message with the given sender and
+// sequence number as delivered. Sequence numbers are assigned per sender by the L1 message bus.
+func deliveredMessageSlot(sender gethcommon.Address, sequence uint64) gethcommon.Hash {
+	seq := make([]byte, 8)
+	binary.BigEndian.PutUint64(seq, sequence)
+	return crypto.Keccak256Hash(deliveredMessagesDomain, sender.Bytes(), seq)
+}
+
+// isDelivered - Returns whether a message with the same sender and sequence number has been delivered in the state.
+func isDelivered(rollupState *state.StateDB, messageBus gethcommon.Address, message common.CrossChainMessage) bool {
+	return rollupState.GetState(messageBus, deliveredMessageSlot(message.Sender, message.Sequence)) != (gethcommon.Hash{})
+}
+
+// markDelivered - Records the delivery of the message in the state.
+func markDelivered(rollupState *state.StateDB, messageBus gethcommon.Address, message common.CrossChainMessage, messageHash gethcommon.Hash) {
+	rollupState.SetState(messageBus, deliveredMessageSlot(message.Sender, message.Sequence), messageHash)
+}
+
+// decodeDeposit - Returns the message delivered by a synthetic transaction.
+func decodeDeposit(tx *common.L2Tx) (common.CrossChainMessage, error) {
+	method, err := MessageBusABI.MethodById(tx.Data())
+	if err != nil || method.Name != storeCrossChainMessageMethod {
+		return common.CrossChainMessage{}, fmt.Errorf("transaction %s is not a deposit", tx.Hash().Hex())
+	}
+	args, err := method.Inputs.Unpack(tx.Data()[4:])
+	if err != nil {
+		return common.CrossChainMessage{}, fmt.Errorf("could not decode deposit transaction %s. Cause: %w", tx.Hash().Hex(), err)
+	}
+	message, ok := abi.ConvertType(args[0], new(common.CrossChainMessage)).(*common.CrossChainMessage)
+	if !ok {
+		return common.CrossChainMessage{}, fmt.Errorf("could not convert message of deposit transaction %s", tx.Hash().Hex())
+	}
+	return *message, nil
+}
+
+// recordDelivery - Returns the audit record of a message delivered by the synthetic transaction in the batch. Batches
+// can be processed more than once, so a delivery already recorded for the batch is not recorded again.
+func recordDelivery(existing *common.InboundMessageRecord, message common.CrossChainMessage, messageHash gethcommon.Hash, txHash gethcommon.Hash, batch *common.BatchHeader) *common.InboundMessageRecord {
+	record := newInboundMessageRecord(existing, message, messageHash)
+	delivery := common.MessageDelivery{
+		MessageHash:     messageHash,
+		SyntheticTxHash: txHash,
+		BatchNumber:     batch.Number.Uint64(),
+		BatchL1Proof:    batch.L1Proof,
+	}
+	for _, recorded := range record.Deliveries {
+		if recorded == delivery {
+			return record
+		}
+	}
+	record.Deliveries = append(record.Deliveries, delivery)
+	// After a reorg, the delivered message may differ from the one delivered on the abandoned fork.
+	record.Nonce = message.Nonce
+	record.Topic = message.Topic
+	setDelivery(record, delivery)
+	return record
+}
+
+// setDelivery - Sets the delivery fields of the record.
+func setDelivery(record *common.InboundMessageRecord, delivery common.MessageDelivery) {
+	record.MessageHash = delivery.MessageHash
+	record.Status = common.InboundMessageDelivered
+	record.SyntheticTxHash = delivery.SyntheticTxHash
+	record.BatchNumber = delivery.BatchNumber
+	record.BatchL1Proof = delivery.BatchL1Proof
+}
+
+// ResolveCanonicalDelivery - Sets the delivery fields of the record from its delivery in a batch of the canonical chain.
+// If the message was only delivered in batches that were abandoned after an L1 reorg, it is marked as orphaned.
+func ResolveCanonicalDelivery(storage db.Storage, record *common.InboundMessageRecord) error {
+	// Records written before the deliveries were kept per batch only hold the latest delivery.
+	if len(record.Deliveries) == 0 {
+		return nil
+	}
+	for _, delivery := range record.Deliveries {
+		batch, err := storage.FetchBatchByHeight(delivery.BatchNumber)
+		if err != nil {
+			if errors.Is(err, errutil.ErrNotFound) {
+				continue
+			}
+			return fmt.Errorf("could not retrieve canonical batch %d. Cause: %w", delivery.BatchNumber, err)
+		}
+		// The deposits of a batch are determined by its L1 block, so the delivery is canonical if its batch was
+		// produced from the same L1 block as the canonical batch at its height.
+		if batch.Header.L1Proof == delivery.BatchL1Proof {
+			setDelivery(record, delivery)
+			return nil
+		}
+	}
+	record.Status = common.InboundMessageOrphaned
+	return nil
+}
+
+// recordReplay - Returns the audit record of a message after a replay of it was refused in the batch. Batches can be
+// processed more than once, so a replay already recorded for the batch is not recorded again.
+func recordReplay(existing *common.InboundMessageRecord, message common.CrossChainMessage, messageHash gethcommon.Hash, batch *common.BatchHeader) *common.InboundMessageRecord {
+	record := newInboundMessageRecord(existing, message, messageHash)
+	if existing == nil {
+		// We only know of the message through its replays, e.g. because it was delivered before records were kept.
+		record.Status = common.InboundMessageRefused
+	}
+
+	replay := common.RefusedReplay{MessageHash: messageHash, BatchNumber: batch.Number.Uint64(), BatchL1Proof: batch.L1Proof}
+	for _, recorded := range record.RefusedReplays {
+		if recorded == replay {
+			return record
+		}
+	}
+	record.RefusedReplays = append(record.RefusedReplays, replay)
+	return record
+}
+
+func newInboundMessageRecord(existing *common.InboundMessageRecord, message common.CrossChainMessage, messageHash gethcommon.Hash) *common.InboundMessageRecord {
+	if existing != nil {
+		return existing
+	}
+	return &common.InboundMessageRecord{
+		Sender:      message.Sender,
+		Sequence:    message.Sequence,
+		Nonce:       message.Nonce,
+		Topic:       message.Topic,
+		MessageHash: messageHash,
+	}
+}
+
+// fetchInboundMessageRecord - Returns the stored audit record of the message, or nil if there is none.
+func fetchInboundMessageRecord(storage db.Storage, message common.CrossChainMessage) (*common.InboundMessageRecord, error) {
+	record, err := storage.GetInboundMessageRecord(message.Sender, message.Sequence)
+	if err != nil {
+		if errors.Is(err, errutil.ErrNotFound) {
+			return nil, nil //nolint:nilnil
+		}
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,162 @@
+package crosschain
+
+import (
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	gethlog "github.com/ethereum/go-ethereum/log"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/enclave/core"
+	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
+	"github.com/obscuronet/go-obscuro/go/enclave/db"
+)
+
+func TestReplayedMessagesAreRefused(t *testing.T) {
+	storage, manager, stateDB := createReplayTestManager(t)
+
+	sender := gethcommon.HexToAddress("0x1234")
+	message := common.CrossChainMessage{Sender: sender, Sequence: 1, Payload: []byte("deposit")}
+	replay := common.CrossChainMessage{Sender: sender, Sequence: 1, Payload: []byte("forged deposit")}
+	firstBatch := &common.BatchHeader{Number: big.NewInt(1), L1Proof: gethcommon.HexToHash("0x01")}
+	secondBatch := &common.BatchHeader{Number: big.NewInt(2), L1Proof: gethcommon.HexToHash("0x02")}
+
+	parentState := stateDB.Copy()
+
+	// A replay in the same batch as the original is refused.
+	txs := deliver(t, manager, common.CrossChainMessages{message, replay}, stateDB, firstBatch, types.ReceiptStatusSuccessful)
+	if len(txs) != 1 {
+		t.Fatalf("expected a single synthetic transaction, got %d", len(txs))
+	}
+	// Processing the batch again must not record the delivery or the replay twice.
+	deliver(t, manager, common.CrossChainMessages{message, replay}, parentState, firstBatch, types.ReceiptStatusSuccessful)
+	// A replay in a later batch is refused, and the next message from the sender is delivered.
+	next := common.CrossChainMessage{Sender: sender, Sequence: 2, Payload: []byte("deposit")}
+	txs = deliver(t, manager, common.CrossChainMessages{message, next}, stateDB, secondBatch, types.ReceiptStatusSuccessful)
+	if len(txs) != 1 {
+		t.Fatalf("expected a single synthetic transaction, got %d", len(txs))
+	}
+
+	records, err := storage.GetInboundMessageRecords(sender)
+	if err != nil {
+		t.Fatalf("could not read inbound message records. Cause: %s", err)
+	}
+	if len(records) != 2 || records[0].Sequence != 1 || records[1].Sequence != 2 {
+		t.Fatalf("expected records for both sequence numbers in order, got %d records", len(records))
+	}
+	record := records[0]
+	if record.Status != common.InboundMessageDelivered || record.BatchNumber != 1 || len(record.Deliveries) != 1 || len(record.RefusedReplays) != 2 {
+		t.Fatalf("unexpected record %+v", record)
+	}
+	if record.RefusedReplays[0].MessageHash == record.MessageHash || record.RefusedReplays[1].BatchNumber != 2 {
+		t.Fatalf("unexpected refused replays %+v", record.RefusedReplays)
+	}
+}
+
+func TestMessagesAreOnlyMarkedDeliveredOnSuccess(t *testing.T) {
+	storage, manager, stateDB := createReplayTestManager(t)
+
+	message := common.CrossChainMessage{Sender: gethcommon.HexToAddress("0x1234"), Sequence: 1, Payload: []byte("deposit")}
+	firstBatch := &common.BatchHeader{Number: big.NewInt(1), L1Proof: gethcommon.HexToHash("0x01")}
+	secondBatch := &common.BatchHeader{Number: big.NewInt(2), L1Proof: gethcommon.HexToHash("0x02")}
+
+	// A delivery that reverted is neither recorded nor refused as a replay later on.
+	deliver(t, manager, common.CrossChainMessages{message}, stateDB, firstBatch, types.ReceiptStatusFailed)
+	if isDelivered(stateDB, *manager.GetBusAddress(), message) {
+		t.Fatal("message of a reverted synthetic transaction was marked as delivered")
+	}
+	if _, err := storage.GetInboundMessageRecord(message.Sender, message.Sequence); err == nil {
+		t.Fatal("message of a reverted synthetic transaction was recorded as delivered")
+	}
+	if txs := deliver(t, manager, common.CrossChainMessages{message}, stateDB, secondBatch, types.ReceiptStatusSuccessful); len(txs) != 1 {
+		t.Fatalf("expected message to be delivered again, got %d synthetic transactions", len(txs))
+	}
+}
+
+func TestReplaysAreDeliveredBeforeTheProtocolFork(t *testing.T) {
+	_, manager, stateDB := createReplayTestManager(t)
+	rootBefore := stateDB.IntermediateRoot(true)
+
+	message := common.CrossChainMessage{Sender: gethcommon.HexToAddress("0x1234"), Sequence: 1, Payload: []byte("deposit")}
+	batch := &common.BatchHeader{Number: big.NewInt(1), L1Proof: gethcommon.HexToHash("0x01")}
+	txs := manager.CreateSyntheticTransactions(common.CrossChainMessages{message, message}, stateDB, batch, false)
+	if len(txs) != 2 {
+		t.Fatalf("expected both messages to be delivered before the fork, got %d synthetic transactions", len(txs))
+	}
+	if err := manager.RecordDeliveries(txs, receiptsFor(txs, types.ReceiptStatusSuccessful), stateDB, batch, false); err != nil {
+		t.Fatalf("could not record deliveries. Cause: %s", err)
+	}
+	if stateDB.IntermediateRoot(true) != rootBefore {
+		t.Fatal("deliveries changed the state before the fork")
+	}
+}
+
+func TestDeliveryIsResolvedFromTheCanonicalBatch(t *testing.T) {
+	storage, manager, stateDB := createReplayTestManager(t)
+
+	message := common.CrossChainMessage{Sender: gethcommon.HexToAddress("0x1234"), Sequence: 1, Payload: []byte("deposit")}
+	orphanedBatch := &common.BatchHeader{Number: big.NewInt(0), L1Proof: gethcommon.HexToHash("0x01")}
+	canonicalBatch := &common.BatchHeader{Number: big.NewInt(0), L1Proof: gethcommon.HexToHash("0x02")}
+	deliver(t, manager, common.CrossChainMessages{message}, stateDB.Copy(), canonicalBatch, types.ReceiptStatusSuccessful)
+	deliver(t, manager, common.CrossChainMessages{message}, stateDB.Copy(), orphanedBatch, types.ReceiptStatusSuccessful)
+
+	record, err := storage.GetInboundMessageRecord(message.Sender, message.Sequence)
+	if err != nil {
+		t.Fatalf("could not read inbound message record. Cause: %s", err)
+	}
+	// No batch is canonical yet.
+	if err = ResolveCanonicalDelivery(storage, record); err != nil || record.Status != common.InboundMessageOrphaned {
+		t.Fatalf("expected delivery to be orphaned, got %s. Cause: %v", record.Status, err)
+	}
+
+	batch := &core.Batch{Header: canonicalBatch}
+	if err = storage.StoreBatch(batch, nil); err != nil {
+		t.Fatalf("could not store batch. Cause: %s", err)
+	}
+	if err = storage.UpdateHeadBatch(canonicalBatch.L1Proof, batch, nil); err != nil {
+		t.Fatalf("could not update head batch. Cause: %s", err)
+	}
+	if err = ResolveCanonicalDelivery(storage, record); err != nil {
+		t.Fatalf("could not resolve delivery. Cause: %s", err)
+	}
+	if record.Status != common.InboundMessageDelivered || record.BatchL1Proof != canonicalBatch.L1Proof || len(record.Deliveries) != 2 {
+		t.Fatalf("expected delivery in the canonical batch, got %+v", record)
+	}
+}
+
+func createReplayTestManager(t *testing.T) (db.Storage, Manager, *state.StateDB) {
+	storage := db.NewStorage(rawdb.NewMemoryDatabase(), nil, gethlog.New())
+	manager := NewObscuroMessageBusManager(storage, big.NewInt(777), gethlog.New())
+	if _, err := manager.DeriveOwner(crypto.SharedEnclaveSecret{1}); err != nil {
+		t.Fatalf("could not derive owner. Cause: %s", err)
+	}
+	stateDB, err := storage.EmptyStateDB()
+	if err != nil {
+		t.Fatalf("could not create state. Cause: %s", err)
+	}
+	// The deliveries are recorded in the storage of the deployed message bus.
+	stateDB.SetCode(*manager.GetBusAddress(), []byte{1})
+	return storage, manager, stateDB
+}
+
+// Creates the synthetic transactions with replay protection, and records the deliveries as if the transactions
+// executed with the given status.
+func deliver(t *testing.T, manager Manager, messages common.CrossChainMessages, stateDB *state.StateDB, batch *common.BatchHeader, status uint64) common.L2Transactions {
+	t.Helper()
+	txs := manager.CreateSyntheticTransactions(messages, stateDB, batch, true)
+	if err := manager.RecordDeliveries(txs, receiptsFor(txs, status), stateDB, batch, true); err != nil {
+		t.Fatalf("could not record deliveries. Cause: %s", err)
+	}
+	return txs
+}
+
+func receiptsFor(txs common.L2Transactions, status uint64) common.L2Receipts {
+	receipts := make(common.L2Receipts, len(txs))
+	for i, tx := range txs {
+		receipts[i] = &types.Receipt{TxHash: tx.Hash(), Status: status}
+	}
+	return receipts
+}
@@ -0,0 +1,62 @@
+package crosschain
+
+import (
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/enclave/core"
+)
+
+const verifyMessageFinalizedMethod = "verifyMessageFinalized"
+
+// NewWithdrawalProof - Returns a pending withdrawal proof for a message published by the transaction in the batch, with
+// the call data to claim it from the L1 message bus at the given address.
+func NewWithdrawalProof(message common.CrossChainMessage, txHash gethcommon.Hash, batch *core.Batch, l1MessageBus gethcommon.Address) (*common.WithdrawalProof, error) {
+	messageHash, err := HashMessage(message)
+	if err != nil {
+		return nil, err
+	}
+	claimCallData, err := MessageBusABI.Pack(verifyMessageFinalizedMethod, message)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode claim call data. Cause: %w", err)
+	}
+
+	return &common.WithdrawalProof{
+		Message:           message,
+		MessageHash:       messageHash,
+		Status:            common.WithdrawalPending,
+		TxHash:            txHash,
+		BatchHash:         *batch.Hash(),
+		BatchNumber:       (*hexutil.Big)(batch.Number()),
+		MessageBusAddress: l1MessageBus,
+		ClaimCallData:     claimCallData,
+	}, nil
+}
+
+// MarkPublished - Records that the message was pushed to the L1 message bus by the rollup published in the L1 block, and
+// whether it is final as of the L1 head.
+func MarkPublished(proof *common.WithdrawalProof, rollup *core.Rollup, l1Block *common.L1Block, l1Head *common.L1Block) {
+	rollupHash := rollup.Hash()
+	l1BlockHash := l1Block.Hash()
+	timeOfFinality := hexutil.Uint64(TimeOfFinality(l1Block))
+
+	proof.RollupHash = rollupHash
+	proof.RollupNumber = (*hexutil.Big)(rollup.Header.Number)
+	proof.L1BlockHash = &l1BlockHash
+	proof.L1BlockNumber = (*hexutil.Big)(l1Block.Number())
+	proof.TimeOfFinality = &timeOfFinality
+
+	proof.Status = common.WithdrawalPublished
+	if uint64(timeOfFinality) <= l1Head.Time() {
+		proof.Status = common.WithdrawalFinal
+	}
+}
+
+// TimeOfFinality - Returns the L1 timestamp from which the L1 message bus considers the messages of a rollup published
+// in the given block final. The management contract stores the messages with the block's timestamp as the delay before
+// finality, and the message bus adds this delay to the block's timestamp.
+func TimeOfFinality(l1Block *common.L1Block) uint64 {
+	return l1Block.Time() + l1Block.Time()
+}
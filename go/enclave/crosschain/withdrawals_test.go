@@ -0,0 +1,60 @@
+package crosschain
+
+import (
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/enclave/core"
+)
+
+func TestWithdrawalProof(t *testing.T) {
+	message := common.CrossChainMessage{
+		Sender:   gethcommon.HexToAddress("0x1234"),
+		Sequence: 3,
+		Nonce:    7,
+		Topic:    1,
+		Payload:  []byte("withdraw"),
+	}
+	batch := &core.Batch{Header: &common.BatchHeader{Number: big.NewInt(5)}}
+	proof, err := NewWithdrawalProof(message, gethcommon.HexToHash("0xaa"), batch, gethcommon.HexToAddress("0xbb"))
+	if err != nil {
+		t.Fatalf("could not create withdrawal proof. Cause: %s", err)
+	}
+	if proof.Status != common.WithdrawalPending || proof.RollupHash != nil {
+		t.Fatalf("expected a pending withdrawal, got status %s", proof.Status)
+	}
+
+	// The claim call data must decode back to the message.
+	method, err := MessageBusABI.MethodById(proof.ClaimCallData)
+	if err != nil || method.Name != verifyMessageFinalizedMethod {
+		t.Fatalf("claim call data did not call %s", verifyMessageFinalizedMethod)
+	}
+	args, err := method.Inputs.Unpack(proof.ClaimCallData[4:])
+	if err != nil {
+		t.Fatalf("could not decode claim call data. Cause: %s", err)
+	}
+	if decoded, ok := args[0].(struct {
+		Sender           gethcommon.Address `json:"sender"`
+		Sequence         uint64             `json:"sequence"`
+		Nonce            uint32             `json:"nonce"`
+		Topic            uint32             `json:"topic"`
+		Payload          []byte             `json:"payload"`
+		ConsistencyLevel uint8              `json:"consistencyLevel"`
+	}); !ok || decoded.Sequence != message.Sequence || string(decoded.Payload) != string(message.Payload) {
+		t.Fatalf("claim call data did not contain the message")
+	}
+
+	rollup := &core.Rollup{Header: &common.RollupHeader{Number: big.NewInt(2)}}
+	l1Block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10), Time: 100})
+	MarkPublished(proof, rollup, l1Block, types.NewBlockWithHeader(&types.Header{Number: big.NewInt(11), Time: 110}))
+	if proof.Status != common.WithdrawalPublished || uint64(*proof.TimeOfFinality) != TimeOfFinality(l1Block) {
+		t.Fatalf("expected a published withdrawal, got status %s", proof.Status)
+	}
+	MarkPublished(proof, rollup, l1Block, types.NewBlockWithHeader(&types.Header{Number: big.NewInt(12), Time: TimeOfFinality(l1Block)}))
+	if proof.Status != common.WithdrawalFinal {
+		t.Fatalf("expected a final withdrawal, got status %s", proof.Status)
+	}
+}
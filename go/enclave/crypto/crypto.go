@@ -15,6 +15,7 @@ import (
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/obscuronet/go-obscuro/go/common"
 )
 
@@ -73,6 +74,47 @@ func EncryptSecret(pubKeyEncoded []byte, secret SharedEnclaveSecret, logger geth
 	return encKey, err
 }
 
+// EncryptEpochSecrets encrypts the secrets of all the epochs held by the enclave, indexed by epoch, so that a new enclave
+// can decrypt the data of every epoch.
+func EncryptEpochSecrets(pubKeyEncoded []byte, secrets []SharedEnclaveSecret) (common.EncryptedSharedEnclaveSecret, error) {
+	key, err := crypto.DecompressPubkey(pubKeyEncoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %w", err)
+	}
+	encoded, err := rlp.EncodeToBytes(secrets)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode epoch secrets. Cause: %w", err)
+	}
+	return encryptWithPublicKey(encoded, key)
+}
+
+// DecryptEpochSecrets decrypts the secrets encrypted by EncryptEpochSecrets. For compatibility with the secret responses
+// published before the secret was rotated in epochs, which carry the bare shared secret, a plaintext of the length of a
+// single secret is taken to be the secret of epoch 0. It cannot be mistaken for an RLP-encoded list of secrets, which is
+// always longer.
+func DecryptEpochSecrets(secrets common.EncryptedSharedEnclaveSecret, privateKey *ecdsa.PrivateKey) ([]SharedEnclaveSecret, error) {
+	if privateKey == nil {
+		return nil, errors.New("private key not found - shouldn't happen")
+	}
+	encoded, err := decryptWithPrivateKey(secrets, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(encoded) == sharedSecretLen {
+		var secret SharedEnclaveSecret
+		copy(secret[:], encoded)
+		return []SharedEnclaveSecret{secret}, nil
+	}
+	var decoded []SharedEnclaveSecret
+	if err = rlp.DecodeBytes(encoded, &decoded); err != nil {
+		return nil, fmt.Errorf("could not decode epoch secrets. Cause: %w", err)
+	}
+	if len(decoded) == 0 {
+		return nil, errors.New("no epoch secrets were received")
+	}
+	return decoded, nil
+}
+
 // Encrypts data with public key
 func encryptWithPublicKey(msg []byte, pub *ecdsa.PublicKey) ([]byte, error) {
 	ciphertext, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(pub), msg, nil, nil)
@@ -0,0 +1,46 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// RollupKeyInfo is the context the transaction blob key of an epoch is derived under.
+	RollupKeyInfo = []byte("obscuro.rollup.blob")
+	// RPCKeyInfo is the context the enclave RPC key of an epoch is derived under.
+	RPCKeyInfo = []byte("obscuro.rpc")
+)
+
+// EpochKeys are the keys used by the network during an epoch of the network secret.
+type EpochKeys struct {
+	Epoch     uint64
+	RollupKey []byte            // The AES key used to encrypt the transaction blobs of batches and rollups.
+	RPCKey    *ecdsa.PrivateKey // The key clients encrypt their requests to the enclave with.
+}
+
+// DeriveEpochKeys returns the keys of the given epoch. The keys of epoch 0 are the fixed keys the network starts with,
+// which existing clients and rollups rely on. The keys of later epochs are derived from the epoch's secret, so that
+// they are only known to the enclaves that were sent that secret.
+func DeriveEpochKeys(epoch uint64, secret SharedEnclaveSecret) (*EpochKeys, error) {
+	if epoch == 0 {
+		rpcKey, err := crypto.HexToECDSA(obscuroPrivateKeyHex)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse enclave RPC key. Cause: %w", err)
+		}
+		return &EpochKeys{RollupKey: gethcommon.Hex2Bytes(RollupEncryptionKeyHex), RPCKey: rpcKey}, nil
+	}
+
+	rollupKey, err := DeriveSymmetricKey(secret, RollupKeyInfo)
+	if err != nil {
+		return nil, fmt.Errorf("could not derive rollup key for epoch %d. Cause: %w", epoch, err)
+	}
+	rpcKey, err := DeriveKey(secret, RPCKeyInfo)
+	if err != nil {
+		return nil, fmt.Errorf("could not derive RPC key for epoch %d. Cause: %w", epoch, err)
+	}
+	return &EpochKeys{Epoch: epoch, RollupKey: rollupKey, RPCKey: rpcKey}, nil
+}
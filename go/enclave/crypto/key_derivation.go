@@ -0,0 +1,40 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/hkdf"
+)
+
+// MessageBusOwnerKeyInfo is the context the message bus owner key is derived under.
+var MessageBusOwnerKeyInfo = []byte("obscuro.crosschain.messagebus.owner")
+
+// DeriveKey - deterministically derives a private key for the given purpose from the shared secret, so that every
+// enclave holding the secret derives the same key while nobody without it can.
+func DeriveKey(secret SharedEnclaveSecret, info []byte) (*ecdsa.PrivateKey, error) {
+	reader := hkdf.New(sha256.New, secret[:], nil, info)
+	keyBytes := make([]byte, sharedSecretLen)
+	// A few candidates fall outside the range of valid secp256k1 keys, in which case we take the next ones.
+	for i := 0; i < 16; i++ {
+		if _, err := io.ReadFull(reader, keyBytes); err != nil {
+			return nil, fmt.Errorf("could not read derived key material. Cause: %w", err)
+		}
+		if key, err := crypto.ToECDSA(keyBytes); err == nil {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("could not derive a valid key")
+}
+
+// DeriveSymmetricKey - deterministically derives a 32-byte symmetric key for the given purpose from the shared secret.
+func DeriveSymmetricKey(secret SharedEnclaveSecret, info []byte) ([]byte, error) {
+	key := make([]byte, sharedSecretLen)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret[:], nil, info), key); err != nil {
+		return nil, fmt.Errorf("could not read derived key material. Cause: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,69 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// SnapshotKeyInfo is the context the key that encrypts state snapshots is derived under.
+var SnapshotKeyInfo = []byte("obscuro.snapshot")
+
+// EncryptSnapshot encrypts an encoded state snapshot with a key derived from the secret of the latest epoch, so that
+// only the enclaves currently authorised can read it, and so that any tampering with it is detected on decryption.
+func EncryptSnapshot(epoch uint64, secret SharedEnclaveSecret, snapshot []byte) ([]byte, error) {
+	snapshotCipher, err := newSnapshotCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, NonceLength)
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("could not generate nonce to encrypt snapshot. Cause: %w", err)
+	}
+	// We prepend the epoch and the nonce to the ciphertext, so that they can be retrieved when decrypting.
+	encryptedSnapshot := make([]byte, EpochLength, EpochLength+NonceLength)
+	binary.BigEndian.PutUint64(encryptedSnapshot, epoch)
+	encryptedSnapshot = append(encryptedSnapshot, nonce...)
+	return snapshotCipher.Seal(encryptedSnapshot, nonce, snapshot, nil), nil
+}
+
+// DecryptSnapshot decrypts a state snapshot encrypted with `EncryptSnapshot`, given the secrets of the epochs held by
+// the enclave, indexed by epoch.
+func DecryptSnapshot(secrets []SharedEnclaveSecret, encryptedSnapshot []byte) ([]byte, error) {
+	if len(encryptedSnapshot) < EpochLength+NonceLength {
+		return nil, errors.New("encrypted snapshot was too short")
+	}
+	epoch := binary.BigEndian.Uint64(encryptedSnapshot[:EpochLength])
+	if epoch >= uint64(len(secrets)) {
+		return nil, fmt.Errorf("snapshot was encrypted with the secret of epoch %d, which the enclave does not hold", epoch)
+	}
+	snapshotCipher, err := newSnapshotCipher(secrets[epoch])
+	if err != nil {
+		return nil, err
+	}
+	nonce := encryptedSnapshot[EpochLength : EpochLength+NonceLength]
+	snapshot, err := snapshotCipher.Open(nil, nonce, encryptedSnapshot[EpochLength+NonceLength:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt snapshot. Cause: %w", err)
+	}
+	return snapshot, nil
+}
+
+func newSnapshotCipher(secret SharedEnclaveSecret) (cipher.AEAD, error) {
+	key := make([]byte, sharedSecretLen)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret[:], nil, SnapshotKeyInfo), key); err != nil {
+		return nil, fmt.Errorf("could not derive snapshot key. Cause: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialise AES cipher for snapshot key. Cause: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -4,7 +4,11 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"encoding/binary"
+	"fmt"
 	"io"
+	"math/bits"
+	"sync"
 
 	gethlog "github.com/ethereum/go-ethereum/log"
 
@@ -13,75 +17,191 @@ import (
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/compression"
 )
 
 const (
-	// RollupEncryptionKeyHex is the AES key used to encrypt and decrypt the transaction blob in rollups.
-	// TODO - Replace this fixed key with derived, rotating keys.
+	// RollupEncryptionKeyHex is the AES key used to encrypt and decrypt the transaction blob in rollups during epoch 0 of
+	// the network secret. The keys of later epochs are derived from the epoch's secret.
 	RollupEncryptionKeyHex = "bddbc0d46a0666ce57a466168d99c1830b0c65e052d77188f2cbfc3f6486588c"
+	// EpochLength is the length in bytes of the epoch that prefixes an encrypted transaction blob.
+	EpochLength = 8
 	// NonceLength is the nonce's length in bytes for encrypting and decrypting transactions.
 	NonceLength = 12
+
+	// paddedBlobVersion prefixes padded plaintexts. Plaintexts encrypted before padding was introduced start with either
+	// a zstd frame or an RLP list prefix instead, so both can be told apart when decrypting.
+	paddedBlobVersion = 0x02
+	// paddedBlobHeaderLength is the length in bytes of the version and payload length that prefix a padded plaintext.
+	paddedBlobHeaderLength = 5
 )
 
 // TransactionBlobCrypto handles the encryption and decryption of the transaction blobs stored inside a rollup.
 type TransactionBlobCrypto interface {
+	// Encrypt encrypts the transactions with the key of the latest epoch.
 	Encrypt(transactions []*common.L2Tx) common.EncryptedTransactions
-	Decrypt(encryptedTxs common.EncryptedTransactions) []*common.L2Tx
+	// Decrypt decrypts transactions encrypted with the key of any epoch that has been added.
+	Decrypt(encryptedTxs common.EncryptedTransactions) ([]*common.L2Tx, error)
+	// AddEpochKey adds the key of an epoch of the network secret.
+	AddEpochKey(epoch uint64, key []byte) error
+	// SetLatestEpoch sets the epoch whose key the transactions are encrypted with, which goes back to an earlier epoch if
+	// the later ones were published on an L1 fork that was abandoned.
+	SetLatestEpoch(epoch uint64) error
 }
 
 type TransactionBlobCryptoImpl struct {
-	transactionCipher cipher.AEAD
-	logger            gethlog.Logger
+	transactionCiphers map[uint64]cipher.AEAD // The ciphers for each epoch, by epoch.
+	latestEpoch        uint64
+	mutex              sync.RWMutex
+	logger             gethlog.Logger
 }
 
 func NewTransactionBlobCryptoImpl(logger gethlog.Logger) TransactionBlobCrypto {
-	key := gethcommon.Hex2Bytes(RollupEncryptionKeyHex)
+	blobCrypto := &TransactionBlobCryptoImpl{
+		transactionCiphers: map[uint64]cipher.AEAD{},
+		logger:             logger,
+	}
+	if err := blobCrypto.AddEpochKey(0, gethcommon.Hex2Bytes(RollupEncryptionKeyHex)); err != nil {
+		logger.Crit("could not initialise cipher for enclave rollup key.", log.ErrKey, err)
+	}
+	return blobCrypto
+}
+
+func (t *TransactionBlobCryptoImpl) AddEpochKey(epoch uint64, key []byte) error {
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		logger.Crit("could not initialise AES cipher for enclave rollup key.", log.ErrKey, err)
+		return fmt.Errorf("could not initialise AES cipher for rollup key of epoch %d. Cause: %w", epoch, err)
 	}
 	transactionCipher, err := cipher.NewGCM(block)
 	if err != nil {
-		logger.Crit("could not initialise wrapper for AES cipher for enclave rollup key. ", log.ErrKey, err)
+		return fmt.Errorf("could not initialise wrapper for AES cipher for rollup key of epoch %d. Cause: %w", epoch, err)
 	}
-	return TransactionBlobCryptoImpl{
-		transactionCipher: transactionCipher,
-		logger:            logger,
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.transactionCiphers[epoch] = transactionCipher
+	if epoch > t.latestEpoch {
+		t.latestEpoch = epoch
 	}
+	return nil
 }
 
+func (t *TransactionBlobCryptoImpl) SetLatestEpoch(epoch uint64) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if _, ok := t.transactionCiphers[epoch]; !ok {
+		return fmt.Errorf("no rollup key was added for epoch %d", epoch)
+	}
+	t.latestEpoch = epoch
+	return nil
+}
+
+// The transactions are compressed before they are encrypted, since the ciphertext cannot be compressed. Since the
+// compression ratio depends on the contents of the transactions, the compressed plaintext is padded before it is
+// encrypted, so that the length of the ciphertext only reveals its size class (see `pad`).
 // TODO - Modify this logic so that transactions with different reveal periods are in different blobs, as per the whitepaper.
-func (t TransactionBlobCryptoImpl) Encrypt(transactions []*common.L2Tx) common.EncryptedTransactions {
+func (t *TransactionBlobCryptoImpl) Encrypt(transactions []*common.L2Tx) common.EncryptedTransactions {
 	encodedTxs, err := rlp.EncodeToBytes(transactions)
 	if err != nil {
 		t.logger.Crit("could not encrypt L2 transaction.", log.ErrKey, err)
 	}
+	// Small blobs (e.g. without any transactions) are left uncompressed, as compression would make them larger.
+	if compressedTxs := compression.Compress(encodedTxs); len(compressedTxs) < len(encodedTxs) {
+		encodedTxs = compressedTxs
+	}
+
+	encodedTxs = pad(encodedTxs)
 
 	nonce := make([]byte, NonceLength)
 	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
 		t.logger.Crit("could not generate nonce to encrypt transactions.", log.ErrKey, err)
 	}
 
+	t.mutex.RLock()
+	epoch := t.latestEpoch
+	transactionCipher := t.transactionCiphers[epoch]
+	t.mutex.RUnlock()
+
 	// TODO - Ensure this nonce is not used too many times (2^32?) with the same key, to avoid risk of repeat.
-	ciphertext := t.transactionCipher.Seal(nil, nonce, encodedTxs, nil)
-	// We prepend the nonce to the ciphertext, so that it can be retrieved when decrypting.
-	return append(nonce, ciphertext...) //nolint:makezero
+	ciphertext := transactionCipher.Seal(nil, nonce, encodedTxs, nil)
+	// We prepend the epoch and the nonce to the ciphertext, so that they can be retrieved when decrypting.
+	encryptedTxs := make([]byte, EpochLength, EpochLength+NonceLength+len(ciphertext))
+	binary.BigEndian.PutUint64(encryptedTxs, epoch)
+	encryptedTxs = append(encryptedTxs, nonce...)
+	return append(encryptedTxs, ciphertext...)
 }
 
-func (t TransactionBlobCryptoImpl) Decrypt(encryptedTxs common.EncryptedTransactions) []*common.L2Tx {
-	// The nonce is prepended to the ciphertext.
-	nonce := encryptedTxs[0:NonceLength]
-	ciphertext := encryptedTxs[NonceLength:]
+func (t *TransactionBlobCryptoImpl) Decrypt(encryptedTxs common.EncryptedTransactions) ([]*common.L2Tx, error) {
+	if len(encryptedTxs) < EpochLength+NonceLength {
+		return nil, fmt.Errorf("encrypted transactions were too short")
+	}
+	// The epoch and the nonce are prepended to the ciphertext.
+	epoch := binary.BigEndian.Uint64(encryptedTxs[:EpochLength])
+	nonce := encryptedTxs[EpochLength : EpochLength+NonceLength]
+	ciphertext := encryptedTxs[EpochLength+NonceLength:]
+
+	t.mutex.RLock()
+	transactionCipher, found := t.transactionCiphers[epoch]
+	t.mutex.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("transactions were encrypted with the key of epoch %d, which the enclave does not hold", epoch)
+	}
 
-	encodedTxs, err := t.transactionCipher.Open(nil, nonce, ciphertext, nil)
+	encodedTxs, err := transactionCipher.Open(nil, nonce, ciphertext, nil)
 	if err != nil {
-		t.logger.Crit("could not decrypt encrypted L2 transactions.", log.ErrKey, err)
+		return nil, fmt.Errorf("could not decrypt encrypted L2 transactions. Cause: %w", err)
+	}
+	if encodedTxs, err = unpad(encodedTxs); err != nil {
+		return nil, fmt.Errorf("could not remove padding from L2 transactions. Cause: %w", err)
+	}
+	if compression.IsCompressed(encodedTxs) {
+		if encodedTxs, err = compression.Decompress(encodedTxs, 0); err != nil {
+			return nil, fmt.Errorf("could not decompress L2 transactions. Cause: %w", err)
+		}
 	}
 
 	var txs []*common.L2Tx
 	if err := rlp.DecodeBytes(encodedTxs, &txs); err != nil {
-		t.logger.Crit("could not decode encoded L2 transactions.", log.ErrKey, err)
+		return nil, fmt.Errorf("could not decode encoded L2 transactions. Cause: %w", err)
 	}
 
-	return txs
+	return txs, nil
+}
+
+// Prefixes the data with its length and pads it with zeroes to the size given by the Padmé scheme, which rounds the
+// length up to a number whose binary representation only has O(log log L) significant bits. This bounds both the
+// information revealed by the length to O(log log L) bits, and the padding overhead to 12%.
+func pad(data []byte) []byte {
+	length := uint64(paddedBlobHeaderLength + len(data))
+	paddedLength := length
+	if length >= 2 {
+		exponent := bits.Len64(length) - 1              // floor(log2(L))
+		significantBits := bits.Len64(uint64(exponent)) // floor(log2(floor(log2(L)))) + 1
+		lastBits := exponent - significantBits
+		if lastBits > 0 {
+			bitMask := uint64(1)<<lastBits - 1
+			paddedLength = (length + bitMask) &^ bitMask
+		}
+	}
+
+	padded := make([]byte, paddedLength)
+	padded[0] = paddedBlobVersion
+	binary.BigEndian.PutUint32(padded[1:paddedBlobHeaderLength], uint32(len(data)))
+	copy(padded[paddedBlobHeaderLength:], data)
+	return padded
+}
+
+// Removes the padding added by `pad`. Plaintexts encrypted before padding was introduced are returned unchanged.
+func unpad(data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] != paddedBlobVersion {
+		return data, nil
+	}
+	if len(data) < paddedBlobHeaderLength {
+		return nil, fmt.Errorf("padded data was too short")
+	}
+	length := uint64(binary.BigEndian.Uint32(data[1:paddedBlobHeaderLength]))
+	if length > uint64(len(data)-paddedBlobHeaderLength) {
+		return nil, fmt.Errorf("padded data was shorter than its declared length of %d bytes", length)
+	}
+	return data[paddedBlobHeaderLength : paddedBlobHeaderLength+length], nil
 }
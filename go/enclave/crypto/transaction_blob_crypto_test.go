@@ -0,0 +1,72 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/compression"
+	"github.com/obscuronet/go-obscuro/integration/datagenerator"
+)
+
+func TestTransactionBlobsAreReadableOnlyWithTheKeyOfTheirEpoch(t *testing.T) {
+	txs := []*common.L2Tx{datagenerator.CreateL2Tx()}
+	blobCrypto := NewTransactionBlobCryptoImpl(nil)
+	epochZeroBlob := blobCrypto.Encrypt(txs)
+
+	epochKeys, err := DeriveEpochKeys(1, GenerateEntropy(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = blobCrypto.AddEpochKey(1, epochKeys.RollupKey); err != nil {
+		t.Fatal(err)
+	}
+	epochOneBlob := blobCrypto.Encrypt(txs)
+
+	for _, blob := range []common.EncryptedTransactions{epochZeroBlob, epochOneBlob} {
+		decryptedTxs, err := blobCrypto.Decrypt(blob)
+		if err != nil {
+			t.Fatalf("could not decrypt transactions. Cause: %s", err)
+		}
+		if len(decryptedTxs) != 1 || decryptedTxs[0].Hash() != txs[0].Hash() {
+			t.Fatal("decrypted transactions did not match the encrypted transactions")
+		}
+	}
+
+	// An enclave that was not sent the secret of epoch 1 can still read the blobs of epoch 0, but not those of epoch 1.
+	revokedCrypto := NewTransactionBlobCryptoImpl(nil)
+	if _, err = revokedCrypto.Decrypt(epochZeroBlob); err != nil {
+		t.Fatalf("could not decrypt transactions of epoch 0. Cause: %s", err)
+	}
+	if _, err = revokedCrypto.Decrypt(epochOneBlob); err == nil {
+		t.Fatal("expected transactions of epoch 1 not to be decryptable without the secret of epoch 1")
+	}
+}
+
+func TestPaddingOnlyRevealsTheSizeClass(t *testing.T) {
+	// Lengths of the same size class are padded to the same length.
+	if len(pad(make([]byte, 1000))) != len(pad(make([]byte, 1010))) {
+		t.Error("expected lengths of the same size class to be padded to the same length")
+	}
+
+	for _, length := range []int{0, 1, 100, 1000, 10_000, 100_000} {
+		data := datagenerator.RandomBytes(length)
+		padded := pad(data)
+		if overhead := float64(len(padded)-length-paddedBlobHeaderLength) / float64(length+paddedBlobHeaderLength); overhead > 0.12 {
+			t.Errorf("expected padding overhead of at most 12%% for length %d, got %.2f", length, overhead)
+		}
+		unpadded, err := unpad(padded)
+		if err != nil {
+			t.Fatalf("could not remove padding. Cause: %s", err)
+		}
+		if !bytes.Equal(unpadded, data) {
+			t.Errorf("expected padding of length %d to be removed", length)
+		}
+	}
+
+	// Plaintexts encrypted before padding was introduced are left unchanged.
+	legacy := compression.Compress([]byte("transactions"))
+	if unpadded, err := unpad(legacy); err != nil || !bytes.Equal(unpadded, legacy) {
+		t.Error("expected unpadded plaintext to be left unchanged")
+	}
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/obscuronet/go-obscuro/go/common"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+func TestAttestationAllowListFollowsTheL1Fork(t *testing.T) {
+	storage := NewStorage(rawdb.NewMemoryDatabase(), params.AllEthashProtocolChanges, gethlog.New())
+
+	genesisBlock := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0)})
+	canonicalBlock := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), ParentHash: genesisBlock.Hash()})
+	forkBlock := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), ParentHash: genesisBlock.Hash(), Time: 1})
+	childBlock := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(2), ParentHash: canonicalBlock.Hash()})
+	for _, block := range []*types.Block{genesisBlock, canonicalBlock, forkBlock, childBlock} {
+		storage.StoreBlock(block)
+	}
+
+	genesisAllowList := &common.AttestationAllowList{Version: 1, UniqueIDs: []gethcommon.Hash{gethcommon.HexToHash("0x01")}}
+	forkAllowList := &common.AttestationAllowList{Version: 2, UniqueIDs: []gethcommon.Hash{gethcommon.HexToHash("0x02")}}
+	if err := storage.StoreAttestationAllowList(genesisBlock.Hash(), genesisAllowList); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.StoreAttestationAllowList(forkBlock.Hash(), forkAllowList); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedAllowLists := map[*types.Block]*common.AttestationAllowList{
+		genesisBlock:   genesisAllowList,
+		forkBlock:      forkAllowList,
+		canonicalBlock: genesisAllowList,
+		childBlock:     genesisAllowList,
+	}
+	for block, expected := range expectedAllowLists {
+		allowList, err := storage.FetchAttestationAllowList(block.Hash())
+		if err != nil {
+			t.Fatalf("could not fetch allow-list at block %d. Cause: %s", block.NumberU64(), err)
+		}
+		if allowList.Version != expected.Version {
+			t.Errorf("expected allow-list version %d at block %s, got %d", expected.Version, block.Hash(), allowList.Version)
+		}
+	}
+}
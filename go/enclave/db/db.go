@@ -14,7 +14,7 @@ import (
 
 // CreateDBFromConfig creates an appropriate ethdb.Database instance based on your config
 func CreateDBFromConfig(cfg config.EnclaveConfig, logger gethlog.Logger) (ethdb.Database, error) {
-	if err := validateDBConf(cfg); err != nil {
+	if err := cfg.ValidateDB(); err != nil {
 		return nil, err
 	}
 	if cfg.UseInMemoryDB {
@@ -35,26 +35,6 @@ func CreateDBFromConfig(cfg config.EnclaveConfig, logger gethlog.Logger) (ethdb.
 	return getEdgelessDB(cfg, logger)
 }
 
-// validateDBConf high-level checks that you have a valid configuration for DB creation
-func validateDBConf(cfg config.EnclaveConfig) error {
-	if cfg.UseInMemoryDB && cfg.EdgelessDBHost != "" {
-		return fmt.Errorf("invalid db config, useInMemoryDB=true so EdgelessDB host not expected, but EdgelessDBHost=%s", cfg.EdgelessDBHost)
-	}
-	if !cfg.WillAttest && cfg.EdgelessDBHost != "" {
-		return fmt.Errorf("invalid db config, willAttest=false so EdgelessDB host not supported, but EdgelessDBHost=%s", cfg.EdgelessDBHost)
-	}
-	if !cfg.UseInMemoryDB && cfg.WillAttest && cfg.EdgelessDBHost == "" {
-		return fmt.Errorf("useInMemoryDB=false, willAttest=true so expected an EdgelessDB host but none was provided")
-	}
-	if cfg.SqliteDBPath != "" && cfg.UseInMemoryDB {
-		return fmt.Errorf("useInMemoryDB=true so sqlite database will not be used and no path is needed, but sqliteDBPath=%s", cfg.SqliteDBPath)
-	}
-	if cfg.SqliteDBPath != "" && cfg.WillAttest {
-		return fmt.Errorf("willAttest=true so sqlite database will not be used and no path is needed, but sqliteDBPath=%s", cfg.SqliteDBPath)
-	}
-	return nil
-}
-
 func getInMemDB() (ethdb.Database, error) {
 	return rawdb.NewMemoryDatabase(), nil
 }
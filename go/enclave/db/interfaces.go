@@ -2,7 +2,9 @@ package db
 
 import (
 	"crypto/ecdsa"
+	"time"
 
+	"github.com/obscuronet/go-obscuro/go/enclave/chainconfig"
 	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
 
 	gethcommon "github.com/ethereum/go-ethereum/common"
@@ -42,8 +44,19 @@ type BatchResolver interface {
 }
 
 type RollupResolver interface {
-	// StoreRollup stores a rollup.
-	StoreRollup(rollup *core.Rollup) error
+	// StoreRollup stores a rollup, and records that it was published in the L1 block with the given hash.
+	StoreRollup(rollup *core.Rollup, l1Block common.L1RootHash) error
+	// FetchRollupForBatch returns the rollup containing the batch with the given hash that was published on the
+	// canonical L1 chain, and the hash of the L1 block the rollup was published in. Returns ErrNotFound if the batch was
+	// only rolled up on L1 forks.
+	FetchRollupForBatch(batchHash common.L2RootHash) (*core.Rollup, *common.L1RootHash, error)
+	// FetchInFlightRollup returns the number of the last rollup produced by the sequencer that has not been seen on the
+	// L1 yet, and the time it was produced at.
+	FetchInFlightRollup() (uint64, time.Time, error)
+	// StoreInFlightRollup stores the number of the last rollup produced by the sequencer, and the time it was produced at.
+	StoreInFlightRollup(number uint64, producedAt time.Time) error
+	// DeleteInFlightRollup records that no rollup produced by the sequencer is awaiting inclusion on the L1.
+	DeleteInFlightRollup() error
 }
 
 type HeadsAfterL1BlockStorage interface {
@@ -64,6 +77,9 @@ type HeadsAfterL1BlockStorage interface {
 	CreateStateDB(hash common.L2RootHash) (*state.StateDB, error)
 	// EmptyStateDB creates the original empty StateDB
 	EmptyStateDB() (*state.StateDB, error)
+	// CommitStateDB commits the state of the batch with the given number, and prunes the states that have fallen out of
+	// the retention window
+	CommitStateDB(stateDB *state.StateDB, batchNumber uint64) (common.StateRoot, error)
 }
 
 type SharedSecretStorage interface {
@@ -71,6 +87,21 @@ type SharedSecretStorage interface {
 	FetchSecret() (*crypto.SharedEnclaveSecret, error)
 	// StoreSecret stores a secret in the enclave
 	StoreSecret(secret crypto.SharedEnclaveSecret) error
+	// FetchEpochSecrets returns the secrets of the epochs held by the enclave, indexed by epoch, starting with the secret of epoch 0
+	FetchEpochSecrets() ([]crypto.SharedEnclaveSecret, error)
+	// StoreEpochSecret stores the secret of an epoch, and the hash of the L1 block it was published in, or nil if it was
+	// received from another enclave
+	StoreEpochSecret(epoch uint64, secret crypto.SharedEnclaveSecret, l1Block *common.L1RootHash) error
+	// FetchLatestEpoch returns the latest epoch whose secret was published on the L1 chain ending in the block with the
+	// given hash. The secrets received from another enclave are taken to be published on every L1 chain
+	FetchLatestEpoch(l1Block common.L1RootHash) (uint64, error)
+}
+
+type ChainConfigStorage interface {
+	// FetchChainConfig returns the most recent version of the chain config applied by the enclave
+	FetchChainConfig() (*chainconfig.ChainConfig, error)
+	// StoreChainConfig stores the version of the chain config applied by the enclave
+	StoreChainConfig(cfg *chainconfig.ChainConfig) error
 }
 
 type TransactionStorage interface {
@@ -91,11 +122,46 @@ type AttestationStorage interface {
 	FetchAttestedKey(aggregator gethcommon.Address) (*ecdsa.PublicKey, error)
 	// StoreAttestedKey - store the public key of an attested aggregator
 	StoreAttestedKey(aggregator gethcommon.Address, key *ecdsa.PublicKey) error
+	// FetchAttestedKeys returns the public keys of all attested aggregators, by aggregator
+	FetchAttestedKeys() (map[gethcommon.Address]*ecdsa.PublicKey, error)
+	// IsAttestationRevoked returns whether the attestation of an aggregator was revoked on the L1 chain ending in the
+	// block with the given hash
+	IsAttestationRevoked(aggregator gethcommon.Address, l1Block common.L1RootHash) (bool, error)
+	// StoreAttestationRevocation - stores the revocation of an aggregator's attestation, and the hash of the L1 block it
+	// was published in
+	StoreAttestationRevocation(aggregator gethcommon.Address, l1Block common.L1RootHash) error
+	// FetchAttestationAllowList returns the attestation allow-list with the highest version that was published on the
+	// L1 chain ending in the block with the given hash
+	FetchAttestationAllowList(l1Block common.L1RootHash) (*common.AttestationAllowList, error)
+	// StoreAttestationAllowList - stores an attestation allow-list applied by the enclave, and the hash of the L1 block
+	// it was published in
+	StoreAttestationAllowList(l1Block common.L1RootHash, allowList *common.AttestationAllowList) error
 }
 
 type CrossChainMessagesStorage interface {
 	StoreL1Messages(blockHash common.L1RootHash, messages common.CrossChainMessages) error
 	GetL1Messages(blockHash common.L1RootHash) (common.CrossChainMessages, error)
+
+	// StoreInboundMessageRecord - stores the audit record of a cross-chain message sent from the L1
+	StoreInboundMessageRecord(record *common.InboundMessageRecord) error
+	// GetInboundMessageRecord - returns the audit record of the L1 message with the given sender and sequence number
+	GetInboundMessageRecord(sender gethcommon.Address, sequence uint64) (*common.InboundMessageRecord, error)
+	// GetInboundMessageRecords - returns the audit records of the L1 messages from the given sender, in sequence order
+	GetInboundMessageRecords(sender gethcommon.Address) ([]*common.InboundMessageRecord, error)
+}
+
+type DivergenceReportStorage interface {
+	// StoreDivergenceReport - stores the report of a batch that did not match its re-execution
+	StoreDivergenceReport(report *common.DivergenceReport) error
+	// FetchDivergenceReports - returns the stored divergence reports, in batch number order
+	FetchDivergenceReports() ([]*common.DivergenceReport, error)
+}
+
+type SnapshotStorage interface {
+	// ExportSnapshot - returns the data needed to resume processing the L1 from the block with the given hash
+	ExportSnapshot(l1Head common.L1RootHash) (*Snapshot, error)
+	// ImportSnapshot - checks the snapshot and stores its data, so that processing resumes from its L1 head
+	ImportSnapshot(snapshot *Snapshot) error
 }
 
 // Storage is the enclave's interface for interacting with the enclave's datastore
@@ -104,10 +170,13 @@ type Storage interface {
 	BatchResolver
 	RollupResolver
 	SharedSecretStorage
+	ChainConfigStorage
 	HeadsAfterL1BlockStorage
 	TransactionStorage
 	AttestationStorage
 	CrossChainMessagesStorage
+	DivergenceReportStorage
+	SnapshotStorage
 
 	// HealthCheck returns whether the storage is deemed healthy or not
 	HealthCheck() (bool, error)
@@ -4,12 +4,22 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 )
 
 func ReadAttestationKey(db ethdb.KeyValueReader, address gethcommon.Address) (*ecdsa.PublicKey, error) {
+	has, err := db.Has(attestationPkKey(address))
+	if err != nil {
+		return nil, fmt.Errorf("could not check for attestation key for address %s. Cause: %w", address, err)
+	}
+	if !has {
+		return nil, errutil.ErrNotFound
+	}
 	key, err := db.Get(attestationPkKey(address))
 	if err != nil {
 		return nil, fmt.Errorf("could not retrieve attestation key for address %s. Cause: %w", address, err)
@@ -29,3 +39,100 @@ func WriteAttestationKey(db ethdb.KeyValueWriter, address gethcommon.Address, ke
 	}
 	return nil
 }
+
+// ReadAttestationKeys returns the attested keys of all the aggregators, by aggregator address.
+func ReadAttestationKeys(db ethdb.Iteratee) (map[gethcommon.Address]*ecdsa.PublicKey, error) {
+	it := db.NewIterator(attestationKeyPrefix, nil)
+	defer it.Release()
+
+	keys := map[gethcommon.Address]*ecdsa.PublicKey{}
+	for it.Next() {
+		// Other entries may share the prefix, so we only take those of the expected length.
+		if len(it.Key()) != len(attestationKeyPrefix)+gethcommon.AddressLength {
+			continue
+		}
+		publicKey, err := crypto.DecompressPubkey(it.Value())
+		if err != nil {
+			return nil, fmt.Errorf("could not parse key from db. Cause: %w", err)
+		}
+		keys[gethcommon.BytesToAddress(it.Key()[len(attestationKeyPrefix):])] = publicKey
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("could not iterate over attestation keys. Cause: %w", err)
+	}
+	return keys, nil
+}
+
+// ReadAttestationRevocations returns the hashes of the L1 blocks in which the aggregator's attestation was revoked. A
+// revocation stored before revocations were recorded by L1 block is returned with the zero hash.
+func ReadAttestationRevocations(db ethdb.Iteratee, address gethcommon.Address) ([]common.L1RootHash, error) {
+	revocations, err := readAttestationRevocations(db, revokedAttestationsKey(address))
+	if err != nil {
+		return nil, err
+	}
+	return revocations[address], nil
+}
+
+func WriteAttestationRevocation(db ethdb.KeyValueWriter, address gethcommon.Address, l1Block common.L1RootHash) error {
+	if err := db.Put(revokedAttestationKey(address, l1Block), []byte{1}); err != nil {
+		return fmt.Errorf("could not write attestation revocation. Cause: %w", err)
+	}
+	return nil
+}
+
+// ReadRevokedAttestations returns the hashes of the L1 blocks in which the attestation of each revoked aggregator was
+// revoked, by aggregator.
+func ReadRevokedAttestations(db ethdb.Iteratee) (map[gethcommon.Address][]common.L1RootHash, error) {
+	return readAttestationRevocations(db, revokedAttestationPrefix)
+}
+
+func readAttestationRevocations(db ethdb.Iteratee, prefix []byte) (map[gethcommon.Address][]common.L1RootHash, error) {
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	revocations := map[gethcommon.Address][]common.L1RootHash{}
+	for it.Next() {
+		key := it.Key()[len(revokedAttestationPrefix):]
+		if len(key) < gethcommon.AddressLength {
+			continue
+		}
+		aggregator := gethcommon.BytesToAddress(key[:gethcommon.AddressLength])
+		// Revocations stored before they were recorded by L1 block have no block hash in their key.
+		revocations[aggregator] = append(revocations[aggregator], gethcommon.BytesToHash(key[gethcommon.AddressLength:]))
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("could not iterate over attestation revocations. Cause: %w", err)
+	}
+	return revocations, nil
+}
+
+// ReadAttestationAllowLists returns the attestation allow-lists applied by the enclave, by the hash of the L1 block
+// they were published in.
+func ReadAttestationAllowLists(db ethdb.Iteratee) (map[common.L1RootHash]*common.AttestationAllowList, error) {
+	it := db.NewIterator(attestationAllowListPrefix, nil)
+	defer it.Release()
+
+	allowLists := map[common.L1RootHash]*common.AttestationAllowList{}
+	for it.Next() {
+		allowList, err := common.DecodeAttestationAllowList(it.Value())
+		if err != nil {
+			return nil, err
+		}
+		allowLists[gethcommon.BytesToHash(it.Key()[len(attestationAllowListPrefix):])] = allowList
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("could not iterate over attestation allow-lists. Cause: %w", err)
+	}
+	return allowLists, nil
+}
+
+func WriteAttestationAllowList(db ethdb.KeyValueWriter, l1Block common.L1RootHash, allowList *common.AttestationAllowList) error {
+	encoded, err := common.EncodeAttestationAllowList(allowList)
+	if err != nil {
+		return fmt.Errorf("could not encode attestation allow-list. Cause: %w", err)
+	}
+	if err = db.Put(attestationAllowListKey(l1Block), encoded); err != nil {
+		return fmt.Errorf("could not write attestation allow-list. Cause: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"time"
 
 	"github.com/obscuronet/go-obscuro/go/common/errutil"
 
@@ -82,6 +83,54 @@ func WriteRollup(db ethdb.KeyValueWriter, rollup *core.Rollup) error {
 	return nil
 }
 
+// WriteRollupInclusion records the L1 block the rollup was published in, and the rollup containing each of its batches.
+func WriteRollupInclusion(db ethdb.KeyValueWriter, rollup *core.Rollup, l1Block common.L1RootHash) error {
+	rollupHash := rollup.Hash()
+	if err := db.Put(rollupL1BlockKey(*rollupHash), l1Block.Bytes()); err != nil {
+		return fmt.Errorf("could not put rollup L1 block in DB. Cause: %w", err)
+	}
+	for _, batch := range rollup.Batches {
+		if err := db.Put(rollupForBatchKey(*batch.Hash(), *rollupHash), rollupHash.Bytes()); err != nil {
+			return fmt.Errorf("could not put rollup for batch in DB. Cause: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReadRollupsForBatch returns the hashes of the rollups containing the batch with the given hash, which may have been
+// published on different L1 forks.
+func ReadRollupsForBatch(db ethdb.Iteratee, batchHash common.L2RootHash) ([]common.L2RootHash, error) {
+	it := db.NewIterator(rollupsForBatchKey(batchHash), nil)
+	defer it.Release()
+
+	var rollupHashes []common.L2RootHash
+	seen := map[common.L2RootHash]bool{}
+	for it.Next() {
+		rollupHash := gethcommon.BytesToHash(it.Value())
+		if !seen[rollupHash] {
+			seen[rollupHash] = true
+			rollupHashes = append(rollupHashes, rollupHash)
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("could not iterate over rollups for batch. Cause: %w", err)
+	}
+	if len(rollupHashes) == 0 {
+		return nil, errutil.ErrNotFound
+	}
+	return rollupHashes, nil
+}
+
+// ReadRollupL1Block returns the hash of the L1 block the rollup with the given hash was published in.
+func ReadRollupL1Block(kv ethdb.KeyValueReader, rollupHash common.L2RootHash) (*common.L1RootHash, error) {
+	data, err := kv.Get(rollupL1BlockKey(rollupHash))
+	if err != nil {
+		return nil, errutil.ErrNotFound
+	}
+	blockHash := gethcommon.BytesToHash(data)
+	return &blockHash, nil
+}
+
 // Stores a batch header into the database and also stores the hash-to-number mapping.
 func writeBatchHeader(db ethdb.KeyValueWriter, header *common.BatchHeader) error {
 	// Write the hash -> number mapping
@@ -372,3 +421,33 @@ func readRollupBodyRLP(db ethdb.KeyValueReader, hash common.L2RootHash) (rlp.Raw
 	}
 	return data, nil
 }
+
+// ReadInFlightRollup returns the number of the last rollup produced by the sequencer that has not been seen on the L1
+// yet, and the time it was produced at.
+func ReadInFlightRollup(db ethdb.KeyValueReader) (uint64, time.Time, error) {
+	data, err := db.Get(inFlightRollup)
+	if err != nil {
+		return 0, time.Time{}, errutil.ErrNotFound
+	}
+	if len(data) != 16 {
+		return 0, time.Time{}, fmt.Errorf("in-flight rollup record has invalid length %d", len(data))
+	}
+	number := binary.BigEndian.Uint64(data[:8])
+	producedAt := time.Unix(0, int64(binary.BigEndian.Uint64(data[8:])))
+	return number, producedAt, nil
+}
+
+func WriteInFlightRollup(db ethdb.KeyValueWriter, number uint64, producedAt time.Time) error {
+	data := append(encodeNumber(number), encodeNumber(uint64(producedAt.UnixNano()))...)
+	if err := db.Put(inFlightRollup, data); err != nil {
+		return fmt.Errorf("could not put in-flight rollup in DB. Cause: %w", err)
+	}
+	return nil
+}
+
+func DeleteInFlightRollup(db ethdb.KeyValueWriter) error {
+	if err := db.Delete(inFlightRollup); err != nil {
+		return fmt.Errorf("could not delete in-flight rollup from DB. Cause: %w", err)
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package rawdb
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -47,3 +49,63 @@ func GetL1Messages(db ethdb.KeyValueReader, blockHash gethcommon.Hash, logger ge
 	}
 	return messages, nil
 }
+
+// WriteInboundMessageRecord stores the audit record of an inbound cross-chain message, replacing any earlier version.
+func WriteInboundMessageRecord(db ethdb.KeyValueWriter, record *common.InboundMessageRecord) error {
+	// The records are only read back through the authorised debugging API, so we store them as JSON.
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("could not encode inbound message record. Cause: %w", err)
+	}
+	if err = db.Put(inboundMessageKey(record.Sender, record.Sequence), data); err != nil {
+		return fmt.Errorf("could not store inbound message record. Cause: %w", err)
+	}
+	return nil
+}
+
+// ReadInboundMessageRecord retrieves the audit record of the inbound cross-chain message with the given sender and
+// sequence number.
+func ReadInboundMessageRecord(db ethdb.KeyValueReader, sender gethcommon.Address, sequence uint64) (*common.InboundMessageRecord, error) {
+	data, err := db.Get(inboundMessageKey(sender, sequence))
+	if err != nil {
+		return nil, errutil.ErrNotFound
+	}
+	return decodeInboundMessageRecord(data)
+}
+
+// ReadInboundMessageRecords retrieves the audit records of the inbound cross-chain messages from the given sender, in
+// sequence order.
+func ReadInboundMessageRecords(db ethdb.Iteratee, sender gethcommon.Address) ([]*common.InboundMessageRecord, error) {
+	return readInboundMessageRecords(db, inboundMessagesKey(sender))
+}
+
+// ReadAllInboundMessageRecords retrieves the records of the inbound cross-chain messages of every sender.
+func ReadAllInboundMessageRecords(db ethdb.Iteratee) ([]*common.InboundMessageRecord, error) {
+	return readInboundMessageRecords(db, inboundMessagePrefix)
+}
+
+func readInboundMessageRecords(db ethdb.Iteratee, prefix []byte) ([]*common.InboundMessageRecord, error) {
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	records := make([]*common.InboundMessageRecord, 0)
+	for it.Next() {
+		record, err := decodeInboundMessageRecord(it.Value())
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("could not iterate over inbound message records. Cause: %w", err)
+	}
+	return records, nil
+}
+
+func decodeInboundMessageRecord(data []byte) (*common.InboundMessageRecord, error) {
+	record := new(common.InboundMessageRecord)
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, fmt.Errorf("could not decode inbound message record. Cause: %w", err)
+	}
+	return record, nil
+}
@@ -0,0 +1,40 @@
+package rawdb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/obscuronet/go-obscuro/go/common"
+)
+
+// WriteDivergenceReport stores a divergence report, replacing any earlier report for the same batch.
+func WriteDivergenceReport(db ethdb.KeyValueWriter, report *common.DivergenceReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("could not encode divergence report. Cause: %w", err)
+	}
+	if err = db.Put(divergenceReportKey(report.BatchNumber, report.BatchHash), data); err != nil {
+		return fmt.Errorf("could not store divergence report. Cause: %w", err)
+	}
+	return nil
+}
+
+// ReadDivergenceReports retrieves the stored divergence reports, in batch number order.
+func ReadDivergenceReports(db ethdb.Iteratee) ([]*common.DivergenceReport, error) {
+	it := db.NewIterator(divergenceReportPrefix, nil)
+	defer it.Release()
+
+	reports := make([]*common.DivergenceReport, 0)
+	for it.Next() {
+		report := new(common.DivergenceReport)
+		if err := json.Unmarshal(it.Value(), report); err != nil {
+			return nil, fmt.Errorf("could not decode divergence report. Cause: %w", err)
+		}
+		reports = append(reports, report)
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("could not iterate over divergence reports. Cause: %w", err)
+	}
+	return reports, nil
+}
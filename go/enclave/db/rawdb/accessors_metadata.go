@@ -1,11 +1,16 @@
 package rawdb
 
 import (
+	"encoding/json"
 	"fmt"
 
+	gethcommon "github.com/ethereum/go-ethereum/common"
+
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/obscuronet/go-obscuro/go/common"
 	"github.com/obscuronet/go-obscuro/go/common/errutil"
+	"github.com/obscuronet/go-obscuro/go/enclave/chainconfig"
 	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
 )
 
@@ -33,3 +38,82 @@ func WriteSharedSecret(db ethdb.KeyValueWriter, ss crypto.SharedEnclaveSecret) e
 	}
 	return nil
 }
+
+// ReadEpochSecrets returns the secrets of the epochs of the network secret held by the enclave, indexed by epoch. The
+// secret of epoch 0 is the shared secret.
+func ReadEpochSecrets(db ethdb.KeyValueReader) ([]crypto.SharedEnclaveSecret, error) {
+	secret, err := ReadSharedSecret(db)
+	if err != nil {
+		return nil, err
+	}
+	secrets := []crypto.SharedEnclaveSecret{*secret}
+	for epoch := uint64(1); ; epoch++ {
+		enc, err := db.Get(epochSecretKey(epoch))
+		if err != nil {
+			// The secrets are stored for consecutive epochs, so the first missing epoch follows the latest one.
+			return secrets, nil //nolint:nilerr
+		}
+		var ss crypto.SharedEnclaveSecret
+		if err = rlp.DecodeBytes(enc, &ss); err != nil {
+			return nil, fmt.Errorf("could not decode secret of epoch %d", epoch)
+		}
+		secrets = append(secrets, ss)
+	}
+}
+
+func WriteEpochSecret(db ethdb.KeyValueWriter, epoch uint64, ss crypto.SharedEnclaveSecret) error {
+	if epoch == 0 {
+		return WriteSharedSecret(db, ss)
+	}
+	enc, err := rlp.EncodeToBytes(ss)
+	if err != nil {
+		return fmt.Errorf("could not encode secret of epoch %d. Cause: %w", epoch, err)
+	}
+	if err = db.Put(epochSecretKey(epoch), enc); err != nil {
+		return fmt.Errorf("could not store secret of epoch %d in DB. Cause: %w", epoch, err)
+	}
+	return nil
+}
+
+// ReadEpochSecretL1Block returns the hash of the L1 block the secret of the epoch was published in. It is not found for
+// the secrets the enclave received from another enclave.
+func ReadEpochSecretL1Block(db ethdb.KeyValueReader, epoch uint64) (*common.L1RootHash, error) {
+	enc, err := db.Get(epochSecretL1BlockKey(epoch))
+	if err != nil {
+		return nil, errutil.ErrNotFound
+	}
+	l1Block := gethcommon.BytesToHash(enc)
+	return &l1Block, nil
+}
+
+func WriteEpochSecretL1Block(db ethdb.KeyValueWriter, epoch uint64, l1Block common.L1RootHash) error {
+	if err := db.Put(epochSecretL1BlockKey(epoch), l1Block.Bytes()); err != nil {
+		return fmt.Errorf("could not store L1 block of secret of epoch %d in DB. Cause: %w", epoch, err)
+	}
+	return nil
+}
+
+// ReadChainConfig returns the most recent version of the chain config applied by the enclave.
+func ReadChainConfig(db ethdb.KeyValueReader) (*chainconfig.ChainConfig, error) {
+	enc, err := db.Get(chainConfig)
+	if err != nil {
+		return nil, errutil.ErrNotFound
+	}
+	var cfg chainconfig.ChainConfig
+	if err = json.Unmarshal(enc, &cfg); err != nil {
+		return nil, fmt.Errorf("could not decode chain config. Cause: %w", err)
+	}
+	return &cfg, nil
+}
+
+func WriteChainConfig(db ethdb.KeyValueWriter, cfg *chainconfig.ChainConfig) error {
+	// The chain config is stored as JSON, since the EVM config holds optional integers that RLP cannot encode.
+	enc, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("could not encode chain config. Cause: %w", err)
+	}
+	if err = db.Put(chainConfig, enc); err != nil {
+		return fmt.Errorf("could not write chain config. Cause: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package rawdb
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/obscuronet/go-obscuro/go/common"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// StateCheckpoint is a batch whose state has been persisted, and is retained when older states are pruned.
+type StateCheckpoint struct {
+	Number uint64
+	Root   common.StateRoot
+}
+
+// WriteStateCheckpoint records that the state of the batch with the given number has been persisted.
+func WriteStateCheckpoint(db ethdb.KeyValueWriter, number uint64, root common.StateRoot) error {
+	if err := db.Put(stateCheckpointKey(number), root.Bytes()); err != nil {
+		return fmt.Errorf("could not store state checkpoint. Cause: %w", err)
+	}
+	return nil
+}
+
+// DeleteStateCheckpoint removes the record of the state checkpoint of the batch with the given number.
+func DeleteStateCheckpoint(db ethdb.KeyValueWriter, number uint64) error {
+	if err := db.Delete(stateCheckpointKey(number)); err != nil {
+		return fmt.Errorf("could not delete state checkpoint. Cause: %w", err)
+	}
+	return nil
+}
+
+// ReadStateCheckpoints retrieves the recorded state checkpoints, in batch number order.
+func ReadStateCheckpoints(db ethdb.Iteratee) ([]StateCheckpoint, error) {
+	it := db.NewIterator(stateCheckpointPrefix, nil)
+	defer it.Release()
+
+	var checkpoints []StateCheckpoint
+	for it.Next() {
+		if len(it.Key()) != len(stateCheckpointPrefix)+8 {
+			continue
+		}
+		checkpoints = append(checkpoints, StateCheckpoint{
+			Number: binary.BigEndian.Uint64(it.Key()[len(stateCheckpointPrefix):]),
+			Root:   gethcommon.BytesToHash(it.Value()),
+		})
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("could not iterate over state checkpoints. Cause: %w", err)
+	}
+	return checkpoints, nil
+}
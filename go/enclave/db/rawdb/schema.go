@@ -11,6 +11,10 @@ var (
 	sharedSecret  = []byte("SharedSecret")
 	headBatchHash = []byte("HeadBatch") // headBatchHashPrefix -> curr L2 head batch hash
 
+	chainConfig = []byte("ChainConfig") // chainConfig -> latest applied version of the chain config
+
+	inFlightRollup = []byte("InFlightRollup") // inFlightRollup -> number of the last rollup produced and its production time
+
 	attestationKeyPrefix           = []byte("oAK")  // attestationKeyPrefix + address -> key
 	syntheticTransactionsKeyPrefix = []byte("oSTX") // attestationKeyPrefix + address -> key
 
@@ -21,12 +25,21 @@ var (
 	rollupHeaderPrefix           = []byte("rh")  // rollupHeaderPrefix + num (uint64 big endian) + hash -> header
 	rollupBodyPrefix             = []byte("rb")  // rollupBodyPrefix + num (uint64 big endian) + hash -> batch body
 	rollupNumberPrefix           = []byte("rn")  // rollupNumberPrefix + hash -> num (uint64 big endian)
+	rollupForBatchPrefix         = []byte("rB")  // rollupForBatchPrefix + batch hash + rollup hash -> hash of a rollup containing the batch
+	rollupL1BlockPrefix          = []byte("rl")  // rollupL1BlockPrefix + rollup hash -> hash of the L1 block the rollup was published in
 	headBatchAfterL1BlockPrefix  = []byte("hb")  // headBatchAfterL1BlockPrefix + hash -> num (uint64 big endian)
 	headRollupAfterL1BlockPrefix = []byte("hr")  // headRollupAfterL1BlockPrefix + hash -> num (uint64 big endian)
 	logsPrefix                   = []byte("olg") // logsPrefix + hash -> block logs
 	batchReceiptsPrefix          = []byte("or")  // batchReceiptsPrefix + num (uint64 big endian) + hash -> batch receipts
 	contractReceiptPrefix        = []byte("ocr") // contractReceiptPrefix + address -> tx hash
 	txLookupPrefix               = []byte("ol")  // txLookupPrefix + hash -> transaction/receipt lookup metadata
+	inboundMessagePrefix         = []byte("oIM") // inboundMessagePrefix + sender + sequence (uint64 big endian) -> inbound message record
+	divergenceReportPrefix       = []byte("oDR") // divergenceReportPrefix + num (uint64 big endian) + hash -> divergence report
+	stateCheckpointPrefix        = []byte("oSC") // stateCheckpointPrefix + num (uint64 big endian) -> state root
+	epochSecretPrefix            = []byte("oES") // epochSecretPrefix + epoch (uint64 big endian) -> secret of the epoch
+	epochSecretL1BlockPrefix     = []byte("oEB") // epochSecretL1BlockPrefix + epoch (uint64 big endian) -> hash of the L1 block the secret was published in
+	revokedAttestationPrefix     = []byte("oRA") // revokedAttestationPrefix + address + L1 block hash -> revocation marker
+	attestationAllowListPrefix   = []byte("oAL") // attestationAllowListPrefix + L1 block hash -> allow-list published in the block
 )
 
 // encodeNumber encodes a number as big endian uint64
@@ -36,6 +49,26 @@ func encodeNumber(number uint64) []byte {
 	return enc
 }
 
+// For storing and fetching the records of a sender's inbound cross-chain messages, in sequence order.
+func inboundMessagesKey(sender gethcommon.Address) []byte {
+	return append(append([]byte{}, inboundMessagePrefix...), sender.Bytes()...)
+}
+
+// For storing and fetching an inbound cross-chain message record by sender and sequence.
+func inboundMessageKey(sender gethcommon.Address, sequence uint64) []byte {
+	return append(inboundMessagesKey(sender), encodeNumber(sequence)...)
+}
+
+// For storing and fetching the divergence report for a batch, in batch number order.
+func divergenceReportKey(number uint64, hash common.L2RootHash) []byte {
+	return append(append(append([]byte{}, divergenceReportPrefix...), encodeNumber(number)...), hash.Bytes()...)
+}
+
+// For storing and fetching the state checkpoint of a batch, in batch number order.
+func stateCheckpointKey(number uint64) []byte {
+	return append(append([]byte{}, stateCheckpointPrefix...), encodeNumber(number)...)
+}
+
 // For storing and fetching a batch header by batch hash.
 func batchHeaderKey(hash common.L2RootHash) []byte {
 	return append(batchHeaderPrefix, hash.Bytes()...)
@@ -89,6 +122,31 @@ func attestationPkKey(aggregator gethcommon.Address) []byte {
 	return append(attestationKeyPrefix, aggregator.Bytes()...)
 }
 
+// For fetching the revocations of an aggregator's attestation.
+func revokedAttestationsKey(aggregator gethcommon.Address) []byte {
+	return append(append([]byte{}, revokedAttestationPrefix...), aggregator.Bytes()...)
+}
+
+// For storing the revocation of an aggregator's attestation by the hash of the L1 block it was published in.
+func revokedAttestationKey(aggregator gethcommon.Address, l1Block common.L1RootHash) []byte {
+	return append(revokedAttestationsKey(aggregator), l1Block.Bytes()...)
+}
+
+// For storing and fetching the attestation allow-list published in an L1 block by block hash.
+func attestationAllowListKey(l1Block common.L1RootHash) []byte {
+	return append(append([]byte{}, attestationAllowListPrefix...), l1Block.Bytes()...)
+}
+
+// For storing and fetching the secret of an epoch after epoch 0, in epoch order.
+func epochSecretKey(epoch uint64) []byte {
+	return append(append([]byte{}, epochSecretPrefix...), encodeNumber(epoch)...)
+}
+
+// For storing and fetching the hash of the L1 block the secret of an epoch was published in.
+func epochSecretL1BlockKey(epoch uint64) []byte {
+	return append(append([]byte{}, epochSecretL1BlockPrefix...), encodeNumber(epoch)...)
+}
+
 func crossChainMessagesKey(blockHash common.L1RootHash) []byte {
 	return append(syntheticTransactionsKeyPrefix, blockHash.Bytes()...)
 }
@@ -107,3 +165,20 @@ func rollupBodyKey(hash common.L2RootHash) []byte {
 func rollupNumberKey(hash common.L2RootHash) []byte {
 	return append(rollupNumberPrefix, hash.Bytes()...)
 }
+
+// For fetching the hashes of the rollups containing a batch by batch hash. A batch is contained in several rollups if
+// it was rolled up again after an L1 fork. Before that was accounted for, a single rollup hash was stored under this
+// key itself, so it is found when iterating over the key as a prefix too.
+func rollupsForBatchKey(batchHash common.L2RootHash) []byte {
+	return append(append([]byte{}, rollupForBatchPrefix...), batchHash.Bytes()...)
+}
+
+// For storing the hash of a rollup containing a batch.
+func rollupForBatchKey(batchHash common.L2RootHash, rollupHash common.L2RootHash) []byte {
+	return append(rollupsForBatchKey(batchHash), rollupHash.Bytes()...)
+}
+
+// For storing and fetching the hash of the L1 block a rollup was published in by rollup hash.
+func rollupL1BlockKey(hash common.L2RootHash) []byte {
+	return append(rollupL1BlockPrefix, hash.Bytes()...)
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+	"github.com/obscuronet/go-obscuro/go/enclave/core"
+
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+func TestRollupForBatchFollowsTheCanonicalL1Chain(t *testing.T) {
+	storage := NewStorage(rawdb.NewMemoryDatabase(), params.AllEthashProtocolChanges, gethlog.New())
+
+	genesisBlock := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0)})
+	canonicalBlock := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), ParentHash: genesisBlock.Hash()})
+	forkBlock := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), ParentHash: genesisBlock.Hash(), Time: 1})
+	for _, block := range []*types.Block{genesisBlock, canonicalBlock, forkBlock} {
+		storage.StoreBlock(block)
+	}
+
+	batch := &core.Batch{Header: &common.BatchHeader{Number: big.NewInt(0), L1Proof: genesisBlock.Hash()}}
+	canonicalRollup := createRollup(canonicalBlock, batch)
+	forkRollup := createRollup(forkBlock, batch)
+
+	// The rollup on the orphaned fork is stored last, as happens when the batch is re-published after a reorg.
+	if err := storage.StoreRollup(canonicalRollup, canonicalBlock.Hash()); err != nil {
+		t.Fatalf("could not store rollup. Cause: %s", err)
+	}
+	if err := storage.StoreRollup(forkRollup, forkBlock.Hash()); err != nil {
+		t.Fatalf("could not store rollup. Cause: %s", err)
+	}
+
+	assertRollupForBatch(t, storage, canonicalBlock, batch, canonicalRollup)
+	assertRollupForBatch(t, storage, forkBlock, batch, forkRollup)
+
+	// A batch only rolled up on a fork has no rollup on the canonical chain.
+	orphanedBatch := &core.Batch{Header: &common.BatchHeader{Number: big.NewInt(1), L1Proof: genesisBlock.Hash()}}
+	if err := storage.StoreRollup(createRollup(forkBlock, orphanedBatch), forkBlock.Hash()); err != nil {
+		t.Fatalf("could not store rollup. Cause: %s", err)
+	}
+	if err := storage.UpdateL1Head(canonicalBlock.Hash()); err != nil {
+		t.Fatalf("could not update L1 head. Cause: %s", err)
+	}
+	if _, _, err := storage.FetchRollupForBatch(*orphanedBatch.Hash()); !errors.Is(err, errutil.ErrNotFound) {
+		t.Fatalf("expected rollup of batch only rolled up on a fork not to be found, got %v", err)
+	}
+}
+
+func TestRollupForBatchIsFoundUnderTheLegacyKey(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	storage := NewStorage(db, params.AllEthashProtocolChanges, gethlog.New())
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0)})
+	storage.StoreBlock(block)
+	batch := &core.Batch{Header: &common.BatchHeader{Number: big.NewInt(0), L1Proof: block.Hash()}}
+	rollup := createRollup(block, batch)
+	if err := storage.StoreRollup(rollup, block.Hash()); err != nil {
+		t.Fatalf("could not store rollup. Cause: %s", err)
+	}
+
+	// Nodes written before rollups were recorded per fork keyed the rollup by batch hash only.
+	legacyKey := append([]byte("rB"), batch.Hash().Bytes()...)
+	if err := db.Put(legacyKey, rollup.Hash().Bytes()); err != nil {
+		t.Fatalf("could not write legacy mapping. Cause: %s", err)
+	}
+	if err := db.Delete(append(append([]byte{}, legacyKey...), rollup.Hash().Bytes()...)); err != nil {
+		t.Fatalf("could not delete mapping. Cause: %s", err)
+	}
+
+	assertRollupForBatch(t, storage, block, batch, rollup)
+}
+
+func createRollup(l1Block *types.Block, batches ...*core.Batch) *core.Rollup {
+	return &core.Rollup{
+		Header:  &common.RollupHeader{Number: big.NewInt(0), L1Proof: l1Block.Hash()},
+		Batches: batches,
+	}
+}
+
+func assertRollupForBatch(t *testing.T, storage Storage, l1Head *types.Block, batch *core.Batch, expected *core.Rollup) {
+	t.Helper()
+	if err := storage.UpdateL1Head(l1Head.Hash()); err != nil {
+		t.Fatalf("could not update L1 head. Cause: %s", err)
+	}
+	rollup, l1BlockHash, err := storage.FetchRollupForBatch(*batch.Hash())
+	if err != nil {
+		t.Fatalf("could not fetch rollup for batch. Cause: %s", err)
+	}
+	if *rollup.Hash() != *expected.Hash() || *l1BlockHash != l1Head.Hash() {
+		t.Fatalf("expected rollup %s in L1 block %s, got rollup %s in L1 block %s",
+			expected.Hash(), l1Head.Hash(), rollup.Hash(), l1BlockHash)
+	}
+}
@@ -0,0 +1,442 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+	"github.com/obscuronet/go-obscuro/go/enclave/core"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	obscurorawdb "github.com/obscuronet/go-obscuro/go/enclave/db/rawdb"
+)
+
+var emptyCodeHash = crypto.Keccak256Hash(nil)
+
+// Snapshot - the data an enclave needs to resume processing the L1 from a given block, instead of replaying the L1
+// blocks that precede it. The state trie nodes and the contract code are stored under their hashes, so they are carried
+// without their keys and cannot be altered without breaking the state roots of the batches.
+type Snapshot struct {
+	L1Head     common.L1RootHash
+	HeadBatch  common.L2RootHash
+	StateNodes [][]byte
+	Code       [][]byte
+	Entries    []SnapshotEntry // The chain data, head pointers, cross-chain indexes, attested keys, revocations and allow-list.
+}
+
+// SnapshotEntry - a raw database entry included in a snapshot.
+type SnapshotEntry struct {
+	Key   []byte
+	Value []byte
+}
+
+// ExportSnapshot - the snapshot contains:
+//   - The L1 blocks from the given L1 head back to the L1 proof of the earliest batch it includes, with their
+//     cross-chain messages, so that the deposits of the next batches can be retrieved
+//   - The head batch for the L1 head and the batches back to the head batch of the latest rollup, with the state after
+//     each of them, since those batches are re-executed when the rollup containing them is published
+//   - The earlier canonical batches back to genesis, without their state, so that their transactions, receipts and
+//     withdrawals can still be looked up
+//   - The rollup containing each batch on the canonical L1 chain, with the L1 block it was published in, including the
+//     latest rollup, which the next rollup must be chained to
+//   - The audit records of the inbound cross-chain messages
+//   - The attested keys, which are needed to check the sequencer's signatures, and the attestations revoked on the L1
+//     chain ending in the L1 head
+//   - The attestation allow-list in force at the L1 head, which may have been published on an L1 block that is not
+//     replayed
+func (s *storageImpl) ExportSnapshot(l1Head common.L1RootHash) (*Snapshot, error) {
+	headBatch, err := s.FetchHeadBatchForBlock(l1Head)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{L1Head: l1Head, HeadBatch: *headBatch.Hash()}
+	// We write the entries using the same methods as when they are first stored, to capture their exact layout.
+	entries := rawdb.NewMemoryDatabase()
+	snapshotStorage := &storageImpl{db: entries, chainConfig: s.chainConfig, logger: s.logger}
+
+	latestRollupHash := gethcommon.Hash{}
+	latestRollup, err := s.latestRollupAtBlock(l1Head)
+	if err != nil && !errors.Is(err, ErrNoRollups) {
+		return nil, err
+	}
+	if latestRollup != nil {
+		latestRollupHash = *latestRollup.Hash()
+		rollupBlock, err := obscurorawdb.ReadRollupL1Block(s.db, latestRollupHash)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve L1 block of latest rollup. Cause: %w", err)
+		}
+		if err = snapshotStorage.StoreRollup(latestRollup, *rollupBlock); err != nil {
+			return nil, err
+		}
+	}
+
+	l1HeadBlock, err := s.FetchBlock(l1Head)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve L1 head. Cause: %w", err)
+	}
+	exportedRollups := map[gethcommon.Hash]bool{latestRollupHash: true}
+	earliestL1Proof := headBatch.Header.L1Proof
+	withState := true
+	visited := map[gethcommon.Hash]bool{}
+	for batch := headBatch; ; {
+		receipts, err := s.GetReceiptsByHash(*batch.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve receipts for batch %d. Cause: %w", batch.NumberU64(), err)
+		}
+		if err = snapshotStorage.StoreBatch(batch, receipts); err != nil {
+			return nil, err
+		}
+		if err = obscurorawdb.WriteCanonicalHash(entries, batch); err != nil {
+			return nil, err
+		}
+		if err = s.exportRollupForBatch(batch, l1HeadBlock, snapshotStorage, exportedRollups); err != nil {
+			return nil, err
+		}
+		if withState {
+			if err = s.visitState(batch.Header.Root, visited, snapshot); err != nil {
+				return nil, fmt.Errorf("could not export state of batch %d. Cause: %w", batch.NumberU64(), err)
+			}
+			earliestL1Proof = batch.Header.L1Proof
+			// The state of the batches that were rolled up before the latest rollup is not needed.
+			withState = latestRollup == nil || *batch.Hash() != latestRollup.Header.HeadBatchHash
+		}
+
+		if batch.IsGenesis() {
+			break
+		}
+		if batch, err = s.FetchBatch(batch.Header.ParentHash); err != nil {
+			return nil, fmt.Errorf("could not retrieve parent batch. Cause: %w", err)
+		}
+	}
+
+	for blockHash := l1Head; ; {
+		block, err := s.FetchBlock(blockHash)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve L1 block %s. Cause: %w", blockHash, err)
+		}
+		snapshotStorage.StoreBlock(block)
+		messages, err := s.GetL1Messages(blockHash)
+		if err != nil {
+			return nil, err
+		}
+		if len(messages) != 0 {
+			if err = snapshotStorage.StoreL1Messages(blockHash, messages); err != nil {
+				return nil, err
+			}
+		}
+		if blockHash == earliestL1Proof {
+			break
+		}
+		blockHash = block.ParentHash()
+	}
+
+	headReceipts, err := s.GetReceiptsByHash(*headBatch.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve receipts for head batch. Cause: %w", err)
+	}
+	if err = snapshotStorage.UpdateHeadBatch(l1Head, headBatch, headReceipts); err != nil {
+		return nil, err
+	}
+	if err = snapshotStorage.UpdateHeadRollup(&l1Head, &latestRollupHash); err != nil {
+		return nil, err
+	}
+	if err = snapshotStorage.UpdateL1Head(l1Head); err != nil {
+		return nil, err
+	}
+
+	attestedKeys, err := obscurorawdb.ReadAttestationKeys(s.db)
+	if err != nil {
+		return nil, err
+	}
+	for aggregator, key := range attestedKeys {
+		if err = snapshotStorage.StoreAttestedKey(aggregator, key); err != nil {
+			return nil, err
+		}
+	}
+	// The revocations in force at the L1 head are exported as if they were published in the earliest L1 block of the
+	// snapshot, since the importing enclave cannot follow the L1 chain any further back.
+	revoked, err := obscurorawdb.ReadRevokedAttestations(s.db)
+	if err != nil {
+		return nil, err
+	}
+	for aggregator := range revoked {
+		isRevoked, err := s.IsAttestationRevoked(aggregator, l1Head)
+		if err != nil {
+			return nil, err
+		}
+		if !isRevoked {
+			continue
+		}
+		if err = snapshotStorage.StoreAttestationRevocation(aggregator, earliestL1Proof); err != nil {
+			return nil, err
+		}
+	}
+	inboundMessageRecords, err := obscurorawdb.ReadAllInboundMessageRecords(s.db)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range inboundMessageRecords {
+		if err = snapshotStorage.StoreInboundMessageRecord(record); err != nil {
+			return nil, err
+		}
+	}
+	// Likewise for the allow-list in force at the L1 head.
+	allowList, err := s.FetchAttestationAllowList(l1Head)
+	if err != nil && !errors.Is(err, errutil.ErrNotFound) {
+		return nil, err
+	}
+	if allowList != nil {
+		if err = snapshotStorage.StoreAttestationAllowList(earliestL1Proof, allowList); err != nil {
+			return nil, err
+		}
+	}
+
+	it := entries.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		snapshot.Entries = append(snapshot.Entries, SnapshotEntry{
+			Key:   gethcommon.CopyBytes(it.Key()),
+			Value: gethcommon.CopyBytes(it.Value()),
+		})
+	}
+	return snapshot, it.Error()
+}
+
+// Adds the rollup containing the batch on the L1 chain ending in the given L1 head to the snapshot, with the L1 block it
+// was published in, unless the rollup has already been added.
+func (s *storageImpl) exportRollupForBatch(batch *core.Batch, l1Head *types.Block, snapshotStorage *storageImpl, exported map[gethcommon.Hash]bool) error {
+	rollup, l1BlockHash, err := s.rollupForBatchAt(*batch.Hash(), l1Head)
+	if err != nil {
+		if errors.Is(err, errutil.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("could not retrieve rollup for batch %d. Cause: %w", batch.NumberU64(), err)
+	}
+	if exported[*rollup.Hash()] {
+		return nil
+	}
+	exported[*rollup.Hash()] = true
+	l1Block, err := s.FetchBlock(*l1BlockHash)
+	if err != nil {
+		return fmt.Errorf("could not retrieve L1 block of rollup. Cause: %w", err)
+	}
+	snapshotStorage.StoreBlock(l1Block)
+	return snapshotStorage.StoreRollup(rollup, *l1BlockHash)
+}
+
+// ImportSnapshot - the snapshot is checked in a staging database before anything is written, so that a snapshot that
+// is inconsistent or does not contain the complete state of its batches leaves the enclave untouched.
+func (s *storageImpl) ImportSnapshot(snapshot *Snapshot) error {
+	if _, err := s.FetchHeadBatch(); err == nil {
+		return errors.New("cannot import a snapshot into an enclave that already has a head batch")
+	} else if !errors.Is(err, errutil.ErrNotFound) {
+		return fmt.Errorf("could not retrieve head batch. Cause: %w", err)
+	}
+
+	staging := rawdb.NewMemoryDatabase()
+	for _, node := range snapshot.StateNodes {
+		rawdb.WriteTrieNode(staging, crypto.Keccak256Hash(node), node)
+	}
+	for _, code := range snapshot.Code {
+		rawdb.WriteCode(staging, crypto.Keccak256Hash(code), code)
+	}
+	for _, entry := range snapshot.Entries {
+		if err := staging.Put(entry.Key, entry.Value); err != nil {
+			return fmt.Errorf("could not stage snapshot entry. Cause: %w", err)
+		}
+	}
+	stagingStorage := &storageImpl{
+		db: staging, stateDB: state.NewDatabase(staging), stateRetainer: newStateRetainer(StateRetention{}), chainConfig: s.chainConfig, logger: s.logger,
+	}
+	if err := stagingStorage.checkSnapshot(snapshot); err != nil {
+		return fmt.Errorf("snapshot was invalid. Cause: %w", err)
+	}
+	// The state of the head batch is persisted, so it is retained as a checkpoint if older states are pruned.
+	headBatch, err := stagingStorage.FetchHeadBatch()
+	if err != nil {
+		return fmt.Errorf("could not retrieve snapshot head batch. Cause: %w", err)
+	}
+	if err = obscurorawdb.WriteStateCheckpoint(staging, headBatch.NumberU64(), headBatch.Header.Root); err != nil {
+		return err
+	}
+
+	dbBatch := s.db.NewBatch()
+	it := staging.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		if err := dbBatch.Put(it.Key(), it.Value()); err != nil {
+			return fmt.Errorf("could not write snapshot entry. Cause: %w", err)
+		}
+		if dbBatch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := dbBatch.Write(); err != nil {
+				return fmt.Errorf("could not write snapshot to storage. Cause: %w", err)
+			}
+			dbBatch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return fmt.Errorf("could not iterate over snapshot entries. Cause: %w", err)
+	}
+	if err := dbBatch.Write(); err != nil {
+		return fmt.Errorf("could not write snapshot to storage. Cause: %w", err)
+	}
+	return nil
+}
+
+// Checks that the head pointers match the snapshot, that the batches match their hashes, and that the complete state
+// of each batch since the latest rollup is present under the state root in its header.
+func (s *storageImpl) checkSnapshot(snapshot *Snapshot) error {
+	l1Head, err := s.FetchHeadBlock()
+	if err != nil {
+		return fmt.Errorf("could not retrieve L1 head. Cause: %w", err)
+	}
+	if l1Head.Hash() != snapshot.L1Head {
+		return fmt.Errorf("L1 head %s did not match snapshot L1 head %s", l1Head.Hash(), snapshot.L1Head)
+	}
+	headBatch, err := s.FetchHeadBatch()
+	if err != nil {
+		return fmt.Errorf("could not retrieve head batch. Cause: %w", err)
+	}
+	headBatchForBlock, err := s.FetchHeadBatchForBlock(snapshot.L1Head)
+	if err != nil {
+		return err
+	}
+	if *headBatch.Hash() != snapshot.HeadBatch || *headBatchForBlock.Hash() != snapshot.HeadBatch {
+		return fmt.Errorf("head batch did not match snapshot head batch %s", snapshot.HeadBatch)
+	}
+
+	// The snapshot only carries the state of the batches back to the head batch of the latest rollup.
+	stateUntil := gethcommon.Hash{}
+	latestRollup, err := s.FetchHeadRollupForBlock(&snapshot.L1Head)
+	if err != nil && !errors.Is(err, ErrNoRollups) {
+		return fmt.Errorf("could not retrieve latest rollup. Cause: %w", err)
+	}
+	if latestRollup != nil {
+		stateUntil = latestRollup.Header.HeadBatchHash
+	}
+
+	withState := true
+	visited := map[gethcommon.Hash]bool{}
+	for batch := headBatch; ; {
+		if withState {
+			if err = s.visitState(batch.Header.Root, visited, nil); err != nil {
+				return fmt.Errorf("state of batch %d did not match its state root %s. Cause: %w", batch.NumberU64(), batch.Header.Root, err)
+			}
+			withState = *batch.Hash() != stateUntil
+		}
+		if batch.IsGenesis() {
+			return nil
+		}
+		parentHash := batch.Header.ParentHash
+		if _, err = obscurorawdb.ReadBatchNumber(s.db, parentHash); errors.Is(err, errutil.ErrNotFound) {
+			// We have reached the earliest batch in the snapshot.
+			return nil
+		}
+		if batch, err = s.FetchBatch(parentHash); err != nil {
+			return fmt.Errorf("could not retrieve batch %s. Cause: %w", parentHash, err)
+		}
+		if *batch.Hash() != parentHash {
+			return fmt.Errorf("batch stored under hash %s had hash %s", parentHash, batch.Hash())
+		}
+	}
+}
+
+// Visits the trie nodes and contract code of the state with the given root, skipping the subtries that have already
+// been visited, and adds them to the snapshot if there is one. Returns an error if any of them are missing.
+func (s *storageImpl) visitState(root gethcommon.Hash, visited map[gethcommon.Hash]bool, snapshot *Snapshot) error {
+	accountTrie, err := s.stateDB.OpenTrie(root)
+	if err != nil {
+		return fmt.Errorf("could not open state trie. Cause: %w", err)
+	}
+	return s.visitTrie(accountTrie, visited, snapshot, s.visitAccount)
+}
+
+// Visits the storage trie and the code of the account in the given leaf of the account trie.
+func (s *storageImpl) visitAccount(leaf []byte, visited map[gethcommon.Hash]bool, snapshot *Snapshot) error {
+	var account types.StateAccount
+	if err := rlp.DecodeBytes(leaf, &account); err != nil {
+		return fmt.Errorf("could not decode account. Cause: %w", err)
+	}
+
+	if account.Root != types.EmptyRootHash {
+		storageTrie, err := s.stateDB.OpenStorageTrie(gethcommon.Hash{}, account.Root)
+		if err != nil {
+			return fmt.Errorf("could not open storage trie. Cause: %w", err)
+		}
+		if err = s.visitTrie(storageTrie, visited, snapshot, nil); err != nil {
+			return err
+		}
+	}
+
+	codeHash := gethcommon.BytesToHash(account.CodeHash)
+	if codeHash == emptyCodeHash || visited[codeHash] {
+		return nil
+	}
+	visited[codeHash] = true
+	code, err := s.stateDB.ContractCode(gethcommon.Hash{}, codeHash)
+	if err != nil {
+		return fmt.Errorf("could not retrieve contract code %s. Cause: %w", codeHash, err)
+	}
+	if snapshot != nil {
+		snapshot.Code = append(snapshot.Code, code)
+	}
+	return nil
+}
+
+func (s *storageImpl) visitTrie(
+	tr state.Trie,
+	visited map[gethcommon.Hash]bool,
+	snapshot *Snapshot,
+	visitLeaf func([]byte, map[gethcommon.Hash]bool, *Snapshot) error,
+) error {
+	it := tr.NodeIterator(nil)
+	for descend := true; it.Next(descend); {
+		descend = true
+		if hash := it.Hash(); hash != (gethcommon.Hash{}) {
+			// Identical subtries are shared between the states of consecutive batches, so we only visit them once.
+			if visited[hash] {
+				descend = false
+				continue
+			}
+			visited[hash] = true
+			if snapshot != nil {
+				node, err := s.stateDB.TrieDB().Node(hash)
+				if err != nil {
+					return fmt.Errorf("could not retrieve trie node %s. Cause: %w", hash, err)
+				}
+				snapshot.StateNodes = append(snapshot.StateNodes, node)
+			}
+		}
+		if it.Leaf() && visitLeaf != nil {
+			if err := visitLeaf(it.LeafBlob(), visited, snapshot); err != nil {
+				return err
+			}
+		}
+	}
+	return it.Error()
+}
+
+// Returns the latest rollup published in the given L1 block or its ancestors.
+func (s *storageImpl) latestRollupAtBlock(blockHash common.L1RootHash) (*core.Rollup, error) {
+	for {
+		rollup, err := s.FetchHeadRollupForBlock(&blockHash)
+		if err == nil || !errors.Is(err, errutil.ErrNotFound) {
+			return rollup, err
+		}
+		block, err := s.FetchBlock(blockHash)
+		if err != nil {
+			if errors.Is(err, errutil.ErrNotFound) {
+				return nil, ErrNoRollups
+			}
+			return nil, fmt.Errorf("could not retrieve L1 block. Cause: %w", err)
+		}
+		blockHash = block.ParentHash()
+	}
+}
@@ -0,0 +1,239 @@
+package db
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+	"github.com/obscuronet/go-obscuro/go/enclave/core"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	snapshotAccount = gethcommon.HexToAddress("0x01")
+	snapshotSlot    = gethcommon.HexToHash("0x02")
+	snapshotCode    = []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+)
+
+func TestSnapshotResumesFromHeadBatch(t *testing.T) {
+	source, headBatch, l1Head, aggregator := createSnapshotSource(t)
+	snapshot, err := source.ExportSnapshot(l1Head.Hash())
+	if err != nil {
+		t.Fatalf("could not export snapshot. Cause: %s", err)
+	}
+
+	importer := NewStorage(rawdb.NewMemoryDatabase(), params.AllEthashProtocolChanges, gethlog.New())
+	if err = importer.ImportSnapshot(snapshot); err != nil {
+		t.Fatalf("could not import snapshot. Cause: %s", err)
+	}
+
+	importedHead, err := importer.FetchHeadBatch()
+	if err != nil || *importedHead.Hash() != *headBatch.Hash() {
+		t.Fatal("imported head batch did not match the exported head batch")
+	}
+	importedL1Head, err := importer.FetchHeadBlock()
+	if err != nil || importedL1Head.Hash() != l1Head.Hash() {
+		t.Fatal("imported L1 head did not match the exported L1 head")
+	}
+	if _, err = importer.FetchHeadBatchForBlock(l1Head.Hash()); err != nil {
+		t.Fatalf("could not retrieve head batch for imported L1 head. Cause: %s", err)
+	}
+	if _, err = importer.FetchAttestedKey(aggregator); err != nil {
+		t.Fatalf("could not retrieve imported attested key. Cause: %s", err)
+	}
+
+	stateDB, err := importer.CreateStateDB(*importedHead.Hash())
+	if err != nil {
+		t.Fatalf("could not create state DB for imported head batch. Cause: %s", err)
+	}
+	if stateDB.GetBalance(snapshotAccount).Cmp(big.NewInt(2)) != 0 ||
+		stateDB.GetState(snapshotAccount, snapshotSlot) != gethcommon.BigToHash(big.NewInt(2)) ||
+		string(stateDB.GetCode(snapshotAccount)) != string(snapshotCode) {
+		t.Fatal("imported state did not match the exported state")
+	}
+
+	if err = importer.ImportSnapshot(snapshot); err == nil {
+		t.Fatal("expected a snapshot to be refused by an enclave with a head batch")
+	}
+}
+
+func TestSnapshotWithIncompleteStateIsRefused(t *testing.T) {
+	source, _, l1Head, _ := createSnapshotSource(t)
+	snapshot, err := source.ExportSnapshot(l1Head.Hash())
+	if err != nil {
+		t.Fatalf("could not export snapshot. Cause: %s", err)
+	}
+	// Dropping any trie node means the state can no longer be rebuilt under the state root in the batch header.
+	snapshot.StateNodes = snapshot.StateNodes[1:]
+
+	importer := NewStorage(rawdb.NewMemoryDatabase(), params.AllEthashProtocolChanges, gethlog.New())
+	if err = importer.ImportSnapshot(snapshot); err == nil {
+		t.Fatal("expected a snapshot with incomplete state to be refused")
+	}
+	if _, err = importer.FetchHeadBatch(); !errors.Is(err, errutil.ErrNotFound) {
+		t.Fatal("expected a refused snapshot to leave the storage untouched")
+	}
+}
+
+func TestSnapshotCarriesTheHistoryBeforeTheLatestRollup(t *testing.T) {
+	storage := NewStorage(rawdb.NewMemoryDatabase(), params.AllEthashProtocolChanges, gethlog.New())
+
+	// The genesis batch is rolled up in block 1 and the second batch in block 4, so the snapshot only carries the state
+	// of the last two batches and the L1 blocks from block 3.
+	var blocks []*types.Block
+	parentBlockHash := gethcommon.Hash{}
+	for number := 0; number < 5; number++ {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(number)), ParentHash: parentBlockHash})
+		storage.StoreBlock(block)
+		blocks = append(blocks, block)
+		parentBlockHash = block.Hash()
+	}
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1})
+	batches := storeSnapshotBatches(t, storage, []*types.Block{blocks[0], blocks[3], blocks[4]}, tx)
+
+	earlierRollup := createRollup(blocks[1], batches[0])
+	earlierRollup.Header.HeadBatchHash = *batches[0].Hash()
+	latestRollup := createRollup(blocks[4], batches[1])
+	latestRollup.Header.Number = big.NewInt(1)
+	latestRollup.Header.HeadBatchHash = *batches[1].Hash()
+	for _, rollup := range []*core.Rollup{earlierRollup, latestRollup} {
+		l1BlockHash := rollup.Header.L1Proof
+		if err := storage.StoreRollup(rollup, l1BlockHash); err != nil {
+			t.Fatalf("could not store rollup. Cause: %s", err)
+		}
+		if err := storage.UpdateHeadRollup(&l1BlockHash, rollup.Hash()); err != nil {
+			t.Fatalf("could not update head rollup. Cause: %s", err)
+		}
+	}
+	record := &common.InboundMessageRecord{Sender: snapshotAccount, Sequence: 1, Status: common.InboundMessageDelivered}
+	if err := storage.StoreInboundMessageRecord(record); err != nil {
+		t.Fatalf("could not store inbound message record. Cause: %s", err)
+	}
+
+	snapshot, err := storage.ExportSnapshot(blocks[4].Hash())
+	if err != nil {
+		t.Fatalf("could not export snapshot. Cause: %s", err)
+	}
+	importer := NewStorage(rawdb.NewMemoryDatabase(), params.AllEthashProtocolChanges, gethlog.New())
+	if err = importer.ImportSnapshot(snapshot); err != nil {
+		t.Fatalf("could not import snapshot. Cause: %s", err)
+	}
+
+	if _, err = importer.FetchBlock(blocks[2].Hash()); !errors.Is(err, errutil.ErrNotFound) {
+		t.Fatal("expected the L1 blocks preceding the snapshot not to be imported")
+	}
+	if _, err = importer.CreateStateDB(*batches[0].Hash()); err == nil {
+		t.Fatal("expected the state of the batches preceding the latest rollup not to be imported")
+	}
+	if _, batchHash, _, _, err := importer.GetTransaction(tx.Hash()); err != nil || batchHash != *batches[0].Hash() {
+		t.Fatalf("expected transaction of the genesis batch to be found, got error %v", err)
+	}
+	rollup, _, err := importer.FetchRollupForBatch(*batches[0].Hash())
+	if err != nil || *rollup.Hash() != *earlierRollup.Hash() {
+		t.Fatalf("expected rollup of the genesis batch to be found, got error %v", err)
+	}
+	if _, err = importer.GetInboundMessageRecord(record.Sender, record.Sequence); err != nil {
+		t.Fatalf("could not retrieve imported inbound message record. Cause: %s", err)
+	}
+}
+
+// Stores a batch with the given L1 proof for each of the given L1 blocks, each the head batch after its L1 proof. The
+// genesis batch contains the given transaction.
+func storeSnapshotBatches(t *testing.T, storage Storage, l1Proofs []*types.Block, genesisTx *types.Transaction) []*core.Batch {
+	stateDB, err := storage.EmptyStateDB()
+	if err != nil {
+		t.Fatalf("could not create state DB. Cause: %s", err)
+	}
+	var batches []*core.Batch
+	var parentHash common.L2RootHash
+	for number, block := range l1Proofs {
+		stateDB.SetBalance(snapshotAccount, big.NewInt(int64(number+1)))
+		root, err := stateDB.Commit(true)
+		if err != nil {
+			t.Fatalf("could not commit state. Cause: %s", err)
+		}
+		batch := &core.Batch{Header: &common.BatchHeader{
+			ParentHash: parentHash,
+			Number:     big.NewInt(int64(number)),
+			Root:       root,
+			L1Proof:    block.Hash(),
+		}}
+		var receipts []*types.Receipt
+		if number == 0 {
+			batch.Transactions = []*common.L2Tx{genesisTx}
+			receipts = []*types.Receipt{{TxHash: genesisTx.Hash(), Status: types.ReceiptStatusSuccessful}}
+		}
+		if err = storage.StoreBatch(batch, receipts); err != nil {
+			t.Fatalf("could not store batch. Cause: %s", err)
+		}
+		if err = storage.UpdateHeadBatch(block.Hash(), batch, nil); err != nil {
+			t.Fatalf("could not update head batch. Cause: %s", err)
+		}
+		batches = append(batches, batch)
+		parentHash = *batch.Hash()
+	}
+	if err = storage.UpdateL1Head(l1Proofs[len(l1Proofs)-1].Hash()); err != nil {
+		t.Fatalf("could not update L1 head. Cause: %s", err)
+	}
+	return batches
+}
+
+// Creates a storage with a genesis batch and a second batch on top of it, each the head batch after an L1 block.
+func createSnapshotSource(t *testing.T) (Storage, *core.Batch, *types.Block, gethcommon.Address) {
+	storage := NewStorage(rawdb.NewMemoryDatabase(), params.AllEthashProtocolChanges, gethlog.New())
+
+	genesisBlock := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0)})
+	l1Head := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), ParentHash: genesisBlock.Hash()})
+
+	stateDB, err := storage.EmptyStateDB()
+	if err != nil {
+		t.Fatalf("could not create state DB. Cause: %s", err)
+	}
+	var parentHash common.L2RootHash
+	var batch *core.Batch
+	for number, block := range []*types.Block{genesisBlock, l1Head} {
+		stateDB.SetBalance(snapshotAccount, big.NewInt(int64(number+1)))
+		stateDB.SetState(snapshotAccount, snapshotSlot, gethcommon.BigToHash(big.NewInt(int64(number+1))))
+		stateDB.SetCode(snapshotAccount, snapshotCode)
+		root, err := stateDB.Commit(true)
+		if err != nil {
+			t.Fatalf("could not commit state. Cause: %s", err)
+		}
+
+		batch = &core.Batch{Header: &common.BatchHeader{
+			ParentHash: parentHash,
+			Number:     big.NewInt(int64(number)),
+			Root:       root,
+			L1Proof:    block.Hash(),
+		}}
+		storage.StoreBlock(block)
+		if err = storage.StoreBatch(batch, nil); err != nil {
+			t.Fatalf("could not store batch. Cause: %s", err)
+		}
+		if err = storage.UpdateHeadBatch(block.Hash(), batch, nil); err != nil {
+			t.Fatalf("could not update head batch. Cause: %s", err)
+		}
+		parentHash = *batch.Hash()
+	}
+	if err = storage.UpdateL1Head(l1Head.Hash()); err != nil {
+		t.Fatalf("could not update L1 head. Cause: %s", err)
+	}
+
+	aggregatorKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("could not generate key. Cause: %s", err)
+	}
+	aggregator := crypto.PubkeyToAddress(aggregatorKey.PublicKey)
+	if err = storage.StoreAttestedKey(aggregator, &aggregatorKey.PublicKey); err != nil {
+		t.Fatalf("could not store attested key. Cause: %s", err)
+	}
+	return storage, batch, l1Head, aggregator
+}
@@ -0,0 +1,334 @@
+package db
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/prque"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+	"github.com/obscuronet/go-obscuro/go/common/log"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	obscurorawdb "github.com/obscuronet/go-obscuro/go/enclave/db/rawdb"
+)
+
+// ErrStateNotRetained is returned when the state of a batch has been pruned, because the batch is older than the state
+// retention window and is not a retained checkpoint.
+var ErrStateNotRetained = errors.New("state of batch is outside the retention window")
+
+// StateRetention configures which batch states the storage retains. The zero value retains the state of every batch in
+// memory, and never persists or prunes any of them. The state of the batches since the latest rollup is always retained,
+// since they are re-executed when their rollup is published and are carried by snapshots.
+type StateRetention struct {
+	RecentBatches      uint64 // The number of most recent batches whose state is retained in memory. Zero retains all of them.
+	CheckpointInterval uint64 // The state of every batch whose number is a multiple of this is persisted. Zero disables checkpoints.
+	Checkpoints        uint64 // The number of most recent checkpoints that are retained. Zero retains all of them.
+	TrieCacheSizeMB    int    // The size of the in-memory cache of trie nodes read from the database.
+}
+
+func (r StateRetention) enabled() bool {
+	return r.RecentBatches != 0 || r.CheckpointInterval != 0
+}
+
+// stateRetainer tracks the batch states held in the trie database's memory, so that the trie nodes only reachable from
+// states outside the retention window can be garbage collected.
+type stateRetainer struct {
+	config      StateRetention
+	triegc      *prque.Prque            // The state roots held in memory, by negated batch number.
+	recentRoots map[gethcommon.Hash]int // The number of times each of the state roots in `triegc` is held.
+	latest      uint64                  // The highest batch number whose state has been committed.
+	mutex       sync.Mutex
+
+	pruning     bool           // Whether the old checkpoints are being pruned in the background.
+	pruneAgain  bool           // Whether a checkpoint was persisted during the pruning, so that it must run again.
+	pruningDone sync.WaitGroup // Done when the background pruning completes.
+}
+
+func newStateRetainer(config StateRetention) *stateRetainer {
+	return &stateRetainer{
+		config:      config,
+		triegc:      prque.New(nil),
+		recentRoots: map[gethcommon.Hash]int{},
+	}
+}
+
+// Indicates whether the state of the batch with the given number would have been pruned.
+func (r *stateRetainer) outsideWindow(number uint64) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.config.RecentBatches != 0 && number+r.config.RecentBatches <= r.latest
+}
+
+// Resumes the retention window from the head batch after a restart, so that the pruned states are reported as such.
+func (s *storageImpl) resumeStateRetention() {
+	headBatchHash, err := obscurorawdb.ReadL2HeadBatch(s.db)
+	if err != nil {
+		return
+	}
+	headBatchNumber, err := obscurorawdb.ReadBatchNumber(s.db, *headBatchHash)
+	if err != nil {
+		return
+	}
+	s.stateRetainer.latest = *headBatchNumber
+}
+
+func (s *storageImpl) CommitStateDB(stateDB *state.StateDB, batchNumber uint64) (common.StateRoot, error) {
+	root, err := stateDB.Commit(true)
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("could not commit state DB. Cause: %w", err)
+	}
+	if err = s.retainState(root, batchNumber); err != nil {
+		return gethcommon.Hash{}, err
+	}
+	return root, nil
+}
+
+// Holds the committed state of a batch in memory, persists it if it is a checkpoint, and releases the states that have
+// fallen out of the retention window. The trie database garbage collects the nodes that are no longer referenced.
+func (s *storageImpl) retainState(root common.StateRoot, number uint64) error {
+	r := s.stateRetainer
+	if !r.config.enabled() {
+		return nil
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	trieDB := s.stateDB.TrieDB()
+	trieDB.Reference(root, gethcommon.Hash{})
+	r.triegc.Push(root, -int64(number))
+	r.recentRoots[root]++
+	if number > r.latest {
+		r.latest = number
+	}
+
+	if r.config.CheckpointInterval != 0 && number%r.config.CheckpointInterval == 0 {
+		if err := trieDB.Commit(root, false, nil); err != nil {
+			return fmt.Errorf("could not persist state checkpoint for batch %d. Cause: %w", number, err)
+		}
+		if err := obscurorawdb.WriteStateCheckpoint(s.db, number, root); err != nil {
+			return err
+		}
+		if r.config.Checkpoints != 0 {
+			s.requestPruning()
+		}
+	}
+
+	if r.config.RecentBatches == 0 || r.latest < r.config.RecentBatches {
+		return nil
+	}
+	oldest := r.latest - r.config.RecentBatches
+	rolledUp, err := s.latestRolledUpBatch()
+	if errors.Is(err, errutil.ErrNotFound) {
+		// No batch has been rolled up yet, so every state may be needed to re-execute the batches of the next rollup.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not determine which states the next rollup needs. Cause: %w", err)
+	}
+	if rolledUp == 0 {
+		return nil
+	}
+	if rolledUp-1 < oldest {
+		oldest = rolledUp - 1
+	}
+	for !r.triegc.Empty() {
+		retained, negNumber := r.triegc.Pop()
+		if uint64(-negNumber) > oldest {
+			r.triegc.Push(retained, negNumber)
+			break
+		}
+		retainedRoot := retained.(gethcommon.Hash)
+		trieDB.Dereference(retainedRoot)
+		if r.recentRoots[retainedRoot]--; r.recentRoots[retainedRoot] == 0 {
+			delete(r.recentRoots, retainedRoot)
+		}
+	}
+	return nil
+}
+
+// Returns the number of the head batch of the latest rollup on the L1 chain ending in the L1 head, or
+// errutil.ErrNotFound if no rollup has been published on that chain yet.
+func (s *storageImpl) latestRolledUpBatch() (uint64, error) {
+	l1Head := rawdb.ReadHeadHeaderHash(s.db)
+	rollup, err := s.FetchHeadRollupForBlock(&l1Head)
+	if errors.Is(err, ErrNoRollups) {
+		return 0, errutil.ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	number, err := obscurorawdb.ReadBatchNumber(s.db, rollup.Header.HeadBatchHash)
+	if err != nil {
+		return 0, err
+	}
+	return *number, nil
+}
+
+// Starts pruning the old checkpoints in the background, or has the running pruning go again once it completes. Must be
+// called with the retainer's mutex held.
+func (s *storageImpl) requestPruning() {
+	r := s.stateRetainer
+	if r.pruning {
+		r.pruneAgain = true
+		return
+	}
+	r.pruning = true
+	r.pruningDone.Add(1)
+	go s.pruneCheckpoints()
+}
+
+// Deletes the oldest checkpoints beyond the configured number, then deletes the persisted trie nodes that are no
+// longer reachable from the remaining checkpoints or from the states held in memory, whose nodes may be persisted as
+// part of an older checkpoint. Runs in the background, only holding the retainer's mutex while deleting.
+func (s *storageImpl) pruneCheckpoints() {
+	r := s.stateRetainer
+	defer r.pruningDone.Done()
+	for {
+		start := time.Now()
+		pruned, deleted, err := s.pruneCheckpointsAndSweep()
+		if err != nil {
+			s.logger.Error("Could not prune state checkpoints.", log.ErrKey, err)
+		} else if pruned > 0 {
+			s.logger.Info(fmt.Sprintf("Pruned %d state checkpoints, deleting %d unreachable trie nodes.", pruned, deleted),
+				"duration", time.Since(start))
+		}
+
+		r.mutex.Lock()
+		if !r.pruneAgain {
+			r.pruning = false
+			r.mutex.Unlock()
+			return
+		}
+		r.pruneAgain = false
+		r.mutex.Unlock()
+	}
+}
+
+func (s *storageImpl) pruneCheckpointsAndSweep() (int, int, error) {
+	r := s.stateRetainer
+	r.mutex.Lock()
+	checkpoints, err := obscurorawdb.ReadStateCheckpoints(s.db)
+	if err != nil {
+		r.mutex.Unlock()
+		return 0, 0, err
+	}
+	if uint64(len(checkpoints)) <= r.config.Checkpoints {
+		r.mutex.Unlock()
+		return 0, 0, nil
+	}
+	pruned := checkpoints[:uint64(len(checkpoints))-r.config.Checkpoints]
+	for _, checkpoint := range pruned {
+		if err = obscurorawdb.DeleteStateCheckpoint(s.db, checkpoint.Number); err != nil {
+			r.mutex.Unlock()
+			return 0, 0, err
+		}
+	}
+	r.mutex.Unlock()
+
+	// Only the trie nodes persisted before we mark the reachable ones are candidates for deletion, so that the nodes of
+	// the checkpoints persisted in the meantime are never swept.
+	candidates, err := s.persistedTrieNodes()
+	if err != nil {
+		return 0, 0, err
+	}
+	reachable := map[gethcommon.Hash]bool{}
+	r.mutex.Lock()
+	roots, err := s.retainedRoots()
+	r.mutex.Unlock()
+	if err != nil {
+		return 0, 0, err
+	}
+	if err = s.markStates(roots, reachable); err != nil {
+		return 0, 0, err
+	}
+
+	// A candidate may have been persisted again by a later checkpoint, or become part of a state held in memory, so we
+	// mark the states retained in the meantime before deleting. Only the nodes not visited yet are traversed.
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if roots, err = s.retainedRoots(); err != nil {
+		return 0, 0, err
+	}
+	if err = s.markStates(roots, reachable); err != nil {
+		return 0, 0, err
+	}
+	deleted, err := s.deleteTrieNodes(candidates, reachable)
+	return len(pruned), deleted, err
+}
+
+// Returns the roots of the retained checkpoints and of the states held in memory. Must be called with the retainer's
+// mutex held.
+func (s *storageImpl) retainedRoots() ([]gethcommon.Hash, error) {
+	checkpoints, err := obscurorawdb.ReadStateCheckpoints(s.db)
+	if err != nil {
+		return nil, err
+	}
+	roots := make([]gethcommon.Hash, 0, len(checkpoints)+len(s.stateRetainer.recentRoots))
+	for _, checkpoint := range checkpoints {
+		roots = append(roots, checkpoint.Root)
+	}
+	for root := range s.stateRetainer.recentRoots {
+		roots = append(roots, root)
+	}
+	return roots, nil
+}
+
+// Marks the trie nodes reachable from the states with the given roots.
+func (s *storageImpl) markStates(roots []gethcommon.Hash, reachable map[gethcommon.Hash]bool) error {
+	for _, root := range roots {
+		if err := s.visitState(root, reachable, nil); err != nil {
+			return fmt.Errorf("could not mark retained state %s. Cause: %w", root, err)
+		}
+	}
+	return nil
+}
+
+// Returns the keys of the persisted trie nodes. Trie nodes are stored under the hash of their content, which
+// distinguishes them from any other entry keyed by 32 bytes.
+func (s *storageImpl) persistedTrieNodes() ([]gethcommon.Hash, error) {
+	var nodes []gethcommon.Hash
+	it := s.db.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		if len(it.Key()) == gethcommon.HashLength && bytes.Equal(it.Key(), crypto.Keccak256(it.Value())) {
+			nodes = append(nodes, gethcommon.BytesToHash(it.Key()))
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("could not iterate over trie nodes. Cause: %w", err)
+	}
+	return nodes, nil
+}
+
+// Deletes the given trie nodes that are not in the reachable set.
+func (s *storageImpl) deleteTrieNodes(nodes []gethcommon.Hash, reachable map[gethcommon.Hash]bool) (int, error) {
+	deleted := 0
+	dbBatch := s.db.NewBatch()
+	for _, node := range nodes {
+		if reachable[node] {
+			continue
+		}
+		if err := dbBatch.Delete(node.Bytes()); err != nil {
+			return 0, fmt.Errorf("could not delete trie node. Cause: %w", err)
+		}
+		deleted++
+		if dbBatch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := dbBatch.Write(); err != nil {
+				return 0, fmt.Errorf("could not delete trie nodes. Cause: %w", err)
+			}
+			dbBatch.Reset()
+		}
+	}
+	if err := dbBatch.Write(); err != nil {
+		return 0, fmt.Errorf("could not delete trie nodes. Cause: %w", err)
+	}
+	return deleted, nil
+}
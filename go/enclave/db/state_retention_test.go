@@ -0,0 +1,129 @@
+package db
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/enclave/core"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+	obscurorawdb "github.com/obscuronet/go-obscuro/go/enclave/db/rawdb"
+)
+
+var (
+	retentionAccount = gethcommon.HexToAddress("0x03")
+	retentionValue   = gethcommon.HexToHash("0x04")
+)
+
+func TestStateOutsideRetentionWindowIsPruned(t *testing.T) {
+	backingDB := rawdb.NewMemoryDatabase()
+	retention := StateRetention{RecentBatches: 2, CheckpointInterval: 4, Checkpoints: 1}
+	storage := NewStorageWithStateRetention(backingDB, params.AllEthashProtocolChanges, retention, gethlog.New())
+
+	// An entry keyed by 32 bytes that is not a trie node must survive the pruning.
+	otherKey := gethcommon.HexToHash("0x05").Bytes()
+	if err := backingDB.Put(otherKey, []byte("not a trie node")); err != nil {
+		t.Fatalf("could not write entry. Cause: %s", err)
+	}
+	l1Block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0)})
+	storage.StoreBlock(l1Block)
+	if err := storage.UpdateL1Head(l1Block.Hash()); err != nil {
+		t.Fatalf("could not update L1 head. Cause: %s", err)
+	}
+
+	stateDB, err := storage.EmptyStateDB()
+	if err != nil {
+		t.Fatalf("could not create state DB. Cause: %s", err)
+	}
+	batches := make([]*core.Batch, 13)
+	for number := 0; number < 10; number++ {
+		batches[number] = commitRetentionBatch(t, storage, stateDB, batches, number)
+	}
+	// Nothing has been rolled up, so the states outside the window are still needed to re-execute the batches.
+	if _, err = storage.CreateStateDB(*batches[1].Hash()); err != nil {
+		t.Fatalf("expected state of batch not yet rolled up to be retained. Cause: %s", err)
+	}
+
+	rollup := &core.Rollup{
+		Header:  &common.RollupHeader{Number: big.NewInt(0), L1Proof: l1Block.Hash(), HeadBatchHash: *batches[9].Hash()},
+		Batches: batches[:10],
+	}
+	l1BlockHash := l1Block.Hash()
+	if err = storage.StoreRollup(rollup, l1BlockHash); err != nil {
+		t.Fatalf("could not store rollup. Cause: %s", err)
+	}
+	if err = storage.UpdateHeadRollup(&l1BlockHash, rollup.Hash()); err != nil {
+		t.Fatalf("could not update head rollup. Cause: %s", err)
+	}
+	for number := 10; number < len(batches); number++ {
+		batches[number] = commitRetentionBatch(t, storage, stateDB, batches, number)
+	}
+	storage.(*storageImpl).stateRetainer.pruningDone.Wait()
+
+	// Batches 11 and 12 are within the window, batch 9 is the head batch of the latest rollup and batch 12 is also the
+	// only retained checkpoint.
+	for _, number := range []int{9, 10, 11, 12} {
+		if _, err = storage.CreateStateDB(*batches[number].Hash()); err != nil {
+			t.Fatalf("expected state of batch %d to be retained. Cause: %s", number, err)
+		}
+	}
+	for _, number := range []int{0, 4, 7, 8} {
+		if _, err = storage.CreateStateDB(*batches[number].Hash()); !errors.Is(err, ErrStateNotRetained) {
+			t.Fatalf("expected state of batch %d to be pruned, got %v", number, err)
+		}
+	}
+	checkpoints, err := obscurorawdb.ReadStateCheckpoints(backingDB)
+	if err != nil || len(checkpoints) != 1 || checkpoints[0].Number != 12 {
+		t.Fatalf("expected batch 12 to be the only retained checkpoint, got %v", checkpoints)
+	}
+	if has, err := backingDB.Has(otherKey); err != nil || !has {
+		t.Fatal("expected entry that is not a trie node to survive the pruning")
+	}
+
+	// After a restart, only the persisted checkpoint remains, and the older states are still reported as pruned.
+	if err = storage.UpdateHeadBatch(l1Block.Hash(), batches[12], nil); err != nil {
+		t.Fatalf("could not update head batch. Cause: %s", err)
+	}
+	restarted := NewStorageWithStateRetention(backingDB, params.AllEthashProtocolChanges, retention, gethlog.New())
+	checkpointState, err := restarted.CreateStateDB(*batches[12].Hash())
+	if err != nil {
+		t.Fatalf("expected state of checkpoint to be persisted. Cause: %s", err)
+	}
+	if checkpointState.GetBalance(retentionAccount).Cmp(big.NewInt(13)) != 0 ||
+		checkpointState.GetState(retentionAccount, gethcommon.BigToHash(big.NewInt(0))) != retentionValue {
+		t.Fatal("persisted checkpoint state did not match the committed state")
+	}
+	if _, err = restarted.CreateStateDB(*batches[11].Hash()); err == nil {
+		t.Fatal("expected state of batch before the checkpoint not to be persisted")
+	}
+	if _, err = restarted.CreateStateDB(*batches[0].Hash()); !errors.Is(err, ErrStateNotRetained) {
+		t.Fatalf("expected state of batch 0 to be reported as pruned after a restart, got %v", err)
+	}
+}
+
+// Commits the state of the batch with the given number, which changes the account's balance and sets a new slot, and
+// stores the batch.
+func commitRetentionBatch(t *testing.T, storage Storage, stateDB *state.StateDB, batches []*core.Batch, number int) *core.Batch {
+	stateDB.SetBalance(retentionAccount, big.NewInt(int64(number+1)))
+	stateDB.SetState(retentionAccount, gethcommon.BigToHash(big.NewInt(int64(number))), retentionValue)
+	root, err := storage.CommitStateDB(stateDB, uint64(number))
+	if err != nil {
+		t.Fatalf("could not commit state. Cause: %s", err)
+	}
+	var parentHash common.L2RootHash
+	if number > 0 {
+		parentHash = *batches[number-1].Hash()
+	}
+	batch := &core.Batch{Header: &common.BatchHeader{ParentHash: parentHash, Number: big.NewInt(int64(number)), Root: root}}
+	if err = storage.StoreBatch(batch, nil); err != nil {
+		t.Fatalf("could not store batch. Cause: %s", err)
+	}
+	return batch
+}
@@ -6,15 +6,18 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/obscuronet/go-obscuro/go/common"
 	"github.com/obscuronet/go-obscuro/go/common/errutil"
 	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/go/enclave/chainconfig"
 	"github.com/obscuronet/go-obscuro/go/enclave/core"
 	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
 
@@ -30,19 +33,29 @@ var ErrNoRollups = errors.New("no rollups have been published")
 // TODO - Consistency around whether we assert the secret is available or not.
 
 type storageImpl struct {
-	db          ethdb.Database
-	stateDB     state.Database
-	chainConfig *params.ChainConfig
-	logger      gethlog.Logger
+	db            ethdb.Database
+	stateDB       state.Database
+	stateRetainer *stateRetainer
+	chainConfig   *params.ChainConfig
+	logger        gethlog.Logger
 }
 
+// NewStorage creates a storage that retains the state of every batch.
 func NewStorage(backingDB ethdb.Database, chainConfig *params.ChainConfig, logger gethlog.Logger) Storage {
-	return &storageImpl{
-		db:          backingDB,
-		stateDB:     state.NewDatabase(backingDB),
-		chainConfig: chainConfig,
-		logger:      logger,
+	return NewStorageWithStateRetention(backingDB, chainConfig, StateRetention{}, logger)
+}
+
+// NewStorageWithStateRetention creates a storage that prunes the state of old batches as configured by `retention`.
+func NewStorageWithStateRetention(backingDB ethdb.Database, chainConfig *params.ChainConfig, retention StateRetention, logger gethlog.Logger) Storage {
+	storage := &storageImpl{
+		db:            backingDB,
+		stateDB:       state.NewDatabaseWithConfig(backingDB, &trie.Config{Cache: retention.TrieCacheSizeMB}),
+		stateRetainer: newStateRetainer(retention),
+		chainConfig:   chainConfig,
+		logger:        logger,
 	}
+	storage.resumeStateRetention()
+	return storage
 }
 
 func (s *storageImpl) FetchHeadBatch() (*core.Batch, error) {
@@ -108,6 +121,45 @@ func (s *storageImpl) FetchSecret() (*crypto.SharedEnclaveSecret, error) {
 	return obscurorawdb.ReadSharedSecret(s.db)
 }
 
+func (s *storageImpl) FetchEpochSecrets() ([]crypto.SharedEnclaveSecret, error) {
+	return obscurorawdb.ReadEpochSecrets(s.db)
+}
+
+func (s *storageImpl) StoreEpochSecret(epoch uint64, secret crypto.SharedEnclaveSecret, l1Block *common.L1RootHash) error {
+	if err := obscurorawdb.WriteEpochSecret(s.db, epoch, secret); err != nil {
+		return err
+	}
+	if l1Block == nil {
+		return nil
+	}
+	return obscurorawdb.WriteEpochSecretL1Block(s.db, epoch, *l1Block)
+}
+
+func (s *storageImpl) FetchLatestEpoch(l1Block common.L1RootHash) (uint64, error) {
+	secrets, err := obscurorawdb.ReadEpochSecrets(s.db)
+	if err != nil {
+		return 0, err
+	}
+	block, err := s.FetchBlock(l1Block)
+	if err != nil {
+		return 0, err
+	}
+
+	// The secrets are stored for consecutive epochs, but the latest ones may have been published on another L1 fork.
+	latestEpoch := uint64(0)
+	for epoch := uint64(1); epoch < uint64(len(secrets)); epoch++ {
+		publishedIn, err := obscurorawdb.ReadEpochSecretL1Block(s.db, epoch)
+		if err != nil && !errors.Is(err, errutil.ErrNotFound) {
+			return 0, err
+		}
+		if publishedIn != nil && !s.IsBlockAncestor(block, *publishedIn) {
+			break
+		}
+		latestEpoch = epoch
+	}
+	return latestEpoch, nil
+}
+
 func (s *storageImpl) IsAncestor(block *types.Block, maybeAncestor *types.Block) bool {
 	s.assertSecretAvailable()
 	if bytes.Equal(maybeAncestor.Hash().Bytes(), block.Hash().Bytes()) {
@@ -250,6 +302,9 @@ func (s *storageImpl) CreateStateDB(hash common.L2RootHash) (*state.StateDB, err
 	// todo - snapshots?
 	statedb, err := state.New(batch.Header.Root, s.stateDB, nil)
 	if err != nil {
+		if s.stateRetainer.outsideWindow(batch.NumberU64()) {
+			return nil, fmt.Errorf("%w: batch %d", ErrStateNotRetained, batch.NumberU64())
+		}
 		return nil, fmt.Errorf("could not create state DB. Cause: %w", err)
 	}
 
@@ -325,6 +380,72 @@ func (s *storageImpl) StoreAttestedKey(aggregator gethcommon.Address, key *ecdsa
 	return obscurorawdb.WriteAttestationKey(s.db, aggregator, key)
 }
 
+func (s *storageImpl) FetchAttestedKeys() (map[gethcommon.Address]*ecdsa.PublicKey, error) {
+	return obscurorawdb.ReadAttestationKeys(s.db)
+}
+
+func (s *storageImpl) IsAttestationRevoked(aggregator gethcommon.Address, l1Block common.L1RootHash) (bool, error) {
+	revocations, err := obscurorawdb.ReadAttestationRevocations(s.db, aggregator)
+	if err != nil {
+		return false, err
+	}
+	block, err := s.FetchBlock(l1Block)
+	if err != nil && !errors.Is(err, errutil.ErrNotFound) {
+		return false, err
+	}
+
+	// Revocations published on other L1 forks are not in force, but those stored without an L1 block are in force on
+	// every fork.
+	for _, revokedIn := range revocations {
+		if revokedIn == (gethcommon.Hash{}) || (block != nil && s.IsBlockAncestor(block, revokedIn)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *storageImpl) StoreAttestationRevocation(aggregator gethcommon.Address, l1Block common.L1RootHash) error {
+	return obscurorawdb.WriteAttestationRevocation(s.db, aggregator, l1Block)
+}
+
+func (s *storageImpl) FetchChainConfig() (*chainconfig.ChainConfig, error) {
+	return obscurorawdb.ReadChainConfig(s.db)
+}
+
+func (s *storageImpl) StoreChainConfig(cfg *chainconfig.ChainConfig) error {
+	return obscurorawdb.WriteChainConfig(s.db, cfg)
+}
+
+func (s *storageImpl) FetchAttestationAllowList(l1Block common.L1RootHash) (*common.AttestationAllowList, error) {
+	allowLists, err := obscurorawdb.ReadAttestationAllowLists(s.db)
+	if err != nil {
+		return nil, err
+	}
+	block, err := s.FetchBlock(l1Block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Allow-lists published on other L1 forks are not in force.
+	var latest *common.AttestationAllowList
+	for publishedIn, allowList := range allowLists {
+		if latest != nil && allowList.Version <= latest.Version {
+			continue
+		}
+		if s.IsBlockAncestor(block, publishedIn) {
+			latest = allowList
+		}
+	}
+	if latest == nil {
+		return nil, errutil.ErrNotFound
+	}
+	return latest, nil
+}
+
+func (s *storageImpl) StoreAttestationAllowList(l1Block common.L1RootHash, allowList *common.AttestationAllowList) error {
+	return obscurorawdb.WriteAttestationAllowList(s.db, l1Block, allowList)
+}
+
 func (s *storageImpl) StoreBatch(batch *core.Batch, receipts []*types.Receipt) error {
 	dbBatch := s.db.NewBatch()
 
@@ -355,15 +476,102 @@ func (s *storageImpl) GetL1Messages(blockHash common.L1RootHash) (common.CrossCh
 	return obscurorawdb.GetL1Messages(s.db, blockHash, s.logger)
 }
 
-func (s *storageImpl) StoreRollup(rollup *core.Rollup) error {
+func (s *storageImpl) StoreInboundMessageRecord(record *common.InboundMessageRecord) error {
+	return obscurorawdb.WriteInboundMessageRecord(s.db, record)
+}
+
+func (s *storageImpl) GetInboundMessageRecord(sender gethcommon.Address, sequence uint64) (*common.InboundMessageRecord, error) {
+	return obscurorawdb.ReadInboundMessageRecord(s.db, sender, sequence)
+}
+
+func (s *storageImpl) GetInboundMessageRecords(sender gethcommon.Address) ([]*common.InboundMessageRecord, error) {
+	return obscurorawdb.ReadInboundMessageRecords(s.db, sender)
+}
+
+func (s *storageImpl) StoreDivergenceReport(report *common.DivergenceReport) error {
+	return obscurorawdb.WriteDivergenceReport(s.db, report)
+}
+
+func (s *storageImpl) FetchDivergenceReports() ([]*common.DivergenceReport, error) {
+	return obscurorawdb.ReadDivergenceReports(s.db)
+}
+
+func (s *storageImpl) StoreRollup(rollup *core.Rollup, l1Block common.L1RootHash) error {
 	dbBatch := s.db.NewBatch()
 
 	if err := obscurorawdb.WriteRollup(dbBatch, rollup); err != nil {
 		return fmt.Errorf("could not write rollup. Cause: %w", err)
 	}
+	if err := obscurorawdb.WriteRollupInclusion(dbBatch, rollup, l1Block); err != nil {
+		return fmt.Errorf("could not write rollup inclusion. Cause: %w", err)
+	}
 
 	if err := dbBatch.Write(); err != nil {
 		return fmt.Errorf("could not write rollup to storage. Cause: %w", err)
 	}
 	return nil
 }
+
+func (s *storageImpl) FetchRollupForBatch(batchHash common.L2RootHash) (*core.Rollup, *common.L1RootHash, error) {
+	l1Head, err := s.FetchHeadBlock()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not retrieve L1 head. Cause: %w", err)
+	}
+	return s.rollupForBatchAt(batchHash, l1Head)
+}
+
+// Returns the rollup containing the batch that was published on the L1 chain ending in the given block.
+func (s *storageImpl) rollupForBatchAt(batchHash common.L2RootHash, l1Head *types.Block) (*core.Rollup, *common.L1RootHash, error) {
+	rollupHashes, err := obscurorawdb.ReadRollupsForBatch(s.db, batchHash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The batch may have been rolled up again after an L1 fork, so we look for the rollup on the canonical L1 chain.
+	for _, rollupHash := range rollupHashes {
+		l1BlockHash, err := obscurorawdb.ReadRollupL1Block(s.db, rollupHash)
+		if err != nil {
+			return nil, nil, err
+		}
+		l1Block, err := s.FetchBlock(*l1BlockHash)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not retrieve L1 block containing rollup. Cause: %w", err)
+		}
+		// A snapshot only carries the canonical rollup of the batches that precede its L1 blocks, so a single rollup
+		// published before the earliest L1 block we hold is canonical.
+		if !s.IsAncestor(l1Head, l1Block) && !(len(rollupHashes) == 1 && s.predatesL1History(l1Head, l1Block)) {
+			continue
+		}
+		rollup, err := obscurorawdb.ReadRollup(s.db, rollupHash)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read rollup. Cause: %w", err)
+		}
+		return rollup, l1BlockHash, nil
+	}
+	return nil, nil, errutil.ErrNotFound
+}
+
+// Returns whether the chain ending in the given L1 head runs out of stored blocks above the height of the given block,
+// as it does for the blocks preceding a snapshot.
+func (s *storageImpl) predatesL1History(l1Head *types.Block, block *types.Block) bool {
+	for ancestor := l1Head; ancestor.NumberU64() > block.NumberU64(); {
+		parent, err := s.FetchBlock(ancestor.ParentHash())
+		if err != nil {
+			return true
+		}
+		ancestor = parent
+	}
+	return false
+}
+
+func (s *storageImpl) FetchInFlightRollup() (uint64, time.Time, error) {
+	return obscurorawdb.ReadInFlightRollup(s.db)
+}
+
+func (s *storageImpl) StoreInFlightRollup(number uint64, producedAt time.Time) error {
+	return obscurorawdb.WriteInFlightRollup(s.db, number, producedAt)
+}
+
+func (s *storageImpl) DeleteInFlightRollup() error {
+	return obscurorawdb.DeleteInFlightRollup(s.db)
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/obscuronet/go-obscuro/go/enclave/l2chain"
 
@@ -25,6 +26,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/obscuronet/go-obscuro/go/common/gethencoding"
 	"github.com/obscuronet/go-obscuro/go/common/log"
 
@@ -59,11 +61,14 @@ type enclaveImpl struct {
 	rollupManager        rollupmanager.RollupManager
 	subscriptionManager  *events.SubscriptionManager
 	crossChainProcessors *crosschain.Processors
+	eventStreams         *eventStreams
+	pushedLogs           *pushedLogs // the batches whose logs were pushed on the event stream
 
 	chain *l2chain.ObscuroChain
 
 	mgmtContractLib     mgmtcontractlib.MgmtContractLib
 	attestationProvider AttestationProvider // interface for producing attestation reports and verifying them
+	attestationPolicy   *attestationPolicyHolder
 
 	enclaveKey    *ecdsa.PrivateKey // this is a key specific to this enclave, which is included in the Attestation. Used for signing rollups and for encryption of the shared secret.
 	enclavePubKey []byte            // the public key of the above
@@ -99,22 +104,23 @@ func NewEnclave(
 	if err != nil {
 		logger.Crit("Failed to connect to backing database", log.ErrKey, err)
 	}
-	chainConfig := params.ChainConfig{
-		ChainID:             big.NewInt(config.ObscuroChainID),
-		HomesteadBlock:      gethcommon.Big0,
-		DAOForkBlock:        gethcommon.Big0,
-		EIP150Block:         gethcommon.Big0,
-		EIP155Block:         gethcommon.Big0,
-		EIP158Block:         gethcommon.Big0,
-		ByzantiumBlock:      gethcommon.Big0,
-		ConstantinopleBlock: gethcommon.Big0,
-		PetersburgBlock:     gethcommon.Big0,
-		IstanbulBlock:       gethcommon.Big0,
-		MuirGlacierBlock:    gethcommon.Big0,
-		BerlinBlock:         gethcommon.Big0,
-		LondonBlock:         gethcommon.Big0,
-	}
-	storage := db.NewStorage(backingDB, &chainConfig, logger)
+	chainConfig, err := genesis.ChainConfig(config.ObscuroChainID)
+	if err != nil {
+		logger.Crit("Invalid chain config in genesis.", log.ErrKey, err)
+	}
+	if err = genesis.Validate(config.MinGasPrice); err != nil {
+		logger.Crit("Invalid genesis.", log.ErrKey, err)
+	}
+	stateRetention := db.StateRetention{
+		RecentBatches:      config.StateRetentionBatches,
+		CheckpointInterval: config.StateCheckpointInterval,
+		Checkpoints:        config.StateCheckpointsRetained,
+		TrieCacheSizeMB:    config.TrieCacheSizeMB,
+	}
+	storage := db.NewStorageWithStateRetention(backingDB, chainConfig.EVM, stateRetention, logger)
+	if err = applyChainConfig(storage, chainConfig, logger); err != nil {
+		logger.Crit("Could not apply chain config.", log.ErrKey, err)
+	}
 
 	// Initialise the Ethereum "Blockchain" structure that will allow us to validate incoming blocks
 	// Todo - check the minimum difficulty parameter
@@ -128,10 +134,17 @@ func NewEnclave(
 		logger.Info("validateBlocks is set to false. L1 blocks will not be validated.")
 	}
 
+	policy, err := NewAttestationPolicy(config)
+	if err != nil {
+		logger.Crit("Invalid attestation policy.", log.ErrKey, err)
+	}
+	attestationPolicy := newAttestationPolicyHolder(policy, config.L1ChainID, config.ManagementContractAddress)
+	loadAttestationAllowListAtHead(storage, attestationPolicy, logger)
+
 	// Todo- make sure the enclave cannot be started in production with WillAttest=false
 	var attestationProvider AttestationProvider
 	if config.WillAttest {
-		attestationProvider = &EgoAttestationProvider{}
+		attestationProvider = &EgoAttestationProvider{policy: attestationPolicy}
 	} else {
 		logger.Info("WARNING - Attestation is not enabled, enclave will not create a verified attestation report.")
 		attestationProvider = &DummyAttestationProvider{}
@@ -157,6 +170,12 @@ func NewEnclave(
 	memp := mempool.New(config.ObscuroChainID)
 
 	crossChainProcessors := crosschain.New(&config.MessageBusAddress, storage, big.NewInt(config.ObscuroChainID), logger)
+	// If the enclave is restarting, the secret is already available. Otherwise, the owner is derived once it's received.
+	if secret, err := storage.FetchSecret(); err == nil {
+		if _, err = crossChainProcessors.Local.DeriveOwner(*secret); err != nil {
+			logger.Crit("Could not derive the message bus owner.", log.ErrKey, err)
+		}
+	}
 
 	subscriptionManager := events.NewSubscriptionManager(&rpcEncryptionManager, storage, logger)
 	chain := l2chain.New(
@@ -167,9 +186,12 @@ func NewEnclave(
 		crossChainProcessors,
 		memp,
 		enclaveKey,
-		&chainConfig,
+		chainConfig,
 		config.SequencerID,
 		genesis,
+		config.TimeBasedBatches,
+		config.MaxBatchGas,
+		config.MaxBatchSize,
 		logger,
 	)
 
@@ -178,6 +200,8 @@ func NewEnclave(
 		transactionBlobCrypto,
 		config.ObscuroChainID,
 		config.L1ChainID,
+		config.MaxRollupSize,
+		publicationPolicy(config),
 		storage,
 		chain,
 		logger,
@@ -191,7 +215,7 @@ func NewEnclave(
 
 	jsonConfig, _ := json.MarshalIndent(config, "", "  ")
 	logger.Info("Enclave service created with following config", log.CfgKey, string(jsonConfig))
-	return &enclaveImpl{
+	enclave := &enclaveImpl{
 		config:                config,
 		storage:               storage,
 		blockResolver:         storage,
@@ -201,15 +225,23 @@ func NewEnclave(
 		rollupManager:         rollupManager,
 		subscriptionManager:   subscriptionManager,
 		crossChainProcessors:  crossChainProcessors,
+		eventStreams:          newEventStreams(logger),
+		pushedLogs:            newPushedLogs(),
 		chain:                 chain,
 		mgmtContractLib:       mgmtContractLib,
 		attestationProvider:   attestationProvider,
+		attestationPolicy:     attestationPolicy,
 		enclaveKey:            enclaveKey,
 		enclavePubKey:         serializedEnclavePubKey,
 		transactionBlobCrypto: transactionBlobCrypto,
 		profiler:              prof,
 		logger:                logger,
 	}
+	// If the enclave is restarting, the keys of the epochs after the first are derived from the stored secrets.
+	if err = enclave.loadEpochKeys(); err != nil {
+		logger.Crit("Could not load the keys of the network secret epochs.", log.ErrKey, err)
+	}
+	return enclave
 }
 
 // Status is only implemented by the RPC wrapper
@@ -230,20 +262,25 @@ func (e *enclaveImpl) StopClient() error {
 }
 
 // SubmitL1Block is used to update the enclave with an additional L1 block.
-func (e *enclaveImpl) SubmitL1Block(block types.Block, receipts types.Receipts, isLatest bool) (*common.BlockSubmissionResponse, error) {
+func (e *enclaveImpl) SubmitL1Block(block types.Block, receipts types.Receipts, isLatest bool) error {
 	e.logger.Info("SubmitL1Block", log.BlockHeightKey, block.Number(), log.BlockHashKey, block.Hash())
 
 	// If the block and receipts do not match, reject the block.
 	br, err := common.ParseBlockAndReceipts(&block, &receipts, e.crossChainProcessors.Enabled())
 	if err != nil {
-		return nil, e.rejectBlockErr(fmt.Errorf("could not submit L1 block. Cause: %w", err))
+		return e.rejectBlockErr(fmt.Errorf("could not submit L1 block. Cause: %w", err))
+	}
+
+	// The batches produced for the block are encrypted with the key of the latest epoch on the block's L1 chain.
+	if err = e.setLatestEpoch(block.ParentHash()); err != nil && !errors.Is(err, errutil.ErrNotFound) {
+		e.logger.Error("Could not set the latest epoch of the network secret.", log.ErrKey, err)
 	}
 
 	// We update the enclave state based on the L1 block.
 	newL2Head, producedBatch, err := e.chain.ProcessL1Block(block, receipts, isLatest)
 	if err != nil {
 		e.logger.Info("ProcessL1Block failed", log.BlockHeightKey, block.Number(), log.BlockHashKey, block.Hash(), log.ErrKey, err)
-		return nil, e.rejectBlockErr(fmt.Errorf("could not submit L1 block. Cause: %w", err))
+		return e.rejectBlockErr(fmt.Errorf("could not submit L1 block. Cause: %w", err))
 	}
 	e.logger.Info("ProcessL1Block successful", log.BlockHeightKey, block.Number(), log.BlockHashKey, block.Hash())
 
@@ -252,50 +289,50 @@ func (e *enclaveImpl) SubmitL1Block(block types.Block, receipts types.Receipts,
 		e.logger.Warn("Error processing L1 block for rollups", log.ErrKey, err)
 	}
 
-	// We prepare the block submission response.
-	// TODO: Fix subscribed logs for validators who are being synchronized only through L1
-	blockSubmissionResponse := e.produceBlockSubmissionResponse(&block, newL2Head, producedBatch)
+	// We prepare the event for the host.
+	event := e.produceBlockEvent(newL2Head, producedBatch)
 
-	if producedBatch != nil && (producedBatch.Header.Number.Uint64()%e.config.Cadence == 0) {
-		rollup, err := e.rollupManager.CreateRollup()
-		if err != nil {
-			e.logger.Error("Failed to produce rollup", log.ErrKey, err)
-		}
-		blockSubmissionResponse.ProducedRollup = rollup.ToExtRollup(e.transactionBlobCrypto)
+	if producedBatch != nil {
+		event.ProducedRollups, event.RollupDecision = e.produceRollupsIfDue()
 	}
 
-	e.logger.Info("produceBlockSubmissionResponse successful", log.BlockHeightKey, block.Number(), log.BlockHashKey, block.Hash(),
-		"newBatch", describeBSR(blockSubmissionResponse))
-	blockSubmissionResponse.ProducedSecretResponses = e.processNetworkSecretMsgs(block)
+	e.logger.Info("produceBlockEvent successful", log.BlockHeightKey, block.Number(), log.BlockHashKey, block.Hash(),
+		"newBatch", describeEvent(event))
+	event.ProducedSecretResponses, event.ProducedSecretRotation = e.processNetworkSecretMsgs(block)
 
 	// We remove any transactions considered immune to re-orgs from the mempool.
-	if blockSubmissionResponse.ProducedBatch != nil {
-		err = e.removeOldMempoolTxs(blockSubmissionResponse.ProducedBatch.Header)
+	if event.ProducedBatch != nil {
+		err = e.removeOldMempoolTxs(event.ProducedBatch.Header)
 		if err != nil {
 			e.logger.Error("removeOldMempoolTxs fail", log.BlockHeightKey, block.Number(), log.BlockHashKey, block.Hash(), log.ErrKey, err)
-			return nil, e.rejectBlockErr(fmt.Errorf("could not remove transactions from mempool. Cause: %w", err))
+			return e.rejectBlockErr(fmt.Errorf("could not remove transactions from mempool. Cause: %w", err))
 		}
 	}
 
-	return blockSubmissionResponse, nil
+	e.eventStreams.publish(event)
+	return nil
 }
 
-// useful description of the BSR for debugging
-func describeBSR(response *common.BlockSubmissionResponse) string {
-	if response.RejectError != nil {
-		return fmt.Sprintf("BlockSubmissionResponse failed with err=%s", response.RejectError.Error())
-	}
+// useful description of the event for debugging
+func describeEvent(response *common.EnclaveEvent) string {
 	producedBatch := "no batch produced"
 	if response.ProducedBatch != nil {
 		producedBatch = fmt.Sprintf("newBatch{num=%d, numTx=%d, hash=%s}",
 			response.ProducedBatch.Header.Number, len(response.ProducedBatch.TxHashes), response.ProducedBatch.Hash())
 	}
-	producedRollup := "no rollup produced"
-	if response.ProducedRollup != nil {
-		producedRollup = fmt.Sprintf("newRollup{num=%d, numBatches=%d, hash=%s}",
-			response.ProducedRollup.Header.Number, len(response.ProducedRollup.Batches), response.ProducedRollup.Hash())
+	producedRollups := "no rollup produced"
+	if len(response.ProducedRollups) != 0 {
+		descriptions := make([]string, len(response.ProducedRollups))
+		for idx, rollup := range response.ProducedRollups {
+			descriptions[idx] = fmt.Sprintf("newRollup{num=%d, numBatches=%d, hash=%s}",
+				rollup.Header.Number, len(rollup.Batches), rollup.Hash())
+		}
+		producedRollups = strings.Join(descriptions, ", ")
+	}
+	if response.RollupDecision != nil {
+		producedRollups = fmt.Sprintf("%s (%s)", producedRollups, response.RollupDecision.Reason)
 	}
-	return fmt.Sprintf("%s, %s", producedBatch, producedRollup)
+	return fmt.Sprintf("%s, %s", producedBatch, producedRollups)
 }
 
 func (e *enclaveImpl) SubmitTx(tx common.EncryptedTx) (common.EncryptedResponseSendRawTx, error) {
@@ -309,8 +346,11 @@ func (e *enclaveImpl) SubmitTx(tx common.EncryptedTx) (common.EncryptedResponseS
 		return nil, fmt.Errorf("could not decrypt transaction. Cause: %w", err)
 	}
 
-	if e.crossChainProcessors.Local.IsSyntheticTransaction(*decryptedTx) {
-		return nil, fmt.Errorf("synthetic transaction coming from external rpc")
+	if err = e.crossChainProcessors.Local.VerifySyntheticTransactions(common.L2Transactions{decryptedTx}); err != nil {
+		if errors.Is(err, crosschain.ErrOwnerNotDerived) {
+			return nil, errors.New("the enclave cannot accept transactions until it has received the shared secret")
+		}
+		return nil, fmt.Errorf("synthetic transaction coming from external rpc. Cause: %w", err)
 	}
 	if err = e.checkGas(decryptedTx); err != nil {
 		e.logger.Info("", log.ErrKey, err.Error())
@@ -339,25 +379,116 @@ func (e *enclaveImpl) SubmitTx(tx common.EncryptedTx) (common.EncryptedResponseS
 
 func (e *enclaveImpl) SubmitBatch(extBatch *common.ExtBatch) error {
 	e.logger.Info("SubmitBatch", "height", extBatch.Header.Number, "hash", extBatch.Hash(), "l1", extBatch.Header.L1Proof)
-	batch := core.ToBatch(extBatch, e.transactionBlobCrypto)
-	if err := e.chain.UpdateL2Chain(batch); err != nil {
+	batch, err := core.ToBatch(extBatch, e.transactionBlobCrypto)
+	if err != nil {
+		return err
+	}
+	if err = e.chain.UpdateL2Chain(batch); err != nil {
 		return fmt.Errorf("could not update L2 chain based on batch. Cause: %w", err)
 	}
 
+	// The logs are pushed as soon as the batch is processed, rather than when the next L1 block is ingested.
+	e.eventStreams.publish(&common.EnclaveEvent{SubscribedLogs: e.getEncryptedLogs(*batch.Hash())})
 	return nil
 }
 
-func (e *enclaveImpl) GenerateRollup() (*common.ExtRollup, error) {
+func (e *enclaveImpl) StreamEvents(after uint64) (<-chan *common.EnclaveEvent, func(), error) {
+	events, closeStream := e.eventStreams.open(after)
+	return events, closeStream, nil
+}
+
+func (e *enclaveImpl) AckEvents(sequence uint64) error {
+	e.eventStreams.ack(sequence)
+	return nil
+}
+
+func (e *enclaveImpl) CreateBatch(skipIfEmpty bool) error {
+	if e.config.NodeType != common.Sequencer || !e.config.TimeBasedBatches {
+		return errors.New("only a sequencer configured for time-based batches can create batches on request")
+	}
+
+	batch, _, err := e.chain.ProduceBatch(skipIfEmpty)
+	if err != nil {
+		return fmt.Errorf("could not produce batch. Cause: %w", err)
+	}
+	if batch == nil {
+		return nil
+	}
+
+	event := &common.EnclaveEvent{
+		ProducedBatch:  batch.ToExtBatch(e.transactionBlobCrypto),
+		SubscribedLogs: e.getEncryptedLogs(*batch.Hash()),
+	}
+	event.ProducedRollups, event.RollupDecision = e.produceRollupsIfDue()
+	e.logger.Info("CreateBatch successful", "newBatch", describeEvent(event))
+
+	// We remove any transactions considered immune to re-orgs from the mempool.
+	if err = e.removeOldMempoolTxs(batch.Header); err != nil {
+		return fmt.Errorf("could not remove transactions from mempool. Cause: %w", err)
+	}
+	e.eventStreams.publish(event)
+	return nil
+}
+
+func (e *enclaveImpl) TimeBasedBatches() (bool, error) {
+	return e.config.NodeType == common.Sequencer && e.config.TimeBasedBatches, nil
+}
+
+// Produces the rollups if the publication policy decides it is time to publish the pending batches. Returns the
+// rollups and the decision.
+func (e *enclaveImpl) produceRollupsIfDue() ([]*common.ExtRollup, *common.RollupDecision) {
+	decision, err := e.rollupManager.DecidePublication()
+	if err != nil {
+		e.logger.Error("Failed to decide whether to produce rollup", log.ErrKey, err)
+		return nil, &common.RollupDecision{Publish: false, Reason: fmt.Sprintf("could not apply publication policy: %s", err)}
+	}
+	if !decision.Publish {
+		return nil, decision
+	}
+
+	rollups, err := e.rollupManager.CreateRollups()
+	if err != nil {
+		e.logger.Error("Failed to produce rollup", log.ErrKey, err)
+		return nil, &common.RollupDecision{Publish: false, Reason: fmt.Sprintf("could not produce rollup: %s", err)}
+	}
+	extRollups := make([]*common.ExtRollup, len(rollups))
+	for idx, rollup := range rollups {
+		extRollups[idx] = rollup.ToExtRollup(e.transactionBlobCrypto)
+	}
+	return extRollups, decision
+}
+
+// Builds the rollup publication policy from the enclave config.
+func publicationPolicy(cfg config.EnclaveConfig) rollupmanager.PublicationPolicy {
+	var maxL1BaseFee *big.Int
+	if cfg.RollupMaxL1BaseFee != nil && cfg.RollupMaxL1BaseFee.Sign() > 0 {
+		maxL1BaseFee = cfg.RollupMaxL1BaseFee
+	}
+	return rollupmanager.PublicationPolicy{
+		MaxPendingBatches: cfg.Cadence,
+		MaxPendingBytes:   cfg.MaxPendingRollupSize,
+		MaxInterval:       cfg.RollupInterval,
+		MaxL1BaseFee:      maxL1BaseFee,
+		L1BaseFeeDeadline: cfg.RollupL1BaseFeeDeadline,
+		InclusionTimeout:  cfg.RollupInclusionTimeout,
+	}
+}
+
+func (e *enclaveImpl) GenerateRollups() ([]*common.ExtRollup, error) {
 	if e.config.NodeType != common.Sequencer {
 		return nil, errors.New("only sequencer can generate rollups")
 	}
 
-	rollup, err := e.rollupManager.CreateRollup()
+	rollups, err := e.rollupManager.CreateRollups()
 	if err != nil {
 		return nil, err
 	}
 
-	return rollup.ToExtRollup(e.transactionBlobCrypto), nil
+	extRollups := make([]*common.ExtRollup, len(rollups))
+	for idx, rollup := range rollups {
+		extRollups[idx] = rollup.ToExtRollup(e.transactionBlobCrypto)
+	}
+	return extRollups, nil
 }
 
 // ExecuteOffChainTransaction handles param decryption, validation and encryption
@@ -550,12 +681,236 @@ func (e *enclaveImpl) GetTransactionReceipt(encryptedParams common.EncryptedPara
 	return encryptedTxReceipt, nil
 }
 
+func (e *enclaveImpl) GetWithdrawalProofs(encryptedParams common.EncryptedParamsGetWithdrawals) (common.EncryptedResponseGetWithdrawals, error) {
+	paramBytes, err := e.rpcEncryptionManager.DecryptBytes(encryptedParams)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt params in obscuro_getWithdrawalProofs request. Cause: %w", err)
+	}
+	txHash, err := rpc.ExtractTxHash(paramBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, txBatchHash, txBatchHeight, _, err := e.storage.GetTransaction(txHash)
+	if err != nil {
+		if errors.Is(err, errutil.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// Only return proofs for transactions included in the canonical chain.
+	batch, err := e.storage.FetchBatchByHeight(txBatchHeight)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve batch containing transaction. Cause: %w", err)
+	}
+	if *batch.Hash() != txBatchHash {
+		return nil, fmt.Errorf("transaction not included in the canonical chain")
+	}
+
+	txReceipt, err := e.storage.GetTransactionReceipt(txHash)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve transaction receipt in obscuro_getWithdrawalProofs request. Cause: %w", err)
+	}
+	messages, err := e.crossChainProcessors.Local.ExtractOutboundMessages(types.Receipts{txReceipt})
+	if err != nil {
+		return nil, fmt.Errorf("could not extract cross chain messages. Cause: %w", err)
+	}
+
+	proofs := make([]*common.WithdrawalProof, len(messages))
+	for idx, message := range messages {
+		if proofs[idx], err = crosschain.NewWithdrawalProof(message, txHash, batch, e.config.MessageBusAddress); err != nil {
+			return nil, err
+		}
+	}
+	if err = e.markWithdrawalsPublished(batch, proofs); err != nil {
+		return nil, err
+	}
+
+	sender, err := rpc.GetSender(tx)
+	if err != nil {
+		return nil, fmt.Errorf("could not recover viewing key address to encrypt obscuro_getWithdrawalProofs response. Cause: %w", err)
+	}
+	proofsBytes, err := json.Marshal(proofs)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal withdrawal proofs to JSON. Cause: %w", err)
+	}
+	return e.rpcEncryptionManager.EncryptWithViewingKey(sender, proofsBytes)
+}
+
+var errDebugNamespaceDisabled = errors.New("the debug namespace is not enabled on this node")
+
+func (e *enclaveImpl) DebugTraceTransaction(encryptedParams common.EncryptedParamsTraceTx) (common.EncryptedResponseTrace, error) {
+	if !e.config.DebugNamespaceEnabled {
+		return nil, errDebugNamespaceDisabled
+	}
+	paramBytes, err := e.rpcEncryptionManager.DecryptBytes(encryptedParams)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt params in debug_traceTransaction request. Cause: %w", err)
+	}
+	txHash, traceConfig, err := rpc.ExtractTraceTxParams(paramBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, trace, err := e.chain.TraceTransaction(txHash, traceConfig)
+	if err != nil {
+		if errors.Is(err, errutil.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not trace transaction %s. Cause: %w", txHash, err)
+	}
+
+	// Only the sender of the transaction can read its trace.
+	sender, err := rpc.GetSender(tx)
+	if err != nil {
+		return nil, fmt.Errorf("could not recover viewing key address to encrypt debug_traceTransaction response. Cause: %w", err)
+	}
+	return e.rpcEncryptionManager.EncryptWithViewingKey(sender, trace)
+}
+
+func (e *enclaveImpl) DebugTraceCall(encryptedParams common.EncryptedParamsTraceCall) (common.EncryptedResponseTrace, error) {
+	if !e.config.DebugNamespaceEnabled {
+		return nil, errDebugNamespaceDisabled
+	}
+	paramBytes, err := e.rpcEncryptionManager.DecryptBytes(encryptedParams)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt params in debug_traceCall request. Cause: %w", err)
+	}
+
+	// params are [TransactionArgs, BlockNumber, TraceConfig], and the trace config is optional
+	var paramList []json.RawMessage
+	if err = json.Unmarshal(paramBytes, &paramList); err != nil {
+		return nil, fmt.Errorf("unable to decode debug_traceCall params - %w", err)
+	}
+	if len(paramList) < 2 || len(paramList) > 3 {
+		return nil, fmt.Errorf("required two or three params, but received %d", len(paramList))
+	}
+	var callParam, blockParam interface{}
+	if err = json.Unmarshal(paramList[0], &callParam); err != nil {
+		return nil, fmt.Errorf("unable to decode call params - %w", err)
+	}
+	if err = json.Unmarshal(paramList[1], &blockParam); err != nil {
+		return nil, fmt.Errorf("unable to decode block number - %w", err)
+	}
+	apiArgs, err := gethencoding.ExtractEthCall(callParam)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode EthCall Params - %w", err)
+	}
+	// encryption will fail if no From address is provided
+	if apiArgs.From == nil {
+		return nil, fmt.Errorf("no from address provided")
+	}
+	blkNumber, err := gethencoding.ExtractBlockNumber(blockParam)
+	if err != nil {
+		return nil, fmt.Errorf("unable to extract requested block number - %w", err)
+	}
+	var traceConfig *tracers.TraceConfig
+	if len(paramList) == 3 {
+		if traceConfig, err = rpc.ExtractTraceConfig(paramList[2]); err != nil {
+			return nil, err
+		}
+	}
+
+	trace, err := e.chain.TraceCall(apiArgs, blkNumber, traceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not trace call. Cause: %w", err)
+	}
+
+	encryptedTrace, err := e.rpcEncryptionManager.EncryptWithViewingKey(*apiArgs.From, trace)
+	if err != nil {
+		return nil, fmt.Errorf("enclave could not respond securely to debug_traceCall request. Cause: %w", err)
+	}
+	return encryptedTrace, nil
+}
+
+func (e *enclaveImpl) GetDivergenceReports() ([]*common.DivergenceReport, error) {
+	return e.storage.FetchDivergenceReports()
+}
+
+func (e *enclaveImpl) GetInboundMessageRecords(encryptedParams common.EncryptedParamsGetInboundMsgs) (common.EncryptedResponseGetInboundMsgs, error) {
+	paramBytes, err := e.rpcEncryptionManager.DecryptBytes(encryptedParams)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt params in obscuro_getInboundMessageRecords request. Cause: %w", err)
+	}
+	sender, sequence, err := rpc.ExtractInboundMessageParams(paramBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*common.InboundMessageRecord, 0)
+	if sequence == nil {
+		if records, err = e.storage.GetInboundMessageRecords(sender); err != nil {
+			return nil, fmt.Errorf("could not retrieve inbound message records. Cause: %w", err)
+		}
+	} else {
+		record, err := e.storage.GetInboundMessageRecord(sender, *sequence)
+		if err != nil && !errors.Is(err, errutil.ErrNotFound) {
+			return nil, fmt.Errorf("could not retrieve inbound message record. Cause: %w", err)
+		}
+		if record != nil {
+			records = append(records, record)
+		}
+	}
+	for _, record := range records {
+		if err = crosschain.ResolveCanonicalDelivery(e.storage, record); err != nil {
+			return nil, err
+		}
+	}
+
+	recordsBytes, err := json.Marshal(records)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal inbound message records to JSON. Cause: %w", err)
+	}
+	// The audit trail covers every user's deposits, so it is only disclosed to the operator of the node.
+	return e.rpcEncryptionManager.EncryptWithViewingKey(e.config.HostID, recordsBytes)
+}
+
+func (e *enclaveImpl) GetInboundMessageDelivery(sender gethcommon.Address, sequence uint64) (*common.InboundMessageDelivery, error) {
+	record, err := e.storage.GetInboundMessageRecord(sender, sequence)
+	if err != nil {
+		if errors.Is(err, errutil.ErrNotFound) {
+			return nil, nil //nolint:nilnil
+		}
+		return nil, fmt.Errorf("could not retrieve inbound message record. Cause: %w", err)
+	}
+	if err = crosschain.ResolveCanonicalDelivery(e.storage, record); err != nil {
+		return nil, err
+	}
+	return record.Delivery(), nil
+}
+
+// Marks the withdrawals as published if the batch was rolled up in a block on the canonical L1 chain. If the batch was
+// only rolled up on L1 forks, it will be rolled up again.
+func (e *enclaveImpl) markWithdrawalsPublished(batch *core.Batch, proofs []*common.WithdrawalProof) error {
+	rollup, l1BlockHash, err := e.storage.FetchRollupForBatch(*batch.Hash())
+	if err != nil {
+		if errors.Is(err, errutil.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("could not retrieve rollup containing batch. Cause: %w", err)
+	}
+	l1Block, err := e.storage.FetchBlock(*l1BlockHash)
+	if err != nil {
+		return fmt.Errorf("could not retrieve L1 block containing rollup. Cause: %w", err)
+	}
+	l1Head, err := e.storage.FetchHeadBlock()
+	if err != nil {
+		return fmt.Errorf("could not retrieve L1 head. Cause: %w", err)
+	}
+	for _, proof := range proofs {
+		crosschain.MarkPublished(proof, rollup, l1Block, l1Head)
+	}
+	return nil
+}
+
 func (e *enclaveImpl) Attestation() (*common.AttestationReport, error) {
 	if e.enclavePubKey == nil {
 		e.logger.Error("public key not initialized, we can't produce the attestation report")
 		return nil, fmt.Errorf("public key not initialized, we can't produce the attestation report")
 	}
-	report, err := e.attestationProvider.GetReport(e.enclavePubKey, e.config.HostID, e.config.HostAddress)
+	// The RPC key included in the report is that of the latest epoch of the network secret.
+	report, err := e.attestationProvider.GetReport(e.enclavePubKey, e.rpcEncryptionManager.EnclavePublicKey(), e.config.HostID, e.config.HostAddress)
 	if err != nil {
 		e.logger.Error("could not produce remote report")
 		return nil, fmt.Errorf("could not produce remote report")
@@ -570,7 +925,10 @@ func (e *enclaveImpl) GenerateSecret() (common.EncryptedSharedEnclaveSecret, err
 	if err != nil {
 		return nil, fmt.Errorf("could not store secret. Cause: %w", err)
 	}
-	encSec, err := crypto.EncryptSecret(e.enclavePubKey, secret, e.logger)
+	if _, err = e.crossChainProcessors.Local.DeriveOwner(secret); err != nil {
+		return nil, err
+	}
+	encSec, err := crypto.EncryptEpochSecrets(e.enclavePubKey, []crypto.SharedEnclaveSecret{secret})
 	if err != nil {
 		e.logger.Error("failed to encrypt secret.", log.ErrKey, err)
 		return nil, fmt.Errorf("failed to encrypt secret. Cause: %w", err)
@@ -578,38 +936,50 @@ func (e *enclaveImpl) GenerateSecret() (common.EncryptedSharedEnclaveSecret, err
 	return encSec, nil
 }
 
-// InitEnclave - initialise an enclave with a seed received by another enclave
+// InitEnclave - initialise an enclave with the secrets of every epoch, received from another enclave
 func (e *enclaveImpl) InitEnclave(s common.EncryptedSharedEnclaveSecret) error {
-	secret, err := crypto.DecryptSecret(s, e.enclaveKey)
+	secrets, err := crypto.DecryptEpochSecrets(s, e.enclaveKey)
 	if err != nil {
 		return err
 	}
-	err = e.storage.StoreSecret(*secret)
-	if err != nil {
-		return fmt.Errorf("could not store secret. Cause: %w", err)
+	if err = e.storeEpochSecrets(secrets); err != nil {
+		return err
+	}
+	// The message bus owner is part of the genesis state, so it is always derived from the secret of epoch 0.
+	if _, err = e.crossChainProcessors.Local.DeriveOwner(secrets[0]); err != nil {
+		return err
 	}
-	e.logger.Trace(fmt.Sprintf("Secret decrypted and stored. Secret: %v", secret))
+	e.logger.Trace(fmt.Sprintf("Secrets of %d epochs decrypted and stored.", len(secrets)))
 	return nil
 }
 
-// ShareSecret verifies the request and if it trusts the report and the public key it will return the secret encrypted with that public key.
-func (e *enclaveImpl) verifyAttestationAndEncryptSecret(att *common.AttestationReport) (common.EncryptedSharedEnclaveSecret, error) {
+// ShareSecret verifies the request and if it trusts the report and the public key it will return the secrets of the epochs
+// published on the L1 chain ending in the given block, encrypted with that public key.
+func (e *enclaveImpl) verifyAttestationAndEncryptSecret(att *common.AttestationReport, l1Block common.L1RootHash) (common.EncryptedSharedEnclaveSecret, error) {
 	// First we verify the attestation report has come from a valid obscuro enclave running in a verified TEE.
 	data, err := e.attestationProvider.VerifyReport(att)
 	if err != nil {
 		return nil, fmt.Errorf("unable to verify report - %w", err)
 	}
 	// Then we verify the public key provided has come from the same enclave as that attestation report
-	if err = VerifyIdentity(data, att); err != nil {
+	if err = att.VerifyIdentity(data); err != nil {
 		return nil, fmt.Errorf("unable to verify identity - %w", err)
 	}
 	e.logger.Info(fmt.Sprintf("Successfully verified attestation and identity. Owner: %s", att.Owner))
 
-	secret, err := e.storage.FetchSecret()
+	revoked, err := e.storage.IsAttestationRevoked(att.Owner, l1Block)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, fmt.Errorf("attestation of %s was revoked", att.Owner)
+	}
+
+	secrets, err := e.epochSecretsAt(l1Block)
 	if err != nil {
-		return nil, fmt.Errorf("could not retrieve secret; this should not happen. Cause: %w", err)
+		return nil, fmt.Errorf("could not retrieve secrets; this should not happen. Cause: %w", err)
 	}
-	return crypto.EncryptSecret(att.PubKey, *secret, e.logger)
+	return crypto.EncryptEpochSecrets(att.PubKey, secrets)
 }
 
 func (e *enclaveImpl) AddViewingKey(encryptedViewingKeyBytes []byte, signature []byte) error {
@@ -681,6 +1051,36 @@ func (e *enclaveImpl) GetCode(address gethcommon.Address, batchHash *common.L2Ro
 	return stateDB.GetCode(address), nil
 }
 
+func (e *enclaveImpl) GetPublicStorageAt(address gethcommon.Address, slot gethcommon.Hash, batchHash *common.L2RootHash) (gethcommon.Hash, error) {
+	return e.chain.GetPublicStorageAt(address, slot, *batchHash)
+}
+
+func (e *enclaveImpl) GetStorageAt(encryptedParams common.EncryptedParamsGetStorageAt) (common.EncryptedResponseGetStorageAt, error) {
+	paramBytes, err := e.rpcEncryptionManager.DecryptBytes(encryptedParams)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt params in obscuro_getStorageAt request. Cause: %w", err)
+	}
+	address, slot, blockNumber, requester, err := rpc.ExtractStorageAtParams(paramBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := e.chain.GetStorageAt(requester, address, slot, blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get storage - %w", err)
+	}
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal storage value to JSON. Cause: %w", err)
+	}
+
+	encryptedValue, err := e.rpcEncryptionManager.EncryptWithViewingKey(requester, valueJSON)
+	if err != nil {
+		return nil, fmt.Errorf("enclave could not respond securely to obscuro_getStorageAt request. Cause: %w", err)
+	}
+	return encryptedValue, nil
+}
+
 func (e *enclaveImpl) Subscribe(id gethrpc.ID, encryptedSubscription common.EncryptedParamsLogSubscription) error {
 	return e.subscriptionManager.AddSubscription(id, encryptedSubscription)
 }
@@ -964,9 +1364,25 @@ func (e *enclaveImpl) checkGas(tx *types.Transaction) error {
 	return nil
 }
 
-// processNetworkSecretMsgs we watch for all messages that are requesting or receiving the secret and we store the nodes attested keys
-func (e *enclaveImpl) processNetworkSecretMsgs(block types.Block) []*common.ProducedSecretResponse {
+// processNetworkSecretMsgs we watch for all messages that are requesting, receiving or rotating the secret and we store the nodes attested keys
+func (e *enclaveImpl) processNetworkSecretMsgs(block types.Block) ([]*common.ProducedSecretResponse, *common.SecretRotation) {
 	var responses []*common.ProducedSecretResponse
+	var rotation *common.SecretRotation
+
+	// The allow-list in force depends on the L1 fork, so it is loaded again unless the block follows the last one.
+	if _, inForceAt := e.attestationPolicy.currentAllowList(); inForceAt != block.ParentHash() {
+		if err := loadAttestationAllowList(e.storage, block.ParentHash(), e.attestationPolicy); err != nil {
+			e.logger.Error("Could not load the attestation allow-list in force.", log.ErrKey, err)
+		}
+	}
+	defer func() {
+		allowList, _ := e.attestationPolicy.currentAllowList()
+		e.attestationPolicy.setAllowList(allowList, block.Hash())
+		if err := e.setLatestEpoch(block.Hash()); err != nil && !errors.Is(err, errutil.ErrNotFound) {
+			e.logger.Error("Could not set the latest epoch of the network secret.", log.ErrKey, err)
+		}
+	}()
+
 	for _, tx := range block.Transactions() {
 		t := e.mgmtContractLib.DecodeTx(tx)
 
@@ -974,7 +1390,7 @@ func (e *enclaveImpl) processNetworkSecretMsgs(block types.Block) []*common.Prod
 		if scrtReqTx, ok := t.(*ethadapter.L1RequestSecretTx); ok {
 			e.logger.Info(fmt.Sprintf("Process shared secret request. Block: %d. TxKey: %d",
 				block.NumberU64(), common.ShortHash(tx.Hash())))
-			resp, err := e.processSecretRequest(scrtReqTx)
+			resp, err := e.processSecretRequest(scrtReqTx, block.Hash())
 			if err != nil {
 				e.logger.Error("Failed to process shared secret request.", log.ErrKey, err)
 				continue
@@ -982,6 +1398,27 @@ func (e *enclaveImpl) processNetworkSecretMsgs(block types.Block) []*common.Prod
 			responses = append(responses, resp)
 		}
 
+		if rotationReqTx, ok := t.(*ethadapter.L1RequestSecretRotationTx); ok {
+			producedRotation, err := e.processSecretRotationRequest(rotationReqTx, block.Hash())
+			if err != nil {
+				e.logger.Warn("Secret rotation request was not processed.", log.ErrKey, err)
+			} else if producedRotation != nil {
+				rotation = producedRotation
+			}
+		}
+
+		if rotationTx, ok := t.(*ethadapter.L1SecretRotationTx); ok {
+			if err := e.processSecretRotation(rotationTx, block.Hash()); err != nil {
+				e.logger.Warn("Secret rotation was not applied.", log.ErrKey, err)
+			}
+		}
+
+		if allowListTx, ok := t.(*ethadapter.L1SetAttestationAllowListTx); ok {
+			if err := e.processAttestationAllowList(allowListTx, block.Hash()); err != nil {
+				e.logger.Warn("Attestation allow-list was not applied.", log.ErrKey, err)
+			}
+		}
+
 		// this transaction was created by the genesis node, we need to store their attested key to decrypt their rollup
 		if initSecretTx, ok := t.(*ethadapter.L1InitializeSecretTx); ok {
 			// TODO - Ensure that we don't accidentally skip over the real `L1InitializeSecretTx` message. Otherwise
@@ -998,17 +1435,36 @@ func (e *enclaveImpl) processNetworkSecretMsgs(block types.Block) []*common.Prod
 			}
 		}
 	}
-	return responses
+	return responses, rotation
 }
 
-func (e *enclaveImpl) processSecretRequest(req *ethadapter.L1RequestSecretTx) (*common.ProducedSecretResponse, error) {
+// Puts the allow-list published in the management contract in the given L1 block in force, and stores it so that it is
+// in force again after a restart, and whenever the block is on the L1 chain.
+func (e *enclaveImpl) processAttestationAllowList(tx *ethadapter.L1SetAttestationAllowListTx, l1Block common.L1RootHash) error {
+	allowList, err := common.DecodeAttestationAllowList(tx.AllowList)
+	if err != nil {
+		return err
+	}
+	current, _ := e.attestationPolicy.currentAllowList()
+	if err = e.attestationPolicy.verifyAllowList(allowList, current); err != nil {
+		return err
+	}
+	if err = e.storage.StoreAttestationAllowList(l1Block, allowList); err != nil {
+		return fmt.Errorf("could not store attestation allow-list. Cause: %w", err)
+	}
+	e.attestationPolicy.setAllowList(allowList, l1Block)
+	e.logger.Info(fmt.Sprintf("Applied attestation allow-list version %d.", allowList.Version))
+	return nil
+}
+
+func (e *enclaveImpl) processSecretRequest(req *ethadapter.L1RequestSecretTx, l1Block common.L1RootHash) (*common.ProducedSecretResponse, error) {
 	att, err := common.DecodeAttestation(req.Attestation)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode attestation - %w", err)
 	}
 
 	e.logger.Info("received attestation", "attestation", att)
-	secret, err := e.verifyAttestationAndEncryptSecret(att)
+	secret, err := e.verifyAttestationAndEncryptSecret(att, l1Block)
 	if err != nil {
 		return nil, fmt.Errorf("secret request failed, no response will be published - %w", err)
 	}
@@ -1080,10 +1536,10 @@ func (e *enclaveImpl) removeOldMempoolTxs(batchHeader *common.BatchHeader) error
 	return nil
 }
 
-func (e *enclaveImpl) produceBlockSubmissionResponse(block *types.Block, l2Head *common.L2RootHash, producedBatch *core.Batch) *common.BlockSubmissionResponse {
+func (e *enclaveImpl) produceBlockEvent(l2Head *common.L2RootHash, producedBatch *core.Batch) *common.EnclaveEvent {
 	if l2Head == nil {
 		// not an error state, we ingested a block but no rollup head found
-		return &common.BlockSubmissionResponse{}
+		return &common.EnclaveEvent{}
 	}
 
 	var producedExtBatch *common.ExtBatch
@@ -1091,22 +1547,30 @@ func (e *enclaveImpl) produceBlockSubmissionResponse(block *types.Block, l2Head
 		producedExtBatch = producedBatch.ToExtBatch(e.transactionBlobCrypto)
 	}
 
-	return &common.BlockSubmissionResponse{
+	// If the head batch was already processed through SubmitBatch or CreateBatch, its logs have already been pushed.
+	return &common.EnclaveEvent{
 		ProducedBatch:  producedExtBatch,
-		SubscribedLogs: e.getEncryptedLogs(*block, l2Head),
+		SubscribedLogs: e.getEncryptedLogs(*l2Head),
 	}
 }
 
-// Retrieves and encrypts the logs for the block.
-func (e *enclaveImpl) getEncryptedLogs(block types.Block, l2Head *common.L2RootHash) map[gethrpc.ID][]byte {
+// Retrieves and encrypts the logs of the batch for each subscription, unless they were already pushed on the event
+// stream.
+func (e *enclaveImpl) getEncryptedLogs(batchHash common.L2RootHash) map[gethrpc.ID][]byte {
+	if !e.pushedLogs.record(batchHash) {
+		return nil
+	}
+
 	var logs []*types.Log
-	fetchedLogs, err := e.storage.FetchLogs(block.Hash())
+	receipts, err := e.storage.GetReceiptsByHash(batchHash)
 	if err == nil {
-		logs = fetchedLogs
+		for _, receipt := range receipts {
+			logs = append(logs, receipt.Logs...)
+		}
 	} else {
-		e.logger.Error("Could not retrieve logs for stored block state; returning no logs. Cause: %w", err)
+		e.logger.Error("Could not retrieve receipts for batch; returning no logs.", log.ErrKey, err)
 	}
-	encryptedLogs, err := e.subscriptionManager.GetSubscribedLogsEncrypted(logs, *l2Head)
+	encryptedLogs, err := e.subscriptionManager.GetSubscribedLogsEncrypted(logs, batchHash)
 	if err != nil {
 		e.logger.Crit("Could not get subscribed logs in encrypted form. ", log.ErrKey, err)
 	}
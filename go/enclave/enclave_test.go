@@ -3,6 +3,7 @@ package enclave
 import (
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"testing"
@@ -19,10 +20,13 @@ import (
 	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/obscuronet/go-obscuro/contracts/generated/ManagementContract"
+	"github.com/obscuronet/go-obscuro/contracts/generated/MessageBus"
 	"github.com/obscuronet/go-obscuro/go/common"
 	"github.com/obscuronet/go-obscuro/go/common/log"
 	"github.com/obscuronet/go-obscuro/go/config"
 	"github.com/obscuronet/go-obscuro/go/enclave/core"
+	"github.com/obscuronet/go-obscuro/go/enclave/crosschain"
+	"github.com/obscuronet/go-obscuro/go/enclave/l2chain"
 	"github.com/obscuronet/go-obscuro/go/obsclient"
 	"github.com/obscuronet/go-obscuro/go/rpc"
 	"github.com/obscuronet/go-obscuro/go/wallet"
@@ -491,7 +495,7 @@ func createTestEnclave(prefundedAddresses []genesis.Account) (common.Enclave, er
 func createFakeGenesis(enclave common.Enclave, addresses []genesis.Account) error {
 	// Random Layer 1 block where the genesis rollup is set
 	blk := types.NewBlock(&types.Header{}, nil, nil, nil, &trie.StackTrie{})
-	_, err := enclave.SubmitL1Block(*blk, make(types.Receipts, 0), true)
+	err := enclave.SubmitL1Block(*blk, make(types.Receipts, 0), true)
 	if err != nil {
 		return err
 	}
@@ -518,13 +522,13 @@ func createFakeGenesis(enclave common.Enclave, addresses []genesis.Account) erro
 	}
 
 	// We update the database
-	if err = enclave.(*enclaveImpl).storage.StoreRollup(genesisRollup); err != nil {
+	blockHash := blk.Hash()
+	if err = enclave.(*enclaveImpl).storage.StoreRollup(genesisRollup, blockHash); err != nil {
 		return err
 	}
 	if err = enclave.(*enclaveImpl).storage.StoreBatch(genesisBatch, nil); err != nil {
 		return err
 	}
-	blockHash := blk.Hash()
 	if err = enclave.(*enclaveImpl).storage.UpdateHeadRollup(&blockHash, genesisRollup.Hash()); err != nil {
 		return err
 	}
@@ -550,7 +554,7 @@ func injectNewBlockAndChangeBalance(enclave common.Enclave, funds []genesis.Acco
 			Number:     big.NewInt(0).Add(headBlock.Number(), big.NewInt(1)),
 			ParentHash: headBlock.Hash(),
 		}, nil, nil, nil, &trie.StackTrie{})
-	_, err = enclave.SubmitL1Block(*blk, make(types.Receipts, 0), true)
+	err = enclave.SubmitL1Block(*blk, make(types.Receipts, 0), true)
 	if err != nil {
 		return err
 	}
@@ -581,13 +585,13 @@ func injectNewBlockAndChangeBalance(enclave common.Enclave, funds []genesis.Acco
 	}
 
 	// We update the database.
-	if err = enclave.(*enclaveImpl).storage.StoreRollup(rollup); err != nil {
+	blockHash := blk.Hash()
+	if err = enclave.(*enclaveImpl).storage.StoreRollup(rollup, blockHash); err != nil {
 		return err
 	}
 	if err = enclave.(*enclaveImpl).storage.StoreBatch(batch, nil); err != nil {
 		return err
 	}
-	blockHash := blk.Hash()
 	if err = enclave.(*enclaveImpl).storage.UpdateHeadRollup(&blockHash, rollup.Hash()); err != nil {
 		return err
 	}
@@ -626,3 +630,393 @@ func dummyBatch(blkHash gethcommon.Hash, height uint64, state *state.StateDB) *c
 		Transactions: []*common.L2Tx{},
 	}
 }
+
+func TestCreateBatch(t *testing.T) {
+	enclaveConfig := config.EnclaveConfig{
+		HostID:           gethcommon.BigToAddress(big.NewInt(0)),
+		NodeType:         common.Sequencer,
+		L1ChainID:        integration.EthereumChainID,
+		ObscuroChainID:   integration.ObscuroChainID,
+		UseInMemoryDB:    true,
+		MinGasPrice:      big.NewInt(1),
+		TimeBasedBatches: true,
+	}
+	logger := log.New(log.TestLogCmp, int(gethlog.LvlError), log.SysOut)
+	enclave := NewEnclave(enclaveConfig, &genesis.TestnetGenesis, nil, logger).(*enclaveImpl)
+	if _, err := enclave.GenerateSecret(); err != nil {
+		t.Fatal(err)
+	}
+	if timeBased, err := enclave.TimeBasedBatches(); err != nil || !timeBased {
+		t.Fatalf("expected the enclave to report time-based batches. Cause: %s", err)
+	}
+
+	// No batch is produced before the enclave has ingested an L1 block, nor when an L1 block is ingested.
+	if err := enclave.CreateBatch(false); err != nil {
+		t.Fatal(err)
+	}
+	blk := types.NewBlock(&types.Header{}, nil, nil, nil, &trie.StackTrie{})
+	if err := enclave.SubmitL1Block(*blk, make(types.Receipts, 0), true); err != nil {
+		t.Fatal(err)
+	}
+	assertHeadBatch(t, enclave, nil)
+
+	// The genesis batch is produced even if there is nothing pending.
+	if err := enclave.CreateBatch(true); err != nil {
+		t.Fatal(err)
+	}
+	assertHeadBatch(t, enclave, big.NewInt(0))
+
+	// The genesis batch leaves the message bus deployment pending.
+	if err := enclave.CreateBatch(true); err != nil {
+		t.Fatal(err)
+	}
+	assertHeadBatch(t, enclave, big.NewInt(1))
+
+	// Empty batches are only produced if requested.
+	if err := enclave.CreateBatch(true); err != nil {
+		t.Fatal(err)
+	}
+	assertHeadBatch(t, enclave, big.NewInt(1))
+	if err := enclave.CreateBatch(false); err != nil {
+		t.Fatal(err)
+	}
+	assertHeadBatch(t, enclave, big.NewInt(2))
+
+	// A pending transaction is pending work, whether or not it succeeds.
+	w := datagenerator.RandomWallet(integration.ObscuroChainID)
+	tx, err := w.SignTransaction(datagenerator.CreateL2TxData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = enclave.mempool.AddMempoolTx(tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := enclave.CreateBatch(true); err != nil {
+		t.Fatal(err)
+	}
+	assertHeadBatch(t, enclave, big.NewInt(3))
+
+	// Batches are only produced on request if the enclave is a sequencer configured for time-based batches.
+	enclave.config.TimeBasedBatches = false
+	if err := enclave.CreateBatch(false); err == nil {
+		t.Fatal("expected batch request to be rejected")
+	}
+}
+
+func assertHeadBatch(t *testing.T, enclave *enclaveImpl, number *big.Int) {
+	t.Helper()
+	head, err := enclave.storage.FetchHeadBatch()
+	if number == nil {
+		if err == nil {
+			t.Fatalf("expected no head batch, got batch %d", head.Number())
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("expected head batch %d. Cause: %s", number, err)
+	}
+	if head.Number().Cmp(number) != 0 {
+		t.Fatalf("expected head batch %d, got %d", number, head.Number())
+	}
+}
+
+func TestGetWithdrawalProofs(t *testing.T) {
+	w := datagenerator.RandomWallet(integration.ObscuroChainID)
+	enclave, err := createTestEnclave([]genesis.Account{{Address: w.Address(), Amount: big.NewInt(100_000_000_000_000)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vk, err := registerWalletViewingKey(t, enclave, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An unknown transaction has no withdrawals.
+	if proofs := getWithdrawalProofs(t, enclave, vk, gethcommon.Hash{}); proofs != nil {
+		t.Fatalf("expected no withdrawal proofs for unknown transaction, got %v", proofs)
+	}
+
+	tx, err := w.SignTransaction(datagenerator.CreateL2TxData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := MessageBus.MessageBusLogMessagePublished{Sender: w.Address(), Sequence: 1, Nonce: 2, Topic: 3, Payload: []byte{4}}
+	l1Block, err := injectBatchPublishingMessage(enclave.(*enclaveImpl), tx, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proofs := getWithdrawalProofs(t, enclave, vk, tx.Hash())
+	if len(proofs) != 1 {
+		t.Fatalf("expected a single withdrawal proof, got %d", len(proofs))
+	}
+	proof := proofs[0]
+	if proof.TxHash != tx.Hash() || proof.Message.Sender != message.Sender || proof.Message.Sequence != message.Sequence {
+		t.Fatal("withdrawal proof did not match the published message")
+	}
+	if proof.Status == common.WithdrawalPending || proof.L1BlockHash == nil || *proof.L1BlockHash != l1Block.Hash() {
+		t.Fatal("expected withdrawal to be published in the L1 block containing the rollup")
+	}
+}
+
+func getWithdrawalProofs(t *testing.T, enclave common.Enclave, vk *rpc.ViewingKey, txHash gethcommon.Hash) []*common.WithdrawalProof {
+	t.Helper()
+	reqBytes, err := json.Marshal([]string{txHash.Hex()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedParams, err := ecies.Encrypt(rand.Reader, _enclavePubKey, reqBytes, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedProofs, err := enclave.GetWithdrawalProofs(encryptedParams)
+	if err != nil {
+		t.Fatalf("could not get withdrawal proofs. Cause: %s", err)
+	}
+	if encryptedProofs == nil {
+		return nil
+	}
+	proofsBytes, err := vk.PrivateKey.Decrypt(encryptedProofs, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var proofs []*common.WithdrawalProof
+	if err = json.Unmarshal(proofsBytes, &proofs); err != nil {
+		t.Fatal(err)
+	}
+	return proofs
+}
+
+// injectBatchPublishingMessage stores a batch containing the transaction, whose receipt logs the message on the L2 message
+// bus, and a rollup of the batch published in a new L1 head.
+func injectBatchPublishingMessage(enclave *enclaveImpl, tx *common.L2Tx, message MessageBus.MessageBusLogMessagePublished) (*types.Block, error) {
+	headBlock, err := enclave.storage.FetchHeadBlock()
+	if err != nil {
+		return nil, err
+	}
+	headBatch, err := enclave.storage.FetchHeadBatch()
+	if err != nil {
+		return nil, err
+	}
+	blk := types.NewBlock(&types.Header{
+		Number:     big.NewInt(0).Add(headBlock.Number(), big.NewInt(1)),
+		ParentHash: headBlock.Hash(),
+	}, nil, nil, nil, &trie.StackTrie{})
+	enclave.storage.StoreBlock(blk)
+
+	stateDB, err := enclave.storage.CreateStateDB(*headBatch.Hash())
+	if err != nil {
+		return nil, err
+	}
+	batch := dummyBatch(blk.Hash(), headBatch.NumberU64()+1, stateDB)
+	batch.Transactions = []*common.L2Tx{tx}
+
+	data, err := crosschain.MessageBusABI.Events[crosschain.CrossChainEventName].Inputs.NonIndexed().Pack(
+		message.Sender, message.Sequence, message.Nonce, message.Topic, message.Payload, message.ConsistencyLevel)
+	if err != nil {
+		return nil, err
+	}
+	receipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		Logs: []*types.Log{{
+			Address: *enclave.crossChainProcessors.Local.GetBusAddress(),
+			Topics:  []gethcommon.Hash{crosschain.CrossChainEventID},
+			Data:    data,
+		}},
+	}
+	rollup := &core.Rollup{Header: batch.Header.ToRollupHeader(), Batches: []*core.Batch{batch}}
+
+	blockHash := blk.Hash()
+	if err = enclave.storage.StoreBatch(batch, types.Receipts{receipt}); err != nil {
+		return nil, err
+	}
+	if err = enclave.storage.StoreRollup(rollup, blockHash); err != nil {
+		return nil, err
+	}
+	if err = enclave.storage.UpdateHeadBatch(blockHash, batch, types.Receipts{receipt}); err != nil {
+		return nil, err
+	}
+	return blk, enclave.storage.UpdateL1Head(blockHash)
+}
+
+func TestDebugTraces(t *testing.T) {
+	w := datagenerator.RandomWallet(integration.ObscuroChainID)
+	enclave := createTimeBasedSequencerEnclave(t, w)
+	vk, err := registerWalletViewingKey(t, enclave, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to := datagenerator.RandomAddress()
+	tx, err := w.SignTransaction(&types.LegacyTx{Nonce: 0, Gas: 21_000, GasPrice: big.NewInt(1), To: &to, Value: big.NewInt(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	includeInBatch(t, enclave, tx)
+
+	// The trace of a transaction is encrypted for its sender.
+	encryptedTrace, err := enclave.DebugTraceTransaction(encryptParams(t, []interface{}{tx.Hash(), map[string]string{"tracer": "callTracer"}}))
+	if err != nil {
+		t.Fatalf("could not trace transaction. Cause: %s", err)
+	}
+	assertCallTrace(t, vk, encryptedTrace, w.Address(), to)
+
+	// The trace of a call is encrypted for its sender, and only the call tracer can trace a call.
+	call := map[string]string{"from": w.Address().Hex(), "to": to.Hex(), "value": "0x1"}
+	encryptedTrace, err = enclave.DebugTraceCall(encryptParams(t, []interface{}{call, "latest"}))
+	if err != nil {
+		t.Fatalf("could not trace call. Cause: %s", err)
+	}
+	assertCallTrace(t, vk, encryptedTrace, w.Address(), to)
+	if _, err = enclave.DebugTraceCall(encryptParams(t, []interface{}{call, "latest", map[string]string{"tracer": "prestateTracer"}})); err == nil {
+		t.Fatal("expected the prestate tracer to be rejected for a call")
+	}
+
+	enclave.config.DebugNamespaceEnabled = false
+	if _, err = enclave.DebugTraceTransaction(encryptParams(t, []interface{}{tx.Hash()})); !errors.Is(err, errDebugNamespaceDisabled) {
+		t.Fatalf("expected traces to be rejected when the debug namespace is disabled, got %v", err)
+	}
+	if _, err = enclave.DebugTraceCall(encryptParams(t, []interface{}{call, "latest"})); !errors.Is(err, errDebugNamespaceDisabled) {
+		t.Fatalf("expected traces to be rejected when the debug namespace is disabled, got %v", err)
+	}
+}
+
+// A contract whose slot 0 holds 0x2a and is declared public, whose slot 1 holds 0x2b, and whose slot 2 holds its
+// deployer. `publicStorageSlots()` returns [slot 0], and `canReadStorage(address,bytes32)` only authorises the deployer.
+var publicSlotContractCode = gethcommon.Hex2Bytes(
+	"602a600055602b60015533600255" + "6042601a600039" + "60426000f3" + // constructor
+		"60003560e01c" + "8063c81ef27d14603257" + "6369e7414814601d57" + "600080fd" + // selector dispatch
+		"5b" + "6020600052" + "6001602052" + "6000604052" + "60606000f3" + // publicStorageSlots()
+		"5b" + "600435" + "600254" + "14" + "600052" + "60206000f3", // canReadStorage(address,bytes32)
+)
+
+func TestStorageVisibility(t *testing.T) {
+	deployerWallet := datagenerator.RandomWallet(integration.ObscuroChainID)
+	enclave := createTimeBasedSequencerEnclave(t, deployerWallet)
+	deployerVK, err := registerWalletViewingKey(t, enclave, deployerWallet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherVK, err := registerWalletViewingKey(t, enclave, datagenerator.RandomWallet(integration.ObscuroChainID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deployTx, err := deployerWallet.SignTransaction(&types.LegacyTx{Nonce: 0, Gas: 200_000, GasPrice: big.NewInt(1), Data: publicSlotContractCode})
+	if err != nil {
+		t.Fatal(err)
+	}
+	includeInBatch(t, enclave, deployTx)
+	contract := crypto.CreateAddress(deployerWallet.Address(), 0)
+	headBatch, err := enclave.storage.FetchHeadBatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Anyone can read the public slot, but not the private one.
+	value, err := enclave.GetPublicStorageAt(contract, gethcommon.BigToHash(big.NewInt(0)), headBatch.Hash())
+	if err != nil || value != gethcommon.BigToHash(big.NewInt(0x2a)) {
+		t.Fatalf("expected the value of the public slot, got %s. Cause: %v", value, err)
+	}
+	if _, err = enclave.GetPublicStorageAt(contract, gethcommon.BigToHash(big.NewInt(1)), headBatch.Hash()); !errors.Is(err, l2chain.ErrPrivateStorageSlot) {
+		t.Fatalf("expected the private slot to be refused, got %v", err)
+	}
+
+	// Only the account the contract authorises can read the private slot.
+	privateSlot := gethcommon.BigToHash(big.NewInt(1))
+	otherAddress := *otherVK.Account
+	if _, err = enclave.GetStorageAt(encryptParams(t, []interface{}{contract, privateSlot, "latest", otherAddress})); !errors.Is(err, l2chain.ErrUnreadableStorageSlot) {
+		t.Fatalf("expected the private slot to be refused to an unauthorised account, got %v", err)
+	}
+	encryptedValue, err := enclave.GetStorageAt(encryptParams(t, []interface{}{contract, privateSlot, "latest", deployerWallet.Address()}))
+	if err != nil {
+		t.Fatalf("could not get storage. Cause: %s", err)
+	}
+	if _, err = otherVK.PrivateKey.Decrypt(encryptedValue, nil, nil); err == nil {
+		t.Fatal("expected the value of the private slot to be encrypted for the authorised account only")
+	}
+	valueJSON, err := deployerVK.PrivateKey.Decrypt(encryptedValue, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = json.Unmarshal(valueJSON, &value); err != nil {
+		t.Fatal(err)
+	}
+	if value != gethcommon.BigToHash(big.NewInt(0x2b)) {
+		t.Fatalf("expected the value of the private slot, got %s", value)
+	}
+}
+
+// Returns a sequencer's enclave that produces batches on request, with the genesis batch produced and the wallet
+// prefunded.
+func createTimeBasedSequencerEnclave(t *testing.T, w wallet.Wallet) *enclaveImpl {
+	t.Helper()
+	enclaveConfig := config.EnclaveConfig{
+		HostID:                gethcommon.BigToAddress(big.NewInt(0)),
+		NodeType:              common.Sequencer,
+		L1ChainID:             integration.EthereumChainID,
+		ObscuroChainID:        integration.ObscuroChainID,
+		UseInMemoryDB:         true,
+		MinGasPrice:           big.NewInt(1),
+		TimeBasedBatches:      true,
+		DebugNamespaceEnabled: true,
+	}
+	logger := log.New(log.TestLogCmp, int(gethlog.LvlError), log.SysOut)
+	obsGenesis := &genesis.Genesis{Accounts: []genesis.Account{{Address: w.Address(), Amount: big.NewInt(100_000_000_000_000)}}}
+	enclave := NewEnclave(enclaveConfig, obsGenesis, nil, logger).(*enclaveImpl)
+	if _, err := enclave.GenerateSecret(); err != nil {
+		t.Fatal(err)
+	}
+	blk := types.NewBlock(&types.Header{}, nil, nil, nil, &trie.StackTrie{})
+	if err := enclave.SubmitL1Block(*blk, make(types.Receipts, 0), true); err != nil {
+		t.Fatal(err)
+	}
+	if err := enclave.CreateBatch(true); err != nil {
+		t.Fatal(err)
+	}
+	return enclave
+}
+
+func includeInBatch(t *testing.T, enclave *enclaveImpl, tx *common.L2Tx) {
+	t.Helper()
+	if err := enclave.mempool.AddMempoolTx(tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := enclave.CreateBatch(true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func encryptParams(t *testing.T, params []interface{}) []byte {
+	t.Helper()
+	reqBytes, err := json.Marshal(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedParams, err := ecies.Encrypt(rand.Reader, _enclavePubKey, reqBytes, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encryptedParams
+}
+
+func assertCallTrace(t *testing.T, vk *rpc.ViewingKey, encryptedTrace []byte, from gethcommon.Address, to gethcommon.Address) {
+	t.Helper()
+	traceBytes, err := vk.PrivateKey.Decrypt(encryptedTrace, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var frame struct {
+		Type  string
+		From  gethcommon.Address
+		To    gethcommon.Address
+		Value string
+		Error string
+	}
+	if err = json.Unmarshal(traceBytes, &frame); err != nil {
+		t.Fatal(err)
+	}
+	if frame.Type != "CALL" || frame.From != from || frame.To != to || frame.Value != "0x1" || frame.Error != "" {
+		t.Fatalf("expected the trace of the transfer, got %s", traceBytes)
+	}
+}
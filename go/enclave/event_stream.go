@@ -0,0 +1,143 @@
+package enclave
+
+import (
+	"sync"
+	"time"
+
+	"github.com/obscuronet/go-obscuro/go/common"
+
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+// The number of events the enclave buffers until the host acknowledges them. If the host falls further behind, for
+// example because it is down, the oldest events are dropped rather than holding up the enclave.
+const eventBufferSize = 1024
+
+// eventStreams buffers the enclave's events until the host acknowledges them, and pushes them to each of the host's
+// open event streams. The host replays the events it has not handled yet when it reopens its stream.
+type eventStreams struct {
+	events    []*common.EnclaveEvent // the unacknowledged events, in order of sequence number
+	nextSeq   uint64
+	published chan struct{} // closed, and replaced, whenever an event is published
+	mutex     sync.Mutex
+	logger    gethlog.Logger
+}
+
+func newEventStreams(logger gethlog.Logger) *eventStreams {
+	return &eventStreams{
+		// Sequence numbers start from the enclave's start time, so that they keep increasing across restarts of the
+		// enclave, and a host reconnecting to a restarted enclave receives all of its events.
+		nextSeq:   uint64(time.Now().UnixNano()),
+		published: make(chan struct{}),
+		logger:    logger,
+	}
+}
+
+// Buffers the event and pushes it to every open stream.
+func (s *eventStreams) publish(event *common.EnclaveEvent) {
+	if event.IsEmpty() {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	event.Sequence = s.nextSeq
+	s.nextSeq++
+	s.events = append(s.events, event)
+	if len(s.events) > eventBufferSize {
+		s.logger.Error("Host has not acknowledged enclave events. Dropping oldest event.", "sequence", s.events[0].Sequence)
+		s.events[0] = nil
+		s.events = s.events[1:]
+	}
+	close(s.published)
+	s.published = make(chan struct{})
+}
+
+// Discards the buffered events up to and including the given sequence number, which the host has handled.
+func (s *eventStreams) ack(seq uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	acked := 0
+	for acked < len(s.events) && s.events[acked].Sequence <= seq {
+		s.events[acked] = nil
+		acked++
+	}
+	s.events = s.events[acked:]
+}
+
+// Opens a new stream of the events following the one with the given sequence number, starting with the buffered ones.
+// Returns the events and a function that closes the stream.
+func (s *eventStreams) open(after uint64) (<-chan *common.EnclaveEvent, func()) {
+	events := make(chan *common.EnclaveEvent)
+	closed := make(chan struct{})
+
+	go func() {
+		for {
+			pending, published := s.eventsAfter(after)
+			for _, event := range pending {
+				select {
+				case events <- event:
+					after = event.Sequence
+				case <-closed:
+					return
+				}
+			}
+			if len(pending) > 0 {
+				continue
+			}
+			select {
+			case <-published:
+			case <-closed:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return events, func() { once.Do(func() { close(closed) }) }
+}
+
+// Returns the buffered events following the one with the given sequence number, and a channel closed when the next
+// event is published.
+func (s *eventStreams) eventsAfter(after uint64) ([]*common.EnclaveEvent, <-chan struct{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, event := range s.events {
+		if event.Sequence > after {
+			return append([]*common.EnclaveEvent{}, s.events[i:]...), s.published
+		}
+	}
+	return nil, s.published
+}
+
+// pushedLogs records the batches whose logs were pushed on the event stream, so that they are only pushed once even if
+// the batch is processed again, e.g. when it is both submitted by the host and referenced by an ingested L1 block. Only
+// the most recent batches are recorded.
+type pushedLogs struct {
+	batches map[common.L2RootHash]struct{}
+	order   []common.L2RootHash // the recorded batches, oldest first
+	mutex   sync.Mutex
+}
+
+// The number of batches whose logs are recorded as pushed.
+const pushedLogsBatches = 1024
+
+func newPushedLogs() *pushedLogs {
+	return &pushedLogs{batches: map[common.L2RootHash]struct{}{}}
+}
+
+// Records that the logs of the batch are being pushed, returning false if they already were.
+func (p *pushedLogs) record(batchHash common.L2RootHash) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, pushed := p.batches[batchHash]; pushed {
+		return false
+	}
+	p.batches[batchHash] = struct{}{}
+	p.order = append(p.order, batchHash)
+	if len(p.order) > pushedLogsBatches {
+		delete(p.batches, p.order[0])
+		p.order = p.order[1:]
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package enclave
+
+import (
+	"testing"
+	"time"
+
+	"github.com/obscuronet/go-obscuro/go/common"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+func TestEventsPublishedWithoutStreamAreReplayedUntilAcknowledged(t *testing.T) {
+	streams := newEventStreams(gethlog.New())
+	first, second := newTestEvent(), newTestEvent()
+	streams.publish(first)
+	streams.publish(second)
+	if second.Sequence <= first.Sequence {
+		t.Fatalf("expected sequence numbers to increase, got %d then %d", first.Sequence, second.Sequence)
+	}
+
+	events, closeStream := streams.open(0)
+	expectEvents(t, events, first, second)
+	closeStream()
+
+	// A host that handled the first event only reopens its stream after it, and receives the rest.
+	events, closeStream = streams.open(first.Sequence)
+	expectEvents(t, events, second)
+	third := newTestEvent()
+	streams.publish(third)
+	expectEvents(t, events, third)
+	closeStream()
+
+	// Acknowledged events are no longer buffered.
+	streams.ack(second.Sequence)
+	events, closeStream = streams.open(0)
+	defer closeStream()
+	expectEvents(t, events, third)
+}
+
+func TestEventBufferIsBounded(t *testing.T) {
+	streams := newEventStreams(gethlog.New())
+	_, closeIdleStream := streams.open(0)
+	defer closeIdleStream()
+
+	// The idle stream is never read from, so publishing must not wait for the host.
+	published := make(chan struct{})
+	go func() {
+		for i := 0; i < eventBufferSize+10; i++ {
+			streams.publish(newTestEvent())
+		}
+		close(published)
+	}()
+	select {
+	case <-published:
+	case <-time.After(10 * time.Second):
+		t.Fatal("publishing events waited for the host")
+	}
+
+	pending, _ := streams.eventsAfter(0)
+	if len(pending) != eventBufferSize {
+		t.Fatalf("expected %d buffered events, got %d", eventBufferSize, len(pending))
+	}
+	// A new stream starts from the oldest event still buffered.
+	events, closeStream := streams.open(0)
+	defer closeStream()
+	expectEvents(t, events, pending[0])
+}
+
+func TestLogsArePushedOncePerBatch(t *testing.T) {
+	logs := newPushedLogs()
+	first, second := gethcommon.HexToHash("0x01"), gethcommon.HexToHash("0x02")
+	if !logs.record(first) || !logs.record(second) {
+		t.Fatal("expected logs of new batches to be pushed")
+	}
+	if logs.record(first) {
+		t.Fatal("expected logs of a batch that is not the latest to be pushed only once")
+	}
+}
+
+func newTestEvent() *common.EnclaveEvent {
+	return &common.EnclaveEvent{RollupDecision: &common.RollupDecision{}}
+}
+
+func expectEvents(t *testing.T, events <-chan *common.EnclaveEvent, expected ...*common.EnclaveEvent) {
+	t.Helper()
+	for _, expectedEvent := range expected {
+		select {
+		case event := <-events:
+			if event != expectedEvent {
+				t.Fatalf("expected event %d, got event %d", expectedEvent.Sequence, event.Sequence)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected event %d was not streamed", expectedEvent.Sequence)
+		}
+	}
+}
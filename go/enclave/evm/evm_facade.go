@@ -25,8 +25,12 @@ import (
 // ExecuteTransactions
 // header - the header of the rollup where this transaction will be included
 // fromTxIndex - for the receipts and events, the evm needs to know for each transaction the order in which it was executed in the block.
-func ExecuteTransactions(txs []*common.L2Tx, s *state.StateDB, header *common.BatchHeader, storage db.Storage, chainConfig *params.ChainConfig, fromTxIndex int, logger gethlog.Logger) map[common.TxHash]interface{} {
+// gasLimit - the gas available to the transactions as a whole. Transactions that do not fit fail with `ErrGasLimitReached`. Zero means no limit.
+func ExecuteTransactions(txs []*common.L2Tx, s *state.StateDB, header *common.BatchHeader, storage db.Storage, chainConfig *params.ChainConfig, fromTxIndex int, gasLimit uint64, logger gethlog.Logger) map[common.TxHash]interface{} {
 	chain, vmCfg, gp := initParams(storage, true, logger)
+	if gasLimit != 0 {
+		*gp = gethcore.GasPool(gasLimit)
+	}
 	zero := uint64(0)
 	usedGas := &zero
 	result := map[common.TxHash]interface{}{}
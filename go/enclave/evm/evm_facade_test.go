@@ -0,0 +1,59 @@
+package evm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/go/enclave/chainconfig"
+	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
+	"github.com/obscuronet/go-obscuro/go/enclave/db"
+	"github.com/obscuronet/go-obscuro/integration"
+	"github.com/obscuronet/go-obscuro/integration/datagenerator"
+
+	gethcore "github.com/ethereum/go-ethereum/core"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+func TestTransactionsBeyondTheGasLimitFail(t *testing.T) {
+	logger := log.New(log.TestLogCmp, int(gethlog.LvlError), log.SysOut)
+	storage := db.NewStorage(rawdb.NewMemoryDatabase(), nil, logger)
+	if err := storage.StoreSecret(crypto.SharedEnclaveSecret{}); err != nil {
+		t.Fatalf("could not store secret. Cause: %s", err)
+	}
+	stateDB, err := storage.EmptyStateDB()
+	if err != nil {
+		t.Fatalf("could not create stateDB. Cause: %s", err)
+	}
+	header := &common.BatchHeader{Number: big.NewInt(1)}
+	chainConfig := chainconfig.DefaultEVMConfig(integration.ObscuroChainID)
+
+	// Three transfers from the same account, of which only the first two fit within the gas limit.
+	w := datagenerator.RandomWallet(integration.ObscuroChainID)
+	to := datagenerator.RandomAddress()
+	txs := make([]*common.L2Tx, 3)
+	for i := range txs {
+		txs[i], err = w.SignTransaction(&types.LegacyTx{Nonce: uint64(i), Gas: 21_000, GasPrice: big.NewInt(0), To: &to})
+		if err != nil {
+			t.Fatalf("could not sign transaction. Cause: %s", err)
+		}
+	}
+
+	results := ExecuteTransactions(txs, stateDB, header, storage, chainConfig, 0, 2*21_000, logger)
+	for _, tx := range txs[:2] {
+		if _, ok := results[tx.Hash()].(*types.Receipt); !ok {
+			t.Fatalf("expected transaction within the gas limit to succeed, got %v", results[tx.Hash()])
+		}
+	}
+	if err, ok := results[txs[2].Hash()].(error); !ok || !errors.Is(err, gethcore.ErrGasLimitReached) {
+		t.Fatalf("expected transaction beyond the gas limit to fail with %s, got %v", gethcore.ErrGasLimitReached, results[txs[2].Hash()])
+	}
+	// The failed transaction leaves the state untouched, so it can be included in a later batch.
+	if nonce := stateDB.GetNonce(w.Address()); nonce != 2 {
+		t.Fatalf("expected nonce 2 after the transactions within the gas limit, got %d", nonce)
+	}
+}
@@ -0,0 +1,248 @@
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native" // registers geth's native tracers, by name, with tracers.New
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/gethapi"
+	"github.com/obscuronet/go-obscuro/go/enclave/db"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethcore "github.com/ethereum/go-ethereum/core"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+// The time a trace can run for, if the trace config does not set a timeout. It is the same as geth's default.
+const defaultTraceTimeout = 5 * time.Second
+
+// The maximum size of the struct logs captured by a trace. Each struct log can capture a snapshot of the memory, the
+// stack and the accessed storage of the executing contract, so the logs of a single transaction could otherwise
+// exhaust the enclave's memory.
+const maxStructLogsSize = 64 * 1024 * 1024
+
+// The tracers that can be requested by name, from geth's native tracers. The JavaScript tracers are not supported,
+// since they would run code supplied by the caller inside the enclave.
+var supportedTracers = map[string]bool{
+	callTracerName:   true,
+	"prestateTracer": true,
+}
+
+// The only tracer that can trace a call. The struct logs and the prestate reveal the storage of every contract touched
+// by the call, and, unlike the sender of a transaction, the caller has not paid to execute the call.
+const callTracerName = "callTracer"
+
+// TraceTransaction re-executes the transactions that precede the transaction at txIndex in the batch, then traces the
+// execution of that transaction. The state must be the state of the batch's parent.
+func TraceTransaction(txs []*common.L2Tx, txIndex int, s *state.StateDB, header *common.BatchHeader, storage db.Storage, chainConfig *params.ChainConfig, config *tracers.TraceConfig, logger gethlog.Logger) (json.RawMessage, error) {
+	if txIndex >= len(txs) {
+		return nil, fmt.Errorf("batch has no transaction at index %d", txIndex)
+	}
+	tx := txs[txIndex]
+	ExecuteTransactions(txs[:txIndex], s, header, storage, chainConfig, 0, header.GasLimit, logger)
+
+	tracer, release, err := newTracer(config)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	chain, vmCfg, _ := initParams(storage, true, logger)
+	vmCfg.Debug = true
+	vmCfg.Tracer = tracer
+	ethHeader, err := convertToEthHeader(header, secret(storage))
+	if err != nil {
+		return nil, err
+	}
+	// The transaction was included in the batch, so it fits within the gas left in the batch.
+	gp := gethcore.GasPool(math.MaxUint64)
+	usedGas := uint64(0)
+	receipt, err := executeTransaction(s, chainConfig, chain, &gp, ethHeader, tx, &usedGas, vmCfg, txIndex)
+	if err != nil {
+		return nil, fmt.Errorf("could not trace transaction %s. Cause: %w", tx.Hash(), err)
+	}
+	return traceResult(tracer, receipt.GasUsed, receipt.Status == types.ReceiptStatusFailed, nil)
+}
+
+// TraceCall traces the execution of the call on top of the state of the batch with the given header.
+func TraceCall(msg *types.Message, s *state.StateDB, header *common.BatchHeader, storage db.Storage, chainConfig *params.ChainConfig, config *tracers.TraceConfig) (json.RawMessage, error) {
+	callConfig := tracers.TraceConfig{}
+	if config != nil {
+		callConfig = *config
+	}
+	if callConfig.Tracer == nil {
+		name := callTracerName
+		callConfig.Tracer = &name
+	}
+	if *callConfig.Tracer != callTracerName {
+		return nil, fmt.Errorf("tracer %s cannot trace a call, since it reveals contract storage; only the %s can", *callConfig.Tracer, callTracerName)
+	}
+	tracer, release, err := newTracer(&callConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	chain, vmCfg, gp := initParams(storage, true, nil)
+	vmCfg.Debug = true
+	vmCfg.Tracer = tracer
+	ethHeader, err := convertToEthHeader(header, secret(storage))
+	if err != nil {
+		return nil, err
+	}
+	blockContext := gethcore.NewEVMBlockContext(ethHeader, chain, &header.Agg)
+	vmenv := vm.NewEVM(blockContext, gethcore.NewEVMTxContext(msg), s, chainConfig, vmCfg)
+
+	result, err := gethcore.ApplyMessage(vmenv, msg, gp)
+	if err != nil {
+		return nil, fmt.Errorf("could not trace call. Cause: %w", err)
+	}
+	returnData := result.Return()
+	if len(result.Revert()) > 0 {
+		returnData = result.Revert()
+	}
+	return traceResult(tracer, result.UsedGas, result.Failed(), returnData)
+}
+
+// Returns the tracer requested by the config, which defaults to the struct logger, and a function that releases it
+// once the execution has been traced. The tracer is stopped if the trace runs for longer than the timeout.
+func newTracer(config *tracers.TraceConfig) (tracers.Tracer, func(), error) {
+	timeout := defaultTraceTimeout
+	if config != nil && config.Timeout != nil {
+		var err error
+		if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
+			return nil, nil, fmt.Errorf("could not parse trace timeout. Cause: %w", err)
+		}
+	}
+
+	var tracer tracers.Tracer
+	switch {
+	case config == nil:
+		tracer = newBoundedStructLogger(nil, maxStructLogsSize)
+	case config.Tracer == nil:
+		tracer = newBoundedStructLogger(config.Config, maxStructLogsSize)
+	default:
+		if !supportedTracers[*config.Tracer] {
+			return nil, nil, fmt.Errorf("tracer %s is not supported", *config.Tracer)
+		}
+		var err error
+		if tracer, err = tracers.New(*config.Tracer, &tracers.Context{}); err != nil {
+			return nil, nil, fmt.Errorf("could not create tracer %s. Cause: %w", *config.Tracer, err)
+		}
+	}
+
+	deadlineCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	go func() {
+		<-deadlineCtx.Done()
+		if errors.Is(deadlineCtx.Err(), context.DeadlineExceeded) {
+			tracer.Stop(errors.New("execution timeout"))
+		}
+	}()
+	return tracer, cancel, nil
+}
+
+// Returns the result of the trace, in the same format as geth. If returnData is nil, the output captured by the tracer
+// is returned instead.
+func traceResult(tracer tracers.Tracer, gasUsed uint64, failed bool, returnData []byte) (json.RawMessage, error) {
+	structLogger, ok := tracer.(*boundedStructLogger)
+	if !ok {
+		return tracer.GetResult()
+	}
+	if err := structLogger.stopReason(); err != nil {
+		return nil, fmt.Errorf("trace was stopped. Cause: %w", err)
+	}
+	if returnData == nil {
+		returnData = structLogger.Output()
+	}
+	return json.Marshal(&gethapi.ExecutionResult{
+		Gas:         gasUsed,
+		Failed:      failed,
+		ReturnValue: fmt.Sprintf("%x", returnData),
+		StructLogs:  gethapi.FormatLogs(structLogger.StructLogs()),
+	})
+}
+
+// A struct logger that stops the execution once it is stopped, or once the struct logs it has captured exceed the
+// maximum size. geth's struct logger cannot be stopped, and captures the struct logs without limit.
+type boundedStructLogger struct {
+	*logger.StructLogger
+	maxSize int
+	size    int // The approximate size of the struct logs captured so far.
+
+	interrupt uint32 // Atomic flag set once the logger is stopped.
+	mu        sync.Mutex
+	env       *vm.EVM
+	reason    error
+}
+
+func newBoundedStructLogger(config *logger.Config, maxSize int) *boundedStructLogger {
+	return &boundedStructLogger{StructLogger: logger.NewStructLogger(config), maxSize: maxSize}
+}
+
+func (l *boundedStructLogger) CaptureStart(env *vm.EVM, from gethcommon.Address, to gethcommon.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	l.mu.Lock()
+	l.env = env
+	stopped := l.reason != nil
+	l.mu.Unlock()
+	if stopped {
+		env.Cancel()
+	}
+	l.StructLogger.CaptureStart(env, from, to, create, input, gas, value)
+}
+
+func (l *boundedStructLogger) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	// The EVM only checks whether it has been cancelled on jumps, so the logger stops capturing as soon as it is stopped.
+	if atomic.LoadUint32(&l.interrupt) > 0 {
+		return
+	}
+	logsBefore := len(l.StructLogs())
+	l.StructLogger.CaptureState(pc, op, gas, cost, scope, rData, depth, err)
+	logs := l.StructLogs()
+	if len(logs) == logsBefore {
+		return
+	}
+	structLog := logs[len(logs)-1]
+	// Each captured word of the stack and each captured storage slot is formatted as 64 hex characters.
+	l.size += len(structLog.Memory) + len(structLog.ReturnData) + 64*len(structLog.Stack) + 128*len(structLog.Storage)
+	if l.size > l.maxSize {
+		l.Stop(fmt.Errorf("struct logs exceed the maximum size of %d bytes", l.maxSize))
+	}
+}
+
+func (l *boundedStructLogger) GetResult() (json.RawMessage, error) {
+	return nil, errors.New("the result of the struct logger is built from its struct logs")
+}
+
+// Stop cancels the execution being traced. The execution is cancelled as soon as it starts, if it has not started yet.
+func (l *boundedStructLogger) Stop(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.reason != nil {
+		return
+	}
+	l.reason = err
+	atomic.StoreUint32(&l.interrupt, 1)
+	if l.env != nil {
+		l.env.Cancel()
+	}
+}
+
+func (l *boundedStructLogger) stopReason() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.reason
+}
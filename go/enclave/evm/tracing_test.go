@@ -0,0 +1,168 @@
+package evm
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/gethapi"
+	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/go/enclave/chainconfig"
+	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
+	"github.com/obscuronet/go-obscuro/go/enclave/db"
+	"github.com/obscuronet/go-obscuro/integration"
+	"github.com/obscuronet/go-obscuro/integration/datagenerator"
+
+	gethlogger "github.com/ethereum/go-ethereum/eth/tracers/logger"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+// The fields of the top-level call frame returned by the call tracer.
+type callTracerFrame struct {
+	Type  string `json:"type"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Error string `json:"error"`
+}
+
+// A contract that loops until it runs out of gas: JUMPDEST, PUSH1 0, JUMP.
+var loopingCode = []byte{0x5b, 0x60, 0x00, 0x56}
+
+func TestTraceTransaction(t *testing.T) {
+	logger := log.New(log.TestLogCmp, int(gethlog.LvlError), log.SysOut)
+	storage, stateDB := createTracingState(t, logger)
+	header := &common.BatchHeader{Number: big.NewInt(1), GasLimit: 1_000_000}
+	chainConfig := chainconfig.DefaultEVMConfig(integration.ObscuroChainID)
+
+	w := datagenerator.RandomWallet(integration.ObscuroChainID)
+	loopingContract := datagenerator.RandomAddress()
+	stateDB.SetCode(loopingContract, loopingCode)
+	to := datagenerator.RandomAddress()
+	transfer, err := w.SignTransaction(&types.LegacyTx{Nonce: 0, Gas: 21_000, GasPrice: big.NewInt(0), To: &to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loop, err := w.SignTransaction(&types.LegacyTx{Nonce: 1, Gas: 50_000, GasPrice: big.NewInt(0), To: &loopingContract})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txs := []*common.L2Tx{transfer, loop}
+
+	// The struct logger is used by default, and the preceding transactions are executed before the traced one.
+	trace, err := TraceTransaction(txs, 1, stateDB.Copy(), header, storage, chainConfig, nil, logger)
+	if err != nil {
+		t.Fatalf("could not trace transaction. Cause: %s", err)
+	}
+	var result gethapi.ExecutionResult
+	if err = json.Unmarshal(trace, &result); err != nil {
+		t.Fatal(err)
+	}
+	if !result.Failed || result.Gas != 50_000 || len(result.StructLogs) == 0 {
+		t.Fatalf("expected the struct logs of a transaction that runs out of gas, got %s", trace)
+	}
+
+	tracer := callTracerName
+	trace, err = TraceTransaction(txs, 1, stateDB.Copy(), header, storage, chainConfig, &tracers.TraceConfig{Tracer: &tracer}, logger)
+	if err != nil {
+		t.Fatalf("could not trace transaction. Cause: %s", err)
+	}
+	var frame callTracerFrame
+	if err = json.Unmarshal(trace, &frame); err != nil {
+		t.Fatal(err)
+	}
+	if frame.Type != "CALL" || frame.From != strings.ToLower(w.Address().Hex()) || frame.To != strings.ToLower(loopingContract.Hex()) || frame.Error == "" {
+		t.Fatalf("expected the failed call to the looping contract, got %s", trace)
+	}
+
+	tracer = "4byteTracer"
+	if _, err = TraceTransaction(txs, 1, stateDB.Copy(), header, storage, chainConfig, &tracers.TraceConfig{Tracer: &tracer}, logger); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Fatalf("expected unsupported tracer to be rejected, got %v", err)
+	}
+	if _, err = TraceTransaction(txs, 2, stateDB.Copy(), header, storage, chainConfig, nil, logger); err == nil {
+		t.Fatal("expected trace of a transaction beyond the end of the batch to fail")
+	}
+}
+
+func TestTraceCallOnlyUsesTheCallTracer(t *testing.T) {
+	logger := log.New(log.TestLogCmp, int(gethlog.LvlError), log.SysOut)
+	storage, stateDB := createTracingState(t, logger)
+	header := &common.BatchHeader{Number: big.NewInt(1), GasLimit: 1_000_000}
+	chainConfig := chainconfig.DefaultEVMConfig(integration.ObscuroChainID)
+
+	from := datagenerator.RandomAddress()
+	loopingContract := datagenerator.RandomAddress()
+	stateDB.SetCode(loopingContract, loopingCode)
+	msg := types.NewMessage(from, &loopingContract, 0, big.NewInt(0), 50_000, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, true)
+
+	// The call tracer is used by default, even if struct logger options are given.
+	trace, err := TraceCall(&msg, stateDB.Copy(), header, storage, chainConfig, &tracers.TraceConfig{Config: &gethlogger.Config{EnableMemory: true}})
+	if err != nil {
+		t.Fatalf("could not trace call. Cause: %s", err)
+	}
+	var frame callTracerFrame
+	if err = json.Unmarshal(trace, &frame); err != nil {
+		t.Fatal(err)
+	}
+	if frame.Type != "CALL" || frame.From != strings.ToLower(from.Hex()) || frame.To != strings.ToLower(loopingContract.Hex()) {
+		t.Fatalf("expected the call to the looping contract, got %s", trace)
+	}
+
+	tracer := "prestateTracer"
+	if _, err = TraceCall(&msg, stateDB.Copy(), header, storage, chainConfig, &tracers.TraceConfig{Tracer: &tracer}); err == nil || !strings.Contains(err.Error(), "reveals contract storage") {
+		t.Fatalf("expected the prestate tracer to be rejected, got %v", err)
+	}
+}
+
+func TestStructLoggerIsBounded(t *testing.T) {
+	maxSize := 10_000
+	structLogger := newBoundedStructLogger(nil, maxSize)
+	if _, _, err := runtime.Execute(loopingCode, nil, &runtime.Config{GasLimit: 1_000_000, EVMConfig: vmConfig(structLogger)}); err != nil {
+		t.Fatal(err)
+	}
+	if structLogger.stopReason() == nil {
+		t.Fatal("expected the struct logger to stop once its struct logs exceed the maximum size")
+	}
+	// Every third struct log of the looping contract captures a stack item, which counts for 64 bytes.
+	if logs := len(structLogger.StructLogs()); logs > 3*(maxSize/64+1) {
+		t.Fatalf("expected the struct logs to be capped at %d bytes, got %d struct logs", maxSize, logs)
+	}
+	if _, err := traceResult(structLogger, 0, false, nil); err == nil {
+		t.Fatal("expected the trace to fail once the struct logger is stopped")
+	}
+
+	// A struct logger that is stopped before the execution starts, e.g. by the timeout, cancels the execution.
+	structLogger = newBoundedStructLogger(nil, maxSize)
+	structLogger.Stop(errors.New("execution timeout"))
+	if _, _, err := runtime.Execute(loopingCode, nil, &runtime.Config{GasLimit: 1_000_000, EVMConfig: vmConfig(structLogger)}); err != nil {
+		t.Fatal(err)
+	}
+	if len(structLogger.StructLogs()) != 0 {
+		t.Fatalf("expected no struct logs once the struct logger is stopped, got %d", len(structLogger.StructLogs()))
+	}
+}
+
+func createTracingState(t *testing.T, logger gethlog.Logger) (db.Storage, *state.StateDB) {
+	t.Helper()
+	storage := db.NewStorage(rawdb.NewMemoryDatabase(), nil, logger)
+	if err := storage.StoreSecret(crypto.SharedEnclaveSecret{}); err != nil {
+		t.Fatalf("could not store secret. Cause: %s", err)
+	}
+	stateDB, err := storage.EmptyStateDB()
+	if err != nil {
+		t.Fatalf("could not create stateDB. Cause: %s", err)
+	}
+	return storage, stateDB
+}
+
+func vmConfig(tracer *boundedStructLogger) vm.Config {
+	return vm.Config{Debug: true, Tracer: tracer}
+}
@@ -22,6 +22,11 @@ func convertToEthHeader(h *common.BatchHeader, secret []byte) (*types.Header, er
 	// deterministically calculate private randomness that will be exposed to the evm
 	randomness := crypto.PrivateRollupRnd(h.MixDigest.Bytes(), secret)
 
+	baseFee := gethcommon.Big0
+	if h.BaseFee != nil {
+		baseFee = h.BaseFee
+	}
+
 	return &types.Header{
 		ParentHash:  h.ParentHash,
 		Root:        h.Root,
@@ -36,7 +41,7 @@ func convertToEthHeader(h *common.BatchHeader, secret []byte) (*types.Header, er
 		Extra:       obscuroHeader,
 		MixDigest:   gethcommon.BytesToHash(randomness),
 		Nonce:       types.BlockNonce{},
-		BaseFee:     gethcommon.Big0,
+		BaseFee:     baseFee,
 	}, nil
 }
 
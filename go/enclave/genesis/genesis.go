@@ -1,30 +1,65 @@
 package genesis
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"sort"
+	"strings"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/enclave/chainconfig"
 	"github.com/obscuronet/go-obscuro/go/enclave/db"
 
 	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethcore "github.com/ethereum/go-ethereum/core"
 )
 
-// Account specifies the address that's prefunded and the amount it's funded with
+// Account specifies an account in the genesis state. Accounts are usually just prefunded, but can also be given the
+// runtime code and storage of a contract whose state is known in advance
 type Account struct {
 	Address gethcommon.Address
 	Amount  *big.Int
+	Nonce   uint64
+	Code    hexutil.Bytes
+	Storage map[gethcommon.Hash]gethcommon.Hash
 }
 
-// Genesis holds a range of prefunded accounts
+// Contract specifies a contract that's deployed in the genesis state by executing its creation code, such as a system
+// contract or a token that must exist at batch 0
+type Contract struct {
+	Name     string             // Only used to identify the contract in errors
+	Deployer gethcommon.Address // The contract is deployed at the address derived from the deployer's address and nonce
+	Bytecode hexutil.Bytes      // The creation code, followed by the ABI-encoded constructor arguments
+	Address  gethcommon.Address // If set, the address the contract is expected to be deployed at
+}
+
+// Genesis holds the chain parameters, and the accounts and contracts of the genesis state
 type Genesis struct {
+	// The schedule of the EVM hardforks and of the Obscuro protocol forks. If nil, all the hardforks up to London are
+	// active from genesis
+	Config *chainconfig.ChainConfig
+	// The gas limit of the batches. If zero, the batches are only limited by the sequencer's configuration
+	GasLimit uint64
+	// The base fee of the batches. If nil, the base fee is zero
+	BaseFee *big.Int
+
 	Accounts []Account
+	// The contracts are deployed in order, after the accounts have been applied
+	Contracts []Contract
 }
 
 // New creates a new Genesis given a json string
 // if the string is empty it defaults to the testnet genesis
+// Unknown fields are rejected, so that a chain config given as a bare geth chain config, rather than as a
+// chainconfig.ChainConfig with the geth chain config in its `EVM` field, is not silently ignored
 func New(genesisJSON string) (*Genesis, error) {
 	// defaults to the testnet genesis
 	if genesisJSON == "" {
@@ -32,27 +67,100 @@ func New(genesisJSON string) (*Genesis, error) {
 	}
 
 	genesis := &Genesis{}
-	err := json.Unmarshal([]byte(genesisJSON), genesis)
-	if err != nil {
-		return nil, err
+	decoder := json.NewDecoder(strings.NewReader(genesisJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(genesis); err != nil {
+		return nil, fmt.Errorf("could not parse genesis. Cause: %w", err)
 	}
 	return genesis, nil
 }
 
-func (g Genesis) CommitGenesisState(storage db.Storage) error {
-	stateDB, err := g.applyAllocations(storage)
+// Validate returns an error if the base fee of the batches is higher than the minimum gas price accepted by the
+// enclave, since the transactions priced in between would be accepted, but could never be executed.
+func (g Genesis) Validate(minGasPrice *big.Int) error {
+	if g.BaseFee != nil && minGasPrice != nil && g.BaseFee.Cmp(minGasPrice) > 0 {
+		return fmt.Errorf("genesis base fee %d is higher than the minimum gas price %d", g.BaseFee, minGasPrice)
+	}
+	return nil
+}
+
+// ChainConfig returns the chain config specified by the genesis, or the default chain config if there is none, with the
+// defaults applied.
+func (g Genesis) ChainConfig(chainID int64) (*chainconfig.ChainConfig, error) {
+	if g.Config == nil {
+		return chainconfig.Default(chainID), nil
+	}
+	return g.Config.Resolve(chainID)
+}
+
+// The contents of the genesis that are hashed, in a canonical RLP encoding.
+type hashedGenesis struct {
+	GasLimit  uint64
+	BaseFee   *big.Int
+	Accounts  []hashedAccount
+	Contracts []hashedContract
+}
+
+type hashedAccount struct {
+	Address gethcommon.Address
+	Amount  *big.Int
+	Nonce   uint64
+	Code    []byte
+	Storage []hashedStorageSlot // sorted by key
+}
+
+type hashedStorageSlot struct {
+	Key   gethcommon.Hash
+	Value gethcommon.Hash
+}
+
+type hashedContract struct {
+	Deployer gethcommon.Address
+	Bytecode []byte
+	Address  gethcommon.Address
+}
+
+// Hash returns the hash of the genesis, which is included in the genesis batch so that nodes configured with a
+// different genesis reject it. The chain config is excluded, since new versions of it are released over the life of the
+// network. Its activations are checked through the fork ID of each batch instead. The names of the contracts are
+// excluded too, since they are only used in errors.
+func (g Genesis) Hash() (gethcommon.Hash, error) {
+	hashed := hashedGenesis{GasLimit: g.GasLimit, BaseFee: g.BaseFee}
+	for _, acc := range g.Accounts {
+		account := hashedAccount{Address: acc.Address, Amount: acc.Amount, Nonce: acc.Nonce, Code: acc.Code}
+		for key, value := range acc.Storage {
+			account.Storage = append(account.Storage, hashedStorageSlot{Key: key, Value: value})
+		}
+		sort.Slice(account.Storage, func(i, j int) bool {
+			return bytes.Compare(account.Storage[i].Key.Bytes(), account.Storage[j].Key.Bytes()) < 0
+		})
+		hashed.Accounts = append(hashed.Accounts, account)
+	}
+	for _, contract := range g.Contracts {
+		hashed.Contracts = append(hashed.Contracts, hashedContract{Deployer: contract.Deployer, Bytecode: contract.Bytecode, Address: contract.Address})
+	}
+
+	encoded, err := rlp.EncodeToBytes(hashed)
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("could not encode genesis. Cause: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+func (g Genesis) CommitGenesisState(storage db.Storage, chainConfig *params.ChainConfig) error {
+	stateDB, err := g.applyAllocations(storage, chainConfig)
 	if err != nil {
 		return err
 	}
-	_, err = stateDB.Commit(true)
+	_, err = storage.CommitStateDB(stateDB, common.L2GenesisHeight)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (g Genesis) GetGenesisRoot(storage db.Storage) (*common.StateRoot, error) {
-	stateDB, err := g.applyAllocations(storage)
+func (g Genesis) GetGenesisRoot(storage db.Storage, chainConfig *params.ChainConfig) (*common.StateRoot, error) {
+	stateDB, err := g.applyAllocations(storage, chainConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -60,17 +168,68 @@ func (g Genesis) GetGenesisRoot(storage db.Storage) (*common.StateRoot, error) {
 	return &stateHash, nil
 }
 
-// Applies the faucet preallocation on top of an empty state DB.
-func (g Genesis) applyAllocations(storage db.Storage) (*state.StateDB, error) {
+// Applies the accounts and deploys the contracts on top of an empty state DB.
+func (g Genesis) applyAllocations(storage db.Storage, chainConfig *params.ChainConfig) (*state.StateDB, error) {
 	s, err := storage.EmptyStateDB()
 	if err != nil {
 		return nil, fmt.Errorf("could not initialise empty state DB. Cause: %w", err)
 	}
 
-	// set the accounts funds
 	for _, acc := range g.Accounts {
-		s.SetBalance(acc.Address, acc.Amount)
+		if acc.Amount != nil {
+			s.SetBalance(acc.Address, acc.Amount)
+		}
+		if acc.Nonce != 0 {
+			s.SetNonce(acc.Address, acc.Nonce)
+		}
+		if len(acc.Code) > 0 {
+			s.SetCode(acc.Address, acc.Code)
+		}
+		for key, value := range acc.Storage {
+			s.SetState(acc.Address, key, value)
+		}
+	}
+
+	if len(g.Contracts) > 0 {
+		if err = g.deployContracts(s, chainConfig); err != nil {
+			return nil, err
+		}
 	}
 
 	return s, nil
 }
+
+// Executes the creation code of the contracts. The execution context is fixed, so that every enclave derives the same
+// genesis state.
+func (g Genesis) deployContracts(s *state.StateDB, chainConfig *params.ChainConfig) error {
+	gasLimit := g.GasLimit
+	if gasLimit == 0 {
+		gasLimit = 1_000_000_000 // the gas limit the EVM is given when executing batches
+	}
+	baseFee := g.BaseFee
+	if baseFee == nil {
+		baseFee = gethcommon.Big0
+	}
+	blockContext := vm.BlockContext{
+		CanTransfer: gethcore.CanTransfer,
+		Transfer:    gethcore.Transfer,
+		GetHash:     func(uint64) gethcommon.Hash { return gethcommon.Hash{} },
+		GasLimit:    gasLimit,
+		BlockNumber: big.NewInt(int64(common.L2GenesisHeight)),
+		Time:        gethcommon.Big0,
+		Difficulty:  gethcommon.Big0,
+		BaseFee:     baseFee,
+	}
+
+	for _, contract := range g.Contracts {
+		evm := vm.NewEVM(blockContext, vm.TxContext{Origin: contract.Deployer, GasPrice: gethcommon.Big0}, s, chainConfig, vm.Config{NoBaseFee: true})
+		_, address, _, err := evm.Create(vm.AccountRef(contract.Deployer), contract.Bytecode, gasLimit, gethcommon.Big0)
+		if err != nil {
+			return fmt.Errorf("could not deploy genesis contract %s. Cause: %w", contract.Name, err)
+		}
+		if contract.Address != (gethcommon.Address{}) && contract.Address != address {
+			return fmt.Errorf("genesis contract %s was deployed at %s rather than %s", contract.Name, address, contract.Address)
+		}
+	}
+	return nil
+}
@@ -1,13 +1,20 @@
 package genesis
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/obscuronet/go-obscuro/go/enclave/chainconfig"
 	"github.com/obscuronet/go-obscuro/go/enclave/db"
+	"github.com/obscuronet/go-obscuro/integration"
 	"github.com/obscuronet/go-obscuro/integration/datagenerator"
+	"github.com/obscuronet/go-obscuro/integration/erc20contract"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
 
 	gethlog "github.com/ethereum/go-ethereum/log"
 )
@@ -24,7 +31,7 @@ func TestDefaultGenesis(t *testing.T) {
 
 	backingDB := rawdb.NewMemoryDatabase()
 	storageDB := db.NewStorage(backingDB, nil, gethlog.New())
-	stateDB, err := gen.applyAllocations(storageDB)
+	stateDB, err := gen.applyAllocations(storageDB, chainconfig.DefaultEVMConfig(integration.ObscuroChainID))
 	if err != nil {
 		t.Fatalf("unable to apply genesis allocations")
 	}
@@ -57,7 +64,7 @@ func TestCustomGenesis(t *testing.T) {
 
 	backingDB := rawdb.NewMemoryDatabase()
 	storageDB := db.NewStorage(backingDB, nil, gethlog.New())
-	stateDB, err := gen.applyAllocations(storageDB)
+	stateDB, err := gen.applyAllocations(storageDB, chainconfig.DefaultEVMConfig(integration.ObscuroChainID))
 	if err != nil {
 		t.Fatalf("unable to apply genesis allocations")
 	}
@@ -69,3 +76,122 @@ func TestCustomGenesis(t *testing.T) {
 		t.Fatalf("unexpected balance")
 	}
 }
+
+func TestGenesisWithPredeployedContracts(t *testing.T) {
+	registryAddr := datagenerator.RandomAddress()
+	registryCode := datagenerator.RandomBytes(64)
+	registrySlot := gethcommon.BytesToHash(datagenerator.RandomBytes(32))
+	registryValue := gethcommon.BytesToHash(datagenerator.RandomBytes(32))
+	deployer := datagenerator.RandomAddress()
+	tokenAddr := crypto.CreateAddress(deployer, 0)
+
+	gen := Genesis{
+		GasLimit: 30_000_000,
+		Accounts: []Account{
+			{
+				Address: registryAddr,
+				Nonce:   1,
+				Code:    registryCode,
+				Storage: map[gethcommon.Hash]gethcommon.Hash{registrySlot: registryValue},
+			},
+		},
+		Contracts: []Contract{
+			{
+				Name:     "HOC",
+				Deployer: deployer,
+				Bytecode: erc20contract.L2BytecodeWithDefaultSupply("HOC", datagenerator.RandomAddress()),
+				Address:  tokenAddr,
+			},
+		},
+	}
+
+	storageDB := db.NewStorage(rawdb.NewMemoryDatabase(), nil, gethlog.New())
+	chainConfig, err := gen.ChainConfig(integration.ObscuroChainID)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	stateDB, err := gen.applyAllocations(storageDB, chainConfig.EVM)
+	if err != nil {
+		t.Fatalf("unable to apply genesis allocations. Cause: %s", err)
+	}
+
+	if !bytes.Equal(stateDB.GetCode(registryAddr), registryCode) || stateDB.GetNonce(registryAddr) != 1 {
+		t.Fatal("unexpected code or nonce for predeployed account")
+	}
+	if stateDB.GetState(registryAddr, registrySlot) != registryValue {
+		t.Fatal("unexpected storage for predeployed account")
+	}
+	if len(stateDB.GetCode(tokenAddr)) == 0 {
+		t.Fatal("expected token contract to be deployed at genesis")
+	}
+	if stateDB.GetNonce(deployer) != 1 {
+		t.Fatal("expected deployer nonce to be incremented by the deployment")
+	}
+
+	gen.Contracts[0].Address = datagenerator.RandomAddress()
+	if _, err = gen.applyAllocations(storageDB, chainConfig.EVM); err == nil {
+		t.Fatal("expected deployment at an unexpected address to fail")
+	}
+	if _, err = gen.ChainConfig(integration.ObscuroChainID + 1); err != nil {
+		t.Fatalf("unexpected error for genesis without chain config %s", err)
+	}
+	gen.Config = chainconfig.Default(integration.ObscuroChainID)
+	if _, err = gen.ChainConfig(integration.ObscuroChainID + 1); err == nil {
+		t.Fatal("expected genesis for another chain to be rejected")
+	}
+}
+
+func TestGenesisHashCoversTheGenesisState(t *testing.T) {
+	gen := Genesis{
+		Accounts: []Account{{
+			Address: datagenerator.RandomAddress(),
+			Storage: map[gethcommon.Hash]gethcommon.Hash{
+				gethcommon.HexToHash("0x01"): gethcommon.HexToHash("0x0a"),
+				gethcommon.HexToHash("0x02"): gethcommon.HexToHash("0x0b"),
+				gethcommon.HexToHash("0x03"): gethcommon.HexToHash("0x0c"),
+			},
+		}},
+		Contracts: []Contract{{Name: "HOC", Deployer: datagenerator.RandomAddress(), Bytecode: datagenerator.RandomBytes(32)}},
+	}
+	hash, err := gen.Hash()
+	if err != nil {
+		t.Fatalf("could not hash genesis. Cause: %s", err)
+	}
+
+	// The hash does not depend on the order the storage is iterated in, on the chain config or on the contract names.
+	gen.Config = chainconfig.Default(integration.ObscuroChainID)
+	gen.Contracts[0].Name = "POC"
+	for i := 0; i < 10; i++ {
+		if rehash, err := gen.Hash(); err != nil || rehash != hash {
+			t.Fatalf("expected genesis hash %s to be stable, got %s (error: %v)", hash, rehash, err)
+		}
+	}
+
+	gen.Accounts[0].Storage[gethcommon.HexToHash("0x02")] = gethcommon.HexToHash("0x0d")
+	if rehash, err := gen.Hash(); err != nil || rehash == hash {
+		t.Fatal("expected the genesis hash to change with the genesis storage")
+	}
+}
+
+func TestGenesisOnlyAcceptsTheVersionedChainConfig(t *testing.T) {
+	gen, err := New(`{"Config": {"Version": 1, "EVM": {"chainId": 777, "londonBlock": 0}}}`)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if gen.Config.Version != 1 || gen.Config.EVM.LondonBlock == nil {
+		t.Fatal("expected chain config to be parsed")
+	}
+	if _, err = New(`{"Config": {"chainId": 777, "londonBlock": 0}}`); err == nil {
+		t.Fatal("expected a bare geth chain config to be rejected")
+	}
+}
+
+func TestGenesisBaseFeeCannotExceedTheMinGasPrice(t *testing.T) {
+	gen := Genesis{BaseFee: big.NewInt(10)}
+	if err := gen.Validate(big.NewInt(10)); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if err := gen.Validate(big.NewInt(9)); err == nil {
+		t.Fatal("expected base fee higher than the minimum gas price to be rejected")
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"math/big"
 	"testing"
 
+	obscurocommon "github.com/obscuronet/go-obscuro/go/common"
 	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/go/enclave/genesis"
+	"github.com/obscuronet/go-obscuro/integration/datagenerator"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
@@ -38,3 +41,33 @@ func TestInvalidBlocksAreRejected(t *testing.T) {
 		}
 	}
 }
+
+func TestBatchSizeLimitDefersTransactions(t *testing.T) {
+	txs := []*obscurocommon.L2Tx{datagenerator.CreateL2Tx(), datagenerator.CreateL2Tx(), datagenerator.CreateL2Tx()}
+	logger := log.New(log.TestLogCmp, int(gethlog.LvlError), log.SysOut)
+
+	// The limit leaves room for two of the three transactions.
+	maxBatchSize := uint64(txs[0].Size()+txs[1].Size()) + 1
+	chain := ObscuroChain{maxBatchSize: maxBatchSize, logger: logger}
+	if included := chain.limitBatchSize(txs); len(included) != 2 || included[1] != txs[1] {
+		t.Errorf("expected the first two transactions to be included, got %d", len(included))
+	}
+
+	unlimitedChain := ObscuroChain{logger: logger}
+	if included := unlimitedChain.limitBatchSize(txs); len(included) != len(txs) {
+		t.Errorf("expected all transactions to be included, got %d", len(included))
+	}
+}
+
+func TestBatchesAboveTheGasLimitAreInvalid(t *testing.T) {
+	// The sequencer's own cap on the gas of its batches does not affect their validity.
+	chain := ObscuroChain{genesis: &genesis.Genesis{GasLimit: 20_000_000}, maxBatchGas: 10_000_000, BaseFee: big.NewInt(0)}
+	for gasLimit, valid := range map[uint64]bool{0: true, 10_000_000: true, 20_000_000: true, 20_000_001: false} {
+		if chain.hasValidChainParams(&obscurocommon.BatchHeader{GasLimit: gasLimit}) != valid {
+			t.Errorf("expected validity of batch with gas limit %d to be %t", gasLimit, valid)
+		}
+	}
+	if chain.batchGasLimit() != 10_000_000 {
+		t.Errorf("expected the sequencer to produce batches with its configured gas limit, got %d", chain.batchGasLimit())
+	}
+}
@@ -0,0 +1,146 @@
+package l2chain
+
+import (
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	gethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/enclave/core"
+	"github.com/obscuronet/go-obscuro/go/enclave/crosschain"
+)
+
+// divergenceReport accumulates the ways in which the re-execution of a batch diverged from it.
+type divergenceReport struct {
+	*common.DivergenceReport
+}
+
+func newDivergenceReport(batch *core.Batch, validator gethcommon.Address) *divergenceReport {
+	return &divergenceReport{&common.DivergenceReport{
+		BatchHash:           *batch.Hash(),
+		BatchNumber:         batch.NumberU64(),
+		ParentHash:          batch.Header.ParentHash,
+		L1Proof:             batch.Header.L1Proof,
+		Sequencer:           batch.Header.Agg,
+		Validator:           validator,
+		Divergences:         []common.DivergenceKind{},
+		ExpectedStateRoot:   batch.Header.Root,
+		ExpectedReceiptRoot: batch.Header.ReceiptHash,
+	}}
+}
+
+// Records a divergence, and the index of the transaction it was caused by if known.
+func (r *divergenceReport) diverge(kind common.DivergenceKind, txIdx *int) {
+	r.Divergences = append(r.Divergences, kind)
+	if txIdx != nil && (r.FirstDivergentTx == nil || uint64(*txIdx) < *r.FirstDivergentTx) {
+		idx := uint64(*txIdx)
+		r.FirstDivergentTx = &idx
+	}
+}
+
+// Compares the cross-chain messages in the batch's header with the ones emitted by the re-executed transactions.
+func (oc *ObscuroChain) checkCrossChainMessages(batch *core.Batch, txReceipts types.Receipts, report *divergenceReport) error {
+	txIndices := make(map[gethcommon.Hash]int, len(batch.Transactions))
+	for idx, tx := range batch.Transactions {
+		txIndices[tx.Hash()] = idx
+	}
+
+	// The hashes of the messages emitted by the transactions, and the index of the transaction that emitted each.
+	var actual []gethcommon.Hash
+	var actualTxIndices []int
+	for _, receipt := range txReceipts {
+		messages, err := oc.crossChainProcessors.Local.ExtractOutboundMessages(types.Receipts{receipt})
+		if err != nil {
+			return fmt.Errorf("could not extract cross chain messages. Cause: %w", err)
+		}
+		for _, message := range messages {
+			messageHash, err := crosschain.HashMessage(message)
+			if err != nil {
+				return err
+			}
+			actual = append(actual, messageHash)
+			actualTxIndices = append(actualTxIndices, txIndices[receipt.TxHash])
+		}
+	}
+
+	expected := make([]gethcommon.Hash, len(batch.Header.CrossChainMessages))
+	for idx, message := range batch.Header.CrossChainMessages {
+		messageHash, err := crosschain.HashMessage(message)
+		if err != nil {
+			return err
+		}
+		expected[idx] = messageHash
+	}
+
+	report.ExpectedCrossChainMessages = hashOfHashes(expected)
+	report.ActualCrossChainMessages = hashOfHashes(actual)
+	if report.ExpectedCrossChainMessages == report.ActualCrossChainMessages {
+		return nil
+	}
+	for idx := range actual {
+		if idx >= len(expected) || actual[idx] != expected[idx] {
+			report.diverge(common.CrossChainMessagesDivergence, &actualTxIndices[idx])
+			return nil
+		}
+	}
+	// The batch's header has more messages than were emitted, so we cannot tell which transaction should have emitted them.
+	report.diverge(common.CrossChainMessagesDivergence, nil)
+	return nil
+}
+
+// Returns the index in the batch of the first transaction whose re-executed receipt has logs missing from the batch's
+// logs bloom, or nil if there is none. The bloom combines the blooms of the sequencer's receipts, so the sequencer's
+// receipt for the transaction must differ.
+func firstTxOutsideBloom(batch *core.Batch, txReceipts types.Receipts) *int {
+	txIndices := make(map[gethcommon.Hash]int, len(batch.Transactions))
+	for idx, tx := range batch.Transactions {
+		txIndices[tx.Hash()] = idx
+	}
+	for _, receipt := range txReceipts {
+		receiptBloom := types.CreateBloom(types.Receipts{receipt})
+		for i := range receiptBloom {
+			if receiptBloom[i]&^batch.Header.Bloom[i] != 0 {
+				idx := txIndices[receipt.TxHash]
+				return &idx
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the hashes of the consensus encodings of the receipts.
+func receiptHashes(receipts types.Receipts) ([]gethcommon.Hash, error) {
+	hashes := make([]gethcommon.Hash, len(receipts))
+	for idx, receipt := range receipts {
+		encoded, err := receipt.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("could not encode receipt. Cause: %w", err)
+		}
+		hashes[idx] = gethcrypto.Keccak256Hash(encoded)
+	}
+	return hashes, nil
+}
+
+// Signs the report with the enclave key and stores it.
+func (oc *ObscuroChain) storeDivergenceReport(report *divergenceReport) error {
+	reportHash, err := report.Hash()
+	if err != nil {
+		return err
+	}
+	if report.Signature, err = gethcrypto.Sign(reportHash.Bytes(), oc.enclavePrivateKey); err != nil {
+		return fmt.Errorf("could not sign divergence report. Cause: %w", err)
+	}
+
+	oc.logger.Error(fmt.Sprintf("Batch b_%d at height %d diverged from its re-execution in %v.",
+		common.ShortHash(report.BatchHash), report.BatchNumber, report.Divergences), "firstDivergentTx", report.FirstDivergentTx)
+	return oc.storage.StoreDivergenceReport(report.DivergenceReport)
+}
+
+func hashOfHashes(hashes []gethcommon.Hash) gethcommon.Hash {
+	data := make([]byte, 0, len(hashes)*gethcommon.HashLength)
+	for _, hash := range hashes {
+		data = append(data, hash.Bytes()...)
+	}
+	return gethcrypto.Keccak256Hash(data)
+}
@@ -0,0 +1,154 @@
+package l2chain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	obscurocommon "github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/enclave/core"
+	"github.com/obscuronet/go-obscuro/go/enclave/crosschain"
+	obscurocrypto "github.com/obscuronet/go-obscuro/go/enclave/crypto"
+	"github.com/obscuronet/go-obscuro/go/enclave/db"
+
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+func TestDivergenceReportIsSignedAndStored(t *testing.T) {
+	enclaveKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("could not generate key. Cause: %s", err)
+	}
+	storage := db.NewStorage(rawdb.NewMemoryDatabase(), nil, gethlog.New())
+	chain := ObscuroChain{storage: storage, enclavePrivateKey: enclaveKey, logger: gethlog.New()}
+
+	batch := &core.Batch{Header: &obscurocommon.BatchHeader{Number: big.NewInt(3), Root: common.HexToHash("0x01")}}
+	report := newDivergenceReport(batch, common.HexToAddress("0x02"))
+	report.ActualStateRoot = common.HexToHash("0x03")
+	report.diverge(obscurocommon.StateRootDivergence, nil)
+	txIdx, earlierTxIdx := 4, 2
+	report.diverge(obscurocommon.TransactionExecutionDivergence, &txIdx)
+	report.diverge(obscurocommon.CrossChainMessagesDivergence, &earlierTxIdx)
+
+	if err = chain.storeDivergenceReport(report); err != nil {
+		t.Fatalf("could not store divergence report. Cause: %s", err)
+	}
+	reports, err := storage.FetchDivergenceReports()
+	if err != nil || len(reports) != 1 {
+		t.Fatalf("expected a single stored divergence report")
+	}
+	stored := reports[0]
+	if len(stored.Divergences) != 3 || stored.FirstDivergentTx == nil || *stored.FirstDivergentTx != uint64(earlierTxIdx) {
+		t.Fatalf("unexpected divergence report %+v", stored)
+	}
+
+	// The signature must recover the enclave key, so the report can be checked against the validator's attestation.
+	reportHash, err := stored.Hash()
+	if err != nil {
+		t.Fatalf("could not hash divergence report. Cause: %s", err)
+	}
+	pubKey, err := crypto.SigToPub(reportHash.Bytes(), stored.Signature)
+	if err != nil || crypto.PubkeyToAddress(*pubKey) != crypto.PubkeyToAddress(enclaveKey.PublicKey) {
+		t.Fatal("divergence report was not signed with the enclave key")
+	}
+}
+
+func TestCrossChainMessageDivergenceIdentifiesTheTransaction(t *testing.T) {
+	storage := db.NewStorage(rawdb.NewMemoryDatabase(), nil, gethlog.New())
+	manager := crosschain.NewObscuroMessageBusManager(storage, big.NewInt(777), gethlog.New())
+	if _, err := manager.DeriveOwner(obscurocrypto.SharedEnclaveSecret{1}); err != nil {
+		t.Fatalf("could not derive owner. Cause: %s", err)
+	}
+	chain := ObscuroChain{storage: storage, crossChainProcessors: &crosschain.Processors{Local: manager}, logger: gethlog.New()}
+
+	first := obscurocommon.CrossChainMessage{Sender: common.HexToAddress("0x01"), Sequence: 1, Payload: []byte{}}
+	second := obscurocommon.CrossChainMessage{Sender: common.HexToAddress("0x01"), Sequence: 2, Payload: []byte{}}
+	forged := obscurocommon.CrossChainMessage{Sender: common.HexToAddress("0x01"), Sequence: 3, Payload: []byte{}}
+	txs := []*obscurocommon.L2Tx{types.NewTx(&types.LegacyTx{Nonce: 0}), types.NewTx(&types.LegacyTx{Nonce: 1})}
+	receipts := types.Receipts{
+		messageReceipt(t, txs[0], *manager.GetBusAddress(), first),
+		messageReceipt(t, txs[1], *manager.GetBusAddress(), second),
+	}
+
+	for name, tc := range map[string]struct {
+		headerMessages   []obscurocommon.CrossChainMessage
+		diverges         bool
+		firstDivergentTx *uint64
+	}{
+		"matching messages":            {[]obscurocommon.CrossChainMessage{first, second}, false, nil},
+		"message of second tx forged":  {[]obscurocommon.CrossChainMessage{first, forged}, true, uint64Ptr(1)},
+		"message of second tx missing": {[]obscurocommon.CrossChainMessage{first}, true, uint64Ptr(1)},
+		"extra message in header":      {[]obscurocommon.CrossChainMessage{first, second, forged}, true, nil},
+	} {
+		batch := &core.Batch{Header: &obscurocommon.BatchHeader{Number: big.NewInt(1), CrossChainMessages: tc.headerMessages}, Transactions: txs}
+		report := newDivergenceReport(batch, common.HexToAddress("0x02"))
+		if err := chain.checkCrossChainMessages(batch, receipts, report); err != nil {
+			t.Fatalf("%s: could not check cross chain messages. Cause: %s", name, err)
+		}
+		if diverged := len(report.Divergences) > 0; diverged != tc.diverges {
+			t.Fatalf("%s: expected divergence %t, got %v", name, tc.diverges, report.Divergences)
+		}
+		if (tc.firstDivergentTx == nil) != (report.FirstDivergentTx == nil) ||
+			(tc.firstDivergentTx != nil && *tc.firstDivergentTx != *report.FirstDivergentTx) {
+			t.Fatalf("%s: unexpected first divergent transaction %v", name, report.FirstDivergentTx)
+		}
+	}
+}
+
+func TestTransactionWithLogsMissingFromTheBloomHasDiverged(t *testing.T) {
+	txs := []*obscurocommon.L2Tx{types.NewTx(&types.LegacyTx{Nonce: 0}), types.NewTx(&types.LegacyTx{Nonce: 1})}
+	receipts := types.Receipts{
+		{TxHash: txs[0].Hash(), Logs: []*types.Log{{Address: common.HexToAddress("0x01")}}},
+		{TxHash: txs[1].Hash(), Logs: []*types.Log{{Address: common.HexToAddress("0x02")}}},
+	}
+
+	// The sequencer's bloom only covers the logs of the first transaction.
+	batch := &core.Batch{
+		Header:       &obscurocommon.BatchHeader{Bloom: types.CreateBloom(receipts[:1])},
+		Transactions: txs,
+	}
+	if idx := firstTxOutsideBloom(batch, receipts); idx == nil || *idx != 1 {
+		t.Fatalf("expected the second transaction to have diverged, got %v", idx)
+	}
+	batch.Header.Bloom = types.CreateBloom(receipts)
+	if idx := firstTxOutsideBloom(batch, receipts); idx != nil {
+		t.Fatalf("expected no transaction to have diverged, got %d", *idx)
+	}
+}
+
+func TestDivergenceReportHashDistinguishesAMissingFirstDivergentTx(t *testing.T) {
+	report := &obscurocommon.DivergenceReport{BatchNumber: 1}
+	unknownHash, err := report.Hash()
+	if err != nil {
+		t.Fatalf("could not hash divergence report. Cause: %s", err)
+	}
+	report.FirstDivergentTx = uint64Ptr(0)
+	knownHash, err := report.Hash()
+	if err != nil {
+		t.Fatalf("could not hash divergence report. Cause: %s", err)
+	}
+	if unknownHash == knownHash {
+		t.Fatal("report hash does not distinguish an unknown first divergent transaction from the first transaction")
+	}
+}
+
+func messageReceipt(t *testing.T, tx *obscurocommon.L2Tx, messageBus common.Address, message obscurocommon.CrossChainMessage) *types.Receipt {
+	t.Helper()
+	data, err := crosschain.MessageBusABI.Events[crosschain.CrossChainEventName].Inputs.NonIndexed().Pack(
+		message.Sender, message.Sequence, message.Nonce, message.Topic, message.Payload, message.ConsistencyLevel)
+	if err != nil {
+		t.Fatalf("could not encode message. Cause: %s", err)
+	}
+	return &types.Receipt{
+		TxHash: tx.Hash(),
+		Status: types.ReceiptStatusSuccessful,
+		Logs:   []*types.Log{{Address: messageBus, Topics: []common.Hash{crosschain.CrossChainEventID}, Data: data}},
+	}
+}
+
+func uint64Ptr(value uint64) *uint64 {
+	return &value
+}
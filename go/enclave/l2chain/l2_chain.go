@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/ecdsa"
 	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -15,13 +16,15 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/trie"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/obscuronet/go-obscuro/go/common"
 	"github.com/obscuronet/go-obscuro/go/common/errutil"
 	"github.com/obscuronet/go-obscuro/go/common/gethapi"
 	"github.com/obscuronet/go-obscuro/go/common/gethutil"
 	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/go/enclave/chainconfig"
 	"github.com/obscuronet/go-obscuro/go/enclave/core"
 	"github.com/obscuronet/go-obscuro/go/enclave/crosschain"
 	"github.com/obscuronet/go-obscuro/go/enclave/db"
@@ -40,7 +43,7 @@ import (
 type ObscuroChain struct {
 	hostID      gethcommon.Address
 	nodeType    common.NodeType
-	chainConfig *params.ChainConfig
+	chainConfig *chainconfig.ChainConfig
 	sequencerID gethcommon.Address
 
 	storage              db.Storage
@@ -50,9 +53,14 @@ type ObscuroChain struct {
 	crossChainProcessors *crosschain.Processors
 
 	enclavePrivateKey    *ecdsa.PrivateKey // this is a key known only to the current enclave, and the public key was shared with everyone during attestation
+	timeBasedBatches     bool              // whether the sequencer produces batches on request, rather than for each live L1 block
+	maxBatchGas          uint64            // the gas limit of the batches produced by the sequencer
+	maxBatchSize         uint64            // the maximum encoded size in bytes of the transactions in the batches produced by the sequencer
 	blockProcessingMutex sync.Mutex
 	logger               gethlog.Logger
 
+	publicStorageSlotsCache *lru.Cache // the storage slots declared public, keyed by batch and contract
+
 	// Gas usage values
 	// TODO use the ethconfig.Config instead
 	GlobalGasCap uint64
@@ -67,11 +75,22 @@ func New(
 	crossChainProcessors *crosschain.Processors,
 	mempool mempool.Manager,
 	privateKey *ecdsa.PrivateKey,
-	chainConfig *params.ChainConfig,
+	chainConfig *chainconfig.ChainConfig,
 	sequencerID gethcommon.Address,
 	genesis *genesis.Genesis,
+	timeBasedBatches bool,
+	maxBatchGas uint64,
+	maxBatchSize uint64,
 	logger gethlog.Logger,
 ) *ObscuroChain {
+	baseFee := gethcommon.Big0
+	if genesis.BaseFee != nil {
+		baseFee = genesis.BaseFee
+	}
+	publicStorageSlotsCache, err := lru.New(publicStorageSlotsCacheSize)
+	if err != nil {
+		logger.Crit("Could not create cache of public storage slots.", log.ErrKey, err)
+	}
 	return &ObscuroChain{
 		hostID:               hostID,
 		nodeType:             nodeType,
@@ -80,13 +99,18 @@ func New(
 		mempool:              mempool,
 		crossChainProcessors: crossChainProcessors,
 		enclavePrivateKey:    privateKey,
+		timeBasedBatches:     timeBasedBatches,
+		maxBatchGas:          maxBatchGas,
+		maxBatchSize:         maxBatchSize,
 		chainConfig:          chainConfig,
 		blockProcessingMutex: sync.Mutex{},
 		logger:               logger,
 		GlobalGasCap:         5_000_000_000,
-		BaseFee:              gethcommon.Big0,
+		BaseFee:              baseFee,
 		sequencerID:          sequencerID,
 		genesis:              genesis,
+
+		publicStorageSlotsCache: publicStorageSlotsCache,
 	}
 }
 
@@ -111,6 +135,50 @@ func (oc *ObscuroChain) ProcessL1Block(block types.Block, receipts types.Receipt
 	return newL2Head, producedBatch, nil
 }
 
+// ProduceBatch produces a new batch on top of the head batch, linked to the latest ingested L1 block, and makes it the
+// new head. Returns nil if no L1 block has been ingested yet, or if skipIfEmpty is true and there are no pending
+// transactions or deposits.
+func (oc *ObscuroChain) ProduceBatch(skipIfEmpty bool) (*core.Batch, types.Receipts, error) {
+	oc.blockProcessingMutex.Lock()
+	defer oc.blockProcessingMutex.Unlock()
+
+	l1Head, err := oc.storage.FetchHeadBlock()
+	if err != nil {
+		if errors.Is(err, errutil.ErrNotFound) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("could not retrieve head block. Cause: %w", err)
+	}
+
+	genesisBatchStored := true
+	headBatch, err := oc.storage.FetchHeadBatch()
+	if err != nil {
+		if !errors.Is(err, errutil.ErrNotFound) {
+			return nil, nil, fmt.Errorf("could not retrieve current head batch. Cause: %w", err)
+		}
+		genesisBatchStored = false
+	}
+
+	if genesisBatchStored && skipIfEmpty {
+		hasPendingWork, err := oc.hasPendingWork(headBatch, l1Head)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !hasPendingWork {
+			return nil, nil, nil
+		}
+	}
+
+	batch, receipts, err := oc.produceAndStoreBatch(l1Head, genesisBatchStored)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not produce and store new batch. Cause: %w", err)
+	}
+	if err = oc.storage.UpdateHeadBatch(l1Head.Hash(), batch, receipts); err != nil {
+		return nil, nil, fmt.Errorf("could not store new head. Cause: %w", err)
+	}
+	return batch, receipts, nil
+}
+
 // UpdateL2Chain updates the L2 chain based on the received batch.
 func (oc *ObscuroChain) UpdateL2Chain(batch *core.Batch) error {
 	oc.blockProcessingMutex.Lock()
@@ -173,21 +241,11 @@ func (oc *ObscuroChain) GetBalance(accountAddress gethcommon.Address, blockNumbe
 	address := accountAddress
 	// If the accountAddress is a contract, encrypt with the address of the contract owner
 	if isAddrContract {
-		txHash, err := oc.storage.GetContractCreationTx(accountAddress)
+		deployer, err := oc.contractDeployer(accountAddress)
 		if err != nil {
 			return nil, nil, err
 		}
-		transaction, _, _, _, err := oc.storage.GetTransaction(*txHash)
-		if err != nil {
-			return nil, nil, err
-		}
-		signer := types.NewLondonSigner(oc.chainConfig.ChainID)
-
-		sender, err := signer.Sender(transaction)
-		if err != nil {
-			return nil, nil, err
-		}
-		address = sender
+		address = *deployer
 	}
 
 	return &address, balance, nil
@@ -249,7 +307,7 @@ func (oc *ObscuroChain) ExecuteOffChainTransactionAtBlock(apiArgs *gethapi.Trans
 			batch.Header.Root.Hex()),
 	)
 
-	result, err := evm.ExecuteOffChainCall(&callMsg, blockState, batch.Header, oc.storage, oc.chainConfig, oc.logger)
+	result, err := evm.ExecuteOffChainCall(&callMsg, blockState, batch.Header, oc.storage, oc.chainConfig.EVM, oc.logger)
 	if err != nil {
 		// also return the result as the result can be evaluated on some errors like ErrIntrinsicGas
 		return result, err
@@ -265,6 +323,49 @@ func (oc *ObscuroChain) ExecuteOffChainTransactionAtBlock(apiArgs *gethapi.Trans
 	return result, nil
 }
 
+// TraceTransaction re-executes the canonical batch that includes the transaction, and traces the execution of the
+// transaction. Returns the transaction along with the trace, so that the trace can be encrypted for its sender.
+func (oc *ObscuroChain) TraceTransaction(txHash gethcommon.Hash, config *tracers.TraceConfig) (*common.L2Tx, json.RawMessage, error) {
+	tx, batchHash, batchHeight, txIndex, err := oc.storage.GetTransaction(txHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	batch, err := oc.storage.FetchBatchByHeight(batchHeight)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not retrieve batch containing transaction. Cause: %w", err)
+	}
+	if *batch.Hash() != batchHash {
+		return nil, nil, fmt.Errorf("transaction not included in the canonical chain")
+	}
+
+	stateDB, err := oc.storage.CreateStateDB(batch.Header.ParentHash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create stateDB. Cause: %w", err)
+	}
+	trace, err := evm.TraceTransaction(batch.Transactions, int(txIndex), stateDB, batch.Header, oc.storage, oc.chainConfig.EVM, config, oc.logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, trace, nil
+}
+
+// TraceCall traces the execution of the call at the given height, in the same way as eth_call executes it.
+func (oc *ObscuroChain) TraceCall(apiArgs *gethapi.TransactionArgs, blockNumber *gethrpc.BlockNumber, config *tracers.TraceConfig) (json.RawMessage, error) {
+	callMsg, err := apiArgs.ToMessage(oc.GlobalGasCap, oc.BaseFee)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert TransactionArgs to Message - %w", err)
+	}
+	blockState, err := oc.getChainStateAtBlock(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := oc.getBatch(*blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch head state batch. Cause: %w", err)
+	}
+	return evm.TraceCall(&callMsg, blockState, batch.Header, oc.storage, oc.chainConfig.EVM, config)
+}
+
 func (oc *ObscuroChain) updateL1State(block types.Block, receipts types.Receipts, isLatest bool) (*blockIngestionType, error) {
 	// We check whether we've already processed the block.
 	_, err := oc.storage.FetchBlock(block.Hash())
@@ -377,9 +478,10 @@ func (oc *ObscuroChain) updateL1AndL2Heads(block *types.Block, ingestionType *bl
 		}
 	}
 
-	// If we're the sequencer and we're on the latest block, we produce a new L2 head to replace the old one.
+	// If we're the sequencer and we're on the latest block, we produce a new L2 head to replace the old one, unless
+	// batches are produced on the host's request instead.
 	var producedBatch *core.Batch
-	if oc.nodeType == common.Sequencer && ingestionType.isLatest {
+	if oc.nodeType == common.Sequencer && ingestionType.isLatest && !oc.timeBasedBatches {
 		l2Head, l2HeadTxReceipts, err = oc.produceAndStoreBatch(block, genesisBatchStored)
 		if err != nil {
 			return nil, nil, fmt.Errorf("could not produce and store new batch. Cause: %w", err)
@@ -395,6 +497,11 @@ func (oc *ObscuroChain) updateL1AndL2Heads(block *types.Block, ingestionType *bl
 		if err = oc.storage.UpdateL1Head(block.Hash()); err != nil {
 			return nil, nil, fmt.Errorf("could not store new L1 head. Cause: %w", err)
 		}
+	} else if oc.nodeType == common.Sequencer && oc.timeBasedBatches {
+		// The genesis batch is produced on request, and is linked to the L1 head, so we track the head beforehand.
+		if err = oc.storage.UpdateL1Head(block.Hash()); err != nil {
+			return nil, nil, fmt.Errorf("could not store new L1 head. Cause: %w", err)
+		}
 	}
 
 	var l2HeadHash *gethcommon.Hash
@@ -426,13 +533,39 @@ func (oc *ObscuroChain) produceAndStoreBatch(block *common.L1Block, genesisBatch
 	return l2Head, l2HeadTxReceipts, nil
 }
 
+// Returns whether a batch produced on top of the head batch would contain any transactions or deposits.
+func (oc *ObscuroChain) hasPendingWork(headBatch *core.Batch, l1Head *types.Block) (bool, error) {
+	pendingTxs, err := oc.mempool.CurrentTxs(headBatch, oc.storage)
+	if err != nil {
+		return false, fmt.Errorf("could not retrieve current transactions. Cause: %w", err)
+	}
+	if len(pendingTxs) > 0 {
+		return true, nil
+	}
+
+	headBatchProof, err := oc.storage.FetchBlock(headBatch.Header.L1Proof)
+	if err != nil {
+		return false, fmt.Errorf("could not retrieve proof for head batch. Cause: %w", err)
+	}
+	pendingDeposits := oc.crossChainProcessors.Local.RetrieveInboundMessages(headBatchProof, l1Head, nil)
+	return len(pendingDeposits) > 0, nil
+}
+
 // Creates a genesis batch linked to the provided L1 block and signs it.
 func (oc *ObscuroChain) produceGenesisBatch(blkHash common.L1RootHash) (*core.Batch, error) {
-	preFundGenesisState, err := oc.genesis.GetGenesisRoot(oc.storage)
+	preFundGenesisState, err := oc.genesis.GetGenesisRoot(oc.storage, oc.chainConfig.EVM)
+	if err != nil {
+		return nil, err
+	}
+	genesisHash, err := oc.genesis.Hash()
 	if err != nil {
 		return nil, err
 	}
 
+	forkID, err := oc.chainConfig.ForkID(big.NewInt(0))
+	if err != nil {
+		return nil, err
+	}
 	genesisBatch := &core.Batch{
 		Header: &common.BatchHeader{
 			Agg:         oc.hostID,
@@ -443,6 +576,11 @@ func (oc *ObscuroChain) produceGenesisBatch(blkHash common.L1RootHash) (*core.Ba
 			Number:      big.NewInt(int64(0)),
 			ReceiptHash: types.EmptyRootHash,
 			Time:        uint64(time.Now().Unix()),
+			GasLimit:    oc.genesis.GasLimit,
+			BaseFee:     oc.genesis.BaseFee,
+			// The genesis batch commits to the genesis it was produced from.
+			Extra:  genesisHash.Bytes(),
+			ForkID: forkID,
 		},
 		Transactions: []*common.L2Tx{},
 	}
@@ -459,7 +597,7 @@ func (oc *ObscuroChain) produceGenesisBatch(blkHash common.L1RootHash) (*core.Ba
 		oc.logger.Crit("Cannot create synthetic transaction for deploying the message bus contract on :|")
 	}
 
-	if err = oc.genesis.CommitGenesisState(oc.storage); err != nil {
+	if err = oc.genesis.CommitGenesisState(oc.storage, oc.chainConfig.EVM); err != nil {
 		return nil, fmt.Errorf("could not apply genesis preallocation. Cause: %w", err)
 	}
 	return genesisBatch, nil
@@ -472,7 +610,7 @@ func (oc *ObscuroChain) processState(batch *core.Batch, txs []*common.L2Tx, stat
 	var executedTransactions []*common.L2Tx
 	var txReceipts []*types.Receipt
 
-	txResults := evm.ExecuteTransactions(txs, stateDB, batch.Header, oc.storage, oc.chainConfig, 0, oc.logger)
+	txResults := evm.ExecuteTransactions(txs, stateDB, batch.Header, oc.storage, oc.chainConfig.EVM, 0, batch.Header.GasLimit, oc.logger)
 	for _, tx := range txs {
 		result, f := txResults[tx.Hash()]
 		if !f {
@@ -505,8 +643,10 @@ func (oc *ObscuroChain) processState(batch *core.Batch, txs []*common.L2Tx, stat
 	}
 
 	messages := oc.crossChainProcessors.Local.RetrieveInboundMessages(parentProof, batchProof, stateDB)
-	transactions := oc.crossChainProcessors.Local.CreateSyntheticTransactions(messages, stateDB)
-	syntheticTransactionsResponses := evm.ExecuteTransactions(transactions, stateDB, batch.Header, oc.storage, oc.chainConfig, len(executedTransactions), oc.logger)
+	replayProtection := oc.chainConfig.IsActive(chainconfig.InboundMessageReplayProtection, batch.Header.Number)
+	transactions := oc.crossChainProcessors.Local.CreateSyntheticTransactions(messages, stateDB, batch.Header, replayProtection)
+	// deposits are not subject to the batch's gas limit, since they cannot be deferred to a later batch
+	syntheticTransactionsResponses := evm.ExecuteTransactions(transactions, stateDB, batch.Header, oc.storage, oc.chainConfig.EVM, len(executedTransactions), 0, oc.logger)
 	synthReceipts := make([]*types.Receipt, len(syntheticTransactionsResponses))
 	if len(syntheticTransactionsResponses) != len(transactions) {
 		oc.logger.Crit("Sanity check. Some synthetic transactions failed.")
@@ -536,15 +676,18 @@ func (oc *ObscuroChain) processState(batch *core.Batch, txs []*common.L2Tx, stat
 				false)
 
 			clonedDB := stateDB.Copy()
-			res, err := evm.ExecuteOffChainCall(&txCallMessage, clonedDB, batch.Header, oc.storage, oc.chainConfig, oc.logger)
+			res, err := evm.ExecuteOffChainCall(&txCallMessage, clonedDB, batch.Header, oc.storage, oc.chainConfig.EVM, oc.logger)
 			oc.logger.Crit("Synthetic transaction failed!", log.ErrKey, err, "result", res)
 		}
 
 		synthReceipts[i] = rec
 		i++
 	}
+	if err = oc.crossChainProcessors.Local.RecordDeliveries(transactions, synthReceipts, stateDB, batch.Header, replayProtection); err != nil {
+		oc.logger.Crit("Could not record the delivery of cross chain messages.", log.ErrKey, err, log.CmpKey, log.CrossChainCmp)
+	}
 
-	rootHash, err := stateDB.Commit(true)
+	rootHash, err := oc.storage.CommitStateDB(stateDB, batch.NumberU64())
 	if err != nil {
 		oc.logger.Crit("could not commit to state DB. ", log.ErrKey, err)
 	}
@@ -611,7 +754,7 @@ func (oc *ObscuroChain) replayBatchesToValidState() error {
 
 		// if genesis batch then create the genesis state before continuing on with remaining batches
 		if batch.NumberU64() == 0 {
-			err := oc.genesis.CommitGenesisState(oc.storage)
+			err := oc.genesis.CommitGenesisState(oc.storage, oc.chainConfig.EVM)
 			if err != nil {
 				return err
 			}
@@ -638,42 +781,153 @@ func stateDBAvailableForBatch(storage db.Storage, hash *common.L2RootHash) bool
 	return err == nil
 }
 
-// Checks the internal validity of the batch.
+// Checks that the genesis batch was produced from the same genesis as the enclave's. The genesis batches of the networks
+// started before the genesis batch committed to the hash of the genesis do not carry it, and are only checked against
+// the genesis state root and chain parameters, which still cover every account and contract of the genesis.
+func (oc *ObscuroChain) isValidGenesisBatch(batch *core.Batch) error {
+	genesisHash, err := oc.genesis.Hash()
+	if err != nil {
+		return err
+	}
+	if len(batch.Header.Extra) == 0 {
+		oc.logger.Warn("Genesis batch does not commit to the hash of its genesis. Checking it against the genesis state root only.")
+	} else if !bytes.Equal(batch.Header.Extra, genesisHash.Bytes()) {
+		return fmt.Errorf("batch was produced from genesis %s, but the enclave's genesis is %s", gethcommon.BytesToHash(batch.Header.Extra), genesisHash)
+	}
+	genesisRoot, err := oc.genesis.GetGenesisRoot(oc.storage, oc.chainConfig.EVM)
+	if err != nil {
+		return err
+	}
+	if batch.Header.Root != *genesisRoot {
+		return fmt.Errorf("batch has state root %s, but the genesis state root is %s", batch.Header.Root, genesisRoot)
+	}
+	if !oc.hasValidChainParams(batch.Header) {
+		return errors.New("batch did not match the chain parameters of the genesis")
+	}
+	return oc.checkForkID(batch.Header)
+}
+
+// Checks that the batch was produced under the same changes of the chain config as the ones the enclave has scheduled
+// at its number. Otherwise, the sequencer and the enclave are running different versions of the chain config. A batch
+// produced before the fork ID was introduced is checked against the default config.
+func (oc *ObscuroChain) checkForkID(header *common.BatchHeader) error {
+	return oc.chainConfig.CheckForkID(header.Number, header.ForkID)
+}
+
+// Checks that the batch does not exceed the gas limit set in the genesis, and uses the base fee set in the genesis. A
+// batch produced before the batches had a gas limit has a zero gas limit, and is accepted.
+func (oc *ObscuroChain) hasValidChainParams(header *common.BatchHeader) bool {
+	if oc.genesis.GasLimit != 0 && header.GasLimit > oc.genesis.GasLimit {
+		return false
+	}
+	return headerBaseFee(header).Cmp(oc.BaseFee) == 0
+}
+
+// Returns the gas limit of the batches produced by the sequencer, which cannot exceed the gas limit set in the genesis.
+func (oc *ObscuroChain) batchGasLimit() uint64 {
+	if oc.genesis.GasLimit != 0 && (oc.maxBatchGas == 0 || oc.maxBatchGas > oc.genesis.GasLimit) {
+		return oc.genesis.GasLimit
+	}
+	return oc.maxBatchGas
+}
+
+func headerBaseFee(header *common.BatchHeader) *big.Int {
+	if header.BaseFee == nil {
+		return gethcommon.Big0
+	}
+	return header.BaseFee
+}
+
+// Checks the internal validity of the batch. If re-executing the batch does not match it, a signed divergence report is
+// stored as evidence against the sequencer.
 func (oc *ObscuroChain) isInternallyValidBatch(batch *core.Batch) (types.Receipts, error) {
+	// Check that the signature is valid. A batch that was not signed by the sequencer is not evidence against it.
+	if err := oc.CheckSequencerSignature(batch.Hash(), &batch.Header.Agg, batch.Header.R, batch.Header.S); err != nil {
+		return nil, fmt.Errorf("verify batch r_%d: invalid signature. Cause: %w", common.ShortHash(*batch.Hash()), err)
+	}
+	// A batch produced under another version of the chain config is not evidence against the sequencer, since the
+	// enclave's own version may be the outdated one.
+	if err := oc.checkForkID(batch.Header); err != nil {
+		return nil, fmt.Errorf("verify batch r_%d: %w", common.ShortHash(*batch.Hash()), err)
+	}
+
 	stateDB, err := oc.storage.CreateStateDB(batch.Header.ParentHash)
 	if err != nil {
 		return nil, fmt.Errorf("could not create stateDB. Cause: %w", err)
 	}
 
+	report := newDivergenceReport(batch, oc.hostID)
+
+	// The batch must respect the chain parameters set in the genesis.
+	if !oc.hasValidChainParams(batch.Header) {
+		report.diverge(common.ChainParamsDivergence, nil)
+	}
+
+	// Only the enclaves can sign as the message bus owner, so the sequencer cannot have forged synthetic transactions.
+	for idx, tx := range batch.Transactions {
+		if err = oc.crossChainProcessors.Local.VerifySyntheticTransactions(common.L2Transactions{tx}); err != nil {
+			oc.logger.Warn(fmt.Sprintf("Invalid synthetic transaction in batch b_%d.", common.ShortHash(*batch.Hash())), log.ErrKey, err)
+			report.diverge(common.SyntheticTransactionDivergence, &idx)
+			break
+		}
+	}
+
 	// calculate the state to compare with what is in the batch
 	rootHash, executedTxs, txReceipts, depositReceipts := oc.processState(batch, batch.Transactions, stateDB)
 	if len(executedTxs) != len(batch.Transactions) {
-		return nil, fmt.Errorf("all transactions that are included in a batch must be executed")
+		// All transactions that are included in a batch must be executed.
+		for idx, tx := range batch.Transactions {
+			if idx >= len(executedTxs) || executedTxs[idx].Hash() != tx.Hash() {
+				report.diverge(common.TransactionExecutionDivergence, &idx)
+				break
+			}
+		}
 	}
 
-	// Check that the root hash in the header matches the root hash as calculated.
+	// The batch does not commit to the results of each transaction, but a transaction whose logs are missing from the
+	// batch's bloom is known to have diverged.
+	receipts := allReceipts(txReceipts, depositReceipts)
+	divergentTx := firstTxOutsideBloom(batch, txReceipts)
+	if report.ActualReceiptHashes, err = receiptHashes(receipts); err != nil {
+		return nil, err
+	}
+
+	report.ActualStateRoot = rootHash
 	if !bytes.Equal(rootHash.Bytes(), batch.Header.Root.Bytes()) {
 		dump := strings.Replace(string(stateDB.Dump(&state.DumpConfig{})), "\n", "", -1)
-		return nil, fmt.Errorf("verify batch b_%d: Calculated a different state. This should not happen as there are no malicious actors yet. \nGot: %s\nExp: %s\nHeight:%d\nTxs:%v\nState: %s.\nDeposits: %+v",
-			common.ShortHash(*batch.Hash()), rootHash, batch.Header.Root, batch.Header.Number, core.PrintTxs(batch.Transactions), dump, depositReceipts)
+		oc.logger.Error(fmt.Sprintf("verify batch b_%d: Calculated a different state. \nGot: %s\nExp: %s\nHeight:%d\nTxs:%v\nState: %s.\nDeposits: %+v",
+			common.ShortHash(*batch.Hash()), rootHash, batch.Header.Root, batch.Header.Number, core.PrintTxs(batch.Transactions), dump, depositReceipts))
+		report.diverge(common.StateRootDivergence, divergentTx)
+	}
+
+	// Check that the gas used in the header matches the gas used as calculated.
+	if batchGasUsed := gasUsed(txReceipts); batchGasUsed != batch.Header.GasUsed {
+		report.diverge(common.GasUsedDivergence, nil)
 	}
 
 	// Check that the receipts bloom in the header matches the receipts bloom as calculated.
-	receipts := allReceipts(txReceipts, depositReceipts)
 	receiptBloom := types.CreateBloom(receipts)
 	if !bytes.Equal(receiptBloom.Bytes(), batch.Header.Bloom.Bytes()) {
-		return nil, fmt.Errorf("verify batch r_%d: Invalid bloom (remote: %x  local: %x)", common.ShortHash(*batch.Hash()), batch.Header.Bloom, receiptBloom)
+		report.diverge(common.LogsBloomDivergence, divergentTx)
 	}
 
 	// Check that the receipts SHA in the header matches the receipts SHA as calculated.
 	receiptSha := types.DeriveSha(receipts, trie.NewStackTrie(nil))
+	report.ActualReceiptRoot = receiptSha
 	if !bytes.Equal(receiptSha.Bytes(), batch.Header.ReceiptHash.Bytes()) {
-		return nil, fmt.Errorf("verify batch r_%d: invalid receipt root hash (remote: %x local: %x)", common.ShortHash(*batch.Hash()), batch.Header.ReceiptHash, receiptSha)
+		report.diverge(common.ReceiptRootDivergence, divergentTx)
 	}
 
-	// Check that the signature is valid.
-	if err = oc.CheckSequencerSignature(batch.Hash(), &batch.Header.Agg, batch.Header.R, batch.Header.S); err != nil {
-		return nil, fmt.Errorf("verify batch r_%d: invalid signature. Cause: %w", common.ShortHash(*batch.Hash()), err)
+	// Check that the cross-chain messages in the header are the ones emitted by the transactions.
+	if err = oc.checkCrossChainMessages(batch, txReceipts, report); err != nil {
+		return nil, err
+	}
+
+	if len(report.Divergences) > 0 {
+		if err = oc.storeDivergenceReport(report); err != nil {
+			oc.logger.Error("Could not store divergence report.", log.ErrKey, err)
+		}
+		return nil, fmt.Errorf("verify batch b_%d: re-execution diverged from the batch in %v", common.ShortHash(*batch.Hash()), report.Divergences)
 	}
 
 	// todo - check that the transactions hash to the header.txHash
@@ -795,6 +1049,12 @@ func (oc *ObscuroChain) produceBatch(block *types.Block, genesisBatchStored bool
 	if err != nil {
 		return nil, fmt.Errorf("could not retrieve current transactions. Cause: %w", err)
 	}
+	newBatchTxs = oc.limitBatchSize(newBatchTxs)
+	// The transactions that do not fit within the gas limit fail, and remain in the mempool for a later batch.
+	batch.Header.GasLimit = oc.batchGasLimit()
+	if batch.Header.ForkID, err = oc.chainConfig.ForkID(batch.Header.Number); err != nil {
+		return nil, err
+	}
 
 	newBatchState, err = oc.storage.CreateStateDB(batch.Header.ParentHash)
 	if err != nil {
@@ -804,6 +1064,7 @@ func (oc *ObscuroChain) produceBatch(block *types.Block, genesisBatchStored bool
 	rootHash, successfulTxs, txReceipts, depositReceipts := oc.processState(batch, newBatchTxs, newBatchState)
 
 	batch.Header.Root = rootHash
+	batch.Header.GasUsed = gasUsed(txReceipts)
 	batch.Transactions = successfulTxs
 
 	crossChainMessages, err := oc.crossChainProcessors.Local.ExtractOutboundMessages(txReceipts)
@@ -847,8 +1108,24 @@ func (oc *ObscuroChain) produceBatch(block *types.Block, genesisBatchStored bool
 	return batch, nil
 }
 
-// Returns the state of the chain at height
-// TODO make this cacheable
+// Returns the longest prefix of the transactions whose encoded size is within the maximum batch size. The remaining
+// transactions stay in the mempool for a later batch.
+func (oc *ObscuroChain) limitBatchSize(txs []*common.L2Tx) []*common.L2Tx {
+	if oc.maxBatchSize == 0 {
+		return txs
+	}
+	size := uint64(0)
+	for i, tx := range txs {
+		size += uint64(tx.Size())
+		if size > oc.maxBatchSize {
+			oc.logger.Info(fmt.Sprintf("Batch size limit reached. Deferring %d transactions to a later batch.", len(txs)-i))
+			return txs[:i]
+		}
+	}
+	return txs
+}
+
+// Returns the state of the chain at height. The trie nodes are served from the storage's shared trie cache.
 func (oc *ObscuroChain) getChainStateAtBlock(blockNumber *gethrpc.BlockNumber) (*state.StateDB, error) {
 	// We retrieve the batch of interest.
 	batch, err := oc.getBatch(*blockNumber)
@@ -885,7 +1162,11 @@ func (oc *ObscuroChain) CheckAndStoreBatch(batch *core.Batch) error {
 	// We check the batch.
 	var txReceipts types.Receipts
 	// TODO - #718 - Determine what level of checking we should perform on the genesis batch.
-	if !batch.IsGenesis() {
+	if batch.IsGenesis() {
+		if err := oc.isValidGenesisBatch(batch); err != nil {
+			return fmt.Errorf("genesis batch was invalid. Cause: %w", err)
+		}
+	} else {
 		var err error
 		txReceipts, err = oc.isInternallyValidBatch(batch)
 		if err != nil {
@@ -923,7 +1204,7 @@ func (oc *ObscuroChain) CheckAndStoreBatch(batch *core.Batch) error {
 
 	// If this is the genesis batch, we commit the genesis state.
 	if batch.IsGenesis() {
-		if err := oc.genesis.CommitGenesisState(oc.storage); err != nil {
+		if err := oc.genesis.CommitGenesisState(oc.storage, oc.chainConfig.EVM); err != nil {
 			return fmt.Errorf("could not apply genesis state. Cause: %w", err)
 		}
 	}
@@ -987,6 +1268,14 @@ func (c sortByTxIndex) Len() int           { return len(c) }
 func (c sortByTxIndex) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
 func (c sortByTxIndex) Less(i, j int) bool { return c[i].TransactionIndex < c[j].TransactionIndex }
 
+func gasUsed(receipts []*types.Receipt) uint64 {
+	total := uint64(0)
+	for _, receipt := range receipts {
+		total += receipt.GasUsed
+	}
+	return total
+}
+
 func allReceipts(txReceipts []*types.Receipt, depositReceipts []*types.Receipt) types.Receipts {
 	return append(txReceipts, depositReceipts...)
 }
@@ -0,0 +1,198 @@
+package l2chain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/go/enclave/evm"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// A contract declares the storage slots that anyone can read by implementing `publicStorageSlots()`, which returns the
+// slots as a `bytes32[]`. The contracts that do not implement it have no public slots. A contract authorises an account
+// to read any other slot by implementing `canReadStorage(address,bytes32)`, which returns a `bool`. The contracts that do
+// not implement it keep their other slots private from everyone, including their deployer, since the storage of e.g. a
+// token contract holds the balances of all its holders.
+
+const (
+	// The gas available to a contract to declare its public storage slots, or to authorise an account to read a slot.
+	publicStorageSlotsGasLimit = 1_000_000
+	// The number of declarations of public storage slots that are cached. eth_getStorageAt is served to anyone, so the
+	// declaration is only executed once per batch and contract.
+	publicStorageSlotsCacheSize = 1024
+)
+
+var (
+	publicStorageSlotsSelector = crypto.Keccak256([]byte("publicStorageSlots()"))[:4]
+	canReadStorageSelector     = crypto.Keccak256([]byte("canReadStorage(address,bytes32)"))[:4]
+
+	ErrPrivateStorageSlot    = errors.New("the contract does not declare the storage slot public")
+	ErrUnreadableStorageSlot = errors.New("the contract does not authorise the account to read the storage slot")
+	ErrNoContractDeployer    = errors.New("the contract was created by another contract, so it has no deployer")
+)
+
+// GetPublicStorageAt returns the value of the storage slot of the contract in the state of the given batch, or
+// ErrPrivateStorageSlot if the contract does not declare the slot public.
+func (oc *ObscuroChain) GetPublicStorageAt(address gethcommon.Address, slot gethcommon.Hash, batchHash common.L2RootHash) (gethcommon.Hash, error) {
+	batch, err := oc.storage.FetchBatch(batchHash)
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("could not retrieve batch. Cause: %w", err)
+	}
+	stateDB, err := oc.storage.CreateStateDB(batchHash)
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("could not create stateDB. Cause: %w", err)
+	}
+	// The value is read before the contract's code is executed, so that the declaration cannot alter it.
+	value := stateDB.GetState(address, slot)
+
+	publicSlots := oc.cachedPublicStorageSlots(address, stateDB, batch.Header)
+	for _, publicSlot := range publicSlots {
+		if publicSlot == slot {
+			return value, nil
+		}
+	}
+	return gethcommon.Hash{}, ErrPrivateStorageSlot
+}
+
+// GetStorageAt returns the value of the storage slot of the contract at the given height, or ErrUnreadableStorageSlot
+// if the contract does not authorise the requester to read it. The value is encrypted with the requester's viewing key.
+func (oc *ObscuroChain) GetStorageAt(requester gethcommon.Address, address gethcommon.Address, slot gethcommon.Hash, blockNumber *gethrpc.BlockNumber) (gethcommon.Hash, error) {
+	batch, err := oc.getBatch(*blockNumber)
+	if err != nil {
+		return gethcommon.Hash{}, err
+	}
+	stateDB, err := oc.storage.CreateStateDB(*batch.Hash())
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("could not create stateDB. Cause: %w", err)
+	}
+	if len(stateDB.GetCode(address)) == 0 {
+		return gethcommon.Hash{}, fmt.Errorf("account %s is not a contract", address)
+	}
+	// The value is read before the contract's code is executed, so that the authorisation cannot alter it.
+	value := stateDB.GetState(address, slot)
+
+	if !oc.canReadStorage(requester, address, slot, stateDB, batch.Header) {
+		return gethcommon.Hash{}, ErrUnreadableStorageSlot
+	}
+	return value, nil
+}
+
+// A contract and the batch in whose state its public storage slots were declared.
+type publicStorageSlotsKey struct {
+	batchHash common.L2RootHash
+	address   gethcommon.Address
+}
+
+// Returns the storage slots the contract declares public in the state of the batch, from the cache if the declaration
+// was already executed in that state.
+func (oc *ObscuroChain) cachedPublicStorageSlots(address gethcommon.Address, stateDB *state.StateDB, header *common.BatchHeader) []gethcommon.Hash {
+	key := publicStorageSlotsKey{batchHash: header.Hash(), address: address}
+	if publicSlots, found := oc.publicStorageSlotsCache.Get(key); found {
+		return publicSlots.([]gethcommon.Hash)
+	}
+	publicSlots := oc.publicStorageSlots(address, stateDB, header)
+	oc.publicStorageSlotsCache.Add(key, publicSlots)
+	return publicSlots
+}
+
+// Returns the storage slots the contract declares public. If the declaration cannot be executed or decoded, the
+// contract has no public slots.
+func (oc *ObscuroChain) publicStorageSlots(address gethcommon.Address, stateDB *state.StateDB, header *common.BatchHeader) []gethcommon.Hash {
+	if len(stateDB.GetCode(address)) == 0 {
+		return nil
+	}
+	msg := types.NewMessage(gethcommon.Address{}, &address, 0, gethcommon.Big0, publicStorageSlotsGasLimit, gethcommon.Big0, gethcommon.Big0, gethcommon.Big0, publicStorageSlotsSelector, nil, true)
+	result, err := evm.ExecuteOffChainCall(&msg, stateDB, header, oc.storage, oc.chainConfig.EVM, oc.logger)
+	if err != nil || result.Failed() {
+		return nil
+	}
+
+	bytes32Array, err := abi.NewType("bytes32[]", "", nil)
+	if err != nil {
+		oc.logger.Error("Could not create ABI type for public storage slots.", log.ErrKey, err)
+		return nil
+	}
+	decoded, err := abi.Arguments{{Type: bytes32Array}}.Unpack(result.ReturnData)
+	if err != nil || len(decoded) != 1 {
+		return nil
+	}
+	slots, ok := decoded[0].([][32]byte)
+	if !ok {
+		return nil
+	}
+	publicSlots := make([]gethcommon.Hash, len(slots))
+	for i, slot := range slots {
+		publicSlots[i] = slot
+	}
+	return publicSlots
+}
+
+// Returns whether the contract authorises the requester to read the storage slot. If the authorisation cannot be
+// executed or decoded, the requester is not authorised.
+func (oc *ObscuroChain) canReadStorage(requester gethcommon.Address, address gethcommon.Address, slot gethcommon.Hash, stateDB *state.StateDB, header *common.BatchHeader) bool {
+	addressType, err := abi.NewType("address", "", nil)
+	if err != nil {
+		oc.logger.Error("Could not create ABI type for storage authorisation.", log.ErrKey, err)
+		return false
+	}
+	bytes32Type, err := abi.NewType("bytes32", "", nil)
+	if err != nil {
+		oc.logger.Error("Could not create ABI type for storage authorisation.", log.ErrKey, err)
+		return false
+	}
+	args, err := abi.Arguments{{Type: addressType}, {Type: bytes32Type}}.Pack(requester, [32]byte(slot))
+	if err != nil {
+		oc.logger.Error("Could not encode storage authorisation arguments.", log.ErrKey, err)
+		return false
+	}
+
+	data := append(append([]byte{}, canReadStorageSelector...), args...)
+	msg := types.NewMessage(gethcommon.Address{}, &address, 0, gethcommon.Big0, publicStorageSlotsGasLimit, gethcommon.Big0, gethcommon.Big0, gethcommon.Big0, data, nil, true)
+	result, err := evm.ExecuteOffChainCall(&msg, stateDB, header, oc.storage, oc.chainConfig.EVM, oc.logger)
+	if err != nil || result.Failed() {
+		return false
+	}
+
+	boolType, err := abi.NewType("bool", "", nil)
+	if err != nil {
+		oc.logger.Error("Could not create ABI type for storage authorisation.", log.ErrKey, err)
+		return false
+	}
+	decoded, err := abi.Arguments{{Type: boolType}}.Unpack(result.ReturnData)
+	if err != nil || len(decoded) != 1 {
+		return false
+	}
+	authorised, ok := decoded[0].(bool)
+	return ok && authorised
+}
+
+// Returns the address of the account that deployed the contract, or ErrNoContractDeployer if the contract was created
+// by another contract (e.g. a factory), in which case no transaction records its creation.
+func (oc *ObscuroChain) contractDeployer(address gethcommon.Address) (*gethcommon.Address, error) {
+	txHash, err := oc.storage.GetContractCreationTx(address)
+	if err != nil {
+		if errors.Is(err, errutil.ErrNotFound) {
+			return nil, fmt.Errorf("could not find the deployer of contract %s. Cause: %w", address, ErrNoContractDeployer)
+		}
+		return nil, fmt.Errorf("could not retrieve contract creation transaction. Cause: %w", err)
+	}
+	transaction, _, _, _, err := oc.storage.GetTransaction(*txHash)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve contract creation transaction. Cause: %w", err)
+	}
+	signer := types.NewLondonSigner(oc.chainConfig.EVM.ChainID)
+	sender, err := signer.Sender(transaction)
+	if err != nil {
+		return nil, fmt.Errorf("could not recover sender of contract creation transaction. Cause: %w", err)
+	}
+	return &sender, nil
+}
@@ -0,0 +1,48 @@
+package l2chain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/go/enclave/chainconfig"
+	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
+	"github.com/obscuronet/go-obscuro/go/enclave/db"
+	"github.com/obscuronet/go-obscuro/integration"
+	"github.com/obscuronet/go-obscuro/integration/datagenerator"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+// Runtime code that returns the ABI encoding of a `bytes32[]` holding slot 1, whatever the call data.
+const declaresSlotOnePublicCode = "60206000526001602052600160405260606000f3"
+
+func TestContractsDeclareTheirPublicStorageSlots(t *testing.T) {
+	logger := log.New(log.TestLogCmp, int(gethlog.LvlError), log.SysOut)
+	storage := db.NewStorage(rawdb.NewMemoryDatabase(), nil, logger)
+	if err := storage.StoreSecret(crypto.SharedEnclaveSecret{}); err != nil {
+		t.Fatalf("could not store secret. Cause: %s", err)
+	}
+	chain := ObscuroChain{storage: storage, chainConfig: chainconfig.Default(integration.ObscuroChainID), logger: logger}
+	header := &common.BatchHeader{Number: big.NewInt(0)}
+
+	stateDB, err := storage.EmptyStateDB()
+	if err != nil {
+		t.Fatalf("could not create stateDB. Cause: %s", err)
+	}
+	declaringContract := datagenerator.RandomAddress()
+	stateDB.SetCode(declaringContract, gethcommon.Hex2Bytes(declaresSlotOnePublicCode))
+	silentContract := datagenerator.RandomAddress()
+	stateDB.SetCode(silentContract, []byte{0x00}) // STOP, so the declaration returns nothing
+
+	publicSlots := chain.publicStorageSlots(declaringContract, stateDB, header)
+	if len(publicSlots) != 1 || publicSlots[0] != gethcommon.BigToHash(big.NewInt(1)) {
+		t.Fatalf("expected slot 1 to be declared public, got %v", publicSlots)
+	}
+	if publicSlots = chain.publicStorageSlots(silentContract, stateDB, header); len(publicSlots) != 0 {
+		t.Fatalf("expected contract without declaration to have no public slots, got %v", publicSlots)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/obscuronet/go-obscuro/go/common/container"
 	enclavecontainer "github.com/obscuronet/go-obscuro/go/enclave/container"
@@ -9,11 +10,14 @@ import (
 
 // Runs an Obscuro enclave as a standalone process.
 func main() {
-	config, err := enclavecontainer.ParseConfig()
+	config, loader, err := enclavecontainer.ParseConfig()
 	if err != nil {
-		panic(fmt.Errorf("could not parse config. Cause: %w", err))
+		fmt.Printf("Could not load config. Cause: %s\n", err)
+		os.Exit(1)
 	}
+	fmt.Println(loader.Effective())
 
 	enclaveContainer := enclavecontainer.NewEnclaveContainerFromConfig(config)
+	enclaveContainer.WatchConfig(loader)
 	container.Serve(enclaveContainer)
 }
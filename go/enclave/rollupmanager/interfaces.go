@@ -6,9 +6,14 @@ import (
 )
 
 type RollupManager interface {
-	// CreateRollup - creates a rollup encapsulating the state from the
-	// latest published head batch to the most current headbatch.
-	CreateRollup() (*core.Rollup, error)
+	// CreateRollups - creates the rollups encapsulating the state from the
+	// latest published head batch to the most current headbatch. The batches
+	// are split across several chained rollups if a single rollup would exceed
+	// the maximum L1 calldata size.
+	CreateRollups() ([]*core.Rollup, error)
+	// DecidePublication - applies the publication policy to the batches
+	// pending publication, to decide whether it is time to create rollups.
+	DecidePublication() (*common.RollupDecision, error)
 	// ProcessL1Block - extracts the rollups from the block's transactions
 	// and verifies their integrity, saving and processing any batches that have
 	// not been seenp previously.
@@ -0,0 +1,113 @@
+package rollupmanager
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/enclave/core"
+)
+
+// defaultInclusionTimeout is used if the policy does not set an inclusion timeout.
+const defaultInclusionTimeout = 2 * time.Minute
+
+// PublicationPolicy configures when the sequencer publishes the batches pending publication in a rollup. A rollup is
+// due once any of the thresholds is reached. Zero disables a threshold. If all the thresholds are disabled, a rollup is
+// due whenever there are pending batches.
+type PublicationPolicy struct {
+	MaxPendingBatches uint64        // The number of pending batches
+	MaxPendingBytes   uint64        // The encoded size of the pending batches, before compression
+	MaxInterval       time.Duration // The time since the last rollup, measured using the batches' timestamps
+
+	// While the base fee of the latest L1 block is above MaxL1BaseFee, due rollups are deferred, until the time since the
+	// last rollup reaches L1BaseFeeDeadline.
+	MaxL1BaseFee      *big.Int
+	L1BaseFeeDeadline time.Duration
+
+	// How long to wait for a produced rollup to appear on the L1 before it is considered lost and its batches are
+	// rolled up again. No rollups are produced in the meantime, so that rollups are not published twice. Zero means
+	// `defaultInclusionTimeout`.
+	InclusionTimeout time.Duration
+}
+
+// The batches pending publication, and the rollup they follow.
+type pendingBatches struct {
+	lastRollup *core.Rollup // nil if no rollup has been produced yet
+	batches    []*core.Batch
+	l1BaseFee  *big.Int // nil if the L1 does not have a base fee
+}
+
+// Decides whether to publish the pending batches.
+func (p *PublicationPolicy) decide(pending *pendingBatches) *common.RollupDecision {
+	if len(pending.batches) == 0 {
+		return &common.RollupDecision{Publish: false, Reason: "no pending batches"}
+	}
+	if pending.lastRollup == nil {
+		return &common.RollupDecision{Publish: true, Reason: "no rollup has been published yet"}
+	}
+
+	headBatch := pending.batches[len(pending.batches)-1]
+	sinceLastRollup := time.Duration(0)
+	if headBatch.Header.Time > pending.lastRollup.Header.Time {
+		sinceLastRollup = time.Duration(headBatch.Header.Time-pending.lastRollup.Header.Time) * time.Second
+	}
+
+	reason := p.thresholdReached(pending.batches, sinceLastRollup)
+	if reason == "" {
+		return &common.RollupDecision{Publish: false, Reason: "no publication threshold reached"}
+	}
+
+	if p.MaxL1BaseFee != nil && pending.l1BaseFee != nil && pending.l1BaseFee.Cmp(p.MaxL1BaseFee) > 0 {
+		if sinceLastRollup < p.L1BaseFeeDeadline {
+			return &common.RollupDecision{
+				Publish: false,
+				Reason: fmt.Sprintf("%s, but the L1 base fee of %s exceeds the ceiling of %s until the deadline of %s",
+					reason, pending.l1BaseFee, p.MaxL1BaseFee, p.L1BaseFeeDeadline),
+			}
+		}
+		reason = fmt.Sprintf("%s, and the deadline of %s overrides the L1 base fee ceiling", reason, p.L1BaseFeeDeadline)
+	}
+	return &common.RollupDecision{Publish: true, Reason: reason}
+}
+
+// Returns a description of the first threshold reached, or the empty string if none are.
+func (p *PublicationPolicy) thresholdReached(batches []*core.Batch, sinceLastRollup time.Duration) string {
+	if p.MaxPendingBatches == 0 && p.MaxInterval == 0 && p.MaxPendingBytes == 0 {
+		return "no publication threshold is configured, so pending batches are published immediately"
+	}
+	if p.MaxPendingBatches != 0 && uint64(len(batches)) >= p.MaxPendingBatches {
+		return fmt.Sprintf("%d pending batches reached the maximum of %d", len(batches), p.MaxPendingBatches)
+	}
+	if p.MaxInterval != 0 && sinceLastRollup >= p.MaxInterval {
+		return fmt.Sprintf("%s since the last rollup reached the maximum of %s", sinceLastRollup, p.MaxInterval)
+	}
+	if p.MaxPendingBytes != 0 {
+		if size := pendingSize(batches); size >= p.MaxPendingBytes {
+			return fmt.Sprintf("%d pending bytes reached the maximum of %d", size, p.MaxPendingBytes)
+		}
+	}
+	return ""
+}
+
+func (p *PublicationPolicy) inclusionTimeout() time.Duration {
+	if p.InclusionTimeout == 0 {
+		return defaultInclusionTimeout
+	}
+	return p.InclusionTimeout
+}
+
+// Returns the encoded size of the batches' headers and transactions.
+func pendingSize(batches []*core.Batch) uint64 {
+	size := uint64(0)
+	for _, batch := range batches {
+		if encodedHeader, err := rlp.EncodeToBytes(batch.Header); err == nil {
+			size += uint64(len(encodedHeader))
+		}
+		for _, tx := range batch.Transactions {
+			size += uint64(tx.Size())
+		}
+	}
+	return size
+}
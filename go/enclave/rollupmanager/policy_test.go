@@ -0,0 +1,61 @@
+package rollupmanager
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/enclave/core"
+)
+
+func TestPublicationPolicy(t *testing.T) {
+	policy := PublicationPolicy{
+		MaxPendingBatches: 3,
+		MaxInterval:       time.Minute,
+		MaxL1BaseFee:      big.NewInt(100),
+		L1BaseFeeDeadline: time.Hour,
+	}
+	lastRollup := &core.Rollup{Header: &common.RollupHeader{Time: 1000}}
+
+	testCases := []struct {
+		name    string
+		pending *pendingBatches
+		publish bool
+	}{
+		{"no pending batches", &pendingBatches{lastRollup: lastRollup}, false},
+		{"first rollup", &pendingBatches{batches: batchesAt(1010)}, true},
+		{"no threshold reached", &pendingBatches{lastRollup: lastRollup, batches: batchesAt(1010, 1020)}, false},
+		{"batch threshold reached", &pendingBatches{lastRollup: lastRollup, batches: batchesAt(1010, 1020, 1030)}, true},
+		{"interval threshold reached", &pendingBatches{lastRollup: lastRollup, batches: batchesAt(1060)}, true},
+		{"L1 base fee too high", &pendingBatches{lastRollup: lastRollup, batches: batchesAt(1060), l1BaseFee: big.NewInt(101)}, false},
+		{"L1 base fee deadline reached", &pendingBatches{lastRollup: lastRollup, batches: batchesAt(4600), l1BaseFee: big.NewInt(101)}, true},
+	}
+
+	for _, testCase := range testCases {
+		decision := policy.decide(testCase.pending)
+		if decision.Publish != testCase.publish {
+			t.Errorf("%s: expected publish=%t, got publish=%t (%s)", testCase.name, testCase.publish, decision.Publish, decision.Reason)
+		}
+	}
+}
+
+func batchesAt(times ...uint64) []*core.Batch {
+	batches := make([]*core.Batch, len(times))
+	for idx, batchTime := range times {
+		batches[idx] = &core.Batch{Header: &common.BatchHeader{Time: batchTime}}
+	}
+	return batches
+}
+
+func TestPublicationPolicyWithoutThresholdsPublishesPendingBatches(t *testing.T) {
+	policy := PublicationPolicy{}
+	lastRollup := &core.Rollup{Header: &common.RollupHeader{Time: 1000}}
+
+	if decision := policy.decide(&pendingBatches{lastRollup: lastRollup, batches: batchesAt(1001)}); !decision.Publish {
+		t.Errorf("expected pending batches to be published, got: %s", decision.Reason)
+	}
+	if decision := policy.decide(&pendingBatches{lastRollup: lastRollup}); decision.Publish {
+		t.Error("expected nothing to be published without pending batches")
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"math/big"
 	"sort"
+	"sync"
+	"time"
 
 	gethlog "github.com/ethereum/go-ethereum/log"
 
@@ -21,6 +23,7 @@ import (
 	"github.com/obscuronet/go-obscuro/go/enclave/l2chain"
 
 	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/obscuronet/go-obscuro/go/enclave/core"
 	"github.com/obscuronet/go-obscuro/go/enclave/db"
 	"github.com/obscuronet/go-obscuro/go/ethadapter/mgmtcontractlib"
@@ -37,6 +40,14 @@ type rollupManager struct {
 	ObscuroChainID  int64
 	EthereumChainID int64
 
+	// The maximum size in bytes of the calldata of a rollup transaction, or zero if there is no limit.
+	maxRollupSize uint64
+	policy        PublicationPolicy
+
+	// Guards the in-flight rollup, i.e. the last rollup produced until it is seen on the L1. It is persisted, so that
+	// a restarted sequencer does not publish its batches again.
+	inFlightMutex sync.Mutex
+
 	logger gethlog.Logger
 
 	l2chain *l2chain.ObscuroChain
@@ -48,6 +59,8 @@ func New(
 	transactionBlobCrypto crypto.TransactionBlobCrypto,
 	obscuroChainID int64,
 	ethereumChainID int64,
+	maxRollupSize uint64,
+	policy PublicationPolicy,
 	storage db.Storage,
 	l2chain *l2chain.ObscuroChain,
 	logger gethlog.Logger,
@@ -57,6 +70,8 @@ func New(
 		TransactionBlobCrypto: transactionBlobCrypto,
 		ObscuroChainID:        obscuroChainID,
 		EthereumChainID:       ethereumChainID,
+		maxRollupSize:         maxRollupSize,
+		policy:                policy,
 		logger:                logger,
 		l2chain:               l2chain,
 		storage:               storage,
@@ -92,8 +107,7 @@ func createNextRollup(rollup *core.Rollup, batches []*core.Batch) *core.Rollup {
 
 	rollupHeight := big.NewInt(0)
 	if rollup != nil {
-		rollupHeight = rollup.Header.Number
-		rollupHeight.Add(rollupHeight, gethcommon.Big1)
+		rollupHeight.Add(rollup.Header.Number, gethcommon.Big1)
 	}
 
 	rh.Number = rollupHeight
@@ -105,7 +119,7 @@ func createNextRollup(rollup *core.Rollup, batches []*core.Batch) *core.Rollup {
 	}
 }
 
-func (re *rollupManager) CreateRollup() (*core.Rollup, error) {
+func (re *rollupManager) CreateRollups() ([]*core.Rollup, error) {
 	rollup, err := re.fetchLatestRollup()
 	if err != nil && !errors.Is(err, db.ErrNoRollups) {
 		return nil, err
@@ -129,13 +143,156 @@ func (re *rollupManager) CreateRollup() (*core.Rollup, error) {
 		return nil, fmt.Errorf("current head batch matches the rollup head bash")
 	}
 
-	newRollup := createNextRollup(rollup, batches)
+	// We encrypt the batches once, rather than each time we measure the size of a candidate rollup.
+	extBatches := make([]*common.ExtBatch, len(batches))
+	for idx, batch := range batches {
+		extBatches[idx] = batch.ToExtBatch(re.TransactionBlobCrypto)
+	}
+
+	var rollups []*core.Rollup
+	for len(batches) > 0 {
+		newRollup, batchCount, err := re.createRollupWithinSizeLimit(rollup, batches, extBatches)
+		if err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, newRollup)
+		rollup = newRollup
+		batches = batches[batchCount:]
+		extBatches = extBatches[batchCount:]
+	}
+
+	if len(rollups) > 1 {
+		re.logger.Info(fmt.Sprintf("Split batches across %d rollups to stay within the maximum rollup size.", len(rollups)))
+	}
+
+	re.inFlightMutex.Lock()
+	defer re.inFlightMutex.Unlock()
+	if err = re.storage.StoreInFlightRollup(rollups[len(rollups)-1].NumberU64(), time.Now()); err != nil {
+		return nil, fmt.Errorf("could not store in-flight rollup. Cause: %w", err)
+	}
+
+	return rollups, nil
+}
+
+func (re *rollupManager) DecidePublication() (*common.RollupDecision, error) {
+	publishedRollup, err := re.fetchLatestRollup()
+	if err != nil && !errors.Is(err, db.ErrNoRollups) {
+		return nil, fmt.Errorf("could not retrieve latest rollup. Cause: %w", err)
+	}
 
-	if err := re.l2chain.SignRollup(newRollup); err != nil {
+	decision, err := re.awaitInFlightRollup(publishedRollup)
+	if err != nil {
 		return nil, err
 	}
+	if decision != nil {
+		return decision, nil
+	}
 
-	return newRollup, nil
+	hash := gethcommon.Hash{}
+	if publishedRollup != nil {
+		hash = publishedRollup.Header.HeadBatchHash
+	}
+	batches, err := re.l2chain.BatchesAfter(hash)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve pending batches. Cause: %w", err)
+	}
+	l1Head, err := re.storage.FetchHeadBlock()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve L1 head. Cause: %w", err)
+	}
+
+	return re.policy.decide(&pendingBatches{lastRollup: publishedRollup, batches: batches, l1BaseFee: l1Head.BaseFee()}), nil
+}
+
+// Returns a decision not to publish if the last rollup produced has not been seen on the L1 yet, or nil otherwise.
+func (re *rollupManager) awaitInFlightRollup(publishedRollup *core.Rollup) (*common.RollupDecision, error) {
+	re.inFlightMutex.Lock()
+	defer re.inFlightMutex.Unlock()
+
+	inFlightNumber, producedAt, err := re.storage.FetchInFlightRollup()
+	if err != nil {
+		if errors.Is(err, errutil.ErrNotFound) {
+			return nil, nil //nolint:nilnil
+		}
+		return nil, fmt.Errorf("could not retrieve in-flight rollup. Cause: %w", err)
+	}
+
+	if publishedRollup != nil && publishedRollup.NumberU64() >= inFlightNumber {
+		return nil, re.clearInFlightRollup()
+	}
+	if inclusionTimeout := re.policy.inclusionTimeout(); time.Since(producedAt) >= inclusionTimeout {
+		re.logger.Warn(fmt.Sprintf("Rollup %d was not seen on the L1 within %s. Rolling up its batches again.",
+			inFlightNumber, inclusionTimeout))
+		return nil, re.clearInFlightRollup()
+	}
+	return &common.RollupDecision{
+		Publish: false,
+		Reason:  fmt.Sprintf("rollup %d is awaiting inclusion on the L1", inFlightNumber),
+	}, nil
+}
+
+func (re *rollupManager) clearInFlightRollup() error {
+	if err := re.storage.DeleteInFlightRollup(); err != nil {
+		return fmt.Errorf("could not clear in-flight rollup. Cause: %w", err)
+	}
+	return nil
+}
+
+// Creates and signs the next rollup, containing as many of the batches as fit within the maximum rollup size. Returns
+// the rollup and the number of batches it contains.
+func (re *rollupManager) createRollupWithinSizeLimit(previousRollup *core.Rollup, batches []*core.Batch, extBatches []*common.ExtBatch) (*core.Rollup, int, error) {
+	createRollup := func(batchCount int) (*core.Rollup, bool, error) {
+		newRollup := createNextRollup(previousRollup, batches[:batchCount])
+		if err := re.l2chain.SignRollup(newRollup); err != nil {
+			return nil, false, err
+		}
+		encodedRollup, err := common.EncodeRollup(&common.ExtRollup{Header: newRollup.Header, Batches: extBatches[:batchCount]})
+		if err != nil {
+			return nil, false, fmt.Errorf("could not encode rollup. Cause: %w", err)
+		}
+		if len(encodedRollup) > common.MaxEncodedRollupSize {
+			return newRollup, false, nil
+		}
+		if re.maxRollupSize == 0 {
+			return newRollup, true, nil
+		}
+		return newRollup, re.calldataSize(encodedRollup) <= re.maxRollupSize, nil
+	}
+
+	newRollup, fits, err := createRollup(len(batches))
+	if err != nil || fits {
+		return newRollup, len(batches), err
+	}
+
+	// We search for the largest number of batches that fit. A single batch is always included, even if it is too large,
+	// since a batch cannot be split.
+	fittingRollup, fittingCount := (*core.Rollup)(nil), 0
+	low, high := 1, len(batches)-1
+	for low <= high {
+		mid := (low + high) / 2
+		candidate, fits, err := createRollup(mid)
+		if err != nil {
+			return nil, 0, err
+		}
+		if fits {
+			fittingRollup, fittingCount = candidate, mid
+			low = mid + 1
+		} else {
+			high = mid - 1
+		}
+	}
+	if fittingRollup == nil {
+		re.logger.Warn(fmt.Sprintf("Batch %d exceeds the maximum rollup size on its own.", batches[0].NumberU64()))
+		fittingRollup, _, err = createRollup(1)
+		return fittingRollup, 1, err
+	}
+	return fittingRollup, fittingCount, nil
+}
+
+// Returns the size of the calldata of the L1 transaction publishing the rollup.
+func (re *rollupManager) calldataSize(encodedRollup common.EncodedRollup) uint64 {
+	rollupTx := re.MgmtContractLib.CreateRollup(&ethadapter.L1RollupTx{Rollup: encodedRollup}, 0)
+	return uint64(len(types.NewTx(rollupTx).Data()))
 }
 
 func (re *rollupManager) ProcessL1Block(br *common.BlockAndReceipts) ([]*core.Rollup, error) {
@@ -168,7 +325,12 @@ func (re *rollupManager) extractRollups(br *common.BlockAndReceipts, blockResolv
 		// Ignore rollups created with proofs from different L1 blocks
 		// In case of L1 reorgs, rollups may end published on a fork
 		if blockResolver.IsBlockAncestor(b, r.Header.L1Proof) {
-			rollups = append(rollups, core.ToRollup(r, re.TransactionBlobCrypto))
+			rollup, err := core.ToRollup(r, re.TransactionBlobCrypto)
+			if err != nil {
+				re.logger.Error(fmt.Sprintf("Could not decrypt rollup r_%d.", common.ShortHash(r.Hash())), log.ErrKey, err)
+				continue
+			}
+			rollups = append(rollups, rollup)
 			re.logger.Trace(fmt.Sprintf("Extracted Rollup r_%d from block b_%d",
 				common.ShortHash(r.Hash()),
 				common.ShortHash(b.Hash()),
@@ -227,7 +389,7 @@ func (re *rollupManager) processRollups(br *common.BlockAndReceipts) ([]*core.Ro
 			}
 		}
 
-		if err = re.storage.StoreRollup(rollup); err != nil {
+		if err = re.storage.StoreRollup(rollup, blockHash); err != nil {
 			return nil, fmt.Errorf("could not store rollup. Cause: %w", err)
 		}
 	}
@@ -0,0 +1,141 @@
+package rollupmanager
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/go/enclave/core"
+	"github.com/obscuronet/go-obscuro/go/enclave/db"
+	"github.com/obscuronet/go-obscuro/go/enclave/genesis"
+	"github.com/obscuronet/go-obscuro/go/enclave/l2chain"
+	"github.com/obscuronet/go-obscuro/go/ethadapter/mgmtcontractlib"
+	"github.com/obscuronet/go-obscuro/integration/datagenerator"
+
+	gethlog "github.com/ethereum/go-ethereum/log"
+	obscurocrypto "github.com/obscuronet/go-obscuro/go/enclave/crypto"
+)
+
+func TestRollupsAreSplitToStayWithinTheSizeLimit(t *testing.T) {
+	logger := log.New(log.TestLogCmp, int(gethlog.LvlError), log.SysOut)
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg := datagenerator.RandomAddress()
+	chain := l2chain.New(agg, common.Sequencer, nil, nil, nil, nil, privateKey, nil, agg, &genesis.Genesis{}, false, 0, 0, logger)
+	txBlobCrypto := obscurocrypto.NewTransactionBlobCryptoImpl(logger)
+	manager := &rollupManager{
+		MgmtContractLib:       mgmtcontractlib.NewMgmtContractLib(nil, logger),
+		TransactionBlobCrypto: txBlobCrypto,
+		logger:                logger,
+		l2chain:               chain,
+	}
+
+	// We create a chain of batches with a few transactions each.
+	l1Proof := datagenerator.RandomAddress().Hash()
+	parent := &common.BatchHeader{Number: big.NewInt(0)}
+	batches := make([]*core.Batch, 4)
+	extBatches := make([]*common.ExtBatch, len(batches))
+	for idx := range batches {
+		batch, err := core.EmptyBatch(agg, parent, l1Proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		batch.Transactions = []*common.L2Tx{datagenerator.CreateL2Tx(), datagenerator.CreateL2Tx(), datagenerator.CreateL2Tx()}
+		batches[idx] = batch
+		extBatches[idx] = batch.ToExtBatch(txBlobCrypto)
+		parent = batch.Header
+	}
+
+	// Without a limit, all the batches go in a single rollup.
+	rollup, batchCount, err := manager.createRollupWithinSizeLimit(nil, batches, extBatches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batchCount != len(batches) || rollup.Header.HeadBatchHash != *batches[len(batches)-1].Hash() {
+		t.Fatalf("expected all %d batches in the rollup, got %d", len(batches), batchCount)
+	}
+
+	// With a limit that fits two batches, only the first two batches go in the rollup.
+	twoBatchRollup := createNextRollup(nil, batches[:2])
+	if err = chain.SignRollup(twoBatchRollup); err != nil {
+		t.Fatal(err)
+	}
+	encodedTwoBatchRollup, err := common.EncodeRollup(&common.ExtRollup{Header: twoBatchRollup.Header, Batches: extBatches[:2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	twoBatchSize := manager.calldataSize(encodedTwoBatchRollup)
+	// We leave some room, since the length of the signature varies.
+	manager.maxRollupSize = twoBatchSize + 8
+	rollup, batchCount, err = manager.createRollupWithinSizeLimit(nil, batches, extBatches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batchCount != 2 || rollup.Header.HeadBatchHash != *batches[1].Hash() {
+		t.Fatalf("expected the first 2 batches in the rollup, got %d", batchCount)
+	}
+
+	// A batch that exceeds the limit on its own still goes in a rollup by itself, since batches cannot be split.
+	manager.maxRollupSize = 1
+	_, batchCount, err = manager.createRollupWithinSizeLimit(nil, batches, extBatches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batchCount != 1 {
+		t.Fatalf("expected a single batch in the rollup, got %d", batchCount)
+	}
+}
+
+func TestRollupsAreNotProducedWhileTheLastRollupIsInFlight(t *testing.T) {
+	logger := log.New(log.TestLogCmp, int(gethlog.LvlError), log.SysOut)
+	storage := db.NewStorage(rawdb.NewMemoryDatabase(), nil, logger)
+	manager := &rollupManager{policy: PublicationPolicy{InclusionTimeout: time.Hour}, storage: storage, logger: logger}
+	rollupAt := func(number int64) *core.Rollup {
+		return &core.Rollup{Header: &common.RollupHeader{Number: big.NewInt(number)}}
+	}
+
+	// Without an in-flight rollup, the publication policy applies.
+	assertAwaitingInclusion(t, manager, rollupAt(1), false)
+
+	// A rollup that has not been seen on the L1 is awaited, even by a restarted sequencer.
+	if err := storage.StoreInFlightRollup(2, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	assertAwaitingInclusion(t, manager, rollupAt(1), true)
+	restartedManager := &rollupManager{policy: manager.policy, storage: storage, logger: logger}
+	assertAwaitingInclusion(t, restartedManager, rollupAt(1), true)
+
+	// Once the rollup is seen on the L1, it is no longer awaited.
+	assertAwaitingInclusion(t, manager, rollupAt(2), false)
+	assertAwaitingInclusion(t, manager, rollupAt(1), false)
+
+	// A rollup that is not seen on the L1 within the inclusion timeout is considered lost.
+	if err := storage.StoreInFlightRollup(3, time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	assertAwaitingInclusion(t, manager, rollupAt(2), false)
+
+	// A zero inclusion timeout means the default timeout, rather than no wait at all.
+	manager.policy.InclusionTimeout = 0
+	if err := storage.StoreInFlightRollup(3, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	assertAwaitingInclusion(t, manager, rollupAt(2), true)
+}
+
+func assertAwaitingInclusion(t *testing.T, manager *rollupManager, publishedRollup *core.Rollup, awaiting bool) {
+	t.Helper()
+	decision, err := manager.awaitInFlightRollup(publishedRollup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if (decision != nil) != awaiting {
+		t.Fatalf("expected awaiting inclusion to be %t", awaiting)
+	}
+}
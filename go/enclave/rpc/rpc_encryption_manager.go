@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"sync"
 
 	"github.com/obscuronet/go-obscuro/go/common"
 
@@ -24,9 +25,13 @@ const ViewingKeySignedMsgPrefix = "vk"
 // Used when the result to an eth_call is equal to nil. Attempting to encrypt then decrypt nil using ECIES throws an exception.
 var placeholderResult = []byte("0x")
 
+// The number of epochs of the network secret whose enclave keys are accepted, so that clients that fetched the enclave's
+// public key before a rotation can keep sending requests until they fetch the new key.
+const acceptedEnclaveKeys = 2
+
 // EncryptionManager manages the decryption and encryption of sensitive RPC requests.
 type EncryptionManager struct {
-	enclavePrivateKeyECIES *ecies.PrivateKey
+	enclaveKeys *enclaveKeys
 	// TODO - Replace with persistent storage.
 	// TODO - Handle multiple viewing keys per address.
 	viewingKeys map[gethcommon.Address]*ecies.PublicKey // Maps account addresses to viewing public keys.
@@ -34,14 +39,32 @@ type EncryptionManager struct {
 
 func NewEncryptionManager(enclavePrivateKeyECIES *ecies.PrivateKey) EncryptionManager {
 	return EncryptionManager{
-		enclavePrivateKeyECIES: enclavePrivateKeyECIES,
-		viewingKeys:            make(map[gethcommon.Address]*ecies.PublicKey),
+		enclaveKeys: &enclaveKeys{keys: []*ecies.PrivateKey{enclavePrivateKeyECIES}},
+		viewingKeys: make(map[gethcommon.Address]*ecies.PublicKey),
+	}
+}
+
+// AddEnclaveKey sets the enclave's private key for a new epoch of the network secret. Requests encrypted with the key of
+// the previous epoch are still accepted.
+func (rpc *EncryptionManager) AddEnclaveKey(enclavePrivateKeyECIES *ecies.PrivateKey) {
+	rpc.enclaveKeys.mutex.Lock()
+	defer rpc.enclaveKeys.mutex.Unlock()
+	rpc.enclaveKeys.keys = append([]*ecies.PrivateKey{enclavePrivateKeyECIES}, rpc.enclaveKeys.keys...)
+	if len(rpc.enclaveKeys.keys) > acceptedEnclaveKeys {
+		rpc.enclaveKeys.keys = rpc.enclaveKeys.keys[:acceptedEnclaveKeys]
 	}
 }
 
+// EnclavePublicKey returns the compressed public key that clients should encrypt their requests with.
+func (rpc *EncryptionManager) EnclavePublicKey() []byte {
+	rpc.enclaveKeys.mutex.RLock()
+	defer rpc.enclaveKeys.mutex.RUnlock()
+	return crypto.CompressPubkey(rpc.enclaveKeys.keys[0].PublicKey.ExportECDSA())
+}
+
 // DecryptBytes decrypts the bytes with the enclave's private key.
 func (rpc *EncryptionManager) DecryptBytes(encryptedBytes []byte) ([]byte, error) {
-	bytes, err := rpc.enclavePrivateKeyECIES.Decrypt(encryptedBytes, nil, nil)
+	bytes, err := rpc.enclaveKeys.decrypt(encryptedBytes)
 	if err != nil {
 		return nil, fmt.Errorf("could not decrypt bytes with enclave private key. Cause: %w", err)
 	}
@@ -52,7 +75,7 @@ func (rpc *EncryptionManager) DecryptBytes(encryptedBytes []byte) ([]byte, error
 // AddViewingKey - see the description of Enclave.AddViewingKey.
 func (rpc *EncryptionManager) AddViewingKey(encryptedViewingKeyBytes []byte, signature []byte) error {
 	// We decrypt the viewing key.
-	viewingKeyBytes, err := rpc.enclavePrivateKeyECIES.Decrypt(encryptedViewingKeyBytes, nil, nil)
+	viewingKeyBytes, err := rpc.enclaveKeys.decrypt(encryptedViewingKeyBytes)
 	if err != nil {
 		return fmt.Errorf("could not decrypt viewing key when adding it to enclave. Cause: %w", err)
 	}
@@ -114,3 +137,24 @@ func (rpc *EncryptionManager) AuthenticateSubscriptionRequest(subscription commo
 
 	return nil
 }
+
+// enclaveKeys holds the enclave's private keys for the accepted epochs, most recent first.
+type enclaveKeys struct {
+	keys  []*ecies.PrivateKey
+	mutex sync.RWMutex
+}
+
+// Decrypts the bytes with the first accepted key that they were encrypted with.
+func (k *enclaveKeys) decrypt(encryptedBytes []byte) ([]byte, error) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	var err error
+	for _, key := range k.keys {
+		var bytes []byte
+		if bytes, err = key.Decrypt(encryptedBytes, nil, nil); err == nil {
+			return bytes, nil
+		}
+	}
+	return nil, err
+}
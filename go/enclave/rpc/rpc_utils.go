@@ -5,8 +5,12 @@ import (
 	"fmt"
 
 	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/obscuronet/go-obscuro/go/common"
+
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
 )
 
 // ExtractTxHash returns the transaction hash from the params of an eth_getTransactionReceipt request.
@@ -51,6 +55,89 @@ func ExtractAddress(getTransactionCountParams []byte) (gethcommon.Address, error
 	return txHash, err
 }
 
+// ExtractInboundMessageParams returns the sender and, if given, the sequence number from the params of an
+// obscuro_getInboundMessageRecords request.
+func ExtractInboundMessageParams(getInboundMessagesParams []byte) (gethcommon.Address, *uint64, error) {
+	var paramsJSONList []string
+	err := json.Unmarshal(getInboundMessagesParams, &paramsJSONList)
+	if err != nil {
+		return gethcommon.Address{}, nil, fmt.Errorf("could not parse JSON params in obscuro_getInboundMessageRecords request. Cause: %w", err)
+	}
+	if len(paramsJSONList) < 1 || len(paramsJSONList) > 2 {
+		return gethcommon.Address{}, nil, fmt.Errorf("expected the sender and optionally a sequence number but received %d params", len(paramsJSONList))
+	}
+	sender := gethcommon.HexToAddress(paramsJSONList[0])
+	if len(paramsJSONList) == 1 {
+		return sender, nil, nil
+	}
+
+	sequence, err := hexutil.DecodeUint64(paramsJSONList[1])
+	if err != nil {
+		return gethcommon.Address{}, nil, fmt.Errorf("could not parse sequence number in obscuro_getInboundMessageRecords request. Cause: %w", err)
+	}
+	return sender, &sequence, nil
+}
+
+// ExtractStorageAtParams returns the contract address, the storage slot, the block number (the latest block if it is not
+// given) and the requesting account from the params of an obscuro_getStorageAt request. The requesting account is
+// always the last param.
+func ExtractStorageAtParams(getStorageAtParams []byte) (gethcommon.Address, gethcommon.Hash, *gethrpc.BlockNumber, gethcommon.Address, error) {
+	var paramsJSONList []string
+	err := json.Unmarshal(getStorageAtParams, &paramsJSONList)
+	if err != nil {
+		return gethcommon.Address{}, gethcommon.Hash{}, nil, gethcommon.Address{}, fmt.Errorf("could not parse JSON params in obscuro_getStorageAt request. Cause: %w", err)
+	}
+	if len(paramsJSONList) < 3 || len(paramsJSONList) > 4 {
+		return gethcommon.Address{}, gethcommon.Hash{}, nil, gethcommon.Address{}, fmt.Errorf("expected the address, the slot, optionally a block number and the requesting account but received %d params", len(paramsJSONList))
+	}
+	address := gethcommon.HexToAddress(paramsJSONList[0])
+	slot := gethcommon.HexToHash(paramsJSONList[1])
+	requester := gethcommon.HexToAddress(paramsJSONList[len(paramsJSONList)-1])
+
+	blockNumber := gethrpc.LatestBlockNumber
+	if len(paramsJSONList) == 4 {
+		if err = blockNumber.UnmarshalJSON([]byte(paramsJSONList[2])); err != nil {
+			return gethcommon.Address{}, gethcommon.Hash{}, nil, gethcommon.Address{}, fmt.Errorf("could not parse block number in obscuro_getStorageAt request. Cause: %w", err)
+		}
+	}
+	return address, slot, &blockNumber, requester, nil
+}
+
+// ExtractTraceTxParams returns the transaction hash and, if given, the trace config from the params of a
+// debug_traceTransaction request.
+func ExtractTraceTxParams(traceTxParams []byte) (gethcommon.Hash, *tracers.TraceConfig, error) {
+	var paramsJSONList []json.RawMessage
+	err := json.Unmarshal(traceTxParams, &paramsJSONList)
+	if err != nil {
+		return gethcommon.Hash{}, nil, fmt.Errorf("could not parse JSON params in debug_traceTransaction request. Cause: %w", err)
+	}
+	if len(paramsJSONList) < 1 || len(paramsJSONList) > 2 {
+		return gethcommon.Hash{}, nil, fmt.Errorf("expected the tx hash and optionally a trace config but received %d params", len(paramsJSONList))
+	}
+	var txHash gethcommon.Hash
+	if err = json.Unmarshal(paramsJSONList[0], &txHash); err != nil {
+		return gethcommon.Hash{}, nil, fmt.Errorf("could not parse tx hash in debug_traceTransaction request. Cause: %w", err)
+	}
+	if len(paramsJSONList) == 1 {
+		return txHash, nil, nil
+	}
+	config, err := ExtractTraceConfig(paramsJSONList[1])
+	if err != nil {
+		return gethcommon.Hash{}, nil, err
+	}
+	return txHash, config, nil
+}
+
+// ExtractTraceConfig returns the trace config from a param of a debug_traceTransaction or debug_traceCall request, or
+// nil if the param is null.
+func ExtractTraceConfig(configParam []byte) (*tracers.TraceConfig, error) {
+	var config *tracers.TraceConfig
+	if err := json.Unmarshal(configParam, &config); err != nil {
+		return nil, fmt.Errorf("could not parse trace config. Cause: %w", err)
+	}
+	return config, nil
+}
+
 // GetSender returns the address whose viewing key should be used to encrypt the response,
 // given a transaction.
 func GetSender(tx *common.L2Tx) (gethcommon.Address, error) {
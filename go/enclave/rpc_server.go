@@ -2,10 +2,13 @@ package enclave
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -15,6 +18,7 @@ import (
 	"github.com/obscuronet/go-obscuro/go/common/rpc/generated"
 	"github.com/obscuronet/go-obscuro/go/enclave/evm"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	gethlog "github.com/ethereum/go-ethereum/log"
@@ -28,13 +32,21 @@ type RPCServer struct {
 	grpcServer    *grpc.Server
 	logger        gethlog.Logger
 	listenAddress string
+	stopped       chan struct{} // closed when the server stops, ending the event streams
+	stopOnce      sync.Once
 }
 
 // NewEnclaveRPCServer prepares an enclave RPCServer (doesn't start listening until `StartServer` is called
-func NewEnclaveRPCServer(listenAddress string, enclave common.Enclave, logger gethlog.Logger) *RPCServer {
+// If `tlsConfig` is not nil, the server only accepts calls over TLS from the peers it authenticates.
+func NewEnclaveRPCServer(listenAddress string, enclave common.Enclave, tlsConfig *tls.Config, logger gethlog.Logger) *RPCServer {
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
 	return &RPCServer{
 		enclave:       enclave,
-		grpcServer:    grpc.NewServer(),
+		grpcServer:    grpc.NewServer(opts...),
+		stopped:       make(chan struct{}),
 		logger:        logger,
 		listenAddress: listenAddress,
 	}
@@ -95,30 +107,83 @@ func (s *RPCServer) InitEnclave(_ context.Context, request *generated.InitEnclav
 	return &generated.InitEnclaveResponse{Error: errStr}, nil
 }
 
-func (s *RPCServer) SubmitL1Block(_ context.Context, request *generated.SubmitBlockRequest) (*generated.SubmitBlockResponse, error) {
+// Ingest processes the blocks and batches streamed by the host in order, acknowledging each of them in turn.
+func (s *RPCServer) Ingest(stream generated.EnclaveProto_IngestServer) error {
+	for {
+		request, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		response := &generated.IngestResponse{}
+		switch item := request.Item.(type) {
+		case *generated.IngestRequest_Block:
+			response.BlockError = s.submitL1Block(item.Block)
+		case *generated.IngestRequest_Batch:
+			if err = s.enclave.SubmitBatch(rpc.FromExtBatchMsg(item.Batch.Batch)); err != nil {
+				response.Error = err.Error()
+			}
+		default:
+			response.Error = "ingest request carried neither a block nor a batch"
+		}
+		if err = stream.Send(response); err != nil {
+			return err
+		}
+	}
+}
+
+// Submits the block to the enclave, returning the rejection if the enclave could not ingest it.
+func (s *RPCServer) submitL1Block(request *generated.SubmitBlockRequest) *generated.BlockSubmissionErrorMsg {
 	bl := s.decodeBlock(request.EncodedBlock)
 	receipts := s.decodeReceipts(request.EncodedReceipts)
-	blockSubmissionResponse, err := s.enclave.SubmitL1Block(bl, receipts, request.IsLatest)
+	err := s.enclave.SubmitL1Block(bl, receipts, request.IsLatest)
+	if err == nil {
+		return nil
+	}
+	var rejErr *common.BlockRejectError
+	if !errors.As(err, &rejErr) {
+		// without an L1 head, the host continues with its existing stream of blocks
+		rejErr = &common.BlockRejectError{Wrapped: err}
+	}
+	return rpc.ToBlockSubmissionErrorMsg(rejErr)
+}
+
+// StreamEvents pushes the enclave's events to the host until the host closes the stream or the server stops.
+func (s *RPCServer) StreamEvents(request *generated.StreamEventsRequest, stream generated.EnclaveProto_StreamEventsServer) error {
+	events, closeStream, err := s.enclave.StreamEvents(request.After)
 	if err != nil {
-		var rejErr *common.BlockRejectError
-		isReject := errors.As(err, &rejErr)
-		if isReject {
-			// todo: we should avoid errors in response messages and use the gRPC error objects for this stuff (standardized across all enclave responses)
-			msg, err := rpc.ToBlockSubmissionRejectionMsg(rejErr)
-			if err == nil {
-				// send back reject err response
-				return &generated.SubmitBlockResponse{BlockSubmissionResponse: &msg}, nil
+		return err
+	}
+	defer closeStream()
+
+	// An empty event tells the host the stream is open, so that no event it triggers afterwards is missed.
+	if err = stream.Send(&generated.EnclaveEventMsg{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case event := <-events:
+			msg, err := rpc.ToEnclaveEventMsg(event)
+			if err != nil {
+				return err
+			}
+			if err = stream.Send(&msg); err != nil {
+				return err
 			}
-			s.logger.Warn("failed to process the BlockRejectError, falling back to original error")
+		case <-stream.Context().Done():
+			return nil
+		case <-s.stopped:
+			return nil
 		}
-		return nil, err
 	}
+}
 
-	msg, err := rpc.ToBlockSubmissionResponseMsg(blockSubmissionResponse)
-	if err != nil {
-		return nil, err
-	}
-	return &generated.SubmitBlockResponse{BlockSubmissionResponse: &msg}, nil
+func (s *RPCServer) AckEvents(_ context.Context, request *generated.AckEventsRequest) (*generated.AckEventsResponse, error) {
+	return &generated.AckEventsResponse{}, s.enclave.AckEvents(request.Sequence)
 }
 
 func (s *RPCServer) SubmitTx(_ context.Context, request *generated.SubmitTxRequest) (*generated.SubmitTxResponse, error) {
@@ -126,11 +191,6 @@ func (s *RPCServer) SubmitTx(_ context.Context, request *generated.SubmitTxReque
 	return &generated.SubmitTxResponse{EncryptedHash: encryptedHash}, err
 }
 
-func (s *RPCServer) SubmitBatch(_ context.Context, request *generated.SubmitBatchRequest) (*generated.SubmitBatchResponse, error) {
-	batch := rpc.FromExtBatchMsg(request.Batch)
-	return &generated.SubmitBatchResponse{}, s.enclave.SubmitBatch(batch)
-}
-
 func (s *RPCServer) ExecuteOffChainTransaction(_ context.Context, request *generated.OffChainRequest) (*generated.OffChainResponse, error) {
 	result, err := s.enclave.ExecuteOffChainTransaction(request.EncryptedParams)
 	if err != nil {
@@ -155,6 +215,7 @@ func (s *RPCServer) GetTransactionCount(_ context.Context, request *generated.Ge
 
 func (s *RPCServer) Stop(context.Context, *generated.StopRequest) (*generated.StopResponse, error) {
 	defer s.grpcServer.GracefulStop()
+	s.stopOnce.Do(func() { close(s.stopped) })
 	err := s.enclave.Stop()
 	return &generated.StopResponse{}, err
 }
@@ -202,6 +263,26 @@ func (s *RPCServer) GetCode(_ context.Context, request *generated.GetCodeRequest
 	return &generated.GetCodeResponse{Code: code}, nil
 }
 
+func (s *RPCServer) GetPublicStorageAt(_ context.Context, request *generated.GetPublicStorageAtRequest) (*generated.GetPublicStorageAtResponse, error) {
+	address := gethcommon.BytesToAddress(request.Address)
+	slot := gethcommon.BytesToHash(request.Slot)
+	batchHash := gethcommon.BytesToHash(request.BatchHash)
+
+	value, err := s.enclave.GetPublicStorageAt(address, slot, &batchHash)
+	if err != nil {
+		return nil, err
+	}
+	return &generated.GetPublicStorageAtResponse{Value: value.Bytes()}, nil
+}
+
+func (s *RPCServer) GetStorageAt(_ context.Context, req *generated.GetStorageAtRequest) (*generated.GetStorageAtResponse, error) {
+	encryptedValue, err := s.enclave.GetStorageAt(req.EncryptedParams)
+	if err != nil {
+		return nil, err
+	}
+	return &generated.GetStorageAtResponse{EncryptedResponse: encryptedValue}, nil
+}
+
 func (s *RPCServer) Subscribe(_ context.Context, req *generated.SubscribeRequest) (*generated.SubscribeResponse, error) {
 	err := s.enclave.Subscribe(gethrpc.ID(req.Id), req.EncryptedSubscription)
 	return &generated.SubscribeResponse{}, err
@@ -234,6 +315,85 @@ func (s *RPCServer) GetLogs(_ context.Context, req *generated.GetLogsRequest) (*
 	return &generated.GetLogsResponse{EncryptedResponse: encryptedLogs}, nil
 }
 
+func (s *RPCServer) DebugTraceTransaction(_ context.Context, req *generated.DebugTraceTransactionRequest) (*generated.DebugTraceResponse, error) {
+	encryptedTrace, err := s.enclave.DebugTraceTransaction(req.EncryptedParams)
+	if err != nil {
+		return nil, err
+	}
+	return &generated.DebugTraceResponse{EncryptedResponse: encryptedTrace}, nil
+}
+
+func (s *RPCServer) DebugTraceCall(_ context.Context, req *generated.DebugTraceCallRequest) (*generated.DebugTraceResponse, error) {
+	encryptedTrace, err := s.enclave.DebugTraceCall(req.EncryptedParams)
+	if err != nil {
+		return nil, err
+	}
+	return &generated.DebugTraceResponse{EncryptedResponse: encryptedTrace}, nil
+}
+
+func (s *RPCServer) GetWithdrawalProofs(_ context.Context, req *generated.GetWithdrawalProofsRequest) (*generated.GetWithdrawalProofsResponse, error) {
+	encryptedProofs, err := s.enclave.GetWithdrawalProofs(req.EncryptedParams)
+	if err != nil {
+		return nil, err
+	}
+	return &generated.GetWithdrawalProofsResponse{EncryptedResponse: encryptedProofs}, nil
+}
+
+func (s *RPCServer) GetInboundMessageRecords(_ context.Context, req *generated.GetInboundMessageRecordsRequest) (*generated.GetInboundMessageRecordsResponse, error) {
+	encryptedRecords, err := s.enclave.GetInboundMessageRecords(req.EncryptedParams)
+	if err != nil {
+		return nil, err
+	}
+	return &generated.GetInboundMessageRecordsResponse{EncryptedResponse: encryptedRecords}, nil
+}
+
+func (s *RPCServer) GetInboundMessageDelivery(_ context.Context, request *generated.GetInboundMessageDeliveryRequest) (*generated.GetInboundMessageDeliveryResponse, error) {
+	delivery, err := s.enclave.GetInboundMessageDelivery(gethcommon.BytesToAddress(request.Sender), request.Sequence)
+	if err != nil {
+		return nil, err
+	}
+	return &generated.GetInboundMessageDeliveryResponse{Delivery: rpc.ToInboundMessageDeliveryMsg(delivery)}, nil
+}
+
+func (s *RPCServer) ExportSnapshot(_ context.Context, request *generated.ExportSnapshotRequest) (*generated.ExportSnapshotResponse, error) {
+	snapshot, err := s.enclave.ExportSnapshot(request.BatchNumber)
+	if err != nil {
+		return nil, err
+	}
+	return &generated.ExportSnapshotResponse{EncryptedSnapshot: snapshot}, nil
+}
+
+func (s *RPCServer) ImportSnapshot(stream generated.EnclaveProto_ImportSnapshotServer) error {
+	var snapshot []byte
+	for {
+		request, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(snapshot)+len(request.Chunk) > rpc.MaxSnapshotSize {
+			return fmt.Errorf("snapshot was larger than the maximum size of %d bytes", rpc.MaxSnapshotSize)
+		}
+		snapshot = append(snapshot, request.Chunk...)
+	}
+
+	errStr := ""
+	if err := s.enclave.ImportSnapshot(snapshot); err != nil {
+		errStr = err.Error()
+	}
+	return stream.SendAndClose(&generated.ImportSnapshotResponse{Error: errStr})
+}
+
+func (s *RPCServer) GetDivergenceReports(_ context.Context, _ *generated.EmptyArgs) (*generated.GetDivergenceReportsResponse, error) {
+	reports, err := s.enclave.GetDivergenceReports()
+	if err != nil {
+		return nil, err
+	}
+	return &generated.GetDivergenceReportsResponse{Reports: rpc.ToDivergenceReportMsgs(reports)}, nil
+}
+
 func (s *RPCServer) HealthCheck(_ context.Context, _ *generated.EmptyArgs) (*generated.HealthCheckResponse, error) {
 	healthy, err := s.enclave.HealthCheck()
 	if err != nil {
@@ -243,13 +403,29 @@ func (s *RPCServer) HealthCheck(_ context.Context, _ *generated.EmptyArgs) (*gen
 }
 
 func (s *RPCServer) CreateRollup(_ context.Context, _ *generated.CreateRollupRequest) (*generated.CreateRollupResponse, error) {
-	rollup, err := s.enclave.GenerateRollup()
+	rollups, err := s.enclave.GenerateRollups()
+	if err != nil {
+		return nil, err
+	}
 
-	msg := rpc.ToExtRollupMsg(rollup)
+	msgs := make([]*generated.ExtRollupMsg, len(rollups))
+	for idx, rollup := range rollups {
+		msg := rpc.ToExtRollupMsg(rollup)
+		msgs[idx] = &msg
+	}
+	return &generated.CreateRollupResponse{Msgs: msgs}, nil
+}
 
-	return &generated.CreateRollupResponse{
-		Msg: &msg,
-	}, err
+func (s *RPCServer) CreateBatch(_ context.Context, request *generated.CreateBatchRequest) (*generated.CreateBatchResponse, error) {
+	return &generated.CreateBatchResponse{}, s.enclave.CreateBatch(request.SkipIfEmpty)
+}
+
+func (s *RPCServer) TimeBasedBatches(_ context.Context, _ *generated.EmptyArgs) (*generated.TimeBasedBatchesResponse, error) {
+	timeBased, err := s.enclave.TimeBasedBatches()
+	if err != nil {
+		return nil, err
+	}
+	return &generated.TimeBasedBatchesResponse{TimeBased: timeBased}, nil
 }
 
 func (s *RPCServer) decodeBlock(encodedBlock []byte) types.Block {
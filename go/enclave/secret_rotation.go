@@ -0,0 +1,219 @@
+package enclave
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
+	"github.com/obscuronet/go-obscuro/go/ethadapter"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// The network secret is rotated in epochs. The secret of epoch 0 is generated by the genesis enclave. When the policy
+// key requests a rotation, optionally revoking the attestation of an aggregator, the sequencer's enclave generates the
+// secret of the next epoch and encrypts it for the enclave of each aggregator whose attestation is not revoked. Every
+// enclave switches to the keys of the new epoch when it ingests the L1 block in which the secret is published, so that
+// the batches and rollups produced after that block can only be read by the authorised enclaves.
+//
+// The rotations and revocations are only in force on the L1 chain they were published on. If their L1 block is
+// abandoned in a reorg, the enclave goes back to the latest epoch published on the new L1 chain, and a rotation to the
+// same epoch published on the new chain replaces the abandoned one.
+
+// Adds the keys derived from the secret of an epoch, so that data encrypted with them can be decrypted, and so that the
+// data and requests of the latest epoch are encrypted with them.
+func (e *enclaveImpl) addEpochKeys(epoch uint64, secret crypto.SharedEnclaveSecret) error {
+	// The keys of epoch 0 are always held.
+	if epoch == 0 {
+		return nil
+	}
+	keys, err := crypto.DeriveEpochKeys(epoch, secret)
+	if err != nil {
+		return err
+	}
+	if err = e.transactionBlobCrypto.AddEpochKey(epoch, keys.RollupKey); err != nil {
+		return err
+	}
+	e.rpcEncryptionManager.AddEnclaveKey(ecies.ImportECDSA(keys.RPCKey))
+	return nil
+}
+
+// Adds the keys of the epochs whose secrets are stored by the enclave, if any, and encrypts with the key of the latest
+// epoch published on the L1 chain ending in the head block.
+func (e *enclaveImpl) loadEpochKeys() error {
+	secrets, err := e.storage.FetchEpochSecrets()
+	if err != nil {
+		if errors.Is(err, errutil.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("could not retrieve epoch secrets. Cause: %w", err)
+	}
+	for epoch, secret := range secrets {
+		if err = e.addEpochKeys(uint64(epoch), secret); err != nil {
+			return err
+		}
+	}
+	headBlock, err := e.storage.FetchHeadBlock()
+	if err != nil {
+		if errors.Is(err, errutil.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("could not retrieve head block. Cause: %w", err)
+	}
+	return e.setLatestEpoch(headBlock.Hash())
+}
+
+// Encrypts with the key of the latest epoch published on the L1 chain ending in the given block.
+func (e *enclaveImpl) setLatestEpoch(l1Block common.L1RootHash) error {
+	latestEpoch, err := e.storage.FetchLatestEpoch(l1Block)
+	if err != nil {
+		return fmt.Errorf("could not retrieve latest epoch. Cause: %w", err)
+	}
+	return e.transactionBlobCrypto.SetLatestEpoch(latestEpoch)
+}
+
+// Stores the secrets received from another enclave, indexed by epoch, and adds their keys. The L1 blocks they were
+// published in are not known, so they are in force on every L1 chain.
+func (e *enclaveImpl) storeEpochSecrets(secrets []crypto.SharedEnclaveSecret) error {
+	for epoch, secret := range secrets {
+		if err := e.storage.StoreEpochSecret(uint64(epoch), secret, nil); err != nil {
+			return fmt.Errorf("could not store secret of epoch %d. Cause: %w", epoch, err)
+		}
+		if err := e.addEpochKeys(uint64(epoch), secret); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the secrets of the epochs up to the latest one published on the L1 chain ending in the given block.
+func (e *enclaveImpl) epochSecretsAt(l1Block common.L1RootHash) ([]crypto.SharedEnclaveSecret, error) {
+	secrets, err := e.storage.FetchEpochSecrets()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve epoch secrets. Cause: %w", err)
+	}
+	latestEpoch, err := e.storage.FetchLatestEpoch(l1Block)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve latest epoch. Cause: %w", err)
+	}
+	return secrets[:latestEpoch+1], nil
+}
+
+// Records the revocation requested by the policy key in the given L1 block, if any. If the enclave is the sequencer's,
+// it also generates the secret of the requested epoch, to be published by the host.
+func (e *enclaveImpl) processSecretRotationRequest(req *ethadapter.L1RequestSecretRotationTx, l1Block common.L1RootHash) (*common.SecretRotation, error) {
+	policySigner := e.config.AttestationAllowListSigner
+	if policySigner == (gethcommon.Address{}) {
+		return nil, errors.New("no policy signer is configured")
+	}
+	signer, err := req.Signer(e.config.L1ChainID, e.config.ManagementContractAddress)
+	if err != nil {
+		return nil, err
+	}
+	if signer != policySigner {
+		return nil, fmt.Errorf("secret rotation request was signed by %s rather than the policy signer", signer)
+	}
+	latestEpoch, err := e.storage.FetchLatestEpoch(l1Block)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve latest epoch. Cause: %w", err)
+	}
+	if req.Epoch != latestEpoch+1 {
+		return nil, fmt.Errorf("secret rotation request is for epoch %d, but the latest epoch is %d", req.Epoch, latestEpoch)
+	}
+
+	if req.RevokedID != (gethcommon.Address{}) {
+		if err = e.storage.StoreAttestationRevocation(req.RevokedID, l1Block); err != nil {
+			return nil, fmt.Errorf("could not store attestation revocation. Cause: %w", err)
+		}
+		e.logger.Info(fmt.Sprintf("Revoked attestation of aggregator %s.", req.RevokedID))
+	}
+
+	if e.config.NodeType != common.Sequencer {
+		return nil, nil //nolint:nilnil
+	}
+	return e.generateEpochSecret(req.Epoch, l1Block)
+}
+
+// Generates the secret of the given epoch, encrypted for the enclave of each aggregator whose attestation is not revoked
+// on the L1 chain ending in the given block.
+func (e *enclaveImpl) generateEpochSecret(epoch uint64, l1Block common.L1RootHash) (*common.SecretRotation, error) {
+	attestedKeys, err := e.storage.FetchAttestedKeys()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve attested keys. Cause: %w", err)
+	}
+	// The sequencer's enclave receives the secret like any other enclave, under its current key.
+	attestedKeys[e.config.HostID] = &e.enclaveKey.PublicKey
+
+	secret := crypto.GenerateEntropy(e.logger)
+	rotation := &common.SecretRotation{Epoch: epoch}
+	for aggregator, key := range attestedKeys {
+		revoked, err := e.storage.IsAttestationRevoked(aggregator, l1Block)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			continue
+		}
+		encryptedSecret, err := crypto.EncryptSecret(gethcrypto.CompressPubkey(key), secret, e.logger)
+		if err != nil {
+			return nil, fmt.Errorf("could not encrypt secret of epoch %d for aggregator %s. Cause: %w", epoch, aggregator, err)
+		}
+		rotation.Secrets = append(rotation.Secrets, &common.EpochSecret{RecipientID: aggregator, Secret: encryptedSecret})
+	}
+	if err = rotation.Sign(e.enclaveKey); err != nil {
+		return nil, err
+	}
+	e.logger.Info(fmt.Sprintf("Generated secret of epoch %d for %d aggregators.", epoch, len(rotation.Secrets)))
+	return rotation, nil
+}
+
+// Stores the secret of the next epoch published by the sequencer in the given L1 block, and switches to its keys. If the
+// enclave was not sent the secret, its attestation was revoked and it can no longer read the network's data.
+func (e *enclaveImpl) processSecretRotation(tx *ethadapter.L1SecretRotationTx, l1Block common.L1RootHash) error {
+	rotation, err := common.DecodeSecretRotation(tx.Rotation)
+	if err != nil {
+		return err
+	}
+	latestEpoch, err := e.storage.FetchLatestEpoch(l1Block)
+	if err != nil {
+		return fmt.Errorf("could not retrieve latest epoch. Cause: %w", err)
+	}
+	if rotation.Epoch != latestEpoch+1 {
+		return fmt.Errorf("secret rotation is for epoch %d, but the latest epoch is %d", rotation.Epoch, latestEpoch)
+	}
+
+	signer, err := rotation.Signer()
+	if err != nil {
+		return err
+	}
+	sequencerKey := &e.enclaveKey.PublicKey
+	if e.config.NodeType != common.Sequencer {
+		if sequencerKey, err = e.storage.FetchAttestedKey(e.config.SequencerID); err != nil {
+			return fmt.Errorf("could not retrieve sequencer's attested key. Cause: %w", err)
+		}
+	}
+	if gethcrypto.PubkeyToAddress(*signer) != gethcrypto.PubkeyToAddress(*sequencerKey) {
+		return errors.New("secret rotation was not signed by the sequencer's enclave")
+	}
+
+	encryptedSecret := rotation.SecretFor(e.config.HostID)
+	if encryptedSecret == nil {
+		return fmt.Errorf("enclave was not sent the secret of epoch %d", rotation.Epoch)
+	}
+	secret, err := crypto.DecryptSecret(encryptedSecret, e.enclaveKey)
+	if err != nil {
+		return err
+	}
+	if err = e.storage.StoreEpochSecret(rotation.Epoch, *secret, &l1Block); err != nil {
+		return fmt.Errorf("could not store secret of epoch %d. Cause: %w", rotation.Epoch, err)
+	}
+	if err = e.addEpochKeys(rotation.Epoch, *secret); err != nil {
+		return err
+	}
+	e.logger.Info(fmt.Sprintf("Rotated network secret to epoch %d.", rotation.Epoch))
+	return nil
+}
@@ -0,0 +1,218 @@
+package enclave
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
+	"github.com/obscuronet/go-obscuro/go/ethadapter"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	rotationHostID   = gethcommon.HexToAddress("0x0a")
+	retainedAggID    = gethcommon.HexToAddress("0x0b")
+	revokedAggID     = gethcommon.HexToAddress("0x0c")
+	otherMgmtAddress = gethcommon.HexToAddress("0x0d")
+)
+
+func TestSecretRotationRequestRevokesOnItsL1Fork(t *testing.T) {
+	encl, policyKey := createRotationTestEnclave(t)
+	canonicalBlock, forkBlock := storeL1Fork(t, encl)
+
+	req := &ethadapter.L1RequestSecretRotationTx{Epoch: 1, RevokedID: revokedAggID}
+	if err := req.Sign(policyKey, encl.config.L1ChainID, otherMgmtAddress); err != nil {
+		t.Fatal(err)
+	}
+	assertRotationRequestFails(t, encl, req, canonicalBlock, "rather than the policy signer")
+
+	req.Epoch = 2
+	if err := req.Sign(policyKey, encl.config.L1ChainID, encl.config.ManagementContractAddress); err != nil {
+		t.Fatal(err)
+	}
+	assertRotationRequestFails(t, encl, req, canonicalBlock, "the latest epoch is 0")
+
+	req.Epoch = 1
+	if err := req.Sign(policyKey, encl.config.L1ChainID, encl.config.ManagementContractAddress); err != nil {
+		t.Fatal(err)
+	}
+	rotation, err := encl.processSecretRotationRequest(req, canonicalBlock.Hash())
+	if err != nil {
+		t.Fatalf("could not process secret rotation request. Cause: %s", err)
+	}
+	if rotation.Epoch != 1 || rotation.SecretFor(rotationHostID) == nil || rotation.SecretFor(retainedAggID) == nil {
+		t.Fatal("expected the secret of epoch 1 to be generated for the sequencer and the retained aggregator")
+	}
+	if rotation.SecretFor(revokedAggID) != nil {
+		t.Fatal("expected the secret of epoch 1 not to be generated for the revoked aggregator")
+	}
+
+	assertRevoked(t, encl, canonicalBlock, true)
+	assertRevoked(t, encl, forkBlock, false)
+	forkRotation, err := encl.generateEpochSecret(1, forkBlock.Hash())
+	if err != nil {
+		t.Fatalf("could not generate secret. Cause: %s", err)
+	}
+	if forkRotation.SecretFor(revokedAggID) == nil {
+		t.Fatal("expected the secret to be generated for an aggregator revoked on another L1 fork")
+	}
+}
+
+func TestSecretRotationIsAppliedOnItsL1Fork(t *testing.T) {
+	encl, _ := createRotationTestEnclave(t)
+	canonicalBlock, forkBlock := storeL1Fork(t, encl)
+
+	forgerKey, err := gethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := generateRotation(t, encl, forkBlock)
+	if err = forged.Sign(forgerKey); err != nil {
+		t.Fatal(err)
+	}
+	assertRotationFails(t, encl, forged, canonicalBlock, "not signed by the sequencer's enclave")
+
+	rotation := generateRotation(t, encl, canonicalBlock)
+	if err = encl.processSecretRotation(encodeRotation(t, rotation), canonicalBlock.Hash()); err != nil {
+		t.Fatalf("could not apply secret rotation. Cause: %s", err)
+	}
+	assertLatestEpoch(t, encl, canonicalBlock, 1)
+	assertLatestEpoch(t, encl, forkBlock, 0)
+	assertRotationFails(t, encl, rotation, canonicalBlock, "the latest epoch is 1")
+
+	// The transactions are encrypted with the key of the latest epoch on the L1 chain of the block being processed.
+	if err = encl.setLatestEpoch(forkBlock.Hash()); err != nil {
+		t.Fatal(err)
+	}
+	assertEncryptionEpoch(t, encl, 0)
+	if err = encl.setLatestEpoch(canonicalBlock.Hash()); err != nil {
+		t.Fatal(err)
+	}
+	assertEncryptionEpoch(t, encl, 1)
+
+	// A rotation to the same epoch on another L1 fork replaces the abandoned one.
+	if err = encl.processSecretRotation(encodeRotation(t, generateRotation(t, encl, forkBlock)), forkBlock.Hash()); err != nil {
+		t.Fatalf("could not apply secret rotation on L1 fork. Cause: %s", err)
+	}
+	assertLatestEpoch(t, encl, forkBlock, 1)
+	assertLatestEpoch(t, encl, canonicalBlock, 0)
+
+	excluded := &common.SecretRotation{Epoch: 2}
+	if err = excluded.Sign(encl.enclaveKey); err != nil {
+		t.Fatal(err)
+	}
+	assertRotationFails(t, encl, excluded, forkBlock, "was not sent the secret of epoch 2")
+}
+
+// Returns a sequencer's enclave that recognises the returned policy key, with the attested keys of two aggregators.
+func createRotationTestEnclave(t *testing.T) (*enclaveImpl, *ecdsa.PrivateKey) {
+	t.Helper()
+	enclave, err := createTestEnclave(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encl := enclave.(*enclaveImpl)
+	policyKey, err := gethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encl.config.HostID = rotationHostID
+	encl.config.ManagementContractAddress = testMgmtContract
+	encl.config.AttestationAllowListSigner = gethcrypto.PubkeyToAddress(policyKey.PublicKey)
+	for _, aggregator := range []gethcommon.Address{retainedAggID, revokedAggID} {
+		aggregatorKey, err := gethcrypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = encl.storage.StoreAttestedKey(aggregator, &aggregatorKey.PublicKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return encl, policyKey
+}
+
+// Stores two competing children of the enclave's L1 head.
+func storeL1Fork(t *testing.T, encl *enclaveImpl) (*types.Block, *types.Block) {
+	t.Helper()
+	headBlock, err := encl.storage.FetchHeadBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	number := new(big.Int).Add(headBlock.Number(), big.NewInt(1))
+	canonicalBlock := types.NewBlockWithHeader(&types.Header{Number: number, ParentHash: headBlock.Hash()})
+	forkBlock := types.NewBlockWithHeader(&types.Header{Number: number, ParentHash: headBlock.Hash(), Time: 1})
+	encl.storage.StoreBlock(canonicalBlock)
+	encl.storage.StoreBlock(forkBlock)
+	return canonicalBlock, forkBlock
+}
+
+func generateRotation(t *testing.T, encl *enclaveImpl, block *types.Block) *common.SecretRotation {
+	t.Helper()
+	rotation, err := encl.generateEpochSecret(1, block.Hash())
+	if err != nil {
+		t.Fatalf("could not generate secret. Cause: %s", err)
+	}
+	return rotation
+}
+
+func encodeRotation(t *testing.T, rotation *common.SecretRotation) *ethadapter.L1SecretRotationTx {
+	t.Helper()
+	encoded, err := common.EncodeSecretRotation(rotation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ethadapter.L1SecretRotationTx{Epoch: rotation.Epoch, Rotation: encoded}
+}
+
+func assertRotationRequestFails(t *testing.T, encl *enclaveImpl, req *ethadapter.L1RequestSecretRotationTx, block *types.Block, expectedErr string) {
+	t.Helper()
+	_, err := encl.processSecretRotationRequest(req, block.Hash())
+	if err == nil || !strings.Contains(err.Error(), expectedErr) {
+		t.Fatalf("expected secret rotation request to fail with '%s', got %v", expectedErr, err)
+	}
+}
+
+func assertRotationFails(t *testing.T, encl *enclaveImpl, rotation *common.SecretRotation, block *types.Block, expectedErr string) {
+	t.Helper()
+	err := encl.processSecretRotation(encodeRotation(t, rotation), block.Hash())
+	if err == nil || !strings.Contains(err.Error(), expectedErr) {
+		t.Fatalf("expected secret rotation to fail with '%s', got %v", expectedErr, err)
+	}
+}
+
+func assertRevoked(t *testing.T, encl *enclaveImpl, block *types.Block, expected bool) {
+	t.Helper()
+	revoked, err := encl.storage.IsAttestationRevoked(revokedAggID, block.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked != expected {
+		t.Fatalf("expected attestation revoked at block %s to be %t", block.Hash(), expected)
+	}
+}
+
+func assertLatestEpoch(t *testing.T, encl *enclaveImpl, block *types.Block, expected uint64) {
+	t.Helper()
+	latestEpoch, err := encl.storage.FetchLatestEpoch(block.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latestEpoch != expected {
+		t.Fatalf("expected latest epoch at block %s to be %d, got %d", block.Hash(), expected, latestEpoch)
+	}
+}
+
+func assertEncryptionEpoch(t *testing.T, encl *enclaveImpl, expected uint64) {
+	t.Helper()
+	encrypted := encl.transactionBlobCrypto.Encrypt(nil)
+	if epoch := binary.BigEndian.Uint64(encrypted[:crypto.EpochLength]); epoch != expected {
+		t.Fatalf("expected transactions to be encrypted with the key of epoch %d, got %d", expected, epoch)
+	}
+}
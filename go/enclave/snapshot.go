@@ -0,0 +1,187 @@
+package enclave
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/compression"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
+	"github.com/obscuronet/go-obscuro/go/enclave/db"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// snapshotEnvelope is the plaintext of an exported snapshot.
+type snapshotEnvelope struct {
+	Snapshot    []byte                          // The compressed, RLP-encoded snapshot.
+	Exporter    gethcommon.Address              // The ID of the node whose enclave exported the snapshot.
+	Attestation common.EncodedAttestationReport // The exporting enclave's attestation report, binding its key to its ID.
+	Signature   []byte                          // The exporting enclave's signature over the hash of the snapshot.
+}
+
+// ExportSnapshot - the snapshot is taken at the latest L1 block after which the batch was the head batch, so that the
+// importing enclave can resume by ingesting the children of that block.
+func (e *enclaveImpl) ExportSnapshot(batchNumber uint64) (common.EncryptedSnapshot, error) {
+	l1Head, err := e.l1BlockForHeadBatch(batchNumber)
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := e.epochSecretsAt(l1Head)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := e.storage.ExportSnapshot(l1Head)
+	if err != nil {
+		return nil, fmt.Errorf("could not export snapshot. Cause: %w", err)
+	}
+	encodedSnapshot, err := rlp.EncodeToBytes(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode snapshot. Cause: %w", err)
+	}
+	attestation, err := e.Attestation()
+	if err != nil {
+		return nil, fmt.Errorf("could not produce attestation report. Cause: %w", err)
+	}
+	encodedAttestation, err := common.EncodeAttestation(attestation)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode attestation report. Cause: %w", err)
+	}
+	envelope := snapshotEnvelope{Snapshot: compression.Compress(encodedSnapshot), Exporter: e.config.HostID, Attestation: encodedAttestation}
+	if envelope.Signature, err = gethcrypto.Sign(gethcrypto.Keccak256(envelope.Snapshot), e.enclaveKey); err != nil {
+		return nil, fmt.Errorf("could not sign snapshot. Cause: %w", err)
+	}
+	encodedEnvelope, err := rlp.EncodeToBytes(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode snapshot envelope. Cause: %w", err)
+	}
+
+	e.logger.Info(fmt.Sprintf("Exported snapshot at batch %d with %d state nodes.", batchNumber, len(snapshot.StateNodes)),
+		"l1Head", l1Head)
+	latestEpoch := len(secrets) - 1
+	return crypto.EncryptSnapshot(uint64(latestEpoch), secrets[latestEpoch], encodedEnvelope)
+}
+
+// ImportSnapshot - the snapshot must have been exported by the sequencer and signed by its attested key, and the storage
+// checks the state of its batches against their state roots before anything is written.
+func (e *enclaveImpl) ImportSnapshot(encryptedSnapshot common.EncryptedSnapshot) error {
+	if e.l1Blockchain != nil {
+		return errors.New("cannot import a snapshot while validating L1 blocks, as the L1 blocks preceding it are missing")
+	}
+	secrets, err := e.storage.FetchEpochSecrets()
+	if err != nil {
+		return fmt.Errorf("could not retrieve secrets. Cause: %w", err)
+	}
+
+	encodedEnvelope, err := crypto.DecryptSnapshot(secrets, encryptedSnapshot)
+	if err != nil {
+		return err
+	}
+	var envelope snapshotEnvelope
+	if err = rlp.DecodeBytes(encodedEnvelope, &envelope); err != nil {
+		return fmt.Errorf("could not decode snapshot envelope. Cause: %w", err)
+	}
+	encodedSnapshot, err := compression.Decompress(envelope.Snapshot, 0)
+	if err != nil {
+		return fmt.Errorf("could not decompress snapshot. Cause: %w", err)
+	}
+	var snapshot db.Snapshot
+	if err = rlp.DecodeBytes(encodedSnapshot, &snapshot); err != nil {
+		return fmt.Errorf("could not decode snapshot. Cause: %w", err)
+	}
+
+	// Anyone holding the shared secret could produce the envelope, so we only trust the sequencer's snapshots, signed by
+	// a key we know to be attested for it.
+	if envelope.Exporter != e.config.SequencerID {
+		return fmt.Errorf("snapshot was exported by node %s, but only snapshots exported by the sequencer %s are accepted",
+			envelope.Exporter, e.config.SequencerID)
+	}
+	attestedKey, err := e.exporterKey(envelope)
+	if err != nil {
+		return err
+	}
+	signer, err := gethcrypto.SigToPub(gethcrypto.Keccak256(envelope.Snapshot), envelope.Signature)
+	if err != nil {
+		return fmt.Errorf("could not recover snapshot signer. Cause: %w", err)
+	}
+	if gethcrypto.PubkeyToAddress(*signer) != gethcrypto.PubkeyToAddress(*attestedKey) {
+		return fmt.Errorf("snapshot was not signed by the attested key of exporting node %s", envelope.Exporter)
+	}
+
+	if err = e.storage.ImportSnapshot(&snapshot); err != nil {
+		return fmt.Errorf("could not import snapshot. Cause: %w", err)
+	}
+	loadAttestationAllowListAtHead(e.storage, e.attestationPolicy, e.logger)
+	e.logger.Info(fmt.Sprintf("Imported snapshot exported by node %s.", envelope.Exporter),
+		"l1Head", snapshot.L1Head, "headBatch", snapshot.HeadBatch)
+	return nil
+}
+
+// Returns the key the exporting node's enclave signs with. We use the key attested on the L1 if we have already stored
+// it, and otherwise the key in the exporting enclave's attestation report, which must satisfy our attestation policy.
+func (e *enclaveImpl) exporterKey(envelope snapshotEnvelope) (*ecdsa.PublicKey, error) {
+	attestedKey, err := e.storage.FetchAttestedKey(envelope.Exporter)
+	if err == nil {
+		return attestedKey, nil
+	}
+	if !errors.Is(err, errutil.ErrNotFound) {
+		return nil, fmt.Errorf("could not retrieve attested key of exporting node. Cause: %w", err)
+	}
+
+	attestation, err := common.DecodeAttestation(envelope.Attestation)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode attestation report of exporting node. Cause: %w", err)
+	}
+	if attestation.Owner != envelope.Exporter {
+		return nil, fmt.Errorf("attestation report was produced for node %s, not exporting node %s", attestation.Owner, envelope.Exporter)
+	}
+	data, err := e.attestationProvider.VerifyReport(attestation)
+	if err != nil {
+		return nil, fmt.Errorf("could not verify attestation report of exporting node. Cause: %w", err)
+	}
+	if err = attestation.VerifyIdentity(data); err != nil {
+		return nil, fmt.Errorf("could not verify identity of exporting node. Cause: %w", err)
+	}
+	// An enclave without an L1 head has not ingested any revocation.
+	var l1Head common.L1RootHash
+	if headBlock, err := e.storage.FetchHeadBlock(); err == nil {
+		l1Head = headBlock.Hash()
+	}
+	revoked, err := e.storage.IsAttestationRevoked(envelope.Exporter, l1Head)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, fmt.Errorf("attestation of exporting node %s was revoked", envelope.Exporter)
+	}
+	return gethcrypto.DecompressPubkey(attestation.PubKey)
+}
+
+// Returns the hash of the latest L1 block after which the canonical batch with the given number was the head batch.
+func (e *enclaveImpl) l1BlockForHeadBatch(batchNumber uint64) (common.L1RootHash, error) {
+	batch, err := e.storage.FetchBatchByHeight(batchNumber)
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("could not retrieve batch %d. Cause: %w", batchNumber, err)
+	}
+	block, err := e.storage.FetchHeadBlock()
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("could not retrieve head block. Cause: %w", err)
+	}
+	for {
+		headBatch, err := e.storage.FetchHeadBatchForBlock(block.Hash())
+		if err != nil || headBatch.NumberU64() < batchNumber {
+			return gethcommon.Hash{}, fmt.Errorf("batch %d was not the head batch after any L1 block, so no snapshot can be taken at it", batchNumber)
+		}
+		if *headBatch.Hash() == *batch.Hash() {
+			return block.Hash(), nil
+		}
+		if block, err = e.storage.FetchBlock(block.ParentHash()); err != nil {
+			return gethcommon.Hash{}, fmt.Errorf("could not retrieve parent block. Cause: %w", err)
+		}
+	}
+}
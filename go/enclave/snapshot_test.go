@@ -0,0 +1,76 @@
+package enclave
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSnapshotsAreOnlyTrustedFromTheSequencer(t *testing.T) {
+	enclave, err := createTestEnclave(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encl := enclave.(*enclaveImpl)
+	snapshot, err := encl.ExportSnapshot(0)
+	if err != nil {
+		t.Fatalf("could not export snapshot. Cause: %s", err)
+	}
+
+	// The snapshot is trusted, so it only fails to import because the enclave already has a head batch.
+	assertImportFails(t, encl, snapshot, "already has a head batch")
+
+	forgerKey, err := gethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("could not generate key. Cause: %s", err)
+	}
+	forged := resealSnapshot(t, encl, snapshot, func(envelope *snapshotEnvelope) {
+		envelope.Signature, err = gethcrypto.Sign(gethcrypto.Keccak256(envelope.Snapshot), forgerKey)
+		if err != nil {
+			t.Fatalf("could not sign snapshot. Cause: %s", err)
+		}
+	})
+	assertImportFails(t, encl, forged, "was not signed by the attested key")
+
+	encl.config.SequencerID = gethcommon.HexToAddress("0x01")
+	assertImportFails(t, encl, snapshot, "only snapshots exported by the sequencer")
+}
+
+func assertImportFails(t *testing.T, encl *enclaveImpl, snapshot []byte, expectedErr string) {
+	t.Helper()
+	err := encl.ImportSnapshot(snapshot)
+	if err == nil || !strings.Contains(err.Error(), expectedErr) {
+		t.Fatalf("expected snapshot import to fail with '%s', got %v", expectedErr, err)
+	}
+}
+
+// Decrypts the snapshot, alters its envelope and encrypts it again with the shared secret.
+func resealSnapshot(t *testing.T, encl *enclaveImpl, snapshot []byte, alter func(*snapshotEnvelope)) []byte {
+	t.Helper()
+	secrets, err := encl.storage.FetchEpochSecrets()
+	if err != nil {
+		t.Fatalf("could not retrieve secrets. Cause: %s", err)
+	}
+	encodedEnvelope, err := crypto.DecryptSnapshot(secrets, snapshot)
+	if err != nil {
+		t.Fatalf("could not decrypt snapshot. Cause: %s", err)
+	}
+	var envelope snapshotEnvelope
+	if err = rlp.DecodeBytes(encodedEnvelope, &envelope); err != nil {
+		t.Fatalf("could not decode snapshot envelope. Cause: %s", err)
+	}
+	alter(&envelope)
+	if encodedEnvelope, err = rlp.EncodeToBytes(envelope); err != nil {
+		t.Fatalf("could not encode snapshot envelope. Cause: %s", err)
+	}
+	resealed, err := crypto.EncryptSnapshot(0, secrets[0], encodedEnvelope)
+	if err != nil {
+		t.Fatalf("could not encrypt snapshot. Cause: %s", err)
+	}
+	return resealed
+}
@@ -9,6 +9,7 @@ import (
 
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 // L1Transaction is an abstraction that transforms an Ethereum transaction into a format that can be consumed more easily by Obscuro.
@@ -60,6 +61,81 @@ type L1RequestSecretTx struct {
 	Attestation common.EncodedAttestationReport
 }
 
+// L1SetAttestationAllowListTx publishes a new signed allow-list of the enclaves recognised by the network.
+type L1SetAttestationAllowListTx struct {
+	AllowList []byte // the encoded common.AttestationAllowList
+}
+
+// L1RequestSecretRotationTx requests that the sequencer's enclave generates the network secret of the next epoch,
+// optionally revoking the attestation of an aggregator so that it is not sent the new secret.
+type L1RequestSecretRotationTx struct {
+	Epoch     uint64
+	RevokedID gethcommon.Address // the zero address if no attestation is revoked
+	Signature []byte             // signed with the network's policy key
+}
+
+// The domain of the secret rotation request signatures, which distinguishes them from the other messages signed with the
+// policy key.
+const secretRotationRequestDomain = "obscuro-secret-rotation-request"
+
+// The contents of a secret rotation request that are signed, along with the network the request is published on.
+type signedSecretRotationRequest struct {
+	Domain             string
+	L1ChainID          uint64
+	ManagementContract gethcommon.Address
+	Epoch              uint64
+	RevokedID          gethcommon.Address
+}
+
+// Hash returns the hash of the request, which is signed with the policy key. The hash commits to the L1 chain and the
+// management contract the request is published in, so that it cannot be replayed on another network.
+func (l *L1RequestSecretRotationTx) Hash(l1ChainID int64, mgmtContract gethcommon.Address) (gethcommon.Hash, error) {
+	encoded, err := rlp.EncodeToBytes(signedSecretRotationRequest{
+		Domain:             secretRotationRequestDomain,
+		L1ChainID:          uint64(l1ChainID),
+		ManagementContract: mgmtContract,
+		Epoch:              l.Epoch,
+		RevokedID:          l.RevokedID,
+	})
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("could not encode secret rotation request. Cause: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// Sign signs the request with the policy key, for publication in the given management contract.
+func (l *L1RequestSecretRotationTx) Sign(policyKey *ecdsa.PrivateKey, l1ChainID int64, mgmtContract gethcommon.Address) error {
+	hash, err := l.Hash(l1ChainID, mgmtContract)
+	if err != nil {
+		return err
+	}
+	signature, err := crypto.Sign(hash.Bytes(), policyKey)
+	if err != nil {
+		return fmt.Errorf("could not sign secret rotation request. Cause: %w", err)
+	}
+	l.Signature = signature
+	return nil
+}
+
+// Signer returns the address of the key that signed the request for publication in the given management contract.
+func (l *L1RequestSecretRotationTx) Signer(l1ChainID int64, mgmtContract gethcommon.Address) (gethcommon.Address, error) {
+	hash, err := l.Hash(l1ChainID, mgmtContract)
+	if err != nil {
+		return gethcommon.Address{}, err
+	}
+	publicKey, err := crypto.SigToPub(hash.Bytes(), l.Signature)
+	if err != nil {
+		return gethcommon.Address{}, fmt.Errorf("could not recover signer of secret rotation request. Cause: %w", err)
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+// L1SecretRotationTx publishes the network secret of a new epoch.
+type L1SecretRotationTx struct {
+	Epoch    uint64
+	Rotation []byte // the encoded common.SecretRotation
+}
+
 type L1InitializeSecretTx struct {
 	AggregatorID  *gethcommon.Address
 	InitialSecret []byte
@@ -8,6 +8,10 @@ const (
 	RequestSecretMethod    = "RequestNetworkSecret"
 	InitializeSecretMethod = "InitializeNetworkSecret" //#nosec
 	GetHostAddressesMethod = "GetHostAddresses"
+
+	SetAttestationAllowListMethod = "SetAttestationAllowList"
+	RequestSecretRotationMethod   = "RequestSecretRotation"
+	PublishSecretRotationMethod   = "PublishSecretRotation"
 )
 
 var MgmtContractABI = ManagementContract.ManagementContractMetaData.ABI
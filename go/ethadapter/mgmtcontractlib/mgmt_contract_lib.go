@@ -1,11 +1,9 @@
 package mgmtcontractlib
 
 import (
-	"bytes"
-	"compress/gzip"
 	"encoding/base64"
 	"fmt"
-	"io"
+	"math/big"
 	"strings"
 
 	"github.com/ethereum/go-ethereum"
@@ -14,6 +12,7 @@ import (
 	"github.com/obscuronet/go-obscuro/contracts/generated/ManagementContract"
 	"github.com/obscuronet/go-obscuro/contracts/generated/MessageBus"
 	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/compression"
 	"github.com/obscuronet/go-obscuro/go/common/log"
 	"github.com/obscuronet/go-obscuro/go/ethadapter"
 
@@ -30,6 +29,9 @@ type MgmtContractLib interface {
 	CreateRequestSecret(tx *ethadapter.L1RequestSecretTx, nonce uint64) types.TxData
 	CreateRespondSecret(tx *ethadapter.L1RespondSecretTx, nonce uint64, verifyAttester bool) types.TxData
 	CreateInitializeSecret(tx *ethadapter.L1InitializeSecretTx, nonce uint64) types.TxData
+	CreateSetAttestationAllowList(tx *ethadapter.L1SetAttestationAllowListTx, nonce uint64) types.TxData
+	CreateRequestSecretRotation(tx *ethadapter.L1RequestSecretRotationTx, nonce uint64) types.TxData
+	CreatePublishSecretRotation(tx *ethadapter.L1SecretRotationTx, nonce uint64) types.TxData
 	GetHostAddresses() (ethereum.CallMsg, error)
 
 	// DecodeTx receives a *types.Transaction and converts it to an common.L1Transaction
@@ -82,7 +84,7 @@ func (c *contractLibImpl) DecodeTx(tx *types.Transaction) ethadapter.L1Transacti
 			panic("call data not found for rollupData")
 		}
 		zipped := Base64DecodeFromString(callData.(string))
-		rollup, err := Decompress(zipped)
+		rollup, err := compression.Decompress(zipped, common.MaxEncodedRollupSize)
 		if err != nil {
 			panic(err)
 		}
@@ -99,6 +101,37 @@ func (c *contractLibImpl) DecodeTx(tx *types.Transaction) ethadapter.L1Transacti
 
 	case InitializeSecretMethod:
 		return c.unpackInitSecretTx(tx, method, contractCallData)
+
+	case SetAttestationAllowListMethod:
+		if err := method.Inputs.UnpackIntoMap(contractCallData, tx.Data()[methodBytesLen:]); err != nil {
+			panic(err)
+		}
+		callData, found := contractCallData["allowList"]
+		if !found {
+			panic("call data not found for allowList")
+		}
+		return &ethadapter.L1SetAttestationAllowListTx{
+			AllowList: callData.([]byte),
+		}
+
+	case RequestSecretRotationMethod:
+		if err := method.Inputs.UnpackIntoMap(contractCallData, tx.Data()[methodBytesLen:]); err != nil {
+			panic(err)
+		}
+		return &ethadapter.L1RequestSecretRotationTx{
+			Epoch:     contractCallData["epoch"].(*big.Int).Uint64(),
+			RevokedID: contractCallData["revokedID"].(gethcommon.Address),
+			Signature: contractCallData["signature"].([]byte),
+		}
+
+	case PublishSecretRotationMethod:
+		if err := method.Inputs.UnpackIntoMap(contractCallData, tx.Data()[methodBytesLen:]); err != nil {
+			panic(err)
+		}
+		return &ethadapter.L1SecretRotationTx{
+			Epoch:    contractCallData["epoch"].(*big.Int).Uint64(),
+			Rotation: contractCallData["rotation"].([]byte),
+		}
 	}
 
 	return nil
@@ -110,11 +143,7 @@ func (c *contractLibImpl) CreateRollup(t *ethadapter.L1RollupTx, nonce uint64) t
 		panic(err)
 	}
 
-	zipped, err := compress(t.Rollup)
-	if err != nil {
-		panic(err)
-	}
-	encRollupData := base64EncodeToString(zipped)
+	encRollupData := base64EncodeToString(compression.Compress(t.Rollup))
 
 	metaRollup := ManagementContract.StructsMetaRollup{
 		ParentHash:   decodedRollup.Header.ParentHash,
@@ -198,6 +227,42 @@ func (c *contractLibImpl) CreateInitializeSecret(tx *ethadapter.L1InitializeSecr
 	}
 }
 
+func (c *contractLibImpl) CreateSetAttestationAllowList(tx *ethadapter.L1SetAttestationAllowListTx, nonce uint64) types.TxData {
+	data, err := c.contractABI.Pack(SetAttestationAllowListMethod, tx.AllowList)
+	if err != nil {
+		panic(err)
+	}
+	return &types.LegacyTx{
+		Nonce: nonce,
+		To:    c.addr,
+		Data:  data,
+	}
+}
+
+func (c *contractLibImpl) CreateRequestSecretRotation(tx *ethadapter.L1RequestSecretRotationTx, nonce uint64) types.TxData {
+	data, err := c.contractABI.Pack(RequestSecretRotationMethod, new(big.Int).SetUint64(tx.Epoch), tx.RevokedID, tx.Signature)
+	if err != nil {
+		panic(err)
+	}
+	return &types.LegacyTx{
+		Nonce: nonce,
+		To:    c.addr,
+		Data:  data,
+	}
+}
+
+func (c *contractLibImpl) CreatePublishSecretRotation(tx *ethadapter.L1SecretRotationTx, nonce uint64) types.TxData {
+	data, err := c.contractABI.Pack(PublishSecretRotationMethod, new(big.Int).SetUint64(tx.Epoch), tx.Rotation)
+	if err != nil {
+		panic(err)
+	}
+	return &types.LegacyTx{
+		Nonce: nonce,
+		To:    c.addr,
+		Data:  data,
+	}
+}
+
 func (c *contractLibImpl) GetHostAddresses() (ethereum.CallMsg, error) {
 	data, err := c.contractABI.Pack(GetHostAddressesMethod)
 	if err != nil {
@@ -320,19 +385,6 @@ func base64EncodeToString(bytes []byte) string {
 	return base64.StdEncoding.EncodeToString(bytes)
 }
 
-// compress the byte array using gzip
-func compress(in []byte) ([]byte, error) {
-	var b bytes.Buffer
-	gz := gzip.NewWriter(&b)
-	if _, err := gz.Write(in); err != nil {
-		return nil, err
-	}
-	if err := gz.Close(); err != nil {
-		return nil, err
-	}
-	return b.Bytes(), nil
-}
-
 // Base64DecodeFromString decodes a string to a byte array
 func Base64DecodeFromString(in string) []byte {
 	bytesStr, err := base64.StdEncoding.DecodeString(in)
@@ -342,18 +394,6 @@ func Base64DecodeFromString(in string) []byte {
 	return bytesStr
 }
 
-// Decompress the byte array using gzip
-func Decompress(in []byte) ([]byte, error) {
-	reader := bytes.NewReader(in)
-	gz, err := gzip.NewReader(reader)
-	if err != nil {
-		return nil, err
-	}
-	defer gz.Close()
-
-	return io.ReadAll(gz)
-}
-
 func convertCrossChainMessages(messages []MessageBus.StructsCrossChainMessage) []ManagementContract.StructsCrossChainMessage {
 	msgs := make([]ManagementContract.StructsCrossChainMessage, 0)
 
@@ -8,132 +8,125 @@ import (
 
 	"github.com/obscuronet/go-obscuro/go/common"
 
-	"github.com/naoina/toml"
-
 	"github.com/obscuronet/go-obscuro/go/config"
 
 	gethcommon "github.com/ethereum/go-ethereum/common"
 )
 
-// HostConfigToml is the structure that a host's .toml config is parsed into.
+// HostConfigToml is the structure that a host's config is loaded into, from its .toml config file, environment variables
+// and flags. See config.Loader for the meaning of the struct tags.
 type HostConfigToml struct {
-	IsGenesis                 bool
-	NodeType                  string
+	IsGenesis                 bool   `flag:"isGenesis"`
+	NodeType                  string `flag:"nodeType" validate:"required,oneof=sequencer|validator"`
 	HasClientRPCHTTP          bool
-	ClientRPCPortHTTP         uint
+	ClientRPCPortHTTP         uint `flag:"clientRPCPortHttp" validate:"port"`
 	HasClientRPCWebsockets    bool
-	ClientRPCPortWS           uint
-	ClientRPCHost             string
-	EnclaveRPCAddress         string
-	P2PBindAddress            string
-	P2PPublicAddress          string
-	L1NodeHost                string
-	L1NodeWebsocketPort       uint
-	EnclaveRPCTimeout         int
-	L1RPCTimeout              int
-	P2PConnectionTimeout      int
-	ManagementContractAddress string
-	LogLevel                  int
-	LogPath                   string
-	PrivateKeyString          string
-	L1ChainID                 int64
-	ObscuroChainID            int64
-	ProfilerEnabled           bool
-	L1StartHash               string
-	MetricsEnabled            bool
-	MetricsHTTPPort           uint
-	UseInMemoryDB             bool
-	LevelDBPath               string
+	ClientRPCPortWS           uint   `flag:"clientRPCPortWs" validate:"port"`
+	ClientRPCHost             string `flag:"clientRPCHost"`
+	EnclaveRPCAddress         string `flag:"enclaveRPCAddress" validate:"required,hostport"`
+	P2PBindAddress            string `flag:"p2pBindAddress" validate:"required,hostport"`
+	P2PPublicAddress          string `flag:"p2pPublicAddress" validate:"required,hostport"`
+	L1NodeHost                string `flag:"l1NodeHost" validate:"required"`
+	L1NodeWebsocketPort       uint   `flag:"l1NodePort" validate:"port"`
+	EnclaveRPCTimeout         int    `flag:"enclaveRPCTimeoutSecs" validate:"min=0"`
+	L1RPCTimeout              int    `flag:"l1RPCTimeoutSecs" validate:"min=0"`
+	P2PConnectionTimeout      int    `flag:"p2pConnectionTimeoutSecs" validate:"min=0"`
+	ManagementContractAddress string `flag:"managementContractAddress" validate:"address"`
+	LogLevel                  int    `flag:"logLevel" validate:"min=0,max=5" reload:"true"`
+	LogPath                   string `flag:"logPath"`
+	PrivateKeyString          string `flag:"privateKey" validate:"required,hex32" secret:"true"`
+	L1ChainID                 int64  `flag:"l1ChainID"`
+	ObscuroChainID            int64  `flag:"obscuroChainID"`
+	ProfilerEnabled           bool   `flag:"profilerEnabled"`
+	L1StartHash               string `flag:"l1Start" validate:"hex32"`
+	MetricsEnabled            bool   `flag:"metricsEnabled"`
+	MetricsHTTPPort           uint   `flag:"metricsHTTPPort" validate:"port"`
+	UseInMemoryDB             bool   `flag:"useInMemoryDB"`
+	LevelDBPath               string `flag:"levelDBPath"`
+	BatchInterval             int    `flag:"batchIntervalMs" validate:"min=0"`
+	SkipEmptyBatches          bool   `flag:"skipEmptyBatches"`
+	SnapshotPath              string `flag:"snapshotPath"`
+	AdminIPCPath              string `flag:"adminIPCPath"`
+	AttestedTLS               bool   `flag:"attestedTLS"`
+
+	EnclaveWillAttest             bool     `flag:"enclaveWillAttest"`
+	AttestationUniqueIDs          []string `flag:"attestationUniqueIDs" validate:"hex32"`
+	AttestationSignerIDs          []string `flag:"attestationSignerIDs" validate:"hex32"`
+	AttestationMinSecurityVersion uint64   `flag:"attestationMinSVN"`
+	AttestationProductID          uint16   `flag:"attestationProductID"`
+	AttestationAllowDebug         bool     `flag:"attestationAllowDebug"`
+	AttestationAllowedTCBStatuses []string `flag:"attestationTCBStatuses"`
 }
 
-// ParseConfig returns a config.HostInputConfig based on either the file identified by the `config` flag, or the flags with
-// specific defaults (if the `config` flag isn't specified).
-func ParseConfig() (*config.HostInputConfig, error) {
-	cfg := config.DefaultHostParsedConfig()
-	flagUsageMap := getFlagUsageMap()
-
-	configPath := flag.String(configName, "", flagUsageMap[configName])
-	isGenesis := flag.Bool(isGenesisName, cfg.IsGenesis, flagUsageMap[isGenesisName])
-	nodeTypeStr := flag.String(nodeTypeName, cfg.NodeType.String(), flagUsageMap[nodeTypeName])
-	clientRPCPortHTTP := flag.Uint64(clientRPCPortHTTPName, cfg.ClientRPCPortHTTP, flagUsageMap[clientRPCPortHTTPName])
-	clientRPCPortWS := flag.Uint64(clientRPCPortWSName, cfg.ClientRPCPortWS, flagUsageMap[clientRPCPortWSName])
-	clientRPCHost := flag.String(clientRPCHostName, cfg.ClientRPCHost, flagUsageMap[clientRPCHostName])
-	enclaveRPCAddress := flag.String(enclaveRPCAddressName, cfg.EnclaveRPCAddress, flagUsageMap[enclaveRPCAddressName])
-	p2pBindAddress := flag.String(p2pBindAddressName, cfg.P2PBindAddress, flagUsageMap[p2pBindAddressName])
-	p2pPublicAddress := flag.String(p2pPublicAddressName, cfg.P2PPublicAddress, flagUsageMap[p2pPublicAddressName])
-	l1NodeHost := flag.String(l1NodeHostName, cfg.L1NodeHost, flagUsageMap[l1NodeHostName])
-	l1NodePort := flag.Uint64(l1NodePortName, uint64(cfg.L1NodeWebsocketPort), flagUsageMap[l1NodePortName])
-	enclaveRPCTimeoutSecs := flag.Uint64(enclaveRPCTimeoutSecsName, uint64(cfg.EnclaveRPCTimeout.Seconds()), flagUsageMap[enclaveRPCTimeoutSecsName])
-	l1RPCTimeoutSecs := flag.Uint64(l1RPCTimeoutSecsName, uint64(cfg.L1RPCTimeout.Seconds()), flagUsageMap[l1RPCTimeoutSecsName])
-	p2pConnectionTimeoutSecs := flag.Uint64(p2pConnectionTimeoutSecsName, uint64(cfg.P2PConnectionTimeout.Seconds()), flagUsageMap[p2pConnectionTimeoutSecsName])
-	managementContractAddress := flag.String(managementContractAddrName, cfg.ManagementContractAddress.Hex(), flagUsageMap[managementContractAddrName])
-	logLevel := flag.Int(logLevelName, cfg.LogLevel, flagUsageMap[logLevelName])
-	logPath := flag.String(logPathName, cfg.LogPath, flagUsageMap[logPathName])
-	l1ChainID := flag.Int64(l1ChainIDName, cfg.L1ChainID, flagUsageMap[l1ChainIDName])
-	obscuroChainID := flag.Int64(obscuroChainIDName, cfg.ObscuroChainID, flagUsageMap[obscuroChainIDName])
-	privateKeyStr := flag.String(privateKeyName, cfg.PrivateKeyString, flagUsageMap[privateKeyName])
-	profilerEnabled := flag.Bool(profilerEnabledName, cfg.ProfilerEnabled, flagUsageMap[profilerEnabledName])
-	l1StartHash := flag.String(l1StartHashName, cfg.L1StartHash.Hex(), flagUsageMap[l1StartHashName])
-	metricsEnabled := flag.Bool(metricsEnabledName, cfg.MetricsEnabled, flagUsageMap[metricsEnabledName])
-	metricsHTPPPort := flag.Uint(metricsHTTPPortName, cfg.MetricsHTTPPort, flagUsageMap[metricsHTTPPortName])
-	useInMemoryDB := flag.Bool(useInMemoryDBName, cfg.UseInMemoryDB, flagUsageMap[useInMemoryDBName])
-	levelDBPath := flag.String(levelDBPathName, cfg.LevelDBPath, flagUsageMap[levelDBPathName])
-
-	flag.Parse()
-
-	if *configPath != "" {
-		return fileBasedConfig(*configPath)
+// ParseConfig loads a config.HostInputConfig from, in increasing order of precedence, the defaults, the file
+// identified by the `config` flag, the `OBSCURO_HOST_*` environment variables and the flags, then validates it. The
+// returned loader prints the effective config and reloads the config.
+func ParseConfig() (*config.HostInputConfig, *config.Loader, error) {
+	tomlConfig := hostConfigTomlFrom(config.DefaultHostParsedConfig())
+	loader := config.NewLoader(&tomlConfig, flag.CommandLine, envPrefix, getFlagUsageMap())
+	if err := loader.Load(os.Args[1:]); err != nil {
+		return nil, nil, err
 	}
 
-	nodeType, err := common.ToNodeType(*nodeTypeStr)
+	cfg, err := toHostInputConfig(tomlConfig)
 	if err != nil {
-		return &config.HostInputConfig{}, fmt.Errorf("unrecognised node type '%s'", *nodeTypeStr)
+		return nil, nil, err
+	}
+	if err = cfg.ToHostConfig().Validate(); err != nil {
+		return nil, nil, err
 	}
 
-	cfg.IsGenesis = *isGenesis
-	cfg.NodeType = nodeType
-	cfg.HasClientRPCHTTP = true
-	cfg.ClientRPCPortHTTP = *clientRPCPortHTTP
-	cfg.HasClientRPCWebsockets = true
-	cfg.ClientRPCPortWS = *clientRPCPortWS
-	cfg.ClientRPCHost = *clientRPCHost
-	cfg.EnclaveRPCAddress = *enclaveRPCAddress
-	cfg.P2PBindAddress = *p2pBindAddress
-	cfg.P2PPublicAddress = *p2pPublicAddress
-	cfg.L1NodeHost = *l1NodeHost
-	cfg.L1NodeWebsocketPort = uint(*l1NodePort)
-	cfg.EnclaveRPCTimeout = time.Duration(*enclaveRPCTimeoutSecs) * time.Second
-	cfg.L1RPCTimeout = time.Duration(*l1RPCTimeoutSecs) * time.Second
-	cfg.P2PConnectionTimeout = time.Duration(*p2pConnectionTimeoutSecs) * time.Second
-	cfg.ManagementContractAddress = gethcommon.HexToAddress(*managementContractAddress)
-	cfg.PrivateKeyString = *privateKeyStr
-	cfg.LogLevel = *logLevel
-	cfg.LogPath = *logPath
-	cfg.L1ChainID = *l1ChainID
-	cfg.ObscuroChainID = *obscuroChainID
-	cfg.ProfilerEnabled = *profilerEnabled
-	cfg.L1StartHash = gethcommon.HexToHash(*l1StartHash)
-	cfg.MetricsEnabled = *metricsEnabled
-	cfg.MetricsHTTPPort = *metricsHTPPPort
-	cfg.UseInMemoryDB = *useInMemoryDB
-	cfg.LevelDBPath = *levelDBPath
-
-	return cfg, nil
+	return cfg, loader, nil
 }
 
-// Parses the config from the .toml file at configPath.
-func fileBasedConfig(configPath string) (*config.HostInputConfig, error) {
-	bytes, err := os.ReadFile(configPath)
-	if err != nil {
-		panic(fmt.Sprintf("could not read config file at %s. Cause: %s", configPath, err))
-	}
-
-	var tomlConfig HostConfigToml
-	err = toml.Unmarshal(bytes, &tomlConfig)
-	if err != nil {
-		panic(fmt.Sprintf("could not read config file at %s. Cause: %s", configPath, err))
+// Converts a HostInputConfig to the structure its config is loaded into.
+func hostConfigTomlFrom(cfg *config.HostInputConfig) HostConfigToml {
+	return HostConfigToml{
+		IsGenesis:                 cfg.IsGenesis,
+		NodeType:                  cfg.NodeType.String(),
+		HasClientRPCHTTP:          cfg.HasClientRPCHTTP,
+		ClientRPCPortHTTP:         uint(cfg.ClientRPCPortHTTP),
+		HasClientRPCWebsockets:    cfg.HasClientRPCWebsockets,
+		ClientRPCPortWS:           uint(cfg.ClientRPCPortWS),
+		ClientRPCHost:             cfg.ClientRPCHost,
+		EnclaveRPCAddress:         cfg.EnclaveRPCAddress,
+		P2PBindAddress:            cfg.P2PBindAddress,
+		P2PPublicAddress:          cfg.P2PPublicAddress,
+		L1NodeHost:                cfg.L1NodeHost,
+		L1NodeWebsocketPort:       cfg.L1NodeWebsocketPort,
+		EnclaveRPCTimeout:         int(cfg.EnclaveRPCTimeout.Seconds()),
+		L1RPCTimeout:              int(cfg.L1RPCTimeout.Seconds()),
+		P2PConnectionTimeout:      int(cfg.P2PConnectionTimeout.Seconds()),
+		ManagementContractAddress: cfg.ManagementContractAddress.Hex(),
+		LogLevel:                  cfg.LogLevel,
+		LogPath:                   cfg.LogPath,
+		PrivateKeyString:          cfg.PrivateKeyString,
+		L1ChainID:                 cfg.L1ChainID,
+		ObscuroChainID:            cfg.ObscuroChainID,
+		ProfilerEnabled:           cfg.ProfilerEnabled,
+		L1StartHash:               cfg.L1StartHash.Hex(),
+		MetricsEnabled:            cfg.MetricsEnabled,
+		MetricsHTTPPort:           cfg.MetricsHTTPPort,
+		UseInMemoryDB:             cfg.UseInMemoryDB,
+		LevelDBPath:               cfg.LevelDBPath,
+		BatchInterval:             int(cfg.BatchInterval.Milliseconds()),
+		SkipEmptyBatches:          cfg.SkipEmptyBatches,
+		SnapshotPath:              cfg.SnapshotPath,
+		AdminIPCPath:              cfg.AdminIPCPath,
+		AttestedTLS:               cfg.AttestedTLS,
+
+		EnclaveWillAttest:             cfg.EnclaveWillAttest,
+		AttestationUniqueIDs:          config.FromHashes(cfg.AttestationUniqueIDs),
+		AttestationSignerIDs:          config.FromHashes(cfg.AttestationSignerIDs),
+		AttestationMinSecurityVersion: cfg.AttestationMinSecurityVersion,
+		AttestationProductID:          cfg.AttestationProductID,
+		AttestationAllowDebug:         cfg.AttestationAllowDebug,
+		AttestationAllowedTCBStatuses: cfg.AttestationAllowedTCBStatuses,
 	}
+}
 
+// Converts the loaded config to a HostInputConfig.
+func toHostInputConfig(tomlConfig HostConfigToml) (*config.HostInputConfig, error) {
 	nodeType, err := common.ToNodeType(tomlConfig.NodeType)
 	if err != nil {
 		return &config.HostInputConfig{}, fmt.Errorf("unrecognised node type '%s'", tomlConfig.NodeType)
@@ -167,5 +160,18 @@ func fileBasedConfig(configPath string) (*config.HostInputConfig, error) {
 		MetricsHTTPPort:           tomlConfig.MetricsHTTPPort,
 		UseInMemoryDB:             tomlConfig.UseInMemoryDB,
 		LevelDBPath:               tomlConfig.LevelDBPath,
+		BatchInterval:             time.Duration(tomlConfig.BatchInterval) * time.Millisecond,
+		SkipEmptyBatches:          tomlConfig.SkipEmptyBatches,
+		SnapshotPath:              tomlConfig.SnapshotPath,
+		AdminIPCPath:              tomlConfig.AdminIPCPath,
+		AttestedTLS:               tomlConfig.AttestedTLS,
+
+		EnclaveWillAttest:             tomlConfig.EnclaveWillAttest,
+		AttestationUniqueIDs:          config.ToHashes(tomlConfig.AttestationUniqueIDs),
+		AttestationSignerIDs:          config.ToHashes(tomlConfig.AttestationSignerIDs),
+		AttestationMinSecurityVersion: tomlConfig.AttestationMinSecurityVersion,
+		AttestationProductID:          tomlConfig.AttestationProductID,
+		AttestationAllowDebug:         tomlConfig.AttestationAllowDebug,
+		AttestationAllowedTCBStatuses: tomlConfig.AttestationAllowedTCBStatuses,
 	}, nil
 }
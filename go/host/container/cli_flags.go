@@ -1,8 +1,13 @@
 package container
 
+import "github.com/obscuronet/go-obscuro/go/config"
+
+// The prefix of the names of the environment variables that set the config, e.g. OBSCURO_HOST_LOG_LEVEL.
+const envPrefix = "OBSCURO_HOST"
+
 // Flag names.
 const (
-	configName                   = "config"
+	configName                   = config.ConfigFlagName
 	nodeIDName                   = "id"
 	isGenesisName                = "isGenesis"
 	nodeTypeName                 = "nodeType"
@@ -29,13 +34,27 @@ const (
 	metricsHTTPPortName          = "metricsHTTPPort"
 	useInMemoryDBName            = "useInMemoryDB"
 	levelDBPathName              = "levelDBPath"
+	batchIntervalMsName          = "batchIntervalMs"
+	skipEmptyBatchesName         = "skipEmptyBatches"
+	snapshotPathName             = "snapshotPath"
+	adminIPCPathName             = "adminIPCPath"
+	attestedTLSName              = "attestedTLS"
+
+	// The attestation policy that the host's enclave must satisfy.
+	enclaveWillAttestName      = "enclaveWillAttest"
+	attestationUniqueIDsName   = "attestationUniqueIDs"
+	attestationSignerIDsName   = "attestationSignerIDs"
+	attestationMinSVNName      = "attestationMinSVN"
+	attestationProductIDName   = "attestationProductID"
+	attestationAllowDebugName  = "attestationAllowDebug"
+	attestationTCBStatusesName = "attestationTCBStatuses"
 )
 
 // Returns a map of the flag usages.
 // While we could just use constants instead of a map, this approach allows us to test that all the expected flags are defined.
 func getFlagUsageMap() map[string]string {
 	return map[string]string{
-		configName:                   "The path to the host's config file. Overrides all other flags",
+		configName:                   "The path to the host's .toml config file. Environment variables and flags override its values",
 		nodeIDName:                   "The 20 bytes of the host's address",
 		isGenesisName:                "Whether the host is the first host to join the network",
 		nodeTypeName:                 "The node's type (e.g. aggregator, validator)",
@@ -57,9 +76,23 @@ func getFlagUsageMap() map[string]string {
 		l1ChainIDName:                "An integer representing the unique chain id of the Ethereum chain used as an L1 (default 1337)",
 		obscuroChainIDName:           "An integer representing the unique chain id of the Obscuro chain (default 777)",
 		profilerEnabledName:          "Runs a profiler instance (Defaults to false)",
+		l1StartHashName:              "The hash of the L1 block to start streaming from (e.g. the management contract deployment block)",
 		metricsEnabledName:           "Whether the metrics are enabled (Defaults to true)",
 		metricsHTTPPortName:          "The port on which the metrics are served (Defaults to 0.0.0.0:14000)",
 		useInMemoryDBName:            "Whether the host will use an in-memory DB rather than persist data",
 		levelDBPathName:              "Filepath for the levelDB persistence dir (can be empty if a throwaway file in /tmp/ is acceptable or if using InMemory DB)",
+		batchIntervalMsName:          "How often the sequencer produces a batch, in milliseconds. If zero, a batch is produced for each L1 block (default 0)",
+		skipEmptyBatchesName:         "Whether the sequencer skips producing a batch on its batch interval if there are no pending transactions or deposits",
+		snapshotPathName:             "The path to a state snapshot exported by another node, for the enclave to import on startup instead of replaying the L1",
+		adminIPCPathName:             "The path of the IPC endpoint serving the operator-only admin API (e.g. snapshot export). If empty, the admin API is not served",
+		attestedTLSName:              "Whether to connect to the enclave over TLS bound to the host's private key and the enclave's attestation report. Can only be turned off if the enclave does not attest",
+
+		enclaveWillAttestName:      "Whether the enclave produces a verified attestation report, which the host checks against the attestation policy when connecting over attested TLS",
+		attestationUniqueIDsName:   "A comma-separated list of the MRENCLAVE values of the enclaves the host trusts",
+		attestationSignerIDsName:   "A comma-separated list of the MRSIGNER values of the enclaves the host trusts",
+		attestationMinSVNName:      "The minimum security version number of the enclaves the host trusts",
+		attestationProductIDName:   "The product ID of the enclaves the host trusts. Zero means any product ID",
+		attestationAllowDebugName:  "Whether the host trusts enclaves running in debug mode",
+		attestationTCBStatusesName: "A comma-separated list of the TCB statuses accepted in addition to UpToDate (e.g. SWHardeningNeeded)",
 	}
 }
@@ -1,6 +1,7 @@
 package container
 
 import (
+	"flag"
 	"os"
 	"path"
 	"reflect"
@@ -22,7 +23,12 @@ func TestConfigIsParsedFromTomlFileIfConfigFlagIsPresent(t *testing.T) {
 		panic(err)
 	}
 
-	cfg, err := fileBasedConfig(path.Join(wd, testToml))
+	tomlConfig := hostConfigTomlFrom(config.DefaultHostParsedConfig())
+	loader := config.NewLoader(&tomlConfig, flag.NewFlagSet("test", flag.ContinueOnError), envPrefix, getFlagUsageMap())
+	if err = loader.Load([]string{"--" + configName, path.Join(wd, testToml)}); err != nil {
+		t.Fatalf("could not load config. Cause: %s", err)
+	}
+	cfg, err := toHostInputConfig(tomlConfig)
 	if err != nil {
 		t.Fatalf("could not parse config. Cause: %s", err)
 	}
@@ -35,7 +41,7 @@ func TestConfigIsParsedFromCmdLineFlagsIfConfigFlagIsNotPresent(t *testing.T) {
 	p2pConnectionTimeout := 6 * time.Second
 	os.Args = append(os.Args, "--"+p2pConnectionTimeoutSecsName, strconv.FormatInt(int64(p2pConnectionTimeout.Seconds()), 10))
 
-	cfg, err := ParseConfig()
+	cfg, _, err := ParseConfig()
 	if err != nil {
 		t.Fatalf("could not parse config. Cause: %s", err)
 	}
@@ -67,6 +73,17 @@ func TestConfigFieldsMatchTomlConfigFields(t *testing.T) {
 	}
 }
 
+func TestConfigFlagsHaveUsages(t *testing.T) {
+	flagUsageMap := getFlagUsageMap()
+	cfgTomlReflection := reflect.TypeOf(HostConfigToml{})
+	for i := 0; i < cfgTomlReflection.NumField(); i++ {
+		flagName := cfgTomlReflection.Field(i).Tag.Get("flag")
+		if _, ok := flagUsageMap[flagName]; flagName != "" && !ok {
+			t.Errorf("flag %s of field %s has no usage", flagName, cfgTomlReflection.Field(i).Name)
+		}
+	}
+}
+
 func TestConfigFlagsMatchConfigFields(t *testing.T) {
 	t.Skip("TODO - Reenable test when it's less disruptive to rename the CLI flags for consistency.")
 
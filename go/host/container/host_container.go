@@ -28,6 +28,8 @@ const (
 	APINamespaceObscuroScan = "obscuroscan"
 	APINamespaceNetwork     = "net"
 	APINamespaceTest        = "test"
+	APINamespaceDebug       = "debug"
+	APINamespaceAdmin       = "admin"
 )
 
 type HostContainer struct {
@@ -35,6 +37,8 @@ type HostContainer struct {
 	logger         gethlog.Logger
 	metricsService *metrics.Service
 	rpcServer      clientrpc.Server
+
+	stopConfigWatch func() // stops reloading the config on SIGHUP, if it is being reloaded
 }
 
 func (h *HostContainer) Start() error {
@@ -62,6 +66,9 @@ func (h *HostContainer) Start() error {
 }
 
 func (h *HostContainer) Stop() error {
+	if h.stopConfigWatch != nil {
+		h.stopConfigWatch()
+	}
 	h.metricsService.Stop()
 
 	// make sure the rpc server does not request services from a stopped host
@@ -77,17 +84,28 @@ func (h *HostContainer) Host() commonhost.Host {
 	return h.host
 }
 
+// WatchConfig reloads the config loaded by loader when the process receives a SIGHUP, and applies the new log level.
+// The peer list is not reloaded, since it is read from the management contract rather than the config.
+func (h *HostContainer) WatchConfig(loader *config.Loader) {
+	h.stopConfigWatch = loader.WatchReload(h.logger, func(schema interface{}) {
+		logLevel := schema.(*HostConfigToml).LogLevel
+		if err := log.SetLevel(h.logger, logLevel); err != nil {
+			h.logger.Error("Could not change the log level.", log.ErrKey, err)
+			return
+		}
+		h.logger.Info("Changed the log level.", "level", logLevel)
+	})
+}
+
 // NewHostContainerFromConfig uses config to create all HostContainer dependencies and inject them into a new HostContainer
 // (Note: it does not start the HostContainer process, `Start()` must be called on the container)
 func NewHostContainerFromConfig(parsedConfig *config.HostInputConfig) *HostContainer {
 	cfg := parsedConfig.ToHostConfig()
 
 	logger := log.New(log.HostCmp, cfg.LogLevel, cfg.LogPath, log.NodeIDKey, cfg.ID)
-	fmt.Printf("Building host container with config: %+v\n", cfg)
-	logger.Info(fmt.Sprintf("Building host container with config: %+v", cfg))
 
 	// set the Host ID as the Public Key Address
-	ethWallet := wallet.NewInMemoryWalletFromConfig(cfg.PrivateKeyString, cfg.L1ChainID, log.New(log.HostCmp, cfg.LogLevel, cfg.LogPath))
+	ethWallet := wallet.NewInMemoryWalletFromConfig(cfg.PrivateKeyString, cfg.L1ChainID, logger)
 	cfg.ID = ethWallet.Address()
 
 	fmt.Println("Connecting to L1 network...")
@@ -178,6 +196,23 @@ func NewHostContainer(
 				Service:   clientapi.NewFilterAPI(h, logger),
 				Public:    true,
 			},
+			{
+				Namespace: APINamespaceDebug,
+				Version:   APIVersion1,
+				Service:   clientapi.NewDebugAPI(h),
+				Public:    true,
+			},
+		})
+	}
+	if cfg.AdminIPCPath != "" {
+		// The admin API is not public, so it is only served over the IPC endpoint, which only the operator can reach.
+		rpcServer.RegisterAPIs([]rpc.API{
+			{
+				Namespace: APINamespaceAdmin,
+				Version:   APIVersion1,
+				Service:   clientapi.NewAdminAPI(h),
+				Public:    false,
+			},
 		})
 	}
 
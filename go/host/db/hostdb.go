@@ -45,7 +45,7 @@ func (db *DB) Stop() error {
 }
 
 func CreateDBFromConfig(cfg *config.HostConfig, regMetrics gethmetrics.Registry, logger gethlog.Logger) (*DB, error) {
-	if err := validateDBConf(cfg); err != nil {
+	if err := cfg.ValidateDB(); err != nil {
 		return nil, err
 	}
 	if cfg.UseInMemoryDB {
@@ -55,13 +55,6 @@ func CreateDBFromConfig(cfg *config.HostConfig, regMetrics gethmetrics.Registry,
 	return NewLevelDBBackedDB(cfg.LevelDBPath, regMetrics, logger)
 }
 
-func validateDBConf(cfg *config.HostConfig) error {
-	if cfg.UseInMemoryDB && cfg.LevelDBPath != "" {
-		return fmt.Errorf("useInMemoryDB=true so levelDB will not be used and no path is needed, but levelDBPath=%s", cfg.LevelDBPath)
-	}
-	return nil
-}
-
 // NewInMemoryDB returns a new instance of the Node DB
 func NewInMemoryDB(regMetrics gethmetrics.Registry, logger gethlog.Logger) *DB {
 	return newDB(gethdb.NewMemDB(), regMetrics, logger)
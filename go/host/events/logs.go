@@ -44,7 +44,7 @@ func (l *LogEventManager) RemoveSubscription(id rpc.ID) {
 }
 
 // SendLogsToSubscribers distributes logs to subscribed clients.
-func (l *LogEventManager) SendLogsToSubscribers(result *common.BlockSubmissionResponse) {
+func (l *LogEventManager) SendLogsToSubscribers(result *common.EnclaveEvent) {
 	for id, encryptedLogs := range result.SubscribedLogs {
 		logSub, found := l.getSubscriptionThreadsafe(id)
 		if !found {
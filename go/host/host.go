@@ -5,13 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"os"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
-	"github.com/naoina/toml"
 	"github.com/obscuronet/go-obscuro/go/common"
 	"github.com/obscuronet/go-obscuro/go/common/errutil"
 	"github.com/obscuronet/go-obscuro/go/common/log"
@@ -53,6 +54,8 @@ type host struct {
 
 	// control the host lifecycle
 	exitHostCh            chan bool
+	stopCh                chan struct{} // closed when the host stops, to stop handling the enclave's events and requesting batches
+	stopOnce              sync.Once
 	stopHostInterrupt     *int32
 	bootstrappingComplete *int32 // Marks when the host is done bootstrapping
 
@@ -71,6 +74,9 @@ type host struct {
 	logger gethlog.Logger
 
 	metricRegistry gethmetrics.Registry
+
+	rollupsPublished gethmetrics.Counter // The number of times the enclave decided to publish its pending batches
+	rollupsDeferred  gethmetrics.Counter // The number of times the enclave decided to defer publishing its pending batches
 }
 
 func NewHost(
@@ -99,6 +105,7 @@ func NewHost(
 
 		// lifecycle channels
 		exitHostCh:            make(chan bool),
+		stopCh:                make(chan struct{}),
 		stopHostInterrupt:     new(int32),
 		bootstrappingComplete: new(int32),
 
@@ -118,6 +125,9 @@ func NewHost(
 
 		logger:         logger,
 		metricRegistry: regMetrics,
+
+		rollupsPublished: gethmetrics.NewRegisteredCounter("host/rollup/decisions/publish", regMetrics),
+		rollupsDeferred:  gethmetrics.NewRegisteredCounter("host/rollup/decisions/defer", regMetrics),
 	}
 
 	var prof *profiler.Profiler
@@ -129,9 +139,6 @@ func NewHost(
 		}
 	}
 
-	jsonConfig, _ := json.MarshalIndent(config, "", "  ")
-	logger.Info("Host service created with following config:", log.CfgKey, string(jsonConfig))
-
 	return host
 }
 
@@ -139,12 +146,6 @@ func NewHost(
 func (h *host) Start() error {
 	h.validateConfig()
 
-	tomlConfig, err := toml.Marshal(h.config)
-	if err != nil {
-		return fmt.Errorf("could not print host config - %w", err)
-	}
-	h.logger.Info("Host started with following config", log.CfgKey, string(tomlConfig))
-
 	go func() {
 		// wait for the Enclave to be available
 		enclStatus := h.waitForEnclave()
@@ -152,13 +153,21 @@ func (h *host) Start() error {
 		// TODO the host should only connect to enclaves with the same ID as the host.ID
 		// TODO Issue: https://github.com/obscuronet/obscuro-internal/issues/1265
 
+		if h.config.NodeType == common.Sequencer {
+			h.checkBatchInterval()
+		}
+
 		if enclStatus == common.AwaitingSecret {
-			err = h.requestSecret()
+			err := h.requestSecret()
 			if err != nil {
 				h.logger.Crit("Could not request secret", log.ErrKey, err.Error())
 			}
 		}
 
+		if h.config.SnapshotPath != "" {
+			h.importSnapshot()
+		}
+
 		err := h.refreshP2PPeerList()
 		if err != nil {
 			h.logger.Warn("unable to sync current p2p peer list on startup - %w", err)
@@ -171,6 +180,36 @@ func (h *host) Start() error {
 	return nil
 }
 
+// Checks that the host's batch interval matches the enclave's batch production. An enclave configured for time-based
+// batches only produces batches on the host's request, so without an interval the sequencer would never produce one.
+func (h *host) checkBatchInterval() {
+	timeBased, err := h.enclaveClient.TimeBasedBatches()
+	if err != nil {
+		h.logger.Crit("Could not retrieve the enclave's batch production mode.", log.ErrKey, err)
+	}
+	if timeBased && h.config.BatchInterval == 0 {
+		h.logger.Crit("The enclave is configured for time-based batches, but the host's batch interval is zero.")
+	}
+	if !timeBased && h.config.BatchInterval > 0 {
+		h.logger.Crit("The host has a batch interval, but the enclave is not configured for time-based batches.")
+	}
+}
+
+// Has the enclave import the snapshot at the configured path, so that it resumes from the snapshot's L1 head instead of
+// replaying the L1. The blocks streamed from L1StartHash are then rejected by the enclave, which resets the stream to its
+// L1 head. An enclave that already has a head batch (e.g. after a restart) refuses the snapshot and carries on.
+func (h *host) importSnapshot() {
+	snapshot, err := os.ReadFile(h.config.SnapshotPath)
+	if err != nil {
+		h.logger.Crit("Could not read snapshot.", log.ErrKey, err, "path", h.config.SnapshotPath)
+	}
+	if err = h.enclaveClient.ImportSnapshot(snapshot); err != nil {
+		h.logger.Warn("Enclave did not import snapshot.", log.ErrKey, err, "path", h.config.SnapshotPath)
+		return
+	}
+	h.logger.Info("Enclave imported snapshot.", "path", h.config.SnapshotPath)
+}
+
 func (h *host) generateAndBroadcastSecret() error {
 	h.logger.Info("Node is genesis node. Broadcasting secret.")
 	// Create the shared secret and submit it to the management contract for storage
@@ -273,8 +312,15 @@ func (h *host) Unsubscribe(id rpc.ID) {
 }
 
 func (h *host) Stop() {
+	// The host may be stopped more than once, for example by its container after a fatal error, so only the first call
+	// stops it.
+	h.stopOnce.Do(h.stop)
+}
+
+func (h *host) stop() {
 	// block all requests
 	atomic.StoreInt32(h.stopHostInterrupt, 1)
+	close(h.stopCh)
 
 	if err := h.p2p.StopListening(); err != nil {
 		h.logger.Error("failed to close transaction P2P listener cleanly", log.ErrKey, err)
@@ -342,6 +388,10 @@ func (h *host) waitForEnclave() common.Status {
 func (h *host) startProcessing() {
 	h.p2p.StartListening(h)
 
+	// We subscribe to the enclave's events before streaming any block, so that none of the events are missed.
+	events, closeEvents := h.openEnclaveEvents(0)
+	go h.handleEnclaveEvents(events, closeEvents)
+
 	// The blockStream channel is a stream of consecutive, canonical blocks. BlockStream may be replaced with a new
 	// stream ch during the main loop if enclave gets out-of-sync, and we need to stream from an earlier block
 	blockStream, err := h.l1BlockProvider.StartStreamingFromHash(h.config.L1StartHash)
@@ -360,6 +410,13 @@ func (h *host) startProcessing() {
 	i := int32(0)
 	roundInterrupt := &i
 
+	// If the sequencer produces batches on an interval, we ask the enclave for a batch on each tick, provided we have
+	// caught up with the L1.
+	isL1Synced := new(int32)
+	if h.config.NodeType == common.Sequencer && h.config.BatchInterval > 0 {
+		go requestBatches(h.config.BatchInterval, func() bool { return atomic.LoadInt32(isL1Synced) == 1 }, h.createBatch, h.stopCh)
+	}
+
 	// Main Processing Loop -
 	// - Process new blocks from the L1 node
 	// - Process new Transactions gossiped from L2 Peers
@@ -373,6 +430,11 @@ func (h *host) startProcessing() {
 				// handle the error, replace the blockStream if necessary (e.g. if stream needs resetting based on enclave's reported L1 head)
 				blockStream = h.handleProcessBlockErr(b, blockStream, err)
 			}
+			if isLive {
+				atomic.StoreInt32(isL1Synced, 1)
+			} else {
+				atomic.StoreInt32(isL1Synced, 0)
+			}
 
 		case tx := <-h.txP2PCh:
 			// todo: discard p2p messages if enclave won't be able to make use of them (e.g. we're way behind L1 head)
@@ -446,8 +508,8 @@ func (h *host) processL1Block(block *types.Block, isLatestBlock bool) error {
 
 	h.processL1BlockTransactions(block)
 
-	// submit each block to the enclave for ingestion plus validation
-	blockSubmissionResponse, err := h.enclaveClient.SubmitL1Block(*block, h.extractReceipts(block), isLatestBlock)
+	// submit each block to the enclave for ingestion plus validation; its outputs are pushed on the event stream
+	err := h.enclaveClient.SubmitL1Block(*block, h.extractReceipts(block), isLatestBlock)
 	if err != nil {
 		return fmt.Errorf("did not ingest block b_%d. Cause: %w", common.ShortHash(block.Hash()), err)
 	}
@@ -455,29 +517,114 @@ func (h *host) processL1Block(block *types.Block, isLatestBlock bool) error {
 	if err != nil {
 		return fmt.Errorf("submitted block to enclave but could not store the block processing result. Cause: %w", err)
 	}
+	return nil
+}
 
-	h.logEventManager.SendLogsToSubscribers(blockSubmissionResponse)
+// Calls createBatch on each tick of the batch interval while the host is in sync with the L1, until stop is closed.
+func requestBatches(interval time.Duration, isL1Synced func() bool, createBatch func(), stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if isL1Synced() {
+				createBatch()
+			}
+		case <-stop:
+			return
+		}
+	}
+}
 
-	err = h.publishSharedSecretResponses(blockSubmissionResponse.ProducedSecretResponses)
-	if err != nil {
+// Asks the enclave to produce a batch on top of the current head batch. The batch is pushed on the event stream.
+func (h *host) createBatch() {
+	if err := h.enclaveClient.CreateBatch(h.config.SkipEmptyBatches); err != nil {
+		h.logger.Error("could not create batch", log.ErrKey, err)
+	}
+}
+
+// Opens the enclave's event stream from the event following the given one, retrying until it succeeds or the host
+// stops. Returns a nil channel if the host stopped.
+func (h *host) openEnclaveEvents(after uint64) (<-chan *common.EnclaveEvent, func()) {
+	for {
+		events, closeEvents, err := h.enclaveClient.StreamEvents(after)
+		if err == nil {
+			return events, closeEvents
+		}
+		h.logger.Warn("Could not open enclave event stream. Retrying.", log.ErrKey, err)
+		select {
+		case <-time.After(time.Second):
+		case <-h.stopCh:
+			return nil, func() {}
+		}
+	}
+}
+
+// Handles the events pushed by the enclave until the host stops, acknowledging each of them. If the event stream fails,
+// it is reopened from the last event handled, so that the enclave replays the events the host missed.
+func (h *host) handleEnclaveEvents(events <-chan *common.EnclaveEvent, closeEvents func()) {
+	defer func() { closeEvents() }()
+	var lastHandled uint64
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				closeEvents()
+				events, closeEvents = h.openEnclaveEvents(lastHandled)
+				continue
+			}
+			h.handleEnclaveEvent(event)
+			lastHandled = event.Sequence
+			if err := h.enclaveClient.AckEvents(event.Sequence); err != nil {
+				h.logger.Warn("Could not acknowledge enclave event.", log.ErrKey, err)
+			}
+
+		case <-h.stopCh:
+			return
+		}
+	}
+}
+
+func (h *host) handleEnclaveEvent(event *common.EnclaveEvent) {
+	h.logEventManager.SendLogsToSubscribers(event)
+
+	if err := h.publishSharedSecretResponses(event.ProducedSecretResponses); err != nil {
 		h.logger.Error("failed to publish response to secret request", log.ErrKey, err)
 	}
+	if event.ProducedSecretRotation != nil {
+		if err := h.publishSecretRotation(event.ProducedSecretRotation); err != nil {
+			h.logger.Error("failed to publish secret rotation", log.ErrKey, err)
+		}
+	}
 
 	// If we're not the sequencer, we do not need to publish and distribute batches or rollups.
 	if h.config.NodeType != common.Sequencer {
-		return nil
+		return
 	}
+	h.distributeBatchAndPublishRollup(event)
+}
 
-	if blockSubmissionResponse.ProducedBatch != nil && blockSubmissionResponse.ProducedBatch.Header != nil {
-		// TODO - #718 - Unlink batch production from L1 cadence.
-		h.storeAndDistributeBatch(blockSubmissionResponse.ProducedBatch)
+// Distributes the batch and publishes the rollups produced by the enclave, if any.
+func (h *host) distributeBatchAndPublishRollup(event *common.EnclaveEvent) {
+	if event.ProducedBatch != nil && event.ProducedBatch.Header != nil {
+		h.storeAndDistributeBatch(event.ProducedBatch)
 	}
 
-	if blockSubmissionResponse.ProducedRollup != nil && blockSubmissionResponse.ProducedRollup.Header != nil {
-		h.publishRollup(blockSubmissionResponse.ProducedRollup)
+	if decision := event.RollupDecision; decision != nil {
+		if decision.Publish {
+			h.rollupsPublished.Inc(1)
+			h.logger.Info("Enclave decided to publish a rollup", "reason", decision.Reason)
+		} else {
+			h.rollupsDeferred.Inc(1)
+			h.logger.Debug("Enclave deferred publishing a rollup", "reason", decision.Reason)
+		}
 	}
 
-	return nil
+	for _, rollup := range event.ProducedRollups {
+		if rollup != nil && rollup.Header != nil {
+			h.publishRollup(rollup)
+		}
+	}
 }
 
 // Looks at each transaction in the block, and kicks off special handling for the transaction if needed.
@@ -713,6 +860,27 @@ func (h *host) publishSharedSecretResponses(scrtResponses []*common.ProducedSecr
 	return nil
 }
 
+// Publishes the secret of a new epoch generated by the sequencer's enclave, so that every authorised enclave switches to
+// it when it ingests the block the secret is published in.
+func (h *host) publishSecretRotation(rotation *common.SecretRotation) error {
+	encodedRotation, err := common.EncodeSecretRotation(rotation)
+	if err != nil {
+		return err
+	}
+	l1tx := &ethadapter.L1SecretRotationTx{Epoch: rotation.Epoch, Rotation: encodedRotation}
+	rotationTx := h.mgmtContractLib.CreatePublishSecretRotation(l1tx, h.ethWallet.GetNonceAndIncrement())
+	rotationTx, err = h.ethClient.EstimateGasAndGasPrice(rotationTx, h.ethWallet.Address())
+	if err != nil {
+		h.ethWallet.SetNonce(h.ethWallet.GetNonce() - 1)
+		return err
+	}
+	h.logger.Info(fmt.Sprintf("Broadcasting secret of epoch %d.", rotation.Epoch))
+	if err = h.signAndBroadcastL1Tx(rotationTx, l1TxTriesSecret, false); err != nil {
+		return fmt.Errorf("could not broadcast secret rotation. Cause %w", err)
+	}
+	return nil
+}
+
 // Whenever we receive a new shared secret response transaction or restart the host, we update our list of P2P peers
 func (h *host) refreshP2PPeerList() error {
 	// We make a call to the L1 node to retrieve the latest list of aggregators
@@ -896,14 +1064,7 @@ func (h *host) handleBatchRequest(encodedBatchRequest *common.EncodedBatchReques
 
 // Checks the host config is valid.
 func (h *host) validateConfig() {
-	if h.config.IsGenesis && h.config.NodeType != common.Sequencer {
-		h.logger.Crit("genesis node must be the sequencer")
-	}
-	if !h.config.IsGenesis && h.config.NodeType == common.Sequencer {
-		h.logger.Crit("only the genesis node can be a sequencer")
-	}
-
-	if h.config.P2PPublicAddress == "" {
-		h.logger.Crit("the host must specify a public P2P address")
+	if err := h.config.Validate(); err != nil {
+		h.logger.Crit("invalid host config", log.ErrKey, err)
 	}
 }
@@ -0,0 +1,37 @@
+package host
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatchesAreOnlyRequestedWhileInSyncWithTheL1(t *testing.T) {
+	synced := new(int32)
+	requests := make(chan struct{}, 100)
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		requestBatches(time.Millisecond, func() bool { return atomic.LoadInt32(synced) == 1 }, func() { requests <- struct{}{} }, stop)
+		close(stopped)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	if len(requests) != 0 {
+		t.Fatalf("expected no batches to be requested while catching up with the L1, got %d", len(requests))
+	}
+
+	atomic.StoreInt32(synced, 1)
+	select {
+	case <-requests:
+	case <-time.After(time.Second):
+		t.Fatal("expected a batch to be requested once in sync with the L1")
+	}
+
+	close(stop)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("expected batch requests to stop when the host stops")
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/obscuronet/go-obscuro/go/common/container"
 	hostcontainer "github.com/obscuronet/go-obscuro/go/host/container"
@@ -9,11 +10,14 @@ import (
 
 // Runs an Obscuro host as a standalone process.
 func main() {
-	parsedConfig, err := hostcontainer.ParseConfig()
+	parsedConfig, loader, err := hostcontainer.ParseConfig()
 	if err != nil {
-		panic(fmt.Errorf("could not parse config. Cause: %w", err))
+		fmt.Printf("Could not load config. Cause: %s\n", err)
+		os.Exit(1)
 	}
+	fmt.Println(loader.Effective())
 
 	hostContainer := hostcontainer.NewHostContainerFromConfig(parsedConfig)
+	hostContainer.WatchConfig(loader)
 	container.Serve(hostContainer)
 }
@@ -0,0 +1,28 @@
+package clientapi
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/obscuronet/go-obscuro/go/common/host"
+)
+
+// AdminAPI implements the JSON RPC operations reserved for the node's operator. It is only served over the host's
+// admin IPC endpoint, and never over HTTP or websockets.
+type AdminAPI struct {
+	host host.Host
+}
+
+func NewAdminAPI(host host.Host) *AdminAPI {
+	return &AdminAPI{
+		host: host,
+	}
+}
+
+// ExportSnapshot returns a snapshot of the node's state at the batch with the given number, from which a new node can
+// bootstrap. The snapshot is encrypted so that only enclaves holding the shared secret can read it.
+func (api *AdminAPI) ExportSnapshot(batchNumber hexutil.Uint64) (hexutil.Bytes, error) {
+	snapshot, err := api.host.EnclaveClient().ExportSnapshot(uint64(batchNumber))
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.Bytes(snapshot), nil
+}
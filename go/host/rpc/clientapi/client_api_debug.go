@@ -0,0 +1,45 @@
+package clientapi
+
+import (
+	"context"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/host"
+)
+
+// DebugAPI implements the tracing subset of the debug JSON RPC operations. The traces are produced by the enclave,
+// which only serves them if its debug namespace is enabled.
+type DebugAPI struct {
+	host host.Host
+}
+
+func NewDebugAPI(host host.Host) *DebugAPI {
+	return &DebugAPI{
+		host: host,
+	}
+}
+
+// TraceTransaction returns the trace of the given transaction's execution, encrypted with the viewing key corresponding
+// to the original transaction submitter and encoded as hex, or nil if no matching transaction exists.
+func (api *DebugAPI) TraceTransaction(_ context.Context, encryptedParams common.EncryptedParamsTraceTx) (*string, error) {
+	encryptedResponse, err := api.host.EnclaveClient().DebugTraceTransaction(encryptedParams)
+	if err != nil {
+		return nil, err
+	}
+	if encryptedResponse == nil {
+		return nil, nil //nolint:nilnil
+	}
+	encryptedResponseHex := gethcommon.Bytes2Hex(encryptedResponse)
+	return &encryptedResponseHex, nil
+}
+
+// TraceCall returns the trace of the given call's execution, encrypted with the viewing key corresponding to the
+// `from` field and encoded as hex.
+func (api *DebugAPI) TraceCall(_ context.Context, encryptedParams common.EncryptedParamsTraceCall) (string, error) {
+	encryptedResponse, err := api.host.EnclaveClient().DebugTraceCall(encryptedParams)
+	if err != nil {
+		return "", err
+	}
+	return gethcommon.Bytes2Hex(encryptedResponse), nil
+}
@@ -147,6 +147,38 @@ func (api *EthereumAPI) GetCode(_ context.Context, address gethcommon.Address, b
 	return nil, errors.New("invalid arguments; neither batch height nor batch hash specified")
 }
 
+// GetStorageAt returns the value of the storage slot of the given contract in the state for the given batch height or
+// batch hash, provided the contract declares the slot public. The private slots can only be read by the accounts the
+// contract authorises, using `obscuro_getStorageAt`.
+func (api *EthereumAPI) GetStorageAt(_ context.Context, address gethcommon.Address, key string, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	slot := gethcommon.HexToHash(key)
+
+	// requested a number
+	if batchNumber, ok := blockNrOrHash.Number(); ok {
+		batchHash, err := api.batchNumberToBatchHash(batchNumber)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve batch with height %d. Cause: %w", batchNumber, err)
+		}
+
+		value, err := api.host.EnclaveClient().GetPublicStorageAt(address, slot, batchHash)
+		if err != nil {
+			return nil, err
+		}
+		return value.Bytes(), nil
+	}
+
+	// requested a hash
+	if batchHash, ok := blockNrOrHash.Hash(); ok {
+		value, err := api.host.EnclaveClient().GetPublicStorageAt(address, slot, &batchHash)
+		if err != nil {
+			return nil, err
+		}
+		return value.Bytes(), nil
+	}
+
+	return nil, errors.New("invalid arguments; neither batch height nor batch hash specified")
+}
+
 func (api *EthereumAPI) GetTransactionCount(_ context.Context, encryptedParams common.EncryptedParamsGetTxCount) (string, error) {
 	encryptedResponse, err := api.host.EnclaveClient().GetTransactionCount(encryptedParams)
 	if err != nil {
@@ -208,6 +240,7 @@ func headerToMap(header *common.BatchHeader) map[string]interface{} {
 		// The custom Obscuro fields.
 		"agg":                     header.Agg,
 		"l1Proof":                 header.L1Proof,
+		"forkID":                  header.ForkID,
 		"crossChainMessages":      header.CrossChainMessages,
 		"inboundCrossChainHash":   header.LatestInboundCrossChainHash,
 		"inboundCrossChainHeight": header.LatestInboundCrossChainHeight,
@@ -1,6 +1,10 @@
 package clientapi
 
 import (
+	"context"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/obscuronet/go-obscuro/go/common"
 	"github.com/obscuronet/go-obscuro/go/common/host"
 )
 
@@ -24,3 +28,46 @@ func (api *ObscuroAPI) AddViewingKey(viewingKeyBytes []byte, signature []byte) e
 func (api *ObscuroAPI) Health() (*host.HealthCheck, error) {
 	return api.host.HealthCheck()
 }
+
+// GetDivergenceReports returns the signed reports of the batches whose re-execution by the node diverged from the
+// batches signed by the sequencer.
+func (api *ObscuroAPI) GetDivergenceReports() ([]*common.DivergenceReport, error) {
+	return api.host.EnclaveClient().GetDivergenceReports()
+}
+
+// GetWithdrawalProofs returns the cross-chain messages published by the given transaction, with their status and the
+// call data to claim them on the L1, encrypted with the viewing key corresponding to the original transaction
+// submitter and encoded as hex, or nil if no matching transaction exists.
+func (api *ObscuroAPI) GetWithdrawalProofs(_ context.Context, encryptedParams common.EncryptedParamsGetWithdrawals) (*string, error) {
+	encryptedResponse, err := api.host.EnclaveClient().GetWithdrawalProofs(encryptedParams)
+	if err != nil {
+		return nil, err
+	}
+	if encryptedResponse == nil {
+		return nil, nil //nolint:nilnil
+	}
+	encryptedResponseHex := gethcommon.Bytes2Hex(encryptedResponse)
+	return &encryptedResponseHex, nil
+}
+
+// GetStorageAt returns the value of a storage slot of the given contract that the contract authorises the requesting
+// account to read, encrypted with the viewing key corresponding to that account and encoded as hex.
+func (api *ObscuroAPI) GetStorageAt(_ context.Context, encryptedParams common.EncryptedParamsGetStorageAt) (string, error) {
+	encryptedResponse, err := api.host.EnclaveClient().GetStorageAt(encryptedParams)
+	if err != nil {
+		return "", err
+	}
+	return gethcommon.Bytes2Hex(encryptedResponse), nil
+}
+
+// GetInboundMessageRecords returns the audit records of the cross-chain messages sent from the L1 by a sender, or of a
+// single message given its sender and sequence number, encrypted with the viewing key of the node's host and encoded
+// as hex.
+func (api *ObscuroAPI) GetInboundMessageRecords(_ context.Context, encryptedParams common.EncryptedParamsGetInboundMsgs) (*string, error) {
+	encryptedResponse, err := api.host.EnclaveClient().GetInboundMessageRecords(encryptedParams)
+	if err != nil {
+		return nil, err
+	}
+	encryptedResponseHex := gethcommon.Bytes2Hex(encryptedResponse)
+	return &encryptedResponseHex, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/obscuronet/go-obscuro/go/common/host"
 
 	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/obscuronet/go-obscuro/go/common"
 )
@@ -113,3 +114,9 @@ func (api *ObscuroScanAPI) GetTotalTransactions() (*big.Int, error) {
 func (api *ObscuroScanAPI) Attestation() (*common.AttestationReport, error) {
 	return api.host.EnclaveClient().Attestation()
 }
+
+// GetInboundMessageDelivery returns whether and where the cross-chain message sent from the L1 with the given sender
+// and sequence number was delivered, or nil if the node has not seen the message.
+func (api *ObscuroScanAPI) GetInboundMessageDelivery(sender gethcommon.Address, sequence hexutil.Uint64) (*common.InboundMessageDelivery, error) {
+	return api.host.EnclaveClient().GetInboundMessageDelivery(sender, uint64(sequence))
+}
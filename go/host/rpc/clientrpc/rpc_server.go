@@ -43,6 +43,9 @@ func NewServer(config *config.HostConfig, logger gethlog.Logger) Server {
 		rpcConfig.WSOrigins = []string{allOrigins}
 	}
 
+	// The IPC endpoint serves every registered API, including those that are not public.
+	rpcConfig.IPCPath = config.AdminIPCPath
+
 	rpcServerNode, err := node.New(&rpcConfig)
 	if err != nil {
 		logger.Crit("could not create new client server.", log.ErrKey, err)
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	gethlog "github.com/ethereum/go-ethereum/log"
@@ -28,19 +29,45 @@ import (
 	"github.com/obscuronet/go-obscuro/go/common"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+
+	gethcrypto "github.com/ethereum/go-ethereum/crypto"
 )
 
+// Exporting and importing a snapshot of a large state takes much longer than the other enclave RPC calls.
+const snapshotRPCTimeout = 30 * time.Minute
+
 // Client implements enclave.Enclave and should be used by the host when communicating with the enclave via RPC.
 type Client struct {
 	protoClient generated.EnclaveProtoClient
 	connection  *grpc.ClientConn
 	config      *config.HostConfig
 	logger      gethlog.Logger
+
+	// The stream of blocks and batches into the enclave, opened on first use and reopened after a failure
+	ingestStream generated.EnclaveProto_IngestClient
+	cancelIngest context.CancelFunc
+	ingestMutex  sync.Mutex
 }
 
 func NewClient(config *config.HostConfig, logger gethlog.Logger) *Client {
 	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if config.AttestedTLS {
+		hostKey, err := gethcrypto.HexToECDSA(config.PrivateKeyString)
+		if err != nil {
+			logger.Crit("Failed to parse host private key.", log.ErrKey, err)
+		}
+		verifyReport, err := newReportVerifier(config)
+		if err != nil {
+			logger.Crit("Failed to create verifier for the enclave's attestation report.", log.ErrKey, err)
+		}
+		tlsConfig, err := rpc.NewHostTLSConfig(hostKey, config.ID, verifyReport)
+		if err != nil {
+			logger.Crit("Failed to create attested TLS config for enclave RPC connection.", log.ErrKey, err)
+		}
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))}
+	}
 	connection, err := grpc.Dial(config.EnclaveRPCAddress, opts...)
 	if err != nil {
 		logger.Crit("Failed to connect to enclave RPC service.", log.ErrKey, err)
@@ -129,30 +156,26 @@ func (c *Client) InitEnclave(secret common.EncryptedSharedEnclaveSecret) error {
 	return nil
 }
 
-func (c *Client) SubmitL1Block(block types.Block, receipts types.Receipts, isLatest bool) (*common.BlockSubmissionResponse, error) {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
-	defer cancel()
-
+func (c *Client) SubmitL1Block(block types.Block, receipts types.Receipts, isLatest bool) error {
 	var buffer bytes.Buffer
 	if err := block.EncodeRLP(&buffer); err != nil {
-		return nil, fmt.Errorf("could not encode block. Cause: %w", err)
+		return fmt.Errorf("could not encode block. Cause: %w", err)
 	}
 
 	serialized, err := rlp.EncodeToBytes(receipts)
 	if err != nil {
-		return nil, fmt.Errorf("could not encode receipts. Cause: %w", err)
+		return fmt.Errorf("could not encode receipts. Cause: %w", err)
 	}
 
-	response, err := c.protoClient.SubmitL1Block(timeoutCtx, &generated.SubmitBlockRequest{EncodedBlock: buffer.Bytes(), EncodedReceipts: serialized, IsLatest: isLatest})
+	request := &generated.SubmitBlockRequest{EncodedBlock: buffer.Bytes(), EncodedReceipts: serialized, IsLatest: isLatest}
+	response, err := c.ingest(&generated.IngestRequest{Item: &generated.IngestRequest_Block{Block: request}})
 	if err != nil {
-		return nil, fmt.Errorf("could not submit block. Cause: %w", err)
+		return fmt.Errorf("could not submit block. Cause: %w", err)
 	}
-
-	blockSubmissionResponse, err := rpc.FromBlockSubmissionResponseMsg(response.BlockSubmissionResponse)
-	if err != nil {
-		return nil, err
+	if response.BlockError != nil {
+		return rpc.FromBlockSubmissionErrorMsg(response.BlockError)
 	}
-	return blockSubmissionResponse, nil
+	return nil
 }
 
 func (c *Client) SubmitTx(tx common.EncryptedTx) (common.EncryptedResponseSendRawTx, error) {
@@ -167,17 +190,113 @@ func (c *Client) SubmitTx(tx common.EncryptedTx) (common.EncryptedResponseSendRa
 }
 
 func (c *Client) SubmitBatch(batch *common.ExtBatch) error {
+	batchMsg := rpc.ToExtBatchMsg(batch)
+	response, err := c.ingest(&generated.IngestRequest{Item: &generated.IngestRequest_Batch{Batch: &generated.SubmitBatchRequest{Batch: &batchMsg}}})
+	if err != nil {
+		return fmt.Errorf("could not submit batch. Cause: %w", err)
+	}
+	if response.Error != "" {
+		return errors.New(response.Error)
+	}
+	return nil
+}
+
+func (c *Client) StreamEvents(after uint64) (<-chan *common.EnclaveEvent, func(), error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := c.protoClient.StreamEvents(ctx, &generated.StreamEventsRequest{After: after})
+	if err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("could not open event stream. Cause: %w", err)
+	}
+	// We wait for the enclave to acknowledge the stream, so that no event is missed by the caller.
+	if _, err = stream.Recv(); err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("event stream was not acknowledged. Cause: %w", err)
+	}
+
+	events := make(chan *common.EnclaveEvent)
+	go func() {
+		defer close(events)
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				if ctx.Err() == nil {
+					c.logger.Warn("Enclave event stream failed.", log.ErrKey, err)
+				}
+				return
+			}
+			event, err := rpc.FromEnclaveEventMsg(msg)
+			if err != nil {
+				c.logger.Error("Could not decode enclave event.", log.ErrKey, err)
+				continue
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, cancel, nil
+}
+
+func (c *Client) AckEvents(sequence uint64) error {
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
 	defer cancel()
 
-	batchMsg := rpc.ToExtBatchMsg(batch)
-	_, err := c.protoClient.SubmitBatch(timeoutCtx, &generated.SubmitBatchRequest{Batch: &batchMsg})
-	if err != nil {
-		return err
+	if _, err := c.protoClient.AckEvents(timeoutCtx, &generated.AckEventsRequest{Sequence: sequence}); err != nil {
+		return fmt.Errorf("could not acknowledge enclave events. Cause: %w", err)
 	}
 	return nil
 }
 
+// Sends the block or batch on the ingest stream, opening the stream if needed, and waits for the enclave to
+// acknowledge it. The stream is reset if it fails or if the enclave does not respond in time.
+func (c *Client) ingest(request *generated.IngestRequest) (*generated.IngestResponse, error) {
+	c.ingestMutex.Lock()
+	defer c.ingestMutex.Unlock()
+
+	if c.ingestStream == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := c.protoClient.Ingest(ctx)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("could not open ingest stream. Cause: %w", err)
+		}
+		c.ingestStream, c.cancelIngest = stream, cancel
+	}
+
+	// The stream outlives each request, so we enforce the timeout by cancelling the stream.
+	timer := time.AfterFunc(c.config.EnclaveRPCTimeout, c.cancelIngest)
+	response, err := c.sendAndReceive(request)
+	if !timer.Stop() || err != nil {
+		c.resetIngestStream()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) sendAndReceive(request *generated.IngestRequest) (*generated.IngestResponse, error) {
+	if err := c.ingestStream.Send(request); err != nil {
+		return nil, fmt.Errorf("could not send on ingest stream. Cause: %w", err)
+	}
+	response, err := c.ingestStream.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("could not receive from ingest stream. Cause: %w", err)
+	}
+	return response, nil
+}
+
+func (c *Client) resetIngestStream() {
+	if c.ingestStream == nil {
+		return
+	}
+	c.cancelIngest()
+	c.ingestStream, c.cancelIngest = nil, nil
+}
+
 func (c *Client) ExecuteOffChainTransaction(encryptedParams common.EncryptedParamsCall) (common.EncryptedResponseCall, error) {
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
 	defer cancel()
@@ -288,6 +407,34 @@ func (c *Client) GetCode(address gethcommon.Address, batchHash *gethcommon.Hash)
 	return resp.Code, nil
 }
 
+func (c *Client) GetPublicStorageAt(address gethcommon.Address, slot gethcommon.Hash, batchHash *gethcommon.Hash) (gethcommon.Hash, error) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
+	defer cancel()
+
+	resp, err := c.protoClient.GetPublicStorageAt(timeoutCtx, &generated.GetPublicStorageAtRequest{
+		Address:   address.Bytes(),
+		Slot:      slot.Bytes(),
+		BatchHash: batchHash.Bytes(),
+	})
+	if err != nil {
+		return gethcommon.Hash{}, err
+	}
+	return gethcommon.BytesToHash(resp.Value), nil
+}
+
+func (c *Client) GetStorageAt(encryptedParams common.EncryptedParamsGetStorageAt) (common.EncryptedResponseGetStorageAt, error) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
+	defer cancel()
+
+	resp, err := c.protoClient.GetStorageAt(timeoutCtx, &generated.GetStorageAtRequest{
+		EncryptedParams: encryptedParams,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.EncryptedResponse, nil
+}
+
 func (c *Client) Subscribe(id gethrpc.ID, encryptedParams common.EncryptedParamsLogSubscription) error {
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
 	defer cancel()
@@ -335,6 +482,122 @@ func (c *Client) GetLogs(encryptedParams common.EncryptedParamsGetLogs) (common.
 	return resp.EncryptedResponse, nil
 }
 
+func (c *Client) GetWithdrawalProofs(encryptedParams common.EncryptedParamsGetWithdrawals) (common.EncryptedResponseGetWithdrawals, error) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
+	defer cancel()
+
+	resp, err := c.protoClient.GetWithdrawalProofs(timeoutCtx, &generated.GetWithdrawalProofsRequest{
+		EncryptedParams: encryptedParams,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.EncryptedResponse, nil
+}
+
+func (c *Client) DebugTraceTransaction(encryptedParams common.EncryptedParamsTraceTx) (common.EncryptedResponseTrace, error) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
+	defer cancel()
+
+	resp, err := c.protoClient.DebugTraceTransaction(timeoutCtx, &generated.DebugTraceTransactionRequest{
+		EncryptedParams: encryptedParams,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.EncryptedResponse, nil
+}
+
+func (c *Client) DebugTraceCall(encryptedParams common.EncryptedParamsTraceCall) (common.EncryptedResponseTrace, error) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
+	defer cancel()
+
+	resp, err := c.protoClient.DebugTraceCall(timeoutCtx, &generated.DebugTraceCallRequest{
+		EncryptedParams: encryptedParams,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.EncryptedResponse, nil
+}
+
+func (c *Client) GetInboundMessageRecords(encryptedParams common.EncryptedParamsGetInboundMsgs) (common.EncryptedResponseGetInboundMsgs, error) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
+	defer cancel()
+
+	resp, err := c.protoClient.GetInboundMessageRecords(timeoutCtx, &generated.GetInboundMessageRecordsRequest{
+		EncryptedParams: encryptedParams,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.EncryptedResponse, nil
+}
+
+func (c *Client) ExportSnapshot(batchNumber uint64) (common.EncryptedSnapshot, error) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), snapshotRPCTimeout)
+	defer cancel()
+
+	resp, err := c.protoClient.ExportSnapshot(timeoutCtx, &generated.ExportSnapshotRequest{BatchNumber: batchNumber},
+		grpc.MaxCallRecvMsgSize(rpc.MaxSnapshotSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to export snapshot. Cause: %w", err)
+	}
+	return resp.EncryptedSnapshot, nil
+}
+
+func (c *Client) ImportSnapshot(snapshot common.EncryptedSnapshot) error {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), snapshotRPCTimeout)
+	defer cancel()
+
+	stream, err := c.protoClient.ImportSnapshot(timeoutCtx)
+	if err != nil {
+		return fmt.Errorf("failed to import snapshot. Cause: %w", err)
+	}
+	for start := 0; start < len(snapshot); start += rpc.SnapshotChunkSize {
+		end := start + rpc.SnapshotChunkSize
+		if end > len(snapshot) {
+			end = len(snapshot)
+		}
+		if err = stream.Send(&generated.ImportSnapshotRequest{Chunk: snapshot[start:end]}); err != nil {
+			return fmt.Errorf("failed to send snapshot. Cause: %w", err)
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return fmt.Errorf("failed to import snapshot. Cause: %w", err)
+	}
+	if resp.GetError() != "" {
+		return errors.New(resp.GetError())
+	}
+	return nil
+}
+
+func (c *Client) GetInboundMessageDelivery(sender gethcommon.Address, sequence uint64) (*common.InboundMessageDelivery, error) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
+	defer cancel()
+
+	resp, err := c.protoClient.GetInboundMessageDelivery(timeoutCtx, &generated.GetInboundMessageDeliveryRequest{
+		Sender:   sender.Bytes(),
+		Sequence: sequence,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve inbound message delivery. Cause: %w", err)
+	}
+	return rpc.FromInboundMessageDeliveryMsg(resp.Delivery), nil
+}
+
+func (c *Client) GetDivergenceReports() ([]*common.DivergenceReport, error) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
+	defer cancel()
+
+	resp, err := c.protoClient.GetDivergenceReports(timeoutCtx, &generated.EmptyArgs{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve divergence reports. Cause: %w", err)
+	}
+	return rpc.FromDivergenceReportMsgs(resp.Reports), nil
+}
+
 func (c *Client) HealthCheck() (bool, error) {
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
 	defer cancel()
@@ -346,7 +609,7 @@ func (c *Client) HealthCheck() (bool, error) {
 	return resp.Status, nil
 }
 
-func (c *Client) GenerateRollup() (*common.ExtRollup, error) {
+func (c *Client) GenerateRollups() ([]*common.ExtRollup, error) {
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
 	defer cancel()
 
@@ -354,5 +617,31 @@ func (c *Client) GenerateRollup() (*common.ExtRollup, error) {
 	if err != nil {
 		return nil, err
 	}
-	return rpc.FromExtRollupMsg(resp.Msg), nil
+	rollups := make([]*common.ExtRollup, len(resp.Msgs))
+	for idx, msg := range resp.Msgs {
+		rollups[idx] = rpc.FromExtRollupMsg(msg)
+	}
+	return rollups, nil
+}
+
+func (c *Client) CreateBatch(skipIfEmpty bool) error {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
+	defer cancel()
+
+	_, err := c.protoClient.CreateBatch(timeoutCtx, &generated.CreateBatchRequest{SkipIfEmpty: skipIfEmpty})
+	if err != nil {
+		return fmt.Errorf("could not create batch. Cause: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) TimeBasedBatches() (bool, error) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), c.config.EnclaveRPCTimeout)
+	defer cancel()
+
+	resp, err := c.protoClient.TimeBasedBatches(timeoutCtx, &generated.EmptyArgs{})
+	if err != nil {
+		return false, fmt.Errorf("could not retrieve batch production mode. Cause: %w", err)
+	}
+	return resp.TimeBased, nil
 }
@@ -0,0 +1,187 @@
+package enclaverpc
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/config"
+	"github.com/obscuronet/go-obscuro/go/enclave"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+const testRPCTimeout = 500 * time.Millisecond
+
+func TestIngestReturnsTheEnclaveResponseToEachBlockAndBatch(t *testing.T) {
+	l1Head := gethcommon.HexToHash("0x01")
+	stub := &stubEnclave{
+		submitL1Block: func(types.Block) error {
+			return &common.BlockRejectError{L1Head: l1Head, Wrapped: errors.New("block is not on the canonical chain")}
+		},
+		submitBatch: func(batch *common.ExtBatch) error {
+			if batch.Header.Number.Uint64() == 1 {
+				return errors.New("batch is invalid")
+			}
+			return nil
+		},
+	}
+	client := newTestClient(t, stub)
+
+	var rejectErr *common.BlockRejectError
+	err := client.SubmitL1Block(*types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}), types.Receipts{}, true)
+	if !errors.As(err, &rejectErr) || rejectErr.L1Head != l1Head {
+		t.Fatalf("expected block to be rejected with the enclave's L1 head, got: %v", err)
+	}
+	if err = client.SubmitBatch(newTestBatch(1)); err == nil || !strings.Contains(err.Error(), "batch is invalid") {
+		t.Fatalf("expected batch to be rejected, got: %v", err)
+	}
+	if err = client.SubmitBatch(newTestBatch(2)); err != nil {
+		t.Fatalf("expected batch to be ingested, got: %s", err)
+	}
+}
+
+func TestIngestStreamIsResetAfterTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	var calls int
+	var callsMutex sync.Mutex
+	stub := &stubEnclave{
+		submitBatch: func(*common.ExtBatch) error {
+			callsMutex.Lock()
+			calls++
+			first := calls == 1
+			callsMutex.Unlock()
+			if first {
+				<-release
+			}
+			return nil
+		},
+	}
+	client := newTestClient(t, stub)
+
+	start := time.Now()
+	if err := client.SubmitBatch(newTestBatch(1)); err == nil {
+		t.Fatal("expected batch submission to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 5*testRPCTimeout {
+		t.Fatalf("expected batch submission to time out after %s, took %s", testRPCTimeout, elapsed)
+	}
+
+	// The enclave is still stuck on the first batch, so the next batch must go over a new stream.
+	if err := client.SubmitBatch(newTestBatch(2)); err != nil {
+		t.Fatalf("expected batch to be ingested over a new stream, got: %s", err)
+	}
+}
+
+func TestEventsAreStreamedFromTheRequestedSequenceAndAcknowledged(t *testing.T) {
+	stub := &stubEnclave{
+		events:    make(chan *common.EnclaveEvent),
+		streamed:  make(chan uint64, 1),
+		acked:     make(chan uint64, 1),
+		closedEvt: make(chan struct{}),
+	}
+	client := newTestClient(t, stub)
+
+	events, closeEvents, err := client.StreamEvents(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after := <-stub.streamed; after != 5 {
+		t.Fatalf("expected enclave to stream the events after 5, got after %d", after)
+	}
+
+	stub.events <- &common.EnclaveEvent{RollupDecision: &common.RollupDecision{Reason: "due"}, Sequence: 6}
+	select {
+	case event := <-events:
+		if event.Sequence != 6 || event.RollupDecision.Reason != "due" {
+			t.Fatalf("unexpected event: %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not streamed")
+	}
+
+	if err = client.AckEvents(6); err != nil {
+		t.Fatal(err)
+	}
+	if sequence := <-stub.acked; sequence != 6 {
+		t.Fatalf("expected event 6 to be acknowledged, got %d", sequence)
+	}
+
+	closeEvents()
+	select {
+	case <-stub.closedEvt:
+	case <-time.After(5 * time.Second):
+		t.Fatal("enclave did not close the event stream after the host closed it")
+	}
+}
+
+// stubEnclave responds to the calls made over the enclave's ingest and event streams.
+type stubEnclave struct {
+	common.Enclave
+	submitL1Block func(block types.Block) error
+	submitBatch   func(batch *common.ExtBatch) error
+
+	events    chan *common.EnclaveEvent
+	streamed  chan uint64 // receives the sequence number each event stream is opened after
+	acked     chan uint64
+	closedEvt chan struct{} // closed when the event stream is closed
+}
+
+func (s *stubEnclave) Stop() error {
+	return nil
+}
+
+func (s *stubEnclave) SubmitL1Block(block types.Block, _ types.Receipts, _ bool) error {
+	return s.submitL1Block(block)
+}
+
+func (s *stubEnclave) SubmitBatch(batch *common.ExtBatch) error {
+	return s.submitBatch(batch)
+}
+
+func (s *stubEnclave) StreamEvents(after uint64) (<-chan *common.EnclaveEvent, func(), error) {
+	s.streamed <- after
+	var once sync.Once
+	return s.events, func() { once.Do(func() { close(s.closedEvt) }) }, nil
+}
+
+func (s *stubEnclave) AckEvents(sequence uint64) error {
+	s.acked <- sequence
+	return nil
+}
+
+// Starts an enclave RPC server relaying calls to the enclave, and returns a client connected to it.
+func newTestClient(t *testing.T, encl common.Enclave) *Client {
+	address := freeAddress(t)
+	server := enclave.NewEnclaveRPCServer(address, encl, nil, gethlog.New())
+	if err := server.StartServer(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _, _ = server.Stop(context.Background(), nil) })
+
+	client := NewClient(&config.HostConfig{EnclaveRPCAddress: address, EnclaveRPCTimeout: testRPCTimeout}, gethlog.New())
+	t.Cleanup(func() { _ = client.StopClient() })
+	return client
+}
+
+func freeAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+func newTestBatch(number int64) *common.ExtBatch {
+	return &common.ExtBatch{Header: &common.BatchHeader{Number: big.NewInt(number), R: big.NewInt(0), S: big.NewInt(0)}}
+}
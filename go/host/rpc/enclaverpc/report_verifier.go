@@ -0,0 +1,30 @@
+package enclaverpc
+
+import (
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/rpc"
+	"github.com/obscuronet/go-obscuro/go/config"
+)
+
+// Returns the verifier the host applies to its enclave's attestation report when connecting over attested TLS. If the
+// enclave's attestation is simulated, the report is not verified, as DummyAttestationProvider does in the enclave.
+func newReportVerifier(config *config.HostConfig) (rpc.ReportVerifier, error) {
+	if !config.EnclaveWillAttest {
+		return func(att *common.AttestationReport) ([]byte, error) {
+			return att.IDHash()
+		}, nil
+	}
+
+	tcbStatuses, err := common.ParseTCBStatuses(config.AttestationAllowedTCBStatuses)
+	if err != nil {
+		return nil, err
+	}
+	return newEgoReportVerifier(&common.AttestationPolicy{
+		UniqueIDs:          config.AttestationUniqueIDs,
+		SignerIDs:          config.AttestationSignerIDs,
+		MinSecurityVersion: config.AttestationMinSecurityVersion,
+		ProductID:          config.AttestationProductID,
+		AllowDebug:         config.AttestationAllowDebug,
+		AllowedTCBStatuses: tcbStatuses,
+	})
+}
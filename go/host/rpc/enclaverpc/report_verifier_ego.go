@@ -0,0 +1,29 @@
+//go:build hostattestation
+
+package enclaverpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/edgelesssys/ego/eclient"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/rpc"
+)
+
+// Returns a verifier that checks the report's signature against the SGX quoting infrastructure, then checks the report
+// against the policy, as EgoAttestationProvider does in the enclave.
+func newEgoReportVerifier(policy *common.AttestationPolicy) (rpc.ReportVerifier, error) {
+	return func(att *common.AttestationReport) ([]byte, error) {
+		remoteReport, err := eclient.VerifyRemoteReport(att.Report)
+		// A report whose TCB level is not up-to-date is still returned, so that the policy can decide whether to accept it.
+		if err != nil && !errors.Is(err, attestation.ErrTCBLevelInvalid) {
+			return nil, err
+		}
+		if err = policy.Check(remoteReport); err != nil {
+			return nil, fmt.Errorf("attestation report does not satisfy the attestation policy. Cause: %w", err)
+		}
+		return remoteReport.Data, nil
+	}, nil
+}
@@ -0,0 +1,16 @@
+//go:build !hostattestation
+
+package enclaverpc
+
+import (
+	"errors"
+
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/rpc"
+)
+
+// Verifying an SGX report outside an enclave requires the Open Enclave host verification libraries, which the host is
+// only linked against when built with the `hostattestation` tag (see dockerfiles/host.Dockerfile).
+func newEgoReportVerifier(*common.AttestationPolicy) (rpc.ReportVerifier, error) {
+	return nil, errors.New("the host was built without attestation verification; rebuild it with `-tags hostattestation`")
+}
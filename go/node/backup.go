@@ -0,0 +1,133 @@
+package node
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/obscuronet/go-obscuro/go/common/docker"
+)
+
+// The files a backup is made of. Both are tar archives, as produced by docker.
+const (
+	_hostDBBackupFile         = "host-db.tar"
+	_edbCredentialsBackupFile = "edb-credentials.tar"
+	// The file the enclave seals its EdgelessDB credentials into. Without it, the enclave cannot connect to an
+	// EdgelessDB that has already been initialised.
+	_edbCredentialsFile = "edb-credentials.json"
+)
+
+// Backup copies the host DB and, on SGX nodes, the sealed EdgelessDB credentials into the dir. The host is stopped
+// while its DB is copied. The credentials can only be unsealed by the enclave that sealed them, so the backup is only
+// useful to restore the same enclave, e.g. after its volume was lost.
+func (d *DockerNode) Backup(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("could not create backup dir. Cause: %w", err)
+	}
+
+	err := d.whileHostStopped(func() error {
+		return copyFromContainer(d.containerName(HostComponent), _hostDataDir, filepath.Join(dir, _hostDBBackupFile))
+	})
+	if err != nil {
+		return fmt.Errorf("could not back up host DB. Cause: %w", err)
+	}
+
+	if d.cfg.sgxEnabled {
+		credentialsPath := filepath.Join(_enclaveDataDir, _edbCredentialsFile)
+		err = copyFromContainer(d.containerName(EnclaveComponent), credentialsPath, filepath.Join(dir, _edbCredentialsBackupFile))
+		if err != nil {
+			return fmt.Errorf("could not back up EdgelessDB credentials. Cause: %w", err)
+		}
+	}
+
+	fmt.Printf("Node %s backed up to %s\n", d.cfg.nodeName, dir)
+	return nil
+}
+
+// Restore replaces the host DB and, on SGX nodes, the sealed EdgelessDB credentials with those backed up in the dir,
+// then restarts the node.
+func (d *DockerNode) Restore(dir string) error {
+	hostDBBackup, err := os.Open(filepath.Join(dir, _hostDBBackupFile))
+	if err != nil {
+		return fmt.Errorf("could not open host DB backup. Cause: %w", err)
+	}
+	defer hostDBBackup.Close()
+
+	var edbCredentialsBackup *os.File
+	if d.cfg.sgxEnabled {
+		edbCredentialsBackup, err = os.Open(filepath.Join(dir, _edbCredentialsBackupFile))
+		if err != nil {
+			return fmt.Errorf("could not open EdgelessDB credentials backup. Cause: %w", err)
+		}
+		defer edbCredentialsBackup.Close()
+	}
+
+	if err = d.Stop(); err != nil {
+		return err
+	}
+
+	// the host volume is recreated, so that no file from the current DB is left alongside the restored one. The volume
+	// is named after the node, so a host that still uses the volume shared by the nodes created before is moved to its
+	// own volume, and the shared volume is left to the other nodes
+	hostName := d.containerName(HostComponent)
+	if err = d.removeIfExists(hostName); err != nil {
+		return err
+	}
+	if err = docker.RemoveVolume(d.hostVolume()); err != nil {
+		return fmt.Errorf("could not remove host volume %s. Cause: %w", d.hostVolume(), err)
+	}
+	if err = d.createHost(map[string]string{d.hostVolume(): _hostDataDir}); err != nil {
+		return fmt.Errorf("could not create host. Cause: %w", err)
+	}
+	// the archive holds the data dir itself, so it is extracted into the dir's parent
+	if err = docker.CopyToContainer(hostName, filepath.Dir(_hostDataDir), hostDBBackup); err != nil {
+		return fmt.Errorf("could not restore host DB. Cause: %w", err)
+	}
+
+	if edbCredentialsBackup != nil {
+		err = docker.CopyToContainer(d.containerName(EnclaveComponent), _enclaveDataDir, edbCredentialsBackup)
+		if err != nil {
+			return fmt.Errorf("could not restore EdgelessDB credentials. Cause: %w", err)
+		}
+	}
+
+	for _, component := range d.components() {
+		if err = d.startExisting(component); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Node %s restored from %s\n", d.cfg.nodeName, dir)
+	return nil
+}
+
+// Runs the function with the host container stopped, then starts the host again if it was running.
+func (d *DockerNode) whileHostStopped(fn func() error) error {
+	name := d.containerName(HostComponent)
+	state, found, err := docker.ContainerState(name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("node has no %s container", name)
+	}
+	if state == "running" {
+		if err = docker.Stop(name); err != nil {
+			return fmt.Errorf("could not stop %s. Cause: %w", name, err)
+		}
+		defer func() {
+			if startErr := docker.StartContainer(name); startErr != nil {
+				fmt.Printf("Could not restart %s. Cause: %s\n", name, startErr)
+			}
+		}()
+	}
+	return fn()
+}
+
+func copyFromContainer(containerName, srcPath, dstFile string) error {
+	f, err := os.OpenFile(dstFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return docker.CopyFromContainer(containerName, srcPath, f)
+}
@@ -5,12 +5,20 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/obscuronet/go-obscuro/go/node"
 )
 
 var (
+	initAction       = "init"
 	startAction      = "start"
+	stopAction       = "stop"
+	statusAction     = "status"
 	upgradeAction    = "upgrade"
-	validNodeActions = []string{startAction, upgradeAction}
+	backupAction     = "backup"
+	restoreAction    = "restore"
+	logsAction       = "logs"
+	validNodeActions = []string{initAction, startAction, stopAction, statusAction, upgradeAction, backupAction, restoreAction, logsAction}
 )
 
 // NodeConfigCLI represents the configurations passed into the node over CLI
@@ -37,10 +45,19 @@ type NodeConfigCLI struct {
 	edgelessDBImage        string
 	hostHTTPPort           int
 	hostWSPort             int
+	hostRPCHost            string
 	nodeName               string
+	batchIntervalMs        uint64
+	enclaveSignerID        string
+	configPath             string
+	backupDir              string
+	logsComponent          string
+	logsTail               string
+	logsFollow             bool
 }
 
-// ParseConfigCLI returns a NodeConfigCLI based the cli params and defaults.
+// ParseConfigCLI returns a NodeConfigCLI based the cli params and defaults. Except for the init action, the flags that are
+// not set are read from the node config file, if there is one.
 func ParseConfigCLI() *NodeConfigCLI {
 	cfg := &NodeConfigCLI{}
 	flagUsageMap := getFlagUsageMap()
@@ -58,6 +75,7 @@ func ParseConfigCLI() *NodeConfigCLI {
 	hostP2PPublicAddr := flag.String(hostP2PPublicAddrFlag, "", flagUsageMap[hostP2PPublicAddrFlag])
 	hostHTTPPort := flag.Int(hostHTTPPortFlag, 13000, flagUsageMap[hostHTTPPortFlag])
 	hostWSPort := flag.Int(hostWSPortFlag, 13001, flagUsageMap[hostWSPortFlag])
+	hostRPCHost := flag.String(hostRPCHostFlag, "127.0.0.1", flagUsageMap[hostRPCHostFlag])
 	enclaveHTTPPort := flag.Int(enclaveHTTPPortFlag, 11000, flagUsageMap[enclaveHTTPPortFlag])
 	enclaveWSPort := flag.Int(enclaveWSPortFlag, 11001, flagUsageMap[enclaveWSPortFlag])
 	privateKey := flag.String(privateKeyFlag, "", flagUsageMap[privateKeyFlag])
@@ -67,8 +85,35 @@ func ParseConfigCLI() *NodeConfigCLI {
 	messageBusContractAddr := flag.String(messageBusContractAddrFlag, "", flagUsageMap[messageBusContractAddrFlag])
 	pccsAddr := flag.String(pccsAddrFlag, "", flagUsageMap[pccsAddrFlag])
 	edgelessDBImage := flag.String(edgelessDBImageFlag, "ghcr.io/edgelesssys/edgelessdb-sgx-4gb:v0.3.2", flagUsageMap[edgelessDBImageFlag])
+	batchIntervalMs := flag.Uint64(batchIntervalMsFlag, 0, flagUsageMap[batchIntervalMsFlag])
+	enclaveSignerID := flag.String(enclaveSignerIDFlag, "", flagUsageMap[enclaveSignerIDFlag])
+	configPath := flag.String(configPathFlag, "/home/obscuro/node.json", flagUsageMap[configPathFlag])
+	backupDir := flag.String(backupDirFlag, "/home/obscuro/backup", flagUsageMap[backupDirFlag])
+	logsComponent := flag.String(logsComponentFlag, node.HostComponent, flagUsageMap[logsComponentFlag])
+	logsTail := flag.String(logsTailFlag, "100", flagUsageMap[logsTailFlag])
+	logsFollow := flag.Bool(logsFollowFlag, false, flagUsageMap[logsFollowFlag])
 
 	flag.Parse()
+
+	cfg.nodeAction = flag.Arg(0)
+	if !validateNodeAction(cfg.nodeAction) {
+		if cfg.nodeAction == "" {
+			fmt.Printf("expected a node action string (%s) as the only argument after the flags but no argument provided\n",
+				strings.Join(validNodeActions, ", "))
+		} else {
+			fmt.Printf("expected a node action string (%s) as the only argument after the flags but got %s\n",
+				strings.Join(validNodeActions, ", "), cfg.nodeAction)
+		}
+		os.Exit(1)
+	}
+
+	if cfg.nodeAction != initAction {
+		if err := applyConfigFile(*configPath); err != nil {
+			fmt.Printf("could not read node config file %s. Cause: %s\n", *configPath, err)
+			os.Exit(1)
+		}
+	}
+
 	cfg.nodeName = *nodeName
 	cfg.nodeType = *nodeType
 	cfg.isGenesis = *isGenesis
@@ -91,18 +136,14 @@ func ParseConfigCLI() *NodeConfigCLI {
 	cfg.edgelessDBImage = *edgelessDBImage
 	cfg.hostHTTPPort = *hostHTTPPort
 	cfg.hostWSPort = *hostWSPort
-
-	cfg.nodeAction = flag.Arg(0)
-	if !validateNodeAction(cfg.nodeAction) {
-		if cfg.nodeAction == "" {
-			fmt.Printf("expected a node action string (%s) as the only argument after the flags but no argument provided\n",
-				strings.Join(validNodeActions, ", "))
-		} else {
-			fmt.Printf("expected a node action string (%s) as the only argument after the flags but got %s\n",
-				strings.Join(validNodeActions, ", "), cfg.nodeAction)
-		}
-		os.Exit(1)
-	}
+	cfg.hostRPCHost = *hostRPCHost
+	cfg.batchIntervalMs = *batchIntervalMs
+	cfg.enclaveSignerID = *enclaveSignerID
+	cfg.configPath = *configPath
+	cfg.backupDir = *backupDir
+	cfg.logsComponent = *logsComponent
+	cfg.logsTail = *logsTail
+	cfg.logsFollow = *logsFollow
 
 	return cfg
 }
@@ -13,6 +13,7 @@ const (
 	l1WSPortFlag               = "l1_ws_port"
 	hostHTTPPortFlag           = "host_http_port"
 	hostWSPortFlag             = "host_ws_port"
+	hostRPCHostFlag            = "host_rpc_host"
 	hostP2PPortFlag            = "host_p2p_port"
 	hostP2PHostFlag            = "host_p2p_host"
 	hostP2PPublicAddrFlag      = "host_public_p2p_addr"
@@ -24,8 +25,25 @@ const (
 	messageBusContractAddrFlag = "message_bus_contract_addr"
 	pccsAddrFlag               = "pccs_addr"
 	edgelessDBImageFlag        = "edgeless_db_image"
+	batchIntervalMsFlag        = "batch_interval_ms"
+	enclaveSignerIDFlag        = "enclave_signer_id"
+	configPathFlag             = "config_path"
+	backupDirFlag              = "backup_dir"
+	logsComponentFlag          = "logs_component"
+	logsTailFlag               = "logs_tail"
+	logsFollowFlag             = "logs_follow"
 )
 
+// The flags that select what an action operates on, rather than configure the node, so they are not written to the
+// node config file.
+var actionFlags = map[string]bool{
+	configPathFlag:    true,
+	backupDirFlag:     true,
+	logsComponentFlag: true,
+	logsTailFlag:      true,
+	logsFollowFlag:    true,
+}
+
 // Returns a map of the flag usages.
 // While we could just use constants instead of a map, this approach allows us to test that all the expected flags are defined.
 func getFlagUsageMap() map[string]string {
@@ -52,5 +70,13 @@ func getFlagUsageMap() map[string]string {
 		edgelessDBImageFlag:        "Sets the edgelessdb image",
 		hostHTTPPortFlag:           "Host HTTPs bound port",
 		hostWSPortFlag:             "Host WebSocket bound port",
+		hostRPCHostFlag:            "The address at which the status and upgrade actions reach the host's HTTP port, which is published on the docker host",
+		batchIntervalMsFlag:        "How often the sequencer produces a batch, in milliseconds. If zero, a batch is produced for each L1 block",
+		enclaveSignerIDFlag:        "The signing key (MRSIGNER) of the enclave image. Required on SGX, where the host and enclave only recognise enclaves signed with it",
+		configPathFlag:             "The node config file written by the init action. The flags that are not set are read from it",
+		backupDirFlag:              "The dir the backup action writes to, and the restore action reads from. The upgrade action backs the node up into its pre-upgrade subdir",
+		logsComponentFlag:          "The component whose logs are shown by the logs action (host, enclave or edgelessdb)",
+		logsTailFlag:               "How many lines from the end of the logs are shown by the logs action, or all",
+		logsFollowFlag:             "Whether the logs action keeps showing new logs",
 	}
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// The node config file holds the value of each flag that configures the node, by flag name, so that the operator does
+// not have to pass them again to every action. It holds the node's private key, so it is only readable by its owner.
+
+// initConfigFile generates the node's private key if none was provided, then writes the node config file. It does not
+// overwrite an existing file, since that would lose the node's key.
+func initConfigFile(cliConfig *NodeConfigCLI) error {
+	if _, err := os.Stat(cliConfig.configPath); err == nil {
+		return fmt.Errorf("node config file %s already exists", cliConfig.configPath)
+	}
+	if cliConfig.privateKey == "" {
+		privateKey, err := crypto.GenerateKey()
+		if err != nil {
+			return fmt.Errorf("could not generate private key. Cause: %w", err)
+		}
+		cliConfig.privateKey = gethcommon.Bytes2Hex(crypto.FromECDSA(privateKey))
+		fmt.Println("Generated a new private key for the node")
+	}
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(cliConfig.privateKey, "0x"))
+	if err != nil {
+		return fmt.Errorf("could not parse private key. Cause: %w", err)
+	}
+	hostID := crypto.PubkeyToAddress(privateKey.PublicKey)
+	if cliConfig.hostID != "" && gethcommon.HexToAddress(cliConfig.hostID) != hostID {
+		return fmt.Errorf("host ID %s is not the address of the private key, which is %s", cliConfig.hostID, hostID)
+	}
+	cliConfig.hostID = hostID.Hex()
+
+	values := map[string]string{}
+	flag.VisitAll(func(f *flag.Flag) {
+		if !actionFlags[f.Name] {
+			values[f.Name] = f.Value.String()
+		}
+	})
+	values[privateKeyFlag] = cliConfig.privateKey
+	values[hostIDFlag] = cliConfig.hostID
+
+	encoded, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode node config. Cause: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(cliConfig.configPath), 0o700); err != nil {
+		return fmt.Errorf("could not create node config dir. Cause: %w", err)
+	}
+	f, err := os.OpenFile(cliConfig.configPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("node config file %s already exists", cliConfig.configPath)
+		}
+		return err
+	}
+	defer f.Close()
+	if _, err = f.Write(encoded); err != nil {
+		return err
+	}
+
+	fmt.Printf("Node config written to %s. The node's host ID is %s\n", cliConfig.configPath, cliConfig.hostID)
+	return nil
+}
+
+// applyConfigFile sets the flags that were not set on the command line to their value in the node config file. It
+// does nothing if there is no file, so that the node can still be configured with flags only.
+func applyConfigFile(path string) error {
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var values map[string]string
+	if err = json.Unmarshal(encoded, &values); err != nil {
+		return err
+	}
+
+	setOnCommandLine := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		setOnCommandLine[f.Name] = true
+	})
+	for name, value := range values {
+		if setOnCommandLine[name] {
+			continue
+		}
+		if err = flag.Set(name, value); err != nil {
+			return fmt.Errorf("invalid value for %s. Cause: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+func TestConfigFileRoundTrip(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "node.json")
+	initConfig := parseTestCLI(t, "-config_path", configPath, "-node_type", "validator", "-l1_host", "l1.example", "init")
+	if err := initConfigFile(initConfig); err != nil {
+		t.Fatalf("could not write node config file. Cause: %s", err)
+	}
+	info, err := os.Stat(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected the node config file to only be readable by its owner, got %s", info.Mode().Perm())
+	}
+
+	// The flags that are not set on the command line are read from the file, and the others take precedence over it.
+	cfg := parseTestCLI(t, "-config_path", configPath, "-l1_host", "other.example", "start")
+	if cfg.nodeType != "validator" || cfg.l1Host != "other.example" {
+		t.Fatalf("expected the node type from the file and the L1 host from the command line, got %s and %s", cfg.nodeType, cfg.l1Host)
+	}
+	privateKey, err := crypto.HexToECDSA(cfg.privateKey)
+	if err != nil {
+		t.Fatalf("expected the generated private key to be read from the file. Cause: %s", err)
+	}
+	if gethcommon.HexToAddress(cfg.hostID) != crypto.PubkeyToAddress(privateKey.PublicKey) {
+		t.Fatal("expected the host ID to be the address of the private key")
+	}
+	// The flags that select what an action operates on are not written to the file.
+	if cfg = parseTestCLI(t, "-config_path", configPath, "status"); cfg.configPath != configPath || cfg.backupDir != "/home/obscuro/backup" {
+		t.Fatal("expected the action flags not to be read from the node config file")
+	}
+}
+
+func TestConfigFileIsNotOverwritten(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "node.json")
+	if err := initConfigFile(parseTestCLI(t, "-config_path", configPath, "init")); err != nil {
+		t.Fatalf("could not write node config file. Cause: %s", err)
+	}
+	original, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = initConfigFile(parseTestCLI(t, "-config_path", configPath, "-node_type", "sequencer", "init"))
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected the existing node config file not to be overwritten, got %v", err)
+	}
+	current, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(current) != string(original) {
+		t.Fatal("expected the node config file to be unchanged")
+	}
+}
+
+func TestConfigFileRejectsMismatchedHostID(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "node.json")
+	err := initConfigFile(parseTestCLI(t, "-config_path", configPath, "-host_id", "0x01", "init"))
+	if err == nil || !strings.Contains(err.Error(), "is not the address of the private key") {
+		t.Fatalf("expected a host ID that does not match the private key to be rejected, got %v", err)
+	}
+	if _, err = os.Stat(configPath); !os.IsNotExist(err) {
+		t.Fatal("expected no node config file to be written")
+	}
+}
+
+// Parses the flags and action as if they were passed to the CLI.
+func parseTestCLI(t *testing.T, args ...string) *NodeConfigCLI {
+	t.Helper()
+	originalArgs := os.Args
+	t.Cleanup(func() { os.Args = originalArgs })
+	flag.CommandLine = flag.NewFlagSet(originalArgs[0], flag.ContinueOnError)
+	os.Args = append([]string{originalArgs[0]}, args...)
+	return ParseConfigCLI()
+}
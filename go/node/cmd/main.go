@@ -1,12 +1,21 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/obscuronet/go-obscuro/go/node"
 )
 
 func main() {
 	cliConfig := ParseConfigCLI()
-	// todo: allow for multiple operation (start, stop, status)
+	if cliConfig.nodeAction == initAction {
+		if err := initConfigFile(cliConfig); err != nil {
+			panic(err)
+		}
+		return
+	}
 
 	nodeCfg := node.NewNodeConfig(
 		node.WithNodeName(cliConfig.nodeName),
@@ -22,6 +31,7 @@ func main() {
 		node.WithHostPublicP2PAddr(cliConfig.hostP2PPublicAddr),              // node public facing ip and port
 		node.WithHostHTTPPort(cliConfig.hostHTTPPort),                        // 12000
 		node.WithHostWSPort(cliConfig.hostWSPort),                            // 12001
+		node.WithHostRPCHost(cliConfig.hostRPCHost),                          // 127.0.0.1
 		node.WithEnclaveWSPort(cliConfig.enclaveWSPort),                      // 13001
 		node.WithPrivateKey(cliConfig.privateKey),                            // "8ead642ca80dadb0f346a66cd6aa13e08a8ac7b5c6f7578d4bac96f5db01ac99"
 		node.WithHostID(cliConfig.hostID),                                    // "0x0654D8B60033144D567f25bF41baC1FB0D60F23B"),
@@ -30,6 +40,8 @@ func main() {
 		node.WithMessageBusContractAddress(cliConfig.messageBusContractAddr), // "0xFD03804faCA2538F4633B3EBdfEfc38adafa259B"),
 		node.WithPCCSAddr(cliConfig.pccsAddr),
 		node.WithEdgelessDBImage(cliConfig.edgelessDBImage),
+		node.WithBatchIntervalMs(cliConfig.batchIntervalMs),
+		node.WithEnclaveSignerID(cliConfig.enclaveSignerID),
 	)
 
 	dockerNode, err := node.NewDockerNode(nodeCfg)
@@ -40,8 +52,22 @@ func main() {
 	switch cliConfig.nodeAction {
 	case startAction:
 		err = dockerNode.Start()
+	case stopAction:
+		err = dockerNode.Stop()
+	case statusAction:
+		var status *node.Status
+		status, err = dockerNode.Status()
+		if status != nil {
+			printStatus(status)
+		}
 	case upgradeAction:
-		err = dockerNode.Upgrade()
+		err = dockerNode.Upgrade(cliConfig.backupDir)
+	case backupAction:
+		err = dockerNode.Backup(cliConfig.backupDir)
+	case restoreAction:
+		err = dockerNode.Restore(cliConfig.backupDir)
+	case logsAction:
+		err = dockerNode.Logs(cliConfig.logsComponent, cliConfig.logsTail, cliConfig.logsFollow, os.Stdout, os.Stderr)
 	default:
 		panic("unrecognized node action: " + cliConfig.nodeAction)
 	}
@@ -49,3 +75,27 @@ func main() {
 		panic(err)
 	}
 }
+
+func printStatus(status *node.Status) {
+	for _, component := range []string{node.EdgelessDBComponent, node.EnclaveComponent, node.HostComponent} {
+		if state, found := status.Containers[component]; found {
+			fmt.Printf("%s container: %s\n", component, state)
+		}
+	}
+	if status.Health != nil {
+		fmt.Printf("Overall health: %t\n", status.Health.OverallHealth)
+		if status.Health.HealthCheckEnclave != nil {
+			fmt.Printf("Enclave health: %t\n", status.Health.EnclaveHealthy)
+		}
+		if status.Health.HealthCheckHost != nil && status.Health.P2PStatus != nil {
+			fmt.Printf("P2P messages received: %d, failed to receive: %d, failed to send: %d\n",
+				status.Health.P2PStatus.ReceivedMessages, status.Health.P2PStatus.FailedReceivedMessages, status.Health.P2PStatus.FailedSendMessage)
+		}
+	}
+	if status.HeadBatch != nil {
+		fmt.Printf("Head batch: %d, produced %s ago\n", *status.HeadBatch, time.Since(status.HeadTime).Round(time.Second))
+	}
+	if status.L1Head != 0 {
+		fmt.Printf("L1 head: %d, head batch is %d blocks behind\n", status.L1Head, status.L1Lag)
+	}
+}
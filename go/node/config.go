@@ -1,5 +1,7 @@
 package node
 
+import "errors"
+
 // Option is a function that applies configs to a Config Object
 type Option = func(c *Config)
 
@@ -17,6 +19,7 @@ type Config struct {
 	hostID                    string
 	hostHTTPPort              int
 	hostWSPort                int
+	hostRPCHost               string
 	enclaveWSPort             int
 	messageBusContractAddress string
 	managementContractAddr    string
@@ -27,6 +30,8 @@ type Config struct {
 	edgelessDBImage           string
 	enclaveDebug              bool
 	nodeName                  string
+	batchIntervalMs           uint64
+	enclaveSignerID           string
 }
 
 func NewNodeConfig(opts ...Option) *Config {
@@ -159,6 +164,14 @@ func WithHostWSPort(i int) Option {
 	}
 }
 
+// WithHostRPCHost sets the address at which the operator CLI reaches the host's RPC to check the node's health. The host
+// publishes its RPC ports on the docker host, so this is the address of the docker host, as seen from the CLI.
+func WithHostRPCHost(s string) Option {
+	return func(c *Config) {
+		c.hostRPCHost = s
+	}
+}
+
 func WithEdgelessDBImage(s string) Option {
 	return func(c *Config) {
 		c.edgelessDBImage = s
@@ -170,3 +183,26 @@ func WithPCCSAddr(s string) Option {
 		c.pccsAddr = s
 	}
 }
+
+func WithBatchIntervalMs(i uint64) Option {
+	return func(c *Config) {
+		c.batchIntervalMs = i
+	}
+}
+
+// WithEnclaveSignerID sets the signing key (MRSIGNER) of the enclave image. On SGX nodes, the host and the enclave only
+// recognise enclaves signed with this key.
+func WithEnclaveSignerID(s string) Option {
+	return func(c *Config) {
+		c.enclaveSignerID = s
+	}
+}
+
+// Checks that the enclaves the node recognises are configured, if the node runs on SGX and so verifies their
+// attestation reports.
+func (c *Config) validateAttestation() error {
+	if c.sgxEnabled && c.enclaveSignerID == "" {
+		return errors.New("the node runs on SGX, so the signing key of the enclave image must be set")
+	}
+	return nil
+}
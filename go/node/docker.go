@@ -1,16 +1,37 @@
 package node
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"path/filepath"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/obscuronet/go-obscuro/go/common/docker"
+	"github.com/obscuronet/go-obscuro/go/common/retry"
+	"github.com/obscuronet/go-obscuro/go/obsclient"
+)
+
+// The components of the node, each of which runs in its own container.
+const (
+	HostComponent       = "host"
+	EnclaveComponent    = "enclave"
+	EdgelessDBComponent = "edgelessdb"
 )
 
 var (
-	_hostDataDir      = "/data"
-	_defaultHostMount = map[string]string{"host-persistence": _hostDataDir}
+	_hostDataDir = "/data"
+	// the enclave keeps its sqlite DB, or its sealed EdgelessDB credentials, in its /data dir, which its enclave.json
+	// mounts from this dir of the container
+	_enclaveDataDir = "/home/obscuro/data"
+
+	// the containers replaced by an upgrade are kept under this suffix until the upgraded node is healthy
+	_rollbackSuffix       = "-rollback"
+	_upgradeHealthTimeout = 5 * time.Minute
+	// the subdir of the backup dir the node is backed up to before an upgrade, and restored from if the upgrade fails
+	_upgradeBackupDir = "pre-upgrade"
 )
 
 type DockerNode struct {
@@ -23,12 +44,32 @@ func NewDockerNode(cfg *Config) (Node, error) {
 	}, nil // todo: add config validation
 }
 
+// Start creates and starts the node's containers. If the node was stopped, its existing containers are started again
+// instead, so that they keep their config and data.
 func (d *DockerNode) Start() error {
+	_, found, err := docker.ContainerState(d.containerName(HostComponent))
+	if err != nil {
+		return err
+	}
+	if found {
+		fmt.Printf("Restarting stopped node %s\n", d.cfg.nodeName)
+		for _, component := range d.components() {
+			if err = d.startExisting(component); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err = d.cfg.validateAttestation(); err != nil {
+		return err
+	}
+
 	// TODO this should probably be removed in the future
 	fmt.Printf("Starting Node %s with config: %+v\n", d.cfg.nodeName, d.cfg)
 
 	// write the network-level config to disk for future restarts
-	err := WriteNetworkConfigToDisk(d.getNetworkConfig())
+	err = WriteNetworkConfigToDisk(d.getNetworkConfig())
 	if err != nil {
 		return err
 	}
@@ -43,7 +84,7 @@ func (d *DockerNode) Start() error {
 		return err
 	}
 
-	err = d.startHost()
+	err = d.startHost(map[string]string{d.hostVolume(): _hostDataDir})
 	if err != nil {
 		return err
 	}
@@ -51,7 +92,36 @@ func (d *DockerNode) Start() error {
 	return nil
 }
 
-func (d *DockerNode) Upgrade() error {
+// Stop stops the node's containers without removing them, so that Start can resume the node.
+func (d *DockerNode) Stop() error {
+	components := d.components()
+	// the host is stopped first, so that it does not see the enclave going down
+	for i := len(components) - 1; i >= 0; i-- {
+		name := d.containerName(components[i])
+		state, found, err := docker.ContainerState(name)
+		if err != nil {
+			return err
+		}
+		if !found || state != "running" {
+			continue
+		}
+		fmt.Printf("Stopping %s\n", name)
+		if err = docker.Stop(name); err != nil {
+			return fmt.Errorf("could not stop %s. Cause: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Upgrade replaces the host and enclave containers with containers running the configured images. The node is backed up
+// into a subdir of the backup dir first. If the upgraded node does not become healthy, it is rolled back to the
+// previous containers, and its host DB is restored from the backup. The enclave data is kept across the upgrade, so a
+// rollback does not undo the changes the upgraded enclave made to it.
+func (d *DockerNode) Upgrade(backupDir string) error {
+	if err := d.cfg.validateAttestation(); err != nil {
+		return err
+	}
+
 	// TODO this should probably be removed in the future
 	fmt.Printf("Upgrading node %s with config: %+v\n", d.cfg.nodeName, d.cfg)
 
@@ -61,38 +131,207 @@ func (d *DockerNode) Upgrade() error {
 		return err
 	}
 	d.updateConfigWithNetworkConfig(networkCfg)
-
-	fmt.Println("Stopping existing host and enclave")
-	err = docker.StopAndRemove(d.cfg.nodeName + "-host")
+	// the upgraded host keeps the volume of the current host
+	hostMount, err := d.hostMount()
 	if err != nil {
 		return err
 	}
 
-	err = docker.StopAndRemove(d.cfg.nodeName + "-enclave")
-	if err != nil {
-		return err
+	fmt.Println("Stopping existing host and enclave")
+	components := []string{HostComponent, EnclaveComponent}
+	for _, component := range components {
+		name := d.containerName(component)
+		// containers left over from a previous upgrade are no longer needed
+		if err = d.removeIfExists(name + _rollbackSuffix); err != nil {
+			return err
+		}
+		if err = docker.Stop(name); err != nil {
+			return fmt.Errorf("could not stop %s. Cause: %w", name, err)
+		}
+	}
+	rollbackDir := filepath.Join(backupDir, _upgradeBackupDir)
+	if err = d.Backup(rollbackDir); err != nil {
+		return fmt.Errorf("could not back up node before upgrade. Cause: %w", err)
+	}
+	for _, component := range components {
+		name := d.containerName(component)
+		if err = docker.Rename(name, name+_rollbackSuffix); err != nil {
+			return fmt.Errorf("could not keep %s for rollback. Cause: %w", name, err)
+		}
 	}
 
 	fmt.Println("Starting upgraded host and enclave")
 	err = d.startEnclave()
+	if err == nil {
+		err = d.startHost(hostMount)
+	}
+	if err == nil {
+		err = d.waitForHealthyNode(_upgradeHealthTimeout)
+	}
 	if err != nil {
-		return err
+		fmt.Printf("Upgrade failed, rolling back to the previous host and enclave. Cause: %s\n", err)
+		if rollbackErr := d.rollback(rollbackDir); rollbackErr != nil {
+			return fmt.Errorf("upgrade failed and could not be rolled back (%s). Cause: %w", rollbackErr, err)
+		}
+		return fmt.Errorf("upgrade failed and was rolled back. Cause: %w", err)
+	}
+
+	for _, component := range []string{HostComponent, EnclaveComponent} {
+		if err = d.removeIfExists(d.containerName(component) + _rollbackSuffix); err != nil {
+			return err
+		}
+	}
+	fmt.Println("Upgrade complete")
+	return nil
+}
+
+// Logs writes the logs of the given component of the node.
+func (d *DockerNode) Logs(component string, tail string, follow bool, stdout, stderr io.Writer) error {
+	for _, c := range d.components() {
+		if c == component {
+			return docker.Logs(d.containerName(component), tail, follow, stdout, stderr)
+		}
 	}
+	return fmt.Errorf("node has no component %s. Components are: %v", component, d.components())
+}
 
-	err = d.startHost()
+// Removes the containers started by the failed upgrade and restores the previous containers, then restores the host DB
+// from the backup taken before the upgrade, which recreates the host container with its previous image.
+func (d *DockerNode) rollback(backupDir string) error {
+	for _, component := range []string{EnclaveComponent, HostComponent} {
+		name := d.containerName(component)
+		if err := d.removeIfExists(name); err != nil {
+			return err
+		}
+		if err := docker.Rename(name+_rollbackSuffix, name); err != nil {
+			return fmt.Errorf("could not restore %s. Cause: %w", name, err)
+		}
+	}
+	hostImage, err := docker.ContainerImage(d.containerName(HostComponent))
+	if err != nil {
+		return fmt.Errorf("could not retrieve image of previous host. Cause: %w", err)
+	}
+	d.cfg.hostImage = hostImage
+	return d.Restore(backupDir)
+}
+
+// Returns the address of the host's HTTP RPC, as published on the docker host.
+func (d *DockerNode) hostRPCAddress() string {
+	host := d.cfg.hostRPCHost
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	return fmt.Sprintf("http://%s:%d", host, d.cfg.hostHTTPPort)
+}
+
+func (d *DockerNode) waitForHealthyNode(timeout time.Duration) error {
+	client, err := obsclient.Dial(d.hostRPCAddress())
+	if err != nil {
+		return fmt.Errorf("could not connect to host. Cause: %w", err)
+	}
+	defer client.Close()
+
+	fmt.Println("Waiting for obscuro node to be healthy...")
+	return retry.Do(
+		func() error {
+			healthy, err := client.Health()
+			if err != nil {
+				return err
+			}
+			if !healthy {
+				return errors.New("node OverallHealth is not good yet")
+			}
+			return nil
+		}, retry.NewTimeoutStrategy(timeout, time.Second),
+	)
+}
+
+// Starts the existing container of the component, if it is not already running.
+func (d *DockerNode) startExisting(component string) error {
+	name := d.containerName(component)
+	state, found, err := docker.ContainerState(name)
 	if err != nil {
 		return err
 	}
+	if !found {
+		return fmt.Errorf("node has no %s container", name)
+	}
+	if state == "running" {
+		return nil
+	}
+	if err = docker.StartContainer(name); err != nil {
+		return fmt.Errorf("could not start %s. Cause: %w", name, err)
+	}
+	return nil
+}
 
+func (d *DockerNode) removeIfExists(name string) error {
+	_, found, err := docker.ContainerState(name)
+	if err != nil || !found {
+		return err
+	}
+	if err = docker.StopAndRemove(name); err != nil {
+		return fmt.Errorf("could not remove %s. Cause: %w", name, err)
+	}
 	return nil
 }
 
-func (d *DockerNode) startHost() error {
+// Returns the components of the node, in the order they are started.
+func (d *DockerNode) components() []string {
+	if d.cfg.sgxEnabled {
+		return []string{EdgelessDBComponent, EnclaveComponent, HostComponent}
+	}
+	// Non-SGX hardware use sqlite database so EdgelessDB is not required.
+	return []string{EnclaveComponent, HostComponent}
+}
+
+func (d *DockerNode) containerName(component string) string {
+	return d.cfg.nodeName + "-" + component
+}
+
+// The enclave's data is kept in a volume, so that it survives upgrades. The volume is named after the node, since
+// several nodes can run on the same docker host.
+func (d *DockerNode) enclaveMount() map[string]string {
+	return map[string]string{d.cfg.nodeName + "-enclave-persistence": _enclaveDataDir}
+}
+
+// The host's DB is kept in a volume named after the node, since several nodes can run on the same docker host.
+func (d *DockerNode) hostVolume() string {
+	return d.cfg.nodeName + "-host-persistence"
+}
+
+// Returns the volume mount of the host's DB. A host created before the volume was named after the node keeps the shared
+// volume it was created with, until the node is restored from a backup.
+func (d *DockerNode) hostMount() (map[string]string, error) {
+	volume, found, err := docker.ContainerVolume(d.containerName(HostComponent), _hostDataDir)
+	if err != nil {
+		return nil, fmt.Errorf("could not inspect host container. Cause: %w", err)
+	}
+	if !found {
+		volume = d.hostVolume()
+	}
+	return map[string]string{volume: _hostDataDir}, nil
+}
+
+func (d *DockerNode) startHost(mount map[string]string) error {
+	cmd, exposedPorts := d.hostContainerCmd()
+	_, err := docker.StartNewContainer(d.containerName(HostComponent), d.cfg.hostImage, cmd, exposedPorts, nil, nil, mount)
+	return err
+}
+
+// Creates the host container without starting it, so that its DB can be restored first.
+func (d *DockerNode) createHost(mount map[string]string) error {
+	cmd, exposedPorts := d.hostContainerCmd()
+	_, err := docker.CreateNewContainer(d.containerName(HostComponent), d.cfg.hostImage, cmd, exposedPorts, nil, nil, mount)
+	return err
+}
+
+func (d *DockerNode) hostContainerCmd() ([]string, []int) {
 	cmd := []string{
 		"/home/obscuro/go-obscuro/go/host/main/main",
 		"-l1NodeHost", d.cfg.l1Host,
 		"-l1NodePort", fmt.Sprintf("%d", d.cfg.l1WSPort),
-		"-enclaveRPCAddress", fmt.Sprintf("%s:%d", d.cfg.nodeName+"-enclave", d.cfg.enclaveWSPort),
+		"-enclaveRPCAddress", fmt.Sprintf("%s:%d", d.containerName(EnclaveComponent), d.cfg.enclaveWSPort),
 		"-managementContractAddress", d.cfg.managementContractAddr,
 		"-privateKey", d.cfg.privateKey,
 		"-clientRPCHost", "0.0.0.0",
@@ -108,6 +347,13 @@ func (d *DockerNode) startHost() error {
 		// host persistence hardcoded to use /data dir within the container, this needs to be mounted
 		"-useInMemoryDB=false",
 		"-levelDBPath", _hostDataDir,
+		"-batchIntervalMs", fmt.Sprintf("%d", d.cfg.batchIntervalMs),
+		"-attestedTLS=true",
+		// the host checks its enclave's attestation report against the same policy as the enclaves
+		fmt.Sprintf("-enclaveWillAttest=%t", d.cfg.sgxEnabled),
+	}
+	if d.cfg.sgxEnabled {
+		cmd = append(cmd, d.attestationPolicyFlags()...)
 	}
 
 	exposedPorts := []int{
@@ -116,9 +362,7 @@ func (d *DockerNode) startHost() error {
 		d.cfg.hostP2PPort,
 	}
 
-	_, err := docker.StartNewContainer(d.cfg.nodeName+"-host", d.cfg.hostImage, cmd, exposedPorts, nil, nil, _defaultHostMount)
-
-	return err
+	return cmd, exposedPorts
 }
 
 func (d *DockerNode) startEnclave() error {
@@ -159,6 +403,8 @@ func (d *DockerNode) startEnclave() error {
 		"-useInMemoryDB=false",
 		"-logPath", "sys_out",
 		"-logLevel", fmt.Sprintf("%d", log.LvlInfo),
+		fmt.Sprintf("-timeBasedBatches=%t", d.cfg.batchIntervalMs > 0),
+		"-attestedTLS=true",
 	)
 
 	if d.cfg.sgxEnabled {
@@ -170,19 +416,29 @@ func (d *DockerNode) startEnclave() error {
 		// prepend the entry.sh execution
 		cmd = append([]string{"/home/obscuro/go-obscuro/go/enclave/main/entry.sh"}, cmd...)
 		cmd = append(cmd,
-			"-edgelessDBHost", d.cfg.nodeName+"-edgelessdb",
+			"-edgelessDBHost", d.containerName(EdgelessDBComponent),
 			"-willAttest=true",
 		)
+		cmd = append(cmd, d.attestationPolicyFlags()...)
 	} else {
 		cmd = append(cmd,
 			"-sqliteDBPath", "/data/sqlite.db",
 		)
 	}
 
-	_, err := docker.StartNewContainer(d.cfg.nodeName+"-enclave", d.cfg.enclaveImage, cmd, exposedPorts, envs, devices, nil)
+	_, err := docker.StartNewContainer(d.containerName(EnclaveComponent), d.cfg.enclaveImage, cmd, exposedPorts, envs, devices, d.enclaveMount())
 	return err
 }
 
+// Returns the flags setting the attestation policy of the host and the enclave, which recognise the enclaves signed with
+// the same key as the node's enclave image.
+func (d *DockerNode) attestationPolicyFlags() []string {
+	return []string{
+		"-attestationSignerIDs", d.cfg.enclaveSignerID,
+		"-attestationAllowDebug=true", // todo: the enclave is signed in debug mode until production release
+	}
+}
+
 func (d *DockerNode) startEdgelessDB() error {
 	if !d.cfg.sgxEnabled {
 		// Non-SGX hardware use sqlite database so EdgelessDB is not required.
@@ -190,7 +446,7 @@ func (d *DockerNode) startEdgelessDB() error {
 	}
 
 	envs := map[string]string{
-		"EDG_EDB_CERT_DNS": d.cfg.nodeName + "-edgelessdb",
+		"EDG_EDB_CERT_DNS": d.containerName(EdgelessDBComponent),
 	}
 
 	devices := map[string]string{
@@ -203,7 +459,7 @@ func (d *DockerNode) startEdgelessDB() error {
 		envs["PCCS_ADDR"] = d.cfg.pccsAddr
 	}
 
-	_, err := docker.StartNewContainer(d.cfg.nodeName+"-edgelessdb", d.cfg.edgelessDBImage, nil, nil, envs, devices, nil)
+	_, err := docker.StartNewContainer(d.containerName(EdgelessDBComponent), d.cfg.edgelessDBImage, nil, nil, envs, devices, nil)
 
 	return err
 }
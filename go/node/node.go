@@ -1,6 +1,13 @@
 package node
 
+import "io"
+
 type Node interface {
 	Start() error
-	Upgrade() error
+	Stop() error
+	Upgrade(backupDir string) error
+	Status() (*Status, error)
+	Backup(dir string) error
+	Restore(dir string) error
+	Logs(component string, tail string, follow bool, stdout, stderr io.Writer) error
 }
@@ -0,0 +1,87 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/obscuronet/go-obscuro/go/common/docker"
+	"github.com/obscuronet/go-obscuro/go/obsclient"
+
+	hostcommon "github.com/obscuronet/go-obscuro/go/common/host"
+)
+
+const _statusTimeout = 10 * time.Second
+
+// Status is the state of the node's containers, and the health and sync progress the node reports.
+type Status struct {
+	Containers map[string]string       // The state of the container of each component (e.g. running, exited), by component
+	Health     *hostcommon.HealthCheck // Nil if the host is not running
+	HeadBatch  *uint64                 // Nil if the node has not produced or received a batch yet
+	HeadTime   time.Time               // The time of the head batch
+	L1Head     uint64                  // The number of the head block of the L1
+	L1Lag      uint64                  // How many L1 blocks the head batch is behind the L1 head
+}
+
+// Status returns the status of the node. If the node or the L1 cannot be queried, the status gathered so far is returned
+// along with the error.
+func (d *DockerNode) Status() (*Status, error) {
+	status := &Status{Containers: map[string]string{}}
+	for _, component := range d.components() {
+		state, found, err := docker.ContainerState(d.containerName(component))
+		if err != nil {
+			return status, fmt.Errorf("could not inspect %s container. Cause: %w", component, err)
+		}
+		if !found {
+			state = "not created"
+		}
+		status.Containers[component] = state
+	}
+	if status.Containers[HostComponent] != "running" {
+		return status, nil
+	}
+
+	client, err := obsclient.Dial(d.hostRPCAddress())
+	if err != nil {
+		return status, fmt.Errorf("could not connect to host. Cause: %w", err)
+	}
+	defer client.Close()
+
+	status.Health, err = client.HealthCheck()
+	if err != nil {
+		return status, fmt.Errorf("could not retrieve node health. Cause: %w", err)
+	}
+	head, err := client.RollupHeaderByNumber(nil)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return status, nil
+		}
+		return status, fmt.Errorf("could not retrieve head batch. Cause: %w", err)
+	}
+	headNumber := head.Number.Uint64()
+	status.HeadBatch = &headNumber
+	status.HeadTime = time.Unix(int64(head.Time), 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), _statusTimeout)
+	defer cancel()
+	l1Client, err := ethclient.DialContext(ctx, fmt.Sprintf("ws://%s:%d", d.cfg.l1Host, d.cfg.l1WSPort))
+	if err != nil {
+		return status, fmt.Errorf("could not connect to L1. Cause: %w", err)
+	}
+	defer l1Client.Close()
+
+	if status.L1Head, err = l1Client.BlockNumber(ctx); err != nil {
+		return status, fmt.Errorf("could not retrieve L1 head. Cause: %w", err)
+	}
+	l1Proof, err := l1Client.HeaderByHash(ctx, head.L1Proof)
+	if err != nil {
+		return status, fmt.Errorf("could not retrieve L1 block %s of head batch. Cause: %w", head.L1Proof, err)
+	}
+	if l1Proof.Number.Uint64() < status.L1Head {
+		status.L1Lag = status.L1Head - l1Proof.Number.Uint64()
+	}
+	return status, nil
+}
@@ -52,12 +52,14 @@ func NewAuthObsClient(client *rpc.EncRPCClient) *AuthObsClient {
 // DialWithAuth will generate and sign a viewing key for given wallet, then initiate a connection with the RPC node and
 //
 //	register the viewing key
-func DialWithAuth(rpcurl string, wal wallet.Wallet, logger gethlog.Logger) (*AuthObsClient, error) {
+//
+// The enclave public key is retrieved and verified using the given key fetcher, which may be shared between clients.
+func DialWithAuth(rpcurl string, wal wallet.Wallet, keyFetcher *rpc.EnclaveKeyFetcher, logger gethlog.Logger) (*AuthObsClient, error) {
 	viewingKey, err := rpc.GenerateAndSignViewingKey(wal)
 	if err != nil {
 		return nil, err
 	}
-	encClient, err := rpc.NewEncNetworkClient(rpcurl, viewingKey, logger)
+	encClient, err := rpc.NewEncNetworkClient(rpcurl, viewingKey, keyFetcher, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -78,6 +80,34 @@ func (ac *AuthObsClient) TransactionReceipt(ctx context.Context, txHash gethcomm
 	return &receipt, err
 }
 
+// WithdrawalProofs returns the cross-chain messages published by the transaction, with their progress towards finality
+// on the L1 and the call data to check they are final on the L1 message bus before acting on them
+func (ac *AuthObsClient) WithdrawalProofs(ctx context.Context, txHash gethcommon.Hash) ([]*common.WithdrawalProof, error) {
+	var proofs []*common.WithdrawalProof
+	err := ac.rpcClient.CallContext(ctx, &proofs, rpc.GetWithdrawalProofs, txHash)
+	return proofs, err
+}
+
+// InboundMessageRecords returns the node's audit records of the cross-chain messages sent from the L1 by the sender, or
+// only of the message with the given sequence number if it is not nil. Only the node's operator can decrypt them
+func (ac *AuthObsClient) InboundMessageRecords(ctx context.Context, sender gethcommon.Address, sequence *uint64) ([]*common.InboundMessageRecord, error) {
+	params := []interface{}{sender}
+	if sequence != nil {
+		params = append(params, hexutil.Uint64(*sequence))
+	}
+	var records []*common.InboundMessageRecord
+	err := ac.rpcClient.CallContext(ctx, &records, rpc.GetInboundMessages, params...)
+	return records, err
+}
+
+// PrivateStorageAt returns the value of a storage slot of the contract, if the contract authorises the account registered
+// on this client to read it
+func (ac *AuthObsClient) PrivateStorageAt(ctx context.Context, contract gethcommon.Address, slot gethcommon.Hash, blockNumber *big.Int) (gethcommon.Hash, error) {
+	var value gethcommon.Hash
+	err := ac.rpcClient.CallContext(ctx, &value, rpc.GetPrivateStorageAt, contract, slot, toBlockNumArg(blockNumber), ac.account)
+	return value, err
+}
+
 // NonceAt retrieves the nonce for the account registered on this client (due to obscuro privacy restrictions,
 // nonce cannot be requested for other accounts)
 func (ac *AuthObsClient) NonceAt(ctx context.Context, blockNumber *big.Int) (uint64, error) {
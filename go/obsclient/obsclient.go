@@ -74,9 +74,45 @@ func (oc *ObsClient) RollupHeaderByHash(hash gethcommon.Hash) (*common.BatchHead
 	return batchHeader, err
 }
 
+// StorageAt returns the value of the storage slot of the contract, provided the contract declares the slot public.
+func (oc *ObsClient) StorageAt(contract gethcommon.Address, slot gethcommon.Hash, blockNumber *big.Int) (gethcommon.Hash, error) {
+	var value hexutil.Bytes
+	err := oc.rpcClient.Call(&value, rpc.GetStorageAt, contract, slot, toBlockNumArg(blockNumber))
+	return gethcommon.BytesToHash(value), err
+}
+
+// DivergenceReports returns the signed reports of the batches whose re-execution by the node diverged from the batches
+// signed by the sequencer.
+func (oc *ObsClient) DivergenceReports() ([]*common.DivergenceReport, error) {
+	var reports []*common.DivergenceReport
+	err := oc.rpcClient.Call(&reports, rpc.GetDivergenceReports)
+	return reports, err
+}
+
+// ExportSnapshot returns the encrypted snapshot of the node's state at the batch with the given number, which can be
+// used to bootstrap a new node (see the host's `snapshotPath` flag). It is part of the admin API, so the client must
+// be connected to the host's admin IPC endpoint (see rpc.NewIPCClient).
+func (oc *ObsClient) ExportSnapshot(batchNumber uint64) (common.EncryptedSnapshot, error) {
+	var snapshot hexutil.Bytes
+	err := oc.rpcClient.Call(&snapshot, rpc.ExportSnapshot, hexutil.Uint64(batchNumber))
+	return common.EncryptedSnapshot(snapshot), err
+}
+
 // Health returns the health of the node.
 func (oc *ObsClient) Health() (bool, error) {
-	var healthy *hostcommon.HealthCheck
-	err := oc.rpcClient.Call(&healthy, rpc.Health)
-	return healthy.OverallHealth, err
+	healthCheck, err := oc.HealthCheck()
+	if err != nil {
+		return false, err
+	}
+	return healthCheck.OverallHealth, nil
+}
+
+// HealthCheck returns the health of the node's host and enclave.
+func (oc *ObsClient) HealthCheck() (*hostcommon.HealthCheck, error) {
+	var healthCheck *hostcommon.HealthCheck
+	err := oc.rpcClient.Call(&healthCheck, rpc.Health)
+	if err == nil && healthCheck == nil {
+		err = ethereum.NotFound
+	}
+	return healthCheck, err
 }
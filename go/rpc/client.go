@@ -15,6 +15,7 @@ const (
 	GetRollupByHash       = "eth_getBlockByHash"
 	GetRollupByNumber     = "eth_getBlockByNumber"
 	GetCode               = "eth_getCode"
+	GetStorageAt          = "eth_getStorageAt"
 	GetTransactionByHash  = "eth_getTransactionByHash"
 	GetTransactionCount   = "eth_getTransactionCount"
 	GetTransactionReceipt = "eth_getTransactionReceipt"
@@ -23,11 +24,19 @@ const (
 	GetLogs               = "eth_getLogs"
 	AddViewingKey         = "obscuro_addViewingKey"
 	Health                = "obscuro_health"
+	GetWithdrawalProofs   = "obscuro_getWithdrawalProofs"
+	GetInboundMessages    = "obscuro_getInboundMessageRecords"
+	GetPrivateStorageAt   = "obscuro_getStorageAt"
+	GetDivergenceReports  = "obscuro_getDivergenceReports"
+	ExportSnapshot        = "admin_exportSnapshot"
+	DebugTraceTransaction = "debug_traceTransaction"
+	DebugTraceCall        = "debug_traceCall"
 	GetBlockHeaderByHash  = "obscuroscan_getBlockHeaderByHash"
 	GetBatch              = "obscuroscan_getBatch"
 	GetBatchForTx         = "obscuroscan_getBatchForTx"
 	GetLatestTxs          = "obscuroscan_getLatestTransactions"
 	GetTotalTxs           = "obscuroscan_getTotalTransactions"
+	GetInboundDelivery    = "obscuroscan_getInboundMessageDelivery"
 	Attestation           = "obscuroscan_attestation"
 	StopHost              = "test_stopHost"
 	Subscribe             = "eth_subscribe"
@@ -0,0 +1,195 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/obscuronet/go-obscuro/go/common"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+// ReportVerifier checks the signature of a raw attestation report and returns its contents.
+type ReportVerifier func(report []byte) (attestation.Report, error)
+
+// AttestationConfig sets how the attestation report of a node's enclave is verified before the client trusts the
+// enclave public key contained in it.
+type AttestationConfig struct {
+	Policy common.AttestationPolicy // the policy the enclave's attestation report must satisfy
+	// Whether to trust the enclave public key without verifying the attestation at all. Only appropriate for dev networks
+	// that run without SGX.
+	InsecureSkipVerify bool
+}
+
+// Validate checks that the policy pins the enclave's code, either by its UniqueID or by its SignerID and ProductID, so
+// that a fetcher built from it fails closed.
+func (c AttestationConfig) Validate() error {
+	if c.InsecureSkipVerify {
+		return nil
+	}
+	if len(c.Policy.UniqueIDs) == 0 && (len(c.Policy.SignerIDs) == 0 || c.Policy.ProductID == 0) {
+		return errors.New("attestation policy must recognise either an enclave unique ID, or an enclave signer ID and product ID")
+	}
+	return nil
+}
+
+// ParseAttestationPolicy returns the attestation policy that recognises the hex-encoded enclave unique IDs (MRENCLAVE
+// values) and signer IDs (MRSIGNER values), and that accepts the named TCB statuses (e.g. SWHardeningNeeded) in
+// addition to an up-to-date TCB. Empty IDs and statuses are ignored, so that the values of unset flags can be passed.
+func ParseAttestationPolicy(uniqueIDs, signerIDs []string, productID uint16, minSecurityVersion uint64, allowDebug bool, tcbStatuses []string) (common.AttestationPolicy, error) {
+	parsedUniqueIDs, err := parseEnclaveIDs(uniqueIDs)
+	if err != nil {
+		return common.AttestationPolicy{}, fmt.Errorf("could not decode enclave unique ID. Cause: %w", err)
+	}
+	parsedSignerIDs, err := parseEnclaveIDs(signerIDs)
+	if err != nil {
+		return common.AttestationPolicy{}, fmt.Errorf("could not decode enclave signer ID. Cause: %w", err)
+	}
+	parsedTCBStatuses, err := common.ParseTCBStatuses(nonEmpty(tcbStatuses))
+	if err != nil {
+		return common.AttestationPolicy{}, err
+	}
+	return common.AttestationPolicy{
+		UniqueIDs:          parsedUniqueIDs,
+		SignerIDs:          parsedSignerIDs,
+		MinSecurityVersion: minSecurityVersion,
+		ProductID:          productID,
+		AllowDebug:         allowDebug,
+		AllowedTCBStatuses: parsedTCBStatuses,
+	}, nil
+}
+
+// EnclaveKeyFetcher retrieves the public key that the enclave uses for encrypted RPC communication from a node's
+// attestation endpoint. It verifies the attestation report before trusting the key, and caches the verified key so that
+// clients connecting to the same network only go through verification once.
+type EnclaveKeyFetcher struct {
+	verifier          ReportVerifier
+	attestationConfig AttestationConfig
+	cachedKey         *ecies.PublicKey
+	lock              sync.Mutex
+	logger            gethlog.Logger
+}
+
+// NewEnclaveKeyFetcher returns an EnclaveKeyFetcher that only trusts keys attested by an enclave matching the
+// attestation config's policy. If the verifier is nil, VerifyRemoteReport is used. Policies that do not pin the
+// enclave's code are rejected, unless the config explicitly skips verification.
+func NewEnclaveKeyFetcher(verifier ReportVerifier, attestationConfig AttestationConfig, logger gethlog.Logger) (*EnclaveKeyFetcher, error) {
+	if err := attestationConfig.Validate(); err != nil {
+		return nil, err
+	}
+	if verifier == nil {
+		verifier = VerifyRemoteReport
+	}
+	if attestationConfig.InsecureSkipVerify {
+		logger.Warn("Attestation verification is disabled. The enclave public key will be trusted without verification.")
+	}
+	return &EnclaveKeyFetcher{
+		verifier:          verifier,
+		attestationConfig: attestationConfig,
+		logger:            logger,
+	}, nil
+}
+
+// NewInsecureEnclaveKeyFetcher returns an EnclaveKeyFetcher that trusts the key as returned by the node, for tests and
+// dev networks that run without attestation.
+func NewInsecureEnclaveKeyFetcher(logger gethlog.Logger) *EnclaveKeyFetcher {
+	return &EnclaveKeyFetcher{
+		attestationConfig: AttestationConfig{InsecureSkipVerify: true},
+		logger:            logger,
+	}
+}
+
+// EnclavePublicKey returns the enclave's public key, requesting and verifying the attestation via the client if the key
+// has not been cached yet.
+func (f *EnclaveKeyFetcher) EnclavePublicKey(client Client) (*ecies.PublicKey, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.cachedKey != nil {
+		return f.cachedKey, nil
+	}
+
+	var att common.AttestationReport
+	err := client.Call(&att, Attestation)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve attestation report from node. Cause: %w", err)
+	}
+
+	if err = f.verifyReport(&att); err != nil {
+		return nil, fmt.Errorf("could not verify attestation report. Cause: %w", err)
+	}
+
+	enclPubECDSA, err := crypto.DecompressPubkey(att.RPCPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress enclave public key from attestation report. Cause: %w", err)
+	}
+	f.cachedKey = ecies.ImportECDSAPublic(enclPubECDSA)
+	return f.cachedKey, nil
+}
+
+// ClearCache discards the cached key, forcing the attestation to be fetched and verified again on the next request.
+func (f *EnclaveKeyFetcher) ClearCache() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.cachedKey = nil
+}
+
+func (f *EnclaveKeyFetcher) verifyReport(att *common.AttestationReport) error {
+	if f.attestationConfig.InsecureSkipVerify {
+		return nil
+	}
+
+	report, err := f.verifier(att.Report)
+	// A report whose TCB level is not up-to-date is still returned, so that the policy can decide whether to accept it.
+	if err != nil && !errors.Is(err, attestation.ErrTCBLevelInvalid) {
+		return err
+	}
+
+	// we check the keys in the report were those attested by the enclave
+	expectedIDHash, err := att.IDHash()
+	if err != nil {
+		return err
+	}
+	if len(report.Data) < len(expectedIDHash) || !bytes.Equal(expectedIDHash, report.Data[:len(expectedIDHash)]) {
+		return errors.New("report data does not match the attested enclave keys")
+	}
+
+	if err = f.attestationConfig.Policy.Check(report); err != nil {
+		return fmt.Errorf("attestation report does not satisfy the attestation policy. Cause: %w", err)
+	}
+	return nil
+}
+
+// Decodes the hex-encoded enclave IDs, with or without the 0x prefix.
+func parseEnclaveIDs(ids []string) ([]gethcommon.Hash, error) {
+	var hashes []gethcommon.Hash
+	for _, id := range nonEmpty(ids) {
+		decoded, err := hex.DecodeString(strings.TrimPrefix(id, "0x"))
+		if err != nil {
+			return nil, err
+		}
+		if len(decoded) != gethcommon.HashLength {
+			return nil, fmt.Errorf("enclave ID %s is not %d bytes long", id, gethcommon.HashLength)
+		}
+		hashes = append(hashes, gethcommon.BytesToHash(decoded))
+	}
+	return hashes, nil
+}
+
+func nonEmpty(values []string) []string {
+	var nonEmptyValues []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			nonEmptyValues = append(nonEmptyValues, value)
+		}
+	}
+	return nonEmptyValues
+}
@@ -0,0 +1,163 @@
+package rpc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/edgelesssys/ego/attestation/tcbstatus"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/log"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	testUniqueID = gethcommon.HexToHash("0x01")
+	testSignerID = gethcommon.HexToHash("0x02")
+)
+
+func TestVerifyReportAcceptsMatchingEnclave(t *testing.T) {
+	att, report := newTestAttestation(t)
+
+	for _, policy := range []common.AttestationPolicy{
+		{UniqueIDs: []gethcommon.Hash{testUniqueID}},
+		{SignerIDs: []gethcommon.Hash{testSignerID}, ProductID: 1, MinSecurityVersion: 2},
+	} {
+		fetcher := newTestFetcher(t, policy, report, nil)
+		if err := fetcher.verifyReport(att); err != nil {
+			t.Fatalf("expected report to be accepted with policy %+v. Cause: %s", policy, err)
+		}
+	}
+}
+
+func TestVerifyReportRejectsWrongMeasurement(t *testing.T) {
+	att, report := newTestAttestation(t)
+	otherID := gethcommon.HexToHash("0x03")
+
+	for _, policy := range []common.AttestationPolicy{
+		{UniqueIDs: []gethcommon.Hash{otherID}},
+		{SignerIDs: []gethcommon.Hash{otherID}, ProductID: 1},
+		{SignerIDs: []gethcommon.Hash{testSignerID}, ProductID: 2},
+		{SignerIDs: []gethcommon.Hash{testSignerID}, ProductID: 1, MinSecurityVersion: 3},
+	} {
+		fetcher := newTestFetcher(t, policy, report, nil)
+		if err := fetcher.verifyReport(att); err == nil {
+			t.Fatalf("expected report to be rejected with policy %+v", policy)
+		}
+	}
+}
+
+func TestVerifyReportRejectsBadReport(t *testing.T) {
+	att, report := newTestAttestation(t)
+	policy := common.AttestationPolicy{UniqueIDs: []gethcommon.Hash{testUniqueID}}
+
+	fetcher := newTestFetcher(t, policy, report, errors.New("invalid signature"))
+	if err := fetcher.verifyReport(att); err == nil {
+		t.Fatal("expected report with an invalid signature to be rejected")
+	}
+
+	// the report must attest to the enclave's keys, so a node cannot swap in its own RPC key
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("could not generate key. Cause: %s", err)
+	}
+	swappedKeyAtt := *att
+	swappedKeyAtt.RPCPubKey = crypto.CompressPubkey(&otherKey.PublicKey)
+	fetcher = newTestFetcher(t, policy, report, nil)
+	if err = fetcher.verifyReport(&swappedKeyAtt); err == nil {
+		t.Fatal("expected report that does not match the attested keys to be rejected")
+	}
+}
+
+func TestVerifyReportOnlyAcceptsAllowedTCBStatuses(t *testing.T) {
+	att, report := newTestAttestation(t)
+	report.TCBStatus = tcbstatus.OutOfDate
+	policy := common.AttestationPolicy{UniqueIDs: []gethcommon.Hash{testUniqueID}}
+
+	// the verifier returns a report whose TCB level is not up-to-date along with an error
+	fetcher := newTestFetcher(t, policy, report, attestation.ErrTCBLevelInvalid)
+	if err := fetcher.verifyReport(att); err == nil {
+		t.Fatal("expected report from an out-of-date platform to be rejected")
+	}
+	policy.AllowedTCBStatuses = []tcbstatus.Status{tcbstatus.OutOfDate}
+	fetcher = newTestFetcher(t, policy, report, attestation.ErrTCBLevelInvalid)
+	if err := fetcher.verifyReport(att); err != nil {
+		t.Fatalf("expected report from an out-of-date platform to be accepted when allowed. Cause: %s", err)
+	}
+}
+
+func TestNewEnclaveKeyFetcherFailsClosed(t *testing.T) {
+	for _, policy := range []common.AttestationPolicy{
+		{},
+		{SignerIDs: []gethcommon.Hash{testSignerID}},
+		{ProductID: 1},
+	} {
+		if _, err := NewEnclaveKeyFetcher(nil, AttestationConfig{Policy: policy}, testLogger()); err == nil {
+			t.Fatalf("expected policy %+v to be rejected", policy)
+		}
+	}
+	if _, err := NewEnclaveKeyFetcher(nil, AttestationConfig{InsecureSkipVerify: true}, testLogger()); err != nil {
+		t.Fatalf("expected verification to be skipped when explicitly requested. Cause: %s", err)
+	}
+}
+
+func TestParseAttestationPolicy(t *testing.T) {
+	policy, err := ParseAttestationPolicy([]string{""}, []string{testSignerID.Hex(), " "}, 1, 2, true, []string{"SWHardeningNeeded", ""})
+	if err != nil {
+		t.Fatalf("could not parse attestation policy. Cause: %s", err)
+	}
+	if len(policy.UniqueIDs) != 0 || len(policy.SignerIDs) != 1 || policy.SignerIDs[0] != testSignerID ||
+		len(policy.AllowedTCBStatuses) != 1 || policy.AllowedTCBStatuses[0] != tcbstatus.SWHardeningNeeded {
+		t.Fatalf("unexpected attestation policy %+v", policy)
+	}
+	for _, uniqueID := range []string{"not hex", "0x01"} {
+		if _, err = ParseAttestationPolicy([]string{uniqueID}, nil, 0, 0, false, nil); err == nil {
+			t.Fatalf("expected unique ID %s to be rejected", uniqueID)
+		}
+	}
+	if _, err = ParseAttestationPolicy(nil, nil, 0, 0, false, []string{"NotAStatus"}); err == nil {
+		t.Fatal("expected unknown TCB status to be rejected")
+	}
+}
+
+// Returns an attestation for a random RPC key, and the report that a verifier returns for it.
+func newTestAttestation(t *testing.T) (*common.AttestationReport, attestation.Report) {
+	rpcKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("could not generate key. Cause: %s", err)
+	}
+	att := &common.AttestationReport{
+		Report:    []byte("report"),
+		Owner:     gethcommon.HexToAddress("0x04"),
+		RPCPubKey: crypto.CompressPubkey(&rpcKey.PublicKey),
+	}
+	idHash, err := att.IDHash()
+	if err != nil {
+		t.Fatalf("could not hash attestation. Cause: %s", err)
+	}
+	return att, attestation.Report{
+		Data:            append(idHash, make([]byte, 32)...),
+		SecurityVersion: 2,
+		UniqueID:        testUniqueID.Bytes(),
+		SignerID:        testSignerID.Bytes(),
+		ProductID:       []byte{1, 0},
+	}
+}
+
+func newTestFetcher(t *testing.T, policy common.AttestationPolicy, report attestation.Report, verifierErr error) *EnclaveKeyFetcher {
+	verifier := func([]byte) (attestation.Report, error) {
+		return report, verifierErr
+	}
+	fetcher, err := NewEnclaveKeyFetcher(verifier, AttestationConfig{Policy: policy}, testLogger())
+	if err != nil {
+		t.Fatalf("could not create key fetcher. Cause: %s", err)
+	}
+	return fetcher
+}
+
+func testLogger() gethlog.Logger {
+	return log.New(log.TestLogCmp, int(gethlog.LvlError), log.SysOut)
+}
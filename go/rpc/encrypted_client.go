@@ -26,11 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto/ecies"
 )
 
-const (
-	// todo: this is a convenience for testnet testing and will eventually be retrieved from the L1
-	enclavePublicKeyHex = "034d3b7e63a8bcd532ee3d1d6ecad9d67fca7821981a044551f0f0cbec74d0bc5e"
-	emptyFilterCriteria = "[]" // This is the value that gets passed for an empty filter criteria.
-)
+const emptyFilterCriteria = "[]" // This is the value that gets passed for an empty filter criteria.
 
 // SensitiveMethods for which the RPC requests and responses should be encrypted
 var SensitiveMethods = []string{
@@ -43,6 +39,11 @@ var SensitiveMethods = []string{
 	Subscribe,
 	EstimateGas,
 	GetLogs,
+	GetWithdrawalProofs,
+	GetInboundMessages,
+	GetPrivateStorageAt,
+	DebugTraceTransaction,
+	DebugTraceCall,
 }
 
 // EncRPCClient is a Client wrapper that implements Client but also has extra functionality for managing viewing key registration and decryption
@@ -54,13 +55,12 @@ type EncRPCClient struct {
 }
 
 // NewEncRPCClient sets up a client with a viewing key for encrypted communication (this submits the VK to the enclave)
-func NewEncRPCClient(client Client, viewingKey *ViewingKey, logger gethlog.Logger) (*EncRPCClient, error) {
-	// todo: this is a convenience for testnet but needs to replaced by a parameter and/or retrieved from the target host
-	enclPubECDSA, err := crypto.DecompressPubkey(gethcommon.Hex2Bytes(enclavePublicKeyHex))
+// The enclave public key is retrieved from the node's attestation and verified by the key fetcher.
+func NewEncRPCClient(client Client, viewingKey *ViewingKey, keyFetcher *EnclaveKeyFetcher, logger gethlog.Logger) (*EncRPCClient, error) {
+	enclavePublicKey, err := keyFetcher.EnclavePublicKey(client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decompress key for RPC client: %w", err)
+		return nil, fmt.Errorf("failed to retrieve enclave public key for RPC client: %w", err)
 	}
-	enclavePublicKey := ecies.ImportECDSAPublic(enclPubECDSA)
 
 	encClient := &EncRPCClient{
 		obscuroClient:    client,
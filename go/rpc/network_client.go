@@ -21,18 +21,32 @@ type networkClient struct {
 }
 
 // NewEncNetworkClient returns a network RPC client with Viewing Key encryption/decryption
-func NewEncNetworkClient(rpcAddress string, viewingKey *ViewingKey, logger gethlog.Logger) (*EncRPCClient, error) {
+func NewEncNetworkClient(rpcAddress string, viewingKey *ViewingKey, keyFetcher *EnclaveKeyFetcher, logger gethlog.Logger) (*EncRPCClient, error) {
 	rpcClient, err := NewNetworkClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
-	encClient, err := NewEncRPCClient(rpcClient, viewingKey, logger)
+	encClient, err := NewEncRPCClient(rpcClient, viewingKey, keyFetcher, logger)
 	if err != nil {
+		rpcClient.Stop()
 		return nil, err
 	}
 	return encClient, nil
 }
 
+// NewIPCClient returns a client that can make RPC calls to an Obscuro node over the IPC endpoint at the given path, which
+// also serves the node's admin API
+func NewIPCClient(path string) (Client, error) {
+	rpcClient, err := rpc.DialIPC(context.Background(), path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create RPC client on %s. Cause: %w", path, err)
+	}
+
+	return &networkClient{
+		rpcClient: rpcClient,
+	}, nil
+}
+
 // NewNetworkClient returns a client that can make RPC calls to an Obscuro node
 func NewNetworkClient(address string) (Client, error) {
 	if !strings.HasPrefix(address, http) && !strings.HasPrefix(address, ws) {
@@ -0,0 +1,14 @@
+//go:build hostattestation
+
+package rpc
+
+import (
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/edgelesssys/ego/eclient"
+)
+
+// VerifyRemoteReport is the default ReportVerifier. It checks the report's signature against the SGX quoting
+// infrastructure from outside an enclave, which requires DCAP to be set up on the client's machine.
+func VerifyRemoteReport(report []byte) (attestation.Report, error) {
+	return eclient.VerifyRemoteReport(report)
+}
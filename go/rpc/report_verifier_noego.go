@@ -0,0 +1,15 @@
+//go:build !hostattestation
+
+package rpc
+
+import (
+	"errors"
+
+	"github.com/edgelesssys/ego/attestation"
+)
+
+// VerifyRemoteReport is the default ReportVerifier. Verifying an SGX report outside an enclave requires the Open Enclave
+// host verification libraries, which clients are only linked against when built with the `hostattestation` tag.
+func VerifyRemoteReport([]byte) (attestation.Report, error) {
+	return attestation.Report{}, errors.New("the client was built without attestation verification; rebuild it with `-tags hostattestation`")
+}
@@ -0,0 +1,106 @@
+package attestedtls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/go/common/rpc"
+	"github.com/obscuronet/go-obscuro/go/common/rpc/generated"
+	"github.com/obscuronet/go-obscuro/go/config"
+	"github.com/obscuronet/go-obscuro/go/host/rpc/enclaverpc"
+	"github.com/obscuronet/go-obscuro/integration"
+	"github.com/obscuronet/go-obscuro/integration/common/testlog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	gethlog "github.com/ethereum/go-ethereum/log"
+	enclavecontainer "github.com/obscuronet/go-obscuro/go/enclave/container"
+)
+
+const _testLogs = "../.build/attestedtls/"
+
+// Checks that a host and its enclave, wired up as in a node, connect over attested TLS, and that another host cannot
+// connect to the enclave.
+func TestHostConnectsToItsEnclaveOverAttestedTLS(t *testing.T) {
+	testlog.Setup(&testlog.Cfg{
+		LogDir:      _testLogs,
+		TestType:    "attestedtls",
+		TestSubtype: "test",
+		LogLevel:    gethlog.LvlInfo,
+	})
+
+	hostKey := generateKey(t)
+	hostID := crypto.PubkeyToAddress(hostKey.PublicKey)
+	enclaveAddr := fmt.Sprintf("127.0.0.1:%d", integration.StartPortAttestedTLSTest+integration.DefaultEnclaveOffset)
+
+	enclaveConfig := config.DefaultEnclaveConfig()
+	enclaveConfig.HostID = hostID
+	enclaveConfig.Address = enclaveAddr
+	enclaveConfig.LogPath = testlog.LogFile()
+	enclaveConfig.AttestedTLS = true
+	enclaveContainer := enclavecontainer.NewEnclaveContainerWithLogger(enclaveConfig, testlog.Logger().New(log.CmpKey, log.EnclaveCmp))
+	if err := enclaveContainer.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer enclaveContainer.Stop() //nolint:errcheck
+
+	hostConfig := &config.HostConfig{
+		ID:                hostID,
+		PrivateKeyString:  hex.EncodeToString(crypto.FromECDSA(hostKey)),
+		EnclaveRPCAddress: enclaveAddr,
+		EnclaveRPCTimeout: 10 * time.Second,
+		AttestedTLS:       true,
+	}
+	enclaveClient := enclaverpc.NewClient(hostConfig, testlog.Logger().New(log.CmpKey, log.HostCmp))
+	defer enclaveClient.StopClient() //nolint:errcheck
+
+	if _, err := enclaveClient.Status(); err != nil {
+		t.Fatalf("host could not call its enclave over attested TLS. Cause: %s", err)
+	}
+	attestation, err := enclaveClient.Attestation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attestation.Owner != hostID {
+		t.Fatalf("expected enclave to be attested for host %s, got %s", hostID, attestation.Owner)
+	}
+
+	if err = callStatus(enclaveAddr, generateKey(t)); err == nil {
+		t.Fatal("expected another host not to be able to call the enclave")
+	}
+}
+
+// Calls the enclave's status over attested TLS as the host with the given key.
+func callStatus(enclaveAddr string, hostKey *ecdsa.PrivateKey) error {
+	tlsConfig, err := rpc.NewHostTLSConfig(hostKey, crypto.PubkeyToAddress(hostKey.PublicKey), func(att *common.AttestationReport) ([]byte, error) {
+		return att.IDHash()
+	})
+	if err != nil {
+		return err
+	}
+	connection, err := grpc.Dial(enclaveAddr, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = generated.NewEnclaveProtoClient(connection).Status(ctx, &generated.StatusRequest{})
+	return err
+}
+
+func generateKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
@@ -60,6 +60,7 @@ func TestCanDeployLayer2ERC20Contract(t *testing.T) {
 		ChainID:           big.NewInt(integration.ObscuroChainID),
 		ContractName:      contractdeployer.Layer2Erc20Contract,
 		ConstructorParams: []string{erc20ParamOne, erc20ParamTwo, erc20ParamThree},
+		AttestationConfig: rpc.AttestationConfig{InsecureSkipVerify: true},
 	}
 
 	contractAddr, err := contractdeployer.Deploy(config, testlog.Logger())
@@ -110,6 +111,7 @@ func TestFaucetSendsFundsOnlyIfNeeded(t *testing.T) {
 		ChainID:           big.NewInt(integration.ObscuroChainID),
 		ContractName:      contractdeployer.Layer2Erc20Contract,
 		ConstructorParams: []string{erc20ParamOne, erc20ParamTwo, erc20ParamThree},
+		AttestationConfig: rpc.AttestationConfig{InsecureSkipVerify: true},
 	}
 
 	_, err = contractdeployer.Deploy(config, testlog.Logger())
@@ -167,7 +169,7 @@ func getClient(hostWSPort int, wallet wallet.Wallet) *rpc.EncRPCClient {
 	if err != nil {
 		panic(err)
 	}
-	client, err := rpc.NewEncNetworkClient(fmt.Sprintf("ws://%s:%d", network.Localhost, hostWSPort), viewingKey, testlog.Logger())
+	client, err := rpc.NewEncNetworkClient(fmt.Sprintf("ws://%s:%d", network.Localhost, hostWSPort), viewingKey, rpc.NewInsecureEnclaveKeyFetcher(testlog.Logger()), testlog.Logger())
 	if err != nil {
 		panic(err)
 	}
@@ -22,6 +22,9 @@ var (
 	storeSecretTxAddr      = datagenerator.RandomAddress()
 	requestSecretTxAddr    = datagenerator.RandomAddress()
 	initializeSecretTxAddr = datagenerator.RandomAddress()
+	allowListTxAddr        = datagenerator.RandomAddress()
+	rotationRequestTxAddr  = datagenerator.RandomAddress()
+	rotationTxAddr         = datagenerator.RandomAddress()
 )
 
 // mockContractLib is an implementation of the mgmtcontractlib.MgmtContractLib
@@ -63,6 +66,18 @@ func (m *mockContractLib) CreateInitializeSecret(tx *ethadapter.L1InitializeSecr
 	return encodeTx(tx, nonce, initializeSecretTxAddr)
 }
 
+func (m *mockContractLib) CreateSetAttestationAllowList(tx *ethadapter.L1SetAttestationAllowListTx, nonce uint64) types.TxData {
+	return encodeTx(tx, nonce, allowListTxAddr)
+}
+
+func (m *mockContractLib) CreateRequestSecretRotation(tx *ethadapter.L1RequestSecretRotationTx, nonce uint64) types.TxData {
+	return encodeTx(tx, nonce, rotationRequestTxAddr)
+}
+
+func (m *mockContractLib) CreatePublishSecretRotation(tx *ethadapter.L1SecretRotationTx, nonce uint64) types.TxData {
+	return encodeTx(tx, nonce, rotationTxAddr)
+}
+
 func (m *mockContractLib) GetHostAddresses() (ethereum.CallMsg, error) {
 	return ethereum.CallMsg{}, nil
 }
@@ -95,6 +110,12 @@ func decodeTx(tx *types.Transaction) ethadapter.L1Transaction {
 		t = &ethadapter.L1RequestSecretTx{}
 	case initializeSecretTxAddr.Hex():
 		t = &ethadapter.L1InitializeSecretTx{}
+	case allowListTxAddr.Hex():
+		t = &ethadapter.L1SetAttestationAllowListTx{}
+	case rotationRequestTxAddr.Hex():
+		t = &ethadapter.L1RequestSecretRotationTx{}
+	case rotationTxAddr.Hex():
+		t = &ethadapter.L1SecretRotationTx{}
 	default:
 		panic("unexpected type")
 	}
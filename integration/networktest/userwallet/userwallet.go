@@ -51,7 +51,7 @@ type Option func(wallet *UserWallet)
 // Note: will use testlog.Logger() as the logger
 func GenerateRandomWallet(network networktest.NetworkConnector) *UserWallet {
 	wallet := datagenerator.RandomWallet(network.ChainID())
-	_, err := obsclient.DialWithAuth(network.SequencerRPCAddress(), wallet, testlog.Logger())
+	_, err := obsclient.DialWithAuth(network.SequencerRPCAddress(), wallet, rpc.NewInsecureEnclaveKeyFetcher(testlog.Logger()), testlog.Logger())
 	if err != nil {
 		panic(err)
 	}
@@ -165,7 +165,7 @@ func (s *UserWallet) EnsureClientSetup(ctx context.Context) error {
 		// client already setup
 		return nil
 	}
-	authClient, err := obsclient.DialWithAuth(s.rpcEndpoint, s, s.logger)
+	authClient, err := obsclient.DialWithAuth(s.rpcEndpoint, s, rpc.NewInsecureEnclaveKeyFetcher(s.logger), s.logger)
 	if err != nil {
 		return err
 	}
@@ -188,7 +188,7 @@ func (s *UserWallet) ResetClient(ctx context.Context) error {
 		// client already setup, close it before re-authenticating
 		s.client.Close()
 	}
-	authClient, err := obsclient.DialWithAuth(s.rpcEndpoint, s, s.logger)
+	authClient, err := obsclient.DialWithAuth(s.rpcEndpoint, s, rpc.NewInsecureEnclaveKeyFetcher(s.logger), s.logger)
 	if err != nil {
 		return err
 	}
@@ -82,6 +82,7 @@ func createInMemObscuroNode(
 		MessageBusAddress:         *l1BusAddress,
 		ManagementContractAddress: *mgtContractAddress,
 		Cadence:                   10,
+		RollupInclusionTimeout:    time.Minute,
 	}
 
 	enclaveLogger := testlog.Logger().New(log.NodeIDKey, id, log.CmpKey, log.EnclaveCmp)
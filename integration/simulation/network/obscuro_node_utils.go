@@ -170,7 +170,7 @@ func createAuthClients(clients []rpc.Client, wal wallet.Wallet) []*obsclient.Aut
 			panic(err)
 		}
 		// todo - use a child logger
-		encClient, err := rpc.NewEncRPCClient(client, vk, testlog.Logger())
+		encClient, err := rpc.NewEncRPCClient(client, vk, rpc.NewInsecureEnclaveKeyFetcher(testlog.Logger()), testlog.Logger())
 		if err != nil {
 			panic(err)
 		}
@@ -187,25 +187,26 @@ func startRemoteEnclaveServers(params *params.SimParams) {
 
 		// TODO - Change/derive from the default enclave config
 		enclaveConfig := config.EnclaveConfig{
-			HostID:            gethcommon.BigToAddress(big.NewInt(int64(i))),
-			HostAddress:       hostAddr,
-			Address:           enclaveAddr,
-			NodeType:          GetNodeType(i),
-			L1ChainID:         integration.EthereumChainID,
-			ObscuroChainID:    integration.ObscuroChainID,
-			ValidateL1Blocks:  false,
-			WillAttest:        false,
-			GenesisJSON:       nil,
-			UseInMemoryDB:     false,
-			MinGasPrice:       big.NewInt(1),
-			MessageBusAddress: *params.L1SetupData.MessageBusAddr,
-			Cadence:           10,
+			HostID:                 gethcommon.BigToAddress(big.NewInt(int64(i))),
+			HostAddress:            hostAddr,
+			Address:                enclaveAddr,
+			NodeType:               GetNodeType(i),
+			L1ChainID:              integration.EthereumChainID,
+			ObscuroChainID:         integration.ObscuroChainID,
+			ValidateL1Blocks:       false,
+			WillAttest:             false,
+			GenesisJSON:            nil,
+			UseInMemoryDB:          false,
+			MinGasPrice:            big.NewInt(1),
+			MessageBusAddress:      *params.L1SetupData.MessageBusAddr,
+			Cadence:                10,
+			RollupInclusionTimeout: time.Minute,
 		}
 		enclaveLogger := testlog.Logger().New(log.NodeIDKey, i, log.CmpKey, log.EnclaveCmp)
 		encl := enclave.NewEnclave(enclaveConfig, &genesis.TestnetGenesis, params.MgmtContractLib, enclaveLogger)
 		enclaveContainer := enclavecontainer.EnclaveContainer{
 			Enclave:   encl,
-			RPCServer: enclave.NewEnclaveRPCServer(enclaveConfig.Address, encl, enclaveLogger),
+			RPCServer: enclave.NewEnclaveRPCServer(enclaveConfig.Address, encl, nil, enclaveLogger),
 			Logger:    enclaveLogger,
 		}
 		err := enclaveContainer.Start()
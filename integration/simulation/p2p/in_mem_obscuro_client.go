@@ -7,7 +7,6 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/obscuronet/go-obscuro/go/common"
 	"github.com/obscuronet/go-obscuro/go/common/log"
@@ -21,37 +20,24 @@ import (
 	hostcommon "github.com/obscuronet/go-obscuro/go/common/host"
 )
 
-const (
-	// todo: this is a convenience for testnet testing and will eventually be retrieved from the L1
-	enclavePublicKeyHex = "034d3b7e63a8bcd532ee3d1d6ecad9d67fca7821981a044551f0f0cbec74d0bc5e"
-)
-
 // An in-memory implementation of `rpc.Client` that speaks directly to the node.
 type inMemObscuroClient struct {
-	obscuroAPI       *clientapi.ObscuroAPI
-	ethAPI           *clientapi.EthereumAPI
-	filterAPI        *clientapi.FilterAPI
-	obscuroScanAPI   *clientapi.ObscuroScanAPI
-	testAPI          *clientapi.TestAPI
-	enclavePublicKey *ecies.PublicKey
+	obscuroAPI     *clientapi.ObscuroAPI
+	ethAPI         *clientapi.EthereumAPI
+	filterAPI      *clientapi.FilterAPI
+	obscuroScanAPI *clientapi.ObscuroScanAPI
+	testAPI        *clientapi.TestAPI
 }
 
 func NewInMemObscuroClient(hostContainer *container.HostContainer) rpc.Client {
 	logger := testlog.Logger().New(log.CmpKey, log.RPCClientCmp)
-	// todo: this is a convenience for testnet but needs to replaced by a parameter and/or retrieved from the target host
-	enclPubECDSA, err := crypto.DecompressPubkey(gethcommon.Hex2Bytes(enclavePublicKeyHex))
-	if err != nil {
-		panic(err)
-	}
-	enclPubKey := ecies.ImportECDSAPublic(enclPubECDSA)
 
 	return &inMemObscuroClient{
-		obscuroAPI:       clientapi.NewObscuroAPI(hostContainer.Host()),
-		ethAPI:           clientapi.NewEthereumAPI(hostContainer.Host(), logger),
-		filterAPI:        clientapi.NewFilterAPI(hostContainer.Host(), logger),
-		obscuroScanAPI:   clientapi.NewObscuroScanAPI(hostContainer.Host()),
-		testAPI:          clientapi.NewTestAPI(hostContainer),
-		enclavePublicKey: enclPubKey,
+		obscuroAPI:     clientapi.NewObscuroAPI(hostContainer.Host()),
+		ethAPI:         clientapi.NewEthereumAPI(hostContainer.Host(), logger),
+		filterAPI:      clientapi.NewFilterAPI(hostContainer.Host(), logger),
+		obscuroScanAPI: clientapi.NewObscuroScanAPI(hostContainer.Host()),
+		testAPI:        clientapi.NewTestAPI(hostContainer),
 	}
 }
 
@@ -96,6 +82,9 @@ func (c *inMemObscuroClient) Call(result interface{}, method string, args ...int
 	case rpc.Health:
 		return c.health(result)
 
+	case rpc.GetDivergenceReports:
+		return c.getDivergenceReports(result)
+
 	case rpc.GetTotalTxs:
 		return c.getTotalTransactions(result)
 
@@ -108,6 +97,9 @@ func (c *inMemObscuroClient) Call(result interface{}, method string, args ...int
 	case rpc.GetBatch:
 		return c.getBatch(result, args)
 
+	case rpc.Attestation:
+		return c.attestation(result)
+
 	default:
 		return fmt.Errorf("RPC method %s is unknown", method)
 	}
@@ -295,6 +287,26 @@ func (c *inMemObscuroClient) health(result interface{}) error {
 	return nil
 }
 
+func (c *inMemObscuroClient) getDivergenceReports(result interface{}) error {
+	reports, err := c.obscuroAPI.GetDivergenceReports()
+	if err != nil {
+		return fmt.Errorf("`%s` call failed. Cause: %w", rpc.GetDivergenceReports, err)
+	}
+
+	*result.(*[]*common.DivergenceReport) = reports
+	return nil
+}
+
+func (c *inMemObscuroClient) attestation(result interface{}) error {
+	att, err := c.obscuroScanAPI.Attestation()
+	if err != nil {
+		return fmt.Errorf("`%s` call failed. Cause: %w", rpc.Attestation, err)
+	}
+
+	*result.(*common.AttestationReport) = *att
+	return nil
+}
+
 func (c *inMemObscuroClient) getTotalTransactions(result interface{}) error {
 	totalTxs, err := c.obscuroScanAPI.GetTotalTransactions()
 	if err != nil {
@@ -383,6 +383,15 @@ func checkBlockchainOfObscuroNode(t *testing.T, rpcHandles *network.RPCHandles,
 
 	checkTransactionReceipts(s.ctx, t, nodeIdx, rpcHandles, s.TxInjector)
 
+	// check that the node's re-execution of the batches it received matched them.
+	divergenceReports, err := obscuroClient.DivergenceReports()
+	if err != nil {
+		t.Errorf("Node %d: Could not retrieve divergence reports. Cause: %s", nodeIdx, err)
+	}
+	if len(divergenceReports) > 0 {
+		t.Errorf("Node %d: Produced %d divergence reports. First report: %+v", nodeIdx, len(divergenceReports), divergenceReports[0])
+	}
+
 	totalSuccessfullyWithdrawn := extractWithdrawals(t, obscuroClient, nodeIdx)
 
 	totalAmountLogged := getLoggedWithdrawals(minObscuroHeight, obscuroClient, headRollupHeader)
@@ -7,6 +7,7 @@ import (
 	generatedManagementContract "github.com/obscuronet/go-obscuro/contracts/generated/ManagementContract"
 
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/obscuronet/go-obscuro/go/common"
 	"github.com/obscuronet/go-obscuro/go/ethadapter"
 	"github.com/obscuronet/go-obscuro/go/ethadapter/mgmtcontractlib"
@@ -37,7 +38,7 @@ func newDebugMgmtContractLib(address gethcommon.Address, client *ethereumclient.
 
 // AwaitedIssueRollup speeds ups the issuance of rollup, await of tx to be minted and makes sure the values are correctly stored
 func (d *debugMgmtContractLib) AwaitedIssueRollup(rollup common.ExtRollup, client ethadapter.EthClient, w *debugWallet) error {
-	encodedRollup, err := common.EncodeRollup(&rollup)
+	encodedRollup, err := rlp.EncodeToBytes(&rollup)
 	if err != nil {
 		return err
 	}
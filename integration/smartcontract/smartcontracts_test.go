@@ -9,6 +9,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/obscuronet/go-obscuro/go/common"
 	"github.com/obscuronet/go-obscuro/go/common/constants"
 	"github.com/obscuronet/go-obscuro/go/ethadapter"
@@ -136,7 +137,7 @@ func nonAttestedNodesCannotCreateRollup(t *testing.T, mgmtContractLib *debugMgmt
 
 	rollup := datagenerator.RandomRollup(block)
 
-	encodedRollup, err := common.EncodeRollup(&rollup)
+	encodedRollup, err := rlp.EncodeToBytes(&rollup)
 	if err != nil {
 		t.Error(err)
 	}
@@ -671,7 +672,7 @@ func detectSimpleFork(t *testing.T, mgmtContractLib *debugMgmtContractLib, w *de
 
 	t.Logf("LAST Issued Rollup: %s parent: %s", r.Hash(), r.Header.ParentHash)
 
-	encodedRollup, err := common.EncodeRollup(&r)
+	encodedRollup, err := rlp.EncodeToBytes(&r)
 	if err != nil {
 		t.Error(err)
 	}
@@ -129,6 +129,7 @@ func (t *Testnet) Start() error {
 			l2cd.WithHocPKString("6e384a07a01263518a09a5424c7b6bbfc3604ba7d93f47e3a455cbdd7f9f0682"),
 			l2cd.WithPocPKString("4bfe14725e685901c062ccd4e220c61cf9c189897b6c78bd18d7f51291b2b8f8"),
 			l2cd.WithDockerImage("testnetobscuronet.azurecr.io/obscuronet/hardhatdeployer:latest"),
+			l2cd.WithInsecureSkipAttestation(true), // the local testnet runs without SGX
 		),
 	)
 	if err != nil {
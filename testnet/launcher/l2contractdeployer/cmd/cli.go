@@ -16,6 +16,8 @@ type L2ContractDeployerConfigCLI struct {
 	l2PrivateKey           string
 	l2HOCPrivateKey        string
 	l2POCPrivateKey        string
+	enclaveUniqueID        string
+	skipAttestation        bool
 }
 
 // ParseConfigCLI returns a NodeConfigCLI based the cli params and defaults.
@@ -33,6 +35,8 @@ func ParseConfigCLI() *L2ContractDeployerConfigCLI {
 	l2PrivateKey := flag.String(l2privateKeyFlag, "", flagUsageMap[l2privateKeyFlag])
 	l2HOCPrivateKey := flag.String(l2HOCPrivateKeyFlag, "", flagUsageMap[l2HOCPrivateKeyFlag])
 	l2POCPrivateKey := flag.String(l2POCPrivateKeyFlag, "", flagUsageMap[l2POCPrivateKeyFlag])
+	enclaveUniqueID := flag.String(enclaveUniqueIDFlag, "", flagUsageMap[enclaveUniqueIDFlag])
+	skipAttestation := flag.Bool(skipAttestationFlag, false, flagUsageMap[skipAttestationFlag])
 
 	flag.Parse()
 
@@ -46,6 +50,8 @@ func ParseConfigCLI() *L2ContractDeployerConfigCLI {
 	cfg.l2PrivateKey = *l2PrivateKey
 	cfg.l2HOCPrivateKey = *l2POCPrivateKey
 	cfg.l2POCPrivateKey = *l2HOCPrivateKey
+	cfg.enclaveUniqueID = *enclaveUniqueID
+	cfg.skipAttestation = *skipAttestation
 
 	return cfg
 }
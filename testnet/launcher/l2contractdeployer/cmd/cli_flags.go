@@ -12,6 +12,8 @@ const (
 	l2privateKeyFlag           = "l2_private_key"
 	l2HOCPrivateKeyFlag        = "l2_hoc_private_key"
 	l2POCPrivateKeyFlag        = "l2_poc_private_key"
+	enclaveUniqueIDFlag        = "enclave_unique_id"
+	skipAttestationFlag        = "skip_attestation"
 )
 
 // Returns a map of the flag usages.
@@ -28,5 +30,7 @@ func getFlagUsageMap() map[string]string {
 		l2privateKeyFlag:           "Layer 2 private key",
 		l2HOCPrivateKeyFlag:        "Layer 2 HOC contract private key",
 		l2POCPrivateKeyFlag:        "Layer 2 POC contract private key",
+		enclaveUniqueIDFlag:        "The unique ID the Layer 2 node's enclave must attest to",
+		skipAttestationFlag:        "Trust the Layer 2 node's enclave key without verifying its attestation",
 	}
 }
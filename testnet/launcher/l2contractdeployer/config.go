@@ -15,6 +15,9 @@ type Config struct {
 	pocPKString       string
 	messageBusAddress string
 	dockerImage       string
+	// the attestation checks done by the wallet extension the deployer connects through
+	enclaveUniqueID         string
+	insecureSkipAttestation bool
 }
 
 func NewContractDeployerConfig(opts ...Option) *Config {
@@ -86,3 +89,15 @@ func WithPocPKString(s string) Option {
 		c.pocPKString = s
 	}
 }
+
+func WithEnclaveUniqueID(s string) Option {
+	return func(c *Config) {
+		c.enclaveUniqueID = s
+	}
+}
+
+func WithInsecureSkipAttestation(b bool) Option {
+	return func(c *Config) {
+		c.insecureSkipAttestation = b
+	}
+}
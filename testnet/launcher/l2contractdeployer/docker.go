@@ -2,6 +2,7 @@ package l2contractdeployer
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/docker/docker/client"
@@ -65,6 +66,10 @@ func (n *ContractDeployer) Start() error {
 `, n.cfg.l1Host, n.cfg.l1Port, n.cfg.l1privateKey, n.cfg.l2Host, n.cfg.l2Port, n.cfg.l2PrivateKey, n.cfg.hocPKString, n.cfg.pocPKString),
 	}
 
+	// the wallet extension started by the deployer reads its attestation config from the environment
+	envs["OBSCURO_WALLET_EXTENSION_ENCLAVE_UNIQUE_ID"] = n.cfg.enclaveUniqueID
+	envs["OBSCURO_WALLET_EXTENSION_INSECURE_SKIP_ATTESTATION"] = strconv.FormatBool(n.cfg.insecureSkipAttestation)
+
 	containerID, err := docker.StartNewContainer("hh-l2-deployer", n.cfg.dockerImage, cmds, nil, envs, nil, nil)
 	if err != nil {
 		return err
@@ -2,8 +2,11 @@ package contractdeployer
 
 import (
 	"flag"
+	"fmt"
 	"math/big"
 	"strings"
+
+	"github.com/obscuronet/go-obscuro/go/rpc"
 )
 
 const (
@@ -39,6 +42,8 @@ type Config struct {
 	ChainID           *big.Int // chain ID we're deploying too
 	ContractName      string   // the name of the contract to deploy (e.g. ERC20 or MGMT)
 	ConstructorParams []string // parameters sent to the constructor
+	// how the node's enclave's attestation is verified, for L2 deployments
+	AttestationConfig rpc.AttestationConfig
 }
 
 // ParseConfig returns a Config after parsing all available flags
@@ -53,6 +58,13 @@ func ParseConfig() *Config {
 	// if this flag has a non-zero value it will be used instead of the default chain IDs
 	overrideChainID := flag.Int64(chainIDName, chainIDPlaceholder, chainIDUsage)
 	constructorParams := flag.String(constructorParamsName, constructorParamsPlaceholder, constructorParamsUsage)
+	enclaveUniqueID := flag.String(enclaveUniqueIDName, "", enclaveUniqueIDUsage)
+	enclaveSignerID := flag.String(enclaveSignerIDName, "", enclaveSignerIDUsage)
+	enclaveProductID := flag.Uint(enclaveProductIDName, 0, enclaveProductIDUsage)
+	enclaveSecurityVersion := flag.Uint(enclaveSecurityVersionName, 0, enclaveSecurityVersionUsage)
+	enclaveAllowDebug := flag.Bool(enclaveAllowDebugName, false, enclaveAllowDebugUsage)
+	enclaveTCBStatuses := flag.String(enclaveTCBStatusesName, "", enclaveTCBStatusesUsage)
+	insecureSkipAttestation := flag.Bool(insecureSkipAttestationName, false, insecureSkipAttestationUsage)
 
 	flag.Parse()
 
@@ -61,6 +73,12 @@ func ParseConfig() *Config {
 	defaultConfig.IsL1Deployment = *isL1Deployment
 	defaultConfig.PrivateKey = *privateKeyStr
 	defaultConfig.ContractName = *contractName
+	attestationPolicy, err := rpc.ParseAttestationPolicy(strings.Split(*enclaveUniqueID, ","), strings.Split(*enclaveSignerID, ","),
+		uint16(*enclaveProductID), uint64(*enclaveSecurityVersion), *enclaveAllowDebug, strings.Split(*enclaveTCBStatuses, ","))
+	if err != nil {
+		panic(fmt.Sprintf("invalid attestation policy. Cause: %s", err))
+	}
+	defaultConfig.AttestationConfig = rpc.AttestationConfig{Policy: attestationPolicy, InsecureSkipVerify: *insecureSkipAttestation}
 
 	if defaultConfig.IsL1Deployment {
 		// for L1 deployment we default the chain ID to the L1 chain (it will still be overridden if arg was set by caller)
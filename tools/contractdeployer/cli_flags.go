@@ -22,4 +22,25 @@ const (
 
 	constructorParamsName  = "constructorParams"
 	constructorParamsUsage = "A comma separated list of strings that will be passed to the deployer. Defaults to empty."
+
+	enclaveUniqueIDName  = "enclaveUniqueID"
+	enclaveUniqueIDUsage = "The hex-encoded unique ID (MRENCLAVE) the node's enclave must attest to, for L2 deployments"
+
+	enclaveSignerIDName  = "enclaveSignerID"
+	enclaveSignerIDUsage = "The hex-encoded signer ID (MRSIGNER) the node's enclave must attest to. Requires enclaveProductID"
+
+	enclaveProductIDName  = "enclaveProductID"
+	enclaveProductIDUsage = "The product ID the node's enclave must attest to"
+
+	enclaveSecurityVersionName  = "enclaveSecurityVersion"
+	enclaveSecurityVersionUsage = "The minimum security version the node's enclave must attest to"
+
+	enclaveAllowDebugName  = "enclaveAllowDebug"
+	enclaveAllowDebugUsage = "Whether to accept attestations from debug enclaves"
+
+	enclaveTCBStatusesName  = "enclaveTCBStatuses"
+	enclaveTCBStatusesUsage = "The TCB statuses of the node's enclave that are accepted in addition to UpToDate, as a comma-separated list (e.g. SWHardeningNeeded)"
+
+	insecureSkipAttestationName  = "insecureSkipAttestation"
+	insecureSkipAttestationUsage = "Trust the node's enclave key without verifying its attestation. Only for dev networks that run without SGX"
 )
@@ -11,6 +11,7 @@ import (
 	"github.com/obscuronet/go-obscuro/go/enclave/genesis"
 	"github.com/obscuronet/go-obscuro/go/obsclient"
 	"github.com/obscuronet/go-obscuro/go/obsclient/clientutil"
+	"github.com/obscuronet/go-obscuro/go/rpc"
 	"github.com/obscuronet/go-obscuro/go/wallet"
 
 	gethcommon "github.com/ethereum/go-ethereum/common"
@@ -18,13 +19,17 @@ import (
 )
 
 func prepareObscuroDeployer(cfg *Config, wal wallet.Wallet, logger gethlog.Logger) (contractDeployerClient, error) {
-	client, err := connectClient(getURL(cfg), wal, logger)
+	keyFetcher, err := rpc.NewEnclaveKeyFetcher(nil, cfg.AttestationConfig, logger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation constraints - %w", err)
+	}
+	client, err := connectClient(getURL(cfg), wal, keyFetcher, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup obscuro client - %w", err)
 	}
 
 	// todo: this step doesn't belong in the contract_deployer tool, script should fail for underfunded deployer account
-	err = fundDeployerWithFaucet(cfg, client, logger)
+	err = fundDeployerWithFaucet(cfg, client, keyFetcher, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fund deployer acc from faucet - %w", err)
 	}
@@ -32,7 +37,7 @@ func prepareObscuroDeployer(cfg *Config, wal wallet.Wallet, logger gethlog.Logge
 	return &obscuroDeployer{client: client}, nil
 }
 
-func fundDeployerWithFaucet(cfg *Config, client *obsclient.AuthObsClient, logger gethlog.Logger) error {
+func fundDeployerWithFaucet(cfg *Config, client *obsclient.AuthObsClient, keyFetcher *rpc.EnclaveKeyFetcher, logger gethlog.Logger) error {
 	// Create the L2 faucet wallet and client.
 	faucetPrivKey, err := crypto.HexToECDSA(genesis.TestnetPrefundedPK)
 	if err != nil {
@@ -40,7 +45,7 @@ func fundDeployerWithFaucet(cfg *Config, client *obsclient.AuthObsClient, logger
 	}
 	faucetWallet := wallet.NewInMemoryWalletFromPK(cfg.ChainID, faucetPrivKey, logger)
 
-	faucetClient, err := connectClient(getURL(cfg), faucetWallet, logger)
+	faucetClient, err := connectClient(getURL(cfg), faucetWallet, keyFetcher, logger)
 	if err != nil {
 		return err
 	}
@@ -88,14 +93,14 @@ func fundDeployerWithFaucet(cfg *Config, client *obsclient.AuthObsClient, logger
 	return nil
 }
 
-func connectClient(url string, wal wallet.Wallet, logger gethlog.Logger) (*obsclient.AuthObsClient, error) {
+func connectClient(url string, wal wallet.Wallet, keyFetcher *rpc.EnclaveKeyFetcher, logger gethlog.Logger) (*obsclient.AuthObsClient, error) {
 	var client *obsclient.AuthObsClient
 	var err error
 
 	startConnectingTime := time.Now()
 	// since the nodes we are connecting to may have only just started, we retry connection until it is successful
 	for client == nil && time.Since(startConnectingTime) < timeoutWait {
-		client, err = obsclient.DialWithAuth(url, wal, logger)
+		client, err = obsclient.DialWithAuth(url, wal, keyFetcher, logger)
 		if err == nil {
 			break // success
 		}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/obscuronet/go-obscuro/go/rpc"
 	"github.com/obscuronet/go-obscuro/integration"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -20,9 +21,13 @@ const (
 	DeployMgmtContract Command = iota
 	DeployERC20Contract
 	InjectTxs
+	PublishAllowList
+	RequestRotation
 	deployMgmtContractName  = "deployMgmtContract"
 	deployERC20ContractName = "deployERC20Contract"
 	injectTxsName           = "injectTransactions"
+	publishAllowListName    = "publishAttestationAllowList"
+	requestRotationName     = "requestSecretRotation"
 
 	// Flag names and usages.
 	l1NodeHostName  = "l1NodeHost"
@@ -54,6 +59,30 @@ const (
 
 	erc20TokenName  = "erc20Token" //nolint:gosec
 	erc20TokenUsage = "The name of the ERC20 token. Default: TST"
+
+	policyKeyName  = "policyKey"
+	policyKeyUsage = "The private key that signs the attestation allow-list and the secret rotation requests"
+
+	enclaveUniqueIDName  = "enclaveUniqueID"
+	enclaveUniqueIDUsage = "The hex-encoded unique ID (MRENCLAVE) the node's enclave must attest to"
+
+	enclaveSignerIDName  = "enclaveSignerID"
+	enclaveSignerIDUsage = "The hex-encoded signer ID (MRSIGNER) the node's enclave must attest to. Requires enclaveProductID"
+
+	enclaveProductIDName  = "enclaveProductID"
+	enclaveProductIDUsage = "The product ID the node's enclave must attest to"
+
+	enclaveSecurityVersionName  = "enclaveSecurityVersion"
+	enclaveSecurityVersionUsage = "The minimum security version the node's enclave must attest to"
+
+	enclaveAllowDebugName  = "enclaveAllowDebug"
+	enclaveAllowDebugUsage = "Whether to accept attestations from debug enclaves"
+
+	enclaveTCBStatusesName  = "enclaveTCBStatuses"
+	enclaveTCBStatusesUsage = "The TCB statuses of the node's enclave that are accepted in addition to UpToDate, as a comma-separated list (e.g. SWHardeningNeeded)"
+
+	insecureSkipAttestationName  = "insecureSkipAttestation"
+	insecureSkipAttestationUsage = "Trust the node's enclave key without verifying its attestation. Only for dev networks that run without SGX"
 )
 
 type Config struct {
@@ -68,6 +97,9 @@ type Config struct {
 	erc20ContractAddress common.Address
 	obscuroClientAddress string
 	erc20Token           string
+	policyKey            string
+	// how the node's enclave's attestation is verified before transactions are submitted to it
+	attestationConfig rpc.AttestationConfig
 }
 
 func defaultNetworkManagerConfig() Config {
@@ -100,6 +132,14 @@ func ParseCLIArgs() (Config, []string) {
 	erc20ContractAddress := flag.String(erc20ContractAddressName, defaultConfig.erc20ContractAddress.Hex(), erc20ContractAddressUsage)
 	obscuroClientAddress := flag.String(obscuroClientAddressName, defaultConfig.obscuroClientAddress, obscuroClientAddressUsage)
 	erc20Token := flag.String(erc20TokenName, defaultConfig.obscuroClientAddress, erc20TokenUsage)
+	policyKey := flag.String(policyKeyName, defaultConfig.policyKey, policyKeyUsage)
+	enclaveUniqueID := flag.String(enclaveUniqueIDName, "", enclaveUniqueIDUsage)
+	enclaveSignerID := flag.String(enclaveSignerIDName, "", enclaveSignerIDUsage)
+	enclaveProductID := flag.Uint(enclaveProductIDName, 0, enclaveProductIDUsage)
+	enclaveSecurityVersion := flag.Uint(enclaveSecurityVersionName, 0, enclaveSecurityVersionUsage)
+	enclaveAllowDebug := flag.Bool(enclaveAllowDebugName, false, enclaveAllowDebugUsage)
+	enclaveTCBStatuses := flag.String(enclaveTCBStatusesName, "", enclaveTCBStatusesUsage)
+	insecureSkipAttestation := flag.Bool(insecureSkipAttestationName, false, insecureSkipAttestationUsage)
 
 	flag.Parse()
 
@@ -113,6 +153,13 @@ func ParseCLIArgs() (Config, []string) {
 	defaultConfig.erc20ContractAddress = common.HexToAddress(*erc20ContractAddress)
 	defaultConfig.obscuroClientAddress = *obscuroClientAddress
 	defaultConfig.erc20Token = *erc20Token
+	defaultConfig.policyKey = *policyKey
+	attestationPolicy, err := rpc.ParseAttestationPolicy(strings.Split(*enclaveUniqueID, ","), strings.Split(*enclaveSignerID, ","),
+		uint16(*enclaveProductID), uint64(*enclaveSecurityVersion), *enclaveAllowDebug, strings.Split(*enclaveTCBStatuses, ","))
+	if err != nil {
+		panic(fmt.Sprintf("invalid attestation policy. Cause: %s", err))
+	}
+	defaultConfig.attestationConfig = rpc.AttestationConfig{Policy: attestationPolicy, InsecureSkipVerify: *insecureSkipAttestation}
 
 	command := flag.Arg(0)
 	var args []string
@@ -125,6 +172,15 @@ func ParseCLIArgs() (Config, []string) {
 		defaultConfig.Command = InjectTxs
 		numOfTxs := flag.Arg(1)
 		args = append(args, numOfTxs)
+	case publishAllowListName:
+		defaultConfig.Command = PublishAllowList
+		allowListPath := flag.Arg(1)
+		args = append(args, allowListPath)
+	case requestRotationName:
+		defaultConfig.Command = RequestRotation
+		epoch := flag.Arg(1)
+		revokedID := flag.Arg(2)
+		args = append(args, epoch, revokedID)
 	default:
 		panic(fmt.Sprintf("unrecognised command %s", command))
 	}
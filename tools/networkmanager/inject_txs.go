@@ -56,7 +56,7 @@ func InjectTransactions(cfg Config, args []string, logger gethlog.Logger) {
 	avgBlockDuration := time.Second
 
 	wallets := createWallets(cfg, l1Client, l2Client, logger)
-	walletClients := createWalletRPCClients(wallets, cfg.obscuroClientAddress, logger)
+	walletClients := createWalletRPCClients(wallets, cfg.obscuroClientAddress, cfg.attestationConfig, logger)
 
 	rpcHandles := &network.RPCHandles{
 		EthClients:     []ethadapter.EthClient{l1Client},
@@ -91,15 +91,19 @@ func InjectTransactions(cfg Config, args []string, logger gethlog.Logger) {
 }
 
 // createWalletRPCClients creates map of wallet address to list of wallet clients (of length 1 because we have 1 node)
-func createWalletRPCClients(wallets *params.SimWallets, obscuroNodeAddr string, logger gethlog.Logger) map[string][]*obsclient.AuthObsClient {
+func createWalletRPCClients(wallets *params.SimWallets, obscuroNodeAddr string, attestationConfig rpc.AttestationConfig, logger gethlog.Logger) map[string][]*obsclient.AuthObsClient {
 	clients := make(map[string][]*obsclient.AuthObsClient)
+	keyFetcher, err := rpc.NewEnclaveKeyFetcher(nil, attestationConfig, logger)
+	if err != nil {
+		panic(fmt.Sprintf("invalid attestation config. Cause: %s", err))
+	}
 
 	for _, w := range wallets.SimObsWallets {
 		vk, err := rpc.GenerateAndSignViewingKey(w)
 		if err != nil {
 			panic(err)
 		}
-		client, err := rpc.NewEncNetworkClient(obscuroNodeAddr, vk, logger)
+		client, err := rpc.NewEncNetworkClient(obscuroNodeAddr, vk, keyFetcher, logger)
 		if err != nil {
 			panic(err)
 		}
@@ -113,7 +117,7 @@ func createWalletRPCClients(wallets *params.SimWallets, obscuroNodeAddr string,
 		if err != nil {
 			panic(err)
 		}
-		client, err := rpc.NewEncNetworkClient(obscuroNodeAddr, vk, logger)
+		client, err := rpc.NewEncNetworkClient(obscuroNodeAddr, vk, keyFetcher, logger)
 		if err != nil {
 			panic(err)
 		}
@@ -18,6 +18,10 @@ func main() {
 		networkmanager.DeployContract(config, logger)
 	case networkmanager.InjectTxs:
 		networkmanager.InjectTransactions(config, args, logger)
+	case networkmanager.PublishAllowList:
+		networkmanager.PublishAttestationAllowList(config, args, logger)
+	case networkmanager.RequestRotation:
+		networkmanager.RequestSecretRotation(config, args, logger)
 	default:
 		panic("unrecognised command type")
 	}
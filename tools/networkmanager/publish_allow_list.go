@@ -0,0 +1,76 @@
+package networkmanager
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/ethadapter"
+	"github.com/obscuronet/go-obscuro/go/ethadapter/mgmtcontractlib"
+	"github.com/obscuronet/go-obscuro/go/wallet"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+// PublishAttestationAllowList signs the attestation allow-list at the path given in the args with the policy key, and
+// publishes it in the management contract.
+func PublishAttestationAllowList(config Config, args []string, logger gethlog.Logger) {
+	encoded, err := os.ReadFile(args[0])
+	if err != nil {
+		panic(fmt.Sprintf("could not read allow-list at %s. Cause: %s", args[0], err))
+	}
+	allowList, err := common.DecodeAttestationAllowList(encoded)
+	if err != nil {
+		panic(err)
+	}
+	policyKey, err := crypto.HexToECDSA(config.policyKey)
+	if err != nil {
+		panic(fmt.Sprintf("could not parse policy key. Cause: %s", err))
+	}
+	if err = allowList.Validate(); err != nil {
+		panic(err)
+	}
+	if err = allowList.Sign(policyKey, config.l1ChainID, config.mgmtContractAddress); err != nil {
+		panic(err)
+	}
+	signedAllowList, err := common.EncodeAttestationAllowList(allowList)
+	if err != nil {
+		panic(err)
+	}
+
+	sendMgmtContractTx(config, func(mgmtContractLib mgmtcontractlib.MgmtContractLib, nonce uint64) types.TxData {
+		return mgmtContractLib.CreateSetAttestationAllowList(&ethadapter.L1SetAttestationAllowListTx{AllowList: signedAllowList}, nonce)
+	}, logger)
+}
+
+// Sends the transaction created by createTx to the management contract from the first L1 wallet, and prints its hash.
+func sendMgmtContractTx(config Config, createTx func(mgmtcontractlib.MgmtContractLib, uint64) types.TxData, logger gethlog.Logger) {
+	l1Client, err := ethadapter.NewEthClient(config.l1NodeHost, config.l1NodeWebsocketPort, config.l1RPCTimeout, gethcommon.HexToAddress("0x0"), logger)
+	if err != nil {
+		panic(err)
+	}
+	l1Wallet := wallet.NewInMemoryWalletFromConfig(config.privateKeys[0], config.l1ChainID, logger)
+	nonce, err := l1Client.Nonce(l1Wallet.Address())
+	if err != nil {
+		panic(err)
+	}
+
+	tx := createTx(mgmtcontractlib.NewMgmtContractLib(&config.mgmtContractAddress, logger), nonce)
+	tx, err = l1Client.EstimateGasAndGasPrice(tx, l1Wallet.Address())
+	if err != nil {
+		panic(err)
+	}
+	signedTx, err := l1Wallet.SignTransaction(tx)
+	if err != nil {
+		panic(err)
+	}
+	if err = l1Client.SendTransaction(signedTx); err != nil {
+		panic(err)
+	}
+
+	println(signedTx.Hash().Hex())
+	os.Exit(0)
+}
@@ -0,0 +1,42 @@
+package networkmanager
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/obscuronet/go-obscuro/go/ethadapter"
+	"github.com/obscuronet/go-obscuro/go/ethadapter/mgmtcontractlib"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+// RequestSecretRotation requests that the network secret is rotated to the epoch given in the args, revoking the
+// attestation of the aggregator given in the args, if any. The request is signed with the policy key, and must be sent
+// from the account that deployed the management contract.
+func RequestSecretRotation(config Config, args []string, logger gethlog.Logger) {
+	epoch, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("could not parse epoch %s. Cause: %s", args[0], err))
+	}
+	req := &ethadapter.L1RequestSecretRotationTx{Epoch: epoch}
+	if args[1] != "" {
+		if !gethcommon.IsHexAddress(args[1]) {
+			panic(fmt.Sprintf("invalid aggregator ID %s", args[1]))
+		}
+		req.RevokedID = gethcommon.HexToAddress(args[1])
+	}
+	policyKey, err := crypto.HexToECDSA(config.policyKey)
+	if err != nil {
+		panic(fmt.Sprintf("could not parse policy key. Cause: %s", err))
+	}
+	if err = req.Sign(policyKey, config.l1ChainID, config.mgmtContractAddress); err != nil {
+		panic(err)
+	}
+
+	sendMgmtContractTx(config, func(mgmtContractLib mgmtcontractlib.MgmtContractLib, nonce uint64) types.TxData {
+		return mgmtContractLib.CreateRequestSecretRotation(req, nonce)
+	}, logger)
+}
@@ -0,0 +1,287 @@
+package obscuroscan
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+	"github.com/obscuronet/go-obscuro/go/common/httputil"
+	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/tools/obscuroscan/indexer"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	pathAPIV1     = "/api/v1"
+	pathV1Batches = "/batches"
+	pathV1Rollups = "/rollups"
+	pathV1Blocks  = "/blocks"
+	pathV1Stats   = "/stats"
+
+	queryParamCursor     = "cursor"
+	queryParamLimit      = "limit"
+	queryParamFromNumber = "fromNumber"
+	queryParamToNumber   = "toNumber"
+	queryParamFromTime   = "fromTime"
+	queryParamToTime     = "toTime"
+	queryParamFormat     = "format"
+
+	formatJSON         = "json"
+	formatCSV          = "csv"
+	contentTypeCSV     = "text/csv"
+	headerAccept       = "Accept"
+	headerContentType  = "Content-Type"
+	headerDisposition  = "Content-Disposition"
+	headerNextCursor   = "X-Next-Cursor"
+	cursorSeparator    = "_"
+	defaultV1Limit     = 20
+	maxV1Limit         = 1000
+	csvFileNamePattern = "attachment; filename=%s.csv"
+)
+
+// The response to the v1 API's list endpoints.
+type listResponse[T any] struct {
+	Items      []*T    `json:"items"`
+	NextCursor *string `json:"nextCursor"` // passed as the cursor parameter to fetch the next page; nil on the last page
+}
+
+// The response to the v1 API's stats endpoint.
+type statsV1 struct {
+	*indexer.Stats
+	HeadBatchNumber *uint64 `json:"headBatchNumber"` // nil if no batches have been indexed yet
+	HeadBatchTime   *uint64 `json:"headBatchTime"`
+}
+
+// A collection served by the v1 API, with cursor pagination, number and time filters, and CSV export.
+type v1Resource[T any] struct {
+	name      string
+	query     func(indexer.Range) ([]*T, error)
+	cursor    func(*T) indexer.Cursor
+	csvHeader []string
+	csvRow    func(*T) []string
+}
+
+func (o *Obscuroscan) v1Batches() http.HandlerFunc {
+	return v1Resource[indexer.Batch]{
+		name:      "batches",
+		query:     o.db.BatchRange,
+		cursor:    func(b *indexer.Batch) indexer.Cursor { return indexer.Cursor{Number: b.Number, Hash: b.Hash} },
+		csvHeader: []string{"hash", "number", "parentHash", "l1Proof", "time", "txCount", "rollupHash"},
+		csvRow: func(b *indexer.Batch) []string {
+			rollupHash := ""
+			if b.RollupHash != nil {
+				rollupHash = b.RollupHash.Hex()
+			}
+			return []string{b.Hash.Hex(), formatUint(b.Number), b.ParentHash.Hex(), b.L1Proof.Hex(), formatUint(b.Time), formatUint(b.TxCount), rollupHash}
+		},
+	}.handle(o)
+}
+
+func (o *Obscuroscan) v1Rollups() http.HandlerFunc {
+	return v1Resource[indexer.Rollup]{
+		name:      "rollups",
+		query:     o.db.RollupRange,
+		cursor:    func(r *indexer.Rollup) indexer.Cursor { return indexer.Cursor{Number: r.Number, Hash: r.Hash} },
+		csvHeader: []string{"hash", "number", "headBatchHash", "batchCount", "l1BlockHash", "l1BlockNumber", "time"},
+		csvRow: func(r *indexer.Rollup) []string {
+			return []string{r.Hash.Hex(), formatUint(r.Number), r.HeadBatchHash.Hex(), formatUint(r.BatchCount), r.L1BlockHash.Hex(), formatUint(r.L1BlockNumber), formatUint(r.Time)}
+		},
+	}.handle(o)
+}
+
+func (o *Obscuroscan) v1Blocks() http.HandlerFunc {
+	return v1Resource[indexer.Block]{
+		name:      "blocks",
+		query:     o.db.BlockRange,
+		cursor:    func(b *indexer.Block) indexer.Cursor { return indexer.Cursor{Number: b.Number, Hash: b.Hash} },
+		csvHeader: []string{"hash", "number", "parentHash", "time"},
+		csvRow: func(b *indexer.Block) []string {
+			return []string{b.Hash.Hex(), formatUint(b.Number), b.ParentHash.Hex(), formatUint(b.Time)}
+		},
+	}.handle(o)
+}
+
+// Retrieves the number of indexed items of each type, and the head batch.
+func (o *Obscuroscan) v1Stats(resp http.ResponseWriter, req *http.Request) {
+	if httputil.EnableCORS(resp, req) {
+		return
+	}
+	format, err := parseFormat(req)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	counts, err := o.db.Stats()
+	if err != nil {
+		o.logger.Error("could not retrieve stats.", log.ErrKey, err)
+		logAndSendErr(resp, "Could not fetch stats.")
+		return
+	}
+	stats := statsV1{Stats: counts}
+	headBatch, err := o.db.HeadBatch()
+	if err != nil && !errors.Is(err, errutil.ErrNotFound) {
+		o.logger.Error("could not retrieve head batch.", log.ErrKey, err)
+		logAndSendErr(resp, "Could not fetch stats.")
+		return
+	}
+	if headBatch != nil {
+		stats.HeadBatchNumber = &headBatch.Number
+		stats.HeadBatchTime = &headBatch.Time
+	}
+
+	if format == formatJSON {
+		o.writeJSON(resp, stats, "Could not fetch stats.")
+		return
+	}
+	header := []string{"batches", "rollups", "blocks", "txs", "messages", "headBatchNumber", "headBatchTime"}
+	row := []string{
+		formatUint(counts.Batches), formatUint(counts.Rollups), formatUint(counts.Blocks), formatUint(counts.Txs),
+		formatUint(counts.Messages), "", "",
+	}
+	if headBatch != nil {
+		row[5], row[6] = formatUint(headBatch.Number), formatUint(headBatch.Time)
+	}
+	o.writeCSV(resp, "stats", header, [][]string{row})
+}
+
+// Returns a handler serving a page of the resource's items, most recent first.
+func (r v1Resource[T]) handle(o *Obscuroscan) http.HandlerFunc {
+	errMsg := fmt.Sprintf("Could not fetch %s.", r.name)
+	return func(resp http.ResponseWriter, req *http.Request) {
+		if httputil.EnableCORS(resp, req) {
+			return
+		}
+		format, err := parseFormat(req)
+		if err != nil {
+			http.Error(resp, err.Error(), http.StatusBadRequest)
+			return
+		}
+		itemRange, err := parseRange(req)
+		if err != nil {
+			http.Error(resp, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		items, err := r.query(itemRange)
+		if err != nil {
+			o.logger.Error(fmt.Sprintf("could not retrieve %s.", r.name), log.ErrKey, err)
+			logAndSendErr(resp, errMsg)
+			return
+		}
+		var nextCursor *string
+		if uint64(len(items)) == itemRange.Limit {
+			cursor := formatCursor(r.cursor(items[len(items)-1]))
+			nextCursor = &cursor
+		}
+
+		if format == formatJSON {
+			if items == nil {
+				items = []*T{}
+			}
+			o.writeJSON(resp, listResponse[T]{Items: items, NextCursor: nextCursor}, errMsg)
+			return
+		}
+		if nextCursor != nil {
+			resp.Header().Set(headerNextCursor, *nextCursor)
+		}
+		rows := make([][]string, len(items))
+		for idx, item := range items {
+			rows[idx] = r.csvRow(item)
+		}
+		o.writeCSV(resp, r.name, r.csvHeader, rows)
+	}
+}
+
+// Writes the rows to the client as a CSV file with the given name, preceded by the header.
+func (o *Obscuroscan) writeCSV(resp http.ResponseWriter, name string, header []string, rows [][]string) {
+	resp.Header().Set(headerContentType, contentTypeCSV)
+	resp.Header().Set(headerDisposition, fmt.Sprintf(csvFileNamePattern, name))
+	writer := csv.NewWriter(resp)
+	if err := writer.Write(header); err != nil {
+		o.logger.Error("could not return CSV response to client.", log.ErrKey, err)
+		return
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		o.logger.Error("could not return CSV response to client.", log.ErrKey, err)
+	}
+}
+
+// Returns the format requested via the format query parameter or the Accept header. Defaults to JSON.
+func parseFormat(req *http.Request) (string, error) {
+	switch format := req.URL.Query().Get(queryParamFormat); format {
+	case formatJSON, formatCSV:
+		return format, nil
+	case "":
+		if strings.Contains(req.Header.Get(headerAccept), contentTypeCSV) {
+			return formatCSV, nil
+		}
+		return formatJSON, nil
+	default:
+		return "", fmt.Errorf("format must be %s or %s", formatJSON, formatCSV)
+	}
+}
+
+// Parses the cursor, limit and number and time filters from the request's query parameters.
+func parseRange(req *http.Request) (indexer.Range, error) {
+	query := req.URL.Query()
+	itemRange := indexer.Range{Limit: defaultV1Limit}
+	if limit := query.Get(queryParamLimit); limit != "" {
+		var err error
+		if itemRange.Limit, err = strconv.ParseUint(limit, 10, 64); err != nil || itemRange.Limit == 0 || itemRange.Limit > maxV1Limit {
+			return itemRange, fmt.Errorf("limit must be between 1 and %d", maxV1Limit)
+		}
+	}
+	if cursor := query.Get(queryParamCursor); cursor != "" {
+		parsedCursor, err := parseCursor(cursor)
+		if err != nil {
+			return itemRange, err
+		}
+		itemRange.Cursor = parsedCursor
+	}
+
+	bounds := map[string]**uint64{
+		queryParamFromNumber: &itemRange.FromNumber,
+		queryParamToNumber:   &itemRange.ToNumber,
+		queryParamFromTime:   &itemRange.FromTime,
+		queryParamToTime:     &itemRange.ToTime,
+	}
+	for param, bound := range bounds {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		parsedValue, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return itemRange, fmt.Errorf("could not parse %s %s", param, value)
+		}
+		*bound = &parsedValue
+	}
+	return itemRange, nil
+}
+
+// Cursors are formatted as the item's number and hash, separated by an underscore.
+func formatCursor(cursor indexer.Cursor) string {
+	return formatUint(cursor.Number) + cursorSeparator + cursor.Hash.Hex()
+}
+
+func parseCursor(cursor string) (*indexer.Cursor, error) {
+	number, hash, found := strings.Cut(cursor, cursorSeparator)
+	if !found || len(hash) != 2+2*gethcommon.HashLength {
+		return nil, fmt.Errorf("could not parse cursor %s", cursor)
+	}
+	parsedNumber, err := strconv.ParseUint(number, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse cursor %s", cursor)
+	}
+	return &indexer.Cursor{Number: parsedNumber, Hash: gethcommon.HexToHash(hash)}, nil
+}
+
+func formatUint(value uint64) string {
+	return strconv.FormatUint(value, 10)
+}
@@ -0,0 +1,589 @@
+package indexer
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+	_ "github.com/mattn/go-sqlite3" // this imports the sqlite driver to make the sql.Open() connection work
+)
+
+const (
+	tempDirName = "obscuroscan"
+	dbFileName  = "obscuroscan.db"
+
+	createQry = `
+create table if not exists batch (
+	hash         binary(32) primary key,
+	number       integer not null unique,
+	parent_hash  binary(32) not null,
+	l1_proof     binary(32) not null,
+	time         integer not null,
+	tx_count     integer not null,
+	rollup_hash  binary(32),
+	ext_batch    mediumblob not null
+);
+create table if not exists tx (
+	hash         binary(32) primary key,
+	batch_hash   binary(32) not null,
+	batch_number integer not null
+);
+create index if not exists tx_batch_number on tx (batch_number);
+create table if not exists block (
+	hash         binary(32) primary key,
+	number       integer not null,
+	parent_hash  binary(32) not null,
+	time         integer not null
+);
+create index if not exists block_number on block (number);
+create table if not exists rollup (
+	hash            binary(32) primary key,
+	number          integer not null,
+	head_batch_hash binary(32) not null,
+	batch_count     integer not null,
+	l1_block_hash   binary(32) not null,
+	l1_block_number integer not null,
+	time            integer not null
+);
+create index if not exists rollup_number on rollup (number);
+create index if not exists rollup_l1_block on rollup (l1_block_hash);
+create table if not exists message (
+	hash              binary(32) primary key,
+	direction         varchar(16) not null,
+	sender            binary(20) not null,
+	sequence          integer not null,
+	nonce             integer not null,
+	topic             integer not null,
+	payload           blob not null,
+	consistency_level integer not null,
+	transfer_asset    binary(20),
+	transfer_amount   varchar(78),
+	transfer_receiver binary(20),
+	l1_block_hash     binary(32),
+	l1_block_number   integer,
+	l1_tx_hash        binary(32),
+	batch_hash        binary(32),
+	batch_number      integer,
+	rollup_hash       binary(32),
+	time_of_finality  integer,
+	synthetic_tx_hash binary(32)
+);
+create index if not exists message_sender on message (sender);
+create index if not exists message_receiver on message (transfer_receiver);
+create index if not exists message_l1_tx on message (l1_tx_hash);
+create index if not exists message_batch_number on message (batch_number);`
+
+	batchColumns  = "hash, number, parent_hash, l1_proof, time, tx_count, rollup_hash"
+	rollupColumns = "hash, number, head_batch_hash, batch_count, l1_block_hash, l1_block_number, time"
+	blockColumns  = "hash, number, parent_hash, time"
+	txColumns     = "hash, batch_hash, batch_number"
+)
+
+// Batch is the indexed summary of an L2 batch.
+type Batch struct {
+	Hash       gethcommon.Hash  `json:"hash"`
+	Number     uint64           `json:"number"`
+	ParentHash gethcommon.Hash  `json:"parentHash"`
+	L1Proof    gethcommon.Hash  `json:"l1Proof"`
+	Time       uint64           `json:"time"`
+	TxCount    uint64           `json:"txCount"`
+	RollupHash *gethcommon.Hash `json:"rollupHash"` // nil if the batch has not been seen in a rollup yet
+}
+
+// Rollup is the indexed summary of a rollup published to the L1.
+type Rollup struct {
+	Hash          gethcommon.Hash `json:"hash"`
+	Number        uint64          `json:"number"` // the number of the head batch in the rollup
+	HeadBatchHash gethcommon.Hash `json:"headBatchHash"`
+	BatchCount    uint64          `json:"batchCount"`
+	L1BlockHash   gethcommon.Hash `json:"l1BlockHash"`
+	L1BlockNumber uint64          `json:"l1BlockNumber"`
+	Time          uint64          `json:"time"`
+}
+
+// Block is the indexed summary of an L1 block.
+type Block struct {
+	Hash       gethcommon.Hash `json:"hash"`
+	Number     uint64          `json:"number"`
+	ParentHash gethcommon.Hash `json:"parentHash"`
+	Time       uint64          `json:"time"`
+}
+
+// Tx links the hash of an L2 transaction to the batch that included it.
+type Tx struct {
+	Hash        gethcommon.Hash `json:"hash"`
+	BatchHash   gethcommon.Hash `json:"batchHash"`
+	BatchNumber uint64          `json:"batchNumber"`
+}
+
+// DB is the SQLite database in which the indexer stores the data served by Obscuroscan.
+type DB struct {
+	db     *sql.DB
+	logger gethlog.Logger
+}
+
+// NewDB opens the SQLite database at the given path, creating it if needed. If dbPath is empty, a throwaway database
+// is created in a temporary directory.
+func NewDB(dbPath string, logger gethlog.Logger) (*DB, error) {
+	if dbPath == "" {
+		tempDir, err := os.MkdirTemp("", tempDirName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sqlite temp dir - %w", err)
+		}
+		dbPath = filepath.Join(tempDir, dbFileName)
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open sqlite db - %w", err)
+	}
+	// sqlite does not support concurrent writes, so we serialise all access through a single connection
+	db.SetMaxOpenConns(1)
+	if _, err = db.Exec(createQry); err != nil {
+		return nil, fmt.Errorf("failed to create sqlite db tables - %w", err)
+	}
+	logger.Info(fmt.Sprintf("Opened Obscuroscan sqlite db file at %s", dbPath))
+
+	return &DB{db: db, logger: logger}, nil
+}
+
+// Close closes the underlying database.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// AddBatch stores the batch, the hashes of its transactions and the cross-chain messages it publishes.
+func (d *DB) AddBatch(batch *common.ExtBatch) error {
+	encodedBatch, err := rlp.EncodeToBytes(batch)
+	if err != nil {
+		return fmt.Errorf("could not encode batch. Cause: %w", err)
+	}
+
+	dbTx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction. Cause: %w", err)
+	}
+	defer dbTx.Rollback() //nolint:errcheck
+
+	// A batch that is indexed again keeps its link to the rollup that published it.
+	number := batch.Header.Number.Uint64()
+	_, err = dbTx.Exec(
+		"insert into batch ("+batchColumns+", ext_batch) values (?, ?, ?, ?, ?, ?, null, ?) "+
+			"on conflict(hash) do update set number = excluded.number, parent_hash = excluded.parent_hash, "+
+			"l1_proof = excluded.l1_proof, time = excluded.time, tx_count = excluded.tx_count, ext_batch = excluded.ext_batch",
+		batch.Hash().Bytes(), number, batch.Header.ParentHash.Bytes(), batch.Header.L1Proof.Bytes(), batch.Header.Time, len(batch.TxHashes), encodedBatch,
+	)
+	if err != nil {
+		return fmt.Errorf("could not insert batch. Cause: %w", err)
+	}
+	for _, txHash := range batch.TxHashes {
+		_, err = dbTx.Exec("insert or replace into tx ("+txColumns+") values (?, ?, ?)", txHash.Bytes(), batch.Hash().Bytes(), number)
+		if err != nil {
+			return fmt.Errorf("could not insert transaction. Cause: %w", err)
+		}
+	}
+	if err = addWithdrawals(dbTx, batch); err != nil {
+		return err
+	}
+	return dbTx.Commit()
+}
+
+// DeleteBatchesFrom removes the batches with a number greater than or equal to the given number, and their
+// transactions. It is used when the indexed batches are no longer on the canonical chain.
+func (d *DB) DeleteBatchesFrom(number uint64) error {
+	dbTx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction. Cause: %w", err)
+	}
+	defer dbTx.Rollback() //nolint:errcheck
+
+	if _, err = dbTx.Exec("delete from tx where batch_number >= ?", number); err != nil {
+		return fmt.Errorf("could not delete transactions. Cause: %w", err)
+	}
+	if _, err = dbTx.Exec("delete from batch where number >= ?", number); err != nil {
+		return fmt.Errorf("could not delete batches. Cause: %w", err)
+	}
+	if err = unlinkMessages(dbTx, number); err != nil {
+		return err
+	}
+	return dbTx.Commit()
+}
+
+// AddBlock stores the L1 block.
+func (d *DB) AddBlock(block *Block) error {
+	_, err := d.db.Exec(
+		"insert or replace into block ("+blockColumns+") values (?, ?, ?, ?)",
+		block.Hash.Bytes(), block.Number, block.ParentHash.Bytes(), block.Time,
+	)
+	if err != nil {
+		return fmt.Errorf("could not insert block. Cause: %w", err)
+	}
+	return nil
+}
+
+// DeleteBlocksFrom removes the L1 blocks with a number greater than or equal to the given number, and the rollups
+// they contain. It is used when the indexed blocks were orphaned by an L1 reorg. The batches in the rollups are kept,
+// but are no longer linked to a rollup until they are seen in a rollup on the canonical chain.
+func (d *DB) DeleteBlocksFrom(number uint64) error {
+	dbTx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction. Cause: %w", err)
+	}
+	defer dbTx.Rollback() //nolint:errcheck
+
+	_, err = dbTx.Exec("update batch set rollup_hash = null where rollup_hash in (select hash from rollup where l1_block_number >= ?)", number)
+	if err != nil {
+		return fmt.Errorf("could not unlink batches from rollups. Cause: %w", err)
+	}
+	if err = unlinkL1Messages(dbTx, number); err != nil {
+		return err
+	}
+	if _, err = dbTx.Exec("delete from rollup where l1_block_number >= ?", number); err != nil {
+		return fmt.Errorf("could not delete rollups. Cause: %w", err)
+	}
+	if _, err = dbTx.Exec("delete from block where number >= ?", number); err != nil {
+		return fmt.Errorf("could not delete blocks. Cause: %w", err)
+	}
+	return dbTx.Commit()
+}
+
+// AddRollup stores the rollup and links the batches it contains to it.
+func (d *DB) AddRollup(rollup *Rollup, batchHashes []gethcommon.Hash) error {
+	dbTx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction. Cause: %w", err)
+	}
+	defer dbTx.Rollback() //nolint:errcheck
+
+	_, err = dbTx.Exec(
+		"insert or replace into rollup ("+rollupColumns+") values (?, ?, ?, ?, ?, ?, ?)",
+		rollup.Hash.Bytes(), rollup.Number, rollup.HeadBatchHash.Bytes(), rollup.BatchCount, rollup.L1BlockHash.Bytes(), rollup.L1BlockNumber, rollup.Time,
+	)
+	if err != nil {
+		return fmt.Errorf("could not insert rollup. Cause: %w", err)
+	}
+	for _, batchHash := range batchHashes {
+		if _, err = dbTx.Exec("update batch set rollup_hash = ? where hash = ?", rollup.Hash.Bytes(), batchHash.Bytes()); err != nil {
+			return fmt.Errorf("could not link batch to rollup. Cause: %w", err)
+		}
+	}
+	return dbTx.Commit()
+}
+
+// HeadBatch returns the indexed batch with the highest number.
+func (d *DB) HeadBatch() (*Batch, error) {
+	return d.queryBatch("select " + batchColumns + " from batch order by number desc limit 1")
+}
+
+// BatchByNumber returns the indexed batch with the given number.
+func (d *DB) BatchByNumber(number uint64) (*Batch, error) {
+	return d.queryBatch("select "+batchColumns+" from batch where number = ?", number)
+}
+
+// BatchByHash returns the indexed batch with the given hash.
+func (d *DB) BatchByHash(hash gethcommon.Hash) (*Batch, error) {
+	return d.queryBatch("select "+batchColumns+" from batch where hash = ?", hash.Bytes())
+}
+
+// BatchByTxHash returns the indexed batch that contains the transaction with the given hash.
+func (d *DB) BatchByTxHash(txHash gethcommon.Hash) (*Batch, error) {
+	return d.queryBatch(
+		"select "+prefixColumns("b", batchColumns)+" from batch b join tx t on t.batch_hash = b.hash where t.hash = ?",
+		txHash.Bytes(),
+	)
+}
+
+// ExtBatch returns the full external batch with the given hash, as it was retrieved from the node.
+func (d *DB) ExtBatch(hash gethcommon.Hash) (*common.ExtBatch, error) {
+	var encodedBatch []byte
+	err := d.db.QueryRow("select ext_batch from batch where hash = ?", hash.Bytes()).Scan(&encodedBatch)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errutil.ErrNotFound
+		}
+		return nil, fmt.Errorf("could not retrieve batch. Cause: %w", err)
+	}
+
+	batch := new(common.ExtBatch)
+	if err = rlp.DecodeBytes(encodedBatch, batch); err != nil {
+		return nil, fmt.Errorf("could not decode batch. Cause: %w", err)
+	}
+	return batch, nil
+}
+
+// Batches returns a page of indexed batches, most recent first.
+func (d *DB) Batches(page Page) ([]*Batch, error) {
+	return d.queryBatches("select "+batchColumns+" from batch order by number desc limit ? offset ?", page.Size, page.offset())
+}
+
+// RollupByHash returns the indexed rollup with the given hash.
+func (d *DB) RollupByHash(hash gethcommon.Hash) (*Rollup, error) {
+	rollups, err := d.queryRollups("select "+rollupColumns+" from rollup where hash = ?", hash.Bytes())
+	return first(rollups, err)
+}
+
+// RollupsByL1Block returns the indexed rollups published in the L1 block with the given hash.
+func (d *DB) RollupsByL1Block(blockHash gethcommon.Hash) ([]*Rollup, error) {
+	return d.queryRollups("select "+rollupColumns+" from rollup where l1_block_hash = ? order by number", blockHash.Bytes())
+}
+
+// Rollups returns a page of indexed rollups, most recent first.
+func (d *DB) Rollups(page Page) ([]*Rollup, error) {
+	return d.queryRollups("select "+rollupColumns+" from rollup order by number desc limit ? offset ?", page.Size, page.offset())
+}
+
+// BlockByHash returns the indexed L1 block with the given hash.
+func (d *DB) BlockByHash(hash gethcommon.Hash) (*Block, error) {
+	blocks, err := d.queryBlocks("select "+blockColumns+" from block where hash = ?", hash.Bytes())
+	return first(blocks, err)
+}
+
+// LowestBlock returns the indexed L1 block with the lowest number, where the indexed history starts.
+func (d *DB) LowestBlock() (*Block, error) {
+	blocks, err := d.queryBlocks("select " + blockColumns + " from block order by number limit 1")
+	return first(blocks, err)
+}
+
+// Blocks returns a page of indexed L1 blocks, most recent first.
+func (d *DB) Blocks(page Page) ([]*Block, error) {
+	return d.queryBlocks("select "+blockColumns+" from block order by number desc limit ? offset ?", page.Size, page.offset())
+}
+
+// TxByHash returns the indexed transaction with the given hash.
+func (d *DB) TxByHash(hash gethcommon.Hash) (*Tx, error) {
+	txs, err := d.queryTxs("select "+txColumns+" from tx where hash = ?", hash.Bytes())
+	return first(txs, err)
+}
+
+// Txs returns a page of indexed transactions, most recent first.
+func (d *DB) Txs(page Page) ([]*Tx, error) {
+	return d.queryTxs("select "+txColumns+" from tx order by batch_number desc, rowid desc limit ? offset ?", page.Size, page.offset())
+}
+
+// TxsInBatch returns the indexed transactions included in the batch with the given hash.
+func (d *DB) TxsInBatch(batchHash gethcommon.Hash) ([]*Tx, error) {
+	return d.queryTxs("select "+txColumns+" from tx where batch_hash = ? order by rowid", batchHash.Bytes())
+}
+
+// Stats returns the number of indexed items of each type.
+func (d *DB) Stats() (*Stats, error) {
+	stats := Stats{}
+	err := d.db.QueryRow(
+		"select (select count(*) from batch), (select count(*) from rollup), (select count(*) from block), (select count(*) from tx), "+
+			"(select count(*) from message)",
+	).Scan(&stats.Batches, &stats.Rollups, &stats.Blocks, &stats.Txs, &stats.Messages)
+	if err != nil {
+		return nil, fmt.Errorf("could not count indexed items. Cause: %w", err)
+	}
+	return &stats, nil
+}
+
+// Stats contains the number of indexed items of each type.
+type Stats struct {
+	Batches  uint64 `json:"batches"`
+	Rollups  uint64 `json:"rollups"`
+	Blocks   uint64 `json:"blocks"`
+	Txs      uint64 `json:"txs"`
+	Messages uint64 `json:"messages"`
+}
+
+func (d *DB) queryBatch(query string, args ...any) (*Batch, error) {
+	batches, err := d.queryBatches(query, args...)
+	return first(batches, err)
+}
+
+func (d *DB) queryBatches(query string, args ...any) ([]*Batch, error) {
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query batches. Cause: %w", err)
+	}
+	defer rows.Close()
+
+	var batches []*Batch
+	for rows.Next() {
+		var hash, parentHash, l1Proof, rollupHash []byte
+		batch := Batch{}
+		if err = rows.Scan(&hash, &batch.Number, &parentHash, &l1Proof, &batch.Time, &batch.TxCount, &rollupHash); err != nil {
+			return nil, fmt.Errorf("could not read batch. Cause: %w", err)
+		}
+		batch.Hash = gethcommon.BytesToHash(hash)
+		batch.ParentHash = gethcommon.BytesToHash(parentHash)
+		batch.L1Proof = gethcommon.BytesToHash(l1Proof)
+		if rollupHash != nil {
+			h := gethcommon.BytesToHash(rollupHash)
+			batch.RollupHash = &h
+		}
+		batches = append(batches, &batch)
+	}
+	return batches, rows.Err()
+}
+
+func (d *DB) queryRollups(query string, args ...any) ([]*Rollup, error) {
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query rollups. Cause: %w", err)
+	}
+	defer rows.Close()
+
+	var rollups []*Rollup
+	for rows.Next() {
+		var hash, headBatchHash, l1BlockHash []byte
+		rollup := Rollup{}
+		if err = rows.Scan(&hash, &rollup.Number, &headBatchHash, &rollup.BatchCount, &l1BlockHash, &rollup.L1BlockNumber, &rollup.Time); err != nil {
+			return nil, fmt.Errorf("could not read rollup. Cause: %w", err)
+		}
+		rollup.Hash = gethcommon.BytesToHash(hash)
+		rollup.HeadBatchHash = gethcommon.BytesToHash(headBatchHash)
+		rollup.L1BlockHash = gethcommon.BytesToHash(l1BlockHash)
+		rollups = append(rollups, &rollup)
+	}
+	return rollups, rows.Err()
+}
+
+func (d *DB) queryBlocks(query string, args ...any) ([]*Block, error) {
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query blocks. Cause: %w", err)
+	}
+	defer rows.Close()
+
+	var blocks []*Block
+	for rows.Next() {
+		var hash, parentHash []byte
+		block := Block{}
+		if err = rows.Scan(&hash, &block.Number, &parentHash, &block.Time); err != nil {
+			return nil, fmt.Errorf("could not read block. Cause: %w", err)
+		}
+		block.Hash = gethcommon.BytesToHash(hash)
+		block.ParentHash = gethcommon.BytesToHash(parentHash)
+		blocks = append(blocks, &block)
+	}
+	return blocks, rows.Err()
+}
+
+func (d *DB) queryTxs(query string, args ...any) ([]*Tx, error) {
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query transactions. Cause: %w", err)
+	}
+	defer rows.Close()
+
+	var txs []*Tx
+	for rows.Next() {
+		var hash, batchHash []byte
+		tx := Tx{}
+		if err = rows.Scan(&hash, &batchHash, &tx.BatchNumber); err != nil {
+			return nil, fmt.Errorf("could not read transaction. Cause: %w", err)
+		}
+		tx.Hash = gethcommon.BytesToHash(hash)
+		tx.BatchHash = gethcommon.BytesToHash(batchHash)
+		txs = append(txs, &tx)
+	}
+	return txs, rows.Err()
+}
+
+// first returns the first of the items, or errutil.ErrNotFound if there are none.
+func first[T any](items []*T, err error) (*T, error) {
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, errutil.ErrNotFound
+	}
+	return items[0], nil
+}
+
+// prefixColumns qualifies each of the comma-separated columns with the table alias.
+func prefixColumns(alias string, columns string) string {
+	qualified := strings.Split(columns, ", ")
+	for i, column := range qualified {
+		qualified[i] = alias + "." + column
+	}
+	return strings.Join(qualified, ", ")
+}
+
+// Page selects a page of results. Pages are numbered from zero.
+type Page struct {
+	Number uint64
+	Size   uint64
+}
+
+func (p Page) offset() uint64 {
+	return p.Number * p.Size
+}
+
+// Range selects items by number and time, most recent first. Items are ordered by number, then by hash, so that the
+// cursor of the last item of a page identifies where the next page starts even if several items share a number.
+type Range struct {
+	FromNumber *uint64 // inclusive; no lower bound if nil
+	ToNumber   *uint64 // inclusive; no upper bound if nil
+	FromTime   *uint64 // inclusive, in seconds since the epoch; no lower bound if nil
+	ToTime     *uint64 // inclusive, in seconds since the epoch; no upper bound if nil
+	Cursor     *Cursor // only items after the cursor are returned; results start from the most recent item if nil
+	Limit      uint64
+}
+
+// Cursor identifies the position of an item in a Range.
+type Cursor struct {
+	Number uint64
+	Hash   gethcommon.Hash
+}
+
+// BatchRange returns the indexed batches in the range.
+func (d *DB) BatchRange(r Range) ([]*Batch, error) {
+	where, args := r.clause()
+	return d.queryBatches("select "+batchColumns+" from batch"+where, args...)
+}
+
+// RollupRange returns the indexed rollups in the range. Rollups are numbered after their head batch.
+func (d *DB) RollupRange(r Range) ([]*Rollup, error) {
+	where, args := r.clause()
+	return d.queryRollups("select "+rollupColumns+" from rollup"+where, args...)
+}
+
+// BlockRange returns the indexed L1 blocks in the range.
+func (d *DB) BlockRange(r Range) ([]*Block, error) {
+	where, args := r.clause()
+	return d.queryBlocks("select "+blockColumns+" from block"+where, args...)
+}
+
+// Returns the where, order and limit clauses selecting the range from a table with number, hash and time columns.
+func (r Range) clause() (string, []any) {
+	var conditions []string
+	var args []any
+	if r.FromNumber != nil {
+		conditions = append(conditions, "number >= ?")
+		args = append(args, *r.FromNumber)
+	}
+	if r.ToNumber != nil {
+		conditions = append(conditions, "number <= ?")
+		args = append(args, *r.ToNumber)
+	}
+	if r.FromTime != nil {
+		conditions = append(conditions, "time >= ?")
+		args = append(args, *r.FromTime)
+	}
+	if r.ToTime != nil {
+		conditions = append(conditions, "time <= ?")
+		args = append(args, *r.ToTime)
+	}
+	if r.Cursor != nil {
+		conditions = append(conditions, "(number < ? or (number = ? and hash < ?))")
+		args = append(args, r.Cursor.Number, r.Cursor.Number, r.Cursor.Hash.Bytes())
+	}
+
+	clause := ""
+	if len(conditions) > 0 {
+		clause = " where " + strings.Join(conditions, " and ")
+	}
+	return clause + " order by number desc, hash desc limit ?", append(args, r.Limit)
+}
@@ -0,0 +1,130 @@
+package indexer
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+	"github.com/obscuronet/go-obscuro/go/common/log"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+func TestBatchesCanBeAddedAndDeleted(t *testing.T) {
+	db := newTestDB(t)
+	batch0 := newTestBatch(0, gethcommon.Hash{}, gethcommon.HexToHash("0xb0"), gethcommon.HexToHash("0xa0"))
+	batch1 := newTestBatch(1, batch0.Hash(), gethcommon.HexToHash("0xb0"), gethcommon.HexToHash("0xa1"))
+	for _, batch := range []*common.ExtBatch{batch0, batch1} {
+		if err := db.AddBatch(batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	head, err := db.HeadBatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Hash != batch1.Hash() || head.ParentHash != batch0.Hash() || head.TxCount != 1 {
+		t.Fatalf("unexpected head batch %+v", head)
+	}
+	containing, err := db.BatchByTxHash(gethcommon.HexToHash("0xa1"))
+	if err != nil || containing.Number != 1 {
+		t.Fatalf("expected transaction to be found in batch 1, got %+v. Cause: %s", containing, err)
+	}
+	extBatch, err := db.ExtBatch(batch1.Hash())
+	if err != nil || extBatch.Hash() != batch1.Hash() {
+		t.Fatalf("expected the full batch to be stored. Cause: %s", err)
+	}
+
+	if err = db.DeleteBatchesFrom(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.BatchByHash(batch1.Hash()); !errors.Is(err, errutil.ErrNotFound) {
+		t.Fatalf("expected deleted batch not to be found, got %s", err)
+	}
+	if _, err = db.TxByHash(gethcommon.HexToHash("0xa1")); !errors.Is(err, errutil.ErrNotFound) {
+		t.Fatalf("expected transaction of deleted batch not to be found, got %s", err)
+	}
+	if _, err = db.BatchByHash(batch0.Hash()); err != nil {
+		t.Fatalf("expected earlier batch to be kept. Cause: %s", err)
+	}
+}
+
+func TestDeleteBlocksFromRemovesOrphanedRollups(t *testing.T) {
+	db := newTestDB(t)
+	batch := newTestBatch(0, gethcommon.Hash{}, gethcommon.HexToHash("0xb0"))
+	if err := db.AddBatch(batch); err != nil {
+		t.Fatal(err)
+	}
+	blocks := []*Block{
+		{Hash: gethcommon.HexToHash("0xb0"), Number: 0},
+		{Hash: gethcommon.HexToHash("0xb1"), Number: 1, ParentHash: gethcommon.HexToHash("0xb0")},
+	}
+	for _, block := range blocks {
+		if err := db.AddBlock(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rollup := &Rollup{Hash: gethcommon.HexToHash("0xc1"), HeadBatchHash: batch.Hash(), BatchCount: 1, L1BlockHash: blocks[1].Hash, L1BlockNumber: 1}
+	if err := db.AddRollup(rollup, []gethcommon.Hash{batch.Hash()}); err != nil {
+		t.Fatal(err)
+	}
+	assertBatchRollup(t, db, batch.Hash(), &rollup.Hash)
+	// Indexing the batch again keeps its rollup.
+	if err := db.AddBatch(batch); err != nil {
+		t.Fatal(err)
+	}
+	assertBatchRollup(t, db, batch.Hash(), &rollup.Hash)
+
+	if err := db.DeleteBlocksFrom(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.BlockByHash(blocks[1].Hash); !errors.Is(err, errutil.ErrNotFound) {
+		t.Fatalf("expected orphaned block not to be found, got %s", err)
+	}
+	if _, err := db.RollupByHash(rollup.Hash); !errors.Is(err, errutil.ErrNotFound) {
+		t.Fatalf("expected rollup in orphaned block not to be found, got %s", err)
+	}
+	assertBatchRollup(t, db, batch.Hash(), nil)
+	lowest, err := db.LowestBlock()
+	if err != nil || lowest.Hash != blocks[0].Hash {
+		t.Fatalf("expected earlier block to be kept, got %+v. Cause: %s", lowest, err)
+	}
+}
+
+func newTestDB(t *testing.T) *DB {
+	db, err := NewDB("", log.New(log.TestLogCmp, int(gethlog.LvlError), log.SysOut))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newTestBatch(number int64, parentHash gethcommon.Hash, l1Proof gethcommon.Hash, txHashes ...gethcommon.Hash) *common.ExtBatch {
+	return &common.ExtBatch{
+		Header: &common.BatchHeader{
+			ParentHash: parentHash,
+			Number:     big.NewInt(number),
+			L1Proof:    l1Proof,
+			Time:       uint64(number),
+		},
+		TxHashes: txHashes,
+	}
+}
+
+func assertBatchRollup(t *testing.T, db *DB, batchHash gethcommon.Hash, rollupHash *gethcommon.Hash) {
+	batch, err := db.BatchByHash(batchHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case rollupHash == nil && batch.RollupHash != nil:
+		t.Fatalf("expected batch %d not to be linked to a rollup, got %s", batch.Number, batch.RollupHash)
+	case rollupHash != nil && (batch.RollupHash == nil || *batch.RollupHash != *rollupHash):
+		t.Fatalf("expected batch %d to be linked to rollup %s, got %v", batch.Number, rollupHash, batch.RollupHash)
+	}
+}
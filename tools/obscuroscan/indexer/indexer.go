@@ -0,0 +1,439 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/obscuronet/go-obscuro/contracts/generated/MessageBus"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/go/ethadapter"
+	"github.com/obscuronet/go-obscuro/go/ethadapter/mgmtcontractlib"
+	"github.com/obscuronet/go-obscuro/go/obsclient"
+	"github.com/obscuronet/go-obscuro/go/rpc"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// The maximum number of batches indexed in a single poll, so that a large backlog doesn't block shutdown.
+	maxBatchesPerPoll = 100
+	// The maximum number of ancestors of the first batch's L1 block that are scanned for rollups. Later batches are
+	// scanned back to the last indexed L1 block.
+	maxL1BlocksPerBatch = 64
+	// The maximum number of withdrawals whose time of finality is requested from the L1 in a single poll.
+	maxFinalityChecksPerPoll = 100
+	// The maximum number of deposits whose delivery is requested from the node in a single poll.
+	maxDeliveryChecksPerPoll = 100
+)
+
+// Indexer follows the batches of an Obscuro node, and the rollups published to the L1, and stores them in the
+// database. Obscuroscan serves its data from the database, so it remains available when the node is down.
+type Indexer struct {
+	db                 *DB
+	client             rpc.Client
+	obsClient          *obsclient.ObsClient
+	l1Client           ethadapter.EthClient // nil if rollups are not indexed
+	mgmtContractLib    mgmtcontractlib.MgmtContractLib
+	messageBusAddr     *gethcommon.Address // the address of the L1 message bus; nil if cross-chain messages are not indexed
+	messageBusCaller   *MessageBus.MessageBusCaller
+	messageBusFilterer *MessageBus.MessageBusFilterer
+	pollInterval       time.Duration
+	stopCh             chan struct{}
+	stopped            sync.WaitGroup
+	logger             gethlog.Logger
+}
+
+// NewIndexer returns an Indexer that follows the node via the client. If the L1 client is nil, only batches and their L1
+// blocks are indexed, and rollups are not. If the L1 client and the L1 message bus address are both set, the lifecycle of
+// cross-chain messages is indexed too.
+func NewIndexer(db *DB, client rpc.Client, l1Client ethadapter.EthClient, mgmtContractLib mgmtcontractlib.MgmtContractLib, messageBusAddr *gethcommon.Address, pollInterval time.Duration, logger gethlog.Logger) (*Indexer, error) {
+	indexer := &Indexer{
+		db:              db,
+		client:          client,
+		obsClient:       obsclient.NewObsClient(client),
+		l1Client:        l1Client,
+		mgmtContractLib: mgmtContractLib,
+		pollInterval:    pollInterval,
+		stopCh:          make(chan struct{}),
+		logger:          logger,
+	}
+
+	if l1Client != nil && messageBusAddr != nil {
+		var err error
+		indexer.messageBusAddr = messageBusAddr
+		indexer.messageBusCaller, err = MessageBus.NewMessageBusCaller(*messageBusAddr, l1Client.EthClient())
+		if err != nil {
+			return nil, fmt.Errorf("could not create L1 message bus caller. Cause: %w", err)
+		}
+		indexer.messageBusFilterer, err = MessageBus.NewMessageBusFilterer(*messageBusAddr, l1Client.EthClient())
+		if err != nil {
+			return nil, fmt.Errorf("could not create L1 message bus filterer. Cause: %w", err)
+		}
+	}
+	return indexer, nil
+}
+
+// Start indexes new batches every poll interval, until the indexer is stopped.
+func (i *Indexer) Start() {
+	i.stopped.Add(1)
+	go func() {
+		defer i.stopped.Done()
+		ticker := time.NewTicker(i.pollInterval)
+		defer ticker.Stop()
+		for {
+			// Errors are expected while the node is unavailable. We keep serving the indexed data and retry later.
+			if err := i.indexNewBatches(); err != nil {
+				i.logger.Warn("Could not index new batches.", log.ErrKey, err)
+			}
+			if err := i.indexDepositDeliveries(); err != nil {
+				i.logger.Warn("Could not index delivery of deposits.", log.ErrKey, err)
+			}
+			if err := i.indexWithdrawalFinality(); err != nil {
+				i.logger.Warn("Could not index finality of withdrawals.", log.ErrKey, err)
+			}
+			select {
+			case <-i.stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the indexer and waits for any in-progress poll to complete.
+func (i *Indexer) Stop() {
+	close(i.stopCh)
+	i.stopped.Wait()
+}
+
+// Indexes the batches between the last indexed batch and the node's head batch.
+func (i *Indexer) indexNewBatches() error {
+	headNumber, err := i.obsClient.RollupNumber()
+	if err != nil {
+		return fmt.Errorf("could not retrieve head batch number. Cause: %w", err)
+	}
+
+	nextNumber := uint64(0)
+	lastIndexed, err := i.db.HeadBatch()
+	if err != nil && !errors.Is(err, errutil.ErrNotFound) {
+		return fmt.Errorf("could not retrieve last indexed batch. Cause: %w", err)
+	}
+	if lastIndexed != nil {
+		nextNumber = lastIndexed.Number + 1
+	}
+
+	for indexed := 0; nextNumber <= headNumber && indexed < maxBatchesPerPoll; indexed++ {
+		select {
+		case <-i.stopCh:
+			return nil
+		default:
+		}
+
+		batch, err := i.fetchBatch(nextNumber)
+		if err != nil {
+			return err
+		}
+
+		// If the batch does not follow on from the last indexed batch, the indexed batch is no longer canonical and we
+		// step back to re-index it.
+		if lastIndexed != nil && lastIndexed.Number+1 == nextNumber && batch.Header.ParentHash != lastIndexed.Hash {
+			i.logger.Info(fmt.Sprintf("Indexed batch %d is not canonical. Re-indexing.", lastIndexed.Number))
+			if err = i.db.DeleteBatchesFrom(lastIndexed.Number); err != nil {
+				return err
+			}
+			nextNumber = lastIndexed.Number
+			if nextNumber == 0 {
+				lastIndexed = nil
+				continue
+			}
+			lastIndexed, err = i.db.BatchByNumber(nextNumber - 1)
+			if err != nil && !errors.Is(err, errutil.ErrNotFound) {
+				return err
+			}
+			continue
+		}
+
+		if err = i.db.AddBatch(batch); err != nil {
+			return fmt.Errorf("could not store batch %d. Cause: %w", nextNumber, err)
+		}
+		if err = i.indexL1Blocks(batch.Header.L1Proof); err != nil {
+			return fmt.Errorf("could not index L1 blocks for batch %d. Cause: %w", nextNumber, err)
+		}
+
+		lastIndexed = &Batch{Hash: batch.Hash(), Number: nextNumber}
+		nextNumber++
+	}
+	return nil
+}
+
+// Retrieves the batch with the given number from the node.
+func (i *Indexer) fetchBatch(number uint64) (*common.ExtBatch, error) {
+	header, err := i.obsClient.RollupHeaderByNumber(big.NewInt(int64(number)))
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve header for batch %d. Cause: %w", number, err)
+	}
+
+	var batch *common.ExtBatch
+	err = i.client.Call(&batch, rpc.GetBatch, header.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve batch %d. Cause: %w", number, err)
+	}
+	if batch == nil || batch.Header == nil {
+		return nil, fmt.Errorf("retrieved batch %d had a nil header", number)
+	}
+	return batch, nil
+}
+
+// Indexes the L1 block with the given hash. If an L1 client is configured, the block's unindexed ancestors are indexed
+// too, along with any rollups they contain. Indexed blocks that are not ancestors of the block were orphaned by an L1
+// reorg, and are removed along with their rollups.
+func (i *Indexer) indexL1Blocks(blockHash gethcommon.Hash) error {
+	if _, err := i.db.BlockByHash(blockHash); err == nil {
+		return nil
+	}
+
+	if i.l1Client == nil {
+		var header *types.Header
+		err := i.client.Call(&header, rpc.GetBlockHeaderByHash, blockHash)
+		if err != nil {
+			return fmt.Errorf("could not retrieve L1 block %s. Cause: %w", blockHash, err)
+		}
+		return i.db.AddBlock(toBlock(header))
+	}
+
+	unindexed, err := i.unindexedAncestors(blockHash)
+	if err != nil {
+		return err
+	}
+
+	// Any indexed block at the height of an unindexed ancestor is on an orphaned branch.
+	oldest := unindexed[len(unindexed)-1]
+	if err = i.db.DeleteBlocksFrom(oldest.number); err != nil {
+		return err
+	}
+
+	// We index the ancestors oldest first, so that a block is only indexed once its parent is.
+	for idx := len(unindexed) - 1; idx >= 0; idx-- {
+		block, err := i.l1Client.BlockByHash(unindexed[idx].hash)
+		if err != nil {
+			return fmt.Errorf("could not retrieve L1 block %s. Cause: %w", unindexed[idx].hash, err)
+		}
+		if err = i.indexRollups(block); err != nil {
+			return err
+		}
+		if err = i.indexDeposits(block); err != nil {
+			return err
+		}
+		if err = i.db.AddBlock(toBlock(block.Header())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The hash and number of an L1 block that has not been indexed yet.
+type unindexedBlock struct {
+	hash   gethcommon.Hash
+	number uint64
+}
+
+// Walks back from the block with the given hash until it reaches an indexed ancestor, and returns the unindexed blocks
+// in between, newest first. If no block has been indexed yet, the walk stops after maxL1BlocksPerBatch blocks, which
+// sets where the indexed history starts. Otherwise, the walk stops at the start of the indexed history, so that no block
+// is skipped however far behind the indexer is.
+func (i *Indexer) unindexedAncestors(blockHash gethcommon.Hash) ([]unindexedBlock, error) {
+	historyStart, err := i.db.LowestBlock()
+	if err != nil && !errors.Is(err, errutil.ErrNotFound) {
+		return nil, err
+	}
+
+	var unindexed []unindexedBlock
+	for hash := blockHash; ; {
+		block, err := i.l1Client.BlockByHash(hash)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve L1 block %s. Cause: %w", hash, err)
+		}
+		unindexed = append(unindexed, unindexedBlock{hash: hash, number: block.NumberU64()})
+
+		switch {
+		case block.NumberU64() == 0:
+			return unindexed, nil
+		case historyStart == nil && len(unindexed) >= maxL1BlocksPerBatch:
+			return unindexed, nil
+		case historyStart != nil && block.NumberU64() <= historyStart.Number:
+			return unindexed, nil
+		}
+		if _, err = i.db.BlockByHash(block.ParentHash()); err == nil {
+			return unindexed, nil
+		}
+		hash = block.ParentHash()
+	}
+}
+
+// Indexes the rollups published to the management contract in the given L1 block.
+func (i *Indexer) indexRollups(block *types.Block) error {
+	for _, tx := range block.Transactions() {
+		rollupTx, ok := i.mgmtContractLib.DecodeTx(tx).(*ethadapter.L1RollupTx)
+		if !ok || rollupTx == nil {
+			continue
+		}
+		rollup, err := common.DecodeRollup(rollupTx.Rollup)
+		if err != nil {
+			return fmt.Errorf("could not decode rollup in L1 transaction %s. Cause: %w", tx.Hash(), err)
+		}
+
+		batchHashes := make([]gethcommon.Hash, len(rollup.Batches))
+		for idx, batch := range rollup.Batches {
+			batchHashes[idx] = batch.Hash()
+		}
+		indexedRollup := &Rollup{
+			Hash:          rollup.Hash(),
+			Number:        rollup.Header.Number.Uint64(),
+			HeadBatchHash: rollup.Header.HeadBatchHash,
+			BatchCount:    uint64(len(rollup.Batches)),
+			L1BlockHash:   block.Hash(),
+			L1BlockNumber: block.NumberU64(),
+			Time:          block.Time(),
+		}
+		if err = i.db.AddRollup(indexedRollup, batchHashes); err != nil {
+			return fmt.Errorf("could not store rollup %s. Cause: %w", rollup.Hash(), err)
+		}
+
+		if i.messageBusAddr == nil {
+			continue
+		}
+		for _, crossChainMsg := range rollup.Header.CrossChainMessages {
+			msg, err := newMessage(crossChainMsg, DirectionWithdrawal)
+			if err != nil {
+				return err
+			}
+			if err = i.db.LinkWithdrawal(msg, indexedRollup, tx.Hash()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Indexes the messages published to the L1 message bus in the given L1 block.
+func (i *Indexer) indexDeposits(block *types.Block) error {
+	if i.messageBusAddr == nil {
+		return nil
+	}
+
+	blockHash := block.Hash()
+	logs, err := i.l1Client.EthClient().FilterLogs(context.Background(), ethereum.FilterQuery{
+		BlockHash: &blockHash,
+		Addresses: []gethcommon.Address{*i.messageBusAddr},
+		Topics:    [][]gethcommon.Hash{{crossChainEventID}},
+	})
+	if err != nil {
+		return fmt.Errorf("could not retrieve cross-chain messages for L1 block %s. Cause: %w", blockHash, err)
+	}
+
+	for _, l := range logs {
+		event, err := i.messageBusFilterer.ParseLogMessagePublished(l)
+		if err != nil {
+			return fmt.Errorf("could not parse cross-chain message in L1 transaction %s. Cause: %w", l.TxHash, err)
+		}
+		msg, err := newMessage(common.CrossChainMessage{
+			Sender:           event.Sender,
+			Sequence:         event.Sequence,
+			Nonce:            event.Nonce,
+			Topic:            event.Topic,
+			Payload:          event.Payload,
+			ConsistencyLevel: event.ConsistencyLevel,
+		}, DirectionDeposit)
+		if err != nil {
+			return err
+		}
+		if err = i.db.AddDeposits([]*Message{msg}, toBlock(block.Header()), l.TxHash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Links the deposits that have been stored on the L2 to the synthetic transactions that stored them. The node reports
+// the batch that delivered each message, which is only linked once it is indexed as part of the canonical chain.
+func (i *Indexer) indexDepositDeliveries() error {
+	if i.messageBusAddr == nil {
+		return nil
+	}
+	deposits, err := i.db.DepositsAwaitingDelivery(maxDeliveryChecksPerPoll)
+	if err != nil {
+		return err
+	}
+
+	for _, deposit := range deposits {
+		var delivery *common.InboundMessageDelivery
+		err = i.client.Call(&delivery, rpc.GetInboundDelivery, deposit.Sender, hexutil.Uint64(deposit.Sequence))
+		if err != nil {
+			return fmt.Errorf("could not retrieve delivery of deposit %s. Cause: %w", deposit.Hash, err)
+		}
+		// A message whose sequence number was delivered with other contents is a replay, which the L2 refuses.
+		if delivery == nil || delivery.Status != common.InboundMessageDelivered || delivery.MessageHash != deposit.Hash {
+			continue
+		}
+
+		batch, err := i.db.BatchByNumber(delivery.BatchNumber)
+		if err != nil {
+			if errors.Is(err, errutil.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		if batch.L1Proof != delivery.BatchL1Proof {
+			// The delivering batch has not been indexed yet, or the indexed batch is on a different fork.
+			continue
+		}
+		if err = i.db.LinkDeposit(deposit.Hash, batch, delivery.SyntheticTxHash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Retrieves the time of finality of the withdrawals that have been delivered to the L1 message bus.
+func (i *Indexer) indexWithdrawalFinality() error {
+	if i.messageBusAddr == nil {
+		return nil
+	}
+	withdrawals, err := i.db.WithdrawalsAwaitingFinality(maxFinalityChecksPerPoll)
+	if err != nil {
+		return err
+	}
+
+	for _, withdrawal := range withdrawals {
+		timeOfFinality, err := i.messageBusCaller.GetMessageTimeOfFinality(&bind.CallOpts{}, withdrawal.CrossChainMessage())
+		if err != nil {
+			// The call reverts if the message bus has not stored the message yet, so we retry on the next poll.
+			i.logger.Debug(fmt.Sprintf("Could not retrieve time of finality for withdrawal %s.", withdrawal.Hash), log.ErrKey, err)
+			continue
+		}
+		if err = i.db.SetTimeOfFinality(withdrawal.Hash, timeOfFinality.Uint64()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toBlock(header *types.Header) *Block {
+	return &Block{
+		Hash:       header.Hash(),
+		Number:     header.Number.Uint64(),
+		ParentHash: header.ParentHash,
+		Time:       header.Time,
+	}
+}
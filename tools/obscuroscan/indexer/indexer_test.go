@@ -0,0 +1,265 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/go/ethadapter"
+	"github.com/obscuronet/go-obscuro/go/ethadapter/mgmtcontractlib"
+	"github.com/obscuronet/go-obscuro/go/rpc"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestIndexerRemovesRollupsOrphanedByL1Reorg(t *testing.T) {
+	l1 := newFakeL1()
+	genesis := l1.addBlock(nil, "")
+	a1 := l1.addBlock(genesis, "a")
+	node := &fakeNode{}
+	batch0 := node.addBatch(genesis.Hash())
+	a2 := l1.addBlock(a1, "a", l1.rollupTx(batch0))
+	batch1 := node.addBatch(a2.Hash())
+
+	indexer := newTestIndexer(t, node, l1)
+	if err := indexer.indexNewBatches(); err != nil {
+		t.Fatal(err)
+	}
+	orphanedRollup, err := indexer.db.RollupsByL1Block(a2.Hash())
+	if err != nil || len(orphanedRollup) != 1 {
+		t.Fatalf("expected rollup to be indexed, got %d rollups. Cause: %s", len(orphanedRollup), err)
+	}
+	assertBatchRollup(t, indexer.db, batch0.Hash(), &orphanedRollup[0].Hash)
+
+	// The L1 reorgs onto a branch where the rollup is published later, and the node reorgs batch 1 onto that branch.
+	b1 := l1.addBlock(genesis, "b")
+	b2 := l1.addBlock(b1, "b")
+	node.batches = node.batches[:1]
+	reorgedBatch1 := node.addBatch(b2.Hash())
+	b3 := l1.addBlock(b2, "b", l1.rollupTx(batch0, reorgedBatch1))
+	node.addBatch(b3.Hash())
+	if err = indexer.indexNewBatches(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, orphaned := range []*types.Block{a1, a2} {
+		if _, err = indexer.db.BlockByHash(orphaned.Hash()); !errors.Is(err, errutil.ErrNotFound) {
+			t.Fatalf("expected orphaned block %d to be deleted, got %s", orphaned.NumberU64(), err)
+		}
+	}
+	if _, err = indexer.db.RollupByHash(orphanedRollup[0].Hash); !errors.Is(err, errutil.ErrNotFound) {
+		t.Fatalf("expected orphaned rollup to be deleted, got %s", err)
+	}
+	if _, err = indexer.db.BatchByHash(batch1.Hash()); !errors.Is(err, errutil.ErrNotFound) {
+		t.Fatalf("expected orphaned batch to be deleted, got %s", err)
+	}
+	canonicalRollup, err := indexer.db.RollupsByL1Block(b3.Hash())
+	if err != nil || len(canonicalRollup) != 1 {
+		t.Fatalf("expected rollup on the canonical branch to be indexed, got %d rollups. Cause: %s", len(canonicalRollup), err)
+	}
+	assertBatchRollup(t, indexer.db, batch0.Hash(), &canonicalRollup[0].Hash)
+	assertBatchRollup(t, indexer.db, reorgedBatch1.Hash(), &canonicalRollup[0].Hash)
+}
+
+func TestIndexerIndexesEveryL1BlockWhenBehind(t *testing.T) {
+	l1 := newFakeL1()
+	node := &fakeNode{}
+	block := l1.addBlock(nil, "")
+	node.addBatch(block.Hash())
+	for i := 0; i < 2*maxL1BlocksPerBatch; i++ {
+		block = l1.addBlock(block, "")
+	}
+	node.addBatch(block.Hash())
+
+	indexer := newTestIndexer(t, node, l1)
+	if err := indexer.indexNewBatches(); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := indexer.db.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Batches != 2 || stats.Blocks != 2*maxL1BlocksPerBatch+1 {
+		t.Fatalf("expected every batch and L1 block to be indexed, got %+v", stats)
+	}
+}
+
+func TestIndexerLinksDepositsToTheirSyntheticTransactions(t *testing.T) {
+	l1 := newFakeL1()
+	genesis := l1.addBlock(nil, "")
+	node := &fakeNode{deliveries: map[uint64]*common.InboundMessageDelivery{}}
+	node.addBatch(genesis.Hash())
+	batch1 := node.addBatch(genesis.Hash())
+
+	indexer := newTestIndexer(t, node, l1)
+	if err := indexer.indexNewBatches(); err != nil {
+		t.Fatal(err)
+	}
+	// We add the deposits directly, rather than reading them from the L1 message bus's events.
+	indexer.messageBusAddr = &gethcommon.Address{}
+	var deposits []*Message
+	for sequence := uint64(0); sequence < 3; sequence++ {
+		deposit, err := newMessage(common.CrossChainMessage{Sequence: sequence, Payload: []byte("payload")}, DirectionDeposit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		deposits = append(deposits, deposit)
+	}
+	if err := indexer.db.AddDeposits(deposits, toBlock(genesis.Header()), gethcommon.HexToHash("0xa1")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first deposit is delivered in batch 1, the second is refused as a replay, and the third was delivered by a
+	// batch that is not canonical.
+	syntheticTxHash := gethcommon.HexToHash("0xd1")
+	node.deliveries[0] = &common.InboundMessageDelivery{
+		MessageHash: deposits[0].Hash, Status: common.InboundMessageDelivered, SyntheticTxHash: syntheticTxHash,
+		BatchNumber: 1, BatchL1Proof: batch1.Header.L1Proof,
+	}
+	node.deliveries[1] = &common.InboundMessageDelivery{MessageHash: gethcommon.HexToHash("0xe1"), Status: common.InboundMessageDelivered, BatchNumber: 1, BatchL1Proof: batch1.Header.L1Proof}
+	node.deliveries[2] = &common.InboundMessageDelivery{MessageHash: deposits[2].Hash, Status: common.InboundMessageDelivered, BatchNumber: 0, BatchL1Proof: gethcommon.HexToHash("0xf1")}
+	if err := indexer.indexDepositDeliveries(); err != nil {
+		t.Fatal(err)
+	}
+
+	delivered := assertStatus(t, indexer.db, deposits[0].Hash, StatusFinal)
+	if *delivered.BatchHash != batch1.Hash() || *delivered.SyntheticTxHash != syntheticTxHash {
+		t.Fatalf("expected deposit to be linked to its synthetic transaction in batch 1, got %+v", delivered)
+	}
+	assertStatus(t, indexer.db, deposits[1].Hash, StatusPublished)
+	assertStatus(t, indexer.db, deposits[2].Hash, StatusPublished)
+}
+
+func newTestIndexer(t *testing.T, node *fakeNode, l1 *fakeL1) *Indexer {
+	indexer, err := NewIndexer(newTestDB(t), node, l1, &fakeMgmtContractLib{rollups: l1.rollups}, nil, time.Second, log.New(log.TestLogCmp, int(gethlog.LvlError), log.SysOut))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return indexer
+}
+
+// A node that serves the batches of its canonical chain.
+type fakeNode struct {
+	rpc.Client
+	batches    []*common.ExtBatch
+	deliveries map[uint64]*common.InboundMessageDelivery // by sequence number
+}
+
+func (n *fakeNode) addBatch(l1Proof gethcommon.Hash) *common.ExtBatch {
+	parentHash := gethcommon.Hash{}
+	if len(n.batches) > 0 {
+		parentHash = n.batches[len(n.batches)-1].Hash()
+	}
+	batch := newTestBatch(int64(len(n.batches)), parentHash, l1Proof)
+	n.batches = append(n.batches, batch)
+	return batch
+}
+
+func (n *fakeNode) Call(result interface{}, method string, args ...interface{}) error {
+	switch method {
+	case rpc.RollupNumber:
+		*result.(*hexutil.Uint64) = hexutil.Uint64(len(n.batches) - 1)
+	case rpc.GetRollupByNumber:
+		number, err := hexutil.DecodeUint64(args[0].(string))
+		if err != nil {
+			return err
+		}
+		*result.(**common.BatchHeader) = n.batches[number].Header
+	case rpc.GetInboundDelivery:
+		*result.(**common.InboundMessageDelivery) = n.deliveries[uint64(args[1].(hexutil.Uint64))]
+	case rpc.GetBatch:
+		for _, batch := range n.batches {
+			if batch.Hash() == args[0].(gethcommon.Hash) {
+				*result.(**common.ExtBatch) = batch
+			}
+		}
+	default:
+		return fmt.Errorf("unexpected method %s", method)
+	}
+	return nil
+}
+
+func (n *fakeNode) CallContext(_ context.Context, result interface{}, method string, args ...interface{}) error {
+	return n.Call(result, method, args...)
+}
+
+func (n *fakeNode) Subscribe(context.Context, interface{}, string, interface{}, ...interface{}) (*gethrpc.ClientSubscription, error) {
+	return nil, errors.New("subscriptions are not supported")
+}
+
+func (n *fakeNode) Stop() {}
+
+// The embedded field is named after the alias, so that it does not hide the interface's EthClient method.
+type ethClient = ethadapter.EthClient
+
+// An L1 node with several branches.
+type fakeL1 struct {
+	ethClient
+	blocks  map[gethcommon.Hash]*types.Block
+	rollups map[gethcommon.Hash]*ethadapter.L1RollupTx
+}
+
+func newFakeL1() *fakeL1 {
+	return &fakeL1{blocks: map[gethcommon.Hash]*types.Block{}, rollups: map[gethcommon.Hash]*ethadapter.L1RollupTx{}}
+}
+
+// Adds a block on top of the parent. The branch distinguishes blocks at the same height on different branches.
+func (l *fakeL1) addBlock(parent *types.Block, branch string, txs ...*types.Transaction) *types.Block {
+	header := &types.Header{Number: big.NewInt(0), Extra: []byte(branch)}
+	if parent != nil {
+		header.ParentHash = parent.Hash()
+		header.Number = new(big.Int).Add(parent.Number(), big.NewInt(1))
+	}
+	block := types.NewBlockWithHeader(header).WithBody(txs, nil)
+	l.blocks[block.Hash()] = block
+	return block
+}
+
+func (l *fakeL1) rollupTx(batches ...*common.ExtBatch) *types.Transaction {
+	head := batches[len(batches)-1]
+	rollup := &common.ExtRollup{
+		Header:  &common.RollupHeader{Number: head.Header.Number, HeadBatchHash: head.Hash()},
+		Batches: batches,
+	}
+	encoded, err := rlp.EncodeToBytes(rollup)
+	if err != nil {
+		panic(err)
+	}
+	tx := types.NewTx(&types.LegacyTx{Nonce: uint64(len(l.rollups)), Data: encoded})
+	l.rollups[tx.Hash()] = &ethadapter.L1RollupTx{Rollup: encoded}
+	return tx
+}
+
+func (l *fakeL1) BlockByHash(hash gethcommon.Hash) (*types.Block, error) {
+	block, found := l.blocks[hash]
+	if !found {
+		return nil, errutil.ErrNotFound
+	}
+	return block, nil
+}
+
+// Decodes the rollup transactions created by the fake L1.
+type fakeMgmtContractLib struct {
+	mgmtcontractlib.MgmtContractLib
+	rollups map[gethcommon.Hash]*ethadapter.L1RollupTx
+}
+
+func (m *fakeMgmtContractLib) DecodeTx(tx *types.Transaction) ethadapter.L1Transaction {
+	rollupTx, found := m.rollups[tx.Hash()]
+	if !found {
+		return nil
+	}
+	return rollupTx
+}
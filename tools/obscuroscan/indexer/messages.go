@@ -0,0 +1,465 @@
+package indexer
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/obscuronet/go-obscuro/contracts/generated/MessageBus"
+	"github.com/obscuronet/go-obscuro/contracts/generated/ObscuroBridge"
+	"github.com/obscuronet/go-obscuro/go/common"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// DirectionDeposit is the direction of messages published on the L1 and delivered to the L2.
+	DirectionDeposit = "deposit"
+	// DirectionWithdrawal is the direction of messages published on the L2 and delivered to the L1.
+	DirectionWithdrawal = "withdrawal"
+
+	// StatusPublished means the message has been published on the source chain, but not delivered yet.
+	StatusPublished = "published"
+	// StatusDelivered means the message has been stored on the destination chain, but its challenge period has not passed.
+	StatusDelivered = "delivered"
+	// StatusFinal means the message can be consumed on the destination chain.
+	StatusFinal = "final"
+
+	messageColumns = "hash, direction, sender, sequence, nonce, topic, payload, consistency_level, transfer_asset, " +
+		"transfer_amount, transfer_receiver, l1_block_hash, l1_block_number, l1_tx_hash, batch_hash, batch_number, " +
+		"rollup_hash, time_of_finality, synthetic_tx_hash"
+)
+
+var (
+	messageBusABI, _    = abi.JSON(strings.NewReader(MessageBus.MessageBusMetaData.ABI))
+	bridgeABI, _        = abi.JSON(strings.NewReader(ObscuroBridge.ObscuroBridgeMetaData.ABI))
+	crossChainEventID   = messageBusABI.Events["LogMessagePublished"].ID
+	crossChainCallArgs  = crossChainCallArguments()
+	receiveAssetsMethod = bridgeABI.Methods["receiveAssets"]
+)
+
+// Message is the indexed lifecycle of a cross-chain message.
+type Message struct {
+	Hash             gethcommon.Hash    `json:"hash"` // the hash under which the message bus stores the message
+	Direction        string             `json:"direction"`
+	Status           string             `json:"status"`
+	Sender           gethcommon.Address `json:"sender"`
+	Sequence         uint64             `json:"sequence"`
+	Nonce            uint32             `json:"nonce"`
+	Topic            uint32             `json:"topic"`
+	Payload          hexutil.Bytes      `json:"payload"`
+	ConsistencyLevel uint8              `json:"consistencyLevel"`
+	Transfer         *Transfer          `json:"transfer"`    // nil if the message is not a bridge transfer
+	L1BlockHash      *gethcommon.Hash   `json:"l1BlockHash"` // for deposits, the L1 block that published the message; for withdrawals, the L1 block that stored it
+	L1BlockNumber    *uint64            `json:"l1BlockNumber"`
+	L1TxHash         *gethcommon.Hash   `json:"l1TxHash"`  // for deposits, the publishing transaction; for withdrawals, the rollup transaction
+	BatchHash        *gethcommon.Hash   `json:"batchHash"` // for deposits, the batch that stored the message; for withdrawals, the batch that published it
+	BatchNumber      *uint64            `json:"batchNumber"`
+	RollupHash       *gethcommon.Hash   `json:"rollupHash"`     // for withdrawals, the rollup that delivered the message to the L1
+	TimeOfFinality   *uint64            `json:"timeOfFinality"` // the time after which the message can be consumed on the destination chain
+	crossChainMsg    *common.CrossChainMessage
+
+	// For deposits, the synthetic L2 transaction that stored the message on the L2 message bus.
+	SyntheticTxHash *gethcommon.Hash `json:"syntheticTxHash"`
+	// For transfers, the transfers of the same asset to the same account in the other direction. Only set for messages
+	// looked up by hash.
+	LinkedTransfers []gethcommon.Hash `json:"linkedTransfers"`
+}
+
+// Transfer is the decoded payload of a message sent by the bridge to move assets between chains.
+type Transfer struct {
+	Asset    gethcommon.Address `json:"asset"`
+	Amount   *hexutil.Big       `json:"amount"`
+	Receiver gethcommon.Address `json:"receiver"`
+}
+
+// The payload of messages sent through the cross-chain messenger.
+type crossChainCall struct {
+	Target gethcommon.Address
+	Data   []byte
+	Gas    *big.Int
+}
+
+// MessageFilter restricts the messages returned by DB.Messages.
+type MessageFilter struct {
+	Direction string              // the direction of the messages; all directions if empty
+	Address   *gethcommon.Address // the address that sent the message or received the transfer; all addresses if nil
+}
+
+// CrossChainMessage returns the message in the format used by the message bus.
+func (m *Message) CrossChainMessage() common.CrossChainMessage {
+	if m.crossChainMsg != nil {
+		return *m.crossChainMsg
+	}
+	return common.CrossChainMessage{
+		Sender:           m.Sender,
+		Sequence:         m.Sequence,
+		Nonce:            m.Nonce,
+		Topic:            m.Topic,
+		Payload:          m.Payload,
+		ConsistencyLevel: m.ConsistencyLevel,
+	}
+}
+
+// newMessage returns the indexed form of the cross-chain message, without any lifecycle information.
+func newMessage(msg common.CrossChainMessage, direction string) (*Message, error) {
+	hash, err := messageHash(msg)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{
+		Hash:             hash,
+		Direction:        direction,
+		Sender:           msg.Sender,
+		Sequence:         msg.Sequence,
+		Nonce:            msg.Nonce,
+		Topic:            msg.Topic,
+		Payload:          msg.Payload,
+		ConsistencyLevel: msg.ConsistencyLevel,
+		Transfer:         decodeTransfer(msg.Payload),
+		crossChainMsg:    &msg,
+	}, nil
+}
+
+// messageHash returns the hash under which the message bus stores the message, i.e. keccak256(abi.encode(message)).
+func messageHash(msg common.CrossChainMessage) (gethcommon.Hash, error) {
+	encoded, err := messageBusABI.Methods["getMessageTimeOfFinality"].Inputs.Pack(msg)
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("could not encode cross-chain message. Cause: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// decodeTransfer returns the transfer in the payload, or nil if the payload is not a bridge transfer. Bridge payloads are
+// encoded `CrossChainCall`s wrapping a call to `receiveAssets`.
+func decodeTransfer(payload []byte) *Transfer {
+	unpacked, err := crossChainCallArgs.Unpack(payload)
+	if err != nil || len(unpacked) != 1 {
+		return nil
+	}
+	call, ok := abi.ConvertType(unpacked[0], new(crossChainCall)).(*crossChainCall)
+	if !ok || len(call.Data) < 4 || !bytes.Equal(call.Data[:4], receiveAssetsMethod.ID) {
+		return nil
+	}
+
+	args, err := receiveAssetsMethod.Inputs.Unpack(call.Data[4:])
+	if err != nil || len(args) != 3 {
+		return nil
+	}
+	asset, okAsset := args[0].(gethcommon.Address)
+	amount, okAmount := args[1].(*big.Int)
+	receiver, okReceiver := args[2].(gethcommon.Address)
+	if !okAsset || !okAmount || !okReceiver {
+		return nil
+	}
+	return &Transfer{Asset: asset, Amount: (*hexutil.Big)(amount), Receiver: receiver}
+}
+
+func crossChainCallArguments() abi.Arguments {
+	crossChainCallType, err := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
+		{Name: "target", Type: "address"},
+		{Name: "data", Type: "bytes"},
+		{Name: "gas", Type: "uint256"},
+	})
+	if err != nil {
+		panic(fmt.Sprintf("could not create cross-chain call type. Cause: %s", err))
+	}
+	return abi.Arguments{{Type: crossChainCallType}}
+}
+
+// Stores the messages published to the L2 message bus by the batch, as part of the transaction that stores the batch.
+func addWithdrawals(dbTx *sql.Tx, batch *common.ExtBatch) error {
+	for _, crossChainMsg := range batch.Header.CrossChainMessages {
+		msg, err := newMessage(crossChainMsg, DirectionWithdrawal)
+		if err != nil {
+			return err
+		}
+		_, err = dbTx.Exec(
+			"insert into message ("+messageColumns+") values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, null, null, null, ?, ?, null, null, null) "+
+				"on conflict (hash) do update set batch_hash = excluded.batch_hash, batch_number = excluded.batch_number",
+			append(messageValues(msg), batch.Hash().Bytes(), batch.Header.Number.Uint64())...,
+		)
+		if err != nil {
+			return fmt.Errorf("could not insert withdrawal. Cause: %w", err)
+		}
+	}
+	return nil
+}
+
+// Unlinks the messages from the batches with a number greater than or equal to the given number, as part of the
+// transaction that deletes the batches. Withdrawals that have not been delivered to the L1 are deleted.
+func unlinkMessages(dbTx *sql.Tx, number uint64) error {
+	_, err := dbTx.Exec(
+		"update message set batch_hash = null, batch_number = null, time_of_finality = null, synthetic_tx_hash = null "+
+			"where direction = ? and batch_number >= ?",
+		DirectionDeposit, number,
+	)
+	if err != nil {
+		return fmt.Errorf("could not unlink deposits. Cause: %w", err)
+	}
+	_, err = dbTx.Exec("delete from message where direction = ? and batch_number >= ? and rollup_hash is null", DirectionWithdrawal, number)
+	if err != nil {
+		return fmt.Errorf("could not delete withdrawals. Cause: %w", err)
+	}
+	_, err = dbTx.Exec("update message set batch_hash = null, batch_number = null where direction = ? and batch_number >= ?", DirectionWithdrawal, number)
+	if err != nil {
+		return fmt.Errorf("could not unlink withdrawals. Cause: %w", err)
+	}
+	return nil
+}
+
+// Unlinks the withdrawals from the rollups in the L1 blocks with a number greater than or equal to the given number, and
+// deletes the deposits published in those blocks, as part of the transaction that deletes the blocks. Withdrawals are
+// delivered again by the rollups on the canonical chain, and deposits are indexed again from the canonical blocks.
+func unlinkL1Messages(dbTx *sql.Tx, number uint64) error {
+	_, err := dbTx.Exec("delete from message where direction = ? and l1_block_number >= ?", DirectionDeposit, number)
+	if err != nil {
+		return fmt.Errorf("could not delete deposits. Cause: %w", err)
+	}
+	_, err = dbTx.Exec(
+		"update message set l1_block_hash = null, l1_block_number = null, l1_tx_hash = null, rollup_hash = null, time_of_finality = null "+
+			"where direction = ? and l1_block_number >= ?",
+		DirectionWithdrawal, number,
+	)
+	if err != nil {
+		return fmt.Errorf("could not unlink withdrawals from rollups. Cause: %w", err)
+	}
+	return nil
+}
+
+// AddDeposits stores messages published to the L1 message bus in the given L1 transaction.
+func (d *DB) AddDeposits(messages []*Message, block *Block, txHash gethcommon.Hash) error {
+	for _, msg := range messages {
+		_, err := d.db.Exec(
+			"insert or ignore into message ("+messageColumns+") values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, null, null, null, null, null)",
+			append(messageValues(msg), block.Hash.Bytes(), block.Number, txHash.Bytes())...,
+		)
+		if err != nil {
+			return fmt.Errorf("could not insert deposit. Cause: %w", err)
+		}
+	}
+	return nil
+}
+
+// DepositsAwaitingDelivery returns up to the given number of deposits that have not been stored on the L2 yet, oldest
+// first.
+func (d *DB) DepositsAwaitingDelivery(limit uint64) ([]*Message, error) {
+	return d.queryMessages(
+		"select "+messageColumns+" from message where direction = ? and batch_hash is null order by l1_block_number, rowid limit ?",
+		DirectionDeposit, limit,
+	)
+}
+
+// LinkDeposit records that the deposit was stored on the L2 by the synthetic transaction in the batch. Mirroring the L2
+// message bus, a deposit is final once its consistency level in seconds has passed since the batch.
+func (d *DB) LinkDeposit(hash gethcommon.Hash, batch *Batch, syntheticTxHash gethcommon.Hash) error {
+	_, err := d.db.Exec(
+		"update message set batch_hash = ?, batch_number = ?, synthetic_tx_hash = ?, time_of_finality = ? + consistency_level "+
+			"where hash = ? and direction = ?",
+		batch.Hash.Bytes(), batch.Number, syntheticTxHash.Bytes(), batch.Time, hash.Bytes(), DirectionDeposit,
+	)
+	if err != nil {
+		return fmt.Errorf("could not link deposit to batch. Cause: %w", err)
+	}
+	return nil
+}
+
+// LinkWithdrawal records that the withdrawal was delivered to the L1 by the rollup in the given L1 transaction. If the
+// withdrawal has not been indexed yet, it is added.
+func (d *DB) LinkWithdrawal(msg *Message, rollup *Rollup, txHash gethcommon.Hash) error {
+	_, err := d.db.Exec(
+		"insert into message ("+messageColumns+") values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, null, null, ?, null, null) "+
+			"on conflict (hash) do update set l1_block_hash = excluded.l1_block_hash, l1_block_number = excluded.l1_block_number, "+
+			"l1_tx_hash = excluded.l1_tx_hash, rollup_hash = excluded.rollup_hash",
+		append(messageValues(msg), rollup.L1BlockHash.Bytes(), rollup.L1BlockNumber, txHash.Bytes(), rollup.Hash.Bytes())...,
+	)
+	if err != nil {
+		return fmt.Errorf("could not link withdrawal to rollup. Cause: %w", err)
+	}
+	return nil
+}
+
+// SetTimeOfFinality records the time after which the message can be consumed on the destination chain.
+func (d *DB) SetTimeOfFinality(hash gethcommon.Hash, timeOfFinality uint64) error {
+	if _, err := d.db.Exec("update message set time_of_finality = ? where hash = ?", timeOfFinality, hash.Bytes()); err != nil {
+		return fmt.Errorf("could not set time of finality. Cause: %w", err)
+	}
+	return nil
+}
+
+// WithdrawalsAwaitingFinality returns up to the given number of withdrawals that have been delivered to the L1, but whose
+// time of finality is not known yet.
+func (d *DB) WithdrawalsAwaitingFinality(limit uint64) ([]*Message, error) {
+	return d.queryMessages(
+		"select "+messageColumns+" from message where direction = ? and rollup_hash is not null and time_of_finality is null limit ?",
+		DirectionWithdrawal, limit,
+	)
+}
+
+// MessageByHash returns the indexed message with the given hash, along with its linked transfers.
+func (d *DB) MessageByHash(hash gethcommon.Hash) (*Message, error) {
+	messages, err := d.queryMessages("select "+messageColumns+" from message where hash = ?", hash.Bytes())
+	if err == nil {
+		err = d.addLinkedTransfers(messages)
+	}
+	return first(messages, err)
+}
+
+// MessagesByL1Tx returns the indexed messages published or delivered by the L1 transaction with the given hash, along
+// with their linked transfers.
+func (d *DB) MessagesByL1Tx(txHash gethcommon.Hash) ([]*Message, error) {
+	messages, err := d.queryMessages("select "+messageColumns+" from message where l1_tx_hash = ? order by rowid", txHash.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return messages, d.addLinkedTransfers(messages)
+}
+
+// Links each transfer to the transfers of the same asset to the same account in the other direction. Both bridges name
+// the asset by its L1 address, so a deposit and the withdrawals that return its funds name the same asset. The bridges
+// do not record which deposit funded a withdrawal, so every transfer between the account and the bridge is linked.
+func (d *DB) addLinkedTransfers(messages []*Message) error {
+	for _, msg := range messages {
+		if msg.Transfer == nil {
+			continue
+		}
+		linked, err := d.linkedTransfers(msg)
+		if err != nil {
+			return err
+		}
+		msg.LinkedTransfers = linked
+	}
+	return nil
+}
+
+func (d *DB) linkedTransfers(msg *Message) ([]gethcommon.Hash, error) {
+	rows, err := d.db.Query(
+		"select hash from message where direction != ? and transfer_asset = ? and transfer_receiver = ? order by rowid",
+		msg.Direction, msg.Transfer.Asset.Bytes(), msg.Transfer.Receiver.Bytes(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query linked transfers. Cause: %w", err)
+	}
+	defer rows.Close()
+
+	var linked []gethcommon.Hash
+	for rows.Next() {
+		var hash []byte
+		if err = rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("could not read linked transfer. Cause: %w", err)
+		}
+		linked = append(linked, gethcommon.BytesToHash(hash))
+	}
+	return linked, rows.Err()
+}
+
+// Messages returns a page of indexed messages matching the filter, most recent first.
+func (d *DB) Messages(filter MessageFilter, page Page) ([]*Message, error) {
+	query := "select " + messageColumns + " from message where 1 = 1"
+	var args []any
+	if filter.Direction != "" {
+		query += " and direction = ?"
+		args = append(args, filter.Direction)
+	}
+	if filter.Address != nil {
+		query += " and (sender = ? or transfer_receiver = ?)"
+		args = append(args, filter.Address.Bytes(), filter.Address.Bytes())
+	}
+	query += " order by rowid desc limit ? offset ?"
+	return d.queryMessages(query, append(args, page.Size, page.offset())...)
+}
+
+func (d *DB) queryMessages(query string, args ...any) ([]*Message, error) {
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query messages. Cause: %w", err)
+	}
+	defer rows.Close()
+
+	now := uint64(time.Now().Unix())
+	var messages []*Message
+	for rows.Next() {
+		var hash, sender, asset, receiver, l1BlockHash, l1TxHash, batchHash, rollupHash, syntheticTxHash []byte
+		var amount sql.NullString
+		var l1BlockNumber, batchNumber, timeOfFinality sql.NullInt64
+		msg := Message{}
+		err = rows.Scan(
+			&hash, &msg.Direction, &sender, &msg.Sequence, &msg.Nonce, &msg.Topic, &msg.Payload, &msg.ConsistencyLevel,
+			&asset, &amount, &receiver, &l1BlockHash, &l1BlockNumber, &l1TxHash, &batchHash, &batchNumber, &rollupHash,
+			&timeOfFinality, &syntheticTxHash,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("could not read message. Cause: %w", err)
+		}
+		msg.Hash = gethcommon.BytesToHash(hash)
+		msg.Sender = gethcommon.BytesToAddress(sender)
+		if amount.Valid {
+			transferAmount, _ := new(big.Int).SetString(amount.String, 10)
+			msg.Transfer = &Transfer{
+				Asset:    gethcommon.BytesToAddress(asset),
+				Amount:   (*hexutil.Big)(transferAmount),
+				Receiver: gethcommon.BytesToAddress(receiver),
+			}
+		}
+		msg.L1BlockHash = nullableHash(l1BlockHash)
+		msg.L1BlockNumber = nullableUint(l1BlockNumber)
+		msg.L1TxHash = nullableHash(l1TxHash)
+		msg.BatchHash = nullableHash(batchHash)
+		msg.BatchNumber = nullableUint(batchNumber)
+		msg.RollupHash = nullableHash(rollupHash)
+		msg.TimeOfFinality = nullableUint(timeOfFinality)
+		msg.SyntheticTxHash = nullableHash(syntheticTxHash)
+		msg.Status = messageStatus(&msg, now)
+		messages = append(messages, &msg)
+	}
+	return messages, rows.Err()
+}
+
+// Returns the values of the message's own columns, up to and including the transfer.
+func messageValues(msg *Message) []any {
+	values := []any{
+		msg.Hash.Bytes(), msg.Direction, msg.Sender.Bytes(), msg.Sequence, msg.Nonce, msg.Topic, []byte(msg.Payload),
+		msg.ConsistencyLevel,
+	}
+	if msg.Transfer == nil {
+		return append(values, nil, nil, nil)
+	}
+	return append(values, msg.Transfer.Asset.Bytes(), msg.Transfer.Amount.ToInt().String(), msg.Transfer.Receiver.Bytes())
+}
+
+func messageStatus(msg *Message, now uint64) string {
+	delivered := msg.BatchHash != nil
+	if msg.Direction == DirectionWithdrawal {
+		delivered = msg.RollupHash != nil
+	}
+	switch {
+	case !delivered:
+		return StatusPublished
+	case msg.TimeOfFinality != nil && *msg.TimeOfFinality <= now:
+		return StatusFinal
+	default:
+		return StatusDelivered
+	}
+}
+
+func nullableHash(value []byte) *gethcommon.Hash {
+	if value == nil {
+		return nil
+	}
+	hash := gethcommon.BytesToHash(value)
+	return &hash
+}
+
+func nullableUint(value sql.NullInt64) *uint64 {
+	if !value.Valid {
+		return nil
+	}
+	number := uint64(value.Int64)
+	return &number
+}
@@ -0,0 +1,133 @@
+package indexer
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	asset    = gethcommon.HexToAddress("0x1")
+	receiver = gethcommon.HexToAddress("0x2")
+	amount   = big.NewInt(1000)
+)
+
+func TestDecodeTransfer(t *testing.T) {
+	transfer := decodeTransfer(bridgePayload(t))
+	if transfer == nil {
+		t.Fatal("expected bridge payload to be decoded as a transfer")
+	}
+	if transfer.Asset != asset || transfer.Receiver != receiver || transfer.Amount.ToInt().Cmp(amount) != 0 {
+		t.Fatalf("decoded unexpected transfer %+v", transfer)
+	}
+
+	if decodeTransfer([]byte("not a transfer")) != nil {
+		t.Fatal("expected arbitrary payload not to be decoded as a transfer")
+	}
+}
+
+func TestDepositLifecycle(t *testing.T) {
+	db := newTestDB(t)
+	msg, err := newMessage(common.CrossChainMessage{Sender: receiver, Payload: bridgePayload(t), ConsistencyLevel: 10}, DirectionDeposit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := &Block{Hash: gethcommon.HexToHash("0xb1"), Number: 5}
+	if err = db.AddDeposits([]*Message{msg}, block, gethcommon.HexToHash("0xa1")); err != nil {
+		t.Fatal(err)
+	}
+	assertStatus(t, db, msg.Hash, StatusPublished)
+	awaiting, err := db.DepositsAwaitingDelivery(10)
+	if err != nil || len(awaiting) != 1 || awaiting[0].Hash != msg.Hash {
+		t.Fatalf("expected the deposit to await delivery, got %d deposits. Cause: %s", len(awaiting), err)
+	}
+
+	syntheticTxHash := gethcommon.HexToHash("0xd1")
+	if err = db.LinkDeposit(msg.Hash, &Batch{Hash: gethcommon.HexToHash("0xc2"), Number: 2, Time: 100}, syntheticTxHash); err != nil {
+		t.Fatal(err)
+	}
+	stored := assertStatus(t, db, msg.Hash, StatusFinal)
+	if *stored.BatchNumber != 2 || *stored.TimeOfFinality != 110 || *stored.SyntheticTxHash != syntheticTxHash {
+		t.Fatalf("deposit linked to unexpected batch %d with finality %d", *stored.BatchNumber, *stored.TimeOfFinality)
+	}
+
+	messages, err := db.Messages(MessageFilter{Direction: DirectionDeposit, Address: &receiver}, Page{Number: 0, Size: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].Hash != msg.Hash {
+		t.Fatalf("expected the deposit to be returned for its receiver, got %d messages", len(messages))
+	}
+
+	// The deposit is removed if the L1 block that published it is orphaned.
+	if err = db.DeleteBlocksFrom(block.Number); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.MessageByHash(msg.Hash); !errors.Is(err, errutil.ErrNotFound) {
+		t.Fatalf("expected deposit in orphaned block to be deleted, got %s", err)
+	}
+}
+
+func TestTransfersAreLinkedToTransfersInTheOtherDirection(t *testing.T) {
+	db := newTestDB(t)
+	deposit, err := newMessage(common.CrossChainMessage{Sender: receiver, Payload: bridgePayload(t)}, DirectionDeposit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AddDeposits([]*Message{deposit}, &Block{Hash: gethcommon.HexToHash("0xb1"), Number: 5}, gethcommon.HexToHash("0xa1")); err != nil {
+		t.Fatal(err)
+	}
+	withdrawal := common.CrossChainMessage{Sender: asset, Sequence: 1, Payload: bridgePayload(t)}
+	batch := newTestBatch(0, gethcommon.Hash{}, gethcommon.HexToHash("0xb1"))
+	batch.Header.CrossChainMessages = common.CrossChainMessages{withdrawal}
+	if err = db.AddBatch(batch); err != nil {
+		t.Fatal(err)
+	}
+	withdrawalHash, err := messageHash(withdrawal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := db.MessageByHash(deposit.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.LinkedTransfers) != 1 || stored.LinkedTransfers[0] != withdrawalHash {
+		t.Fatalf("expected the deposit to be linked to the withdrawal, got %v", stored.LinkedTransfers)
+	}
+	stored, err = db.MessageByHash(withdrawalHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.LinkedTransfers) != 1 || stored.LinkedTransfers[0] != deposit.Hash {
+		t.Fatalf("expected the withdrawal to be linked to the deposit, got %v", stored.LinkedTransfers)
+	}
+}
+
+func assertStatus(t *testing.T, db *DB, hash gethcommon.Hash, status string) *Message {
+	msg, err := db.MessageByHash(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Status != status {
+		t.Fatalf("expected message status %s, got %s", status, msg.Status)
+	}
+	return msg
+}
+
+func bridgePayload(t *testing.T) []byte {
+	args, err := receiveAssetsMethod.Inputs.Pack(asset, amount, receiver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := crossChainCallArgs.Pack(crossChainCall{Target: receiver, Data: append(receiveAssetsMethod.ID, args...), Gas: big.NewInt(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
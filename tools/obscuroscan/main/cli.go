@@ -2,10 +2,20 @@ package main
 
 import (
 	"flag"
+	"os"
+	"time"
+
+	gethlog "github.com/ethereum/go-ethereum/log"
+	"github.com/obscuronet/go-obscuro/go/config"
 )
 
 const (
+	// The prefix of the names of the environment variables that set the config, e.g. OBSCUROSCAN_POLL_INTERVAL.
+	envPrefix = "OBSCUROSCAN"
+
 	// Flag names and usages.
+	configUsage = "The path to Obscuroscan's .toml config file. Environment variables and flags override its values"
+
 	nodeIDName  = "nodeID"
 	nodeIDUsage = "The 20 bytes of the node's address"
 
@@ -17,38 +27,119 @@ const (
 
 	logPathName  = "logPath"
 	logPathUsage = "The path to use for Obscuroscan's log file"
+
+	logLevelName  = "logLevel"
+	logLevelUsage = "The verbosity level of logs. (Defaults to Info)"
+
+	dbPathName  = "dbPath"
+	dbPathUsage = "The path to use for Obscuroscan's database. A temporary database is used if empty"
+
+	l1NodeHostName  = "l1NodeHost"
+	l1NodeHostUsage = "The host of the L1 node used to index rollups. Rollups are not indexed if empty"
+
+	l1NodePortName  = "l1NodePort"
+	l1NodePortUsage = "The websocket port of the L1 node used to index rollups"
+
+	mgmtContractAddrName  = "managementContractAddress"
+	mgmtContractAddrUsage = "The address of the management contract that rollups are published to"
+
+	messageBusAddrName  = "messageBusAddress"
+	messageBusAddrUsage = "The address of the L1 message bus. Cross-chain messages are not indexed if empty"
+
+	pollIntervalName  = "pollInterval"
+	pollIntervalUsage = "How often to poll the node for new batches (e.g. 500ms)"
+
+	enclaveUniqueIDName  = "enclaveUniqueID"
+	enclaveUniqueIDUsage = "The hex-encoded unique ID (MRENCLAVE) the node's enclave must attest to"
+
+	enclaveSignerIDName  = "enclaveSignerID"
+	enclaveSignerIDUsage = "The hex-encoded signer ID (MRSIGNER) the node's enclave must attest to. Requires enclaveProductID"
+
+	enclaveProductIDName  = "enclaveProductID"
+	enclaveProductIDUsage = "The product ID the node's enclave must attest to"
+
+	enclaveSecurityVersionName  = "enclaveSecurityVersion"
+	enclaveSecurityVersionUsage = "The minimum security version the node's enclave must attest to"
+
+	enclaveAllowDebugName  = "enclaveAllowDebug"
+	enclaveAllowDebugUsage = "Whether to accept attestations from debug enclaves"
+
+	enclaveTCBStatusesName  = "enclaveTCBStatuses"
+	enclaveTCBStatusesUsage = "The TCB statuses of the node's enclave that are accepted in addition to UpToDate, as a comma-separated list (e.g. SWHardeningNeeded)"
+
+	insecureSkipAttestationName  = "insecureSkipAttestation"
+	insecureSkipAttestationUsage = "Trust the node's enclave key without verifying its attestation. Only for dev networks that run without SGX"
 )
 
+// obscuroscanConfig is the structure that Obscuroscan's config is loaded into, from its .toml config file, environment
+// variables and flags. See config.Loader for the meaning of the struct tags.
 type obscuroscanConfig struct {
-	nodeID        string
-	rpcServerAddr string
-	address       string
-	logPath       string
+	NodeID                    string `flag:"nodeID" validate:"address"`
+	RPCServerAddress          string `flag:"rpcServerAddress" validate:"required"`
+	Address                   string `flag:"address" validate:"required,hostport"`
+	LogPath                   string `flag:"logPath"`
+	LogLevel                  int    `flag:"logLevel" validate:"min=0,max=5" reload:"true"`
+	DBPath                    string `flag:"dbPath"`
+	L1NodeHost                string `flag:"l1NodeHost"`
+	L1NodePort                uint   `flag:"l1NodePort" validate:"port"`
+	ManagementContractAddress string `flag:"managementContractAddress" validate:"address"`
+	MessageBusAddress         string `flag:"messageBusAddress" validate:"address"`
+	PollInterval              string `flag:"pollInterval" validate:"required,duration"`
+
+	EnclaveUniqueID         string   `flag:"enclaveUniqueID" validate:"hex32"`
+	EnclaveSignerID         string   `flag:"enclaveSignerID" validate:"hex32"`
+	EnclaveProductID        uint16   `flag:"enclaveProductID"`
+	EnclaveSecurityVersion  uint     `flag:"enclaveSecurityVersion"`
+	EnclaveAllowDebug       bool     `flag:"enclaveAllowDebug"`
+	EnclaveTCBStatuses      []string `flag:"enclaveTCBStatuses"`
+	InsecureSkipAttestation bool     `flag:"insecureSkipAttestation"`
 }
 
 func defaultObscuroClientConfig() obscuroscanConfig {
 	return obscuroscanConfig{
-		nodeID:        "",
-		rpcServerAddr: "http://testnet.obscu.ro:13000",
-		address:       "127.0.0.1:3000",
-		logPath:       "obscuroscan_logs.txt",
+		NodeID:                    "",
+		RPCServerAddress:          "http://testnet.obscu.ro:13000",
+		Address:                   "127.0.0.1:3000",
+		LogPath:                   "obscuroscan_logs.txt",
+		LogLevel:                  int(gethlog.LvlInfo),
+		DBPath:                    "obscuroscan.db",
+		L1NodeHost:                "",
+		L1NodePort:                9000,
+		ManagementContractAddress: "",
+		MessageBusAddress:         "",
+		PollInterval:              time.Second.String(),
 	}
 }
 
-func parseCLIArgs() obscuroscanConfig {
-	defaultConfig := defaultObscuroClientConfig()
-
-	nodeID := flag.String(nodeIDName, defaultConfig.nodeID, nodeIDUsage)
-	rpcServerAddr := flag.String(rpcServerAddrName, defaultConfig.rpcServerAddr, rpcServerAddrUsage)
-	address := flag.String(addressName, defaultConfig.address, addressUsage)
-	logPath := flag.String(logPathName, defaultConfig.logPath, logPathUsage)
-
-	flag.Parse()
+// Loads Obscuroscan's config from, in increasing order of precedence, the defaults, the file identified by the `config`
+// flag, the environment variables and the flags. The returned loader is used to reload the config.
+func parseCLIArgs() (obscuroscanConfig, *config.Loader, error) {
+	cfg := defaultObscuroClientConfig()
+	usages := map[string]string{
+		config.ConfigFlagName: configUsage,
+		nodeIDName:            nodeIDUsage,
+		rpcServerAddrName:     rpcServerAddrUsage,
+		addressName:           addressUsage,
+		logPathName:           logPathUsage,
+		logLevelName:          logLevelUsage,
+		dbPathName:            dbPathUsage,
+		l1NodeHostName:        l1NodeHostUsage,
+		l1NodePortName:        l1NodePortUsage,
+		mgmtContractAddrName:  mgmtContractAddrUsage,
+		messageBusAddrName:    messageBusAddrUsage,
+		pollIntervalName:      pollIntervalUsage,
 
-	return obscuroscanConfig{
-		nodeID:        *nodeID,
-		rpcServerAddr: *rpcServerAddr,
-		address:       *address,
-		logPath:       *logPath,
+		enclaveUniqueIDName:         enclaveUniqueIDUsage,
+		enclaveSignerIDName:         enclaveSignerIDUsage,
+		enclaveProductIDName:        enclaveProductIDUsage,
+		enclaveSecurityVersionName:  enclaveSecurityVersionUsage,
+		enclaveAllowDebugName:       enclaveAllowDebugUsage,
+		enclaveTCBStatusesName:      enclaveTCBStatusesUsage,
+		insecureSkipAttestationName: insecureSkipAttestationUsage,
+	}
+	loader := config.NewLoader(&cfg, flag.CommandLine, envPrefix, usages)
+	if err := loader.Load(os.Args[1:]); err != nil {
+		return obscuroscanConfig{}, nil, err
 	}
+	return cfg, loader, nil
 }
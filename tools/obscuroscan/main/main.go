@@ -2,22 +2,59 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"time"
 
-	gethlog "github.com/ethereum/go-ethereum/log"
+	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/go/rpc"
 
 	"github.com/obscuronet/go-obscuro/tools/obscuroscan"
 )
 
 func main() {
-	config := parseCLIArgs()
+	cfg, loader, err := parseCLIArgs()
+	if err != nil {
+		fmt.Printf("Could not load config. Cause: %s\n", err)
+		os.Exit(1)
+	}
+	attestationPolicy, err := rpc.ParseAttestationPolicy([]string{cfg.EnclaveUniqueID}, []string{cfg.EnclaveSignerID},
+		cfg.EnclaveProductID, uint64(cfg.EnclaveSecurityVersion), cfg.EnclaveAllowDebug, cfg.EnclaveTCBStatuses)
+	if err != nil {
+		fmt.Printf("Could not load config. Cause: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(loader.Effective())
+	// The poll interval was validated when the config was loaded.
+	pollInterval, _ := time.ParseDuration(cfg.PollInterval)
+
+	logger := log.New(log.ObscuroscanCmp, cfg.LogLevel, cfg.LogPath)
+	stopConfigWatch := loader.WatchReload(logger, func(schema interface{}) {
+		if err := log.SetLevel(logger, schema.(*obscuroscanConfig).LogLevel); err != nil {
+			logger.Error("Could not change the log level.", log.ErrKey, err)
+		}
+	})
+	defer stopConfigWatch()
 
 	server := obscuroscan.NewObscuroscan(
-		config.rpcServerAddr,
-		log.New(log.ObscuroscanCmp, int(gethlog.LvlInfo), config.logPath),
+		obscuroscan.Config{
+			RPCServerAddress: cfg.RPCServerAddress,
+			DBPath:           cfg.DBPath,
+			L1NodeHost:       cfg.L1NodeHost,
+			L1NodePort:       cfg.L1NodePort,
+			L1RPCTimeout:     15 * time.Second,
+			MgmtContractAddr: gethcommon.HexToAddress(cfg.ManagementContractAddress),
+			MessageBusAddr:   gethcommon.HexToAddress(cfg.MessageBusAddress),
+			PollInterval:     pollInterval,
+			AttestationConfig: rpc.AttestationConfig{
+				Policy:             attestationPolicy,
+				InsecureSkipVerify: cfg.InsecureSkipAttestation,
+			},
+		},
+		logger,
 	)
-	go server.Serve(config.address)
-	fmt.Printf("Obscuroscan started.\n💡 Visit %s to monitor the Obscuro network.\n", config.address)
+	go server.Serve(cfg.Address)
+	fmt.Printf("Obscuroscan started.\n💡 Visit %s to monitor the Obscuro network.\n", cfg.Address)
 
 	defer server.Shutdown()
 	select {}
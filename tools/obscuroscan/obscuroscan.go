@@ -3,36 +3,28 @@ package obscuroscan
 import (
 	"bytes"
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
 	"embed"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
-	"math/big"
 	"net/http"
-	"os"
-	"os/signal"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
-	"github.com/edgelesssys/ego/enclave"
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	gethcommon "github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
 	gethlog "github.com/ethereum/go-ethereum/log"
-	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/errutil"
 	"github.com/obscuronet/go-obscuro/go/common/httputil"
 	"github.com/obscuronet/go-obscuro/go/common/log"
 	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
+	"github.com/obscuronet/go-obscuro/go/ethadapter"
 	"github.com/obscuronet/go-obscuro/go/ethadapter/mgmtcontractlib"
-	"github.com/obscuronet/go-obscuro/go/obsclient"
 	"github.com/obscuronet/go-obscuro/go/rpc"
+	"github.com/obscuronet/go-obscuro/tools/obscuroscan/indexer"
 )
 
 const (
@@ -47,8 +39,27 @@ const (
 	pathDecryptTxBlob     = "/decrypttxblob/"
 	pathAttestation       = "/attestation/"
 	pathAttestationReport = "/attestationreport/"
+	pathStats             = "/stats/"
+	pathBatches           = "/batches/"
+	pathRollups           = "/rollups/"
+	pathBlocks            = "/blocks/"
+	pathTxs               = "/txs/"
+	pathSearch            = "/search/"
+	pathMessages          = "/messages/"
+	pathMessage           = "/message/"
 	pathRoot              = "/"
 
+	queryParamPage  = "page"
+	queryParamSize  = "size"
+	queryParamQuery = "q"
+	queryParamHash  = "hash"
+	queryParamDir   = "direction"
+	queryParamAddr  = "address"
+	defaultPageSize = 20
+	maxPageSize     = 100
+	numLatestItems  = 5
+	placeholderNA   = "N/A"
+
 	staticDir   = "static"
 	extDivider  = "."
 	extHTML     = ".html"
@@ -60,11 +71,26 @@ var staticFiles embed.FS
 
 // Obscuroscan is a server that allows the monitoring of a running Obscuro network.
 type Obscuroscan struct {
-	server      *http.Server
-	client      rpc.Client
-	obsClient   *obsclient.ObsClient
-	contractABI abi.ABI
-	logger      gethlog.Logger
+	server   *http.Server
+	client   rpc.Client
+	db       *indexer.DB
+	indexer  *indexer.Indexer
+	sessions *viewingKeySessions
+	logger   gethlog.Logger
+}
+
+// Config contains the configuration of an Obscuroscan server.
+type Config struct {
+	RPCServerAddress string             // the address of the Obscuro node that is indexed
+	DBPath           string             // the path of the SQLite database; a temporary database is used if empty
+	L1NodeHost       string             // the host of the L1 node used to index rollups; rollups are not indexed if empty
+	L1NodePort       uint               // the websocket port of the L1 node used to index rollups
+	L1RPCTimeout     time.Duration      // the timeout for requests to the L1 node
+	MgmtContractAddr gethcommon.Address // the address of the management contract the rollups are published to
+	MessageBusAddr   gethcommon.Address // the address of the L1 message bus; cross-chain messages are not indexed if zero
+	PollInterval     time.Duration      // how often the node is polled for new batches
+	// how the node's enclave's attestation is verified before users' viewing keys are registered with it
+	AttestationConfig rpc.AttestationConfig
 }
 
 // Identical to attestation.Report, but with the status mapped to a user-friendly string.
@@ -78,40 +104,82 @@ type attestationReportExternal struct {
 	TCBStatus       string
 }
 
-func NewObscuroscan(address string, logger gethlog.Logger) *Obscuroscan {
-	client, err := rpc.NewNetworkClient(address)
+func NewObscuroscan(config Config, logger gethlog.Logger) *Obscuroscan {
+	client, err := rpc.NewNetworkClient(config.RPCServerAddress)
 	if err != nil {
 		panic(err)
 	}
-	obsClient := obsclient.NewObsClient(client)
 
-	contractABI, err := abi.JSON(strings.NewReader(mgmtcontractlib.MgmtContractABI))
+	db, err := indexer.NewDB(config.DBPath, logger)
+	if err != nil {
+		panic(fmt.Sprintf("could not open Obscuroscan database. Cause: %s", err))
+	}
+
+	var l1Client ethadapter.EthClient
+	if config.L1NodeHost != "" {
+		l1Client, err = ethadapter.NewEthClient(config.L1NodeHost, config.L1NodePort, config.L1RPCTimeout, gethcommon.Address{}, logger)
+		if err != nil {
+			panic(fmt.Sprintf("could not connect to L1 node. Cause: %s", err))
+		}
+	}
+	mgmtContractLib := mgmtcontractlib.NewMgmtContractLib(&config.MgmtContractAddr, logger)
+	var messageBusAddr *gethcommon.Address
+	if config.MessageBusAddr != (gethcommon.Address{}) {
+		messageBusAddr = &config.MessageBusAddr
+	}
+	idx, err := indexer.NewIndexer(db, client, l1Client, mgmtContractLib, messageBusAddr, config.PollInterval, logger)
+	if err != nil {
+		panic(fmt.Sprintf("could not create Obscuroscan indexer. Cause: %s", err))
+	}
+	// the private views fail closed: without constraints to verify the enclave against, no viewing key is registered
+	keyFetcher, err := rpc.NewEnclaveKeyFetcher(nil, config.AttestationConfig, logger)
 	if err != nil {
-		panic("could not parse management contract ABI to decrypt rollups")
+		logger.Warn("Private views are disabled, as the node's attestation cannot be verified.", log.ErrKey, err)
 	}
 
 	return &Obscuroscan{
-		client:      client,
-		obsClient:   obsClient,
-		contractABI: contractABI,
-		logger:      logger,
+		client:   client,
+		db:       db,
+		indexer:  idx,
+		sessions: newViewingKeySessions(config.RPCServerAddress, keyFetcher, logger),
+		logger:   logger,
 	}
 }
 
 // Serve listens for and serves Obscuroscan requests.
 func (o *Obscuroscan) Serve(hostAndPort string) {
+	o.indexer.Start()
+
 	serveMux := http.NewServeMux()
 
-	serveMux.HandleFunc(pathAPI+pathNumRollups, o.getNumRollups)            // Get the number of published rollups.
-	serveMux.HandleFunc(pathAPI+pathNumTxs, o.getNumTransactions)           // Get the number of rolled-up transactions.
-	serveMux.HandleFunc(pathAPI+pathGetRollupTime, o.getRollupTime)         // Get the average rollup time.
-	serveMux.HandleFunc(pathAPI+pathLatestRollups, o.getLatestRollups)      // Get the latest rollup numbers.
-	serveMux.HandleFunc(pathAPI+pathLatestTxs, o.getLatestTxs)              // Get the latest transaction hashes.
-	serveMux.HandleFunc(pathAPI+pathRollup, o.getRollupByNumOrTxHash)       // Get the rollup given its number or the hash of a transaction it contains.
-	serveMux.HandleFunc(pathAPI+pathBlock, o.getBlock)                      // Get the L1 block with the given number.
-	serveMux.HandleFunc(pathAPI+pathDecryptTxBlob, o.decryptTxBlob)         // Decrypt a transaction blob.
-	serveMux.HandleFunc(pathAPI+pathAttestation, o.attestation)             // Retrieve the node's attestation.
-	serveMux.HandleFunc(pathAPI+pathAttestationReport, o.attestationReport) // Retrieve the node's attestation report.
+	serveMux.HandleFunc(pathAPI+pathNumRollups, o.getNumRollups)              // Get the number of published rollups.
+	serveMux.HandleFunc(pathAPI+pathNumTxs, o.getNumTransactions)             // Get the number of rolled-up transactions.
+	serveMux.HandleFunc(pathAPI+pathGetRollupTime, o.getRollupTime)           // Get the average rollup time.
+	serveMux.HandleFunc(pathAPI+pathLatestRollups, o.getLatestRollups)        // Get the latest rollup numbers.
+	serveMux.HandleFunc(pathAPI+pathLatestTxs, o.getLatestTxs)                // Get the latest transaction hashes.
+	serveMux.HandleFunc(pathAPI+pathRollup, o.getRollupByNumOrTxHash)         // Get the rollup given its number or the hash of a transaction it contains.
+	serveMux.HandleFunc(pathAPI+pathBlock, o.getBlock)                        // Get the L1 block with the given number.
+	serveMux.HandleFunc(pathAPI+pathDecryptTxBlob, o.decryptTxBlob)           // Decrypt a transaction blob.
+	serveMux.HandleFunc(pathAPI+pathAttestation, o.attestation)               // Retrieve the node's attestation.
+	serveMux.HandleFunc(pathAPI+pathAttestationReport, o.attestationReport)   // Retrieve the node's attestation report.
+	serveMux.HandleFunc(pathAPI+pathStats, o.getStats)                        // Get the number of indexed items of each type.
+	serveMux.HandleFunc(pathAPI+pathBatches, o.getBatches)                    // Get a page of batches.
+	serveMux.HandleFunc(pathAPI+pathRollups, o.getRollups)                    // Get a page of rollups.
+	serveMux.HandleFunc(pathAPI+pathBlocks, o.getBlocks)                      // Get a page of L1 blocks, and the rollups they contain.
+	serveMux.HandleFunc(pathAPI+pathTxs, o.getTxs)                            // Get a page of transaction hashes.
+	serveMux.HandleFunc(pathAPI+pathSearch, o.search)                         // Search for a batch, rollup, L1 block or transaction.
+	serveMux.HandleFunc(pathAPI+pathMessages, o.getMessages)                  // Get a page of cross-chain messages.
+	serveMux.HandleFunc(pathAPI+pathMessage, o.getMessage)                    // Get the cross-chain messages with the given hash or L1 transaction hash.
+	serveMux.HandleFunc(pathAPIV1+pathV1Batches, o.v1Batches())               // Get a range of batches, as JSON or CSV.
+	serveMux.HandleFunc(pathAPIV1+pathV1Rollups, o.v1Rollups())               // Get a range of rollups, as JSON or CSV.
+	serveMux.HandleFunc(pathAPIV1+pathV1Blocks, o.v1Blocks())                 // Get a range of L1 blocks, as JSON or CSV.
+	serveMux.HandleFunc(pathAPIV1+pathV1Stats, o.v1Stats)                     // Get the network stats, as JSON or CSV.
+	serveMux.HandleFunc(pathAPI+pathGenerateViewingKey, o.generateViewingKey) // Generate a viewing key for the user to sign.
+	serveMux.HandleFunc(pathAPI+pathSubmitViewingKey, o.submitViewingKey)     // Submit the signed viewing key, starting a session.
+	serveMux.HandleFunc(pathAPI+pathDisconnect, o.disconnectViewingKey)       // End the session.
+	serveMux.HandleFunc(pathAPI+pathPrivateTx, o.getPrivateTx)                // Get one of the user's decrypted transactions and its receipt.
+	serveMux.HandleFunc(pathAPI+pathPrivateBalance, o.getPrivateBalance)      // Get the user's balance.
+	serveMux.HandleFunc(pathAPI+pathPrivateEvents, o.getPrivateEvents)        // Get the events visible to the user.
 
 	// Serves the web assets for the user interface.
 	staticFileFS, err := fs.Sub(staticFiles, staticDir)
@@ -140,20 +208,24 @@ func (o *Obscuroscan) Shutdown() {
 		if err != nil {
 			o.logger.Error("could not shut down Obscuroscan.", log.ErrKey, err)
 		}
+		o.indexer.Stop()
+	}
+	o.sessions.removeAll()
+	if err := o.db.Close(); err != nil {
+		o.logger.Error("could not close Obscuroscan database.", log.ErrKey, err)
 	}
 }
 
 // Retrieves the number of published rollups.
 func (o *Obscuroscan) getNumRollups(resp http.ResponseWriter, _ *http.Request) {
-	numOfRollups, err := o.obsClient.RollupNumber()
+	headBatch, err := o.db.HeadBatch()
 	if err != nil {
 		o.logger.Error("Could not fetch number of rollups.", log.ErrKey, err)
 		logAndSendErr(resp, "Could not fetch number of rollups.")
 		return
 	}
 
-	numOfRollupsStr := strconv.Itoa(int(numOfRollups))
-	_, err = resp.Write([]byte(numOfRollupsStr))
+	_, err = resp.Write([]byte(strconv.FormatUint(headBatch.Number, 10)))
 	if err != nil {
 		o.logger.Error("could not return number of rollups to client.", log.ErrKey, err)
 		logAndSendErr(resp, "Could not fetch number of rollups.")
@@ -163,15 +235,14 @@ func (o *Obscuroscan) getNumRollups(resp http.ResponseWriter, _ *http.Request) {
 
 // Retrieves the total number of transactions.
 func (o *Obscuroscan) getNumTransactions(resp http.ResponseWriter, _ *http.Request) {
-	var numTransactions *big.Int
-	err := o.client.Call(&numTransactions, rpc.GetTotalTxs)
+	stats, err := o.db.Stats()
 	if err != nil {
 		o.logger.Error("Could not fetch total transactions.", log.ErrKey, err)
 		logAndSendErr(resp, "Could not fetch total transactions.")
 		return
 	}
 
-	_, err = resp.Write([]byte(numTransactions.String()))
+	_, err = resp.Write([]byte(strconv.FormatUint(stats.Txs, 10)))
 	if err != nil {
 		o.logger.Error("could not return total number of transactions to client.", log.ErrKey, err)
 		logAndSendErr(resp, "Could not fetch total transactions.")
@@ -181,28 +252,24 @@ func (o *Obscuroscan) getNumTransactions(resp http.ResponseWriter, _ *http.Reque
 
 // Retrieves the average rollup time, as (time last rollup - time first rollup)/number of rollups
 func (o *Obscuroscan) getRollupTime(resp http.ResponseWriter, _ *http.Request) {
-	numLatestRollup, err := o.obsClient.RollupNumber()
+	latestBatch, err := o.db.HeadBatch()
 	if err != nil {
-		o.logger.Error("Could not fetch latest rollup number.", log.ErrKey, err)
+		o.logger.Error("Could not fetch latest rollup.", log.ErrKey, err)
 		logAndSendErr(resp, "Could not fetch average rollup time.")
 		return
 	}
 
-	firstRollupHeader, err := o.obsClient.RollupHeaderByNumber(big.NewInt(0))
-	if err != nil || firstRollupHeader == nil {
+	firstBatch, err := o.db.BatchByNumber(0)
+	if err != nil {
 		o.logger.Error("Could not fetch first rollup.", log.ErrKey, err)
 		logAndSendErr(resp, "Could not fetch average rollup time.")
 		return
 	}
 
-	latestRollupHeader, err := o.obsClient.RollupHeaderByNumber(big.NewInt(int64(numLatestRollup)))
-	if err != nil || latestRollupHeader == nil {
-		o.logger.Error("Could not fetch latest rollup.", log.ErrKey, err)
-		logAndSendErr(resp, "Could not fetch average rollup time.")
-		return
+	avgRollupTime := float64(0)
+	if latestBatch.Number > 0 {
+		avgRollupTime = float64(latestBatch.Time-firstBatch.Time) / float64(latestBatch.Number)
 	}
-
-	avgRollupTime := float64(latestRollupHeader.Time-firstRollupHeader.Time) / float64(numLatestRollup)
 	_, err = resp.Write([]byte(fmt.Sprintf("%.2f", avgRollupTime)))
 	if err != nil {
 		o.logger.Error("could not return average rollup time to client.", log.ErrKey, err)
@@ -213,75 +280,47 @@ func (o *Obscuroscan) getRollupTime(resp http.ResponseWriter, _ *http.Request) {
 
 // Retrieves the last five rollup numbers.
 func (o *Obscuroscan) getLatestRollups(resp http.ResponseWriter, _ *http.Request) {
-	latestRollupNum, err := o.obsClient.RollupNumber()
+	batches, err := o.db.Batches(indexer.Page{Size: numLatestItems})
 	if err != nil {
 		o.logger.Error("Could not fetch latest rollups.", log.ErrKey, err)
 		logAndSendErr(resp, "Could not fetch latest rollups.")
 		return
 	}
 
-	// We walk the chain of rollups, getting the number for the most recent five.
-	rollupNums := make([]string, 5)
-	for idx := 0; idx < 5; idx++ {
-		rollupNum := int(latestRollupNum) - idx
-		if rollupNum < 0 {
-			// If there are less than five rollups, we return an N/A.
-			rollupNums[idx] = "N/A"
+	// If there are less than five rollups, we pad with N/As.
+	rollupNums := make([]string, numLatestItems)
+	for idx := range rollupNums {
+		if idx < len(batches) {
+			rollupNums[idx] = strconv.FormatUint(batches[idx].Number, 10)
 		} else {
-			rollupNums[idx] = strconv.Itoa(rollupNum)
+			rollupNums[idx] = placeholderNA
 		}
 	}
-
-	jsonRollupNums, err := json.Marshal(rollupNums)
-	if err != nil {
-		o.logger.Error("could not return latest rollups to client.", log.ErrKey, err)
-		logAndSendErr(resp, "Could not fetch latest rollups.")
-		return
-	}
-	_, err = resp.Write(jsonRollupNums)
-	if err != nil {
-		o.logger.Error("could not return latest rollups to client.", log.ErrKey, err)
-		logAndSendErr(resp, "Could not fetch latest rollups.")
-		return
-	}
+	o.writeJSON(resp, rollupNums, "Could not fetch latest rollups.")
 }
 
 // Retrieves the last five transaction hashes.
 func (o *Obscuroscan) getLatestTxs(resp http.ResponseWriter, _ *http.Request) {
-	numTransactions := 5
-
-	var txHashes []gethcommon.Hash
-	err := o.client.Call(&txHashes, rpc.GetLatestTxs, numTransactions)
+	txs, err := o.db.Txs(indexer.Page{Size: numLatestItems})
 	if err != nil {
 		o.logger.Error("Could not fetch latest transactions.", log.ErrKey, err)
 		logAndSendErr(resp, "Could not fetch latest transactions.")
+		return
 	}
 
 	// We convert the hashes to strings and pad with N/As as needed.
-	txHashStrings := make([]string, numTransactions)
-	for idx := 0; idx < numTransactions; idx++ {
-		if idx < len(txHashes) {
-			txHashStrings[idx] = txHashes[idx].String()
+	txHashStrings := make([]string, numLatestItems)
+	for idx := range txHashStrings {
+		if idx < len(txs) {
+			txHashStrings[idx] = txs[idx].Hash.String()
 		} else {
-			txHashStrings[idx] = "N/A"
+			txHashStrings[idx] = placeholderNA
 		}
 	}
-
-	jsonTxHashes, err := json.Marshal(txHashStrings)
-	if err != nil {
-		o.logger.Error("could not return latest transaction hashes to client.", log.ErrKey, err)
-		logAndSendErr(resp, "Could not fetch latest transactions.")
-		return
-	}
-	_, err = resp.Write(jsonTxHashes)
-	if err != nil {
-		o.logger.Error("could not return latest transaction hashes to client.", log.ErrKey, err)
-		logAndSendErr(resp, "Could not fetch latest transactions.")
-		return
-	}
+	o.writeJSON(resp, txHashStrings, "Could not fetch latest transactions.")
 }
 
-// Retrieves the L1 block header with the given number.
+// Retrieves the L1 block with the given hash, and the rollups it contains.
 func (o *Obscuroscan) getBlock(resp http.ResponseWriter, req *http.Request) {
 	body := req.Body
 	defer body.Close()
@@ -292,29 +331,15 @@ func (o *Obscuroscan) getBlock(resp http.ResponseWriter, req *http.Request) {
 		logAndSendErr(resp, "Could not fetch block.")
 		return
 	}
-	blockHashStr := buffer.String()
-	blockHash := gethcommon.HexToHash(blockHashStr)
+	blockHash := gethcommon.HexToHash(buffer.String())
 
-	var blockHeader *types.Header
-	err = o.client.Call(&blockHeader, rpc.GetBlockHeaderByHash, blockHash)
+	block, err := o.blockWithRollups(blockHash)
 	if err != nil {
 		o.logger.Error(fmt.Sprintf("could not retrieve block with hash %s", blockHash), log.ErrKey, err)
 		logAndSendErr(resp, "Could not fetch block.")
 		return
 	}
-
-	jsonBlock, err := json.Marshal(blockHeader)
-	if err != nil {
-		o.logger.Error("could not return block to client.", log.ErrKey, err)
-		logAndSendErr(resp, "Could not fetch block.")
-		return
-	}
-	_, err = resp.Write(jsonBlock)
-	if err != nil {
-		o.logger.Error("could not return block to client.", log.ErrKey, err)
-		logAndSendErr(resp, "Could not fetch block.")
-		return
-	}
+	o.writeJSON(resp, block, "Could not fetch block.")
 }
 
 // Retrieves a rollup given its number or the hash of a transaction it contains.
@@ -333,26 +358,25 @@ func (o *Obscuroscan) getRollupByNumOrTxHash(resp http.ResponseWriter, req *http
 		return
 	}
 
-	var rollup *common.ExtRollup
+	var batch *indexer.Batch
 	if strings.HasPrefix(buffer.String(), "0x") {
 		// A "0x" prefix indicates that we should retrieve the rollup by transaction hash.
 		txHash := gethcommon.HexToHash(buffer.String())
-
-		err = o.client.Call(&rollup, rpc.GetBatchForTx, txHash)
-		if err != nil || rollup.Header == nil {
+		batch, err = o.db.BatchByTxHash(txHash)
+		if err != nil {
 			o.logger.Error("could not retrieve rollup.", log.ErrKey, err)
 			logAndSendErr(resp, fmt.Sprintf("Could not fetch rollup for transaction %s.", txHash))
 			return
 		}
 	} else {
 		// Otherwise, we treat the input as a rollup number.
-		rollupNumber, err := strconv.Atoi(buffer.String())
+		rollupNumber, err := strconv.ParseUint(buffer.String(), 10, 64)
 		if err != nil {
 			o.logger.Error(fmt.Sprintf("could not parse \"%s\" as an integer", buffer.String()))
 			logAndSendErr(resp, fmt.Sprintf("Could not parse number %s.", buffer.String()))
 			return
 		}
-		rollup, err = o.getRollupByNumber(int64(rollupNumber))
+		batch, err = o.db.BatchByNumber(rollupNumber)
 		if err != nil {
 			o.logger.Error("Could not fetch rollup.", log.ErrKey, err)
 			logAndSendErr(resp, fmt.Sprintf("Could not fetch rollup for number %d.", rollupNumber))
@@ -360,18 +384,189 @@ func (o *Obscuroscan) getRollupByNumOrTxHash(resp http.ResponseWriter, req *http
 		}
 	}
 
-	jsonRollup, err := json.Marshal(rollup)
+	extBatch, err := o.db.ExtBatch(batch.Hash)
+	if err != nil {
+		o.logger.Error("Could not fetch rollup.", log.ErrKey, err)
+		logAndSendErr(resp, "Could not fetch rollup.")
+		return
+	}
+	o.writeJSON(resp, extBatch, "Could not return rollup to client.")
+}
+
+// Retrieves the number of indexed items of each type.
+func (o *Obscuroscan) getStats(resp http.ResponseWriter, req *http.Request) {
+	if httputil.EnableCORS(resp, req) {
+		return
+	}
+	stats, err := o.db.Stats()
+	if err != nil {
+		o.logger.Error("could not retrieve stats.", log.ErrKey, err)
+		logAndSendErr(resp, "Could not fetch stats.")
+		return
+	}
+	o.writeJSON(resp, stats, "Could not fetch stats.")
+}
+
+// Retrieves a page of batches, most recent first.
+func (o *Obscuroscan) getBatches(resp http.ResponseWriter, req *http.Request) {
+	if httputil.EnableCORS(resp, req) {
+		return
+	}
+	page, err := parsePage(req)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+	batches, err := o.db.Batches(page)
+	if err != nil {
+		o.logger.Error("could not retrieve batches.", log.ErrKey, err)
+		logAndSendErr(resp, "Could not fetch batches.")
+		return
+	}
+	o.writeJSON(resp, batches, "Could not fetch batches.")
+}
+
+// Retrieves a page of rollups, most recent first.
+func (o *Obscuroscan) getRollups(resp http.ResponseWriter, req *http.Request) {
+	if httputil.EnableCORS(resp, req) {
+		return
+	}
+	page, err := parsePage(req)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rollups, err := o.db.Rollups(page)
+	if err != nil {
+		o.logger.Error("could not retrieve rollups.", log.ErrKey, err)
+		logAndSendErr(resp, "Could not fetch rollups.")
+		return
+	}
+	o.writeJSON(resp, rollups, "Could not fetch rollups.")
+}
+
+// Retrieves a page of L1 blocks, most recent first, along with the rollups they contain.
+func (o *Obscuroscan) getBlocks(resp http.ResponseWriter, req *http.Request) {
+	if httputil.EnableCORS(resp, req) {
+		return
+	}
+	page, err := parsePage(req)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+	blocks, err := o.db.Blocks(page)
+	if err != nil {
+		o.logger.Error("could not retrieve blocks.", log.ErrKey, err)
+		logAndSendErr(resp, "Could not fetch blocks.")
+		return
+	}
+
+	blocksWithRollups := make([]*blockWithRollups, len(blocks))
+	for idx, block := range blocks {
+		rollups, err := o.db.RollupsByL1Block(block.Hash)
+		if err != nil {
+			o.logger.Error("could not retrieve rollups for block.", log.ErrKey, err)
+			logAndSendErr(resp, "Could not fetch blocks.")
+			return
+		}
+		blocksWithRollups[idx] = &blockWithRollups{Block: block, Rollups: rollups}
+	}
+	o.writeJSON(resp, blocksWithRollups, "Could not fetch blocks.")
+}
+
+// Retrieves a page of transaction hashes, most recent first.
+func (o *Obscuroscan) getTxs(resp http.ResponseWriter, req *http.Request) {
+	if httputil.EnableCORS(resp, req) {
+		return
+	}
+	page, err := parsePage(req)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+	txs, err := o.db.Txs(page)
+	if err != nil {
+		o.logger.Error("could not retrieve transactions.", log.ErrKey, err)
+		logAndSendErr(resp, "Could not fetch transactions.")
+		return
+	}
+	o.writeJSON(resp, txs, "Could not fetch transactions.")
+}
+
+// Retrieves a page of cross-chain messages, most recent first, optionally filtered by direction and by address.
+func (o *Obscuroscan) getMessages(resp http.ResponseWriter, req *http.Request) {
+	if httputil.EnableCORS(resp, req) {
+		return
+	}
+	page, err := parsePage(req)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter := indexer.MessageFilter{Direction: req.URL.Query().Get(queryParamDir)}
+	if filter.Direction != "" && filter.Direction != indexer.DirectionDeposit && filter.Direction != indexer.DirectionWithdrawal {
+		http.Error(resp, fmt.Sprintf("direction must be %s or %s", indexer.DirectionDeposit, indexer.DirectionWithdrawal), http.StatusBadRequest)
+		return
+	}
+	if address := req.URL.Query().Get(queryParamAddr); address != "" {
+		if !gethcommon.IsHexAddress(address) {
+			http.Error(resp, fmt.Sprintf("could not parse address %s", address), http.StatusBadRequest)
+			return
+		}
+		addr := gethcommon.HexToAddress(address)
+		filter.Address = &addr
+	}
+
+	messages, err := o.db.Messages(filter, page)
 	if err != nil {
-		o.logger.Error("could not return rollup to client.", log.ErrKey, err)
-		logAndSendErr(resp, "Could not marshal rollup to JSON.")
+		o.logger.Error("could not retrieve cross-chain messages.", log.ErrKey, err)
+		logAndSendErr(resp, "Could not fetch cross-chain messages.")
 		return
 	}
-	_, err = resp.Write(jsonRollup)
+	o.writeJSON(resp, messages, "Could not fetch cross-chain messages.")
+}
+
+// Retrieves the cross-chain message with the given hash, or the messages published or delivered by the L1 transaction
+// with the given hash.
+func (o *Obscuroscan) getMessage(resp http.ResponseWriter, req *http.Request) {
+	if httputil.EnableCORS(resp, req) {
+		return
+	}
+	hash := gethcommon.HexToHash(req.URL.Query().Get(queryParamHash))
+
+	messages, err := o.messagesByHash(hash)
 	if err != nil {
-		o.logger.Error("could not return rollup to client.", log.ErrKey, err)
-		logAndSendErr(resp, "Could not return rollup to client.")
+		o.logger.Error("could not retrieve cross-chain messages.", log.ErrKey, err)
+		logAndSendErr(resp, "Could not fetch cross-chain messages.")
 		return
 	}
+	if len(messages) == 0 {
+		http.Error(resp, fmt.Sprintf("No cross-chain messages found for %s.", hash), http.StatusNotFound)
+		return
+	}
+	o.writeJSON(resp, messages, "Could not fetch cross-chain messages.")
+}
+
+// Searches for the batch with the given number, or the batch, transaction, rollup, cross-chain message or L1 block with
+// the given hash.
+func (o *Obscuroscan) search(resp http.ResponseWriter, req *http.Request) {
+	if httputil.EnableCORS(resp, req) {
+		return
+	}
+	query := strings.TrimSpace(req.URL.Query().Get(queryParamQuery))
+
+	result, err := o.searchDB(query)
+	if err != nil {
+		if errors.Is(err, errutil.ErrNotFound) {
+			http.Error(resp, fmt.Sprintf("No results found for %s.", query), http.StatusNotFound)
+			return
+		}
+		o.logger.Error("could not search database.", log.ErrKey, err)
+		logAndSendErr(resp, "Could not complete search.")
+		return
+	}
+	o.writeJSON(resp, result, "Could not complete search.")
 }
 
 // Decrypts the provided transaction blob using the provided key.
@@ -436,12 +631,7 @@ func (o *Obscuroscan) attestationReport(resp http.ResponseWriter, _ *http.Reques
 		return
 	}
 
-	// If DCAP isn't set up, verifying the report will send a SIGSYS signal. We catch this, so that it doesn't crash the program.
-	sigChannel := make(chan os.Signal, 1)
-	defer signal.Stop(sigChannel)
-	signal.Notify(sigChannel, syscall.SIGSYS)
-	attestationReport, err := enclave.VerifyRemoteReport(attestation.Report)
-	signal.Stop(sigChannel)
+	attestationReport, err := rpc.VerifyRemoteReport(attestation.Report)
 
 	if err != nil {
 		o.logger.Error("could not verify node's attestation.", log.ErrKey, err)
@@ -473,55 +663,123 @@ func (o *Obscuroscan) attestationReport(resp http.ResponseWriter, _ *http.Reques
 	}
 }
 
-// Returns the rollup with the given number.
-func (o *Obscuroscan) getRollupByNumber(rollupNumber int64) (*common.ExtRollup, error) {
-	// TODO - If required, consolidate the two calls below into a single RPCGetRollupByNumber call to minimise round trips.
-	rollupHeader, err := o.obsClient.RollupHeaderByNumber(big.NewInt(rollupNumber))
-	if err != nil {
-		return nil, fmt.Errorf("could not retrieve rollup with number %d. Cause: %w", rollupNumber, err)
+// An L1 block, along with the rollups published in it.
+type blockWithRollups struct {
+	*indexer.Block
+	Rollups []*indexer.Rollup `json:"rollups"`
+}
+
+// The result of a search, tagged with the type of the item found.
+type searchResult struct {
+	Type   string `json:"type"`
+	Result any    `json:"result"`
+}
+
+// Returns the indexed item matching the query, or errutil.ErrNotFound if there is none.
+func (o *Obscuroscan) searchDB(query string) (*searchResult, error) {
+	if number, err := strconv.ParseUint(query, 10, 64); err == nil {
+		batch, err := o.db.BatchByNumber(number)
+		if err != nil {
+			return nil, err
+		}
+		return &searchResult{Type: "batch", Result: batch}, nil
 	}
 
-	var rollup *common.ExtRollup
-	err = o.client.Call(&rollup, rpc.GetBatch, rollupHeader.Hash())
-	if err != nil {
-		return nil, fmt.Errorf("could not retrieve rollup. Cause: %w", err)
+	if !strings.HasPrefix(query, "0x") || len(query) != 2+2*gethcommon.HashLength {
+		return nil, errutil.ErrNotFound
 	}
+	hash := gethcommon.HexToHash(query)
 
-	if rollup.Header == nil {
-		return nil, fmt.Errorf("retrieved rollup had a nil header")
+	// Hashes are unique across item types, so we return the first match.
+	if batch, err := o.db.BatchByHash(hash); !errors.Is(err, errutil.ErrNotFound) {
+		return &searchResult{Type: "batch", Result: batch}, err
+	}
+	if tx, err := o.db.TxByHash(hash); !errors.Is(err, errutil.ErrNotFound) {
+		return &searchResult{Type: "tx", Result: tx}, err
+	}
+	if rollup, err := o.db.RollupByHash(hash); !errors.Is(err, errutil.ErrNotFound) {
+		return &searchResult{Type: "rollup", Result: rollup}, err
 	}
+	if messages, err := o.messagesByHash(hash); err != nil || len(messages) > 0 {
+		return &searchResult{Type: "messages", Result: messages}, err
+	}
+	block, err := o.blockWithRollups(hash)
+	if err != nil {
+		return nil, err
+	}
+	return &searchResult{Type: "block", Result: block}, nil
+}
 
-	return rollup, nil
+// Returns the cross-chain message with the given hash, or the messages published or delivered by the L1 transaction with
+// the given hash.
+func (o *Obscuroscan) messagesByHash(hash gethcommon.Hash) ([]*indexer.Message, error) {
+	message, err := o.db.MessageByHash(hash)
+	if err == nil {
+		return []*indexer.Message{message}, nil
+	}
+	if !errors.Is(err, errutil.ErrNotFound) {
+		return nil, err
+	}
+	return o.db.MessagesByL1Tx(hash)
 }
 
-// Decrypts the transaction blob and returns it as JSON.
-func decryptTxBlob(encryptedTxBytesBase64 []byte) ([]byte, error) {
-	encryptedTxBytes, err := base64.StdEncoding.DecodeString(string(encryptedTxBytesBase64))
+// Returns the indexed L1 block with the given hash, along with the rollups published in it.
+func (o *Obscuroscan) blockWithRollups(blockHash gethcommon.Hash) (*blockWithRollups, error) {
+	block, err := o.db.BlockByHash(blockHash)
 	if err != nil {
-		return nil, fmt.Errorf("could not decode encrypted transaction blob from Base64. Cause: %w", err)
+		return nil, err
 	}
+	rollups, err := o.db.RollupsByL1Block(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return &blockWithRollups{Block: block, Rollups: rollups}, nil
+}
 
-	key := gethcommon.Hex2Bytes(crypto.RollupEncryptionKeyHex)
-	block, err := aes.NewCipher(key)
+// Writes the value to the client as JSON, or sends the error message if this fails.
+func (o *Obscuroscan) writeJSON(resp http.ResponseWriter, value any, errMsg string) {
+	jsonValue, err := json.Marshal(value)
 	if err != nil {
-		return nil, fmt.Errorf("could not initialise AES cipher for enclave rollup key. Cause: %w", err)
+		o.logger.Error("could not convert response to JSON.", log.ErrKey, err)
+		logAndSendErr(resp, errMsg)
+		return
 	}
-	transactionCipher, err := cipher.NewGCM(block)
+	_, err = resp.Write(jsonValue)
 	if err != nil {
-		return nil, fmt.Errorf("could not initialise wrapper for AES cipher for enclave rollup key. Cause: %w", err)
+		o.logger.Error("could not return response to client.", log.ErrKey, err)
+		logAndSendErr(resp, errMsg)
+		return
+	}
+}
+
+// Parses the page number and size from the request's query parameters.
+func parsePage(req *http.Request) (indexer.Page, error) {
+	page := indexer.Page{Size: defaultPageSize}
+	var err error
+	if pageNumber := req.URL.Query().Get(queryParamPage); pageNumber != "" {
+		if page.Number, err = strconv.ParseUint(pageNumber, 10, 64); err != nil {
+			return page, fmt.Errorf("could not parse page number %s", pageNumber)
+		}
 	}
+	if pageSize := req.URL.Query().Get(queryParamSize); pageSize != "" {
+		if page.Size, err = strconv.ParseUint(pageSize, 10, 64); err != nil || page.Size == 0 || page.Size > maxPageSize {
+			return page, fmt.Errorf("page size must be between 1 and %d", maxPageSize)
+		}
+	}
+	return page, nil
+}
 
-	// The nonce is prepended to the ciphertext.
-	nonce := encryptedTxBytes[0:crypto.NonceLength]
-	ciphertext := encryptedTxBytes[crypto.NonceLength:]
-	encodedTxs, err := transactionCipher.Open(nil, nonce, ciphertext, nil)
+// Decrypts the transaction blob and returns it as JSON.
+func decryptTxBlob(encryptedTxBytesBase64 []byte) ([]byte, error) {
+	encryptedTxBytes, err := base64.StdEncoding.DecodeString(string(encryptedTxBytesBase64))
 	if err != nil {
-		return nil, fmt.Errorf("could not decrypt encrypted L2 transactions. Cause: %w", err)
+		return nil, fmt.Errorf("could not decode encrypted transaction blob from Base64. Cause: %w", err)
 	}
 
-	var cleartextTxs []*common.L2Tx
-	if err = rlp.DecodeBytes(encodedTxs, &cleartextTxs); err != nil {
-		return nil, fmt.Errorf("could not decode encoded L2 transactions. Cause: %w", err)
+	// ObscuroScan only holds the transaction blob key of epoch 0 of the network secret.
+	cleartextTxs, err := crypto.NewTransactionBlobCryptoImpl(nil).Decrypt(encryptedTxBytes)
+	if err != nil {
+		return nil, err
 	}
 
 	jsonRollup, err := json.Marshal(cleartextTxs)
@@ -3,17 +3,23 @@ package obscuroscan
 import (
 	"context"
 	"encoding/base64"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	gethlog "github.com/ethereum/go-ethereum/log"
 	"github.com/obscuronet/go-obscuro/go/common"
 	"github.com/obscuronet/go-obscuro/go/common/httputil"
 	"github.com/obscuronet/go-obscuro/go/enclave/core"
 	"github.com/obscuronet/go-obscuro/go/enclave/crypto"
+	"github.com/obscuronet/go-obscuro/go/rpc"
 	"github.com/obscuronet/go-obscuro/integration/datagenerator"
+	"github.com/obscuronet/go-obscuro/tools/obscuroscan/indexer"
 )
 
 func TestCanDecryptTxBlob(t *testing.T) {
@@ -50,7 +56,7 @@ func generateEncryptedTxBlob(txs []*common.L2Tx) []byte {
 
 func TestObscuroscan_getRollupByNumOrTxHash(t *testing.T) {
 	logger := gethlog.Logger.New(gethlog.Root())
-	ob := NewObscuroscan("http://testnet.obscuroscan.io", logger)
+	ob := NewObscuroscan(testConfig(), logger)
 	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
 	req.Method = http.MethodOptions
 	resp := httptest.NewRecorder()
@@ -65,3 +71,126 @@ func TestObscuroscan_getRollupByNumOrTxHash(t *testing.T) {
 		t.Fatal("CORS Allow Headers not set.")
 	}
 }
+
+func TestObscuroscan_privateEndpointsRequireSession(t *testing.T) {
+	logger := gethlog.Logger.New(gethlog.Root())
+	ob := NewObscuroscan(testConfig(), logger)
+
+	// A session that has not been submitted yet does not grant access.
+	sessionID, _, err := ob.sessions.generate(datagenerator.RandomAddress())
+	if err != nil {
+		t.Fatalf("could not generate viewing key. Cause: %s", err)
+	}
+
+	for _, authorization := range []string{"", authBearerPrefix + "invalid", authBearerPrefix + sessionID} {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, pathAPI+pathPrivateBalance, nil)
+		req.Header.Set(authHeader, authorization)
+		resp := httptest.NewRecorder()
+		ob.getPrivateBalance(resp, req)
+		if resp.Code != http.StatusUnauthorized {
+			t.Fatalf("expected status %d for authorization '%s', got %d", http.StatusUnauthorized, authorization, resp.Code)
+		}
+	}
+}
+
+func TestObscuroscan_viewingKeyIsOnlyRegisteredOnce(t *testing.T) {
+	logger := gethlog.Logger.New(gethlog.Root())
+	ob := NewObscuroscan(testConfig(), logger)
+	sessionID, _, err := ob.sessions.generate(datagenerator.RandomAddress())
+	if err != nil {
+		t.Fatalf("could not generate viewing key. Cause: %s", err)
+	}
+
+	registering := make(chan struct{})
+	release := make(chan struct{})
+	ob.sessions.register = func(*rpc.ViewingKey) (*rpc.EncRPCClient, error) {
+		close(registering)
+		<-release
+		return nil, errors.New("enclave unavailable")
+	}
+	submitted := make(chan error)
+	go func() {
+		_, err := ob.sessions.submit(sessionID, []byte("signature"))
+		submitted <- err
+	}()
+
+	// A concurrent submission for the same session is rejected while the first is being registered.
+	<-registering
+	if _, err = ob.sessions.submit(sessionID, []byte("signature")); err == nil {
+		t.Fatal("expected concurrent submission to be rejected")
+	}
+	close(release)
+	if err = <-submitted; err == nil {
+		t.Fatal("expected submission to fail when the enclave is unavailable")
+	}
+
+	// A failed registration leaves the session pending, so the user can retry, unless it has been removed.
+	ob.sessions.register = func(*rpc.ViewingKey) (*rpc.EncRPCClient, error) {
+		ob.sessions.remove(sessionID)
+		return nil, errors.New("enclave unavailable")
+	}
+	if _, err = ob.sessions.submit(sessionID, []byte("signature")); err == nil {
+		t.Fatal("expected submission to fail when the enclave is unavailable")
+	}
+	if _, found := ob.sessions.sessions[sessionID]; found {
+		t.Fatal("expected removed session not to be restored")
+	}
+}
+
+func TestObscuroscan_v1BatchesPaginationAndCSV(t *testing.T) {
+	logger := gethlog.Logger.New(gethlog.Root())
+	ob := NewObscuroscan(testConfig(), logger)
+	for number := int64(0); number < 5; number++ {
+		batch := &common.ExtBatch{Header: &common.BatchHeader{Number: big.NewInt(number), Time: uint64(100 + number)}}
+		if err := ob.db.AddBatch(batch); err != nil {
+			t.Fatalf("could not store batch. Cause: %s", err)
+		}
+	}
+
+	// Pages through the batches with numbers 1 to 4, two at a time.
+	var numbers []uint64
+	cursor := ""
+	for page := 0; page < 3; page++ {
+		query := "?limit=2&fromNumber=1"
+		if cursor != "" {
+			query += "&cursor=" + cursor
+		}
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, pathAPIV1+pathV1Batches+query, nil)
+		resp := httptest.NewRecorder()
+		ob.v1Batches()(resp, req)
+
+		var batches listResponse[indexer.Batch]
+		if err := json.Unmarshal(resp.Body.Bytes(), &batches); err != nil {
+			t.Fatalf("could not parse batches. Cause: %s", err)
+		}
+		for _, batch := range batches.Items {
+			numbers = append(numbers, batch.Number)
+		}
+		if batches.NextCursor == nil {
+			break
+		}
+		cursor = *batches.NextCursor
+	}
+	if len(numbers) != 4 || numbers[0] != 4 || numbers[3] != 1 {
+		t.Fatalf("expected batches 4 to 1, got %v", numbers)
+	}
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, pathAPIV1+pathV1Batches+"?format=csv&toTime=101", nil)
+	resp := httptest.NewRecorder()
+	ob.v1Batches()(resp, req)
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("could not parse CSV. Cause: %s", err)
+	}
+	if len(records) != 3 || records[1][1] != "1" || records[2][1] != "0" {
+		t.Fatalf("expected a header and batches 1 and 0, got %v", records)
+	}
+}
+
+func testConfig() Config {
+	return Config{
+		RPCServerAddress:  "http://testnet.obscuroscan.io",
+		PollInterval:      time.Second,
+		AttestationConfig: rpc.AttestationConfig{InsecureSkipVerify: true},
+	}
+}
@@ -0,0 +1,422 @@
+package obscuroscan
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/obscuronet/go-obscuro/go/common"
+	"github.com/obscuronet/go-obscuro/go/common/httputil"
+	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/go/obsclient"
+	"github.com/obscuronet/go-obscuro/go/rpc"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethlog "github.com/ethereum/go-ethereum/log"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	pathGenerateViewingKey = "/viewingkey/generate/"
+	pathSubmitViewingKey   = "/viewingkey/submit/"
+	pathDisconnect         = "/viewingkey/disconnect/"
+	pathPrivateTx          = "/private/tx/"
+	pathPrivateBalance     = "/private/balance/"
+	pathPrivateEvents      = "/private/events/"
+
+	jsonKeyAddress    = "address"
+	jsonKeySignature  = "signature"
+	jsonKeySessionID  = "sessionID"
+	jsonKeyViewingKey = "viewingKey"
+
+	queryParamFromBlock = "fromBlock"
+	queryParamToBlock   = "toBlock"
+
+	authHeader       = "Authorization"
+	authBearerPrefix = "Bearer "
+
+	sessionIDLength = 32
+	sessionTTL      = time.Hour
+	// Viewing keys that are not signed within this period are discarded.
+	pendingSessionTTL = 5 * time.Minute
+)
+
+var (
+	errNoSession            = errors.New("no active session. Connect a viewing key first")
+	errPrivateViewsDisabled = errors.New("private views are disabled, as no attestation constraints are configured for the node's enclave")
+)
+
+// A session in which a user has connected a viewing key to Obscuroscan, allowing them to view their private data.
+type viewingKeySession struct {
+	viewingKey *rpc.ViewingKey
+	client     *obsclient.AuthObsClient // nil until the viewing key has been signed and registered with the enclave
+	expiry     time.Time
+	// Set while the signed viewing key is being registered with the enclave, so that it is only registered once.
+	registering bool
+}
+
+// viewingKeySessions tracks the viewing keys connected by users. Each session is identified by a random ID that is only
+// known to the user's browser, and which must be provided to access the private endpoints.
+//
+// Obscuroscan holds the viewing key's private key for the duration of the session, so users must trust the operator of
+// the Obscuroscan instance in the same way they trust their wallet extension. The viewing key only grants read access to
+// the account's data; it cannot be used to sign transactions.
+type viewingKeySessions struct {
+	rpcAddress string
+	keyFetcher *rpc.EnclaveKeyFetcher // nil if the private views are disabled
+	sessions   map[string]*viewingKeySession
+	lock       sync.Mutex
+	logger     gethlog.Logger
+
+	// Registers the signed viewing key with the enclave. Replaced in tests.
+	register func(viewingKey *rpc.ViewingKey) (*rpc.EncRPCClient, error)
+}
+
+func newViewingKeySessions(rpcAddress string, keyFetcher *rpc.EnclaveKeyFetcher, logger gethlog.Logger) *viewingKeySessions {
+	s := &viewingKeySessions{
+		rpcAddress: rpcAddress,
+		keyFetcher: keyFetcher,
+		sessions:   map[string]*viewingKeySession{},
+		logger:     logger,
+	}
+	s.register = func(viewingKey *rpc.ViewingKey) (*rpc.EncRPCClient, error) {
+		return rpc.NewEncNetworkClient(s.rpcAddress, viewingKey, s.keyFetcher, s.logger)
+	}
+	return s
+}
+
+// Generates a viewing key for the account, and returns the ID of the pending session and the viewing key's public key
+// for the user to sign.
+func (s *viewingKeySessions) generate(account gethcommon.Address) (string, []byte, error) {
+	if s.keyFetcher == nil {
+		return "", nil, errPrivateViewsDisabled
+	}
+	viewingKeyPrivate, err := crypto.GenerateKey()
+	if err != nil {
+		return "", nil, fmt.Errorf("could not generate new keypair. Cause: %w", err)
+	}
+	sessionID, err := newSessionID()
+	if err != nil {
+		return "", nil, err
+	}
+	viewingPublicKeyBytes := crypto.CompressPubkey(&viewingKeyPrivate.PublicKey)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.removeExpired()
+	s.sessions[sessionID] = &viewingKeySession{
+		viewingKey: &rpc.ViewingKey{
+			Account:    &account,
+			PrivateKey: ecies.ImportECDSA(viewingKeyPrivate),
+			PublicKey:  viewingPublicKeyBytes,
+			SignedKey:  nil, // we await a signature from the user before we can set up the EncRPCClient
+		},
+		expiry: time.Now().Add(pendingSessionTTL),
+	}
+	return sessionID, viewingPublicKeyBytes, nil
+}
+
+// Registers the signed viewing key of the pending session with the enclave, activating the session.
+//
+// The lock is not held while the viewing key is registered, as this requires network I/O. Instead, the session is
+// marked as registering so that concurrent submissions are rejected, and afterwards the session is only activated if it
+// has not been removed or expired in the meantime.
+func (s *viewingKeySessions) submit(sessionID string, signature []byte) (gethcommon.Address, error) {
+	s.lock.Lock()
+	session, found := s.sessions[sessionID]
+	if !found || session.client != nil || session.registering || time.Now().After(session.expiry) {
+		s.lock.Unlock()
+		return gethcommon.Address{}, errors.New("no pending viewing key found for session. Generate a viewing key first")
+	}
+	session.registering = true
+	s.lock.Unlock()
+
+	viewingKey := *session.viewingKey
+	viewingKey.SignedKey = signature
+	encClient, err := s.register(&viewingKey)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	session.registering = false
+	if err != nil {
+		return gethcommon.Address{}, fmt.Errorf("could not register viewing key with the enclave. Cause: %w", err)
+	}
+	client := obsclient.NewAuthObsClient(encClient)
+	if s.sessions[sessionID] != session || time.Now().After(session.expiry) {
+		client.Close()
+		return gethcommon.Address{}, errors.New("session was ended while the viewing key was being registered")
+	}
+	s.sessions[sessionID] = &viewingKeySession{
+		viewingKey: &viewingKey,
+		client:     client,
+		expiry:     time.Now().Add(sessionTTL),
+	}
+	return *viewingKey.Account, nil
+}
+
+// Returns the client for the active session with the given ID.
+func (s *viewingKeySessions) client(sessionID string) (*obsclient.AuthObsClient, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.removeExpired()
+	session, found := s.sessions[sessionID]
+	if !found || session.client == nil {
+		return nil, errNoSession
+	}
+	return session.client, nil
+}
+
+// Ends the session with the given ID.
+func (s *viewingKeySessions) remove(sessionID string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if session, found := s.sessions[sessionID]; found {
+		if session.client != nil {
+			session.client.Close()
+		}
+		delete(s.sessions, sessionID)
+	}
+}
+
+// Ends all sessions.
+func (s *viewingKeySessions) removeAll() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for sessionID, session := range s.sessions {
+		if session.client != nil {
+			session.client.Close()
+		}
+		delete(s.sessions, sessionID)
+	}
+}
+
+// Ends the expired sessions. The caller must hold the lock.
+func (s *viewingKeySessions) removeExpired() {
+	now := time.Now()
+	for sessionID, session := range s.sessions {
+		if now.After(session.expiry) {
+			if session.client != nil {
+				session.client.Close()
+			}
+			delete(s.sessions, sessionID)
+		}
+	}
+}
+
+// Generates a new viewing key for the account in the request body, and returns it to be signed by the user.
+func (o *Obscuroscan) generateViewingKey(resp http.ResponseWriter, req *http.Request) {
+	if httputil.EnableCORS(resp, req) {
+		return
+	}
+	var reqJSONMap map[string]string
+	if err := json.NewDecoder(req.Body).Decode(&reqJSONMap); err != nil {
+		http.Error(resp, fmt.Sprintf("could not unmarshal account address from client to JSON: %s", err), http.StatusBadRequest)
+		return
+	}
+	if !gethcommon.IsHexAddress(reqJSONMap[jsonKeyAddress]) {
+		http.Error(resp, "request did not contain a valid account address", http.StatusBadRequest)
+		return
+	}
+
+	sessionID, viewingKey, err := o.sessions.generate(gethcommon.HexToAddress(reqJSONMap[jsonKeyAddress]))
+	if err != nil {
+		o.logger.Error("could not generate viewing key.", log.ErrKey, err)
+		logAndSendErr(resp, "Could not generate viewing key.")
+		return
+	}
+
+	// We return the hex of the viewing key's public key for MetaMask to sign over.
+	o.writeJSON(resp, map[string]string{
+		jsonKeySessionID:  sessionID,
+		jsonKeyViewingKey: hex.EncodeToString(viewingKey),
+	}, "Could not generate viewing key.")
+}
+
+// Submits the signed viewing key of a pending session to the enclave.
+func (o *Obscuroscan) submitViewingKey(resp http.ResponseWriter, req *http.Request) {
+	if httputil.EnableCORS(resp, req) {
+		return
+	}
+	var reqJSONMap map[string]string
+	if err := json.NewDecoder(req.Body).Decode(&reqJSONMap); err != nil {
+		http.Error(resp, fmt.Sprintf("could not unmarshal viewing key signature from client to JSON: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	//  We drop the leading "0x".
+	signature, err := hex.DecodeString(strings.TrimPrefix(reqJSONMap[jsonKeySignature], "0x"))
+	if err != nil || len(signature) != crypto.SignatureLength {
+		http.Error(resp, "could not decode signature from client", http.StatusBadRequest)
+		return
+	}
+	// We transform the V from 27/28 to 0/1. This same change is made in Geth internals, for legacy reasons to be able
+	// to recover the address: https://github.com/ethereum/go-ethereum/blob/55599ee95d4151a2502465e0afc7c47bd1acba77/internal/ethapi/api.go#L452-L459
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+
+	account, err := o.sessions.submit(reqJSONMap[jsonKeySessionID], signature)
+	if err != nil {
+		o.logger.Warn("could not submit viewing key.", log.ErrKey, err)
+		http.Error(resp, fmt.Sprintf("Could not submit viewing key: %s", err), http.StatusBadRequest)
+		return
+	}
+	o.writeJSON(resp, map[string]string{jsonKeyAddress: account.Hex()}, "Could not submit viewing key.")
+}
+
+// Ends the requester's session, discarding their viewing key.
+func (o *Obscuroscan) disconnectViewingKey(resp http.ResponseWriter, req *http.Request) {
+	if httputil.EnableCORS(resp, req) {
+		return
+	}
+	o.sessions.remove(sessionIDFromRequest(req))
+}
+
+// Retrieves the decrypted transaction with the given hash, and its receipt, if visible to the requester.
+func (o *Obscuroscan) getPrivateTx(resp http.ResponseWriter, req *http.Request) {
+	client, ok := o.sessionClient(resp, req)
+	if !ok {
+		return
+	}
+	txHash := gethcommon.HexToHash(req.URL.Query().Get(queryParamHash))
+
+	tx, _, err := client.TransactionByHash(req.Context(), txHash)
+	if err != nil {
+		o.sendPrivateErr(resp, fmt.Sprintf("Could not fetch transaction %s.", txHash), err)
+		return
+	}
+	receipt, err := client.TransactionReceipt(req.Context(), txHash)
+	if err != nil {
+		o.sendPrivateErr(resp, fmt.Sprintf("Could not fetch receipt for transaction %s.", txHash), err)
+		return
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		o.sendPrivateErr(resp, fmt.Sprintf("Could not recover sender of transaction %s.", txHash), err)
+		return
+	}
+
+	o.writeJSON(resp, &privateTx{From: from, Tx: tx, Receipt: receipt}, "Could not fetch transaction.")
+}
+
+// Retrieves the requester's balance.
+func (o *Obscuroscan) getPrivateBalance(resp http.ResponseWriter, req *http.Request) {
+	client, ok := o.sessionClient(resp, req)
+	if !ok {
+		return
+	}
+
+	balance, err := client.BalanceAt(req.Context(), nil)
+	if err != nil {
+		o.sendPrivateErr(resp, "Could not fetch balance.", err)
+		return
+	}
+	o.writeJSON(resp, &privateBalance{Address: client.Address(), Balance: balance}, "Could not fetch balance.")
+}
+
+// Retrieves the events visible to the requester, optionally filtered by block range and contract address.
+func (o *Obscuroscan) getPrivateEvents(resp http.ResponseWriter, req *http.Request) {
+	client, ok := o.sessionClient(resp, req)
+	if !ok {
+		return
+	}
+
+	filterCriteria, err := parseFilterCriteria(req)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+	logs, err := client.GetLogs(req.Context(), filterCriteria)
+	if err != nil {
+		o.sendPrivateErr(resp, "Could not fetch events.", err)
+		return
+	}
+	o.writeJSON(resp, logs, "Could not fetch events.")
+}
+
+// Returns the client for the requester's session. If there is no active session, an error is sent and false is
+// returned.
+func (o *Obscuroscan) sessionClient(resp http.ResponseWriter, req *http.Request) (*obsclient.AuthObsClient, bool) {
+	if httputil.EnableCORS(resp, req) {
+		return nil, false
+	}
+	client, err := o.sessions.client(sessionIDFromRequest(req))
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusUnauthorized)
+		return nil, false
+	}
+	return client, true
+}
+
+// Sends the error from a private request. Data that is not visible to the requester is reported as not found.
+func (o *Obscuroscan) sendPrivateErr(resp http.ResponseWriter, msg string, err error) {
+	if errors.Is(err, rpc.ErrNilResponse) {
+		http.Error(resp, msg, http.StatusNotFound)
+		return
+	}
+	o.logger.Warn(msg, log.ErrKey, err)
+	logAndSendErr(resp, msg)
+}
+
+// A decrypted transaction, along with its sender and receipt.
+type privateTx struct {
+	From    gethcommon.Address `json:"from"`
+	Tx      *types.Transaction `json:"tx"`
+	Receipt *types.Receipt     `json:"receipt"`
+}
+
+type privateBalance struct {
+	Address gethcommon.Address `json:"address"`
+	Balance *big.Int           `json:"balance"`
+}
+
+// Parses the filter for private events from the request's query parameters.
+func parseFilterCriteria(req *http.Request) (common.FilterCriteriaJSON, error) {
+	filterCriteria := common.FilterCriteriaJSON{}
+	for param, target := range map[string]**gethrpc.BlockNumber{
+		queryParamFromBlock: &filterCriteria.FromBlock,
+		queryParamToBlock:   &filterCriteria.ToBlock,
+	} {
+		value := req.URL.Query().Get(param)
+		if value == "" {
+			continue
+		}
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || number < 0 {
+			return filterCriteria, fmt.Errorf("could not parse %s %s", param, value)
+		}
+		blockNumber := gethrpc.BlockNumber(number)
+		*target = &blockNumber
+	}
+	if address := req.URL.Query().Get(jsonKeyAddress); address != "" {
+		if !gethcommon.IsHexAddress(address) {
+			return filterCriteria, fmt.Errorf("could not parse address %s", address)
+		}
+		filterCriteria.Addresses = []gethcommon.Address{gethcommon.HexToAddress(address)}
+	}
+	return filterCriteria, nil
+}
+
+// Returns the session ID from the request's bearer token.
+func sessionIDFromRequest(req *http.Request) string {
+	return strings.TrimPrefix(req.Header.Get(authHeader), authBearerPrefix)
+}
+
+func newSessionID() (string, error) {
+	sessionID := make([]byte, sessionIDLength)
+	if _, err := rand.Read(sessionID); err != nil {
+		return "", fmt.Errorf("could not generate session ID. Cause: %w", err)
+	}
+	return hex.EncodeToString(sessionID), nil
+}
@@ -0,0 +1,51 @@
+"use strict";
+
+const eventDomLoaded = "DOMContentLoaded";
+const typeSubmit = "submit";
+
+const idFormFindMessages = "form-find-messages";
+const idMessageQuery = "messageQuery";
+const idDirection = "direction";
+const idMessages = "messages";
+
+const pathMessages = "/api/messages/";
+const pathMessage = "/api/message/";
+
+const addressLength = 42;
+
+// Displays the messages matching the query: the messages sent or received by an address, the messages with a given
+// hash or in a given L1 transaction, or the latest messages if the query is empty.
+async function displayMessages() {
+    const messagesArea = document.getElementById(idMessages);
+    const query = document.getElementById(idMessageQuery).value.trim();
+    const params = new URLSearchParams();
+
+    let path = pathMessages;
+    if (query.length === addressLength) {
+        params.set("address", query);
+    } else if (query !== "") {
+        path = pathMessage;
+        params.set("hash", query);
+    }
+    const direction = document.getElementById(idDirection).value;
+    if (direction !== "" && path === pathMessages) {
+        params.set("direction", direction);
+    }
+
+    const resp = await fetch(path + "?" + params);
+    if (resp.ok) {
+        messagesArea.innerText = JSON.stringify(await resp.json(), null, "\t");
+    } else {
+        messagesArea.innerText = await resp.text();
+    }
+}
+
+const initialize = async () => {
+    document.getElementById(idFormFindMessages).addEventListener(typeSubmit, async (event) => {
+        event.preventDefault();
+        await displayMessages();
+    });
+    await displayMessages();
+}
+
+window.addEventListener(eventDomLoaded, initialize);
@@ -0,0 +1,143 @@
+"use strict";
+
+const eventDomLoaded = "DOMContentLoaded";
+const eventClick = "click";
+const typeSubmit = "submit";
+const methodPost = "POST";
+const jsonHeaders = {
+    "Accept": "application/json",
+    "Content-Type": "application/json"
+};
+const metamaskRequestAccounts = "eth_requestAccounts";
+const metamaskPersonalSign = "personal_sign";
+const personalSignPrefix = "vk";
+const storageKeySessionID = "obscuroscanSessionID";
+
+const idConnect = "connect";
+const idDisconnect = "disconnect";
+const idStatus = "status";
+const idBalance = "balance";
+const idFormGetTx = "form-get-tx";
+const idTxHash = "txHash";
+const idTx = "tx";
+const idFormGetEvents = "form-get-events";
+const idFromBlock = "fromBlock";
+const idToBlock = "toBlock";
+const idContractAddress = "contractAddress";
+const idEvents = "events";
+
+const pathGenerateViewingKey = "/api/viewingkey/generate/";
+const pathSubmitViewingKey = "/api/viewingkey/submit/";
+const pathDisconnect = "/api/viewingkey/disconnect/";
+const pathPrivateTx = "/api/private/tx/";
+const pathPrivateBalance = "/api/private/balance/";
+const pathPrivateEvents = "/api/private/events/";
+
+// Returns the headers that authenticate the request with the current session.
+function authHeaders() {
+    return {"Authorization": "Bearer " + sessionStorage.getItem(storageKeySessionID)};
+}
+
+// Generates a viewing key, has the user sign it in MetaMask, and submits it to start a session.
+async function connect() {
+    const statusArea = document.getElementById(idStatus);
+    if (typeof ethereum === "undefined") {
+        statusArea.innerText = "`ethereum` object is not available. Please install and enable MetaMask.";
+        return;
+    }
+
+    const accounts = await ethereum.request({method: metamaskRequestAccounts});
+    if (accounts.length === 0) {
+        statusArea.innerText = "No MetaMask accounts found.";
+        return;
+    }
+    const account = accounts[0];
+
+    const generateResp = await fetch(pathGenerateViewingKey, {
+        method: methodPost,
+        headers: jsonHeaders,
+        body: JSON.stringify({"address": account})
+    });
+    if (!generateResp.ok) {
+        statusArea.innerText = "Failed to generate viewing key.";
+        return;
+    }
+    const generateJSON = await generateResp.json();
+
+    const signature = await ethereum.request({
+        method: metamaskPersonalSign,
+        // Without a prefix such as 'vk', personal_sign transforms the data for security reasons.
+        params: [personalSignPrefix + generateJSON.viewingKey, account]
+    }).catch(_ => { return -1 });
+    if (signature === -1) {
+        statusArea.innerText = "Failed to sign viewing key.";
+        return;
+    }
+
+    const submitResp = await fetch(pathSubmitViewingKey, {
+        method: methodPost,
+        headers: jsonHeaders,
+        body: JSON.stringify({"sessionID": generateJSON.sessionID, "signature": signature})
+    });
+    if (!submitResp.ok) {
+        statusArea.innerText = "Failed to submit viewing key: " + await submitResp.text();
+        return;
+    }
+
+    sessionStorage.setItem(storageKeySessionID, generateJSON.sessionID);
+    const submitJSON = await submitResp.json();
+    statusArea.innerText = `Connected as ${submitJSON.address}.`;
+    await displayBalance();
+}
+
+// Ends the session.
+async function disconnect() {
+    await fetch(pathDisconnect, {method: methodPost, headers: authHeaders()});
+    sessionStorage.removeItem(storageKeySessionID);
+    document.getElementById(idStatus).innerText = "Not connected.";
+    document.getElementById(idBalance).innerText = "N/A";
+}
+
+// Fetches the given private path and displays the result in the given area.
+async function displayPrivate(path, areaID) {
+    const area = document.getElementById(areaID);
+    const resp = await fetch(path, {headers: authHeaders()});
+    if (resp.ok) {
+        area.innerText = JSON.stringify(await resp.json(), null, "\t");
+    } else {
+        area.innerText = await resp.text();
+    }
+}
+
+async function displayBalance() {
+    await displayPrivate(pathPrivateBalance, idBalance);
+}
+
+const initialize = () => {
+    document.getElementById(idConnect).addEventListener(eventClick, connect);
+    document.getElementById(idDisconnect).addEventListener(eventClick, disconnect);
+
+    document.getElementById(idFormGetTx).addEventListener(typeSubmit, async (event) => {
+        event.preventDefault();
+        const txHash = document.getElementById(idTxHash).value;
+        await displayPrivate(pathPrivateTx + "?" + new URLSearchParams({"hash": txHash}), idTx);
+    });
+
+    document.getElementById(idFormGetEvents).addEventListener(typeSubmit, async (event) => {
+        event.preventDefault();
+        const params = new URLSearchParams();
+        for (const [param, id] of [["fromBlock", idFromBlock], ["toBlock", idToBlock], ["address", idContractAddress]]) {
+            const value = document.getElementById(id).value;
+            if (value !== "") {
+                params.set(param, value);
+            }
+        }
+        await displayPrivate(pathPrivateEvents + "?" + params, idEvents);
+    });
+
+    if (sessionStorage.getItem(storageKeySessionID) !== null) {
+        displayBalance();
+    }
+}
+
+window.addEventListener(eventDomLoaded, initialize);
@@ -104,7 +104,7 @@ func (m *AccountManager) suggestAccountClient(req *RPCRequest, accClients map[ge
 		return nil
 	}
 
-	if req.Method == rpc.Call {
+	if req.Method == rpc.Call || req.Method == rpc.DebugTraceCall {
 		// check if request params had a "from" address and if we had a client for that address
 		fromClient, found := checkForFromField(paramsMap, accClients)
 		if found {
@@ -274,7 +274,7 @@ func (m *AccountManager) executeSubscribe(client *rpc.EncRPCClient, req *RPCRequ
 }
 
 func executeCall(client *rpc.EncRPCClient, req *RPCRequest, resp *interface{}) error {
-	if req.Method == rpc.Call || req.Method == rpc.EstimateGas {
+	if req.Method == rpc.Call || req.Method == rpc.EstimateGas || req.Method == rpc.DebugTraceCall {
 		// Never modify the original request, as it might be reused.
 		req = req.Clone()
 
@@ -297,6 +297,15 @@ func executeCall(client *rpc.EncRPCClient, req *RPCRequest, resp *interface{}) e
 		req.Params = append(req.Params, client.Account().Hex())
 	}
 
+	if req.Method == rpc.GetPrivateStorageAt {
+		// Never modify the original request, as it might be reused.
+		req = req.Clone()
+
+		// We add the account to the list of arguments, so that the contract can authorise it to read the slot and the
+		// enclave can encrypt the result.
+		req.Params = append(req.Params, client.Account().Hex())
+	}
+
 	return client.Call(resp, req.Method, req.Params...)
 }
 
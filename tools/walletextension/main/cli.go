@@ -3,12 +3,20 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
 
+	"github.com/obscuronet/go-obscuro/go/config"
+	"github.com/obscuronet/go-obscuro/go/rpc"
 	"github.com/obscuronet/go-obscuro/tools/walletextension"
 )
 
 const (
+	// The prefix of the names of the environment variables that set the config, e.g. OBSCURO_WALLET_EXTENSION_NODE_HOST.
+	envPrefix = "OBSCURO_WALLET_EXTENSION"
+
 	// Flag names, defaults and usages.
+	configUsage = "The path to the wallet extension's .toml config file. Environment variables and flags override its values"
+
 	walletExtensionHostName    = "host"
 	walletExtensionHostDefault = "127.0.0.1"
 	walletExtensionHostUsage   = "The host where the wallet extension should open the port."
@@ -43,28 +51,107 @@ const (
 	verboseFlagName    = "verbose"
 	verboseFlagDefault = false
 	verboseFlagUsage   = "Flag to enable verbose logging of wallet extension traffic"
+
+	enclaveUniqueIDName  = "enclaveUniqueID"
+	enclaveUniqueIDUsage = "The hex-encoded unique ID (MRENCLAVE) the node's enclave must attest to"
+
+	enclaveSignerIDName  = "enclaveSignerID"
+	enclaveSignerIDUsage = "The hex-encoded signer ID (MRSIGNER) the node's enclave must attest to. Requires enclaveProductID"
+
+	enclaveProductIDName  = "enclaveProductID"
+	enclaveProductIDUsage = "The product ID the node's enclave must attest to"
+
+	enclaveSecurityVersionName  = "enclaveSecurityVersion"
+	enclaveSecurityVersionUsage = "The minimum security version the node's enclave must attest to"
+
+	enclaveAllowDebugName  = "enclaveAllowDebug"
+	enclaveAllowDebugUsage = "Whether to accept attestations from debug enclaves"
+
+	enclaveTCBStatusesName  = "enclaveTCBStatuses"
+	enclaveTCBStatusesUsage = "The TCB statuses of the node's enclave that are accepted in addition to UpToDate, as a comma-separated list (e.g. SWHardeningNeeded)"
+
+	insecureSkipAttestationName  = "insecureSkipAttestation"
+	insecureSkipAttestationUsage = "Trust the node's enclave key without verifying its attestation. Only for dev networks that run without SGX"
 )
 
-func parseCLIArgs() walletextension.Config {
-	walletExtensionHost := flag.String(walletExtensionHostName, walletExtensionHostDefault, walletExtensionHostUsage)
-	walletExtensionPort := flag.Int(walletExtensionPortName, walletExtensionPortDefault, walletExtensionPortUsage)
-	walletExtensionPortWS := flag.Int(walletExtensionPortWSName, walletExtensionPortWSDefault, walletExtensionPortWSUsage)
-	nodeHost := flag.String(nodeHostName, nodeHostDefault, nodeHostUsage)
-	nodeHTTPPort := flag.Int(nodeHTTPPortName, nodeHTTPPortDefault, nodeHTTPPortUsage)
-	nodeWebsocketPort := flag.Int(nodeWebsocketPortName, nodeWebsocketPortDefault, nodeWebsocketPortUsage)
-	logPath := flag.String(logPathName, logPathDefault, logPathUsage)
-	persistencePath := flag.String(persistencePathName, persistencePathDefault, persistencePathUsage)
-	verboseFlag := flag.Bool(verboseFlagName, verboseFlagDefault, verboseFlagUsage)
-	flag.Parse()
+// walletExtensionConfigToml is the structure that the wallet extension's config is loaded into, from its .toml config
+// file, environment variables and flags. See config.Loader for the meaning of the struct tags.
+type walletExtensionConfigToml struct {
+	Host            string `flag:"host" validate:"required"`
+	Port            int    `flag:"port" validate:"min=0,port"`
+	PortWS          int    `flag:"portWS" validate:"min=0,port"`
+	NodeHost        string `flag:"nodeHost" validate:"required"`
+	NodePortHTTP    int    `flag:"nodePortHTTP" validate:"min=0,port"`
+	NodePortWS      int    `flag:"nodePortWS" validate:"min=0,port"`
+	LogPath         string `flag:"logPath"`
+	PersistencePath string `flag:"persistencePath"`
+	Verbose         bool   `flag:"verbose" reload:"true"`
 
-	return walletextension.Config{
-		WalletExtensionHost:     *walletExtensionHost,
-		WalletExtensionPort:     *walletExtensionPort,
-		WalletExtensionPortWS:   *walletExtensionPortWS,
-		NodeRPCHTTPAddress:      fmt.Sprintf("%s:%d", *nodeHost, *nodeHTTPPort),
-		NodeRPCWebsocketAddress: fmt.Sprintf("%s:%d", *nodeHost, *nodeWebsocketPort),
-		LogPath:                 *logPath,
-		PersistencePathOverride: *persistencePath,
-		VerboseFlag:             *verboseFlag,
+	EnclaveUniqueID         string   `flag:"enclaveUniqueID" validate:"hex32"`
+	EnclaveSignerID         string   `flag:"enclaveSignerID" validate:"hex32"`
+	EnclaveProductID        uint16   `flag:"enclaveProductID"`
+	EnclaveSecurityVersion  uint     `flag:"enclaveSecurityVersion"`
+	EnclaveAllowDebug       bool     `flag:"enclaveAllowDebug"`
+	EnclaveTCBStatuses      []string `flag:"enclaveTCBStatuses"`
+	InsecureSkipAttestation bool     `flag:"insecureSkipAttestation"`
+}
+
+// Loads the wallet extension's config from, in increasing order of precedence, the defaults, the file identified by the
+// `config` flag, the environment variables and the flags. The returned loader is used to reload the config.
+func parseCLIArgs() (walletextension.Config, *config.Loader, error) {
+	tomlConfig := walletExtensionConfigToml{
+		Host:            walletExtensionHostDefault,
+		Port:            walletExtensionPortDefault,
+		PortWS:          walletExtensionPortWSDefault,
+		NodeHost:        nodeHostDefault,
+		NodePortHTTP:    nodeHTTPPortDefault,
+		NodePortWS:      nodeWebsocketPortDefault,
+		LogPath:         logPathDefault,
+		PersistencePath: persistencePathDefault,
+		Verbose:         verboseFlagDefault,
+	}
+	usages := map[string]string{
+		config.ConfigFlagName:     configUsage,
+		walletExtensionHostName:   walletExtensionHostUsage,
+		walletExtensionPortName:   walletExtensionPortUsage,
+		walletExtensionPortWSName: walletExtensionPortWSUsage,
+		nodeHostName:              nodeHostUsage,
+		nodeHTTPPortName:          nodeHTTPPortUsage,
+		nodeWebsocketPortName:     nodeWebsocketPortUsage,
+		logPathName:               logPathUsage,
+		persistencePathName:       persistencePathUsage,
+		verboseFlagName:           verboseFlagUsage,
+
+		enclaveUniqueIDName:         enclaveUniqueIDUsage,
+		enclaveSignerIDName:         enclaveSignerIDUsage,
+		enclaveProductIDName:        enclaveProductIDUsage,
+		enclaveSecurityVersionName:  enclaveSecurityVersionUsage,
+		enclaveAllowDebugName:       enclaveAllowDebugUsage,
+		enclaveTCBStatusesName:      enclaveTCBStatusesUsage,
+		insecureSkipAttestationName: insecureSkipAttestationUsage,
 	}
+	loader := config.NewLoader(&tomlConfig, flag.CommandLine, envPrefix, usages)
+	if err := loader.Load(os.Args[1:]); err != nil {
+		return walletextension.Config{}, nil, err
+	}
+	attestationPolicy, err := rpc.ParseAttestationPolicy([]string{tomlConfig.EnclaveUniqueID}, []string{tomlConfig.EnclaveSignerID},
+		tomlConfig.EnclaveProductID, uint64(tomlConfig.EnclaveSecurityVersion), tomlConfig.EnclaveAllowDebug, tomlConfig.EnclaveTCBStatuses)
+	if err != nil {
+		return walletextension.Config{}, nil, err
+	}
+
+	return walletextension.Config{
+		WalletExtensionHost:     tomlConfig.Host,
+		WalletExtensionPort:     tomlConfig.Port,
+		WalletExtensionPortWS:   tomlConfig.PortWS,
+		NodeRPCHTTPAddress:      fmt.Sprintf("%s:%d", tomlConfig.NodeHost, tomlConfig.NodePortHTTP),
+		NodeRPCWebsocketAddress: fmt.Sprintf("%s:%d", tomlConfig.NodeHost, tomlConfig.NodePortWS),
+		LogPath:                 tomlConfig.LogPath,
+		PersistencePathOverride: tomlConfig.PersistencePath,
+		VerboseFlag:             tomlConfig.Verbose,
+		AttestationConfig: rpc.AttestationConfig{
+			Policy:             attestationPolicy,
+			InsecureSkipVerify: tomlConfig.InsecureSkipAttestation,
+		},
+	}, loader, nil
 }
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"net"
 	"os"
@@ -20,10 +19,13 @@ const (
 )
 
 func main() {
-	config := parseCLIArgs()
-	jsonConfig, _ := json.MarshalIndent(config, "", "  ")
+	config, loader, err := parseCLIArgs()
+	if err != nil {
+		fmt.Printf("Could not load config. Cause: %s\n", err)
+		os.Exit(1)
+	}
 	fmt.Printf("Welcome to the Obscuro wallet extension. \n\n")
-	fmt.Printf("Starting with following config: \n%s\n", string(jsonConfig))
+	fmt.Printf("Starting with following config: \n%s\n", loader.Effective())
 
 	// We wait thirty seconds for a connection to the node. If we cannot establish one, we exit the program.
 	fmt.Printf("Waiting up to thirty seconds for connection to host at %s...\n", config.NodeRPCWebsocketAddress)
@@ -53,11 +55,13 @@ func main() {
 		}
 	}
 
-	logLvl := gethlog.LvlError
-	if config.VerboseFlag {
-		logLvl = gethlog.LvlDebug
-	}
-	logger := log.New(log.WalletExtCmp, int(logLvl), config.LogPath)
+	logger := log.New(log.WalletExtCmp, logLevel(config.VerboseFlag), config.LogPath)
+	stopConfigWatch := loader.WatchReload(logger, func(schema interface{}) {
+		if err := log.SetLevel(logger, logLevel(schema.(*walletExtensionConfigToml).Verbose)); err != nil {
+			logger.Error("Could not change the log level.", log.ErrKey, err)
+		}
+	})
+	defer stopConfigWatch()
 
 	walletExtension := walletextension.NewWalletExtension(config, logger)
 	defer walletExtension.Shutdown()
@@ -69,3 +73,11 @@ func main() {
 
 	select {}
 }
+
+// Returns the log level of the wallet extension, depending on whether verbose logging is enabled.
+func logLevel(verbose bool) int {
+	if verbose {
+		return int(gethlog.LvlDebug)
+	}
+	return int(gethlog.LvlError)
+}
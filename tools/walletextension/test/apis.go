@@ -25,7 +25,7 @@ const (
 	enclavePrivateKeyHex = "81acce9620f0adf1728cb8df7f6b8b8df857955eb9e8b7aed6ef8390c09fc207"
 )
 
-// DummyAPI provides dummies for the RPC operations defined in the `eth_` namespace. For each sensitive RPC
+// DummyAPI provides dummies for the RPC operations defined in the `eth_`, `obscuro_` and `debug_` namespaces. For each sensitive RPC
 // operation, it decrypts the parameters using the enclave's private key, then echoes them back to the caller encrypted
 // with the viewing key set using the `setViewingKey` method, mimicking the privacy behaviour of the host.
 type DummyAPI struct {
@@ -48,6 +48,14 @@ func (api *DummyAPI) AddViewingKey([]byte, []byte) error {
 	return nil
 }
 
+// Attestation returns an unsigned report containing the enclave public key, from which clients retrieve the key.
+func (api *DummyAPI) Attestation() (*common.AttestationReport, error) {
+	return &common.AttestationReport{
+		Report:    []byte("MOCK REPORT"),
+		RPCPubKey: crypto.CompressPubkey(api.enclavePrivateKey.PublicKey.ExportECDSA()),
+	}, nil
+}
+
 // Determines which key the API will encrypt responses with.
 func (api *DummyAPI) setViewingKey(viewingKeyHexBytes []byte) {
 	viewingKeyBytes, err := hex.DecodeString(string(viewingKeyHexBytes))
@@ -163,6 +171,29 @@ func (api *DummyAPI) GetLogs(_ context.Context, encryptedParams common.Encrypted
 	return &reEncryptParams, err
 }
 
+func (api *DummyAPI) GetWithdrawalProofs(_ context.Context, encryptedParams common.EncryptedParamsGetWithdrawals) (*string, error) {
+	reEncryptParams, err := api.reEncryptParams(encryptedParams)
+	return &reEncryptParams, err
+}
+
+func (api *DummyAPI) GetInboundMessageRecords(_ context.Context, encryptedParams common.EncryptedParamsGetInboundMsgs) (*string, error) {
+	reEncryptParams, err := api.reEncryptParams(encryptedParams)
+	return &reEncryptParams, err
+}
+
+func (api *DummyAPI) GetStorageAt(_ context.Context, encryptedParams common.EncryptedParamsGetStorageAt) (string, error) {
+	return api.reEncryptParams(encryptedParams)
+}
+
+func (api *DummyAPI) TraceTransaction(_ context.Context, encryptedParams common.EncryptedParamsTraceTx) (*string, error) {
+	reEncryptParams, err := api.reEncryptParams(encryptedParams)
+	return &reEncryptParams, err
+}
+
+func (api *DummyAPI) TraceCall(_ context.Context, encryptedParams common.EncryptedParamsTraceCall) (string, error) {
+	return api.reEncryptParams(encryptedParams)
+}
+
 // Decrypts the params with the enclave key, and returns them encrypted with the viewing key set via `setViewingKey`.
 func (api *DummyAPI) reEncryptParams(encryptedParams []byte) (string, error) {
 	params, err := api.enclavePrivateKey.Decrypt(encryptedParams, nil, nil)
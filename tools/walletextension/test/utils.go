@@ -18,6 +18,7 @@ import (
 	"github.com/go-kit/kit/transport/http/jsonrpc"
 	"github.com/gorilla/websocket"
 	"github.com/obscuronet/go-obscuro/go/common/log"
+	"github.com/obscuronet/go-obscuro/go/rpc"
 	"github.com/obscuronet/go-obscuro/tools/walletextension"
 	"github.com/obscuronet/go-obscuro/tools/walletextension/common"
 
@@ -40,6 +41,7 @@ func createWalExtCfg(connectPort, wallHTTPPort, wallWSPort int) *walletextension
 		PersistencePathOverride: testPersistencePath.Name(),
 		WalletExtensionPort:     wallHTTPPort,
 		WalletExtensionPortWS:   wallWSPort,
+		AttestationConfig:       rpc.AttestationConfig{InsecureSkipVerify: true},
 	}
 }
 
@@ -80,6 +82,18 @@ func createDummyHost(t *testing.T, wsRPCPort int) (*DummyAPI, func() error) {
 			Service:   dummyAPI,
 			Public:    true,
 		},
+		{
+			Namespace: hostcontainer.APINamespaceObscuroScan,
+			Version:   hostcontainer.APIVersion1,
+			Service:   dummyAPI,
+			Public:    true,
+		},
+		{
+			Namespace: hostcontainer.APINamespaceDebug,
+			Version:   hostcontainer.APIVersion1,
+			Service:   dummyAPI,
+			Public:    true,
+		},
 	})
 	if err != nil {
 		t.Fatalf(fmt.Sprintf("could not create new client server. Cause: %s", err))
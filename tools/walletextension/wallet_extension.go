@@ -46,7 +46,8 @@ var staticFiles embed.FS
 
 // WalletExtension is a server that handles the management of viewing keys and the forwarding of Ethereum JSON-RPC requests.
 type WalletExtension struct {
-	hostAddr           string // The address on which the Obscuro host can be reached.
+	hostAddr           string                 // The address on which the Obscuro host can be reached.
+	enclaveKeyFetcher  *rpc.EnclaveKeyFetcher // Shared by the encrypted clients, so the enclave key is only retrieved and verified once.
 	accountManager     accountmanager.AccountManager
 	unsignedVKs        map[gethcommon.Address]*rpc.ViewingKey // Map temporarily holding VKs that have been generated but not yet signed
 	serverHTTPShutdown func(ctx context.Context) error
@@ -67,19 +68,25 @@ func NewWalletExtension(config Config, logger gethlog.Logger) *WalletExtension {
 		logger.Crit("unable to create temporary client for request ", log.ErrKey, err)
 	}
 
+	enclaveKeyFetcher, err := rpc.NewEnclaveKeyFetcher(nil, config.AttestationConfig, logger)
+	if err != nil {
+		logger.Crit("invalid attestation constraints", log.ErrKey, err)
+	}
+
 	walletExtension := &WalletExtension{
-		hostAddr:       wsProtocol + config.NodeRPCWebsocketAddress,
-		unsignedVKs:    make(map[gethcommon.Address]*rpc.ViewingKey),
-		accountManager: accountmanager.NewAccountManager(unauthedClient, logger),
-		persistence:    persistence.NewPersistence(config.NodeRPCWebsocketAddress, config.PersistencePathOverride, logger),
-		logger:         logger,
+		hostAddr:          wsProtocol + config.NodeRPCWebsocketAddress,
+		enclaveKeyFetcher: enclaveKeyFetcher,
+		unsignedVKs:       make(map[gethcommon.Address]*rpc.ViewingKey),
+		accountManager:    accountmanager.NewAccountManager(unauthedClient, logger),
+		persistence:       persistence.NewPersistence(config.NodeRPCWebsocketAddress, config.PersistencePathOverride, logger),
+		logger:            logger,
 	}
 
 	// We reload the existing viewing keys from persistence.
 	for accountAddr, viewingKey := range walletExtension.persistence.LoadViewingKeys() {
 		// create an encrypted RPC client with the signed VK and register it with the enclave
 		// TODO - Create the clients lazily, to reduce connections to the host.
-		client, err := rpc.NewEncNetworkClient(walletExtension.hostAddr, viewingKey, logger)
+		client, err := rpc.NewEncNetworkClient(walletExtension.hostAddr, viewingKey, walletExtension.enclaveKeyFetcher, logger)
 		if err != nil {
 			logger.Error(fmt.Sprintf("failed to create encrypted RPC client for persisted account %s", accountAddr), log.ErrKey, err)
 			continue
@@ -404,7 +411,7 @@ func (we *WalletExtension) handleSubmitViewingKey(userConn userconn.UserConn) {
 	vk.SignedKey = signature
 	// create an encrypted RPC client with the signed VK and register it with the enclave
 	// TODO - Create the clients lazily, to reduce connections to the host.
-	client, err := rpc.NewEncNetworkClient(we.hostAddr, vk, we.logger)
+	client, err := rpc.NewEncNetworkClient(we.hostAddr, vk, we.enclaveKeyFetcher, we.logger)
 	if err != nil {
 		userConn.HandleError(fmt.Sprintf("failed to create encrypted RPC client for account %s. Cause: %s", accAddress, err))
 		return
@@ -431,4 +438,5 @@ type Config struct {
 	LogPath                 string
 	PersistencePathOverride string // Overrides the persistence file location. Used in tests.
 	VerboseFlag             bool
+	AttestationConfig       rpc.AttestationConfig // How the node's enclave's attestation is verified
 }